@@ -15,42 +15,101 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/branches": {
+        "/api/adjustments": {
             "get": {
-                "description": "Get a list of all branches in the system",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a page of balance adjustments filtered by merchant, user, status and reason code. Sort by id, amount or createdAt, prefixed with \"-\" for descending order; newest first by default.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "branches"
+                    "adjustments"
+                ],
+                "summary": "List balance adjustments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "goodwill",
+                            "service_issue",
+                            "correction",
+                            "migration",
+                            "fraud",
+                            "other"
+                        ],
+                        "type": "string",
+                        "name": "reasonCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "applied",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "userId",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all branches",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/branch_responses.BranchResponse"
-                            }
+                            "$ref": "#/definitions/pagination.Page-adjustment_responses_AdjustmentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new branch for a merchant in the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Credit or debit a user's balance with a reason code and a comment. Adjustments up to the approval threshold are applied at once (status applied); larger ones stay pending until another operator approves them. Debits the available balance does not cover are rejected with insufficient_rewards (422).",
                 "consumes": [
                     "application/json"
                 ],
@@ -58,17 +117,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branches"
+                    "adjustments"
                 ],
-                "summary": "Create a new branch",
+                "summary": "Request a balance adjustment",
                 "parameters": [
                     {
-                        "description": "Branch creation request",
+                        "description": "Adjustment",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/branch_requests.CreateBranchRequest"
+                            "$ref": "#/definitions/adjustment_requests.CreateAdjustmentRequest"
                         }
                     }
                 ],
@@ -76,48 +135,56 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/branch_responses.BranchResponse"
+                            "$ref": "#/definitions/adjustment_responses.AdjustmentResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/branches/merchant/{merchantID}": {
+        "/api/adjustments/{id}": {
             "get": {
-                "description": "Get a list of branches for a specific merchant",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a balance adjustment by its ID, with its audit trail",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "branches"
+                    "adjustments"
                 ],
-                "summary": "Get branches by merchant",
+                "summary": "Get a balance adjustment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Merchant ID",
-                        "name": "merchantID",
+                        "description": "Adjustment ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -126,36 +193,38 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/branch_responses.BranchResponse"
-                            }
+                            "$ref": "#/definitions/adjustment_responses.AdjustmentResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/branches/{id}": {
-            "get": {
-                "description": "Get details of a specific branch",
+        "/api/adjustments/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Approve a pending adjustment and apply it. The operator who requested it cannot approve it (self_review, 403).",
                 "consumes": [
                     "application/json"
                 ],
@@ -163,47 +232,74 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branches"
+                    "adjustments"
                 ],
-                "summary": "Get a branch by ID",
+                "summary": "Approve a balance adjustment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Branch ID",
+                        "description": "Adjustment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Comment of the review",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/adjustment_requests.ReviewAdjustmentRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/branch_responses.BranchResponse"
+                            "$ref": "#/definitions/adjustment_responses.AdjustmentResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update details of an existing branch",
+            }
+        },
+        "/api/adjustments/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reject a pending adjustment without applying it. The operator who requested it cannot reject it (self_review, 403).",
                 "consumes": [
                     "application/json"
                 ],
@@ -211,24 +307,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branches"
+                    "adjustments"
                 ],
-                "summary": "Update a branch",
+                "summary": "Reject a balance adjustment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Branch ID",
+                        "description": "Adjustment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Branch update request",
+                        "description": "Comment of the review",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/branch_requests.UpdateBranchRequest"
+                            "$ref": "#/definitions/adjustment_requests.ReviewAdjustmentRequest"
                         }
                     }
                 ],
@@ -236,45 +331,55 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/branch_responses.BranchResponse"
+                            "$ref": "#/definitions/adjustment_responses.AdjustmentResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/api-keys/{id}": {
             "delete": {
-                "description": "Delete an existing branch from the system",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Revoke an API key so it can no longer authenticate",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "branches"
+                    "api-keys"
                 ],
-                "summary": "Delete a branch",
+                "summary": "Revoke an API key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Branch ID",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -293,77 +398,90 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/branches/{id}/campaigns": {
-            "get": {
-                "description": "Get details of a specific branch along with its associated campaigns",
-                "consumes": [
-                    "application/json"
+        "/api/api-keys/{id}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Issue a replacement key with the same scope and revoke the current one",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "branches"
+                    "api-keys"
                 ],
-                "summary": "Get a branch with its campaigns",
+                "summary": "Rotate an API key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Branch ID",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/branch_responses.BranchWithCampaignsResponse"
+                            "$ref": "#/definitions/auth_responses.IssuedAPIKeyResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/campaigns": {
+        "/api/branches": {
             "get": {
-                "description": "Get a list of all campaigns in the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of branches, optionally filtered by merchant and name. Sort by id, name or createdAt, prefixed with \"-\" for descending order.",
                 "consumes": [
                     "application/json"
                 ],
@@ -371,32 +489,66 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "campaigns"
+                    "branches"
+                ],
+                "summary": "List branches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    }
                 ],
-                "summary": "List all campaigns",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/campaign_responses.CampaignResponse"
-                            }
+                            "$ref": "#/definitions/pagination.Page-branch_responses_BranchResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new loyalty campaign in the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new branch for a merchant in the system",
                 "consumes": [
                     "application/json"
                 ],
@@ -404,17 +556,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "campaigns"
+                    "branches"
                 ],
-                "summary": "Create a new campaign",
+                "summary": "Create a new branch",
                 "parameters": [
                     {
-                        "description": "Campaign creation request",
+                        "description": "Branch creation request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/campaign_requests.CreateCampaignRequest"
+                            "$ref": "#/definitions/branch_requests.CreateBranchRequest"
                         }
                     }
                 ],
@@ -422,33 +574,32 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/campaign_responses.CampaignResponse"
+                            "$ref": "#/definitions/branch_responses.BranchResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/campaigns/active": {
+        "/api/branches/merchant/{merchantID}": {
             "get": {
-                "description": "Get a list of active campaigns for a specific merchant, branch, and date",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of branches for a specific merchant",
                 "consumes": [
                     "application/json"
                 ],
@@ -456,27 +607,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "campaigns"
+                    "branches"
                 ],
-                "summary": "Get active campaigns",
+                "summary": "Get branches by merchant",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Merchant ID",
-                        "name": "merchantId",
+                        "name": "merchantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
+                        "maximum": 100,
+                        "minimum": 1,
                         "type": "integer",
-                        "description": "Branch ID",
-                        "name": "branchId",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "format": "date-time",
-                        "description": "Date (RFC3339 format)",
-                        "name": "date",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
                         "in": "query"
                     }
                 ],
@@ -484,27 +650,32 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/campaign_responses.CampaignResponse"
-                            }
+                            "$ref": "#/definitions/pagination.Page-branch_responses_BranchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/campaigns/{id}": {
+        "/api/branches/{id}": {
             "get": {
-                "description": "Get details of a specific campaign",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific branch",
                 "consumes": [
                     "application/json"
                 ],
@@ -512,13 +683,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "campaigns"
+                    "branches"
                 ],
-                "summary": "Get a campaign by ID",
+                "summary": "Get a branch by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Campaign ID",
+                        "description": "Branch ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -528,31 +699,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/campaign_responses.CampaignResponse"
+                            "$ref": "#/definitions/branch_responses.BranchResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update details of an existing campaign",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update details of an existing branch",
                 "consumes": [
                     "application/json"
                 ],
@@ -560,24 +730,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "campaigns"
+                    "branches"
                 ],
-                "summary": "Update a campaign",
+                "summary": "Update a branch",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Campaign ID",
+                        "description": "Branch ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Campaign update request",
+                        "description": "Branch update request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/campaign_requests.UpdateCampaignRequest"
+                            "$ref": "#/definitions/branch_requests.UpdateBranchRequest"
                         }
                     }
                 ],
@@ -585,31 +755,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/campaign_responses.CampaignResponse"
+                            "$ref": "#/definitions/branch_responses.BranchResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete an existing campaign from the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete an existing branch from the system",
                 "consumes": [
                     "application/json"
                 ],
@@ -617,13 +786,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "campaigns"
+                    "branches"
                 ],
-                "summary": "Delete a campaign",
+                "summary": "Delete a branch",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Campaign ID",
+                        "description": "Branch ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -642,27 +811,26 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/loyalty/process-transaction": {
-            "post": {
-                "description": "Process a user transaction and award loyalty points or cashback based on active campaigns",
+        "/api/branches/{id}/campaigns": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific branch along with its associated campaigns",
                 "consumes": [
                     "application/json"
                 ],
@@ -670,54 +838,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "loyalty"
+                    "branches"
                 ],
-                "summary": "Process a transaction and award loyalty points or cashback",
+                "summary": "Get a branch with its campaigns",
                 "parameters": [
                     {
-                        "description": "Transaction details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/loyalty_requests.ProcessTransactionRequest"
-                        }
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/branch_responses.BranchWithCampaignsResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/loyalty/redeem-rewards": {
-            "post": {
-                "description": "Redeem a user's loyalty points or cashback",
+        "/api/campaigns": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of campaigns filtered by merchant, branch, kind, type and a date range overlapping their validity. Sort by id, startDate, value or createdAt, prefixed with \"-\" for descending order.",
                 "consumes": [
                     "application/json"
                 ],
@@ -725,54 +887,91 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "loyalty"
+                    "campaigns"
                 ],
-                "summary": "Redeem user rewards",
+                "summary": "List campaigns",
                 "parameters": [
                     {
-                        "description": "Redemption details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/loyalty_requests.RedeemRewardsRequest"
-                        }
+                        "type": "integer",
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "multiplier",
+                            "stamp_card",
+                            "spend_threshold"
+                        ],
+                        "type": "string",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/pagination.Page-campaign_responses_CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/merchants": {
-            "get": {
-                "description": "Get a list of all merchants in the system",
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new loyalty campaign in the system. Multiplier campaigns (the default kind) multiply the base reward of each qualifying transaction by value. Stamp-card campaigns (kind stamp_card) give a stamp per qualifying transaction instead, and reward a card of stampsRequired stamps with value in the reward type or, with catalogItemId, a voucher for an item of the merchant's catalog. Spend-threshold campaigns (kind spend_threshold) award value once to each user whose qualifying spend during the campaign reaches spendThreshold. Rejected when a stamp-card campaign lacks stampsRequired (stamps_required), a spend-threshold campaign lacks spendThreshold (spend_threshold_required), or another kind has stamp-card fields (not_a_stamp_card) or a spend threshold (not_a_spend_threshold).",
                 "consumes": [
                     "application/json"
                 ],
@@ -780,32 +979,56 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "merchants"
+                    "campaigns"
+                ],
+                "summary": "Create a new campaign",
+                "parameters": [
+                    {
+                        "description": "Campaign creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/campaign_requests.CreateCampaignRequest"
+                        }
+                    }
                 ],
-                "summary": "List all merchants",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/merchant_responses.MerchantResponse"
-                            }
+                            "$ref": "#/definitions/campaign_responses.CampaignResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new merchant in the system",
+            }
+        },
+        "/api/campaigns/active": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a list of active campaigns for a specific merchant, branch, and date",
                 "consumes": [
                     "application/json"
                 ],
@@ -813,51 +1036,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "merchants"
+                    "campaigns"
                 ],
-                "summary": "Create a new merchant",
+                "summary": "Get active campaigns",
                 "parameters": [
                     {
-                        "description": "Merchant creation request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/merchant_requests.CreateMerchantRequest"
-                        }
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Branch ID",
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Date (RFC3339 format)",
+                        "name": "date",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/merchant_responses.MerchantResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/campaign_responses.CampaignResponse"
                             }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/merchants/{id}": {
+        "/api/campaigns/{id}": {
             "get": {
-                "description": "Get details of a specific merchant",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific campaign",
                 "consumes": [
                     "application/json"
                 ],
@@ -865,13 +1094,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "merchants"
+                    "campaigns"
                 ],
-                "summary": "Get a merchant by ID",
+                "summary": "Get a campaign by ID",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Merchant ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -881,31 +1110,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/merchant_responses.MerchantResponse"
+                            "$ref": "#/definitions/campaign_responses.CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Update details of an existing merchant",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update details of an existing campaign. Its kind cannot change, and a new size of the cards of a stamp-card campaign applies to the cards started afterwards.",
                 "consumes": [
                     "application/json"
                 ],
@@ -913,24 +1141,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "merchants"
+                    "campaigns"
                 ],
-                "summary": "Update a merchant",
+                "summary": "Update a campaign",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Merchant ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Merchant update request",
+                        "description": "Campaign update request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/merchant_requests.UpdateMerchantRequest"
+                            "$ref": "#/definitions/campaign_requests.UpdateCampaignRequest"
                         }
                     }
                 ],
@@ -938,31 +1166,36 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/merchant_responses.MerchantResponse"
+                            "$ref": "#/definitions/campaign_responses.CampaignResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete an existing merchant from the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete an existing campaign from the system",
                 "consumes": [
                     "application/json"
                 ],
@@ -970,98 +1203,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "merchants"
+                    "campaigns"
                 ],
-                "summary": "Delete a merchant",
+                "summary": "Delete a campaign",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Merchant ID",
+                        "description": "Campaign ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/api/rewards": {
-            "post": {
-                "description": "Create a new reward in the system",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "rewards"
-                ],
-                "summary": "Create a new reward",
-                "parameters": [
-                    {
-                        "description": "Reward creation request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/reward_requests.CreateRewardRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/reward_responses.RewardResponse"
-                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/rewards/user/{userID}": {
+        "/api/campaigns/{id}/report": {
             "get": {
-                "description": "Get a list of all rewards for a given user",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compare the transactions of the campaign's merchant, or branch, during the campaign with the period of the same length right before it, with the cost, participants and transactions of the rewards attributed to the campaign. A campaign without an end date, or not finished yet, is reported up to now. The ROI is only given for cashback campaigns.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1069,14 +1255,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rewards"
+                    "campaigns"
                 ],
-                "summary": "List rewards for a specific user",
+                "summary": "Get a campaign report",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "Campaign ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -1085,100 +1271,186 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/reward_responses.RewardResponse"
-                            }
+                            "$ref": "#/definitions/campaign_responses.CampaignReportResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/rewards/user/{userID}/total": {
+        "/api/catalog/items": {
             "get": {
-                "description": "Calculate the total rewards (points and cashback) for a specific user",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a page of catalog items filtered by merchant, branch and name; available=true keeps those that can be redeemed now. Sort by id, name, cost or createdAt, prefixed with \"-\" for descending order.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rewards"
+                    "catalog"
                 ],
-                "summary": "Get total rewards for a user",
+                "summary": "List catalog items",
                 "parameters": [
+                    {
+                        "type": "boolean",
+                        "name": "available",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/reward_responses.TotalRewardsResponse"
+                            "$ref": "#/definitions/pagination.Page-catalog_responses_CatalogItemResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add an item to the catalog of a merchant, redeemable for its cost in points or cashback. Stock is unlimited when omitted, and an item without branchIds is available in all the branches of the merchant.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "catalog"
+                ],
+                "summary": "Create a catalog item",
+                "parameters": [
+                    {
+                        "description": "Catalog item",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/catalog_requests.CreateCatalogItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/catalog_responses.CatalogItemResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/rewards/{id}": {
+        "/api/catalog/items/{id}": {
             "get": {
-                "description": "Get details of a specific reward",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a catalog item by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "rewards"
+                    "catalog"
                 ],
-                "summary": "Get a reward by ID",
+                "summary": "Get a catalog item",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Reward ID",
+                        "description": "Catalog item ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1188,33 +1460,36 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/reward_responses.RewardResponse"
+                            "$ref": "#/definitions/catalog_responses.CatalogItemResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/transactions": {
-            "post": {
-                "description": "Create a new transaction in the system",
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a catalog item and replace its branches. Pending redemptions keep the cost they were paid with.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1222,187 +1497,204 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "catalog"
                 ],
-                "summary": "Create a new transaction",
+                "summary": "Update a catalog item",
                 "parameters": [
                     {
-                        "description": "Transaction creation request",
+                        "type": "integer",
+                        "description": "Catalog item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Catalog item",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/transaction_requests.CreateTransactionRequest"
+                            "$ref": "#/definitions/catalog_requests.UpdateCatalogItemRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/transaction_responses.TransactionResponse"
+                            "$ref": "#/definitions/catalog_responses.CatalogItemResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/transactions/user/{userID}": {
-            "get": {
-                "description": "Get a list of all transactions for a given user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Remove an item from the catalog. Its pending redemptions can still be fulfilled or cancelled.",
                 "tags": [
-                    "transactions"
+                    "catalog"
                 ],
-                "summary": "List transactions for a specific user",
+                "summary": "Delete a catalog item",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
+                        "description": "Catalog item ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/transaction_responses.TransactionResponse"
-                            }
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/transactions/user/{userID}/total": {
+        "/api/catalog/redemptions": {
             "get": {
-                "description": "Calculate the total transaction amount for a specific user within a given date range",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a page of catalog item redemptions filtered by merchant, branch, user, item and status. Sort by id or createdAt, prefixed with \"-\" for descending order; newest first by default.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "catalog"
                 ],
-                "summary": "Get total transaction amount for a user within a date range",
+                "summary": "List item redemptions",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "catalogItemId",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "format": "date-time",
-                        "description": "Start date (RFC3339 format)",
-                        "name": "startDate",
-                        "in": "query",
-                        "required": true
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "format": "date-time",
-                        "description": "End date (RFC3339 format)",
-                        "name": "endDate",
-                        "in": "query",
-                        "required": true
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "fulfilled",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "userId",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "number"
-                            }
+                            "$ref": "#/definitions/pagination.Page-catalog_responses_ItemRedemptionResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/transactions/{id}": {
+        "/api/catalog/redemptions/{id}": {
             "get": {
-                "description": "Get details of a specific transaction",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a catalog item redemption by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transactions"
+                    "catalog"
                 ],
-                "summary": "Get a transaction by ID",
+                "summary": "Get an item redemption",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
+                        "description": "Redemption ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1412,66 +1704,38 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/transaction_responses.TransactionResponse"
+                            "$ref": "#/definitions/catalog_responses.ItemRedemptionResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/users": {
-            "get": {
-                "description": "Get a list of all users in the system",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "List all users",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/user_responses.UserResponse"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            },
+        "/api/catalog/redemptions/{id}/cancel": {
             "post": {
-                "description": "Create a new user in the system",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cancel a pending redemption: the units go back in stock and the cost is refunded to the user as a new reward.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1479,65 +1743,79 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "catalog"
                 ],
-                "summary": "Create a new user",
+                "summary": "Cancel an item redemption",
                 "parameters": [
                     {
-                        "description": "User creation request",
+                        "type": "integer",
+                        "description": "Redemption ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason of the cancellation",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user_requests.CreateUserRequest"
+                            "$ref": "#/definitions/catalog_requests.CancelRedemptionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_responses.UserResponse"
+                            "$ref": "#/definitions/catalog_responses.ItemRedemptionResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/users/{id}": {
-            "get": {
-                "description": "Get details of a specific user",
-                "consumes": [
-                    "application/json"
+        "/api/catalog/redemptions/{id}/fulfil": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Record that the branch of a pending redemption handed the item over to the user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "catalog"
                 ],
-                "summary": "Get a user by ID",
+                "summary": "Fulfil an item redemption",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Redemption ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1547,88 +1825,118 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_responses.UserResponse"
+                            "$ref": "#/definitions/catalog_responses.ItemRedemptionResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update details of an existing user",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/api/challenges": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a page of challenges filtered by merchant and goal; active=true keeps those running now. Sort by id, name, startDate or createdAt, prefixed with \"-\" for descending order.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "challenges"
                 ],
-                "summary": "Update a user",
+                "summary": "List challenges",
                 "parameters": [
                     {
+                        "type": "boolean",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "distinct_branches",
+                            "weekly_streak",
+                            "transactions"
+                        ],
+                        "type": "string",
+                        "name": "goal",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "description": "User update request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user_requests.UpdateUserRequest"
-                        }
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_responses.UserResponse"
+                            "$ref": "#/definitions/pagination.Page-challenge_responses_ChallengeResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Delete an existing user from the system",
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a challenge for the users of a merchant, evaluated on each of their transactions between startDate and endDate that reach minAmount. The goal is distinct_branches (buy at target different branches, within windowDays when set), weekly_streak (buy in target consecutive weeks, Monday to Sunday in UTC) or transactions (make target purchases). Completing it awards the badge once per user, and rewardAmount in rewardType when set.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1636,66 +1944,67 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "challenges"
                 ],
-                "summary": "Delete a user",
+                "summary": "Create a challenge",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Challenge",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge_requests.CreateChallengeRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/challenge_responses.ChallengeResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/users/{id}/rewards": {
+        "/api/challenges/{id}": {
             "get": {
-                "description": "Get details of a user along with their reward history",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Get a challenge by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "challenges"
                 ],
-                "summary": "Get a user with their rewards",
+                "summary": "Get a challenge",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Challenge ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1705,33 +2014,36 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_responses.UserWithRewardsResponse"
+                            "$ref": "#/definitions/challenge_responses.ChallengeResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/api/users/{id}/transactions": {
-            "get": {
-                "description": "Get details of a user along with their transaction history",
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a challenge. The progress of its users is recounted with the new goal on their next transaction; the challenges already completed keep their badges and rewards.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1739,244 +2051,5418 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "challenges"
                 ],
-                "summary": "Get a user with their transactions",
+                "summary": "Update a challenge",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Challenge ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Challenge",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenge_requests.UpdateChallengeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_responses.UserWithTransactionsResponse"
+                            "$ref": "#/definitions/challenge_responses.ChallengeResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a challenge. The badges already awarded for it are kept.",
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Delete a challenge",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Challenge ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/authorize-redemption": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Hold an amount of a user's points or cashback when a checkout starts, to be captured when the sale completes or voided if it is abandoned. The amount stops being available but stays in the user's balance until it is captured. Holds expire after REDEMPTION_HOLD_TIMEOUT (15 minutes by default). Rejected with insufficient_rewards (422) when the available balance is not enough.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Authorize a redemption",
+                "parameters": [
+                    {
+                        "description": "Redemption details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/loyalty_requests.AuthorizeRedemptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/reward_responses.HoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/holds/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a redemption hold by its ID, with its status: held, captured, voided or expired",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Get a redemption hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reward_responses.HoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/holds/{id}/capture": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Redeem the amount of an active hold, or a smaller amount given in the body, releasing the rest. Rejected with hold_not_active or hold_expired (409), and capture_exceeds_hold when the amount is larger than the hold.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Capture a redemption",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount to capture",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/loyalty_requests.CaptureRedemptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reward_responses.HoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/holds/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Release an active hold, making its amount available again. Rejected with hold_not_active (409) when it was already captured, voided or expired.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Void a redemption",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reward_responses.HoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/imports": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queue a CSV or JSONL file of transactions, uploaded as the \"file\" field of a multipart form or as the raw body, to be processed in the background like process-transaction. CSV files need a header with the columns userId, branchId, amount, date and externalRef, and optionally merchantId; JSONL rows use the same keys. Dates are RFC 3339 timestamps or YYYY-MM-DD dates. externalRef is required and rows already processed for the merchant are reported as duplicates. The format defaults to the file extension or the content type (text/csv, application/x-ndjson).",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Import a file of transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File of transactions",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/loyalty_responses.ImportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/imports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the status and the row counters of an import",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Get an import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/loyalty_responses.ImportJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/imports/{id}/errors": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download the failed and duplicate rows of an import as CSV, with the line, the error code and the original row",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Download the error report of an import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/imports/{id}/rows": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of the results of the processed rows of an import, optionally filtered by status. Sort by line, prefixed with \"-\" for descending order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "List the rows of an import",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Import ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "processed",
+                            "failed",
+                            "duplicate"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-loyalty_responses_ImportRowResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/process-transaction": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Process a user transaction and award loyalty points or cashback based on active campaigns. The merchant defaults to the merchant of the branch; a merchant that does not own the branch is rejected with branch_merchant_mismatch, and an externalRef already processed for the merchant with transaction_already_exists (409)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Process a transaction and award loyalty points or cashback",
+                "parameters": [
+                    {
+                        "description": "Transaction details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/loyalty_requests.ProcessTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/redeem-item": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Pay for a catalog item with the user's points or cashback at a branch. The cost is deducted and the stock decremented atomically; the redemption stays pending until the branch fulfils or cancels it. Rejected with item_not_available outside the validity window of the item or at a branch that does not offer it, item_out_of_stock (409) and insufficient_rewards (422).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Redeem a catalog item",
+                "parameters": [
+                    {
+                        "description": "Redemption details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/catalog_requests.RedeemItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/catalog_responses.ItemRedemptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/loyalty/redeem-rewards": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Redeem a user's loyalty points or cashback",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "loyalty"
+                ],
+                "summary": "Redeem user rewards",
+                "parameters": [
+                    {
+                        "description": "Redemption details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/loyalty_requests.RedeemRewardsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/merchants": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of merchants; merchant keys only see their own. Sort by id, name or createdAt, prefixed with \"-\" for descending order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchants"
+                ],
+                "summary": "List merchants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-merchant_responses_MerchantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new merchant in the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchants"
+                ],
+                "summary": "Create a new merchant",
+                "parameters": [
+                    {
+                        "description": "Merchant creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/merchant_requests.CreateMerchantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/merchant_responses.MerchantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/merchants/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific merchant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchants"
+                ],
+                "summary": "Get a merchant by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/merchant_responses.MerchantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update details of an existing merchant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchants"
+                ],
+                "summary": "Update a merchant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Merchant update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/merchant_requests.UpdateMerchantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/merchant_responses.MerchantResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete an existing merchant from the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchants"
+                ],
+                "summary": "Delete a merchant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/merchants/{id}/analytics": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the sales volume, transaction count, unique customers, repeat-visit rate, rewards issued and redeemed and per-branch breakdown of a merchant between two days, both included, with a series by day, week or month in UTC. The outstanding liability is the unexpired rewards at the time of the request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "merchants"
+                ],
+                "summary": "Get merchant analytics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month"
+                        ],
+                        "type": "string",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/merchant_responses.AnalyticsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/merchants/{id}/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of the API keys issued for a merchant, including revoked ones. Sort by id, name or createdAt, prefixed with \"-\" for descending order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "List API keys of a merchant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-auth_responses_APIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue a new API key for a merchant, optionally restricted to one of its branches, with a merchant_admin, branch_operator or analyst role. The key is only returned once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Issue an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth_requests.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/auth_responses.IssuedAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/merchants/{id}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of the webhooks of a merchant. Sort by id or createdAt, prefixed with \"-\" for descending order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks of a merchant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-webhook_responses_WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Subscribe a URL to the events of a merchant. Deliveries are signed with the secret, which is generated when omitted and only returned once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Subscribe a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Merchant ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook subscription request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook_requests.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhook_responses.IssuedWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/referrals": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Attribute a new user of the merchant to the owner of the referral code and enroll them in the merchant's program. Both receive the merchant's referral bonuses on the user's first transaction of at least the merchant's minimum amount. Rejected when users refer themselves (self_referral), the code is mistyped (invalid_referral_code) or unknown (referral_code_not_found), the merchant has no referral bonuses (referrals_disabled), the referrer is not a member of the merchant's program (referrer_not_member), the user was already referred (already_referred) or has transactions at the merchant (not_a_new_user), or the referrer reached the maximum number of referrals for the period (referral_limit_reached).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referrals"
+                ],
+                "summary": "Refer a new user",
+                "parameters": [
+                    {
+                        "description": "Referral",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/referral_requests.CreateReferralRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/referral_responses.ReferralResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rewards": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of rewards filtered by merchant, user and type. Sort by id, amount or createdAt, prefixed with \"-\" for descending order; the default is -createdAt.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "List rewards",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "points",
+                            "cashback"
+                        ],
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-reward_responses_RewardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new reward in the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "Create a new reward",
+                "parameters": [
+                    {
+                        "description": "Reward creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/reward_requests.CreateRewardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/reward_responses.RewardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rewards/movements/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download the rewards granted, adjusted, revoked, redeemed and expired, filtered by merchant, user and date range, as CSV (the default) or JSON lines in the order they were recorded. For adjustments the amount is the new amount of the reward. The file is streamed as it is read, so exports of any size are supported.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "Export reward movements",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rewards/user/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of the rewards of a given user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "List rewards for a specific user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "points",
+                            "cashback"
+                        ],
+                        "type": "string",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-reward_responses_RewardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rewards/user/{userID}/total": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Calculate the total rewards (points and cashback) for a specific user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "Get total rewards for a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reward_responses.TotalRewardsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/rewards/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific reward",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rewards"
+                ],
+                "summary": "Get a reward by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reward ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/reward_responses.RewardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of transactions filtered by merchant, branch, user and date range. Sort by id, date, amount or createdAt, prefixed with \"-\" for descending order; the default is -date.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "List transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-transaction_responses_TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new transaction for an existing user. The merchant defaults to the merchant of the branch and must match it when given",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Create a new transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/transaction_requests.CreateTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/transaction_responses.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transactions/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download the transactions filtered by merchant, branch and date range as CSV (the default) or JSON lines, in id order. The file is streamed as it is read, so exports of any size are supported.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Export transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transactions/user/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of the transactions of a given user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "List transactions for a specific user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "name": "branchId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-transaction_responses_TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transactions/user/{userID}/total": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Calculate the total transaction amount for a specific user within a given date range",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get total transaction amount for a user within a date range",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Start date (RFC3339 format)",
+                        "name": "startDate",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "End date (RFC3339 format)",
+                        "name": "endDate",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "number"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transactions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Get a transaction by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transaction_responses.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/transactions/{id}/reverse": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Record the return of a sale. The rewards it earned that are still pending are revoked; those already vested are kept. Its stamps come off their cards, or off the user's open card of the campaign when their card was already completed. A spend-threshold award whose pending bonus is revoked is revoked too, and the user can reach the threshold again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transactions"
+                ],
+                "summary": "Reverse a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transaction_responses.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of the users enrolled in the caller's merchant, optionally filtered by name. Sort by id, name or createdAt, prefixed with \"-\" for descending order.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-user_responses_UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new user in the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_requests.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/user_responses.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Download the users enrolled in the caller's merchant, optionally filtered by name, as CSV (the default) or JSON lines in id order. The file is streamed as it is read, so exports of any size are supported.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Export users",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "name": "merchantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_responses.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update details of an existing user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_requests.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_responses.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete an existing user from the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/badges": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the badges the user earned by completing challenges, newest first. Merchant callers only see the badges of their merchant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "List the badges of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/challenge_responses.BadgeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/challenges": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the challenges running now of the merchants whose program the user belongs to, with the user's progress towards the target as of their last transaction, and the badge and reward of those completed. Merchant callers only see the challenges of their merchant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "List the challenges of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/challenge_responses.ChallengeProgressResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/referral": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the user's referral code, who referred them and the referrals they made, with how many are pending and qualified. Merchant callers only see the referrals made at their merchant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referrals"
+                ],
+                "summary": "Get the referral status of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/referral_responses.ReferralStatusResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/referral-code": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Give the user a referral code to share, or return the one they already have",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "referrals"
+                ],
+                "summary": "Issue a referral code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/referral_responses.ReferralCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/rewards": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a user along with their reward history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user with their rewards",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_responses.UserWithRewardsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/spend-progress": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the user's progress in the active spend-threshold campaigns of the merchants whose program they belong to: the qualifying spend during the campaign, what is left to reach the threshold, and the award once it was reached. Merchant callers only see the campaigns of their merchant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spend-thresholds"
+                ],
+                "summary": "List the spend progress of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/threshold_responses.SpendProgressResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/stamp-cards": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the user's cards of stamp-card campaigns, newest first: the open card of each campaign with its stamps, and the completed ones with their reward, or the redemption and voucher code of their catalog item. Merchant callers only see the cards of their merchant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stamp-cards"
+                ],
+                "summary": "List the stamp cards of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "completed"
+                        ],
+                        "type": "string",
+                        "description": "Card status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/stamp_responses.StampCardResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/{id}/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a user along with their transaction history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user with their transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_responses.UserWithTransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vouchers/consume": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Use a voucher at a branch of its merchant, which fulfils its redemption. A voucher is used once: of concurrent attempts only one succeeds and the rest fail with voucher_used (409).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vouchers"
+                ],
+                "summary": "Consume a voucher",
+                "parameters": [
+                    {
+                        "description": "Voucher code and branch",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/catalog_requests.VoucherRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/catalog_responses.VoucherResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vouchers/validate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Check that a voucher can be used at a branch without using it. Mistyped codes are rejected with invalid_voucher_code when codes carry a check digit, and vouchers that cannot be used with voucher_used, voucher_expired or voucher_cancelled (409).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vouchers"
+                ],
+                "summary": "Validate a voucher",
+                "parameters": [
+                    {
+                        "description": "Voucher code and branch",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/catalog_requests.VoucherRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/catalog_responses.VoucherResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a webhook subscription by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook_responses.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Change the URL, the event types or the active flag of a webhook",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook_requests.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook_responses.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete a webhook subscription; its pending deliveries are not sent",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a page of the delivery history of a webhook, newest first by default, optionally filtered by status. Sort by id or createdAt, prefixed with \"-\" for descending order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List the deliveries of a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "retrying",
+                            "delivered",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-webhook_responses_DeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/webhooks/{id}/deliveries/{deliveryId}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Send a delivery again right away, including dead ones. A failed redelivery is retried with the usual backoff.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook_responses.DeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain_errors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Report that the process is alive",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health_responses.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Check the database, schema migrations and background scheduler and report whether the service can receive traffic",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health_responses.HealthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health_responses.HealthResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "adjustment_requests.CreateAdjustmentRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "comment",
+                "direction",
+                "merchantId",
+                "reasonCode",
+                "rewardType",
+                "userId"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "comment": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "direction": {
+                    "type": "string",
+                    "enum": [
+                        "credit",
+                        "debit"
+                    ]
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "reasonCode": {
+                    "type": "string",
+                    "enum": [
+                        "goodwill",
+                        "service_issue",
+                        "correction",
+                        "migration",
+                        "fraud",
+                        "other"
+                    ]
+                },
+                "rewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "adjustment_requests.ReviewAdjustmentRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "adjustment_responses.AdjustmentAuditResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "keyId": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "adjustment_responses.AdjustmentResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "appliedAt": {
+                    "type": "string"
+                },
+                "audit": {
+                    "description": "Audit is only included for a single adjustment.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/adjustment_responses.AdjustmentAuditResponse"
+                    }
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "direction": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "reasonCode": {
+                    "type": "string"
+                },
+                "requestedByKeyId": {
+                    "type": "integer"
+                },
+                "requestedByRole": {
+                    "type": "string"
+                },
+                "reviewComment": {
+                    "type": "string"
+                },
+                "reviewedAt": {
+                    "type": "string"
+                },
+                "reviewedByKeyId": {
+                    "type": "integer"
+                },
+                "reviewedByRole": {
+                    "type": "string"
+                },
+                "rewardType": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth_requests.CreateAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role defaults to branch_operator for branch keys and merchant_admin otherwise.",
+                    "type": "string",
+                    "enum": [
+                        "merchant_admin",
+                        "branch_operator",
+                        "analyst"
+                    ]
+                }
+            }
+        },
+        "auth_responses.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth_responses.IssuedAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
         "branch_requests.CreateBranchRequest": {
             "type": "object",
             "required": [
-                "merchant_id",
+                "merchant_id",
+                "name"
+            ],
+            "properties": {
+                "merchant_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "branch_requests.UpdateBranchRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "branch_responses.BranchResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchant_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "branch_responses.BranchWithCampaignsResponse": {
+            "type": "object",
+            "properties": {
+                "campaigns": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/branch_responses.CampaignResponse"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchant_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "branch_responses.CampaignResponse": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "min_amount": {
+                    "type": "number"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "campaign_requests.CreateCampaignRequest": {
+            "type": "object",
+            "required": [
+                "merchantId",
+                "startDate",
+                "type"
+            ],
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "catalogItemId": {
+                    "type": "integer"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "multiplier",
+                        "stamp_card",
+                        "spend_threshold"
+                    ]
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "minAmount": {
+                    "type": "number"
+                },
+                "spendThreshold": {
+                    "type": "number"
+                },
+                "stampsRequired": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "campaign_requests.UpdateCampaignRequest": {
+            "type": "object",
+            "required": [
+                "startDate",
+                "type"
+            ],
+            "properties": {
+                "catalogItemId": {
+                    "type": "integer"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "minAmount": {
+                    "type": "number"
+                },
+                "spendThreshold": {
+                    "type": "number"
+                },
+                "stampsRequired": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "campaign_responses.CampaignReportResponse": {
+            "type": "object",
+            "properties": {
+                "averageTicketUplift": {
+                    "description": "AverageTicketUplift is the relative change of the average ticket over\nthe baseline, absent when the baseline had no transactions.",
+                    "type": "number"
+                },
+                "baseline": {
+                    "$ref": "#/definitions/campaign_responses.ReportPeriod"
+                },
+                "branchId": {
+                    "type": "integer"
+                },
+                "campaign": {
+                    "description": "Campaign covers the campaign up to its end date or now; Baseline is the\nperiod of the same length right before it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/campaign_responses.ReportPeriod"
+                        }
+                    ]
+                },
+                "campaignId": {
+                    "type": "integer"
+                },
+                "inProgress": {
+                    "type": "boolean"
+                },
+                "incrementalSpend": {
+                    "type": "number"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "participants": {
+                    "type": "integer"
+                },
+                "participatingSales": {
+                    "type": "number"
+                },
+                "participatingTransactions": {
+                    "description": "ParticipatingTransactions and ParticipatingSales are those of the\ntransactions that earned a reward from the campaign.",
+                    "type": "integer"
+                },
+                "rewardType": {
+                    "description": "RewardType is the unit of RewardsCost.",
+                    "type": "string"
+                },
+                "rewardsCost": {
+                    "type": "number"
+                },
+                "rewardsCount": {
+                    "type": "integer"
+                },
+                "roi": {
+                    "description": "ROI is (incrementalSpend - rewardsCost) / rewardsCost, only for\ncashback campaigns, whose cost is in the currency of the sales.",
+                    "type": "number"
+                }
+            }
+        },
+        "campaign_responses.CampaignResponse": {
+            "type": "object",
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "catalogItemId": {
+                    "type": "integer"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "minAmount": {
+                    "type": "number"
+                },
+                "spendThreshold": {
+                    "description": "SpendThreshold is only set on spend-threshold campaigns.",
+                    "type": "number"
+                },
+                "stampsRequired": {
+                    "description": "StampsRequired and CatalogItemID are only set on stamp-card campaigns.",
+                    "type": "integer"
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "campaign_responses.ReportPeriod": {
+            "type": "object",
+            "properties": {
+                "averageTicket": {
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "sales": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "integer"
+                },
+                "uniqueCustomers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "catalog_requests.CancelRedemptionRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "catalog_requests.CreateCatalogItemRequest": {
+            "type": "object",
+            "required": [
+                "cost",
+                "merchantId",
+                "name",
+                "rewardType"
+            ],
+            "properties": {
+                "branchIds": {
+                    "description": "BranchIDs restricts the item to some branches; it is available in all\nof them when empty.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "rewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "stock": {
+                    "description": "Stock is unlimited when omitted.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validUntil": {
+                    "type": "string"
+                }
+            }
+        },
+        "catalog_requests.RedeemItemRequest": {
+            "type": "object",
+            "required": [
+                "branchId",
+                "catalogItemId",
+                "userId"
+            ],
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "catalogItemId": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Quantity defaults to one unit.",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "catalog_requests.UpdateCatalogItemRequest": {
+            "type": "object",
+            "required": [
+                "cost",
+                "name",
+                "rewardType"
+            ],
+            "properties": {
+                "branchIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "rewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "stock": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validUntil": {
+                    "type": "string"
+                }
+            }
+        },
+        "catalog_requests.VoucherRequest": {
+            "type": "object",
+            "required": [
+                "branchId",
+                "code"
+            ],
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "catalog_responses.CatalogItemResponse": {
+            "type": "object",
+            "properties": {
+                "branchIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rewardType": {
+                    "type": "string"
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "validFrom": {
+                    "type": "string"
+                },
+                "validUntil": {
+                    "type": "string"
+                }
+            }
+        },
+        "catalog_responses.ItemRedemptionResponse": {
+            "type": "object",
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "cancelReason": {
+                    "type": "string"
+                },
+                "cancelledAt": {
+                    "type": "string"
+                },
+                "catalogItemId": {
+                    "type": "integer"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "fulfilledAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "rewardType": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                },
+                "voucher": {
+                    "description": "Voucher is missing for redemptions made before vouchers were issued.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/catalog_responses.VoucherResponse"
+                        }
+                    ]
+                }
+            }
+        },
+        "catalog_responses.VoucherResponse": {
+            "type": "object",
+            "properties": {
+                "cancelledAt": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "itemRedemptionId": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "redemption": {
+                    "description": "Redemption is included when the voucher is validated or used.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/catalog_responses.ItemRedemptionResponse"
+                        }
+                    ]
+                },
+                "status": {
+                    "type": "string"
+                },
+                "usedAt": {
+                    "type": "string"
+                },
+                "usedBranchId": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge_requests.CreateChallengeRequest": {
+            "type": "object",
+            "required": [
+                "badgeName",
+                "goal",
+                "merchantId",
+                "name",
+                "startDate",
+                "target"
+            ],
+            "properties": {
+                "badgeImageUrl": {
+                    "type": "string"
+                },
+                "badgeName": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "description": {
+                    "type": "string"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "goal": {
+                    "type": "string",
+                    "enum": [
+                        "distinct_branches",
+                        "weekly_streak",
+                        "transactions"
+                    ]
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "minAmount": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "rewardAmount": {
+                    "type": "number"
+                },
+                "rewardType": {
+                    "description": "RewardType and RewardAmount give a reward on completion along with the\nbadge; the challenge only awards the badge when they are omitted.",
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "windowDays": {
+                    "description": "WindowDays only applies to distinct_branches challenges: the branches\nmust be visited within that many days. The whole challenge counts when\nomitted.",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "challenge_requests.UpdateChallengeRequest": {
+            "type": "object",
+            "required": [
+                "badgeName",
+                "goal",
+                "name",
+                "startDate",
+                "target"
+            ],
+            "properties": {
+                "badgeImageUrl": {
+                    "type": "string"
+                },
+                "badgeName": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "description": {
+                    "type": "string"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "goal": {
+                    "type": "string",
+                    "enum": [
+                        "distinct_branches",
+                        "weekly_streak",
+                        "transactions"
+                    ]
+                },
+                "minAmount": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "rewardAmount": {
+                    "type": "number"
+                },
+                "rewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "windowDays": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "challenge_responses.BadgeResponse": {
+            "type": "object",
+            "properties": {
+                "awardedAt": {
+                    "type": "string"
+                },
+                "challengeId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "imageUrl": {
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge_responses.ChallengeProgressResponse": {
+            "type": "object",
+            "properties": {
+                "badgeId": {
+                    "type": "integer"
+                },
+                "challenge": {
+                    "$ref": "#/definitions/challenge_responses.ChallengeResponse"
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "integer"
+                },
+                "rewardId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "challenge_responses.ChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "badgeImageUrl": {
+                    "type": "string"
+                },
+                "badgeName": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "goal": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "minAmount": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rewardAmount": {
+                    "type": "number"
+                },
+                "rewardType": {
+                    "type": "string"
+                },
+                "startDate": {
+                    "type": "string"
+                },
+                "target": {
+                    "type": "integer"
+                },
+                "windowDays": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain_errors.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "user_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "user not found"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                }
+            }
+        },
+        "health_responses.CheckResult": {
+            "type": "object",
+            "properties": {
+                "details": {},
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health_responses.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health_responses.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "loyalty_requests.AuthorizeRedemptionRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "merchantId",
+                "rewardType",
+                "userId"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "branchId": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "rewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "loyalty_requests.CaptureRedemptionRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "loyalty_requests.ProcessTransactionRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "branchId",
+                "date",
+                "userId"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "branchId": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "externalRef": {
+                    "description": "ExternalRef is the reference of the sale in the merchant's system. A\nreference already processed for the merchant is rejected, which makes\nretries safe.",
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "merchantId": {
+                    "description": "MerchantID is optional and defaults to the merchant of the branch.",
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "loyalty_requests.RedeemRewardsRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "merchantId",
+                "rewardType",
+                "userId"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "rewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "loyalty_responses.ImportJobResponse": {
+            "type": "object",
+            "properties": {
+                "branchId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "duplicates": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "loyalty_responses.ImportRowResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "errorCode": {
+                    "type": "string"
+                },
+                "externalRef": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "merchant_requests.CreateMerchantRequest": {
+            "type": "object",
+            "required": [
+                "conversion_factor",
+                "defaultRewardType",
+                "name"
+            ],
+            "properties": {
+                "anniversaryBonus": {
+                    "type": "number"
+                },
+                "birthdayBonus": {
+                    "type": "number"
+                },
+                "bonusWindowDays": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "conversion_factor": {
+                    "type": "number"
+                },
+                "defaultRewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "enrollmentBonus": {
+                    "description": "EnrollmentBonus, BirthdayBonus and AnniversaryBonus are granted by the\ndaily lifecycle bonuses job, up to BonusWindowDays after the date.",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refereeBonus": {
+                    "type": "number"
+                },
+                "referralMinAmount": {
+                    "type": "number"
+                },
+                "referrerBonus": {
+                    "description": "ReferrerBonus and RefereeBonus are granted when a referred user makes\ntheir first transaction of at least ReferralMinAmount; leave both out\nto turn referrals off.",
+                    "type": "number"
+                },
+                "rewardValidityDays": {
+                    "description": "RewardValidityDays makes the rewards granted by the merchant expire after that many days.",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "rewardVestingDays": {
+                    "description": "RewardVestingDays keeps the rewards earned by transactions pending, and\nrevocable by a reversal, for that many days.",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "merchant_requests.UpdateMerchantRequest": {
+            "type": "object",
+            "required": [
+                "conversion_factor",
+                "defaultRewardType",
                 "name"
             ],
             "properties": {
-                "merchant_id": {
-                    "type": "integer"
+                "anniversaryBonus": {
+                    "type": "number"
+                },
+                "birthdayBonus": {
+                    "type": "number"
+                },
+                "bonusWindowDays": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "conversion_factor": {
+                    "type": "number"
+                },
+                "defaultRewardType": {
+                    "type": "string",
+                    "enum": [
+                        "points",
+                        "cashback"
+                    ]
+                },
+                "enrollmentBonus": {
+                    "description": "EnrollmentBonus, BirthdayBonus and AnniversaryBonus are granted by the\ndaily lifecycle bonuses job, up to BonusWindowDays after the date.",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refereeBonus": {
+                    "type": "number"
+                },
+                "referralMinAmount": {
+                    "type": "number"
+                },
+                "referrerBonus": {
+                    "description": "ReferrerBonus and RefereeBonus are granted when a referred user makes\ntheir first transaction of at least ReferralMinAmount; leave both out\nto turn referrals off.",
+                    "type": "number"
+                },
+                "rewardValidityDays": {
+                    "description": "RewardValidityDays makes the rewards granted by the merchant expire after that many days.",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "rewardVestingDays": {
+                    "description": "RewardVestingDays keeps the rewards earned by transactions pending, and\nrevocable by a reversal, for that many days.",
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "merchant_responses.AnalyticsPeriod": {
+            "type": "object",
+            "properties": {
+                "credited": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "debited": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "issued": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "redeemed": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "refunded": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "sales_volume": {
+                    "type": "number"
+                },
+                "transaction_count": {
+                    "type": "integer"
+                },
+                "unique_customers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "merchant_responses.AnalyticsResponse": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/merchant_responses.BranchAnalytics"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "granularity": {
+                    "type": "string"
+                },
+                "merchant_id": {
+                    "type": "integer"
+                },
+                "outstanding_liability": {
+                    "description": "OutstandingLiability is what the merchant owes in unexpired rewards now,\nwhatever the range.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                        }
+                    ]
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/merchant_responses.AnalyticsPeriod"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                },
+                "totals": {
+                    "$ref": "#/definitions/merchant_responses.AnalyticsTotals"
+                }
+            }
+        },
+        "merchant_responses.AnalyticsTotals": {
+            "type": "object",
+            "properties": {
+                "credited": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "debited": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "issued": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "redeemed": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "refunded": {
+                    "$ref": "#/definitions/merchant_responses.RewardAmounts"
+                },
+                "repeat_customers": {
+                    "type": "integer"
+                },
+                "repeat_visit_rate": {
+                    "description": "RepeatVisitRate is the share of unique customers with more than one\ntransaction in the range.",
+                    "type": "number"
+                },
+                "sales_volume": {
+                    "type": "number"
+                },
+                "transaction_count": {
+                    "type": "integer"
+                },
+                "unique_customers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "merchant_responses.BranchAnalytics": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "repeat_customers": {
+                    "type": "integer"
+                },
+                "repeat_visit_rate": {
+                    "type": "number"
+                },
+                "sales_volume": {
+                    "type": "number"
+                },
+                "transaction_count": {
+                    "type": "integer"
+                },
+                "unique_customers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "merchant_responses.MerchantResponse": {
+            "type": "object",
+            "properties": {
+                "anniversaryBonus": {
+                    "type": "number"
+                },
+                "birthdayBonus": {
+                    "type": "number"
+                },
+                "bonusWindowDays": {
+                    "type": "integer"
+                },
+                "conversion_factor": {
+                    "type": "number"
+                },
+                "defaultRewardType": {
+                    "type": "string"
+                },
+                "enrollmentBonus": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "refereeBonus": {
+                    "type": "number"
+                },
+                "referralMinAmount": {
+                    "type": "number"
+                },
+                "referrerBonus": {
+                    "type": "number"
+                },
+                "rewardValidityDays": {
+                    "type": "integer"
+                },
+                "rewardVestingDays": {
+                    "type": "integer"
+                }
+            }
+        },
+        "merchant_responses.RewardAmounts": {
+            "type": "object",
+            "properties": {
+                "cashback": {
+                    "type": "number"
                 },
-                "name": {
-                    "type": "string"
+                "points": {
+                    "type": "number"
                 }
             }
         },
-        "branch_requests.UpdateBranchRequest": {
+        "pagination.Page-adjustment_responses_AdjustmentResponse": {
             "type": "object",
-            "required": [
-                "name"
-            ],
             "properties": {
-                "merchant_id": {
-                    "type": "integer"
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "name": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/adjustment_responses.AdjustmentResponse"
+                    }
+                },
+                "nextCursor": {
                     "type": "string"
                 }
             }
         },
-        "branch_responses.BranchResponse": {
+        "pagination.Page-auth_responses_APIKeyResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "merchant_id": {
-                    "type": "integer"
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "name": {
-                    "type": "string"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth_responses.APIKeyResponse"
+                    }
                 },
-                "updated_at": {
+                "nextCursor": {
                     "type": "string"
                 }
             }
         },
-        "branch_responses.BranchWithCampaignsResponse": {
+        "pagination.Page-branch_responses_BranchResponse": {
             "type": "object",
             "properties": {
-                "campaigns": {
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/branch_responses.CampaignResponse"
+                        "$ref": "#/definitions/branch_responses.BranchResponse"
                     }
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "merchant_id": {
-                    "type": "integer"
-                },
-                "name": {
+                "nextCursor": {
                     "type": "string"
                 }
             }
         },
-        "branch_responses.CampaignResponse": {
+        "pagination.Page-campaign_responses_CampaignResponse": {
             "type": "object",
             "properties": {
-                "end_date": {
-                    "type": "string"
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "min_amount": {
-                    "type": "number"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/campaign_responses.CampaignResponse"
+                    }
                 },
-                "start_date": {
+                "nextCursor": {
                     "type": "string"
+                }
+            }
+        },
+        "pagination.Page-catalog_responses_CatalogItemResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "type": {
-                    "type": "string"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/catalog_responses.CatalogItemResponse"
+                    }
                 },
-                "value": {
-                    "type": "number"
+                "nextCursor": {
+                    "type": "string"
                 }
             }
         },
-        "campaign_requests.CreateCampaignRequest": {
+        "pagination.Page-catalog_responses_ItemRedemptionResponse": {
             "type": "object",
-            "required": [
-                "merchantId",
-                "startDate",
-                "type",
-                "value"
-            ],
             "properties": {
-                "branchId": {
-                    "type": "integer"
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "endDate": {
-                    "type": "string"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/catalog_responses.ItemRedemptionResponse"
+                    }
                 },
-                "merchantId": {
-                    "type": "integer"
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "pagination.Page-challenge_responses_ChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "minAmount": {
-                    "type": "number"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenge_responses.ChallengeResponse"
+                    }
                 },
-                "startDate": {
+                "nextCursor": {
                     "type": "string"
+                }
+            }
+        },
+        "pagination.Page-loyalty_responses_ImportRowResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "type": {
-                    "type": "string"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/loyalty_responses.ImportRowResponse"
+                    }
                 },
-                "value": {
-                    "type": "number"
+                "nextCursor": {
+                    "type": "string"
                 }
             }
         },
-        "campaign_requests.UpdateCampaignRequest": {
+        "pagination.Page-merchant_responses_MerchantResponse": {
             "type": "object",
-            "required": [
-                "startDate",
-                "type",
-                "value"
-            ],
             "properties": {
-                "endDate": {
-                    "type": "string"
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "minAmount": {
-                    "type": "number"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/merchant_responses.MerchantResponse"
+                    }
                 },
-                "startDate": {
+                "nextCursor": {
                     "type": "string"
+                }
+            }
+        },
+        "pagination.Page-reward_responses_RewardResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "type": {
-                    "type": "string"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reward_responses.RewardResponse"
+                    }
                 },
-                "value": {
-                    "type": "number"
+                "nextCursor": {
+                    "type": "string"
                 }
             }
         },
-        "campaign_responses.CampaignResponse": {
+        "pagination.Page-transaction_responses_TransactionResponse": {
             "type": "object",
             "properties": {
-                "branchId": {
-                    "type": "integer"
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "endDate": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/transaction_responses.TransactionResponse"
+                    }
+                },
+                "nextCursor": {
                     "type": "string"
+                }
+            }
+        },
+        "pagination.Page-user_responses_UserResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "type": "integer"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_responses.UserResponse"
+                    }
                 },
-                "merchantId": {
-                    "type": "integer"
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "pagination.Page-webhook_responses_DeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "minAmount": {
-                    "type": "number"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook_responses.DeliveryResponse"
+                    }
                 },
-                "startDate": {
+                "nextCursor": {
                     "type": "string"
+                }
+            }
+        },
+        "pagination.Page-webhook_responses_WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
                 },
-                "type": {
-                    "type": "string"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook_responses.WebhookResponse"
+                    }
                 },
-                "value": {
-                    "type": "number"
+                "nextCursor": {
+                    "type": "string"
                 }
             }
         },
-        "loyalty_requests.ProcessTransactionRequest": {
+        "referral_requests.CreateReferralRequest": {
             "type": "object",
             "required": [
-                "amount",
-                "branchId",
-                "date",
+                "code",
                 "merchantId",
                 "userId"
             ],
             "properties": {
-                "amount": {
-                    "type": "number"
-                },
-                "branchId": {
-                    "type": "integer"
-                },
-                "date": {
-                    "type": "string"
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
                 },
                 "merchantId": {
                     "type": "integer"
@@ -1986,100 +7472,136 @@ const docTemplate = `{
                 }
             }
         },
-        "loyalty_requests.RedeemRewardsRequest": {
+        "referral_responses.ReferralCodeResponse": {
             "type": "object",
-            "required": [
-                "amount",
-                "merchantId",
-                "rewardType",
-                "userId"
-            ],
             "properties": {
-                "amount": {
-                    "type": "number"
-                },
-                "merchantId": {
-                    "type": "integer"
-                },
-                "rewardType": {
-                    "type": "string",
-                    "enum": [
-                        "points",
-                        "cashback"
-                    ]
+                "code": {
+                    "type": "string"
                 },
                 "userId": {
                     "type": "integer"
                 }
             }
         },
-        "merchant_requests.CreateMerchantRequest": {
+        "referral_responses.ReferralResponse": {
             "type": "object",
-            "required": [
-                "conversion_factor",
-                "name"
-            ],
             "properties": {
-                "conversion_factor": {
-                    "type": "number"
+                "createdAt": {
+                    "type": "string"
                 },
-                "name": {
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "qualifiedAt": {
+                    "type": "string"
+                },
+                "refereeId": {
+                    "type": "integer"
+                },
+                "referrerId": {
+                    "type": "integer"
+                },
+                "status": {
                     "type": "string"
+                },
+                "transactionId": {
+                    "type": "integer"
                 }
             }
         },
-        "merchant_requests.UpdateMerchantRequest": {
+        "referral_responses.ReferralStatusResponse": {
             "type": "object",
-            "required": [
-                "conversion_factor",
-                "name"
-            ],
             "properties": {
-                "conversion_factor": {
-                    "type": "number"
+                "pending": {
+                    "type": "integer"
                 },
-                "name": {
+                "qualified": {
+                    "type": "integer"
+                },
+                "referralCode": {
                     "type": "string"
+                },
+                "referrals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/referral_responses.ReferralResponse"
+                    }
+                },
+                "referredBy": {
+                    "$ref": "#/definitions/referral_responses.ReferralResponse"
+                },
+                "userId": {
+                    "type": "integer"
                 }
             }
         },
-        "merchant_responses.MerchantResponse": {
+        "reward_requests.CreateRewardRequest": {
             "type": "object",
+            "required": [
+                "amount",
+                "merchant_id",
+                "type",
+                "user_id"
+            ],
             "properties": {
-                "conversion_factor": {
+                "amount": {
                     "type": "number"
                 },
-                "defaultRewardType": {
+                "expiry_date": {
                     "type": "string"
                 },
-                "id": {
+                "merchant_id": {
                     "type": "integer"
                 },
-                "name": {
+                "type": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "reward_requests.CreateRewardRequest": {
+        "reward_responses.HoldResponse": {
             "type": "object",
-            "required": [
-                "amount",
-                "merchant_id",
-                "type",
-                "user_id"
-            ],
             "properties": {
                 "amount": {
                     "type": "number"
                 },
+                "branch_id": {
+                    "type": "integer"
+                },
+                "captured_amount": {
+                    "type": "number"
+                },
+                "captured_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "merchant_id": {
                     "type": "integer"
                 },
+                "status": {
+                    "type": "string"
+                },
                 "type": {
                     "type": "string"
                 },
                 "user_id": {
                     "type": "integer"
+                },
+                "voided_at": {
+                    "type": "string"
                 }
             }
         },
@@ -2089,17 +7611,30 @@ const docTemplate = `{
                 "amount": {
                     "type": "number"
                 },
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "merchant_id": {
                     "type": "integer"
                 },
+                "transaction_id": {
+                    "type": "integer"
+                },
                 "type": {
                     "type": "string"
                 },
                 "user_id": {
                     "type": "integer"
+                },
+                "vests_at": {
+                    "description": "VestsAt is set while the reward is pending and cannot be spent yet.",
+                    "type": "string"
                 }
             }
         },
@@ -2117,6 +7652,117 @@ const docTemplate = `{
                 }
             }
         },
+        "stamp_responses.StampCardResponse": {
+            "type": "object",
+            "properties": {
+                "campaignId": {
+                    "type": "integer"
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "itemRedemptionId": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "rewardId": {
+                    "type": "integer"
+                },
+                "stamps": {
+                    "type": "integer"
+                },
+                "stampsRequired": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                },
+                "voucherCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "threshold_responses.SpendProgressResponse": {
+            "type": "object",
+            "properties": {
+                "award": {
+                    "$ref": "#/definitions/threshold_responses.ThresholdAwardResponse"
+                },
+                "bonus": {
+                    "type": "number"
+                },
+                "branchId": {
+                    "type": "integer"
+                },
+                "campaignId": {
+                    "type": "integer"
+                },
+                "endDate": {
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "reached": {
+                    "type": "boolean"
+                },
+                "remaining": {
+                    "type": "number"
+                },
+                "rewardType": {
+                    "type": "string"
+                },
+                "spendThreshold": {
+                    "type": "number"
+                },
+                "spent": {
+                    "type": "number"
+                },
+                "startDate": {
+                    "type": "string"
+                }
+            }
+        },
+        "threshold_responses.ThresholdAwardResponse": {
+            "type": "object",
+            "properties": {
+                "campaignId": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "rewardId": {
+                    "type": "integer"
+                },
+                "spent": {
+                    "type": "number"
+                },
+                "transactionId": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
         "transaction_requests.CreateTransactionRequest": {
             "type": "object",
             "required": [
@@ -2135,6 +7781,14 @@ const docTemplate = `{
                 "date": {
                     "type": "string"
                 },
+                "external_ref": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "merchant_id": {
+                    "description": "MerchantID is optional: it defaults to the merchant of the branch and\nmust match it when given.",
+                    "type": "integer"
+                },
                 "user_id": {
                     "type": "integer"
                 }
@@ -2152,9 +7806,18 @@ const docTemplate = `{
                 "date": {
                     "type": "string"
                 },
+                "external_ref": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "merchant_id": {
+                    "type": "integer"
+                },
+                "reversed_at": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
                 }
@@ -2166,6 +7829,13 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "birthDate": {
+                    "description": "BirthDate is a date such as 1990-05-17.",
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 }
@@ -2177,6 +7847,10 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "birthDate": {
+                    "description": "BirthDate is a date such as 1990-05-17; leaving it out clears it.",
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 }
@@ -2219,6 +7893,9 @@ const docTemplate = `{
         "user_responses.UserResponse": {
             "type": "object",
             "properties": {
+                "birthDate": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -2266,6 +7943,156 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "webhook_requests.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "eventTypes",
+                "url"
+            ],
+            "properties": {
+                "eventTypes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries; a random one is generated when it is omitted.",
+                    "type": "string",
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook_requests.UpdateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "eventTypes",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook_responses.DeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastAttemptAt": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook_responses.IssuedWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook_responses.WebhookResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "merchantId": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Merchant API key or admin token. An \"Authorization: Bearer \u003ctoken\u003e\" header is also accepted.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -1,6 +1,9 @@
 package main
 
-import app "loyalty-campaigns/src"
+import (
+	app "loyalty-campaigns/src"
+	"os"
+)
 
 //	@title			Loyalty Campaigns API
 //	@version		1.0
@@ -8,6 +11,11 @@ import app "loyalty-campaigns/src"
 //	@host			localhost:7070
 //	@BasePath		/
 
+//	@securityDefinitions.apikey	ApiKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				Merchant API key or admin token. An "Authorization: Bearer <token>" header is also accepted.
+
 func main() {
-	app.Run()
+	os.Exit(app.Execute(os.Args[1:]))
 }
@@ -0,0 +1,228 @@
+package adjustment_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/adjustment/adjustment_domain/adjustment_ports"
+	"loyalty-campaigns/src/adjustment/adjustment_domain/adjustment_structs/adjustment_requests"
+	"loyalty-campaigns/src/adjustment/adjustment_domain/adjustment_structs/adjustment_responses"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"time"
+)
+
+// ErrSelfReview rejects the approval or rejection of an adjustment by the
+// operator who requested it.
+var ErrSelfReview = domain_errors.Forbidden("self_review", "an adjustment must be reviewed by an operator other than the one who requested it")
+
+// AdjustmentPolicy decides which adjustments need a second operator: those
+// whose amount exceeds ApprovalThreshold.
+type AdjustmentPolicy struct {
+	ApprovalThreshold float64
+}
+
+// AdjustmentPolicyFromEnv reads the policy from ADJUSTMENT_APPROVAL_THRESHOLD.
+func AdjustmentPolicyFromEnv() AdjustmentPolicy {
+	return AdjustmentPolicy{
+		ApprovalThreshold: configs.GetEnvFloat("ADJUSTMENT_APPROVAL_THRESHOLD", 100),
+	}
+}
+
+type IAdjustmentService interface {
+	// RequestAdjustment records a credit or debit of a user's balance. It is
+	// applied at once up to the approval threshold and left pending above it.
+	RequestAdjustment(ctx context.Context, req adjustment_requests.CreateAdjustmentRequest) (*adjustment_responses.AdjustmentResponse, error)
+	GetAdjustment(ctx context.Context, id uint) (*adjustment_responses.AdjustmentResponse, error)
+	ListAdjustments(ctx context.Context, req adjustment_requests.ListAdjustmentsRequest) (*pagination.Page[adjustment_responses.AdjustmentResponse], error)
+	ApproveAdjustment(ctx context.Context, id uint, req adjustment_requests.ReviewAdjustmentRequest) (*adjustment_responses.AdjustmentResponse, error)
+	RejectAdjustment(ctx context.Context, id uint, req adjustment_requests.ReviewAdjustmentRequest) (*adjustment_responses.AdjustmentResponse, error)
+}
+
+type adjustmentService struct {
+	adjustmentRepo adjustment_ports.IAdjustmentRepository
+	policy         AdjustmentPolicy
+	logger         utils.ILogger
+}
+
+func NewAdjustmentService(adjustmentRepo adjustment_ports.IAdjustmentRepository, policy AdjustmentPolicy) IAdjustmentService {
+	return &adjustmentService{
+		adjustmentRepo: adjustmentRepo,
+		policy:         policy,
+		logger:         utils.NewLogger(),
+	}
+}
+
+func (s *adjustmentService) RequestAdjustment(ctx context.Context, req adjustment_requests.CreateAdjustmentRequest) (*adjustment_responses.AdjustmentResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, req.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	requester := operatorOf(ctx)
+
+	adjustment := &models.Adjustment{
+		UserID:           req.UserID,
+		MerchantID:       req.MerchantID,
+		Direction:        req.Direction,
+		RewardType:       req.RewardType,
+		Amount:           req.Amount,
+		ReasonCode:       req.ReasonCode,
+		Comment:          req.Comment,
+		Status:           models.AdjustmentApplied,
+		RequestedByKeyID: requester.KeyID,
+		RequestedByRole:  requester.Role,
+	}
+	if req.Amount > s.policy.ApprovalThreshold {
+		adjustment.Status = models.AdjustmentPending
+	}
+
+	err = s.adjustmentRepo.Create(ctx, adjustment, time.Now())
+	if err != nil {
+		s.logger.Error("Error al registrar ajuste", err)
+		return nil, err
+	}
+
+	return adjustmentToResponse(adjustment, true), nil
+}
+
+func (s *adjustmentService) GetAdjustment(ctx context.Context, id uint) (*adjustment_responses.AdjustmentResponse, error) {
+	adjustment, err := s.adjustmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionRead, adjustment.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustmentToResponse(adjustment, true), nil
+}
+
+func (s *adjustmentService) ListAdjustments(ctx context.Context, req adjustment_requests.ListAdjustmentsRequest) (*pagination.Page[adjustment_responses.AdjustmentResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.adjustmentRepo.List(ctx, adjustment_ports.AdjustmentFilter{
+		MerchantID: merchantID,
+		UserID:     req.UserID,
+		Status:     req.Status,
+		ReasonCode: req.ReasonCode,
+	}, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar ajustes", err)
+		return nil, err
+	}
+
+	return pagination.Map(page, func(adjustment *models.Adjustment) adjustment_responses.AdjustmentResponse {
+		return *adjustmentToResponse(adjustment, false)
+	}), nil
+}
+
+func (s *adjustmentService) ApproveAdjustment(ctx context.Context, id uint, req adjustment_requests.ReviewAdjustmentRequest) (*adjustment_responses.AdjustmentResponse, error) {
+	reviewer, err := s.reviewer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment, err := s.adjustmentRepo.Approve(ctx, id, reviewer, req.Comment, time.Now())
+	if err != nil {
+		s.logger.Error("Error al aprobar ajuste", err)
+		return nil, err
+	}
+
+	return adjustmentToResponse(adjustment, true), nil
+}
+
+func (s *adjustmentService) RejectAdjustment(ctx context.Context, id uint, req adjustment_requests.ReviewAdjustmentRequest) (*adjustment_responses.AdjustmentResponse, error) {
+	reviewer, err := s.reviewer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment, err := s.adjustmentRepo.Reject(ctx, id, reviewer, req.Comment, time.Now())
+	if err != nil {
+		s.logger.Error("Error al rechazar ajuste", err)
+		return nil, err
+	}
+
+	return adjustmentToResponse(adjustment, true), nil
+}
+
+// reviewer checks that the caller may review the adjustment: it manages the
+// merchant and is not the operator who requested it. The platform admin token
+// has no key, so it cannot review the adjustments it requested either.
+func (s *adjustmentService) reviewer(ctx context.Context, id uint) (adjustment_ports.Operator, error) {
+	adjustment, err := s.adjustmentRepo.GetByID(ctx, id)
+	if err != nil {
+		return adjustment_ports.Operator{}, err
+	}
+
+	err = security.Authorize(ctx, security.ActionManage, adjustment.MerchantID, nil)
+	if err != nil {
+		return adjustment_ports.Operator{}, err
+	}
+
+	reviewer := operatorOf(ctx)
+	if sameKey(reviewer.KeyID, adjustment.RequestedByKeyID) {
+		return adjustment_ports.Operator{}, ErrSelfReview
+	}
+	return reviewer, nil
+}
+
+// operatorOf identifies the caller, which Authorize already required.
+func operatorOf(ctx context.Context) adjustment_ports.Operator {
+	principal, _ := security.PrincipalFromContext(ctx)
+	operator := adjustment_ports.Operator{Role: string(principal.Role)}
+	if principal.KeyID != 0 {
+		keyID := principal.KeyID
+		operator.KeyID = &keyID
+	}
+	return operator
+}
+
+func sameKey(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func adjustmentToResponse(adjustment *models.Adjustment, withAudit bool) *adjustment_responses.AdjustmentResponse {
+	response := &adjustment_responses.AdjustmentResponse{
+		ID:               adjustment.ID,
+		UserID:           adjustment.UserID,
+		MerchantID:       adjustment.MerchantID,
+		Direction:        adjustment.Direction,
+		RewardType:       adjustment.RewardType,
+		Amount:           adjustment.Amount,
+		ReasonCode:       adjustment.ReasonCode,
+		Comment:          adjustment.Comment,
+		Status:           adjustment.Status,
+		RequestedByKeyID: adjustment.RequestedByKeyID,
+		RequestedByRole:  adjustment.RequestedByRole,
+		ReviewedByKeyID:  adjustment.ReviewedByKeyID,
+		ReviewedByRole:   adjustment.ReviewedByRole,
+		ReviewComment:    adjustment.ReviewComment,
+		CreatedAt:        adjustment.CreatedAt,
+		ReviewedAt:       adjustment.ReviewedAt,
+		AppliedAt:        adjustment.AppliedAt,
+	}
+	if withAudit {
+		response.Audit = make([]adjustment_responses.AdjustmentAuditResponse, len(adjustment.AuditEntries))
+		for i, entry := range adjustment.AuditEntries {
+			response.Audit[i] = adjustment_responses.AdjustmentAuditResponse{
+				Action:    entry.Action,
+				KeyID:     entry.KeyID,
+				Role:      entry.Role,
+				Comment:   entry.Comment,
+				CreatedAt: entry.CreatedAt,
+			}
+		}
+	}
+	return response
+}
@@ -0,0 +1,13 @@
+package adjustment_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAdjustmentApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AdjustmentApp Suite")
+}
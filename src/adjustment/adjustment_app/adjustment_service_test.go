@@ -0,0 +1,221 @@
+package adjustment_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/adjustment/adjustment_app"
+	"loyalty-campaigns/src/adjustment/adjustment_domain/adjustment_ports"
+	"loyalty-campaigns/src/adjustment/adjustment_domain/adjustment_structs/adjustment_requests"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("AdjustmentService", func() {
+	var (
+		adjustmentService adjustment_app.IAdjustmentService
+		mockAdjustment    *mockAdjustmentRepository
+		merchantID        uint
+		adjustment        *models.Adjustment
+	)
+
+	BeforeEach(func() {
+		mockAdjustment = new(mockAdjustmentRepository)
+		adjustmentService = adjustment_app.NewAdjustmentService(mockAdjustment, adjustment_app.AdjustmentPolicy{ApprovalThreshold: 100})
+		merchantID = 4
+		adjustment = &models.Adjustment{
+			UserID:           1,
+			MerchantID:       merchantID,
+			Direction:        models.AdjustmentCredit,
+			RewardType:       "points",
+			Amount:           500,
+			ReasonCode:       models.AdjustmentReasonGoodwill,
+			Comment:          "Late delivery",
+			Status:           models.AdjustmentPending,
+			RequestedByKeyID: ptr(uint(21)),
+			RequestedByRole:  string(security.RoleMerchantAdmin),
+		}
+		adjustment.ID = 9
+	})
+
+	admin := func(keyID uint) context.Context {
+		return security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleMerchantAdmin,
+			KeyID:      keyID,
+			MerchantID: merchantID,
+		})
+	}
+
+	request := func(amount float64) adjustment_requests.CreateAdjustmentRequest {
+		return adjustment_requests.CreateAdjustmentRequest{
+			UserID:     1,
+			MerchantID: merchantID,
+			Direction:  models.AdjustmentDebit,
+			RewardType: "points",
+			Amount:     amount,
+			ReasonCode: models.AdjustmentReasonCorrection,
+			Comment:    "Duplicated purchase",
+		}
+	}
+
+	Describe("RequestAdjustment", func() {
+		BeforeEach(func() {
+			mockAdjustment.On("Create", mock.Anything, mock.AnythingOfType("*models.Adjustment"), mock.AnythingOfType("time.Time")).Return(nil)
+		})
+
+		It("should apply an adjustment up to the threshold at once", func() {
+			response, err := adjustmentService.RequestAdjustment(admin(21), request(100))
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.AdjustmentApplied))
+			created := mockAdjustment.Calls[0].Arguments.Get(1).(*models.Adjustment)
+			Expect(created.Direction).To(Equal(models.AdjustmentDebit))
+			Expect(created.ReasonCode).To(Equal(models.AdjustmentReasonCorrection))
+			Expect(created.RequestedByKeyID).To(Equal(ptr(uint(21))))
+			Expect(created.RequestedByRole).To(Equal(string(security.RoleMerchantAdmin)))
+		})
+
+		It("should leave an adjustment above the threshold pending", func() {
+			response, err := adjustmentService.RequestAdjustment(admin(21), request(100.5))
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.AdjustmentPending))
+		})
+
+		It("should record the platform admin token without a key", func() {
+			ctx := security.WithPrincipal(context.Background(), security.System())
+
+			_, err := adjustmentService.RequestAdjustment(ctx, request(10))
+
+			Expect(err).To(BeNil())
+			created := mockAdjustment.Calls[0].Arguments.Get(1).(*models.Adjustment)
+			Expect(created.RequestedByKeyID).To(BeNil())
+			Expect(created.RequestedByRole).To(Equal(string(security.RolePlatformAdmin)))
+		})
+
+		It("should not let branch operators adjust balances", func() {
+			branchID := uint(3)
+			ctx := security.WithPrincipal(context.Background(), &security.Principal{
+				Role:       security.RoleBranchOperator,
+				KeyID:      22,
+				MerchantID: merchantID,
+				BranchID:   &branchID,
+			})
+
+			_, err := adjustmentService.RequestAdjustment(ctx, request(10))
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockAdjustment.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("ApproveAdjustment", func() {
+		BeforeEach(func() {
+			mockAdjustment.On("GetByID", mock.Anything, adjustment.ID).Return(adjustment, nil)
+		})
+
+		It("should not let the requester approve its own adjustment", func() {
+			_, err := adjustmentService.ApproveAdjustment(admin(21), adjustment.ID, adjustment_requests.ReviewAdjustmentRequest{})
+
+			Expect(err).To(MatchError(adjustment_app.ErrSelfReview))
+			mockAdjustment.AssertNotCalled(GinkgoT(), "Approve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should apply the adjustment approved by another operator", func() {
+			approved := *adjustment
+			approved.Status = models.AdjustmentApplied
+			reviewer := adjustment_ports.Operator{KeyID: ptr(uint(22)), Role: string(security.RoleMerchantAdmin)}
+			mockAdjustment.On("Approve", mock.Anything, adjustment.ID, reviewer, "Checked", mock.AnythingOfType("time.Time")).Return(&approved, nil)
+
+			response, err := adjustmentService.ApproveAdjustment(admin(22), adjustment.ID, adjustment_requests.ReviewAdjustmentRequest{Comment: "Checked"})
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.AdjustmentApplied))
+		})
+
+		It("should not let the platform admin token approve the adjustments it requested", func() {
+			adjustment.RequestedByKeyID = nil
+			adjustment.RequestedByRole = string(security.RolePlatformAdmin)
+			ctx := security.WithPrincipal(context.Background(), security.System())
+
+			_, err := adjustmentService.ApproveAdjustment(ctx, adjustment.ID, adjustment_requests.ReviewAdjustmentRequest{})
+
+			Expect(err).To(MatchError(adjustment_app.ErrSelfReview))
+		})
+	})
+
+	Describe("RejectAdjustment", func() {
+		It("should reject the adjustment with the comment of the reviewer", func() {
+			rejected := *adjustment
+			rejected.Status = models.AdjustmentRejected
+			mockAdjustment.On("GetByID", mock.Anything, adjustment.ID).Return(adjustment, nil)
+			mockAdjustment.On("Reject", mock.Anything, adjustment.ID, mock.AnythingOfType("adjustment_ports.Operator"), "Not justified", mock.AnythingOfType("time.Time")).Return(&rejected, nil)
+
+			response, err := adjustmentService.RejectAdjustment(admin(22), adjustment.ID, adjustment_requests.ReviewAdjustmentRequest{Comment: "Not justified"})
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.AdjustmentRejected))
+		})
+
+		It("should not let another merchant review the adjustment", func() {
+			mockAdjustment.On("GetByID", mock.Anything, adjustment.ID).Return(adjustment, nil)
+			ctx := security.WithPrincipal(context.Background(), &security.Principal{
+				Role:       security.RoleMerchantAdmin,
+				KeyID:      30,
+				MerchantID: merchantID + 1,
+			})
+
+			_, err := adjustmentService.RejectAdjustment(ctx, adjustment.ID, adjustment_requests.ReviewAdjustmentRequest{})
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockAdjustment.AssertNotCalled(GinkgoT(), "Reject", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+})
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+type mockAdjustmentRepository struct {
+	mock.Mock
+}
+
+func (m *mockAdjustmentRepository) Create(ctx context.Context, adjustment *models.Adjustment, now time.Time) error {
+	args := m.Called(ctx, adjustment, now)
+	return args.Error(0)
+}
+
+func (m *mockAdjustmentRepository) GetByID(ctx context.Context, id uint) (*models.Adjustment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Adjustment), args.Error(1)
+}
+
+func (m *mockAdjustmentRepository) List(ctx context.Context, filter adjustment_ports.AdjustmentFilter, page pagination.Request) (*pagination.Page[models.Adjustment], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(*pagination.Page[models.Adjustment]), args.Error(1)
+}
+
+func (m *mockAdjustmentRepository) Approve(ctx context.Context, id uint, reviewer adjustment_ports.Operator, comment string, now time.Time) (*models.Adjustment, error) {
+	args := m.Called(ctx, id, reviewer, comment, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Adjustment), args.Error(1)
+}
+
+func (m *mockAdjustmentRepository) Reject(ctx context.Context, id uint, reviewer adjustment_ports.Operator, comment string, now time.Time) (*models.Adjustment, error) {
+	args := m.Called(ctx, id, reviewer, comment, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Adjustment), args.Error(1)
+}
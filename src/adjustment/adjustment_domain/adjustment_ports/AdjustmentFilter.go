@@ -0,0 +1,8 @@
+package adjustment_ports
+
+type AdjustmentFilter struct {
+	MerchantID *uint
+	UserID     *uint
+	Status     string
+	ReasonCode string
+}
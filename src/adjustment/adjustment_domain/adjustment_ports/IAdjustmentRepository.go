@@ -0,0 +1,36 @@
+package adjustment_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+var ErrAdjustmentNotPending = domain_errors.Conflict("adjustment_not_pending", "the adjustment was already applied or rejected")
+
+// Operator identifies who acts on an adjustment: the API key, nil for the
+// platform admin token, and its role.
+type Operator struct {
+	KeyID *uint
+	Role  string
+}
+
+type IAdjustmentRepository interface {
+	// Create records the adjustment with its audit trail and, when its status
+	// is applied, applies it in the same database transaction. A debit fails
+	// with reward_ports.ErrInsufficientBalance when the available balance does
+	// not cover it, and nothing is recorded.
+	Create(ctx context.Context, adjustment *models.Adjustment, now time.Time) error
+	// GetByID loads the adjustment with its audit trail.
+	GetByID(ctx context.Context, id uint) (*models.Adjustment, error)
+	List(ctx context.Context, filter AdjustmentFilter, page pagination.Request) (*pagination.Page[models.Adjustment], error)
+	// Approve locks a pending adjustment, records the review and applies it,
+	// or fails with ErrAdjustmentNotPending. A debit the balance no longer
+	// covers fails with reward_ports.ErrInsufficientBalance and stays pending.
+	Approve(ctx context.Context, id uint, reviewer Operator, comment string, now time.Time) (*models.Adjustment, error)
+	// Reject closes a pending adjustment without applying it, or fails with
+	// ErrAdjustmentNotPending.
+	Reject(ctx context.Context, id uint, reviewer Operator, comment string, now time.Time) (*models.Adjustment, error)
+}
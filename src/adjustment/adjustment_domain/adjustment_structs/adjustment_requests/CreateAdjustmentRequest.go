@@ -0,0 +1,11 @@
+package adjustment_requests
+
+type CreateAdjustmentRequest struct {
+	UserID     uint    `json:"userId" binding:"required"`
+	MerchantID uint    `json:"merchantId" binding:"required"`
+	Direction  string  `json:"direction" binding:"required,oneof=credit debit"`
+	RewardType string  `json:"rewardType" binding:"required,oneof=points cashback"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	ReasonCode string  `json:"reasonCode" binding:"required,oneof=goodwill service_issue correction migration fraud other"`
+	Comment    string  `json:"comment" binding:"required,max=500"`
+}
@@ -0,0 +1,12 @@
+package adjustment_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListAdjustmentsRequest accepts sort by id, amount or createdAt.
+type ListAdjustmentsRequest struct {
+	pagination.Request
+	MerchantID *uint  `form:"merchantId"`
+	UserID     *uint  `form:"userId"`
+	Status     string `form:"status" binding:"omitempty,oneof=pending applied rejected"`
+	ReasonCode string `form:"reasonCode" binding:"omitempty,oneof=goodwill service_issue correction migration fraud other"`
+}
@@ -0,0 +1,5 @@
+package adjustment_requests
+
+type ReviewAdjustmentRequest struct {
+	Comment string `json:"comment" binding:"max=500"`
+}
@@ -0,0 +1,33 @@
+package adjustment_responses
+
+import "time"
+
+type AdjustmentResponse struct {
+	ID               uint       `json:"id"`
+	UserID           uint       `json:"userId"`
+	MerchantID       uint       `json:"merchantId"`
+	Direction        string     `json:"direction"`
+	RewardType       string     `json:"rewardType"`
+	Amount           float64    `json:"amount"`
+	ReasonCode       string     `json:"reasonCode"`
+	Comment          string     `json:"comment"`
+	Status           string     `json:"status"`
+	RequestedByKeyID *uint      `json:"requestedByKeyId,omitempty"`
+	RequestedByRole  string     `json:"requestedByRole"`
+	ReviewedByKeyID  *uint      `json:"reviewedByKeyId,omitempty"`
+	ReviewedByRole   string     `json:"reviewedByRole,omitempty"`
+	ReviewComment    string     `json:"reviewComment,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	ReviewedAt       *time.Time `json:"reviewedAt,omitempty"`
+	AppliedAt        *time.Time `json:"appliedAt,omitempty"`
+	// Audit is only included for a single adjustment.
+	Audit []AdjustmentAuditResponse `json:"audit,omitempty"`
+}
+
+type AdjustmentAuditResponse struct {
+	Action    string    `json:"action"`
+	KeyID     *uint     `json:"keyId,omitempty"`
+	Role      string    `json:"role"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,215 @@
+package adjustment_controller
+
+import (
+	"loyalty-campaigns/src/adjustment/adjustment_app"
+	"loyalty-campaigns/src/adjustment/adjustment_domain/adjustment_structs/adjustment_requests"
+	"loyalty-campaigns/src/adjustment/adjustment_infra/adjustment_repository"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdjustmentController struct {
+	adjustmentService adjustment_app.IAdjustmentService
+}
+
+var (
+	adjustmentControllerInstance *AdjustmentController
+	adjustmentControllerOnce     sync.Once
+)
+
+func NewAdjustmentController(router *gin.RouterGroup) *AdjustmentController {
+	adjustmentControllerOnce.Do(func() {
+		adjustmentControllerInstance = &AdjustmentController{}
+		db := configs.NewDBConnection().GetDB()
+		adjustmentRepository := adjustment_repository.NewGormAdjustmentRepository(db)
+		adjustmentControllerInstance.adjustmentService = adjustment_app.NewAdjustmentService(adjustmentRepository, adjustment_app.AdjustmentPolicyFromEnv())
+		adjustmentControllerInstance.setupAdjustmentRoutes(router)
+	})
+	return adjustmentControllerInstance
+}
+
+func (c *AdjustmentController) setupAdjustmentRoutes(router *gin.RouterGroup) {
+	adjustmentGroup := router.Group("/adjustments")
+	{
+		adjustmentGroup.POST("", c.RequestAdjustment)
+		adjustmentGroup.GET("", c.ListAdjustments)
+		adjustmentGroup.GET("/:id", c.GetAdjustment)
+		adjustmentGroup.POST("/:id/approve", c.ApproveAdjustment)
+		adjustmentGroup.POST("/:id/reject", c.RejectAdjustment)
+	}
+}
+
+// RequestAdjustment godoc
+//
+//	@Summary		Request a balance adjustment
+//	@Description	Credit or debit a user's balance with a reason code and a comment. Adjustments up to the approval threshold are applied at once (status applied); larger ones stay pending until another operator approves them. Debits the available balance does not cover are rejected with insufficient_rewards (422).
+//	@Tags			adjustments
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		adjustment_requests.CreateAdjustmentRequest	true	"Adjustment"
+//	@Success		201		{object}	adjustment_responses.AdjustmentResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		422		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/adjustments [post]
+func (c *AdjustmentController) RequestAdjustment(ctx *gin.Context) {
+	var req adjustment_requests.CreateAdjustmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.adjustmentService.RequestAdjustment(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// ListAdjustments godoc
+//
+//	@Summary		List balance adjustments
+//	@Description	Get a page of balance adjustments filtered by merchant, user, status and reason code. Sort by id, amount or createdAt, prefixed with "-" for descending order; newest first by default.
+//	@Tags			adjustments
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	query		adjustment_requests.ListAdjustmentsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[adjustment_responses.AdjustmentResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/adjustments [get]
+func (c *AdjustmentController) ListAdjustments(ctx *gin.Context) {
+	var req adjustment_requests.ListAdjustmentsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.adjustmentService.ListAdjustments(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetAdjustment godoc
+//
+//	@Summary		Get a balance adjustment
+//	@Description	Get a balance adjustment by its ID, with its audit trail
+//	@Tags			adjustments
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Adjustment ID"
+//	@Success		200	{object}	adjustment_responses.AdjustmentResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/adjustments/{id} [get]
+func (c *AdjustmentController) GetAdjustment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.adjustmentService.GetAdjustment(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ApproveAdjustment godoc
+//
+//	@Summary		Approve a balance adjustment
+//	@Description	Approve a pending adjustment and apply it. The operator who requested it cannot approve it (self_review, 403).
+//	@Tags			adjustments
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int											true	"Adjustment ID"
+//	@Param			request	body		adjustment_requests.ReviewAdjustmentRequest	false	"Comment of the review"
+//	@Success		200		{object}	adjustment_responses.AdjustmentResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Failure		422		{object}	domain_errors.Problem
+//	@Router			/api/adjustments/{id}/approve [post]
+func (c *AdjustmentController) ApproveAdjustment(ctx *gin.Context) {
+	id, req, ok := c.bindReview(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := c.adjustmentService.ApproveAdjustment(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RejectAdjustment godoc
+//
+//	@Summary		Reject a balance adjustment
+//	@Description	Reject a pending adjustment without applying it. The operator who requested it cannot reject it (self_review, 403).
+//	@Tags			adjustments
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int											true	"Adjustment ID"
+//	@Param			request	body		adjustment_requests.ReviewAdjustmentRequest	false	"Comment of the review"
+//	@Success		200		{object}	adjustment_responses.AdjustmentResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Router			/api/adjustments/{id}/reject [post]
+func (c *AdjustmentController) RejectAdjustment(ctx *gin.Context) {
+	id, req, ok := c.bindReview(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := c.adjustmentService.RejectAdjustment(ctx.Request.Context(), id, req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// bindReview reads the adjustment ID and the optional review comment.
+func (c *AdjustmentController) bindReview(ctx *gin.Context) (uint, adjustment_requests.ReviewAdjustmentRequest, bool) {
+	var req adjustment_requests.ReviewAdjustmentRequest
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return 0, req, false
+	}
+
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.Error(domain_errors.InvalidRequest(err))
+			return 0, req, false
+		}
+	}
+	return uint(id), req, true
+}
@@ -0,0 +1,198 @@
+package adjustment_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/adjustment/adjustment_domain/adjustment_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormAdjustmentRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormAdjustmentRepository(db *gorm.DB) adjustment_ports.IAdjustmentRepository {
+	return &GormAdjustmentRepository{DB: db}
+}
+
+func (r *GormAdjustmentRepository) Create(ctx context.Context, adjustment *models.Adjustment, now time.Time) error {
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		err := tx.Omit(clause.Associations).Create(adjustment).Error
+		if err != nil {
+			return err
+		}
+		requester := adjustment_ports.Operator{KeyID: adjustment.RequestedByKeyID, Role: adjustment.RequestedByRole}
+		err = appendAudit(tx, adjustment, models.AdjustmentAuditRequested, requester, adjustment.Comment, now)
+		if err != nil {
+			return err
+		}
+		if adjustment.Status != models.AdjustmentApplied {
+			return nil
+		}
+		return applyAdjustment(tx, adjustment, requester, now)
+	})
+	return domain_errors.Translate(err, "adjustment")
+}
+
+func (r *GormAdjustmentRepository) GetByID(ctx context.Context, id uint) (*models.Adjustment, error) {
+	var adjustment models.Adjustment
+	err := configs.DB(ctx, r.DB).
+		Preload("AuditEntries", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&adjustment, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "adjustment")
+	}
+	return &adjustment, nil
+}
+
+var adjustmentSorting = pagination.Sorting[models.Adjustment]{
+	IDColumn: "id",
+	ID:       func(adjustment *models.Adjustment) uint { return adjustment.ID },
+	Fields: map[string]pagination.Key[models.Adjustment]{
+		"id":        {Column: "id", Value: func(adjustment *models.Adjustment) any { return adjustment.ID }},
+		"amount":    {Column: "amount", Value: func(adjustment *models.Adjustment) any { return adjustment.Amount }},
+		"createdAt": {Column: "created_at", Value: func(adjustment *models.Adjustment) any { return adjustment.CreatedAt }},
+	},
+	Default: "-createdAt",
+}
+
+func (r *GormAdjustmentRepository) List(ctx context.Context, filter adjustment_ports.AdjustmentFilter, page pagination.Request) (*pagination.Page[models.Adjustment], error) {
+	query := configs.DB(ctx, r.DB)
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filter.ReasonCode)
+	}
+	return pagination.Find(query, page, adjustmentSorting)
+}
+
+func (r *GormAdjustmentRepository) Approve(ctx context.Context, id uint, reviewer adjustment_ports.Operator, comment string, now time.Time) (*models.Adjustment, error) {
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		adjustment, err := lockPendingAdjustment(tx, id)
+		if err != nil {
+			return err
+		}
+		err = reviewAdjustment(tx, adjustment, models.AdjustmentAuditApproved, reviewer, comment, now)
+		if err != nil {
+			return err
+		}
+		return applyAdjustment(tx, adjustment, reviewer, now)
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "adjustment")
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *GormAdjustmentRepository) Reject(ctx context.Context, id uint, reviewer adjustment_ports.Operator, comment string, now time.Time) (*models.Adjustment, error) {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		adjustment, err := lockPendingAdjustment(tx, id)
+		if err != nil {
+			return err
+		}
+		adjustment.Status = models.AdjustmentRejected
+		return reviewAdjustment(tx, adjustment, models.AdjustmentAuditRejected, reviewer, comment, now)
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "adjustment")
+	}
+	return r.GetByID(ctx, id)
+}
+
+func lockPendingAdjustment(tx *gorm.DB, id uint) (*models.Adjustment, error) {
+	var adjustment models.Adjustment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&adjustment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	if adjustment.Status != models.AdjustmentPending {
+		return nil, adjustment_ports.ErrAdjustmentNotPending
+	}
+	return &adjustment, nil
+}
+
+// reviewAdjustment records the reviewer of the adjustment and its decision.
+func reviewAdjustment(tx *gorm.DB, adjustment *models.Adjustment, action string, reviewer adjustment_ports.Operator, comment string, now time.Time) error {
+	adjustment.ReviewedByKeyID = reviewer.KeyID
+	adjustment.ReviewedByRole = reviewer.Role
+	adjustment.ReviewComment = comment
+	adjustment.ReviewedAt = &now
+	err := tx.Omit(clause.Associations).Save(adjustment).Error
+	if err != nil {
+		return err
+	}
+	return appendAudit(tx, adjustment, action, reviewer, comment, now)
+}
+
+// applyAdjustment changes the user's balance through the rewards ledger: a
+// credit is a new reward, valid for the merchant's reward validity, and a
+// debit consumes the available rewards as a redemption does.
+func applyAdjustment(tx *gorm.DB, adjustment *models.Adjustment, operator adjustment_ports.Operator, now time.Time) error {
+	if adjustment.Direction == models.AdjustmentDebit {
+		err := reward_repository.DebitRewards(tx, adjustment)
+		if err != nil {
+			return err
+		}
+	} else {
+		var merchant models.Merchant
+		err := tx.First(&merchant, adjustment.MerchantID).Error
+		if err != nil {
+			return err
+		}
+		credit := &models.Reward{
+			UserID:       adjustment.UserID,
+			MerchantID:   adjustment.MerchantID,
+			Type:         adjustment.RewardType,
+			Amount:       adjustment.Amount,
+			AdjustmentID: &adjustment.ID,
+		}
+		if merchant.RewardValidityDays != nil {
+			expiry := now.AddDate(0, 0, *merchant.RewardValidityDays)
+			credit.ExpiryDate = &expiry
+		}
+		err = reward_repository.GrantReward(tx, credit, events.RewardCredited)
+		if err != nil {
+			return err
+		}
+	}
+
+	adjustment.Status = models.AdjustmentApplied
+	adjustment.AppliedAt = &now
+	err := tx.Omit(clause.Associations).Save(adjustment).Error
+	if err != nil {
+		return err
+	}
+	return appendAudit(tx, adjustment, models.AdjustmentAuditApplied, operator, "", now)
+}
+
+func appendAudit(tx *gorm.DB, adjustment *models.Adjustment, action string, operator adjustment_ports.Operator, comment string, now time.Time) error {
+	entry := models.AdjustmentAuditEntry{
+		CreatedAt:    now,
+		AdjustmentID: adjustment.ID,
+		Action:       action,
+		KeyID:        operator.KeyID,
+		Role:         operator.Role,
+		Comment:      comment,
+	}
+	err := tx.Create(&entry).Error
+	if err != nil {
+		return err
+	}
+	adjustment.AuditEntries = append(adjustment.AuditEntries, entry)
+	return nil
+}
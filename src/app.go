@@ -1,16 +1,34 @@
 package src
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"loyalty-campaigns/src/adjustment/adjustment_infra/adjustment_controller"
+	"loyalty-campaigns/src/auth/auth_infra/auth_controller"
+	"loyalty-campaigns/src/auth/auth_infra/auth_middleware"
 	"loyalty-campaigns/src/branch/branch_infra/branch_controller"
 	"loyalty-campaigns/src/campaign/campaign_infra/campaign_controller"
+	"loyalty-campaigns/src/catalog/catalog_infra/catalog_controller"
+	"loyalty-campaigns/src/challenge/challenge_infra/challenge_controller"
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/metrics"
 	"loyalty-campaigns/src/common/utils"
+	"loyalty-campaigns/src/health/health_infra/health_controller"
 	"loyalty-campaigns/src/loyalty/loyalty_infra/loyalty_controller"
 	"loyalty-campaigns/src/merchant/merchant_infra/merchant_controller"
+	"loyalty-campaigns/src/referral/referral_infra/referral_controller"
 	"loyalty-campaigns/src/reward/reward_infra/reward_controller"
+	"loyalty-campaigns/src/stamp/stamp_infra/stamp_controller"
+	"loyalty-campaigns/src/threshold/threshold_infra/threshold_controller"
 	"loyalty-campaigns/src/transaction/transaction_infra/transaction_controller"
 	"loyalty-campaigns/src/user/user_infra/user_controller"
+	"loyalty-campaigns/src/webhook/webhook_infra/webhook_controller"
+	"net"
+	"net/http"
+	"os"
 	"time"
 
 	_ "loyalty-campaigns/docs"
@@ -25,43 +43,112 @@ import (
 
 var logger = utils.NewLogger()
 
-func Run() {
-	dbConnection := configs.NewDBConnection()
-	defer dbConnection.Close()
+const (
+	serverAddress   = "127.0.0.1:7070"
+	shutdownTimeout = 15 * time.Second
+)
 
-	err := configs.Migrate(dbConnection.GetDB())
+// serve starts the HTTP server and the background jobs and blocks until the
+// process receives SIGINT or SIGTERM.
+func serve(ctx context.Context, c *container, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "serve takes no arguments\n\n%s", usage)
+		return exitUsage
+	}
+	if !requireSchema(ctx, c) {
+		return exitFailure
+	}
+	logger.Info("[OK] database schema is up to date")
+
+	err := metrics.RegisterDBStats(c.dbConnection.GetDB())
 	if err != nil {
-		log.Fatalf("failed to run migrations: %v", err)
+		logger.Error("failed to register database metrics: %v", err)
+		return exitFailure
 	}
-	logger.Info("[OK] Migrations completed")
 
 	gin.SetMode("debug")
 	router := gin.Default()
 	addCORSConfig(router)
+	router.Use(metrics.Middleware())
+	router.Use(domain_errors.Middleware())
+
+	healthController := health_controller.NewHealthController(router, c.dbConnection, c.migrator, c.scheduler)
 
 	// Register controllers
-	user_controller.NewUserController(router)
-	merchant_controller.NewMerchantController(router)
-	branch_controller.NewBranchController(router)
-	campaign_controller.NewCampaignController(router)
-	reward_controller.NewRewardController(router)
-	transaction_controller.NewTransactionController(router)
-	loyalty_controller.NewLoyaltyController(router)
+	api := router.Group("/api", auth_middleware.Authenticate())
+	auth_controller.NewAuthController(api)
+	user_controller.NewUserController(api)
+	merchant_controller.NewMerchantController(api)
+	branch_controller.NewBranchController(api)
+	campaign_controller.NewCampaignController(api)
+	reward_controller.NewRewardController(api)
+	transaction_controller.NewTransactionController(api)
+	loyalty_controller.NewLoyaltyController(api)
+	webhook_controller.NewWebhookController(api)
+	catalog_controller.NewCatalogController(api)
+	adjustment_controller.NewAdjustmentController(api)
+	referral_controller.NewReferralController(api)
+	stamp_controller.NewStampController(api)
+	threshold_controller.NewThresholdController(api)
+	challenge_controller.NewChallengeController(api)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/metrics", metrics.Handler())
+
+	c.scheduler.Start(ctx)
+	defer c.scheduler.Stop()
+
+	listener, err := net.Listen("tcp", serverAddress)
+	if err != nil {
+		logger.Error("failed to listen on %s: %v", serverAddress, err)
+		return exitFailure
+	}
+
+	server := &http.Server{Handler: router}
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server stopped unexpectedly: %v", err)
+		}
+	}()
 
-	router.Run("127.0.0.1:7070")
+	healthController.SetReady(true)
+	logger.Success("[OK] server listening on %s", serverAddress)
 
+	<-ctx.Done()
+
+	healthController.SetReady(false)
+	logger.Info("shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		logger.Error("failed to shut down server gracefully: %v", err)
+		return exitFailure
+	}
+
+	return exitOK
 }
 
+// addCORSConfig only allows credentialed requests from the origins listed in
+// CORS_ALLOWED_ORIGINS; without it any origin may call the API, but without credentials.
 func addCORSConfig(serverInstance *gin.Engine) {
-	corsMiddleware := cors.New(cors.Config{
-		AllowAllOrigins:  true,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	})
-	serverInstance.Use(corsMiddleware)
+	corsConfig := cors.Config{
+		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "X-API-Key"},
+		ExposeHeaders: []string{"Content-Length"},
+		MaxAge:        12 * time.Hour,
+	}
+
+	allowedOrigins := configs.GetEnvList("CORS_ALLOWED_ORIGINS")
+	if len(allowedOrigins) > 0 {
+		corsConfig.AllowOrigins = allowedOrigins
+		corsConfig.AllowCredentials = true
+	} else {
+		corsConfig.AllowAllOrigins = true
+	}
+
+	serverInstance.Use(cors.New(corsConfig))
 }
@@ -0,0 +1,275 @@
+package auth_app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"loyalty-campaigns/src/auth/auth_domain/auth_ports"
+	"loyalty-campaigns/src/auth/auth_domain/auth_structs/auth_requests"
+	"loyalty-campaigns/src/auth/auth_domain/auth_structs/auth_responses"
+	"loyalty-campaigns/src/branch/branch_domain/branch_ports"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyScheme        = "lc"
+	lastUsedGranularity = time.Minute
+)
+
+var (
+	ErrInvalidCredentials = domain_errors.Unauthenticated("invalid_credentials", "invalid credentials")
+	ErrAPIKeyRevoked      = domain_errors.Unauthenticated("api_key_revoked", "API key has been revoked")
+	ErrAPIKeyNotActive    = domain_errors.Conflict("api_key_not_active", "api key has been revoked and cannot be rotated")
+	ErrBranchNotOwned     = domain_errors.Validation("branch_not_owned", "branch does not belong to the merchant")
+	ErrBranchRequired     = domain_errors.Validation("branch_required", "branch operator keys must be bound to a branch")
+)
+
+type IAuthService interface {
+	CreateAPIKey(ctx context.Context, merchantID uint, req auth_requests.CreateAPIKeyRequest) (*auth_responses.IssuedAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[auth_responses.APIKeyResponse], error)
+	RotateAPIKey(ctx context.Context, id uint) (*auth_responses.IssuedAPIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+	Authenticate(ctx context.Context, token string) (*security.Principal, error)
+}
+
+type authService struct {
+	apiKeyRepo     auth_ports.IAPIKeyRepository
+	branchRepo     branch_ports.IBranchRepository
+	adminTokenHash []byte
+	logger         utils.ILogger
+}
+
+// NewAuthService builds the service; an empty adminToken disables admin access.
+func NewAuthService(apiKeyRepo auth_ports.IAPIKeyRepository, branchRepo branch_ports.IBranchRepository, adminToken string) IAuthService {
+	service := &authService{
+		apiKeyRepo: apiKeyRepo,
+		branchRepo: branchRepo,
+		logger:     utils.NewLogger(),
+	}
+	if adminToken != "" {
+		service.adminTokenHash = hashToken(adminToken)
+	} else {
+		service.logger.Warn("ADMIN_API_TOKEN is not set, admin routes are disabled")
+	}
+	return service
+}
+
+func (s *authService) CreateAPIKey(ctx context.Context, merchantID uint, req auth_requests.CreateAPIKeyRequest) (*auth_responses.IssuedAPIKeyResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, merchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	role := security.Role(req.Role)
+	if role == "" {
+		role = security.RoleMerchantAdmin
+		if req.BranchID != nil {
+			role = security.RoleBranchOperator
+		}
+	}
+	if role == security.RoleBranchOperator && req.BranchID == nil {
+		return nil, ErrBranchRequired
+	}
+
+	if req.BranchID != nil {
+		branch, err := s.branchRepo.GetByID(ctx, *req.BranchID)
+		if err != nil {
+			s.logger.Error("Error al obtener sucursal para la llave de API: %v", err)
+			return nil, err
+		}
+		if branch.MerchantID != merchantID {
+			return nil, ErrBranchNotOwned
+		}
+	}
+
+	return s.issue(ctx, merchantID, req.BranchID, req.Name, role)
+}
+
+func (s *authService) ListAPIKeys(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[auth_responses.APIKeyResponse], error) {
+	err := security.Authorize(ctx, security.ActionManage, merchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.apiKeyRepo.ListByMerchant(ctx, merchantID, page)
+	if err != nil {
+		s.logger.Error("Error al listar llaves de API: %v", err)
+		return nil, err
+	}
+
+	return pagination.Map(apiKeys, func(apiKey *models.APIKey) auth_responses.APIKeyResponse {
+		return *mapAPIKeyToResponse(apiKey)
+	}), nil
+}
+
+// RotateAPIKey issues a replacement key with the same scope and revokes the old one.
+func (s *authService) RotateAPIKey(ctx context.Context, id uint) (*auth_responses.IssuedAPIKeyResponse, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener llave de API para rotar: %v", err)
+		return nil, err
+	}
+	err = security.Authorize(ctx, security.ActionManage, apiKey.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevokedAt != nil {
+		return nil, ErrAPIKeyNotActive
+	}
+
+	issued, err := s.issue(ctx, apiKey.MerchantID, apiKey.BranchID, apiKey.Name, security.Role(apiKey.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.apiKeyRepo.Revoke(ctx, apiKey.ID, time.Now())
+	if err != nil {
+		s.logger.Error("Error al revocar llave de API rotada: %v", err)
+		return nil, err
+	}
+
+	return issued, nil
+}
+
+func (s *authService) RevokeAPIKey(ctx context.Context, id uint) error {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener llave de API para revocar: %v", err)
+		return err
+	}
+	err = security.Authorize(ctx, security.ActionManage, apiKey.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.apiKeyRepo.Revoke(ctx, id, time.Now())
+	if err != nil {
+		s.logger.Error("Error al revocar llave de API: %v", err)
+	}
+	return err
+}
+
+func (s *authService) Authenticate(ctx context.Context, token string) (*security.Principal, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if s.adminTokenHash != nil && subtle.ConstantTimeCompare(hashToken(token), s.adminTokenHash) == 1 {
+		return &security.Principal{Role: security.RolePlatformAdmin}, nil
+	}
+
+	prefix, ok := parsePrefix(token)
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	storedHash, err := hex.DecodeString(apiKey.KeyHash)
+	if err != nil || subtle.ConstantTimeCompare(hashToken(token), storedHash) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	if apiKey.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > lastUsedGranularity {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+			s.logger.Warn("No se pudo actualizar el último uso de la llave de API %d: %v", apiKey.ID, err)
+		}
+	}
+
+	return &security.Principal{
+		Role:       security.Role(apiKey.Role),
+		KeyID:      apiKey.ID,
+		MerchantID: apiKey.MerchantID,
+		BranchID:   apiKey.BranchID,
+	}, nil
+}
+
+func (s *authService) issue(ctx context.Context, merchantID uint, branchID *uint, name string, role security.Role) (*auth_responses.IssuedAPIKeyResponse, error) {
+	prefix, token, err := generateToken()
+	if err != nil {
+		s.logger.Error("Error al generar llave de API: %v", err)
+		return nil, err
+	}
+
+	apiKey := &models.APIKey{
+		MerchantID: merchantID,
+		BranchID:   branchID,
+		Name:       name,
+		Role:       string(role),
+		Prefix:     prefix,
+		KeyHash:    hex.EncodeToString(hashToken(token)),
+	}
+
+	err = s.apiKeyRepo.Create(ctx, apiKey)
+	if err != nil {
+		s.logger.Error("Error al crear llave de API: %v", err)
+		return nil, err
+	}
+
+	return &auth_responses.IssuedAPIKeyResponse{
+		APIKeyResponse: *mapAPIKeyToResponse(apiKey),
+		Key:            token,
+	}, nil
+}
+
+// generateToken returns a key of the form lc_<prefix>_<secret>. The prefix is
+// stored in clear to find the key; only a SHA-256 hash of the whole token is kept.
+func generateToken() (prefix string, token string, err error) {
+	prefixBytes := make([]byte, 6)
+	if _, err = rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+	secretBytes := make([]byte, 32)
+	if _, err = rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	token = fmt.Sprintf("%s_%s_%s", apiKeyScheme, prefix, base64.RawURLEncoding.EncodeToString(secretBytes))
+	return prefix, token, nil
+}
+
+func parsePrefix(token string) (string, bool) {
+	parts := strings.SplitN(token, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func mapAPIKeyToResponse(apiKey *models.APIKey) *auth_responses.APIKeyResponse {
+	return &auth_responses.APIKeyResponse{
+		ID:         apiKey.ID,
+		MerchantID: apiKey.MerchantID,
+		BranchID:   apiKey.BranchID,
+		Name:       apiKey.Name,
+		Role:       apiKey.Role,
+		Prefix:     apiKey.Prefix,
+		CreatedAt:  apiKey.CreatedAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package auth_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAuthApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AuthApp Suite")
+}
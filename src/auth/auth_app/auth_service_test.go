@@ -0,0 +1,220 @@
+package auth_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/auth/auth_app"
+	"loyalty-campaigns/src/auth/auth_domain/auth_structs/auth_requests"
+	"loyalty-campaigns/src/branch/branch_domain/branch_ports"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("AuthService", func() {
+	const adminToken = "super-secret-admin-token"
+
+	var (
+		authService auth_app.IAuthService
+		mockAPIKeys *mockAPIKeyRepository
+		mockBranch  *mockBranchRepository
+		merchantID  uint
+		stored      *models.APIKey
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		mockAPIKeys = new(mockAPIKeyRepository)
+		mockBranch = new(mockBranchRepository)
+		authService = auth_app.NewAuthService(mockAPIKeys, mockBranch, adminToken)
+		merchantID = 7
+		stored = nil
+		ctx = security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleMerchantAdmin,
+			MerchantID: merchantID,
+		})
+
+		mockAPIKeys.On("Create", mock.Anything, mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*models.APIKey)
+			stored.ID = 1
+		}).Return(nil).Maybe()
+	})
+
+	Describe("CreateAPIKey", func() {
+		It("should return the plain key once and only store its hash", func() {
+			response, err := authService.CreateAPIKey(ctx, merchantID, auth_requests.CreateAPIKeyRequest{Name: "POS"})
+
+			Expect(err).To(BeNil())
+			Expect(response.Key).To(HavePrefix("lc_" + response.Prefix + "_"))
+			Expect(stored.Prefix).To(Equal(response.Prefix))
+			Expect(stored.KeyHash).NotTo(ContainSubstring(strings.Split(response.Key, "_")[2]))
+			Expect(stored.MerchantID).To(Equal(merchantID))
+		})
+
+		It("should reject a branch owned by another merchant", func() {
+			branchID := uint(3)
+			mockBranch.On("GetByID", mock.Anything, branchID).Return(&models.Branch{MerchantID: 99}, nil)
+
+			_, err := authService.CreateAPIKey(ctx, merchantID, auth_requests.CreateAPIKeyRequest{Name: "POS", BranchID: &branchID})
+
+			Expect(err).To(MatchError(auth_app.ErrBranchNotOwned))
+			mockAPIKeys.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything)
+		})
+
+		It("should not let a merchant issue keys for another merchant", func() {
+			_, err := authService.CreateAPIKey(ctx, 99, auth_requests.CreateAPIKeyRequest{Name: "POS"})
+
+			Expect(err).To(MatchError(security.ErrForbidden))
+			mockAPIKeys.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("Authenticate", func() {
+		It("should recognise the admin token", func() {
+			principal, err := authService.Authenticate(ctx, adminToken)
+
+			Expect(err).To(BeNil())
+			Expect(principal.IsAdmin()).To(BeTrue())
+		})
+
+		Context("With an issued branch key", func() {
+			var token string
+
+			BeforeEach(func() {
+				branchID := uint(3)
+				mockBranch.On("GetByID", mock.Anything, branchID).Return(&models.Branch{MerchantID: merchantID}, nil)
+				response, err := authService.CreateAPIKey(ctx, merchantID, auth_requests.CreateAPIKeyRequest{Name: "POS", BranchID: &branchID})
+				Expect(err).To(BeNil())
+				token = response.Key
+
+				mockAPIKeys.On("GetByPrefix", mock.Anything, response.Prefix).Return(stored, nil)
+				mockAPIKeys.On("TouchLastUsed", mock.Anything, uint(1), mock.AnythingOfType("time.Time")).Return(nil).Maybe()
+			})
+
+			It("should resolve the merchant and branch scope", func() {
+				principal, err := authService.Authenticate(ctx, token)
+
+				Expect(err).To(BeNil())
+				Expect(principal.Role).To(Equal(security.RoleBranchOperator))
+				Expect(principal.MerchantID).To(Equal(merchantID))
+				Expect(*principal.BranchID).To(Equal(uint(3)))
+			})
+
+			It("should reject a tampered secret", func() {
+				_, err := authService.Authenticate(ctx, token+"x")
+
+				Expect(err).To(MatchError(auth_app.ErrInvalidCredentials))
+			})
+
+			It("should reject a revoked key", func() {
+				revokedAt := time.Now()
+				stored.RevokedAt = &revokedAt
+
+				_, err := authService.Authenticate(ctx, token)
+
+				Expect(err).To(MatchError(auth_app.ErrAPIKeyRevoked))
+				Expect(err).To(MatchError(domain_errors.ErrUnauthenticated))
+			})
+		})
+
+		It("should reject malformed tokens", func() {
+			_, err := authService.Authenticate(ctx, "not-a-key")
+
+			Expect(err).To(MatchError(auth_app.ErrInvalidCredentials))
+		})
+	})
+
+	Describe("RotateAPIKey", func() {
+		It("should not rotate a revoked key", func() {
+			revokedAt := time.Now()
+			mockAPIKeys.On("GetByID", mock.Anything, uint(1)).Return(&models.APIKey{MerchantID: merchantID, RevokedAt: &revokedAt}, nil)
+
+			_, err := authService.RotateAPIKey(ctx, 1)
+
+			Expect(err).To(MatchError(auth_app.ErrAPIKeyNotActive))
+			Expect(err).To(MatchError(domain_errors.ErrConflict))
+			mockAPIKeys.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything)
+		})
+	})
+})
+
+// Mock implementations
+type mockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *mockAPIKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	args := m.Called(ctx, apiKey)
+	return args.Error(0)
+}
+
+func (m *mockAPIKeyRepository) GetByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *mockAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	args := m.Called(ctx, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *mockAPIKeyRepository) ListByMerchant(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[models.APIKey], error) {
+	args := m.Called(ctx, merchantID, page)
+	return args.Get(0).(*pagination.Page[models.APIKey]), args.Error(1)
+}
+
+func (m *mockAPIKeyRepository) Revoke(ctx context.Context, id uint, revokedAt time.Time) error {
+	args := m.Called(ctx, id, revokedAt)
+	return args.Error(0)
+}
+
+func (m *mockAPIKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	args := m.Called(ctx, id, usedAt)
+	return args.Error(0)
+}
+
+type mockBranchRepository struct {
+	mock.Mock
+}
+
+func (m *mockBranchRepository) Create(ctx context.Context, branch *models.Branch) error {
+	args := m.Called(ctx, branch)
+	return args.Error(0)
+}
+
+func (m *mockBranchRepository) GetByID(ctx context.Context, id uint) (*models.Branch, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Branch), args.Error(1)
+}
+
+func (m *mockBranchRepository) Update(ctx context.Context, branch *models.Branch) error {
+	args := m.Called(ctx, branch)
+	return args.Error(0)
+}
+
+func (m *mockBranchRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockBranchRepository) List(ctx context.Context, filter branch_ports.BranchFilter, page pagination.Request) (*pagination.Page[models.Branch], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(*pagination.Page[models.Branch]), args.Error(1)
+}
+
+func (m *mockBranchRepository) GetBranchWithCampaigns(ctx context.Context, id uint) (*models.Branch, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.Branch), args.Error(1)
+}
@@ -0,0 +1,17 @@
+package auth_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+type IAPIKeyRepository interface {
+	Create(ctx context.Context, apiKey *models.APIKey) error
+	GetByID(ctx context.Context, id uint) (*models.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	ListByMerchant(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[models.APIKey], error)
+	Revoke(ctx context.Context, id uint, revokedAt time.Time) error
+	TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error
+}
@@ -0,0 +1,8 @@
+package auth_requests
+
+type CreateAPIKeyRequest struct {
+	Name     string `json:"name" binding:"required"`
+	BranchID *uint  `json:"branchId"`
+	// Role defaults to branch_operator for branch keys and merchant_admin otherwise.
+	Role string `json:"role" binding:"omitempty,oneof=merchant_admin branch_operator analyst"`
+}
@@ -0,0 +1,21 @@
+package auth_responses
+
+import "time"
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	MerchantID uint       `json:"merchantId"`
+	BranchID   *uint      `json:"branchId"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// IssuedAPIKeyResponse carries the plain text key, which is only shown once.
+type IssuedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,180 @@
+package auth_controller
+
+import (
+	"loyalty-campaigns/src/auth/auth_app"
+	"loyalty-campaigns/src/auth/auth_domain/auth_structs/auth_requests"
+	"loyalty-campaigns/src/auth/auth_infra/auth_repository"
+	"loyalty-campaigns/src/branch/branch_infra/branch_repository"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/pagination"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	authService auth_app.IAuthService
+}
+
+var (
+	authControllerInstance *AuthController
+	authControllerOnce     sync.Once
+)
+
+func NewAuthController(router *gin.RouterGroup) *AuthController {
+	authControllerOnce.Do(func() {
+		authControllerInstance = &AuthController{}
+		db := configs.NewDBConnection().GetDB()
+		apiKeyRepository := auth_repository.NewGormAPIKeyRepository(db)
+		branchRepository := branch_repository.NewGormBranchRepository(db)
+		authControllerInstance.authService = auth_app.NewAuthService(
+			apiKeyRepository,
+			branchRepository,
+			configs.GetEnv("ADMIN_API_TOKEN", ""),
+		)
+		authControllerInstance.setupAuthRoutes(router)
+	})
+	return authControllerInstance
+}
+
+func (c *AuthController) setupAuthRoutes(router *gin.RouterGroup) {
+	router.POST("/merchants/:id/api-keys", c.CreateAPIKey)
+	router.GET("/merchants/:id/api-keys", c.ListAPIKeys)
+	router.POST("/api-keys/:id/rotate", c.RotateAPIKey)
+	router.DELETE("/api-keys/:id", c.RevokeAPIKey)
+}
+
+// CreateAPIKey godoc
+//
+//	@Summary		Issue an API key
+//	@Description	Issue a new API key for a merchant, optionally restricted to one of its branches, with a merchant_admin, branch_operator or analyst role. The key is only returned once.
+//	@Tags			api-keys
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int									true	"Merchant ID"
+//	@Param			request	body		auth_requests.CreateAPIKeyRequest	true	"API key creation request"
+//	@Success		201		{object}	auth_responses.IssuedAPIKeyResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/merchants/{id}/api-keys [post]
+func (c *AuthController) CreateAPIKey(ctx *gin.Context) {
+	merchantID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_merchant_id", "Invalid merchant ID"))
+		return
+	}
+
+	var req auth_requests.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.authService.CreateAPIKey(ctx.Request.Context(), uint(merchantID), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// ListAPIKeys godoc
+//
+//	@Summary		List API keys of a merchant
+//	@Description	Get a page of the API keys issued for a merchant, including revoked ones. Sort by id, name or createdAt, prefixed with "-" for descending order.
+//	@Tags			api-keys
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"Merchant ID"
+//	@Param			request	query		pagination.Request	false	"Sort and pagination"
+//	@Success		200		{object}	pagination.Page[auth_responses.APIKeyResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/merchants/{id}/api-keys [get]
+func (c *AuthController) ListAPIKeys(ctx *gin.Context) {
+	merchantID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_merchant_id", "Invalid merchant ID"))
+		return
+	}
+
+	var req pagination.Request
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.authService.ListAPIKeys(ctx.Request.Context(), uint(merchantID), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// RotateAPIKey godoc
+//
+//	@Summary		Rotate an API key
+//	@Description	Issue a replacement key with the same scope and revoke the current one
+//	@Tags			api-keys
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"API key ID"
+//	@Success		201	{object}	auth_responses.IssuedAPIKeyResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		409	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
+//	@Router			/api/api-keys/{id}/rotate [post]
+func (c *AuthController) RotateAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.authService.RotateAPIKey(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// RevokeAPIKey godoc
+//
+//	@Summary		Revoke an API key
+//	@Description	Revoke an API key so it can no longer authenticate
+//	@Tags			api-keys
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"API key ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
+//	@Router			/api/api-keys/{id} [delete]
+func (c *AuthController) RevokeAPIKey(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	err = c.authService.RevokeAPIKey(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
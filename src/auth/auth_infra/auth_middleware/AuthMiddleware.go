@@ -0,0 +1,71 @@
+package auth_middleware
+
+import (
+	"errors"
+	"loyalty-campaigns/src/auth/auth_app"
+	"loyalty-campaigns/src/auth/auth_infra/auth_repository"
+	"loyalty-campaigns/src/branch/branch_infra/branch_repository"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/security"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+var (
+	errMissingCredentials = domain_errors.Unauthenticated("missing_credentials", "missing API credentials")
+)
+
+// Authenticate resolves the caller from the Authorization bearer token or the
+// X-API-Key header and attaches it to the request; unauthenticated calls are rejected.
+func Authenticate() gin.HandlerFunc {
+	db := configs.NewDBConnection().GetDB()
+	apiKeyRepository := auth_repository.NewGormAPIKeyRepository(db)
+	branchRepository := branch_repository.NewGormBranchRepository(db)
+	authService := auth_app.NewAuthService(
+		apiKeyRepository,
+		branchRepository,
+		configs.GetEnv("ADMIN_API_TOKEN", ""),
+	)
+
+	return func(ctx *gin.Context) {
+		token := extractToken(ctx)
+		if token == "" {
+			abortUnauthorized(ctx, errMissingCredentials)
+			return
+		}
+
+		principal, err := authService.Authenticate(ctx.Request.Context(), token)
+		if err != nil {
+			if errors.Is(err, auth_app.ErrAPIKeyRevoked) {
+				abortUnauthorized(ctx, err)
+				return
+			}
+			abortUnauthorized(ctx, auth_app.ErrInvalidCredentials)
+			return
+		}
+
+		security.SetPrincipal(ctx, principal)
+		ctx.Next()
+	}
+}
+
+func extractToken(ctx *gin.Context) string {
+	if apiKey := ctx.GetHeader(apiKeyHeader); apiKey != "" {
+		return strings.TrimSpace(apiKey)
+	}
+
+	scheme, token, found := strings.Cut(ctx.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func abortUnauthorized(ctx *gin.Context, err error) {
+	ctx.Header("WWW-Authenticate", `Bearer realm="loyalty-campaigns"`)
+	domain_errors.Abort(ctx, err)
+}
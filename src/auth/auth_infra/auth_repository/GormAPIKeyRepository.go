@@ -0,0 +1,69 @@
+package auth_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/auth/auth_domain/auth_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GormAPIKeyRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormAPIKeyRepository(db *gorm.DB) auth_ports.IAPIKeyRepository {
+	return &GormAPIKeyRepository{DB: db}
+}
+
+func (r *GormAPIKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Create(apiKey).Error, "api_key")
+}
+
+func (r *GormAPIKeyRepository) GetByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := configs.DB(ctx, r.DB).First(&apiKey, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "api_key")
+	}
+	return &apiKey, nil
+}
+
+func (r *GormAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := configs.DB(ctx, r.DB).Where("prefix = ?", prefix).First(&apiKey).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "api_key")
+	}
+	return &apiKey, nil
+}
+
+var apiKeySorting = pagination.Sorting[models.APIKey]{
+	IDColumn: "id",
+	ID:       func(apiKey *models.APIKey) uint { return apiKey.ID },
+	Fields: map[string]pagination.Key[models.APIKey]{
+		"id":        {Column: "id", Value: func(apiKey *models.APIKey) any { return apiKey.ID }},
+		"name":      {Column: "name", Value: func(apiKey *models.APIKey) any { return apiKey.Name }},
+		"createdAt": {Column: "created_at", Value: func(apiKey *models.APIKey) any { return apiKey.CreatedAt }},
+	},
+	Default: "id",
+}
+
+func (r *GormAPIKeyRepository) ListByMerchant(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[models.APIKey], error) {
+	query := configs.DB(ctx, r.DB).Where("merchant_id = ?", merchantID)
+	return pagination.Find(query, page, apiKeySorting)
+}
+
+func (r *GormAPIKeyRepository) Revoke(ctx context.Context, id uint, revokedAt time.Time) error {
+	return configs.DB(ctx, r.DB).Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt).Error
+}
+
+func (r *GormAPIKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt time.Time) error {
+	return configs.DB(ctx, r.DB).Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,93 @@
+package bonus_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/bonus/bonus_domain/bonus_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"time"
+)
+
+// BonusPolicy shapes the lifecycle bonuses job: WindowDays is how many days
+// after the occasion a bonus can still be issued for the merchants without a
+// window, and BatchSize how many bonuses are looked up at a time.
+type BonusPolicy struct {
+	WindowDays int
+	BatchSize  int
+}
+
+// BonusPolicyFromEnv reads the policy from LIFECYCLE_BONUS_WINDOW_DAYS and
+// LIFECYCLE_BONUS_BATCH_SIZE.
+func BonusPolicyFromEnv() BonusPolicy {
+	return BonusPolicy{
+		WindowDays: configs.GetEnvInt("LIFECYCLE_BONUS_WINDOW_DAYS", 7),
+		BatchSize:  configs.GetEnvInt("LIFECYCLE_BONUS_BATCH_SIZE", 500),
+	}
+}
+
+// bonusKinds are issued in this order, so that a member who enrolls on their
+// birthday gets the enrollment bonus first.
+var bonusKinds = []string{models.BonusEnrollment, models.BonusBirthday, models.BonusAnniversary}
+
+type IBonusService interface {
+	// IssueLifecycleBonuses grants the enrollment, birthday and anniversary
+	// bonuses due on the day of now and returns how many it issued. Each bonus
+	// is issued once, however many times the job runs within its window.
+	IssueLifecycleBonuses(ctx context.Context, now time.Time) (int, error)
+}
+
+type bonusService struct {
+	bonusRepo bonus_ports.IBonusRepository
+	policy    BonusPolicy
+	logger    utils.ILogger
+}
+
+func NewBonusService(bonusRepo bonus_ports.IBonusRepository, policy BonusPolicy) IBonusService {
+	return &bonusService{
+		bonusRepo: bonusRepo,
+		policy:    policy,
+		logger:    utils.NewLogger(),
+	}
+}
+
+func (s *bonusService) IssueLifecycleBonuses(ctx context.Context, now time.Time) (int, error) {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	issued := 0
+	for _, kind := range bonusKinds {
+		for {
+			due, err := s.bonusRepo.FindDue(ctx, kind, today, s.policy.WindowDays, s.policy.BatchSize)
+			if err != nil {
+				s.logger.Error("Error al buscar bonos pendientes", err)
+				return issued, err
+			}
+
+			issuedInBatch := 0
+			for _, bonus := range due {
+				lifecycleBonus, err := s.bonusRepo.Issue(ctx, bonus, now)
+				if err != nil {
+					s.logger.Error("Error al emitir bono", err)
+					return issued, err
+				}
+				if lifecycleBonus != nil {
+					issuedInBatch++
+				}
+			}
+			issued += issuedInBatch
+
+			// A batch that issued nothing would be found again
+			if len(due) < s.policy.BatchSize || issuedInBatch == 0 {
+				break
+			}
+		}
+	}
+	return issued, nil
+}
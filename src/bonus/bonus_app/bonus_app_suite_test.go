@@ -0,0 +1,13 @@
+package bonus_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBonusApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BonusApp Suite")
+}
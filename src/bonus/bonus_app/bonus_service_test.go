@@ -0,0 +1,106 @@
+package bonus_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/bonus/bonus_app"
+	"loyalty-campaigns/src/bonus/bonus_domain/bonus_ports"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("BonusService", func() {
+	var (
+		bonusService bonus_app.IBonusService
+		mockBonus    *mockBonusRepository
+		ctx          context.Context
+		now          time.Time
+		today        time.Time
+	)
+
+	BeforeEach(func() {
+		mockBonus = new(mockBonusRepository)
+		bonusService = bonus_app.NewBonusService(mockBonus, bonus_app.BonusPolicy{WindowDays: 7, BatchSize: 2})
+		ctx = security.WithPrincipal(context.Background(), security.System())
+		now = time.Date(2026, time.January, 3, 23, 30, 0, 0, time.FixedZone("UTC-3", -3*60*60))
+		today = time.Date(2026, time.January, 4, 0, 0, 0, 0, time.UTC)
+	})
+
+	due := func(kind string, userID uint, year int) bonus_ports.DueBonus {
+		return bonus_ports.DueBonus{UserID: userID, MerchantID: 4, Kind: kind, Year: year}
+	}
+
+	Describe("IssueLifecycleBonuses", func() {
+		It("should issue the bonuses of every kind due today in UTC", func() {
+			mockBonus.On("FindDue", mock.Anything, models.BonusEnrollment, today, 7, 2).Return([]bonus_ports.DueBonus{due(models.BonusEnrollment, 1, 0)}, nil)
+			mockBonus.On("FindDue", mock.Anything, models.BonusBirthday, today, 7, 2).Return([]bonus_ports.DueBonus{due(models.BonusBirthday, 2, 2025)}, nil)
+			mockBonus.On("FindDue", mock.Anything, models.BonusAnniversary, today, 7, 2).Return([]bonus_ports.DueBonus{}, nil)
+			mockBonus.On("Issue", mock.Anything, mock.AnythingOfType("bonus_ports.DueBonus"), mock.AnythingOfType("time.Time")).Return(&models.LifecycleBonus{}, nil)
+
+			issued, err := bonusService.IssueLifecycleBonuses(ctx, now)
+
+			Expect(err).To(BeNil())
+			Expect(issued).To(Equal(2))
+			mockBonus.AssertCalled(GinkgoT(), "Issue", mock.Anything, due(models.BonusBirthday, 2, 2025), mock.AnythingOfType("time.Time"))
+		})
+
+		It("should look for more bonuses while the batches are full", func() {
+			mockBonus.On("FindDue", mock.Anything, models.BonusEnrollment, today, 7, 2).Return([]bonus_ports.DueBonus{due(models.BonusEnrollment, 1, 0), due(models.BonusEnrollment, 2, 0)}, nil).Once()
+			mockBonus.On("FindDue", mock.Anything, models.BonusEnrollment, today, 7, 2).Return([]bonus_ports.DueBonus{due(models.BonusEnrollment, 3, 0)}, nil).Once()
+			mockBonus.On("FindDue", mock.Anything, mock.Anything, today, 7, 2).Return([]bonus_ports.DueBonus{}, nil)
+			mockBonus.On("Issue", mock.Anything, mock.AnythingOfType("bonus_ports.DueBonus"), mock.AnythingOfType("time.Time")).Return(&models.LifecycleBonus{}, nil)
+
+			issued, err := bonusService.IssueLifecycleBonuses(ctx, now)
+
+			Expect(err).To(BeNil())
+			Expect(issued).To(Equal(3))
+		})
+
+		It("should not count the bonuses already issued", func() {
+			mockBonus.On("FindDue", mock.Anything, models.BonusEnrollment, today, 7, 2).Return([]bonus_ports.DueBonus{due(models.BonusEnrollment, 1, 0), due(models.BonusEnrollment, 2, 0)}, nil).Once()
+			mockBonus.On("FindDue", mock.Anything, mock.Anything, today, 7, 2).Return([]bonus_ports.DueBonus{}, nil)
+			mockBonus.On("Issue", mock.Anything, mock.AnythingOfType("bonus_ports.DueBonus"), mock.AnythingOfType("time.Time")).Return(nil, nil)
+
+			issued, err := bonusService.IssueLifecycleBonuses(ctx, now)
+
+			Expect(err).To(BeNil())
+			Expect(issued).To(Equal(0))
+			mockBonus.AssertNumberOfCalls(GinkgoT(), "FindDue", 3)
+		})
+
+		It("should be reserved to platform admins", func() {
+			merchantCtx := security.WithPrincipal(context.Background(), &security.Principal{
+				Role:       security.RoleMerchantAdmin,
+				KeyID:      21,
+				MerchantID: 4,
+			})
+
+			_, err := bonusService.IssueLifecycleBonuses(merchantCtx, now)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockBonus.AssertNotCalled(GinkgoT(), "FindDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+})
+
+type mockBonusRepository struct {
+	mock.Mock
+}
+
+func (m *mockBonusRepository) FindDue(ctx context.Context, kind string, today time.Time, windowDays, limit int) ([]bonus_ports.DueBonus, error) {
+	args := m.Called(ctx, kind, today, windowDays, limit)
+	return args.Get(0).([]bonus_ports.DueBonus), args.Error(1)
+}
+
+func (m *mockBonusRepository) Issue(ctx context.Context, due bonus_ports.DueBonus, now time.Time) (*models.LifecycleBonus, error) {
+	args := m.Called(ctx, due, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LifecycleBonus), args.Error(1)
+}
@@ -0,0 +1,28 @@
+package bonus_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"time"
+)
+
+// DueBonus is a lifecycle bonus a member is due: its occasion fell within the
+// merchant's window and it was not issued yet.
+type DueBonus struct {
+	UserID     uint
+	MerchantID uint
+	Kind       string
+	Year       int
+	OccursOn   time.Time
+}
+
+type IBonusRepository interface {
+	// FindDue returns up to limit bonuses of the kind whose occasion is today
+	// or fell within the merchant's window before it; windowDays is the window
+	// of the merchants without one.
+	FindDue(ctx context.Context, kind string, today time.Time, windowDays, limit int) ([]DueBonus, error)
+	// Issue records the bonus and grants its reward in the same database
+	// transaction. It returns nil when the bonus was already issued or the
+	// merchant no longer grants it.
+	Issue(ctx context.Context, due DueBonus, now time.Time) (*models.LifecycleBonus, error)
+}
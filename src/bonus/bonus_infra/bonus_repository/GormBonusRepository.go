@@ -0,0 +1,162 @@
+package bonus_repository
+
+import (
+	"context"
+	"fmt"
+	"loyalty-campaigns/src/bonus/bonus_domain/bonus_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormBonusRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormBonusRepository(db *gorm.DB) bonus_ports.IBonusRepository {
+	return &GormBonusRepository{DB: db}
+}
+
+// occasion describes when a kind of bonus falls for a member: the years it
+// may fall in, the date in each year and the members it applies to.
+type occasion struct {
+	years     string
+	occursOn  string
+	condition string
+}
+
+// Birthdays and anniversaries are looked for this year and the previous one,
+// so that a window crossing New Year finds those of late December. Adding
+// years to February 29 gives February 28 in the other years.
+var occasions = map[string]occasion{
+	models.BonusEnrollment: {
+		years:     "(0)",
+		occursOn:  "m.enrolled_at::date",
+		condition: "mc.enrollment_bonus IS NOT NULL",
+	},
+	models.BonusBirthday: {
+		years:     "(@year), (@year - 1)",
+		occursOn:  "(u.birth_date + make_interval(years => y.year - EXTRACT(YEAR FROM u.birth_date)::int))::date",
+		condition: "mc.birthday_bonus IS NOT NULL AND u.birth_date IS NOT NULL AND y.year > EXTRACT(YEAR FROM u.birth_date)",
+	},
+	models.BonusAnniversary: {
+		years:     "(@year), (@year - 1)",
+		occursOn:  "(m.enrolled_at::date + make_interval(years => y.year - EXTRACT(YEAR FROM m.enrolled_at)::int))::date",
+		condition: "mc.anniversary_bonus IS NOT NULL AND y.year > EXTRACT(YEAR FROM m.enrolled_at)",
+	},
+}
+
+const dueBonusesQuery = `
+	SELECT user_id, merchant_id, year, occurs_on FROM (
+		SELECT m.user_id, m.merchant_id, y.year, %s AS occurs_on,
+			COALESCE(mc.bonus_window_days, @window)::int AS window_days
+		FROM memberships m
+		JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL
+		JOIN merchants mc ON mc.id = m.merchant_id AND mc.deleted_at IS NULL
+		CROSS JOIN (VALUES %s) AS y(year)
+		WHERE m.deleted_at IS NULL AND %s
+	) due
+	WHERE occurs_on BETWEEN CAST(@today AS date) - window_days AND CAST(@today AS date)
+	AND NOT EXISTS (
+		SELECT 1 FROM lifecycle_bonuses b
+		WHERE b.user_id = due.user_id AND b.merchant_id = due.merchant_id
+		AND b.kind = @kind AND b.year = due.year
+	)
+	ORDER BY occurs_on, user_id, merchant_id
+	LIMIT @limit`
+
+func (r *GormBonusRepository) FindDue(ctx context.Context, kind string, today time.Time, windowDays, limit int) ([]bonus_ports.DueBonus, error) {
+	occasion, ok := occasions[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown bonus kind %q", kind)
+	}
+
+	var due []bonus_ports.DueBonus
+	err := configs.DB(ctx, r.DB).Raw(fmt.Sprintf(dueBonusesQuery, occasion.occursOn, occasion.years, occasion.condition), map[string]any{
+		"year":   today.Year(),
+		"today":  today.Format(time.DateOnly),
+		"window": windowDays,
+		"kind":   kind,
+		"limit":  limit,
+	}).Scan(&due).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range due {
+		due[i].Kind = kind
+	}
+	return due, nil
+}
+
+func (r *GormBonusRepository) Issue(ctx context.Context, due bonus_ports.DueBonus, now time.Time) (*models.LifecycleBonus, error) {
+	var issued *models.LifecycleBonus
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		var merchant models.Merchant
+		err := tx.First(&merchant, due.MerchantID).Error
+		if err != nil {
+			return err
+		}
+		amount := bonusAmount(&merchant, due.Kind)
+		if amount == nil {
+			return nil
+		}
+
+		// The unique index on the occasion makes a second run a no-op
+		bonus := &models.LifecycleBonus{
+			CreatedAt:  now,
+			UserID:     due.UserID,
+			MerchantID: due.MerchantID,
+			Kind:       due.Kind,
+			Year:       due.Year,
+			OccursOn:   due.OccursOn,
+			RewardType: merchant.DefaultRewardType,
+			Amount:     *amount,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(bonus)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		reward := &models.Reward{
+			UserID:           bonus.UserID,
+			MerchantID:       bonus.MerchantID,
+			Type:             bonus.RewardType,
+			Amount:           bonus.Amount,
+			LifecycleBonusID: &bonus.ID,
+		}
+		if merchant.RewardValidityDays != nil {
+			expiry := now.AddDate(0, 0, *merchant.RewardValidityDays)
+			reward.ExpiryDate = &expiry
+		}
+		err = reward_repository.GrantReward(tx, reward, events.RewardGranted)
+		if err != nil {
+			return err
+		}
+
+		issued = bonus
+		return events.Enqueue(tx, events.ForLifecycleBonus(bonus))
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "lifecycle_bonus")
+	}
+	return issued, nil
+}
+
+func bonusAmount(merchant *models.Merchant, kind string) *float64 {
+	switch kind {
+	case models.BonusEnrollment:
+		return merchant.EnrollmentBonus
+	case models.BonusBirthday:
+		return merchant.BirthdayBonus
+	case models.BonusAnniversary:
+		return merchant.AnniversaryBonus
+	default:
+		return nil
+	}
+}
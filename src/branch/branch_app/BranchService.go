@@ -1,22 +1,24 @@
 package branch_app
 
 import (
+	"context"
 	"loyalty-campaigns/src/branch/branch_domain/branch_ports"
 	"loyalty-campaigns/src/branch/branch_domain/branch_structs/branch_requests"
 	"loyalty-campaigns/src/branch/branch_domain/branch_structs/branch_responses"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/common/utils"
 	"sync"
 )
 
 type IBranchService interface {
-	CreateBranch(req branch_requests.CreateBranchRequest) (*branch_responses.BranchResponse, error)
-	GetBranch(id uint) (*branch_responses.BranchResponse, error)
-	UpdateBranch(id uint, req branch_requests.UpdateBranchRequest) (*branch_responses.BranchResponse, error)
-	DeleteBranch(id uint) error
-	ListBranches() ([]branch_responses.BranchResponse, error)
-	GetBranchesByMerchant(merchantID uint) ([]branch_responses.BranchResponse, error)
-	GetBranchWithCampaigns(id uint) (*branch_responses.BranchWithCampaignsResponse, error)
+	CreateBranch(ctx context.Context, req branch_requests.CreateBranchRequest) (*branch_responses.BranchResponse, error)
+	GetBranch(ctx context.Context, id uint) (*branch_responses.BranchResponse, error)
+	UpdateBranch(ctx context.Context, id uint, req branch_requests.UpdateBranchRequest) (*branch_responses.BranchResponse, error)
+	DeleteBranch(ctx context.Context, id uint) error
+	ListBranches(ctx context.Context, req branch_requests.ListBranchesRequest) (*pagination.Page[branch_responses.BranchResponse], error)
+	GetBranchWithCampaigns(ctx context.Context, id uint) (*branch_responses.BranchWithCampaignsResponse, error)
 }
 
 type branchService struct {
@@ -39,13 +41,18 @@ func NewBranchService(branchRepo branch_ports.IBranchRepository) IBranchService
 	return branchServiceInstance
 }
 
-func (s *branchService) CreateBranch(req branch_requests.CreateBranchRequest) (*branch_responses.BranchResponse, error) {
+func (s *branchService) CreateBranch(ctx context.Context, req branch_requests.CreateBranchRequest) (*branch_responses.BranchResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, req.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	branch := &models.Branch{
 		Name:       req.Name,
 		MerchantID: req.MerchantID,
 	}
 
-	err := s.branchRepo.Create(branch)
+	err = s.branchRepo.Create(ctx, branch)
 	if err != nil {
 		s.logger.Error("Error al crear sucursal", err)
 		return nil, err
@@ -58,13 +65,18 @@ func (s *branchService) CreateBranch(req branch_requests.CreateBranchRequest) (*
 	}, nil
 }
 
-func (s *branchService) GetBranch(id uint) (*branch_responses.BranchResponse, error) {
-	branch, err := s.branchRepo.GetByID(id)
+func (s *branchService) GetBranch(ctx context.Context, id uint) (*branch_responses.BranchResponse, error) {
+	branch, err := s.branchRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al obtener sucursal", err)
 		return nil, err
 	}
 
+	err = security.Authorize(ctx, security.ActionRead, branch.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	return &branch_responses.BranchResponse{
 		ID:         branch.ID,
 		Name:       branch.Name,
@@ -72,19 +84,23 @@ func (s *branchService) GetBranch(id uint) (*branch_responses.BranchResponse, er
 	}, nil
 }
 
-func (s *branchService) UpdateBranch(id uint, req branch_requests.UpdateBranchRequest) (*branch_responses.BranchResponse, error) {
-	branch, err := s.branchRepo.GetByID(id)
+func (s *branchService) UpdateBranch(ctx context.Context, id uint, req branch_requests.UpdateBranchRequest) (*branch_responses.BranchResponse, error) {
+	branch, err := s.branchRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al obtener sucursal para actualizar", err)
 		return nil, err
 	}
 
-	branch.Name = req.Name
-	if req.MerchantID != 0 {
-		branch.MerchantID = req.MerchantID
+	err = security.Authorize(ctx, security.ActionManage, branch.MerchantID, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	err = s.branchRepo.Update(branch)
+	// A branch never changes merchant: its transactions, redemptions and API
+	// keys reference the pair (branch, merchant).
+	branch.Name = req.Name
+
+	err = s.branchRepo.Update(ctx, branch)
 	if err != nil {
 		s.logger.Error("Error al actualizar sucursal", err)
 		return nil, err
@@ -97,59 +113,61 @@ func (s *branchService) UpdateBranch(id uint, req branch_requests.UpdateBranchRe
 	}, nil
 }
 
-func (s *branchService) DeleteBranch(id uint) error {
-	err := s.branchRepo.Delete(id)
+func (s *branchService) DeleteBranch(ctx context.Context, id uint) error {
+	branch, err := s.branchRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener sucursal para eliminar", err)
+		return err
+	}
+
+	err = security.Authorize(ctx, security.ActionManage, branch.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.branchRepo.Delete(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al eliminar sucursal", err)
 	}
 	return err
 }
 
-func (s *branchService) ListBranches() ([]branch_responses.BranchResponse, error) {
-	branches, err := s.branchRepo.List()
+func (s *branchService) ListBranches(ctx context.Context, req branch_requests.ListBranchesRequest) (*pagination.Page[branch_responses.BranchResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
 	if err != nil {
-		s.logger.Error("Error al listar sucursales", err)
 		return nil, err
 	}
 
-	var responses []branch_responses.BranchResponse
-	for _, branch := range branches {
-		responses = append(responses, branch_responses.BranchResponse{
-			ID:         branch.ID,
-			Name:       branch.Name,
-			MerchantID: branch.MerchantID,
-		})
-	}
-
-	return responses, nil
-}
-
-func (s *branchService) GetBranchesByMerchant(merchantID uint) ([]branch_responses.BranchResponse, error) {
-	branches, err := s.branchRepo.GetByMerchantID(merchantID)
+	page, err := s.branchRepo.List(ctx, branch_ports.BranchFilter{
+		MerchantID: merchantID,
+		Name:       req.Name,
+	}, req.Request)
 	if err != nil {
-		s.logger.Error("Error al obtener sucursales por comerciante", err)
+		s.logger.Error("Error al listar sucursales", err)
 		return nil, err
 	}
 
-	var responses []branch_responses.BranchResponse
-	for _, branch := range branches {
-		responses = append(responses, branch_responses.BranchResponse{
+	return pagination.Map(page, func(branch *models.Branch) branch_responses.BranchResponse {
+		return branch_responses.BranchResponse{
 			ID:         branch.ID,
 			Name:       branch.Name,
 			MerchantID: branch.MerchantID,
-		})
-	}
-
-	return responses, nil
+		}
+	}), nil
 }
 
-func (s *branchService) GetBranchWithCampaigns(id uint) (*branch_responses.BranchWithCampaignsResponse, error) {
-	branch, err := s.branchRepo.GetBranchWithCampaigns(id)
+func (s *branchService) GetBranchWithCampaigns(ctx context.Context, id uint) (*branch_responses.BranchWithCampaignsResponse, error) {
+	branch, err := s.branchRepo.GetBranchWithCampaigns(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al obtener sucursal con campañas", err)
 		return nil, err
 	}
 
+	err = security.Authorize(ctx, security.ActionRead, branch.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	campaignResponses := make([]branch_responses.CampaignResponse, len(branch.Campaigns))
 	for i, campaign := range branch.Campaigns {
 		campaignResponses[i] = branch_responses.CampaignResponse{
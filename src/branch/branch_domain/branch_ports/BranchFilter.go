@@ -0,0 +1,6 @@
+package branch_ports
+
+type BranchFilter struct {
+	MerchantID *uint
+	Name       string
+}
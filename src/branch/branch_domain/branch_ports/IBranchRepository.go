@@ -1,13 +1,16 @@
 package branch_ports
 
-import "loyalty-campaigns/src/common/models"
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+)
 
 type IBranchRepository interface {
-	Create(branch *models.Branch) error
-	GetByID(id uint) (*models.Branch, error)
-	Update(branch *models.Branch) error
-	Delete(id uint) error
-	List() ([]models.Branch, error)
-	GetByMerchantID(merchantID uint) ([]models.Branch, error)
-	GetBranchWithCampaigns(id uint) (*models.Branch, error)
+	Create(ctx context.Context, branch *models.Branch) error
+	GetByID(ctx context.Context, id uint) (*models.Branch, error)
+	Update(ctx context.Context, branch *models.Branch) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter BranchFilter, page pagination.Request) (*pagination.Page[models.Branch], error)
+	GetBranchWithCampaigns(ctx context.Context, id uint) (*models.Branch, error)
 }
@@ -0,0 +1,10 @@
+package branch_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListBranchesRequest accepts sort by id, name or createdAt.
+type ListBranchesRequest struct {
+	pagination.Request
+	MerchantID *uint  `form:"merchantId"`
+	Name       string `form:"name"`
+}
@@ -5,6 +5,8 @@ import (
 	"loyalty-campaigns/src/branch/branch_domain/branch_structs/branch_requests"
 	"loyalty-campaigns/src/branch/branch_infra/branch_repository"
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
 	"net/http"
 	"strconv"
 	"sync"
@@ -21,7 +23,7 @@ var (
 	branchControllerOnce     sync.Once
 )
 
-func NewBranchController(router *gin.Engine) *BranchController {
+func NewBranchController(router *gin.RouterGroup) *BranchController {
 	branchControllerOnce.Do(func() {
 		branchControllerInstance = &BranchController{}
 		db := configs.NewDBConnection().GetDB()
@@ -32,8 +34,8 @@ func NewBranchController(router *gin.Engine) *BranchController {
 	return branchControllerInstance
 }
 
-func (c *BranchController) setupBranchRoutes(router *gin.Engine) {
-	branchGroup := router.Group("/api/branches")
+func (c *BranchController) setupBranchRoutes(router *gin.RouterGroup) {
+	branchGroup := router.Group("/branches")
 	{
 		branchGroup.POST("", c.CreateBranch)
 		branchGroup.GET("/:id", c.GetBranch)
@@ -51,21 +53,22 @@ func (c *BranchController) setupBranchRoutes(router *gin.Engine) {
 //	@Tags			branches
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		branch_requests.CreateBranchRequest	true	"Branch creation request"
 //	@Success		201		{object}	branch_responses.BranchResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/branches [post]
 func (c *BranchController) CreateBranch(ctx *gin.Context) {
 	var req branch_requests.CreateBranchRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.branchService.CreateBranch(req)
+	response, err := c.branchService.CreateBranch(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -78,21 +81,22 @@ func (c *BranchController) CreateBranch(ctx *gin.Context) {
 //	@Tags			branches
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Branch ID"
 //	@Success		200	{object}	branch_responses.BranchResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		404	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
 //	@Router			/api/branches/{id} [get]
 func (c *BranchController) GetBranch(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.branchService.GetBranch(uint(id))
+	response, err := c.branchService.GetBranch(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
+		ctx.Error(err)
 		return
 	}
 
@@ -105,28 +109,29 @@ func (c *BranchController) GetBranch(ctx *gin.Context) {
 //	@Tags			branches
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id		path		int									true	"Branch ID"
 //	@Param			request	body		branch_requests.UpdateBranchRequest	true	"Branch update request"
 //	@Success		200		{object}	branch_responses.BranchResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/branches/{id} [put]
 func (c *BranchController) UpdateBranch(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
 	var req branch_requests.UpdateBranchRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.branchService.UpdateBranch(uint(id), req)
+	response, err := c.branchService.UpdateBranch(ctx.Request.Context(), uint(id), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -139,21 +144,22 @@ func (c *BranchController) UpdateBranch(ctx *gin.Context) {
 //	@Tags			branches
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Branch ID"
 //	@Success		200	{object}	map[string]string
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/branches/{id} [delete]
 func (c *BranchController) DeleteBranch(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	err = c.branchService.DeleteBranch(uint(id))
+	err = c.branchService.DeleteBranch(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -161,49 +167,66 @@ func (c *BranchController) DeleteBranch(ctx *gin.Context) {
 }
 
 // ListBranches godoc
-//	@Summary		List all branches
-//	@Description	Get a list of all branches in the system
+//	@Summary		List branches
+//	@Description	Get a page of branches, optionally filtered by merchant and name. Sort by id, name or createdAt, prefixed with "-" for descending order.
 //	@Tags			branches
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		branch_responses.BranchResponse
-//	@Failure		500	{object}	map[string]string
+//	@Security		ApiKeyAuth
+//	@Param			request	query		branch_requests.ListBranchesRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[branch_responses.BranchResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/branches [get]
 func (c *BranchController) ListBranches(ctx *gin.Context) {
-	responses, err := c.branchService.ListBranches()
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req branch_requests.ListBranchesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responses)
+	c.listBranches(ctx, req)
 }
 
 // GetBranchesByMerchant godoc
 //	@Summary		Get branches by merchant
-//	@Description	Get a list of branches for a specific merchant
+//	@Description	Get a page of branches for a specific merchant
 //	@Tags			branches
 //	@Accept			json
 //	@Produce		json
-//	@Param			merchantID	path		int	true	"Merchant ID"
-//	@Success		200			{array}		branch_responses.BranchResponse
-//	@Failure		400			{object}	map[string]string
-//	@Failure		500			{object}	map[string]string
+//	@Security		ApiKeyAuth
+//	@Param			merchantID	path		int									true	"Merchant ID"
+//	@Param			request		query		branch_requests.ListBranchesRequest	false	"Filters, sort and pagination"
+//	@Success		200			{object}	pagination.Page[branch_responses.BranchResponse]
+//	@Failure		400			{object}	domain_errors.Problem
+//	@Failure		500			{object}	domain_errors.Problem
 //	@Router			/api/branches/merchant/{merchantID} [get]
 func (c *BranchController) GetBranchesByMerchant(ctx *gin.Context) {
 	merchantID, err := strconv.ParseUint(ctx.Param("merchantID"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid merchant ID"})
+		ctx.Error(domain_errors.Validation("invalid_merchant_id", "Invalid merchant ID"))
+		return
+	}
+
+	var req branch_requests.ListBranchesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
+	id := uint(merchantID)
+	req.MerchantID = &id
+
+	c.listBranches(ctx, req)
+}
 
-	responses, err := c.branchService.GetBranchesByMerchant(uint(merchantID))
+func (c *BranchController) listBranches(ctx *gin.Context, req branch_requests.ListBranchesRequest) {
+	page, err := c.branchService.ListBranches(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responses)
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetBranchWithCampaigns godoc
@@ -212,21 +235,22 @@ func (c *BranchController) GetBranchesByMerchant(ctx *gin.Context) {
 //	@Tags			branches
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Branch ID"
 //	@Success		200	{object}	branch_responses.BranchWithCampaignsResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/branches/{id}/campaigns [get]
 func (c *BranchController) GetBranchWithCampaigns(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.branchService.GetBranchWithCampaigns(uint(id))
+	response, err := c.branchService.GetBranchWithCampaigns(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
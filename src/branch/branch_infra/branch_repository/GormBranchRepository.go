@@ -1,8 +1,12 @@
 package branch_repository
 
 import (
+	"context"
 	"loyalty-campaigns/src/branch/branch_domain/branch_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 
 	"gorm.io/gorm"
 )
@@ -15,44 +19,54 @@ func NewGormBranchRepository(db *gorm.DB) branch_ports.IBranchRepository {
 	return &GormBranchRepository{DB: db}
 }
 
-func (r *GormBranchRepository) Create(branch *models.Branch) error {
-	return r.DB.Create(branch).Error
+func (r *GormBranchRepository) Create(ctx context.Context, branch *models.Branch) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Create(branch).Error, "branch")
 }
 
-func (r *GormBranchRepository) GetByID(id uint) (*models.Branch, error) {
+func (r *GormBranchRepository) GetByID(ctx context.Context, id uint) (*models.Branch, error) {
 	var branch models.Branch
-	err := r.DB.First(&branch, id).Error
+	err := configs.DB(ctx, r.DB).First(&branch, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "branch")
 	}
 	return &branch, nil
 }
 
-func (r *GormBranchRepository) Update(branch *models.Branch) error {
-	return r.DB.Save(branch).Error
+func (r *GormBranchRepository) Update(ctx context.Context, branch *models.Branch) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Save(branch).Error, "branch")
 }
 
-func (r *GormBranchRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Branch{}, id).Error
+func (r *GormBranchRepository) Delete(ctx context.Context, id uint) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Delete(&models.Branch{}, id).Error, "branch")
 }
 
-func (r *GormBranchRepository) List() ([]models.Branch, error) {
-	var branches []models.Branch
-	err := r.DB.Find(&branches).Error
-	return branches, err
+var branchSorting = pagination.Sorting[models.Branch]{
+	IDColumn: "id",
+	ID:       func(branch *models.Branch) uint { return branch.ID },
+	Fields: map[string]pagination.Key[models.Branch]{
+		"id":        {Column: "id", Value: func(branch *models.Branch) any { return branch.ID }},
+		"name":      {Column: "name", Value: func(branch *models.Branch) any { return branch.Name }},
+		"createdAt": {Column: "created_at", Value: func(branch *models.Branch) any { return branch.CreatedAt }},
+	},
+	Default: "id",
 }
 
-func (r *GormBranchRepository) GetByMerchantID(merchantID uint) ([]models.Branch, error) {
-	var branches []models.Branch
-	err := r.DB.Where("merchant_id = ?", merchantID).Find(&branches).Error
-	return branches, err
+func (r *GormBranchRepository) List(ctx context.Context, filter branch_ports.BranchFilter, page pagination.Request) (*pagination.Page[models.Branch], error) {
+	query := configs.DB(ctx, r.DB)
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Name+"%")
+	}
+	return pagination.Find(query, page, branchSorting)
 }
 
-func (r *GormBranchRepository) GetBranchWithCampaigns(id uint) (*models.Branch, error) {
+func (r *GormBranchRepository) GetBranchWithCampaigns(ctx context.Context, id uint) (*models.Branch, error) {
 	var branch models.Branch
-	err := r.DB.Preload("Campaigns").First(&branch, id).Error
+	err := configs.DB(ctx, r.DB).Preload("Campaigns").First(&branch, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "branch")
 	}
 	return &branch, nil
 }
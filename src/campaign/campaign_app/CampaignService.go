@@ -1,24 +1,37 @@
 package campaign_app
 
 import (
+	"context"
 	"loyalty-campaigns/src/campaign/campaign_domain/campaign_ports"
 	"loyalty-campaigns/src/campaign/campaign_domain/campaign_structs/campaign_requests"
 	"loyalty-campaigns/src/campaign/campaign_domain/campaign_structs/campaign_responses"
+	"loyalty-campaigns/src/common/domain_errors"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/common/utils"
 	"sync"
 	"time"
 )
 
 type ICampaignService interface {
-	CreateCampaign(req campaign_requests.CreateCampaignRequest) (*campaign_responses.CampaignResponse, error)
-	GetCampaign(id uint) (*campaign_responses.CampaignResponse, error)
-	UpdateCampaign(id uint, req campaign_requests.UpdateCampaignRequest) (*campaign_responses.CampaignResponse, error)
-	DeleteCampaign(id uint) error
-	ListCampaigns() ([]campaign_responses.CampaignResponse, error)
-	GetActiveCampaigns(merchantID uint, branchID *uint, date time.Time) ([]campaign_responses.CampaignResponse, error)
+	CreateCampaign(ctx context.Context, req campaign_requests.CreateCampaignRequest) (*campaign_responses.CampaignResponse, error)
+	GetCampaign(ctx context.Context, id uint) (*campaign_responses.CampaignResponse, error)
+	UpdateCampaign(ctx context.Context, id uint, req campaign_requests.UpdateCampaignRequest) (*campaign_responses.CampaignResponse, error)
+	DeleteCampaign(ctx context.Context, id uint) error
+	ListCampaigns(ctx context.Context, req campaign_requests.ListCampaignsRequest) (*pagination.Page[campaign_responses.CampaignResponse], error)
+	GetActiveCampaigns(ctx context.Context, merchantID uint, branchID *uint, date time.Time) ([]campaign_responses.CampaignResponse, error)
+	GetCampaignReport(ctx context.Context, id uint) (*campaign_responses.CampaignReportResponse, error)
 }
 
+var (
+	ErrCampaignNotStarted = domain_errors.Validation("campaign_not_started", "the campaign has not started yet")
+	ErrStampsRequired     = domain_errors.Validation("stamps_required", "stamp-card campaigns need stampsRequired")
+	ErrNotStampCard       = domain_errors.Validation("not_a_stamp_card", "stampsRequired and catalogItemId only apply to stamp-card campaigns")
+	ErrThresholdRequired  = domain_errors.Validation("spend_threshold_required", "spend-threshold campaigns need spendThreshold")
+	ErrNotSpendThreshold  = domain_errors.Validation("not_a_spend_threshold", "spendThreshold only applies to spend-threshold campaigns")
+)
+
 type campaignService struct {
 	campaignRepo campaign_ports.ICampaignRepository
 	logger       utils.ILogger
@@ -39,18 +52,34 @@ func NewCampaignService(campaignRepo campaign_ports.ICampaignRepository) ICampai
 	return campaignServiceInstance
 }
 
-func (s *campaignService) CreateCampaign(req campaign_requests.CreateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
+func (s *campaignService) CreateCampaign(ctx context.Context, req campaign_requests.CreateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, req.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	campaign := &models.Campaign{
-		MerchantID: req.MerchantID,
-		BranchID:   req.BranchID,
-		StartDate:  req.StartDate,
-		EndDate:    req.EndDate,
-		Type:       req.Type,
-		Value:      req.Value,
-		MinAmount:  req.MinAmount,
+		MerchantID:     req.MerchantID,
+		BranchID:       req.BranchID,
+		StartDate:      req.StartDate,
+		EndDate:        req.EndDate,
+		Kind:           req.Kind,
+		Type:           req.Type,
+		Value:          req.Value,
+		MinAmount:      req.MinAmount,
+		StampsRequired: req.StampsRequired,
+		CatalogItemID:  req.CatalogItemID,
+		SpendThreshold: req.SpendThreshold,
+	}
+	if campaign.Kind == "" {
+		campaign.Kind = models.CampaignMultiplier
+	}
+	err = checkKind(campaign)
+	if err != nil {
+		return nil, err
 	}
 
-	err := s.campaignRepo.Create(campaign)
+	err = s.campaignRepo.Create(ctx, campaign)
 	if err != nil {
 		s.logger.Error("Error al crear campaña", err)
 		return nil, err
@@ -59,30 +88,47 @@ func (s *campaignService) CreateCampaign(req campaign_requests.CreateCampaignReq
 	return campaignToResponse(campaign), nil
 }
 
-func (s *campaignService) GetCampaign(id uint) (*campaign_responses.CampaignResponse, error) {
-	campaign, err := s.campaignRepo.GetByID(id)
+func (s *campaignService) GetCampaign(ctx context.Context, id uint) (*campaign_responses.CampaignResponse, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al obtener campaña", err)
 		return nil, err
 	}
 
+	err = security.Authorize(ctx, security.ActionRead, campaign.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	return campaignToResponse(campaign), nil
 }
 
-func (s *campaignService) UpdateCampaign(id uint, req campaign_requests.UpdateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
-	campaign, err := s.campaignRepo.GetByID(id)
+func (s *campaignService) UpdateCampaign(ctx context.Context, id uint, req campaign_requests.UpdateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al obtener campaña para actualizar", err)
 		return nil, err
 	}
 
+	err = security.Authorize(ctx, security.ActionManage, campaign.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	campaign.StartDate = req.StartDate
 	campaign.EndDate = req.EndDate
 	campaign.Type = req.Type
 	campaign.Value = req.Value
 	campaign.MinAmount = req.MinAmount
+	campaign.StampsRequired = req.StampsRequired
+	campaign.CatalogItemID = req.CatalogItemID
+	campaign.SpendThreshold = req.SpendThreshold
+	err = checkKind(campaign)
+	if err != nil {
+		return nil, err
+	}
 
-	err = s.campaignRepo.Update(campaign)
+	err = s.campaignRepo.Update(ctx, campaign)
 	if err != nil {
 		s.logger.Error("Error al actualizar campaña", err)
 		return nil, err
@@ -91,26 +137,56 @@ func (s *campaignService) UpdateCampaign(id uint, req campaign_requests.UpdateCa
 	return campaignToResponse(campaign), nil
 }
 
-func (s *campaignService) DeleteCampaign(id uint) error {
-	err := s.campaignRepo.Delete(id)
+func (s *campaignService) DeleteCampaign(ctx context.Context, id uint) error {
+	campaign, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener campaña para eliminar", err)
+		return err
+	}
+
+	err = security.Authorize(ctx, security.ActionManage, campaign.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.campaignRepo.Delete(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al eliminar campaña", err)
 	}
 	return err
 }
 
-func (s *campaignService) ListCampaigns() ([]campaign_responses.CampaignResponse, error) {
-	campaigns, err := s.campaignRepo.List()
+func (s *campaignService) ListCampaigns(ctx context.Context, req campaign_requests.ListCampaignsRequest) (*pagination.Page[campaign_responses.CampaignResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.campaignRepo.List(ctx, campaign_ports.CampaignFilter{
+		MerchantID: merchantID,
+		BranchID:   req.BranchID,
+		Kind:       req.Kind,
+		Type:       req.Type,
+		From:       req.From,
+		To:         req.To,
+	}, req.Request)
 	if err != nil {
 		s.logger.Error("Error al listar campañas", err)
 		return nil, err
 	}
 
-	return campaignsToResponses(campaigns), nil
+	return pagination.Map(page, func(campaign *models.Campaign) campaign_responses.CampaignResponse {
+		return *campaignToResponse(campaign)
+	}), nil
 }
 
-func (s *campaignService) GetActiveCampaigns(merchantID uint, branchID *uint, date time.Time) ([]campaign_responses.CampaignResponse, error) {
-	campaigns, err := s.campaignRepo.GetActiveCampaigns(merchantID, branchID, date)
+func (s *campaignService) GetActiveCampaigns(ctx context.Context, merchantID uint, branchID *uint, date time.Time) ([]campaign_responses.CampaignResponse, error) {
+	err := security.Authorize(ctx, security.ActionRead, merchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	campaigns, err := s.campaignRepo.GetActiveCampaigns(ctx, merchantID, branchID, date)
 	if err != nil {
 		s.logger.Error("Error al obtener campañas activas", err)
 		return nil, err
@@ -119,17 +195,136 @@ func (s *campaignService) GetActiveCampaigns(merchantID uint, branchID *uint, da
 	return campaignsToResponses(campaigns), nil
 }
 
+// GetCampaignReport compares the transactions of the campaign's merchant, or
+// branch, during the campaign with those of the period of the same length
+// right before it, next to the cost of the rewards attributed to the campaign.
+func (s *campaignService) GetCampaignReport(ctx context.Context, id uint) (*campaign_responses.CampaignReportResponse, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener campaña para el reporte", err)
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionRead, campaign.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if campaign.StartDate.After(now) {
+		return nil, ErrCampaignNotStarted
+	}
+
+	// The end date is included, as when the campaigns are applied.
+	end := now
+	inProgress := true
+	if campaign.EndDate != nil && campaign.EndDate.Before(now) {
+		end = campaign.EndDate.Add(time.Microsecond)
+		inProgress = false
+	}
+	length := end.Sub(campaign.StartDate)
+
+	during := campaign_ports.SalesFilter{
+		MerchantID: campaign.MerchantID,
+		BranchID:   campaign.BranchID,
+		From:       campaign.StartDate,
+		To:         end,
+	}
+	baseline := during
+	baseline.From = campaign.StartDate.Add(-length)
+	baseline.To = campaign.StartDate
+
+	duringStats, err := s.campaignRepo.SalesStats(ctx, during)
+	if err != nil {
+		s.logger.Error("Error al obtener ventas de la campaña", err)
+		return nil, err
+	}
+	baselineStats, err := s.campaignRepo.SalesStats(ctx, baseline)
+	if err != nil {
+		s.logger.Error("Error al obtener ventas del periodo base", err)
+		return nil, err
+	}
+	rewardStats, err := s.campaignRepo.RewardStats(ctx, campaign.ID)
+	if err != nil {
+		s.logger.Error("Error al obtener recompensas de la campaña", err)
+		return nil, err
+	}
+
+	response := &campaign_responses.CampaignReportResponse{
+		CampaignID:                campaign.ID,
+		MerchantID:                campaign.MerchantID,
+		BranchID:                  campaign.BranchID,
+		RewardType:                campaign.Type,
+		InProgress:                inProgress,
+		Campaign:                  reportPeriod(during, duringStats),
+		Baseline:                  reportPeriod(baseline, baselineStats),
+		RewardsCost:               rewardStats.Cost,
+		RewardsCount:              rewardStats.Rewards,
+		Participants:              rewardStats.Participants,
+		ParticipatingTransactions: rewardStats.Transactions,
+		ParticipatingSales:        rewardStats.Sales,
+		IncrementalSpend:          duringStats.Sales - baselineStats.Sales,
+	}
+
+	if response.Baseline.AverageTicket > 0 {
+		uplift := (response.Campaign.AverageTicket - response.Baseline.AverageTicket) / response.Baseline.AverageTicket
+		response.AverageTicketUplift = &uplift
+	}
+	if campaign.Type == "cashback" && rewardStats.Cost > 0 {
+		roi := (response.IncrementalSpend - rewardStats.Cost) / rewardStats.Cost
+		response.ROI = &roi
+	}
+
+	return response, nil
+}
+
+func reportPeriod(filter campaign_ports.SalesFilter, stats *campaign_ports.SalesStats) campaign_responses.ReportPeriod {
+	period := campaign_responses.ReportPeriod{
+		From:            filter.From,
+		To:              filter.To,
+		Transactions:    stats.Transactions,
+		Sales:           stats.Sales,
+		UniqueCustomers: stats.UniqueCustomers,
+	}
+	if stats.Transactions > 0 {
+		period.AverageTicket = stats.Sales / float64(stats.Transactions)
+	}
+	return period
+}
+
+// checkKind requires the size of the cards of stamp-card campaigns and the
+// threshold of spend-threshold campaigns, and rejects them on the rest.
+func checkKind(campaign *models.Campaign) error {
+	isStampCard := campaign.Kind == models.CampaignStampCard
+	isSpendThreshold := campaign.Kind == models.CampaignSpendThreshold
+	switch {
+	case !isStampCard && (campaign.StampsRequired != nil || campaign.CatalogItemID != nil):
+		return ErrNotStampCard
+	case !isSpendThreshold && campaign.SpendThreshold != nil:
+		return ErrNotSpendThreshold
+	case isStampCard && campaign.StampsRequired == nil:
+		return ErrStampsRequired
+	case isSpendThreshold && campaign.SpendThreshold == nil:
+		return ErrThresholdRequired
+	}
+	return nil
+}
+
 // Función auxiliar para convertir una Campaign a CampaignResponse
 func campaignToResponse(campaign *models.Campaign) *campaign_responses.CampaignResponse {
 	return &campaign_responses.CampaignResponse{
-		ID:         campaign.ID,
-		MerchantID: campaign.MerchantID,
-		BranchID:   campaign.BranchID,
-		StartDate:  campaign.StartDate,
-		EndDate:    campaign.EndDate,
-		Type:       campaign.Type,
-		Value:      campaign.Value,
-		MinAmount:  campaign.MinAmount,
+		ID:             campaign.ID,
+		MerchantID:     campaign.MerchantID,
+		BranchID:       campaign.BranchID,
+		StartDate:      campaign.StartDate,
+		EndDate:        campaign.EndDate,
+		Kind:           campaign.Kind,
+		Type:           campaign.Type,
+		Value:          campaign.Value,
+		MinAmount:      campaign.MinAmount,
+		StampsRequired: campaign.StampsRequired,
+		CatalogItemID:  campaign.CatalogItemID,
+		SpendThreshold: campaign.SpendThreshold,
 	}
 }
 
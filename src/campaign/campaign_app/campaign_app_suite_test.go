@@ -0,0 +1,13 @@
+package campaign_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCampaignApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CampaignApp Suite")
+}
@@ -0,0 +1,215 @@
+package campaign_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/campaign/campaign_app"
+	"loyalty-campaigns/src/campaign/campaign_domain/campaign_ports"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("CampaignService", func() {
+	// The campaign service is a singleton, so every spec shares its repository.
+	mockCampaign := new(mockCampaignRepository)
+	campaignService := campaign_app.NewCampaignService(mockCampaign)
+
+	var (
+		ctx      context.Context
+		campaign *models.Campaign
+		start    time.Time
+		end      time.Time
+	)
+
+	merchantID := uint(4)
+
+	BeforeEach(func() {
+		mockCampaign.ExpectedCalls = nil
+		mockCampaign.Calls = nil
+		ctx = security.WithPrincipal(context.Background(), &security.Principal{Role: security.RoleAnalyst, MerchantID: merchantID})
+		start = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		end = time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC)
+		campaign = &models.Campaign{
+			Model:      gorm.Model{ID: 7},
+			MerchantID: merchantID,
+			StartDate:  start,
+			EndDate:    &end,
+			Type:       "cashback",
+		}
+		mockCampaign.On("GetByID", mock.Anything, uint(7)).Return(campaign, nil)
+	})
+
+	Describe("GetCampaignReport", func() {
+		// The campaign ends at the end of its last day, so both periods last
+		// from the start to right after the end date
+		length := 10*24*time.Hour - time.Second + time.Microsecond
+
+		during := func() campaign_ports.SalesFilter {
+			return campaign_ports.SalesFilter{MerchantID: merchantID, BranchID: campaign.BranchID, From: start, To: start.Add(length)}
+		}
+
+		baseline := func() campaign_ports.SalesFilter {
+			return campaign_ports.SalesFilter{MerchantID: merchantID, BranchID: campaign.BranchID, From: start.Add(-length), To: start}
+		}
+
+		It("should compare the campaign with the period right before it", func() {
+			mockCampaign.On("SalesStats", mock.Anything, during()).Return(&campaign_ports.SalesStats{Transactions: 10, Sales: 1200, UniqueCustomers: 6}, nil)
+			mockCampaign.On("SalesStats", mock.Anything, baseline()).Return(&campaign_ports.SalesStats{Transactions: 10, Sales: 1000, UniqueCustomers: 7}, nil)
+			mockCampaign.On("RewardStats", mock.Anything, uint(7)).Return(&campaign_ports.RewardStats{Rewards: 8, Cost: 50, Participants: 5, Transactions: 7, Sales: 900}, nil)
+
+			report, err := campaignService.GetCampaignReport(ctx, 7)
+
+			Expect(err).To(BeNil())
+			Expect(report.InProgress).To(BeFalse())
+			Expect(report.Campaign.From).To(Equal(start))
+			Expect(report.Campaign.To).To(Equal(start.Add(length)))
+			Expect(report.Campaign.AverageTicket).To(Equal(120.0))
+			Expect(report.Baseline.From).To(Equal(start.Add(-length)))
+			Expect(report.Baseline.AverageTicket).To(Equal(100.0))
+			Expect(report.IncrementalSpend).To(Equal(200.0))
+			Expect(*report.AverageTicketUplift).To(BeNumerically("~", 0.2, 1e-9))
+			Expect(report.RewardsCost).To(Equal(50.0))
+			Expect(report.RewardsCount).To(Equal(int64(8)))
+			Expect(report.Participants).To(Equal(int64(5)))
+			Expect(report.ParticipatingTransactions).To(Equal(int64(7)))
+			Expect(report.ParticipatingSales).To(Equal(900.0))
+			// (200 - 50) / 50
+			Expect(*report.ROI).To(Equal(3.0))
+		})
+
+		It("should measure the sales of the branch of a branch campaign", func() {
+			branchID := uint(2)
+			campaign.BranchID = &branchID
+			mockCampaign.On("SalesStats", mock.Anything, during()).Return(&campaign_ports.SalesStats{}, nil)
+			mockCampaign.On("SalesStats", mock.Anything, baseline()).Return(&campaign_ports.SalesStats{}, nil)
+			mockCampaign.On("RewardStats", mock.Anything, uint(7)).Return(&campaign_ports.RewardStats{}, nil)
+
+			report, err := campaignService.GetCampaignReport(ctx, 7)
+
+			Expect(err).To(BeNil())
+			Expect(report.BranchID).To(Equal(&branchID))
+			mockCampaign.AssertExpectations(GinkgoT())
+		})
+
+		It("should leave out the uplift without baseline sales and the ROI without cost", func() {
+			mockCampaign.On("SalesStats", mock.Anything, during()).Return(&campaign_ports.SalesStats{Transactions: 2, Sales: 100}, nil)
+			mockCampaign.On("SalesStats", mock.Anything, baseline()).Return(&campaign_ports.SalesStats{}, nil)
+			mockCampaign.On("RewardStats", mock.Anything, uint(7)).Return(&campaign_ports.RewardStats{}, nil)
+
+			report, err := campaignService.GetCampaignReport(ctx, 7)
+
+			Expect(err).To(BeNil())
+			Expect(report.Baseline.AverageTicket).To(BeZero())
+			Expect(report.AverageTicketUplift).To(BeNil())
+			Expect(report.ROI).To(BeNil())
+		})
+
+		It("should only compute the ROI of cashback campaigns", func() {
+			campaign.Type = "points"
+			mockCampaign.On("SalesStats", mock.Anything, mock.Anything).Return(&campaign_ports.SalesStats{Transactions: 1, Sales: 100}, nil)
+			mockCampaign.On("RewardStats", mock.Anything, uint(7)).Return(&campaign_ports.RewardStats{Rewards: 1, Cost: 10}, nil)
+
+			report, err := campaignService.GetCampaignReport(ctx, 7)
+
+			Expect(err).To(BeNil())
+			Expect(report.RewardType).To(Equal("points"))
+			Expect(report.ROI).To(BeNil())
+		})
+
+		It("should measure a campaign in progress up to now", func() {
+			campaign.StartDate = time.Now().Add(-24 * time.Hour)
+			campaign.EndDate = nil
+			mockCampaign.On("SalesStats", mock.Anything, mock.Anything).Return(&campaign_ports.SalesStats{}, nil)
+			mockCampaign.On("RewardStats", mock.Anything, uint(7)).Return(&campaign_ports.RewardStats{}, nil)
+
+			report, err := campaignService.GetCampaignReport(ctx, 7)
+
+			Expect(err).To(BeNil())
+			Expect(report.InProgress).To(BeTrue())
+			Expect(report.Campaign.To).To(BeTemporally("~", time.Now(), time.Second))
+			Expect(report.Baseline.To).To(Equal(campaign.StartDate))
+			Expect(report.Campaign.To.Sub(report.Campaign.From)).To(Equal(report.Baseline.To.Sub(report.Baseline.From)))
+		})
+
+		It("should reject a campaign that has not started", func() {
+			campaign.StartDate = time.Now().Add(time.Hour)
+
+			_, err := campaignService.GetCampaignReport(ctx, 7)
+
+			Expect(err).To(MatchError(campaign_app.ErrCampaignNotStarted))
+			mockCampaign.AssertNotCalled(GinkgoT(), "SalesStats", mock.Anything, mock.Anything)
+		})
+
+		It("should not report the campaigns of another merchant", func() {
+			campaign.MerchantID = merchantID + 1
+
+			_, err := campaignService.GetCampaignReport(ctx, 7)
+
+			Expect(err).To(MatchError(security.ErrForbidden))
+			mockCampaign.AssertNotCalled(GinkgoT(), "RewardStats", mock.Anything, mock.Anything)
+		})
+	})
+})
+
+// Mock implementations
+type mockCampaignRepository struct {
+	mock.Mock
+}
+
+func (m *mockCampaignRepository) Create(ctx context.Context, campaign *models.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *mockCampaignRepository) GetByID(ctx context.Context, id uint) (*models.Campaign, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Campaign), args.Error(1)
+}
+
+func (m *mockCampaignRepository) Update(ctx context.Context, campaign *models.Campaign) error {
+	args := m.Called(ctx, campaign)
+	return args.Error(0)
+}
+
+func (m *mockCampaignRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockCampaignRepository) List(ctx context.Context, filter campaign_ports.CampaignFilter, page pagination.Request) (*pagination.Page[models.Campaign], error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pagination.Page[models.Campaign]), args.Error(1)
+}
+
+func (m *mockCampaignRepository) GetActiveCampaigns(ctx context.Context, merchantID uint, branchID *uint, date time.Time) ([]models.Campaign, error) {
+	args := m.Called(ctx, merchantID, branchID, date)
+	return args.Get(0).([]models.Campaign), args.Error(1)
+}
+
+func (m *mockCampaignRepository) SalesStats(ctx context.Context, filter campaign_ports.SalesFilter) (*campaign_ports.SalesStats, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*campaign_ports.SalesStats), args.Error(1)
+}
+
+func (m *mockCampaignRepository) RewardStats(ctx context.Context, campaignID uint) (*campaign_ports.RewardStats, error) {
+	args := m.Called(ctx, campaignID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*campaign_ports.RewardStats), args.Error(1)
+}
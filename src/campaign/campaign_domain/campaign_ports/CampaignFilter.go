@@ -0,0 +1,12 @@
+package campaign_ports
+
+import "time"
+
+type CampaignFilter struct {
+	MerchantID *uint
+	BranchID   *uint
+	Kind       string
+	Type       string
+	From       *time.Time
+	To         *time.Time
+}
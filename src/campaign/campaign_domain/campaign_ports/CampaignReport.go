@@ -0,0 +1,30 @@
+package campaign_ports
+
+import "time"
+
+// SalesFilter selects the transactions of a merchant, or of one of its
+// branches, in [From, To) that were not reversed.
+type SalesFilter struct {
+	MerchantID uint
+	BranchID   *uint
+	From       time.Time
+	To         time.Time
+}
+
+type SalesStats struct {
+	Transactions    int64
+	Sales           float64
+	UniqueCustomers int64
+}
+
+// RewardStats aggregates the rewards attributed to a campaign that were not
+// revoked, and the transactions that earned them and were not reversed. Cost
+// is the amount granted, whether it was redeemed, expired or is still
+// outstanding.
+type RewardStats struct {
+	Rewards      int64
+	Cost         float64
+	Participants int64
+	Transactions int64
+	Sales        float64
+}
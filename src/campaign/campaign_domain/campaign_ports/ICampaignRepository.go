@@ -1,15 +1,19 @@
 package campaign_ports
 
 import (
+	"context"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 	"time"
 )
 
 type ICampaignRepository interface {
-	Create(campaign *models.Campaign) error
-	GetByID(id uint) (*models.Campaign, error)
-	Update(campaign *models.Campaign) error
-	Delete(id uint) error
-	List() ([]models.Campaign, error)
-	GetActiveCampaigns(merchantID uint, branchID *uint, date time.Time) ([]models.Campaign, error)
+	Create(ctx context.Context, campaign *models.Campaign) error
+	GetByID(ctx context.Context, id uint) (*models.Campaign, error)
+	Update(ctx context.Context, campaign *models.Campaign) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter CampaignFilter, page pagination.Request) (*pagination.Page[models.Campaign], error)
+	GetActiveCampaigns(ctx context.Context, merchantID uint, branchID *uint, date time.Time) ([]models.Campaign, error)
+	SalesStats(ctx context.Context, filter SalesFilter) (*SalesStats, error)
+	RewardStats(ctx context.Context, campaignID uint) (*RewardStats, error)
 }
@@ -2,12 +2,20 @@ package campaign_requests
 
 import "time"
 
+// CreateCampaignRequest creates a multiplier campaign unless Kind says
+// otherwise. Stamp-card campaigns need StampsRequired, and reward a full card
+// with Value in Type or, with CatalogItemID, a voucher for the item.
+// Spend-threshold campaigns need SpendThreshold, and award Value in Type.
 type CreateCampaignRequest struct {
-	MerchantID uint       `json:"merchantId" binding:"required"`
-	BranchID   *uint      `json:"branchId"`
-	StartDate  time.Time  `json:"startDate" binding:"required"`
-	EndDate    *time.Time `json:"endDate"`
-	Type       string     `json:"type" binding:"required"`
-	Value      float64    `json:"value" binding:"required"`
-	MinAmount  *float64   `json:"minAmount"`
+	MerchantID     uint       `json:"merchantId" binding:"required"`
+	BranchID       *uint      `json:"branchId"`
+	StartDate      time.Time  `json:"startDate" binding:"required"`
+	EndDate        *time.Time `json:"endDate"`
+	Kind           string     `json:"kind" binding:"omitempty,oneof=multiplier stamp_card spend_threshold"`
+	Type           string     `json:"type" binding:"required"`
+	Value          float64    `json:"value" binding:"required_without=CatalogItemID,omitempty,gt=0"`
+	MinAmount      *float64   `json:"minAmount"`
+	StampsRequired *int       `json:"stampsRequired" binding:"omitempty,min=1"`
+	CatalogItemID  *uint      `json:"catalogItemId"`
+	SpendThreshold *float64   `json:"spendThreshold" binding:"omitempty,gt=0"`
 }
@@ -0,0 +1,18 @@
+package campaign_requests
+
+import (
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+// ListCampaignsRequest accepts sort by id, startDate, value or createdAt. From and
+// To keep the campaigns whose validity overlaps the range.
+type ListCampaignsRequest struct {
+	pagination.Request
+	MerchantID *uint      `form:"merchantId"`
+	BranchID   *uint      `form:"branchId"`
+	Kind       string     `form:"kind" binding:"omitempty,oneof=multiplier stamp_card spend_threshold"`
+	Type       string     `form:"type"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -2,10 +2,15 @@ package campaign_requests
 
 import "time"
 
+// UpdateCampaignRequest cannot change the kind of the campaign. A new size of
+// the cards of a stamp-card campaign applies to the cards started afterwards.
 type UpdateCampaignRequest struct {
-	StartDate time.Time  `json:"startDate" binding:"required"`
-	EndDate   *time.Time `json:"endDate"`
-	Type      string     `json:"type" binding:"required"`
-	Value     float64    `json:"value" binding:"required"`
-	MinAmount *float64   `json:"minAmount"`
+	StartDate      time.Time  `json:"startDate" binding:"required"`
+	EndDate        *time.Time `json:"endDate"`
+	Type           string     `json:"type" binding:"required"`
+	Value          float64    `json:"value" binding:"required_without=CatalogItemID,omitempty,gt=0"`
+	MinAmount      *float64   `json:"minAmount"`
+	StampsRequired *int       `json:"stampsRequired" binding:"omitempty,min=1"`
+	CatalogItemID  *uint      `json:"catalogItemId"`
+	SpendThreshold *float64   `json:"spendThreshold" binding:"omitempty,gt=0"`
 }
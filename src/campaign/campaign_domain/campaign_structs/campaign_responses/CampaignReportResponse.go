@@ -0,0 +1,39 @@
+package campaign_responses
+
+import "time"
+
+type CampaignReportResponse struct {
+	CampaignID uint  `json:"campaignId"`
+	MerchantID uint  `json:"merchantId"`
+	BranchID   *uint `json:"branchId"`
+	// RewardType is the unit of RewardsCost.
+	RewardType string `json:"rewardType"`
+	InProgress bool   `json:"inProgress"`
+	// Campaign covers the campaign up to its end date or now; Baseline is the
+	// period of the same length right before it.
+	Campaign     ReportPeriod `json:"campaign"`
+	Baseline     ReportPeriod `json:"baseline"`
+	RewardsCost  float64      `json:"rewardsCost"`
+	RewardsCount int64        `json:"rewardsCount"`
+	Participants int64        `json:"participants"`
+	// ParticipatingTransactions and ParticipatingSales are those of the
+	// transactions that earned a reward from the campaign.
+	ParticipatingTransactions int64   `json:"participatingTransactions"`
+	ParticipatingSales        float64 `json:"participatingSales"`
+	IncrementalSpend          float64 `json:"incrementalSpend"`
+	// AverageTicketUplift is the relative change of the average ticket over
+	// the baseline, absent when the baseline had no transactions.
+	AverageTicketUplift *float64 `json:"averageTicketUplift"`
+	// ROI is (incrementalSpend - rewardsCost) / rewardsCost, only for
+	// cashback campaigns, whose cost is in the currency of the sales.
+	ROI *float64 `json:"roi"`
+}
+
+type ReportPeriod struct {
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	Transactions    int64     `json:"transactions"`
+	Sales           float64   `json:"sales"`
+	UniqueCustomers int64     `json:"uniqueCustomers"`
+	AverageTicket   float64   `json:"averageTicket"`
+}
@@ -8,7 +8,13 @@ type CampaignResponse struct {
 	BranchID   *uint      `json:"branchId"`
 	StartDate  time.Time  `json:"startDate"`
 	EndDate    *time.Time `json:"endDate"`
+	Kind       string     `json:"kind"`
 	Type       string     `json:"type"`
 	Value      float64    `json:"value"`
 	MinAmount  *float64   `json:"minAmount"`
+	// StampsRequired and CatalogItemID are only set on stamp-card campaigns.
+	StampsRequired *int  `json:"stampsRequired,omitempty"`
+	CatalogItemID  *uint `json:"catalogItemId,omitempty"`
+	// SpendThreshold is only set on spend-threshold campaigns.
+	SpendThreshold *float64 `json:"spendThreshold,omitempty"`
 }
@@ -5,6 +5,8 @@ import (
 	"loyalty-campaigns/src/campaign/campaign_domain/campaign_structs/campaign_requests"
 	"loyalty-campaigns/src/campaign/campaign_infra/campaign_repository"
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
 	"net/http"
 	"strconv"
 	"sync"
@@ -22,7 +24,7 @@ var (
 	campaignControllerOnce     sync.Once
 )
 
-func NewCampaignController(router *gin.Engine) *CampaignController {
+func NewCampaignController(router *gin.RouterGroup) *CampaignController {
 	campaignControllerOnce.Do(func() {
 		campaignControllerInstance = &CampaignController{}
 		db := configs.NewDBConnection().GetDB()
@@ -33,8 +35,8 @@ func NewCampaignController(router *gin.Engine) *CampaignController {
 	return campaignControllerInstance
 }
 
-func (c *CampaignController) setupCampaignRoutes(router *gin.Engine) {
-	campaignGroup := router.Group("/api/campaigns")
+func (c *CampaignController) setupCampaignRoutes(router *gin.RouterGroup) {
+	campaignGroup := router.Group("/campaigns")
 	{
 		campaignGroup.POST("", c.CreateCampaign)
 		campaignGroup.GET("/:id", c.GetCampaign)
@@ -42,31 +44,34 @@ func (c *CampaignController) setupCampaignRoutes(router *gin.Engine) {
 		campaignGroup.DELETE("/:id", c.DeleteCampaign)
 		campaignGroup.GET("", c.ListCampaigns)
 		campaignGroup.GET("/active", c.GetActiveCampaigns)
+		campaignGroup.GET("/:id/report", c.GetCampaignReport)
 	}
 }
 
 // CreateCampaign godoc
 //
 //	@Summary		Create a new campaign
-//	@Description	Create a new loyalty campaign in the system
+//	@Description	Create a new loyalty campaign in the system. Multiplier campaigns (the default kind) multiply the base reward of each qualifying transaction by value. Stamp-card campaigns (kind stamp_card) give a stamp per qualifying transaction instead, and reward a card of stampsRequired stamps with value in the reward type or, with catalogItemId, a voucher for an item of the merchant's catalog. Spend-threshold campaigns (kind spend_threshold) award value once to each user whose qualifying spend during the campaign reaches spendThreshold. Rejected when a stamp-card campaign lacks stampsRequired (stamps_required), a spend-threshold campaign lacks spendThreshold (spend_threshold_required), or another kind has stamp-card fields (not_a_stamp_card) or a spend threshold (not_a_spend_threshold).
 //	@Tags			campaigns
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		campaign_requests.CreateCampaignRequest	true	"Campaign creation request"
 //	@Success		201		{object}	campaign_responses.CampaignResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/campaigns [post]
 func (c *CampaignController) CreateCampaign(ctx *gin.Context) {
 	var req campaign_requests.CreateCampaignRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.campaignService.CreateCampaign(req)
+	response, err := c.campaignService.CreateCampaign(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -80,21 +85,22 @@ func (c *CampaignController) CreateCampaign(ctx *gin.Context) {
 //	@Tags			campaigns
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Campaign ID"
 //	@Success		200	{object}	campaign_responses.CampaignResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		404	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
 //	@Router			/api/campaigns/{id} [get]
 func (c *CampaignController) GetCampaign(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.campaignService.GetCampaign(uint(id))
+	response, err := c.campaignService.GetCampaign(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Campaign not found"})
+		ctx.Error(err)
 		return
 	}
 
@@ -104,32 +110,34 @@ func (c *CampaignController) GetCampaign(ctx *gin.Context) {
 // UpdateCampaign godoc
 //
 //	@Summary		Update a campaign
-//	@Description	Update details of an existing campaign
+//	@Description	Update details of an existing campaign. Its kind cannot change, and a new size of the cards of a stamp-card campaign applies to the cards started afterwards.
 //	@Tags			campaigns
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id		path		int										true	"Campaign ID"
 //	@Param			request	body		campaign_requests.UpdateCampaignRequest	true	"Campaign update request"
 //	@Success		200		{object}	campaign_responses.CampaignResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/campaigns/{id} [put]
 func (c *CampaignController) UpdateCampaign(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
 	var req campaign_requests.UpdateCampaignRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.campaignService.UpdateCampaign(uint(id), req)
+	response, err := c.campaignService.UpdateCampaign(ctx.Request.Context(), uint(id), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -143,21 +151,22 @@ func (c *CampaignController) UpdateCampaign(ctx *gin.Context) {
 //	@Tags			campaigns
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Campaign ID"
 //	@Success		200	{object}	map[string]string
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/campaigns/{id} [delete]
 func (c *CampaignController) DeleteCampaign(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	err = c.campaignService.DeleteCampaign(uint(id))
+	err = c.campaignService.DeleteCampaign(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -166,22 +175,31 @@ func (c *CampaignController) DeleteCampaign(ctx *gin.Context) {
 
 // ListCampaigns godoc
 //
-//	@Summary		List all campaigns
-//	@Description	Get a list of all campaigns in the system
+//	@Summary		List campaigns
+//	@Description	Get a page of campaigns filtered by merchant, branch, kind, type and a date range overlapping their validity. Sort by id, startDate, value or createdAt, prefixed with "-" for descending order.
 //	@Tags			campaigns
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		campaign_responses.CampaignResponse
-//	@Failure		500	{object}	map[string]string
+//	@Security		ApiKeyAuth
+//	@Param			request	query		campaign_requests.ListCampaignsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[campaign_responses.CampaignResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/campaigns [get]
 func (c *CampaignController) ListCampaigns(ctx *gin.Context) {
-	responses, err := c.campaignService.ListCampaigns()
+	var req campaign_requests.ListCampaignsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.campaignService.ListCampaigns(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responses)
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetActiveCampaigns godoc
@@ -191,11 +209,12 @@ func (c *CampaignController) ListCampaigns(ctx *gin.Context) {
 //	@Tags			campaigns
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			merchantId	query		int		false	"Merchant ID"
 //	@Param			branchId	query		int		false	"Branch ID"
 //	@Param			date		query		string	false	"Date (RFC3339 format)"	Format(date-time)
 //	@Success		200			{array}		campaign_responses.CampaignResponse
-//	@Failure		500			{object}	map[string]string
+//	@Failure		500			{object}	domain_errors.Problem
 //	@Router			/api/campaigns/active [get]
 func (c *CampaignController) GetActiveCampaigns(ctx *gin.Context) {
 	merchantID, _ := strconv.ParseUint(ctx.Query("merchantId"), 10, 32)
@@ -204,11 +223,42 @@ func (c *CampaignController) GetActiveCampaigns(ctx *gin.Context) {
 
 	branchIDUint := uint(branchID)
 
-	responses, err := c.campaignService.GetActiveCampaigns(uint(merchantID), &branchIDUint, date)
+	responses, err := c.campaignService.GetActiveCampaigns(ctx.Request.Context(), uint(merchantID), &branchIDUint, date)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, responses)
 }
+
+// GetCampaignReport godoc
+//
+//	@Summary		Get a campaign report
+//	@Description	Compare the transactions of the campaign's merchant, or branch, during the campaign with the period of the same length right before it, with the cost, participants and transactions of the rewards attributed to the campaign. A campaign without an end date, or not finished yet, is reported up to now. The ROI is only given for cashback campaigns.
+//	@Tags			campaigns
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Campaign ID"
+//	@Success		200	{object}	campaign_responses.CampaignReportResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
+//	@Router			/api/campaigns/{id}/report [get]
+func (c *CampaignController) GetCampaignReport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.campaignService.GetCampaignReport(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
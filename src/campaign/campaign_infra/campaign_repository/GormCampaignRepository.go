@@ -1,8 +1,13 @@
 package campaign_repository
 
 import (
+	"context"
 	"loyalty-campaigns/src/campaign/campaign_domain/campaign_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 	"time"
 
 	"gorm.io/gorm"
@@ -16,36 +21,119 @@ func NewGormCampaignRepository(db *gorm.DB) campaign_ports.ICampaignRepository {
 	return &GormCampaignRepository{DB: db}
 }
 
-func (r *GormCampaignRepository) Create(campaign *models.Campaign) error {
-	return r.DB.Create(campaign).Error
+// Create, Update and Delete record the matching campaign event in the same
+// database transaction. The catalog item of a stamp-card campaign must belong
+// to the campaign's merchant.
+func (r *GormCampaignRepository) Create(ctx context.Context, campaign *models.Campaign) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := checkCatalogItem(tx, campaign)
+		if err != nil {
+			return err
+		}
+		err = tx.Create(campaign).Error
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForCampaign(events.CampaignCreated, campaign))
+	})
+	return domain_errors.Translate(err, "campaign")
 }
 
-func (r *GormCampaignRepository) GetByID(id uint) (*models.Campaign, error) {
+func (r *GormCampaignRepository) GetByID(ctx context.Context, id uint) (*models.Campaign, error) {
 	var campaign models.Campaign
-	err := r.DB.First(&campaign, id).Error
+	err := configs.DB(ctx, r.DB).First(&campaign, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "campaign")
 	}
 	return &campaign, nil
 }
 
-func (r *GormCampaignRepository) Update(campaign *models.Campaign) error {
-	return r.DB.Save(campaign).Error
+func (r *GormCampaignRepository) Update(ctx context.Context, campaign *models.Campaign) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := checkCatalogItem(tx, campaign)
+		if err != nil {
+			return err
+		}
+		err = tx.Save(campaign).Error
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForCampaign(events.CampaignUpdated, campaign))
+	})
+	return domain_errors.Translate(err, "campaign")
 }
 
-func (r *GormCampaignRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Campaign{}, id).Error
+func checkCatalogItem(tx *gorm.DB, campaign *models.Campaign) error {
+	if campaign.CatalogItemID == nil {
+		return nil
+	}
+	var count int64
+	err := tx.Model(&models.CatalogItem{}).
+		Where("id = ? AND merchant_id = ?", *campaign.CatalogItemID, campaign.MerchantID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain_errors.NotFound("catalog_item_not_found", "catalog item not found")
+	}
+	return nil
 }
 
-func (r *GormCampaignRepository) List() ([]models.Campaign, error) {
-	var campaigns []models.Campaign
-	err := r.DB.Find(&campaigns).Error
-	return campaigns, err
+func (r *GormCampaignRepository) Delete(ctx context.Context, id uint) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var campaign models.Campaign
+		err := tx.First(&campaign, id).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&campaign).Error
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForCampaign(events.CampaignDeleted, &campaign))
+	})
+	return domain_errors.Translate(err, "campaign")
 }
 
-func (r *GormCampaignRepository) GetActiveCampaigns(merchantID uint, branchID *uint, date time.Time) ([]models.Campaign, error) {
+var campaignSorting = pagination.Sorting[models.Campaign]{
+	IDColumn: "id",
+	ID:       func(campaign *models.Campaign) uint { return campaign.ID },
+	Fields: map[string]pagination.Key[models.Campaign]{
+		"id":        {Column: "id", Value: func(campaign *models.Campaign) any { return campaign.ID }},
+		"startDate": {Column: "start_date", Value: func(campaign *models.Campaign) any { return campaign.StartDate }},
+		"value":     {Column: "value", Value: func(campaign *models.Campaign) any { return campaign.Value }},
+		"createdAt": {Column: "created_at", Value: func(campaign *models.Campaign) any { return campaign.CreatedAt }},
+	},
+	Default: "id",
+}
+
+func (r *GormCampaignRepository) List(ctx context.Context, filter campaign_ports.CampaignFilter, page pagination.Request) (*pagination.Page[models.Campaign], error) {
+	query := configs.DB(ctx, r.DB)
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.BranchID != nil {
+		query = query.Where("branch_id = ?", *filter.BranchID)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.From != nil {
+		query = query.Where("end_date IS NULL OR end_date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("start_date <= ?", *filter.To)
+	}
+	return pagination.Find(query, page, campaignSorting)
+}
+
+func (r *GormCampaignRepository) GetActiveCampaigns(ctx context.Context, merchantID uint, branchID *uint, date time.Time) ([]models.Campaign, error) {
 	var campaigns []models.Campaign
-	query := r.DB.Where("merchant_id = ? AND start_date <= ?", merchantID, date).
+	query := configs.DB(ctx, r.DB).Where("merchant_id = ? AND start_date <= ?", merchantID, date).
 		Where("end_date IS NULL OR end_date >= ?", date)
 
 	if branchID != nil {
@@ -57,3 +145,58 @@ func (r *GormCampaignRepository) GetActiveCampaigns(merchantID uint, branchID *u
 	err := query.Find(&campaigns).Error
 	return campaigns, err
 }
+
+func (r *GormCampaignRepository) SalesStats(ctx context.Context, filter campaign_ports.SalesFilter) (*campaign_ports.SalesStats, error) {
+	query := configs.DB(ctx, r.DB).
+		Model(&models.Transaction{}).
+		Select("COUNT(*) AS transactions, COALESCE(SUM(amount), 0) AS sales, COUNT(DISTINCT user_id) AS unique_customers").
+		Where("merchant_id = ? AND date >= ? AND date < ? AND reversed_at IS NULL", filter.MerchantID, filter.From, filter.To)
+	if filter.BranchID != nil {
+		query = query.Where("branch_id = ?", *filter.BranchID)
+	}
+
+	var stats campaign_ports.SalesStats
+	err := query.Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// RewardStats takes the granted amounts from the reward events, since
+// redemptions consume the rewards in place: the amount of the last grant or
+// adjustment of each reward. Rewards consumed entirely are soft-deleted, so
+// they are read regardless of deleted_at. A transaction that earned several
+// rewards of the campaign counts once, and not at all once reversed.
+func (r *GormCampaignRepository) RewardStats(ctx context.Context, campaignID uint) (*campaign_ports.RewardStats, error) {
+	var stats campaign_ports.RewardStats
+	err := configs.DB(ctx, r.DB).Raw(`
+		WITH attributed AS (
+			SELECT rewards.id, rewards.user_id, rewards.transaction_id,
+				(ARRAY_AGG(CAST(outbox_events.payload->>'amount' AS DECIMAL) ORDER BY outbox_events.sequence DESC)
+					FILTER (WHERE outbox_events.type IN (?, ?)))[1] AS granted,
+				BOOL_OR(outbox_events.type = ?) AS revoked
+			FROM rewards
+			JOIN outbox_events ON outbox_events.aggregate_type = 'reward' AND outbox_events.aggregate_id = rewards.id
+			WHERE rewards.campaign_id = ?
+			GROUP BY rewards.id, rewards.user_id, rewards.transaction_id
+		),
+		kept AS (
+			SELECT * FROM attributed WHERE NOT revoked
+		),
+		participating AS (
+			SELECT transactions.amount
+			FROM transactions
+			WHERE transactions.id IN (SELECT transaction_id FROM kept) AND transactions.reversed_at IS NULL
+		)
+		SELECT (SELECT COUNT(*) FROM kept) AS rewards,
+			(SELECT COALESCE(SUM(granted), 0) FROM kept) AS cost,
+			(SELECT COUNT(DISTINCT user_id) FROM kept) AS participants,
+			(SELECT COUNT(*) FROM participating) AS transactions,
+			(SELECT COALESCE(SUM(amount), 0) FROM participating) AS sales
+	`, events.RewardGranted, events.RewardAdjusted, events.RewardRevoked, campaignID).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,13 @@
+package campaign_repository_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCampaignRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CampaignRepository Suite")
+}
@@ -0,0 +1,125 @@
+package campaign_repository_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/campaign/campaign_domain/campaign_ports"
+	"loyalty-campaigns/src/campaign/campaign_infra/campaign_repository"
+	"loyalty-campaigns/src/common/events"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("GormCampaignRepository", func() {
+	var (
+		sqlMock    sqlmock.Sqlmock
+		repository campaign_ports.ICampaignRepository
+		from, to   time.Time
+	)
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		repository = campaign_repository.NewGormCampaignRepository(db)
+		from = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 1, 0)
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("SalesStats", func() {
+		salesColumns := []string{"transactions", "sales", "unique_customers"}
+
+		It("should aggregate the sales of the merchant that were not reversed", func() {
+			sqlMock.ExpectQuery(`^SELECT COUNT\(\*\) AS transactions, COALESCE\(SUM\(amount\), 0\) AS sales, COUNT\(DISTINCT user_id\) AS unique_customers FROM "transactions" WHERE \(merchant_id = \$1 AND date >= \$2 AND date < \$3 AND reversed_at IS NULL\) AND "transactions"\."deleted_at" IS NULL$`).
+				WithArgs(4, from, to).
+				WillReturnRows(sqlmock.NewRows(salesColumns).AddRow(3, 250.5, 2))
+
+			stats, err := repository.SalesStats(context.Background(), campaign_ports.SalesFilter{MerchantID: 4, From: from, To: to})
+
+			Expect(err).To(BeNil())
+			Expect(*stats).To(Equal(campaign_ports.SalesStats{Transactions: 3, Sales: 250.5, UniqueCustomers: 2}))
+		})
+
+		It("should only aggregate the sales of the branch of the campaign", func() {
+			branchID := uint(2)
+			sqlMock.ExpectQuery(`WHERE \(merchant_id = \$1 AND date >= \$2 AND date < \$3 AND reversed_at IS NULL\) AND branch_id = \$4 AND "transactions"\."deleted_at" IS NULL$`).
+				WithArgs(4, from, to, branchID).
+				WillReturnRows(sqlmock.NewRows(salesColumns).AddRow(0, 0, 0))
+
+			stats, err := repository.SalesStats(context.Background(), campaign_ports.SalesFilter{MerchantID: 4, BranchID: &branchID, From: from, To: to})
+
+			Expect(err).To(BeNil())
+			Expect(stats.Transactions).To(BeZero())
+		})
+	})
+
+	Describe("RewardStats", func() {
+		statsColumns := []string{"rewards", "cost", "participants", "transactions", "sales"}
+
+		It("should attribute the rewards of the campaign through their events", func() {
+			// Each reward takes its last granted or adjusted amount, and is
+			// left out when it was revoked
+			sqlMock.ExpectQuery(`JOIN outbox_events ON outbox_events\.aggregate_type = 'reward' AND outbox_events\.aggregate_id = rewards\.id\s+WHERE rewards\.campaign_id = \$4\s+`).
+				WithArgs(events.RewardGranted, events.RewardAdjusted, events.RewardRevoked, 7).
+				WillReturnRows(sqlmock.NewRows(statsColumns).AddRow(5, 42.5, 3, 4, 800))
+
+			stats, err := repository.RewardStats(context.Background(), 7)
+
+			Expect(err).To(BeNil())
+			Expect(*stats).To(Equal(campaign_ports.RewardStats{Rewards: 5, Cost: 42.5, Participants: 3, Transactions: 4, Sales: 800}))
+		})
+
+		It("should take the amount of the last grant or adjustment of each reward", func() {
+			sqlMock.ExpectQuery(`\(ARRAY_AGG\(CAST\(outbox_events\.payload->>'amount' AS DECIMAL\) ORDER BY outbox_events\.sequence DESC\)\s+FILTER \(WHERE outbox_events\.type IN \(\$1, \$2\)\)\)\[1\] AS granted`).
+				WillReturnRows(sqlmock.NewRows(statsColumns).AddRow(0, 0, 0, 0, 0))
+
+			_, err := repository.RewardStats(context.Background(), 7)
+
+			Expect(err).To(BeNil())
+		})
+
+		It("should leave out revoked rewards", func() {
+			sqlMock.ExpectQuery(`(?s)BOOL_OR\(outbox_events\.type = \$3\) AS revoked.*kept AS \(\s+SELECT \* FROM attributed WHERE NOT revoked\s+\)`).
+				WillReturnRows(sqlmock.NewRows(statsColumns).AddRow(0, 0, 0, 0, 0))
+
+			_, err := repository.RewardStats(context.Background(), 7)
+
+			Expect(err).To(BeNil())
+		})
+
+		It("should count each participating transaction once, unless it was reversed", func() {
+			sqlMock.ExpectQuery(`(?s)participating AS \(\s+SELECT transactions\.amount\s+FROM transactions\s+` +
+				`WHERE transactions\.id IN \(SELECT transaction_id FROM kept\) AND transactions\.reversed_at IS NULL\s+\).*` +
+				`\(SELECT COUNT\(\*\) FROM participating\) AS transactions,\s+\(SELECT COALESCE\(SUM\(amount\), 0\) FROM participating\) AS sales`).
+				WillReturnRows(sqlmock.NewRows(statsColumns).AddRow(0, 0, 0, 0, 0))
+
+			_, err := repository.RewardStats(context.Background(), 7)
+
+			Expect(err).To(BeNil())
+		})
+
+		It("should aggregate the rewards, cost and participants of the rewards kept", func() {
+			sqlMock.ExpectQuery(`(?s)SELECT \(SELECT COUNT\(\*\) FROM kept\) AS rewards,\s+` +
+				`\(SELECT COALESCE\(SUM\(granted\), 0\) FROM kept\) AS cost,\s+` +
+				`\(SELECT COUNT\(DISTINCT user_id\) FROM kept\) AS participants`).
+				WillReturnRows(sqlmock.NewRows(statsColumns).AddRow(0, 0, 0, 0, 0))
+
+			_, err := repository.RewardStats(context.Background(), 7)
+
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,465 @@
+package catalog_app
+
+import (
+	"context"
+	"errors"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_ports"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_structs/catalog_requests"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_structs/catalog_responses"
+	"loyalty-campaigns/src/common/codes"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/metrics"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"time"
+)
+
+var (
+	ErrInvalidValidity    = domain_errors.Validation("invalid_validity", "validUntil must not be before validFrom")
+	ErrInvalidVoucherCode = domain_errors.Validation("invalid_voucher_code", "the voucher code is mistyped")
+)
+
+// VoucherPolicy shapes the vouchers issued for redemptions. Vouchers with a
+// ValidityDays of zero do not expire.
+type VoucherPolicy struct {
+	CodeLength   int
+	CheckDigit   bool
+	ValidityDays int
+}
+
+// VoucherPolicyFromEnv reads the policy from VOUCHER_CODE_LENGTH,
+// VOUCHER_CHECK_DIGIT and VOUCHER_VALIDITY_DAYS.
+func VoucherPolicyFromEnv() VoucherPolicy {
+	return VoucherPolicy{
+		CodeLength:   configs.GetEnvInt("VOUCHER_CODE_LENGTH", 12),
+		CheckDigit:   configs.GetEnvBool("VOUCHER_CHECK_DIGIT", true),
+		ValidityDays: configs.GetEnvInt("VOUCHER_VALIDITY_DAYS", 30),
+	}
+}
+
+type ICatalogService interface {
+	CreateItem(ctx context.Context, req catalog_requests.CreateCatalogItemRequest) (*catalog_responses.CatalogItemResponse, error)
+	GetItem(ctx context.Context, id uint) (*catalog_responses.CatalogItemResponse, error)
+	UpdateItem(ctx context.Context, id uint, req catalog_requests.UpdateCatalogItemRequest) (*catalog_responses.CatalogItemResponse, error)
+	DeleteItem(ctx context.Context, id uint) error
+	ListItems(ctx context.Context, req catalog_requests.ListCatalogItemsRequest) (*pagination.Page[catalog_responses.CatalogItemResponse], error)
+	RedeemItem(ctx context.Context, req catalog_requests.RedeemItemRequest) (*catalog_responses.ItemRedemptionResponse, error)
+	GetRedemption(ctx context.Context, id uint) (*catalog_responses.ItemRedemptionResponse, error)
+	ListRedemptions(ctx context.Context, req catalog_requests.ListItemRedemptionsRequest) (*pagination.Page[catalog_responses.ItemRedemptionResponse], error)
+	FulfilRedemption(ctx context.Context, id uint) (*catalog_responses.ItemRedemptionResponse, error)
+	CancelRedemption(ctx context.Context, id uint, req catalog_requests.CancelRedemptionRequest) (*catalog_responses.ItemRedemptionResponse, error)
+	ValidateVoucher(ctx context.Context, req catalog_requests.VoucherRequest) (*catalog_responses.VoucherResponse, error)
+	ConsumeVoucher(ctx context.Context, req catalog_requests.VoucherRequest) (*catalog_responses.VoucherResponse, error)
+	ExpireVouchers(ctx context.Context, now time.Time) (int, error)
+}
+
+type catalogService struct {
+	catalogRepo catalog_ports.ICatalogRepository
+	vouchers    VoucherPolicy
+	logger      utils.ILogger
+}
+
+func NewCatalogService(catalogRepo catalog_ports.ICatalogRepository, vouchers VoucherPolicy) ICatalogService {
+	return &catalogService{
+		catalogRepo: catalogRepo,
+		vouchers:    vouchers,
+		logger:      utils.NewLogger(),
+	}
+}
+
+func (s *catalogService) CreateItem(ctx context.Context, req catalog_requests.CreateCatalogItemRequest) (*catalog_responses.CatalogItemResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, req.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && req.ValidUntil.Before(*req.ValidFrom) {
+		return nil, ErrInvalidValidity
+	}
+
+	item := &models.CatalogItem{
+		MerchantID:  req.MerchantID,
+		Name:        req.Name,
+		Description: req.Description,
+		RewardType:  req.RewardType,
+		Cost:        req.Cost,
+		Stock:       req.Stock,
+		ValidFrom:   req.ValidFrom,
+		ValidUntil:  req.ValidUntil,
+		Branches:    itemBranches(req.BranchIDs),
+	}
+
+	err = s.catalogRepo.Create(ctx, item)
+	if err != nil {
+		s.logger.Error("Error al crear artículo del catálogo", err)
+		return nil, err
+	}
+
+	return itemToResponse(item), nil
+}
+
+func (s *catalogService) GetItem(ctx context.Context, id uint) (*catalog_responses.CatalogItemResponse, error) {
+	item, err := s.catalogRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionRead, item.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return itemToResponse(item), nil
+}
+
+func (s *catalogService) UpdateItem(ctx context.Context, id uint, req catalog_requests.UpdateCatalogItemRequest) (*catalog_responses.CatalogItemResponse, error) {
+	item, err := s.catalogRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionManage, item.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && req.ValidUntil.Before(*req.ValidFrom) {
+		return nil, ErrInvalidValidity
+	}
+
+	item.Name = req.Name
+	item.Description = req.Description
+	item.RewardType = req.RewardType
+	item.Cost = req.Cost
+	item.Stock = req.Stock
+	item.ValidFrom = req.ValidFrom
+	item.ValidUntil = req.ValidUntil
+	item.Branches = itemBranches(req.BranchIDs)
+
+	err = s.catalogRepo.Update(ctx, item)
+	if err != nil {
+		s.logger.Error("Error al actualizar artículo del catálogo", err)
+		return nil, err
+	}
+
+	return itemToResponse(item), nil
+}
+
+func (s *catalogService) DeleteItem(ctx context.Context, id uint) error {
+	item, err := s.catalogRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = security.Authorize(ctx, security.ActionManage, item.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.catalogRepo.Delete(ctx, id)
+}
+
+func (s *catalogService) ListItems(ctx context.Context, req catalog_requests.ListCatalogItemsRequest) (*pagination.Page[catalog_responses.CatalogItemResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := catalog_ports.CatalogItemFilter{
+		MerchantID: merchantID,
+		BranchID:   req.BranchID,
+		Name:       req.Name,
+	}
+	if req.Available {
+		now := time.Now()
+		filter.AvailableAt = &now
+	}
+
+	page, err := s.catalogRepo.List(ctx, filter, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar artículos del catálogo", err)
+		return nil, err
+	}
+
+	return pagination.Map(page, func(item *models.CatalogItem) catalog_responses.CatalogItemResponse {
+		return *itemToResponse(item)
+	}), nil
+}
+
+// RedeemItem pays for the item with the user's rewards at the branch, which
+// the caller must be able to operate, and issues the voucher the user shows to
+// collect it.
+func (s *catalogService) RedeemItem(ctx context.Context, req catalog_requests.RedeemItemRequest) (*catalog_responses.ItemRedemptionResponse, error) {
+	item, err := s.catalogRepo.GetByID(ctx, req.CatalogItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionOperate, item.MerchantID, &req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	now := time.Now()
+	voucher, err := s.newVoucher(now)
+	if err != nil {
+		return nil, err
+	}
+	redemption := &models.ItemRedemption{
+		CatalogItemID: item.ID,
+		UserID:        req.UserID,
+		BranchID:      req.BranchID,
+		Quantity:      quantity,
+		Voucher:       voucher,
+	}
+
+	err = s.catalogRepo.Redeem(ctx, redemption, now)
+	if err != nil {
+		if errors.Is(err, domain_errors.ErrInsufficientBalance) {
+			metrics.InsufficientBalanceRejections.WithLabelValues(item.RewardType, metrics.ID(item.MerchantID)).Inc()
+		}
+		return nil, err
+	}
+
+	metrics.Redemptions.WithLabelValues(redemption.RewardType, metrics.ID(redemption.MerchantID)).Inc()
+
+	return redemptionToResponse(redemption), nil
+}
+
+func (s *catalogService) GetRedemption(ctx context.Context, id uint) (*catalog_responses.ItemRedemptionResponse, error) {
+	redemption, err := s.catalogRepo.GetRedemption(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionRead, redemption.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return redemptionToResponse(redemption), nil
+}
+
+func (s *catalogService) ListRedemptions(ctx context.Context, req catalog_requests.ListItemRedemptionsRequest) (*pagination.Page[catalog_responses.ItemRedemptionResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.catalogRepo.ListRedemptions(ctx, catalog_ports.ItemRedemptionFilter{
+		MerchantID:    merchantID,
+		BranchID:      req.BranchID,
+		UserID:        req.UserID,
+		CatalogItemID: req.CatalogItemID,
+		Status:        req.Status,
+	}, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar canjes de artículos", err)
+		return nil, err
+	}
+
+	return pagination.Map(page, func(redemption *models.ItemRedemption) catalog_responses.ItemRedemptionResponse {
+		return *redemptionToResponse(redemption)
+	}), nil
+}
+
+// FulfilRedemption records that the branch of the redemption handed the item
+// over.
+func (s *catalogService) FulfilRedemption(ctx context.Context, id uint) (*catalog_responses.ItemRedemptionResponse, error) {
+	redemption, err := s.catalogRepo.GetRedemption(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionOperate, redemption.MerchantID, &redemption.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	redemption, err = s.catalogRepo.Fulfil(ctx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return redemptionToResponse(redemption), nil
+}
+
+// CancelRedemption refunds a pending redemption and puts the item back in stock.
+func (s *catalogService) CancelRedemption(ctx context.Context, id uint, req catalog_requests.CancelRedemptionRequest) (*catalog_responses.ItemRedemptionResponse, error) {
+	redemption, err := s.catalogRepo.GetRedemption(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionOperate, redemption.MerchantID, &redemption.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	redemption, err = s.catalogRepo.Cancel(ctx, id, req.Reason, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return redemptionToResponse(redemption), nil
+}
+
+// ValidateVoucher checks that the voucher can be used at the branch now,
+// without using it.
+func (s *catalogService) ValidateVoucher(ctx context.Context, req catalog_requests.VoucherRequest) (*catalog_responses.VoucherResponse, error) {
+	voucher, err := s.presentedVoucher(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = catalog_ports.VoucherError(voucher, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return voucherToResponse(voucher, true), nil
+}
+
+// ConsumeVoucher uses the voucher at the branch, which fulfils its redemption.
+func (s *catalogService) ConsumeVoucher(ctx context.Context, req catalog_requests.VoucherRequest) (*catalog_responses.VoucherResponse, error) {
+	voucher, err := s.presentedVoucher(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	voucher, err = s.catalogRepo.ConsumeVoucher(ctx, voucher.Code, req.BranchID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return voucherToResponse(voucher, true), nil
+}
+
+// ExpireVouchers expires the issued vouchers past their expiry and refunds
+// their redemptions, and returns how many were expired.
+func (s *catalogService) ExpireVouchers(ctx context.Context, now time.Time) (int, error) {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	expired, err := s.catalogRepo.ExpireVouchers(ctx, now)
+	if err != nil {
+		s.logger.Error("Error al expirar vales", err)
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// presentedVoucher finds the voucher of the code presented at the branch, which
+// the caller must be able to operate. Mistyped codes are rejected before they
+// are looked up when codes carry a check digit.
+func (s *catalogService) presentedVoucher(ctx context.Context, req catalog_requests.VoucherRequest) (*models.Voucher, error) {
+	code := codes.Normalize(req.Code)
+	if s.vouchers.CheckDigit && !codes.Valid(code) {
+		return nil, ErrInvalidVoucherCode
+	}
+
+	voucher, err := s.catalogRepo.GetVoucher(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionOperate, voucher.MerchantID, &req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	return voucher, nil
+}
+
+func (s *catalogService) newVoucher(now time.Time) (*models.Voucher, error) {
+	code, err := codes.Generate(s.vouchers.CodeLength, s.vouchers.CheckDigit)
+	if err != nil {
+		s.logger.Error("Error al generar código de vale", err)
+		return nil, err
+	}
+	voucher := &models.Voucher{Code: code, Status: models.VoucherIssued}
+	if s.vouchers.ValidityDays > 0 {
+		expiresAt := now.AddDate(0, 0, s.vouchers.ValidityDays)
+		voucher.ExpiresAt = &expiresAt
+	}
+	return voucher, nil
+}
+
+func itemBranches(branchIDs []uint) []models.CatalogItemBranch {
+	seen := make(map[uint]bool, len(branchIDs))
+	branches := make([]models.CatalogItemBranch, 0, len(branchIDs))
+	for _, branchID := range branchIDs {
+		if !seen[branchID] {
+			seen[branchID] = true
+			branches = append(branches, models.CatalogItemBranch{BranchID: branchID})
+		}
+	}
+	return branches
+}
+
+func itemToResponse(item *models.CatalogItem) *catalog_responses.CatalogItemResponse {
+	branchIDs := make([]uint, len(item.Branches))
+	for i, branch := range item.Branches {
+		branchIDs[i] = branch.BranchID
+	}
+	return &catalog_responses.CatalogItemResponse{
+		ID:          item.ID,
+		MerchantID:  item.MerchantID,
+		Name:        item.Name,
+		Description: item.Description,
+		RewardType:  item.RewardType,
+		Cost:        item.Cost,
+		Stock:       item.Stock,
+		ValidFrom:   item.ValidFrom,
+		ValidUntil:  item.ValidUntil,
+		BranchIDs:   branchIDs,
+		CreatedAt:   item.CreatedAt,
+	}
+}
+
+func redemptionToResponse(redemption *models.ItemRedemption) *catalog_responses.ItemRedemptionResponse {
+	response := &catalog_responses.ItemRedemptionResponse{
+		ID:            redemption.ID,
+		CatalogItemID: redemption.CatalogItemID,
+		UserID:        redemption.UserID,
+		MerchantID:    redemption.MerchantID,
+		BranchID:      redemption.BranchID,
+		Quantity:      redemption.Quantity,
+		RewardType:    redemption.RewardType,
+		Cost:          redemption.Cost,
+		Status:        redemption.Status,
+		CreatedAt:     redemption.CreatedAt,
+		FulfilledAt:   redemption.FulfilledAt,
+		CancelledAt:   redemption.CancelledAt,
+		CancelReason:  redemption.CancelReason,
+	}
+	if redemption.Voucher != nil {
+		response.Voucher = voucherToResponse(redemption.Voucher, false)
+	}
+	return response
+}
+
+func voucherToResponse(voucher *models.Voucher, withRedemption bool) *catalog_responses.VoucherResponse {
+	response := &catalog_responses.VoucherResponse{
+		Code:             voucher.Code,
+		ItemRedemptionID: voucher.ItemRedemptionID,
+		UserID:           voucher.UserID,
+		MerchantID:       voucher.MerchantID,
+		Status:           voucher.Status,
+		ExpiresAt:        voucher.ExpiresAt,
+		UsedAt:           voucher.UsedAt,
+		UsedBranchID:     voucher.UsedBranchID,
+		CancelledAt:      voucher.CancelledAt,
+		CreatedAt:        voucher.CreatedAt,
+	}
+	if withRedemption {
+		response.Redemption = redemptionToResponse(&voucher.ItemRedemption)
+	}
+	return response
+}
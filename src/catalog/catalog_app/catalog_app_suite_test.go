@@ -0,0 +1,13 @@
+package catalog_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCatalogApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CatalogApp Suite")
+}
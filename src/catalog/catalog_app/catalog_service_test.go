@@ -0,0 +1,414 @@
+package catalog_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/catalog/catalog_app"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_ports"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_structs/catalog_requests"
+	"loyalty-campaigns/src/common/codes"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("CatalogService", func() {
+	var (
+		catalogService catalog_app.ICatalogService
+		mockCatalog    *mockCatalogRepository
+		item           *models.CatalogItem
+		redemption     *models.ItemRedemption
+		branchID       uint
+		otherBranchID  uint
+	)
+
+	BeforeEach(func() {
+		mockCatalog = new(mockCatalogRepository)
+		catalogService = catalog_app.NewCatalogService(mockCatalog, catalog_app.VoucherPolicy{
+			CodeLength:   12,
+			CheckDigit:   true,
+			ValidityDays: 30,
+		})
+		branchID = 3
+		otherBranchID = 5
+		item = &models.CatalogItem{MerchantID: 4, Name: "Coffee", RewardType: "points", Cost: 50}
+		item.ID = 7
+		redemption = &models.ItemRedemption{
+			CatalogItemID: item.ID,
+			UserID:        1,
+			MerchantID:    item.MerchantID,
+			BranchID:      branchID,
+			Quantity:      2,
+			RewardType:    "points",
+			Cost:          100,
+			Status:        models.ItemRedemptionPending,
+		}
+		redemption.ID = 11
+	})
+
+	operator := func(branchID uint) context.Context {
+		return security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleBranchOperator,
+			MerchantID: item.MerchantID,
+			BranchID:   &branchID,
+		})
+	}
+
+	Describe("CreateItem", func() {
+		It("should reject a validity window that ends before it starts", func() {
+			validFrom := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			validUntil := validFrom.AddDate(0, 0, -1)
+			ctx := security.WithPrincipal(context.Background(), &security.Principal{Role: security.RoleMerchantAdmin, MerchantID: item.MerchantID})
+
+			_, err := catalogService.CreateItem(ctx, catalog_requests.CreateCatalogItemRequest{
+				MerchantID: item.MerchantID,
+				Name:       "Coffee",
+				RewardType: "points",
+				Cost:       50,
+				ValidFrom:  &validFrom,
+				ValidUntil: &validUntil,
+			})
+
+			Expect(err).To(MatchError(catalog_app.ErrInvalidValidity))
+			mockCatalog.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything)
+		})
+
+		It("should store each branch once", func() {
+			ctx := security.WithPrincipal(context.Background(), &security.Principal{Role: security.RoleMerchantAdmin, MerchantID: item.MerchantID})
+			mockCatalog.On("Create", mock.Anything, mock.AnythingOfType("*models.CatalogItem")).Return(nil)
+
+			response, err := catalogService.CreateItem(ctx, catalog_requests.CreateCatalogItemRequest{
+				MerchantID: item.MerchantID,
+				Name:       "Coffee",
+				RewardType: "points",
+				Cost:       50,
+				BranchIDs:  []uint{branchID, otherBranchID, branchID},
+			})
+
+			Expect(err).To(BeNil())
+			Expect(response.BranchIDs).To(Equal([]uint{branchID, otherBranchID}))
+		})
+	})
+
+	Describe("RedeemItem", func() {
+		It("should redeem one unit by default", func() {
+			mockCatalog.On("GetByID", mock.Anything, item.ID).Return(item, nil)
+			mockCatalog.On("Redeem", mock.Anything, mock.AnythingOfType("*models.ItemRedemption"), mock.AnythingOfType("time.Time")).
+				Run(func(args mock.Arguments) {
+					stored := args.Get(1).(*models.ItemRedemption)
+					stored.MerchantID = item.MerchantID
+					stored.RewardType = item.RewardType
+					stored.Cost = item.Cost * float64(stored.Quantity)
+					stored.Status = models.ItemRedemptionPending
+				}).Return(nil)
+
+			response, err := catalogService.RedeemItem(operator(branchID), catalog_requests.RedeemItemRequest{
+				UserID:        1,
+				BranchID:      branchID,
+				CatalogItemID: item.ID,
+			})
+
+			Expect(err).To(BeNil())
+			Expect(response.Quantity).To(Equal(1))
+			Expect(response.Cost).To(Equal(50.0))
+			Expect(response.Status).To(Equal(models.ItemRedemptionPending))
+			Expect(response.Voucher).NotTo(BeNil())
+			Expect(response.Voucher.Code).To(HaveLen(13))
+			Expect(codes.Valid(response.Voucher.Code)).To(BeTrue())
+			Expect(response.Voucher.Status).To(Equal(models.VoucherIssued))
+			Expect(*response.Voucher.ExpiresAt).To(BeTemporally("~", time.Now().AddDate(0, 0, 30), time.Minute))
+		})
+
+		It("should issue a different voucher for each redemption", func() {
+			mockCatalog.On("GetByID", mock.Anything, item.ID).Return(item, nil)
+			mockCatalog.On("Redeem", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			req := catalog_requests.RedeemItemRequest{UserID: 1, BranchID: branchID, CatalogItemID: item.ID}
+
+			first, err := catalogService.RedeemItem(operator(branchID), req)
+			Expect(err).To(BeNil())
+			second, err := catalogService.RedeemItem(operator(branchID), req)
+			Expect(err).To(BeNil())
+
+			Expect(first.Voucher.Code).NotTo(Equal(second.Voucher.Code))
+		})
+
+		It("should not redeem at a branch the operator does not run", func() {
+			mockCatalog.On("GetByID", mock.Anything, item.ID).Return(item, nil)
+
+			_, err := catalogService.RedeemItem(operator(otherBranchID), catalog_requests.RedeemItemRequest{
+				UserID:        1,
+				BranchID:      branchID,
+				CatalogItemID: item.ID,
+			})
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockCatalog.AssertNotCalled(GinkgoT(), "Redeem", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should return the insufficient balance of the user", func() {
+			mockCatalog.On("GetByID", mock.Anything, item.ID).Return(item, nil)
+			mockCatalog.On("Redeem", mock.Anything, mock.Anything, mock.Anything).
+				Return(domain_errors.InsufficientBalance("insufficient_rewards", "insufficient rewards"))
+
+			_, err := catalogService.RedeemItem(operator(branchID), catalog_requests.RedeemItemRequest{
+				UserID:        1,
+				BranchID:      branchID,
+				CatalogItemID: item.ID,
+				Quantity:      3,
+			})
+
+			Expect(err).To(MatchError(domain_errors.ErrInsufficientBalance))
+		})
+	})
+
+	Describe("FulfilRedemption", func() {
+		It("should be fulfilled by the branch of the redemption", func() {
+			fulfilled := *redemption
+			fulfilled.Status = models.ItemRedemptionFulfilled
+			mockCatalog.On("GetRedemption", mock.Anything, redemption.ID).Return(redemption, nil)
+			mockCatalog.On("Fulfil", mock.Anything, redemption.ID, mock.AnythingOfType("time.Time")).Return(&fulfilled, nil)
+
+			response, err := catalogService.FulfilRedemption(operator(branchID), redemption.ID)
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.ItemRedemptionFulfilled))
+		})
+
+		It("should not be fulfilled by another branch", func() {
+			mockCatalog.On("GetRedemption", mock.Anything, redemption.ID).Return(redemption, nil)
+
+			_, err := catalogService.FulfilRedemption(operator(otherBranchID), redemption.ID)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockCatalog.AssertNotCalled(GinkgoT(), "Fulfil", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("CancelRedemption", func() {
+		It("should cancel with the reason given", func() {
+			cancelled := *redemption
+			cancelled.Status = models.ItemRedemptionCancelled
+			cancelled.CancelReason = "out of coffee"
+			mockCatalog.On("GetRedemption", mock.Anything, redemption.ID).Return(redemption, nil)
+			mockCatalog.On("Cancel", mock.Anything, redemption.ID, "out of coffee", mock.AnythingOfType("time.Time")).Return(&cancelled, nil)
+
+			response, err := catalogService.CancelRedemption(operator(branchID), redemption.ID, catalog_requests.CancelRedemptionRequest{Reason: "out of coffee"})
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.ItemRedemptionCancelled))
+			Expect(response.CancelReason).To(Equal("out of coffee"))
+		})
+
+		It("should return the error of a redemption that is no longer pending", func() {
+			mockCatalog.On("GetRedemption", mock.Anything, redemption.ID).Return(redemption, nil)
+			mockCatalog.On("Cancel", mock.Anything, redemption.ID, "", mock.Anything).Return(nil, catalog_ports.ErrRedemptionNotPending)
+
+			_, err := catalogService.CancelRedemption(operator(branchID), redemption.ID, catalog_requests.CancelRedemptionRequest{})
+
+			Expect(err).To(MatchError(catalog_ports.ErrRedemptionNotPending))
+		})
+	})
+})
+
+var _ = Describe("Vouchers", func() {
+	var (
+		catalogService catalog_app.ICatalogService
+		mockCatalog    *mockCatalogRepository
+		voucher        *models.Voucher
+		branchID       uint
+		ctx            context.Context
+	)
+
+	BeforeEach(func() {
+		mockCatalog = new(mockCatalogRepository)
+		catalogService = catalog_app.NewCatalogService(mockCatalog, catalog_app.VoucherPolicy{CodeLength: 12, CheckDigit: true})
+		branchID = 3
+		code, err := codes.Generate(12, true)
+		Expect(err).To(BeNil())
+		expiresAt := time.Now().Add(time.Hour)
+		voucher = &models.Voucher{
+			Code:             code,
+			ItemRedemptionID: 11,
+			ItemRedemption:   models.ItemRedemption{CatalogItemID: 7, UserID: 1, MerchantID: 4, BranchID: branchID, Quantity: 1, Status: models.ItemRedemptionPending},
+			UserID:           1,
+			MerchantID:       4,
+			Status:           models.VoucherIssued,
+			ExpiresAt:        &expiresAt,
+		}
+		ctx = security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleBranchOperator,
+			MerchantID: voucher.MerchantID,
+			BranchID:   &branchID,
+		})
+	})
+
+	// typed spells the code as a cashier would type it.
+	typed := func(code string) string {
+		return strings.ToLower(code[:4] + "-" + code[4:8] + " " + code[8:])
+	}
+
+	Describe("ValidateVoucher", func() {
+		It("should accept an issued voucher typed with separators", func() {
+			mockCatalog.On("GetVoucher", mock.Anything, voucher.Code).Return(voucher, nil)
+
+			response, err := catalogService.ValidateVoucher(ctx, catalog_requests.VoucherRequest{Code: typed(voucher.Code), BranchID: branchID})
+
+			Expect(err).To(BeNil())
+			Expect(response.Code).To(Equal(voucher.Code))
+			Expect(response.Redemption.Quantity).To(Equal(1))
+		})
+
+		It("should reject a mistyped code without looking it up", func() {
+			first := strings.IndexByte(codes.Alphabet, voucher.Code[0])
+			mistyped := string(codes.Alphabet[(first+1)%len(codes.Alphabet)]) + voucher.Code[1:]
+
+			_, err := catalogService.ValidateVoucher(ctx, catalog_requests.VoucherRequest{Code: mistyped, BranchID: branchID})
+
+			Expect(err).To(MatchError(catalog_app.ErrInvalidVoucherCode))
+			mockCatalog.AssertNotCalled(GinkgoT(), "GetVoucher", mock.Anything, mock.Anything)
+		})
+
+		It("should reject a voucher past its expiry before the job expires it", func() {
+			expiresAt := time.Now().Add(-time.Minute)
+			voucher.ExpiresAt = &expiresAt
+			mockCatalog.On("GetVoucher", mock.Anything, voucher.Code).Return(voucher, nil)
+
+			_, err := catalogService.ValidateVoucher(ctx, catalog_requests.VoucherRequest{Code: voucher.Code, BranchID: branchID})
+
+			Expect(err).To(MatchError(catalog_ports.ErrVoucherExpired))
+		})
+
+		It("should reject a used voucher", func() {
+			voucher.Status = models.VoucherUsed
+			mockCatalog.On("GetVoucher", mock.Anything, voucher.Code).Return(voucher, nil)
+
+			_, err := catalogService.ValidateVoucher(ctx, catalog_requests.VoucherRequest{Code: voucher.Code, BranchID: branchID})
+
+			Expect(err).To(MatchError(catalog_ports.ErrVoucherUsed))
+		})
+	})
+
+	Describe("ConsumeVoucher", func() {
+		It("should consume the voucher at the branch", func() {
+			used := *voucher
+			used.Status = models.VoucherUsed
+			used.UsedBranchID = &branchID
+			mockCatalog.On("GetVoucher", mock.Anything, voucher.Code).Return(voucher, nil)
+			mockCatalog.On("ConsumeVoucher", mock.Anything, voucher.Code, branchID, mock.AnythingOfType("time.Time")).Return(&used, nil)
+
+			response, err := catalogService.ConsumeVoucher(ctx, catalog_requests.VoucherRequest{Code: typed(voucher.Code), BranchID: branchID})
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.VoucherUsed))
+			Expect(*response.UsedBranchID).To(Equal(branchID))
+		})
+
+		It("should not be consumed at another branch by its operator", func() {
+			mockCatalog.On("GetVoucher", mock.Anything, voucher.Code).Return(voucher, nil)
+
+			_, err := catalogService.ConsumeVoucher(ctx, catalog_requests.VoucherRequest{Code: voucher.Code, BranchID: branchID + 1})
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockCatalog.AssertNotCalled(GinkgoT(), "ConsumeVoucher", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+})
+
+type mockCatalogRepository struct {
+	mock.Mock
+}
+
+func (m *mockCatalogRepository) Create(ctx context.Context, item *models.CatalogItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *mockCatalogRepository) GetByID(ctx context.Context, id uint) (*models.CatalogItem, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CatalogItem), args.Error(1)
+}
+
+func (m *mockCatalogRepository) Update(ctx context.Context, item *models.CatalogItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *mockCatalogRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockCatalogRepository) List(ctx context.Context, filter catalog_ports.CatalogItemFilter, page pagination.Request) (*pagination.Page[models.CatalogItem], error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pagination.Page[models.CatalogItem]), args.Error(1)
+}
+
+func (m *mockCatalogRepository) Redeem(ctx context.Context, redemption *models.ItemRedemption, now time.Time) error {
+	args := m.Called(ctx, redemption, now)
+	return args.Error(0)
+}
+
+func (m *mockCatalogRepository) GetRedemption(ctx context.Context, id uint) (*models.ItemRedemption, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemRedemption), args.Error(1)
+}
+
+func (m *mockCatalogRepository) ListRedemptions(ctx context.Context, filter catalog_ports.ItemRedemptionFilter, page pagination.Request) (*pagination.Page[models.ItemRedemption], error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pagination.Page[models.ItemRedemption]), args.Error(1)
+}
+
+func (m *mockCatalogRepository) Fulfil(ctx context.Context, id uint, now time.Time) (*models.ItemRedemption, error) {
+	args := m.Called(ctx, id, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemRedemption), args.Error(1)
+}
+
+func (m *mockCatalogRepository) Cancel(ctx context.Context, id uint, reason string, now time.Time) (*models.ItemRedemption, error) {
+	args := m.Called(ctx, id, reason, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ItemRedemption), args.Error(1)
+}
+
+func (m *mockCatalogRepository) GetVoucher(ctx context.Context, code string) (*models.Voucher, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Voucher), args.Error(1)
+}
+
+func (m *mockCatalogRepository) ConsumeVoucher(ctx context.Context, code string, branchID uint, now time.Time) (*models.Voucher, error) {
+	args := m.Called(ctx, code, branchID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Voucher), args.Error(1)
+}
+
+func (m *mockCatalogRepository) ExpireVouchers(ctx context.Context, now time.Time) ([]models.Voucher, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]models.Voucher), args.Error(1)
+}
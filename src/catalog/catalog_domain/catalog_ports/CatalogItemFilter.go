@@ -0,0 +1,13 @@
+package catalog_ports
+
+import "time"
+
+// CatalogItemFilter selects the items of a merchant. BranchID keeps the items
+// available in the branch, and AvailableAt those within their validity window
+// and in stock at that time.
+type CatalogItemFilter struct {
+	MerchantID  *uint
+	BranchID    *uint
+	Name        string
+	AvailableAt *time.Time
+}
@@ -0,0 +1,65 @@
+package catalog_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+var (
+	ErrBranchNotOwned       = domain_errors.Validation("branch_not_owned", "branch does not belong to the merchant")
+	ErrItemNotAvailable     = domain_errors.Validation("item_not_available", "the item is not available at the branch at this time")
+	ErrOutOfStock           = domain_errors.Conflict("item_out_of_stock", "not enough units of the item in stock")
+	ErrRedemptionNotPending = domain_errors.Conflict("redemption_not_pending", "the redemption was already fulfilled or cancelled")
+	ErrVoucherUsed          = domain_errors.Conflict("voucher_used", "the voucher was already used")
+	ErrVoucherExpired       = domain_errors.Conflict("voucher_expired", "the voucher expired")
+	ErrVoucherCancelled     = domain_errors.Conflict("voucher_cancelled", "the voucher was cancelled")
+)
+
+// VoucherError returns why the voucher cannot be used at now, or nil if it can.
+func VoucherError(voucher *models.Voucher, now time.Time) error {
+	switch {
+	case voucher.Status == models.VoucherUsed:
+		return ErrVoucherUsed
+	case voucher.Status == models.VoucherCancelled:
+		return ErrVoucherCancelled
+	case voucher.Status == models.VoucherExpired, voucher.ExpiresAt != nil && !now.Before(*voucher.ExpiresAt):
+		return ErrVoucherExpired
+	default:
+		return nil
+	}
+}
+
+type ICatalogRepository interface {
+	// Create and Update reject branches of other merchants with ErrBranchNotOwned.
+	Create(ctx context.Context, item *models.CatalogItem) error
+	GetByID(ctx context.Context, id uint) (*models.CatalogItem, error)
+	Update(ctx context.Context, item *models.CatalogItem) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter CatalogItemFilter, page pagination.Request) (*pagination.Page[models.CatalogItem], error)
+	// Redeem locks the item, checks that it is available at the branch and in
+	// stock at now, and in a single database transaction decrements its stock,
+	// consumes the cost from the user's rewards and records the redemption,
+	// whose reward type and cost are taken from the item, with its voucher.
+	Redeem(ctx context.Context, redemption *models.ItemRedemption, now time.Time) error
+	GetRedemption(ctx context.Context, id uint) (*models.ItemRedemption, error)
+	ListRedemptions(ctx context.Context, filter ItemRedemptionFilter, page pagination.Request) (*pagination.Page[models.ItemRedemption], error)
+	// Fulfil and Cancel change a pending redemption, or fail with
+	// ErrRedemptionNotPending. Cancel puts the units back in stock and refunds
+	// the cost as a new reward, valid for the merchant's reward validity. The
+	// issued voucher of the redemption is used or cancelled along with it.
+	Fulfil(ctx context.Context, id uint, now time.Time) (*models.ItemRedemption, error)
+	Cancel(ctx context.Context, id uint, reason string, now time.Time) (*models.ItemRedemption, error)
+	// GetVoucher finds a voucher by its normalized code, with its redemption.
+	GetVoucher(ctx context.Context, code string) (*models.Voucher, error)
+	// ConsumeVoucher locks the redemption of the voucher, checks the voucher with
+	// VoucherError and that the branch belongs to the merchant, and marks the
+	// voucher used at the branch and the redemption fulfilled. Concurrent uses
+	// of a voucher are serialized, so only one of them succeeds.
+	ConsumeVoucher(ctx context.Context, code string, branchID uint, now time.Time) (*models.Voucher, error)
+	// ExpireVouchers marks the issued vouchers that expired by now as expired
+	// and cancels their redemptions with a refund, and returns them.
+	ExpireVouchers(ctx context.Context, now time.Time) ([]models.Voucher, error)
+}
@@ -0,0 +1,9 @@
+package catalog_ports
+
+type ItemRedemptionFilter struct {
+	MerchantID    *uint
+	BranchID      *uint
+	UserID        *uint
+	CatalogItemID *uint
+	Status        string
+}
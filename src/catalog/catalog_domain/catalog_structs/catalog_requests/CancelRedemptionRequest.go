@@ -0,0 +1,5 @@
+package catalog_requests
+
+type CancelRedemptionRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
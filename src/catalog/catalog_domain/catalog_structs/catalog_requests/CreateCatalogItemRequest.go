@@ -0,0 +1,18 @@
+package catalog_requests
+
+import "time"
+
+type CreateCatalogItemRequest struct {
+	MerchantID  uint    `json:"merchantId" binding:"required"`
+	Name        string  `json:"name" binding:"required,max=200"`
+	Description string  `json:"description"`
+	RewardType  string  `json:"rewardType" binding:"required,oneof=points cashback"`
+	Cost        float64 `json:"cost" binding:"required,gt=0"`
+	// Stock is unlimited when omitted.
+	Stock      *int       `json:"stock" binding:"omitempty,min=0"`
+	ValidFrom  *time.Time `json:"validFrom"`
+	ValidUntil *time.Time `json:"validUntil"`
+	// BranchIDs restricts the item to some branches; it is available in all
+	// of them when empty.
+	BranchIDs []uint `json:"branchIds"`
+}
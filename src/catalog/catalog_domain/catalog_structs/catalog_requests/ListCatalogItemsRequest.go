@@ -0,0 +1,13 @@
+package catalog_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListCatalogItemsRequest accepts sort by id, name, cost or createdAt.
+// Available keeps the items that can be redeemed now, at BranchID if given.
+type ListCatalogItemsRequest struct {
+	pagination.Request
+	MerchantID *uint  `form:"merchantId"`
+	BranchID   *uint  `form:"branchId"`
+	Name       string `form:"name"`
+	Available  bool   `form:"available"`
+}
@@ -0,0 +1,13 @@
+package catalog_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListItemRedemptionsRequest accepts sort by id or createdAt.
+type ListItemRedemptionsRequest struct {
+	pagination.Request
+	MerchantID    *uint  `form:"merchantId"`
+	BranchID      *uint  `form:"branchId"`
+	UserID        *uint  `form:"userId"`
+	CatalogItemID *uint  `form:"catalogItemId"`
+	Status        string `form:"status" binding:"omitempty,oneof=pending fulfilled cancelled"`
+}
@@ -0,0 +1,9 @@
+package catalog_requests
+
+type RedeemItemRequest struct {
+	UserID        uint `json:"userId" binding:"required"`
+	BranchID      uint `json:"branchId" binding:"required"`
+	CatalogItemID uint `json:"catalogItemId" binding:"required"`
+	// Quantity defaults to one unit.
+	Quantity int `json:"quantity" binding:"omitempty,min=1,max=100"`
+}
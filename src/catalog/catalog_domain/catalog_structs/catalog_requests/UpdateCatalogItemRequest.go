@@ -0,0 +1,14 @@
+package catalog_requests
+
+import "time"
+
+type UpdateCatalogItemRequest struct {
+	Name        string     `json:"name" binding:"required,max=200"`
+	Description string     `json:"description"`
+	RewardType  string     `json:"rewardType" binding:"required,oneof=points cashback"`
+	Cost        float64    `json:"cost" binding:"required,gt=0"`
+	Stock       *int       `json:"stock" binding:"omitempty,min=0"`
+	ValidFrom   *time.Time `json:"validFrom"`
+	ValidUntil  *time.Time `json:"validUntil"`
+	BranchIDs   []uint     `json:"branchIds"`
+}
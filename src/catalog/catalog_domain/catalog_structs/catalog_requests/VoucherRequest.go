@@ -0,0 +1,8 @@
+package catalog_requests
+
+// VoucherRequest identifies a voucher presented at a branch. The code is
+// accepted in any case and with separators.
+type VoucherRequest struct {
+	Code     string `json:"code" binding:"required,max=64"`
+	BranchID uint   `json:"branchId" binding:"required"`
+}
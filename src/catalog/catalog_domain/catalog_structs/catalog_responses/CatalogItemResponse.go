@@ -0,0 +1,17 @@
+package catalog_responses
+
+import "time"
+
+type CatalogItemResponse struct {
+	ID          uint       `json:"id"`
+	MerchantID  uint       `json:"merchantId"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	RewardType  string     `json:"rewardType"`
+	Cost        float64    `json:"cost"`
+	Stock       *int       `json:"stock"`
+	ValidFrom   *time.Time `json:"validFrom"`
+	ValidUntil  *time.Time `json:"validUntil"`
+	BranchIDs   []uint     `json:"branchIds"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,21 @@
+package catalog_responses
+
+import "time"
+
+type ItemRedemptionResponse struct {
+	ID            uint       `json:"id"`
+	CatalogItemID uint       `json:"catalogItemId"`
+	UserID        uint       `json:"userId"`
+	MerchantID    uint       `json:"merchantId"`
+	BranchID      uint       `json:"branchId"`
+	Quantity      int        `json:"quantity"`
+	RewardType    string     `json:"rewardType"`
+	Cost          float64    `json:"cost"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	FulfilledAt   *time.Time `json:"fulfilledAt,omitempty"`
+	CancelledAt   *time.Time `json:"cancelledAt,omitempty"`
+	CancelReason  string     `json:"cancelReason,omitempty"`
+	// Voucher is missing for redemptions made before vouchers were issued.
+	Voucher *VoucherResponse `json:"voucher,omitempty"`
+}
@@ -0,0 +1,18 @@
+package catalog_responses
+
+import "time"
+
+type VoucherResponse struct {
+	Code             string     `json:"code"`
+	ItemRedemptionID uint       `json:"itemRedemptionId"`
+	UserID           uint       `json:"userId"`
+	MerchantID       uint       `json:"merchantId"`
+	Status           string     `json:"status"`
+	ExpiresAt        *time.Time `json:"expiresAt"`
+	UsedAt           *time.Time `json:"usedAt,omitempty"`
+	UsedBranchID     *uint      `json:"usedBranchId,omitempty"`
+	CancelledAt      *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	// Redemption is included when the voucher is validated or used.
+	Redemption *ItemRedemptionResponse `json:"redemption,omitempty"`
+}
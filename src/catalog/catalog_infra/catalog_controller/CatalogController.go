@@ -0,0 +1,396 @@
+package catalog_controller
+
+import (
+	"loyalty-campaigns/src/catalog/catalog_app"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_structs/catalog_requests"
+	"loyalty-campaigns/src/catalog/catalog_infra/catalog_repository"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogController struct {
+	catalogService catalog_app.ICatalogService
+}
+
+var (
+	catalogControllerInstance *CatalogController
+	catalogControllerOnce     sync.Once
+)
+
+func NewCatalogController(router *gin.RouterGroup) *CatalogController {
+	catalogControllerOnce.Do(func() {
+		catalogControllerInstance = &CatalogController{}
+		db := configs.NewDBConnection().GetDB()
+		catalogRepository := catalog_repository.NewGormCatalogRepository(db)
+		catalogControllerInstance.catalogService = catalog_app.NewCatalogService(catalogRepository, catalog_app.VoucherPolicyFromEnv())
+		catalogControllerInstance.setupCatalogRoutes(router)
+	})
+	return catalogControllerInstance
+}
+
+func (c *CatalogController) setupCatalogRoutes(router *gin.RouterGroup) {
+	catalogGroup := router.Group("/catalog")
+	{
+		catalogGroup.POST("/items", c.CreateItem)
+		catalogGroup.GET("/items", c.ListItems)
+		catalogGroup.GET("/items/:id", c.GetItem)
+		catalogGroup.PUT("/items/:id", c.UpdateItem)
+		catalogGroup.DELETE("/items/:id", c.DeleteItem)
+		catalogGroup.GET("/redemptions", c.ListRedemptions)
+		catalogGroup.GET("/redemptions/:id", c.GetRedemption)
+		catalogGroup.POST("/redemptions/:id/fulfil", c.FulfilRedemption)
+		catalogGroup.POST("/redemptions/:id/cancel", c.CancelRedemption)
+	}
+	voucherGroup := router.Group("/vouchers")
+	{
+		voucherGroup.POST("/validate", c.ValidateVoucher)
+		voucherGroup.POST("/consume", c.ConsumeVoucher)
+	}
+}
+
+// CreateItem godoc
+//
+//	@Summary		Create a catalog item
+//	@Description	Add an item to the catalog of a merchant, redeemable for its cost in points or cashback. Stock is unlimited when omitted, and an item without branchIds is available in all the branches of the merchant.
+//	@Tags			catalog
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		catalog_requests.CreateCatalogItemRequest	true	"Catalog item"
+//	@Success		201		{object}	catalog_responses.CatalogItemResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/catalog/items [post]
+func (c *CatalogController) CreateItem(ctx *gin.Context) {
+	var req catalog_requests.CreateCatalogItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.catalogService.CreateItem(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// ListItems godoc
+//
+//	@Summary		List catalog items
+//	@Description	Get a page of catalog items filtered by merchant, branch and name; available=true keeps those that can be redeemed now. Sort by id, name, cost or createdAt, prefixed with "-" for descending order.
+//	@Tags			catalog
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	query		catalog_requests.ListCatalogItemsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[catalog_responses.CatalogItemResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/catalog/items [get]
+func (c *CatalogController) ListItems(ctx *gin.Context) {
+	var req catalog_requests.ListCatalogItemsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.catalogService.ListItems(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetItem godoc
+//
+//	@Summary		Get a catalog item
+//	@Description	Get a catalog item by its ID
+//	@Tags			catalog
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Catalog item ID"
+//	@Success		200	{object}	catalog_responses.CatalogItemResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/catalog/items/{id} [get]
+func (c *CatalogController) GetItem(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.catalogService.GetItem(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateItem godoc
+//
+//	@Summary		Update a catalog item
+//	@Description	Update a catalog item and replace its branches. Pending redemptions keep the cost they were paid with.
+//	@Tags			catalog
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int											true	"Catalog item ID"
+//	@Param			request	body		catalog_requests.UpdateCatalogItemRequest	true	"Catalog item"
+//	@Success		200		{object}	catalog_responses.CatalogItemResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Router			/api/catalog/items/{id} [put]
+func (c *CatalogController) UpdateItem(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req catalog_requests.UpdateCatalogItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.catalogService.UpdateItem(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeleteItem godoc
+//
+//	@Summary		Delete a catalog item
+//	@Description	Remove an item from the catalog. Its pending redemptions can still be fulfilled or cancelled.
+//	@Tags			catalog
+//	@Security		ApiKeyAuth
+//	@Param			id	path	int	true	"Catalog item ID"
+//	@Success		204	"No Content"
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/catalog/items/{id} [delete]
+func (c *CatalogController) DeleteItem(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	err = c.catalogService.DeleteItem(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// ListRedemptions godoc
+//
+//	@Summary		List item redemptions
+//	@Description	Get a page of catalog item redemptions filtered by merchant, branch, user, item and status. Sort by id or createdAt, prefixed with "-" for descending order; newest first by default.
+//	@Tags			catalog
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	query		catalog_requests.ListItemRedemptionsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[catalog_responses.ItemRedemptionResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/catalog/redemptions [get]
+func (c *CatalogController) ListRedemptions(ctx *gin.Context) {
+	var req catalog_requests.ListItemRedemptionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.catalogService.ListRedemptions(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetRedemption godoc
+//
+//	@Summary		Get an item redemption
+//	@Description	Get a catalog item redemption by its ID
+//	@Tags			catalog
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Redemption ID"
+//	@Success		200	{object}	catalog_responses.ItemRedemptionResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/catalog/redemptions/{id} [get]
+func (c *CatalogController) GetRedemption(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.catalogService.GetRedemption(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FulfilRedemption godoc
+//
+//	@Summary		Fulfil an item redemption
+//	@Description	Record that the branch of a pending redemption handed the item over to the user
+//	@Tags			catalog
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Redemption ID"
+//	@Success		200	{object}	catalog_responses.ItemRedemptionResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Failure		409	{object}	domain_errors.Problem
+//	@Router			/api/catalog/redemptions/{id}/fulfil [post]
+func (c *CatalogController) FulfilRedemption(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.catalogService.FulfilRedemption(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CancelRedemption godoc
+//
+//	@Summary		Cancel an item redemption
+//	@Description	Cancel a pending redemption: the units go back in stock and the cost is refunded to the user as a new reward.
+//	@Tags			catalog
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int										true	"Redemption ID"
+//	@Param			request	body		catalog_requests.CancelRedemptionRequest	false	"Reason of the cancellation"
+//	@Success		200		{object}	catalog_responses.ItemRedemptionResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Router			/api/catalog/redemptions/{id}/cancel [post]
+func (c *CatalogController) CancelRedemption(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req catalog_requests.CancelRedemptionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.Error(domain_errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	response, err := c.catalogService.CancelRedemption(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ValidateVoucher godoc
+//
+//	@Summary		Validate a voucher
+//	@Description	Check that a voucher can be used at a branch without using it. Mistyped codes are rejected with invalid_voucher_code when codes carry a check digit, and vouchers that cannot be used with voucher_used, voucher_expired or voucher_cancelled (409).
+//	@Tags			vouchers
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		catalog_requests.VoucherRequest	true	"Voucher code and branch"
+//	@Success		200		{object}	catalog_responses.VoucherResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Router			/api/vouchers/validate [post]
+func (c *CatalogController) ValidateVoucher(ctx *gin.Context) {
+	var req catalog_requests.VoucherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.catalogService.ValidateVoucher(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ConsumeVoucher godoc
+//
+//	@Summary		Consume a voucher
+//	@Description	Use a voucher at a branch of its merchant, which fulfils its redemption. A voucher is used once: of concurrent attempts only one succeeds and the rest fail with voucher_used (409).
+//	@Tags			vouchers
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		catalog_requests.VoucherRequest	true	"Voucher code and branch"
+//	@Success		200		{object}	catalog_responses.VoucherResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Router			/api/vouchers/consume [post]
+func (c *CatalogController) ConsumeVoucher(ctx *gin.Context) {
+	var req catalog_requests.VoucherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.catalogService.ConsumeVoucher(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,454 @@
+package catalog_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormCatalogRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormCatalogRepository(db *gorm.DB) catalog_ports.ICatalogRepository {
+	return &GormCatalogRepository{DB: db}
+}
+
+func (r *GormCatalogRepository) Create(ctx context.Context, item *models.CatalogItem) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := checkBranches(tx, item.MerchantID, item.Branches)
+		if err != nil {
+			return err
+		}
+		return tx.Create(item).Error
+	})
+	return domain_errors.Translate(err, "catalog_item")
+}
+
+func (r *GormCatalogRepository) GetByID(ctx context.Context, id uint) (*models.CatalogItem, error) {
+	var item models.CatalogItem
+	err := configs.DB(ctx, r.DB).Preload("Branches").First(&item, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "catalog_item")
+	}
+	return &item, nil
+}
+
+// Update replaces the branches of the item with those given.
+func (r *GormCatalogRepository) Update(ctx context.Context, item *models.CatalogItem) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := checkBranches(tx, item.MerchantID, item.Branches)
+		if err != nil {
+			return err
+		}
+		err = tx.Omit(clause.Associations).Save(item).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("catalog_item_id = ?", item.ID).Delete(&models.CatalogItemBranch{}).Error
+		if err != nil {
+			return err
+		}
+		if len(item.Branches) == 0 {
+			return nil
+		}
+		for i := range item.Branches {
+			item.Branches[i].CatalogItemID = item.ID
+		}
+		return tx.Create(&item.Branches).Error
+	})
+	return domain_errors.Translate(err, "catalog_item")
+}
+
+func checkBranches(tx *gorm.DB, merchantID uint, branches []models.CatalogItemBranch) error {
+	if len(branches) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(branches))
+	for i, branch := range branches {
+		ids[i] = branch.BranchID
+	}
+
+	var owned int64
+	err := tx.Model(&models.Branch{}).Where("id IN ? AND merchant_id = ?", ids, merchantID).Count(&owned).Error
+	if err != nil {
+		return err
+	}
+	if owned != int64(len(ids)) {
+		return catalog_ports.ErrBranchNotOwned
+	}
+	return nil
+}
+
+func (r *GormCatalogRepository) Delete(ctx context.Context, id uint) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Delete(&models.CatalogItem{}, id).Error, "catalog_item")
+}
+
+var catalogItemSorting = pagination.Sorting[models.CatalogItem]{
+	IDColumn: "id",
+	ID:       func(item *models.CatalogItem) uint { return item.ID },
+	Fields: map[string]pagination.Key[models.CatalogItem]{
+		"id":        {Column: "id", Value: func(item *models.CatalogItem) any { return item.ID }},
+		"name":      {Column: "name", Value: func(item *models.CatalogItem) any { return item.Name }},
+		"cost":      {Column: "cost", Value: func(item *models.CatalogItem) any { return item.Cost }},
+		"createdAt": {Column: "created_at", Value: func(item *models.CatalogItem) any { return item.CreatedAt }},
+	},
+	Default: "id",
+}
+
+func (r *GormCatalogRepository) List(ctx context.Context, filter catalog_ports.CatalogItemFilter, page pagination.Request) (*pagination.Page[models.CatalogItem], error) {
+	query := configs.DB(ctx, r.DB).Preload("Branches")
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.BranchID != nil {
+		query = query.Where(`NOT EXISTS (SELECT 1 FROM catalog_item_branches WHERE catalog_item_branches.catalog_item_id = catalog_items.id)
+			OR EXISTS (SELECT 1 FROM catalog_item_branches WHERE catalog_item_branches.catalog_item_id = catalog_items.id AND catalog_item_branches.branch_id = ?)`, *filter.BranchID)
+	}
+	if filter.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.AvailableAt != nil {
+		query = query.
+			Where("valid_from IS NULL OR valid_from <= ?", *filter.AvailableAt).
+			Where("valid_until IS NULL OR valid_until >= ?", *filter.AvailableAt).
+			Where("stock IS NULL OR stock > 0")
+	}
+	return pagination.Find(query, page, catalogItemSorting)
+}
+
+func (r *GormCatalogRepository) Redeem(ctx context.Context, redemption *models.ItemRedemption, now time.Time) error {
+	return configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var item models.CatalogItem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, redemption.CatalogItemID).Error
+		if err != nil {
+			return domain_errors.Translate(err, "catalog_item")
+		}
+		if (item.ValidFrom != nil && now.Before(*item.ValidFrom)) || (item.ValidUntil != nil && now.After(*item.ValidUntil)) {
+			return catalog_ports.ErrItemNotAvailable
+		}
+
+		var branches []models.CatalogItemBranch
+		err = tx.Where("catalog_item_id = ?", item.ID).Find(&branches).Error
+		if err != nil {
+			return err
+		}
+		if len(branches) > 0 {
+			available := false
+			for _, branch := range branches {
+				available = available || branch.BranchID == redemption.BranchID
+			}
+			if !available {
+				return catalog_ports.ErrItemNotAvailable
+			}
+		} else {
+			err = checkBranches(tx, item.MerchantID, []models.CatalogItemBranch{{BranchID: redemption.BranchID}})
+			if err != nil {
+				return err
+			}
+		}
+
+		if item.Stock != nil {
+			if *item.Stock < redemption.Quantity {
+				return catalog_ports.ErrOutOfStock
+			}
+			err = tx.Model(&item).Update("stock", gorm.Expr("stock - ?", redemption.Quantity)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		redemption.MerchantID = item.MerchantID
+		redemption.RewardType = item.RewardType
+		redemption.Cost = item.Cost * float64(redemption.Quantity)
+		redemption.Status = models.ItemRedemptionPending
+		if redemption.Voucher != nil {
+			redemption.Voucher.UserID = redemption.UserID
+			redemption.Voucher.MerchantID = redemption.MerchantID
+		}
+		err = reward_repository.ConsumeRewards(tx, redemption.UserID, redemption.MerchantID, redemption.RewardType, redemption.Cost)
+		if err != nil {
+			return domain_errors.Translate(err, "reward")
+		}
+
+		err = tx.Create(redemption).Error
+		if err != nil {
+			return domain_errors.Translate(err, "item_redemption")
+		}
+		return events.Enqueue(tx, events.ForItemRedemption(events.ItemRedeemed, redemption))
+	})
+}
+
+func (r *GormCatalogRepository) GetRedemption(ctx context.Context, id uint) (*models.ItemRedemption, error) {
+	var redemption models.ItemRedemption
+	err := configs.DB(ctx, r.DB).Preload("Voucher").First(&redemption, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "item_redemption")
+	}
+	return &redemption, nil
+}
+
+var redemptionSorting = pagination.Sorting[models.ItemRedemption]{
+	IDColumn: "id",
+	ID:       func(redemption *models.ItemRedemption) uint { return redemption.ID },
+	Fields: map[string]pagination.Key[models.ItemRedemption]{
+		"id":        {Column: "id", Value: func(redemption *models.ItemRedemption) any { return redemption.ID }},
+		"createdAt": {Column: "created_at", Value: func(redemption *models.ItemRedemption) any { return redemption.CreatedAt }},
+	},
+	Default: "-createdAt",
+}
+
+func (r *GormCatalogRepository) ListRedemptions(ctx context.Context, filter catalog_ports.ItemRedemptionFilter, page pagination.Request) (*pagination.Page[models.ItemRedemption], error) {
+	query := configs.DB(ctx, r.DB).Preload("Voucher")
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.BranchID != nil {
+		query = query.Where("branch_id = ?", *filter.BranchID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.CatalogItemID != nil {
+		query = query.Where("catalog_item_id = ?", *filter.CatalogItemID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return pagination.Find(query, page, redemptionSorting)
+}
+
+func (r *GormCatalogRepository) Fulfil(ctx context.Context, id uint, now time.Time) (*models.ItemRedemption, error) {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		redemption, err := lockPendingRedemption(tx, id)
+		if err != nil {
+			return err
+		}
+		err = closeVoucher(tx, redemption, models.VoucherUsed, now)
+		if err != nil {
+			return err
+		}
+		return fulfilRedemption(tx, redemption, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetRedemption(ctx, id)
+}
+
+func (r *GormCatalogRepository) Cancel(ctx context.Context, id uint, reason string, now time.Time) (*models.ItemRedemption, error) {
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		redemption, err := lockPendingRedemption(tx, id)
+		if err != nil {
+			return err
+		}
+		err = closeVoucher(tx, redemption, models.VoucherCancelled, now)
+		if err != nil {
+			return err
+		}
+		return cancelRedemption(tx, redemption, reason, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetRedemption(ctx, id)
+}
+
+func (r *GormCatalogRepository) GetVoucher(ctx context.Context, code string) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := configs.DB(ctx, r.DB).Preload("ItemRedemption").Where("code = ?", code).First(&voucher).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "voucher")
+	}
+	return &voucher, nil
+}
+
+func (r *GormCatalogRepository) ConsumeVoucher(ctx context.Context, code string, branchID uint, now time.Time) (*models.Voucher, error) {
+	var voucher *models.Voucher
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var err error
+		voucher, err = lockVoucher(tx, code)
+		if err != nil {
+			return err
+		}
+		err = catalog_ports.VoucherError(voucher, now)
+		if err != nil {
+			return err
+		}
+		err = checkBranches(tx, voucher.MerchantID, []models.CatalogItemBranch{{BranchID: branchID}})
+		if err != nil {
+			return err
+		}
+
+		voucher.Status = models.VoucherUsed
+		voucher.UsedAt = &now
+		voucher.UsedBranchID = &branchID
+		err = tx.Omit(clause.Associations).Save(voucher).Error
+		if err != nil {
+			return err
+		}
+		return fulfilRedemption(tx, &voucher.ItemRedemption, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return voucher, nil
+}
+
+func (r *GormCatalogRepository) ExpireVouchers(ctx context.Context, now time.Time) ([]models.Voucher, error) {
+	var codes []string
+	err := configs.DB(ctx, r.DB).
+		Model(&models.Voucher{}).
+		Where("status = ? AND expires_at <= ?", models.VoucherIssued, now).
+		Order("expires_at").
+		Pluck("code", &codes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Each voucher is expired in its own transaction, so that a voucher used
+	// in the meantime does not hold back the rest.
+	var expired []models.Voucher
+	for _, code := range codes {
+		err = configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+			voucher, err := lockVoucher(tx, code)
+			if err != nil {
+				return err
+			}
+			if voucher.Status != models.VoucherIssued || voucher.ExpiresAt == nil || now.Before(*voucher.ExpiresAt) {
+				return nil
+			}
+
+			voucher.Status = models.VoucherExpired
+			err = tx.Omit(clause.Associations).Save(voucher).Error
+			if err != nil {
+				return err
+			}
+			if voucher.ItemRedemption.Status == models.ItemRedemptionPending {
+				err = cancelRedemption(tx, &voucher.ItemRedemption, "voucher_expired", now)
+				if err != nil {
+					return err
+				}
+			}
+			expired = append(expired, *voucher)
+			return nil
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// lockPendingRedemption locks a redemption that has to be pending.
+func lockPendingRedemption(tx *gorm.DB, id uint) (*models.ItemRedemption, error) {
+	var redemption models.ItemRedemption
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&redemption, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "item_redemption")
+	}
+	if redemption.Status != models.ItemRedemptionPending {
+		return nil, catalog_ports.ErrRedemptionNotPending
+	}
+	return &redemption, nil
+}
+
+// lockVoucher locks a voucher and its redemption. The redemption is locked
+// first, as Fulfil and Cancel do, so that they cannot deadlock.
+func lockVoucher(tx *gorm.DB, code string) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := tx.Where("code = ?", code).First(&voucher).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "voucher")
+	}
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&voucher.ItemRedemption, voucher.ItemRedemptionID).Error
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&voucher, voucher.ID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// closeVoucher moves the voucher of a redemption to status if it is still
+// issued; redemptions paid for before vouchers existed have none.
+func closeVoucher(tx *gorm.DB, redemption *models.ItemRedemption, status string, now time.Time) error {
+	updates := map[string]any{"status": status}
+	switch status {
+	case models.VoucherUsed:
+		updates["used_at"] = now
+		updates["used_branch_id"] = redemption.BranchID
+	case models.VoucherCancelled:
+		updates["cancelled_at"] = now
+	}
+	return tx.Model(&models.Voucher{}).
+		Where("item_redemption_id = ? AND status = ?", redemption.ID, models.VoucherIssued).
+		Updates(updates).Error
+}
+
+func fulfilRedemption(tx *gorm.DB, redemption *models.ItemRedemption, now time.Time) error {
+	if redemption.Status != models.ItemRedemptionPending {
+		return catalog_ports.ErrRedemptionNotPending
+	}
+	redemption.Status = models.ItemRedemptionFulfilled
+	redemption.FulfilledAt = &now
+	return saveRedemption(tx, redemption, events.ItemFulfilled)
+}
+
+// cancelRedemption puts the units back in stock and refunds the cost as a new
+// reward. The items of stamp cards cost nothing and have nothing to refund.
+func cancelRedemption(tx *gorm.DB, redemption *models.ItemRedemption, reason string, now time.Time) error {
+	redemption.Status = models.ItemRedemptionCancelled
+	redemption.CancelledAt = &now
+	redemption.CancelReason = reason
+
+	err := tx.Model(&models.CatalogItem{}).
+		Where("id = ? AND stock IS NOT NULL", redemption.CatalogItemID).
+		Update("stock", gorm.Expr("stock + ?", redemption.Quantity)).Error
+	if err != nil {
+		return err
+	}
+	if redemption.Cost == 0 {
+		return saveRedemption(tx, redemption, events.ItemCancelled)
+	}
+
+	var merchant models.Merchant
+	err = tx.First(&merchant, redemption.MerchantID).Error
+	if err != nil {
+		return err
+	}
+	refund := &models.Reward{
+		UserID:     redemption.UserID,
+		MerchantID: redemption.MerchantID,
+		Type:       redemption.RewardType,
+		Amount:     redemption.Cost,
+	}
+	if merchant.RewardValidityDays != nil {
+		expiry := now.AddDate(0, 0, *merchant.RewardValidityDays)
+		refund.ExpiryDate = &expiry
+	}
+	err = reward_repository.GrantReward(tx, refund, events.RewardRefunded)
+	if err != nil {
+		return err
+	}
+	return saveRedemption(tx, redemption, events.ItemCancelled)
+}
+
+func saveRedemption(tx *gorm.DB, redemption *models.ItemRedemption, eventType string) error {
+	err := tx.Omit(clause.Associations).Save(redemption).Error
+	if err != nil {
+		return err
+	}
+	return events.Enqueue(tx, events.ForItemRedemption(eventType, redemption))
+}
@@ -0,0 +1,342 @@
+package challenge_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_ports"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_structs/challenge_requests"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_structs/challenge_responses"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"time"
+)
+
+var (
+	ErrInvalidPeriod       = domain_errors.Validation("invalid_period", "endDate must not be before startDate")
+	ErrWindowNotApplicable = domain_errors.Validation("window_not_applicable", "windowDays only applies to distinct_branches challenges")
+)
+
+type IChallengeService interface {
+	CreateChallenge(ctx context.Context, req challenge_requests.CreateChallengeRequest) (*challenge_responses.ChallengeResponse, error)
+	GetChallenge(ctx context.Context, id uint) (*challenge_responses.ChallengeResponse, error)
+	UpdateChallenge(ctx context.Context, id uint, req challenge_requests.UpdateChallengeRequest) (*challenge_responses.ChallengeResponse, error)
+	DeleteChallenge(ctx context.Context, id uint) error
+	ListChallenges(ctx context.Context, req challenge_requests.ListChallengesRequest) (*pagination.Page[challenge_responses.ChallengeResponse], error)
+	// EvaluateChallenges evaluates the transaction on the challenges of its
+	// merchant running at its date, and returns those it completed.
+	EvaluateChallenges(ctx context.Context, req challenge_requests.EvaluateChallengesRequest) ([]challenge_responses.ChallengeProgressResponse, error)
+	ListUserChallenges(ctx context.Context, userID uint) ([]challenge_responses.ChallengeProgressResponse, error)
+	ListBadges(ctx context.Context, userID uint) ([]challenge_responses.BadgeResponse, error)
+}
+
+type challengeService struct {
+	challengeRepo challenge_ports.IChallengeRepository
+	logger        utils.ILogger
+}
+
+func NewChallengeService(challengeRepo challenge_ports.IChallengeRepository) IChallengeService {
+	return &challengeService{
+		challengeRepo: challengeRepo,
+		logger:        utils.NewLogger(),
+	}
+}
+
+func (s *challengeService) CreateChallenge(ctx context.Context, req challenge_requests.CreateChallengeRequest) (*challenge_responses.ChallengeResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, req.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &models.Challenge{
+		MerchantID:    req.MerchantID,
+		Name:          req.Name,
+		Description:   req.Description,
+		Goal:          req.Goal,
+		Target:        req.Target,
+		WindowDays:    req.WindowDays,
+		MinAmount:     req.MinAmount,
+		StartDate:     req.StartDate,
+		EndDate:       req.EndDate,
+		BadgeName:     req.BadgeName,
+		BadgeImageURL: req.BadgeImageURL,
+		RewardType:    req.RewardType,
+		RewardAmount:  req.RewardAmount,
+	}
+	err = checkChallenge(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.challengeRepo.Create(ctx, challenge)
+	if err != nil {
+		s.logger.Error("Error al crear desafío", err)
+		return nil, err
+	}
+
+	return challengeToResponse(challenge), nil
+}
+
+func (s *challengeService) GetChallenge(ctx context.Context, id uint) (*challenge_responses.ChallengeResponse, error) {
+	challenge, err := s.challengeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionRead, challenge.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return challengeToResponse(challenge), nil
+}
+
+// UpdateChallenge keeps the challenges already completed, and their badges,
+// as they were.
+func (s *challengeService) UpdateChallenge(ctx context.Context, id uint, req challenge_requests.UpdateChallengeRequest) (*challenge_responses.ChallengeResponse, error) {
+	challenge, err := s.challengeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionManage, challenge.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge.Name = req.Name
+	challenge.Description = req.Description
+	challenge.Goal = req.Goal
+	challenge.Target = req.Target
+	challenge.WindowDays = req.WindowDays
+	challenge.MinAmount = req.MinAmount
+	challenge.StartDate = req.StartDate
+	challenge.EndDate = req.EndDate
+	challenge.BadgeName = req.BadgeName
+	challenge.BadgeImageURL = req.BadgeImageURL
+	challenge.RewardType = req.RewardType
+	challenge.RewardAmount = req.RewardAmount
+	err = checkChallenge(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.challengeRepo.Update(ctx, challenge)
+	if err != nil {
+		s.logger.Error("Error al actualizar desafío", err)
+		return nil, err
+	}
+
+	return challengeToResponse(challenge), nil
+}
+
+func (s *challengeService) DeleteChallenge(ctx context.Context, id uint) error {
+	challenge, err := s.challengeRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = security.Authorize(ctx, security.ActionManage, challenge.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.challengeRepo.Delete(ctx, id)
+}
+
+func (s *challengeService) ListChallenges(ctx context.Context, req challenge_requests.ListChallengesRequest) (*pagination.Page[challenge_responses.ChallengeResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := challenge_ports.ChallengeFilter{
+		MerchantID: merchantID,
+		Goal:       req.Goal,
+	}
+	if req.Active {
+		now := time.Now()
+		filter.ActiveAt = &now
+	}
+
+	page, err := s.challengeRepo.List(ctx, filter, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar desafíos", err)
+		return nil, err
+	}
+
+	return pagination.Map(page, func(challenge *models.Challenge) challenge_responses.ChallengeResponse {
+		return *challengeToResponse(challenge)
+	}), nil
+}
+
+func (s *challengeService) EvaluateChallenges(ctx context.Context, req challenge_requests.EvaluateChallengesRequest) ([]challenge_responses.ChallengeProgressResponse, error) {
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, &req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenges, err := s.challengeRepo.ListActive(ctx, req.MerchantID, req.Date)
+	if err != nil {
+		s.logger.Error("Error al obtener desafíos activos", err)
+		return nil, err
+	}
+
+	transaction := challenge_ports.EvaluatedTransaction{
+		ID:         req.TransactionID,
+		UserID:     req.UserID,
+		MerchantID: req.MerchantID,
+		Date:       req.Date,
+	}
+	completed := []challenge_responses.ChallengeProgressResponse{}
+	for i := range challenges {
+		challenge := &challenges[i]
+		if challenge.MinAmount != nil && req.Amount < *challenge.MinAmount {
+			continue
+		}
+
+		progress, err := s.challengeRepo.Evaluate(ctx, challenge.ID, transaction, time.Now())
+		if err != nil {
+			s.logger.Error("Error al evaluar desafío", err)
+			return nil, err
+		}
+		if progress != nil && progress.Status == models.ChallengeCompleted {
+			completed = append(completed, *progressToResponse(challenge, req.UserID, progress))
+		}
+	}
+	return completed, nil
+}
+
+// ListUserChallenges shows merchant callers only the challenges of their
+// merchant.
+func (s *challengeService) ListUserChallenges(ctx context.Context, userID uint) ([]challenge_responses.ChallengeProgressResponse, error) {
+	err := s.authorizeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	standings, err := s.challengeRepo.ListStandings(ctx, userID, merchantID, time.Now())
+	if err != nil {
+		s.logger.Error("Error al listar progreso de desafíos", err)
+		return nil, err
+	}
+
+	responses := make([]challenge_responses.ChallengeProgressResponse, len(standings))
+	for i := range standings {
+		responses[i] = *progressToResponse(&standings[i].Challenge, userID, standings[i].Progress)
+	}
+	return responses, nil
+}
+
+// ListBadges shows merchant callers only the badges of their merchant.
+func (s *challengeService) ListBadges(ctx context.Context, userID uint) ([]challenge_responses.BadgeResponse, error) {
+	err := s.authorizeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	badges, err := s.challengeRepo.ListBadges(ctx, userID, merchantID)
+	if err != nil {
+		s.logger.Error("Error al listar insignias", err)
+		return nil, err
+	}
+
+	responses := make([]challenge_responses.BadgeResponse, len(badges))
+	for i, badge := range badges {
+		responses[i] = challenge_responses.BadgeResponse{
+			ID:          badge.ID,
+			ChallengeID: badge.ChallengeID,
+			UserID:      badge.UserID,
+			MerchantID:  badge.MerchantID,
+			Name:        badge.Name,
+			ImageURL:    badge.ImageURL,
+			AwardedAt:   badge.CreatedAt,
+		}
+	}
+	return responses, nil
+}
+
+// authorizeUser lets merchant callers read only the members of their program.
+func (s *challengeService) authorizeUser(ctx context.Context, userID uint) error {
+	principal, ok := security.PrincipalFromContext(ctx)
+	if !ok {
+		return security.ErrUnauthenticated
+	}
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	err := security.Authorize(ctx, security.ActionRead, principal.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.challengeRepo.IsMember(ctx, userID, principal.MerchantID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return security.ErrForbidden
+	}
+	return nil
+}
+
+func checkChallenge(challenge *models.Challenge) error {
+	if challenge.EndDate != nil && challenge.EndDate.Before(challenge.StartDate) {
+		return ErrInvalidPeriod
+	}
+	if challenge.WindowDays != nil && challenge.Goal != models.ChallengeDistinctBranches {
+		return ErrWindowNotApplicable
+	}
+	return nil
+}
+
+func challengeToResponse(challenge *models.Challenge) *challenge_responses.ChallengeResponse {
+	return &challenge_responses.ChallengeResponse{
+		ID:            challenge.ID,
+		MerchantID:    challenge.MerchantID,
+		Name:          challenge.Name,
+		Description:   challenge.Description,
+		Goal:          challenge.Goal,
+		Target:        challenge.Target,
+		WindowDays:    challenge.WindowDays,
+		MinAmount:     challenge.MinAmount,
+		StartDate:     challenge.StartDate,
+		EndDate:       challenge.EndDate,
+		BadgeName:     challenge.BadgeName,
+		BadgeImageURL: challenge.BadgeImageURL,
+		RewardType:    challenge.RewardType,
+		RewardAmount:  challenge.RewardAmount,
+		CreatedAt:     challenge.CreatedAt,
+	}
+}
+
+// progressToResponse shows a challenge the user has no progress in yet as
+// in progress from zero.
+func progressToResponse(challenge *models.Challenge, userID uint, progress *models.ChallengeProgress) *challenge_responses.ChallengeProgressResponse {
+	response := &challenge_responses.ChallengeProgressResponse{
+		Challenge: *challengeToResponse(challenge),
+		UserID:    userID,
+		Status:    models.ChallengeInProgress,
+	}
+	if progress != nil {
+		response.Progress = progress.Progress
+		response.Status = progress.Status
+		response.CompletedAt = progress.CompletedAt
+		response.BadgeID = progress.BadgeID
+		response.RewardID = progress.RewardID
+		response.UpdatedAt = &progress.UpdatedAt
+	}
+	return response
+}
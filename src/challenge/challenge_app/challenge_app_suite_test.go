@@ -0,0 +1,13 @@
+package challenge_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestChallengeApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ChallengeApp Suite")
+}
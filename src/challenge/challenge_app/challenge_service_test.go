@@ -0,0 +1,285 @@
+package challenge_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/challenge/challenge_app"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_ports"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_structs/challenge_requests"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ChallengeService", func() {
+	var (
+		challengeService challenge_app.IChallengeService
+		mockChallenge    *mockChallengeRepository
+		merchantID       uint
+		date             time.Time
+	)
+
+	BeforeEach(func() {
+		mockChallenge = new(mockChallengeRepository)
+		challengeService = challenge_app.NewChallengeService(mockChallenge)
+		merchantID = 4
+		date = time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC)
+	})
+
+	admin := func() context.Context {
+		return security.WithPrincipal(context.Background(), security.System())
+	}
+
+	operator := func() context.Context {
+		return security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleMerchantAdmin,
+			KeyID:      21,
+			MerchantID: merchantID,
+		})
+	}
+
+	challenge := func(id uint, goal string) models.Challenge {
+		challenge := models.Challenge{
+			MerchantID: merchantID,
+			Name:       "Explorer",
+			Goal:       goal,
+			Target:     3,
+			StartDate:  date.AddDate(0, -1, 0),
+			BadgeName:  "Explorer",
+		}
+		challenge.ID = id
+		return challenge
+	}
+
+	Describe("CreateChallenge", func() {
+		var req challenge_requests.CreateChallengeRequest
+
+		BeforeEach(func() {
+			req = challenge_requests.CreateChallengeRequest{
+				MerchantID:   merchantID,
+				Name:         "Explorer",
+				Goal:         models.ChallengeDistinctBranches,
+				Target:       3,
+				WindowDays:   ptr(7),
+				StartDate:    date,
+				BadgeName:    "Explorer",
+				RewardType:   ptr("points"),
+				RewardAmount: ptr(200.0),
+			}
+		})
+
+		It("should create the challenge", func() {
+			mockChallenge.On("Create", mock.Anything, mock.AnythingOfType("*models.Challenge")).Return(nil)
+
+			response, err := challengeService.CreateChallenge(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response.Goal).To(Equal(models.ChallengeDistinctBranches))
+			Expect(response.WindowDays).To(Equal(ptr(7)))
+			Expect(response.RewardAmount).To(Equal(ptr(200.0)))
+		})
+
+		It("should reject a window on other goals", func() {
+			req.Goal = models.ChallengeWeeklyStreak
+
+			_, err := challengeService.CreateChallenge(operator(), req)
+
+			Expect(err).To(MatchError(challenge_app.ErrWindowNotApplicable))
+			mockChallenge.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything)
+		})
+
+		It("should reject an end before the start", func() {
+			req.EndDate = ptr(date.AddDate(0, 0, -1))
+
+			_, err := challengeService.CreateChallenge(operator(), req)
+
+			Expect(err).To(MatchError(challenge_app.ErrInvalidPeriod))
+		})
+
+		It("should reject callers of other merchants", func() {
+			req.MerchantID = 7
+
+			_, err := challengeService.CreateChallenge(operator(), req)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockChallenge.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("EvaluateChallenges", func() {
+		var req challenge_requests.EvaluateChallengesRequest
+
+		BeforeEach(func() {
+			req = challenge_requests.EvaluateChallengesRequest{
+				TransactionID: 9,
+				UserID:        1,
+				MerchantID:    merchantID,
+				BranchID:      3,
+				Amount:        100,
+				Date:          date,
+			}
+		})
+
+		It("should evaluate the qualifying challenges and return those completed", func() {
+			branches := challenge(5, models.ChallengeDistinctBranches)
+			streak := challenge(6, models.ChallengeWeeklyStreak)
+			large := challenge(7, models.ChallengeTransactions)
+			large.MinAmount = ptr(500.0)
+			transaction := challenge_ports.EvaluatedTransaction{ID: 9, UserID: 1, MerchantID: merchantID, Date: date}
+			mockChallenge.On("ListActive", mock.Anything, merchantID, date).Return([]models.Challenge{branches, streak, large}, nil)
+			mockChallenge.On("Evaluate", mock.Anything, uint(5), transaction, mock.AnythingOfType("time.Time")).Return(&models.ChallengeProgress{ChallengeID: 5, UserID: 1, Progress: 3, Status: models.ChallengeCompleted, BadgeID: ptr(uint(30))}, nil)
+			mockChallenge.On("Evaluate", mock.Anything, uint(6), transaction, mock.AnythingOfType("time.Time")).Return(&models.ChallengeProgress{ChallengeID: 6, UserID: 1, Progress: 2, Status: models.ChallengeInProgress}, nil)
+
+			response, err := challengeService.EvaluateChallenges(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Challenge.ID).To(Equal(uint(5)))
+			Expect(response[0].Progress).To(Equal(3))
+			Expect(response[0].BadgeID).To(Equal(ptr(uint(30))))
+			mockChallenge.AssertNotCalled(GinkgoT(), "Evaluate", mock.Anything, uint(7), mock.Anything, mock.Anything)
+		})
+
+		It("should skip the challenges the user already completed", func() {
+			mockChallenge.On("ListActive", mock.Anything, merchantID, date).Return([]models.Challenge{challenge(5, models.ChallengeTransactions)}, nil)
+			mockChallenge.On("Evaluate", mock.Anything, uint(5), mock.Anything, mock.Anything).Return(nil, nil)
+
+			response, err := challengeService.EvaluateChallenges(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(BeEmpty())
+		})
+
+		It("should reject callers of other merchants", func() {
+			req.MerchantID = 7
+
+			_, err := challengeService.EvaluateChallenges(operator(), req)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockChallenge.AssertNotCalled(GinkgoT(), "ListActive", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("ListUserChallenges", func() {
+		It("should show the challenges without progress from zero", func() {
+			mockChallenge.On("ListStandings", mock.Anything, uint(1), (*uint)(nil), mock.AnythingOfType("time.Time")).Return([]challenge_ports.Standing{
+				{Challenge: challenge(5, models.ChallengeDistinctBranches)},
+				{Challenge: challenge(6, models.ChallengeWeeklyStreak), Progress: &models.ChallengeProgress{ChallengeID: 6, UserID: 1, Progress: 2, Status: models.ChallengeInProgress}},
+			}, nil)
+
+			response, err := challengeService.ListUserChallenges(admin(), 1)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(2))
+			Expect(response[0].Progress).To(BeZero())
+			Expect(response[0].Status).To(Equal(models.ChallengeInProgress))
+			Expect(response[0].UpdatedAt).To(BeNil())
+			Expect(response[1].Progress).To(Equal(2))
+			Expect(response[1].Challenge.Target).To(Equal(3))
+		})
+
+		It("should only list the challenges of the merchant of the caller", func() {
+			mockChallenge.On("IsMember", mock.Anything, uint(1), merchantID).Return(true, nil)
+			mockChallenge.On("ListStandings", mock.Anything, uint(1), &merchantID, mock.AnythingOfType("time.Time")).Return([]challenge_ports.Standing{}, nil)
+
+			response, err := challengeService.ListUserChallenges(operator(), 1)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(BeEmpty())
+		})
+	})
+
+	Describe("ListBadges", func() {
+		It("should list the badges of the merchant of the caller", func() {
+			mockChallenge.On("IsMember", mock.Anything, uint(1), merchantID).Return(true, nil)
+			mockChallenge.On("ListBadges", mock.Anything, uint(1), &merchantID).Return([]models.Badge{
+				{ID: 30, ChallengeID: 5, UserID: 1, MerchantID: merchantID, Name: "Explorer", CreatedAt: date},
+			}, nil)
+
+			response, err := challengeService.ListBadges(operator(), 1)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Name).To(Equal("Explorer"))
+			Expect(response[0].AwardedAt).To(Equal(date))
+		})
+
+		It("should reject users outside the merchant's program", func() {
+			mockChallenge.On("IsMember", mock.Anything, uint(1), merchantID).Return(false, nil)
+
+			_, err := challengeService.ListBadges(operator(), 1)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockChallenge.AssertNotCalled(GinkgoT(), "ListBadges", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+})
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+type mockChallengeRepository struct {
+	mock.Mock
+}
+
+func (m *mockChallengeRepository) Create(ctx context.Context, challenge *models.Challenge) error {
+	args := m.Called(ctx, challenge)
+	return args.Error(0)
+}
+
+func (m *mockChallengeRepository) GetByID(ctx context.Context, id uint) (*models.Challenge, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Challenge), args.Error(1)
+}
+
+func (m *mockChallengeRepository) Update(ctx context.Context, challenge *models.Challenge) error {
+	args := m.Called(ctx, challenge)
+	return args.Error(0)
+}
+
+func (m *mockChallengeRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockChallengeRepository) List(ctx context.Context, filter challenge_ports.ChallengeFilter, page pagination.Request) (*pagination.Page[models.Challenge], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(*pagination.Page[models.Challenge]), args.Error(1)
+}
+
+func (m *mockChallengeRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockChallengeRepository) ListActive(ctx context.Context, merchantID uint, at time.Time) ([]models.Challenge, error) {
+	args := m.Called(ctx, merchantID, at)
+	return args.Get(0).([]models.Challenge), args.Error(1)
+}
+
+func (m *mockChallengeRepository) Evaluate(ctx context.Context, challengeID uint, transaction challenge_ports.EvaluatedTransaction, now time.Time) (*models.ChallengeProgress, error) {
+	args := m.Called(ctx, challengeID, transaction, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ChallengeProgress), args.Error(1)
+}
+
+func (m *mockChallengeRepository) ListStandings(ctx context.Context, userID uint, merchantID *uint, now time.Time) ([]challenge_ports.Standing, error) {
+	args := m.Called(ctx, userID, merchantID, now)
+	return args.Get(0).([]challenge_ports.Standing), args.Error(1)
+}
+
+func (m *mockChallengeRepository) ListBadges(ctx context.Context, userID uint, merchantID *uint) ([]models.Badge, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Get(0).([]models.Badge), args.Error(1)
+}
@@ -0,0 +1,11 @@
+package challenge_ports
+
+import "time"
+
+// ChallengeFilter selects the challenges of a merchant. ActiveAt keeps those
+// running at that time.
+type ChallengeFilter struct {
+	MerchantID *uint
+	Goal       string
+	ActiveAt   *time.Time
+}
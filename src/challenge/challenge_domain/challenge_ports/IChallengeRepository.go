@@ -0,0 +1,45 @@
+package challenge_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+// EvaluatedTransaction is the processed transaction a challenge is evaluated
+// on.
+type EvaluatedTransaction struct {
+	ID         uint
+	UserID     uint
+	MerchantID uint
+	Date       time.Time
+}
+
+// Standing is a challenge with the progress of a user, nil until their first
+// transaction evaluated on it.
+type Standing struct {
+	Challenge models.Challenge
+	Progress  *models.ChallengeProgress
+}
+
+type IChallengeRepository interface {
+	Create(ctx context.Context, challenge *models.Challenge) error
+	GetByID(ctx context.Context, id uint) (*models.Challenge, error)
+	Update(ctx context.Context, challenge *models.Challenge) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter ChallengeFilter, page pagination.Request) (*pagination.Page[models.Challenge], error)
+	IsMember(ctx context.Context, userID, merchantID uint) (bool, error)
+	// ListActive returns the challenges of the merchant running at the date.
+	ListActive(ctx context.Context, merchantID uint, at time.Time) ([]models.Challenge, error)
+	// Evaluate locks the progress of the user in the challenge, recounts it
+	// with the transaction and, when it reaches the target, completes the
+	// challenge with its badge and reward. It returns nil when the user had
+	// already completed the challenge.
+	Evaluate(ctx context.Context, challengeID uint, transaction EvaluatedTransaction, now time.Time) (*models.ChallengeProgress, error)
+	// ListStandings returns the challenges running at now of the merchants
+	// whose program the user belongs to, or of the merchant when one is given,
+	// with the user's progress.
+	ListStandings(ctx context.Context, userID uint, merchantID *uint, now time.Time) ([]Standing, error)
+	ListBadges(ctx context.Context, userID uint, merchantID *uint) ([]models.Badge, error)
+}
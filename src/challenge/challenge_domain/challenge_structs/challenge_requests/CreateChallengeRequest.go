@@ -0,0 +1,24 @@
+package challenge_requests
+
+import "time"
+
+type CreateChallengeRequest struct {
+	MerchantID  uint   `json:"merchantId" binding:"required"`
+	Name        string `json:"name" binding:"required,max=200"`
+	Description string `json:"description"`
+	Goal        string `json:"goal" binding:"required,oneof=distinct_branches weekly_streak transactions"`
+	Target      int    `json:"target" binding:"required,min=1"`
+	// WindowDays only applies to distinct_branches challenges: the branches
+	// must be visited within that many days. The whole challenge counts when
+	// omitted.
+	WindowDays    *int       `json:"windowDays" binding:"omitempty,min=1"`
+	MinAmount     *float64   `json:"minAmount" binding:"omitempty,gt=0"`
+	StartDate     time.Time  `json:"startDate" binding:"required"`
+	EndDate       *time.Time `json:"endDate"`
+	BadgeName     string     `json:"badgeName" binding:"required,max=100"`
+	BadgeImageURL string     `json:"badgeImageUrl" binding:"omitempty,url"`
+	// RewardType and RewardAmount give a reward on completion along with the
+	// badge; the challenge only awards the badge when they are omitted.
+	RewardType   *string  `json:"rewardType" binding:"required_with=RewardAmount,omitempty,oneof=points cashback"`
+	RewardAmount *float64 `json:"rewardAmount" binding:"required_with=RewardType,omitempty,gt=0"`
+}
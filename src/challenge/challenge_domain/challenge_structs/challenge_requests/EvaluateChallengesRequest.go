@@ -0,0 +1,14 @@
+package challenge_requests
+
+import "time"
+
+// EvaluateChallengesRequest describes a processed transaction to evaluate on
+// the challenges of its merchant.
+type EvaluateChallengesRequest struct {
+	TransactionID uint
+	UserID        uint
+	MerchantID    uint
+	BranchID      uint
+	Amount        float64
+	Date          time.Time
+}
@@ -0,0 +1,12 @@
+package challenge_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListChallengesRequest accepts sort by id, name, startDate or createdAt.
+// Active keeps the challenges running now.
+type ListChallengesRequest struct {
+	pagination.Request
+	MerchantID *uint  `form:"merchantId"`
+	Goal       string `form:"goal" binding:"omitempty,oneof=distinct_branches weekly_streak transactions"`
+	Active     bool   `form:"active"`
+}
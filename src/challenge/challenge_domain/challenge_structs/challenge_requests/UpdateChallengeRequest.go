@@ -0,0 +1,18 @@
+package challenge_requests
+
+import "time"
+
+type UpdateChallengeRequest struct {
+	Name          string     `json:"name" binding:"required,max=200"`
+	Description   string     `json:"description"`
+	Goal          string     `json:"goal" binding:"required,oneof=distinct_branches weekly_streak transactions"`
+	Target        int        `json:"target" binding:"required,min=1"`
+	WindowDays    *int       `json:"windowDays" binding:"omitempty,min=1"`
+	MinAmount     *float64   `json:"minAmount" binding:"omitempty,gt=0"`
+	StartDate     time.Time  `json:"startDate" binding:"required"`
+	EndDate       *time.Time `json:"endDate"`
+	BadgeName     string     `json:"badgeName" binding:"required,max=100"`
+	BadgeImageURL string     `json:"badgeImageUrl" binding:"omitempty,url"`
+	RewardType    *string    `json:"rewardType" binding:"required_with=RewardAmount,omitempty,oneof=points cashback"`
+	RewardAmount  *float64   `json:"rewardAmount" binding:"required_with=RewardType,omitempty,gt=0"`
+}
@@ -0,0 +1,13 @@
+package challenge_responses
+
+import "time"
+
+type BadgeResponse struct {
+	ID          uint      `json:"id"`
+	ChallengeID uint      `json:"challengeId"`
+	UserID      uint      `json:"userId"`
+	MerchantID  uint      `json:"merchantId"`
+	Name        string    `json:"name"`
+	ImageURL    string    `json:"imageUrl,omitempty"`
+	AwardedAt   time.Time `json:"awardedAt"`
+}
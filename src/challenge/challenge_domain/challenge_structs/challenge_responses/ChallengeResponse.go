@@ -0,0 +1,34 @@
+package challenge_responses
+
+import "time"
+
+type ChallengeResponse struct {
+	ID            uint       `json:"id"`
+	MerchantID    uint       `json:"merchantId"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	Goal          string     `json:"goal"`
+	Target        int        `json:"target"`
+	WindowDays    *int       `json:"windowDays,omitempty"`
+	MinAmount     *float64   `json:"minAmount,omitempty"`
+	StartDate     time.Time  `json:"startDate"`
+	EndDate       *time.Time `json:"endDate,omitempty"`
+	BadgeName     string     `json:"badgeName"`
+	BadgeImageURL string     `json:"badgeImageUrl,omitempty"`
+	RewardType    *string    `json:"rewardType,omitempty"`
+	RewardAmount  *float64   `json:"rewardAmount,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// ChallengeProgressResponse is a challenge with where the user stands in it,
+// as of their last transaction evaluated.
+type ChallengeProgressResponse struct {
+	Challenge   ChallengeResponse `json:"challenge"`
+	UserID      uint              `json:"userId"`
+	Progress    int               `json:"progress"`
+	Status      string            `json:"status"`
+	CompletedAt *time.Time        `json:"completedAt,omitempty"`
+	BadgeID     *uint             `json:"badgeId,omitempty"`
+	RewardID    *uint             `json:"rewardId,omitempty"`
+	UpdatedAt   *time.Time        `json:"updatedAt,omitempty"`
+}
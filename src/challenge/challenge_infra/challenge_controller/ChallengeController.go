@@ -0,0 +1,259 @@
+package challenge_controller
+
+import (
+	"loyalty-campaigns/src/challenge/challenge_app"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_structs/challenge_requests"
+	"loyalty-campaigns/src/challenge/challenge_infra/challenge_repository"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChallengeController struct {
+	challengeService challenge_app.IChallengeService
+}
+
+var (
+	challengeControllerInstance *ChallengeController
+	challengeControllerOnce     sync.Once
+)
+
+func NewChallengeController(router *gin.RouterGroup) *ChallengeController {
+	challengeControllerOnce.Do(func() {
+		challengeControllerInstance = &ChallengeController{}
+		db := configs.NewDBConnection().GetDB()
+		challengeRepository := challenge_repository.NewGormChallengeRepository(db)
+		challengeControllerInstance.challengeService = challenge_app.NewChallengeService(challengeRepository)
+		challengeControllerInstance.setupChallengeRoutes(router)
+	})
+	return challengeControllerInstance
+}
+
+func (c *ChallengeController) setupChallengeRoutes(router *gin.RouterGroup) {
+	challengeGroup := router.Group("/challenges")
+	{
+		challengeGroup.POST("", c.CreateChallenge)
+		challengeGroup.GET("", c.ListChallenges)
+		challengeGroup.GET("/:id", c.GetChallenge)
+		challengeGroup.PUT("/:id", c.UpdateChallenge)
+		challengeGroup.DELETE("/:id", c.DeleteChallenge)
+	}
+	userGroup := router.Group("/users")
+	{
+		userGroup.GET("/:id/challenges", c.ListUserChallenges)
+		userGroup.GET("/:id/badges", c.ListBadges)
+	}
+}
+
+// CreateChallenge godoc
+//
+//	@Summary		Create a challenge
+//	@Description	Create a challenge for the users of a merchant, evaluated on each of their transactions between startDate and endDate that reach minAmount. The goal is distinct_branches (buy at target different branches, within windowDays when set), weekly_streak (buy in target consecutive weeks, Monday to Sunday in UTC) or transactions (make target purchases). Completing it awards the badge once per user, and rewardAmount in rewardType when set.
+//	@Tags			challenges
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		challenge_requests.CreateChallengeRequest	true	"Challenge"
+//	@Success		201		{object}	challenge_responses.ChallengeResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/challenges [post]
+func (c *ChallengeController) CreateChallenge(ctx *gin.Context) {
+	var req challenge_requests.CreateChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.challengeService.CreateChallenge(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// ListChallenges godoc
+//
+//	@Summary		List challenges
+//	@Description	Get a page of challenges filtered by merchant and goal; active=true keeps those running now. Sort by id, name, startDate or createdAt, prefixed with "-" for descending order.
+//	@Tags			challenges
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	query		challenge_requests.ListChallengesRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[challenge_responses.ChallengeResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/challenges [get]
+func (c *ChallengeController) ListChallenges(ctx *gin.Context) {
+	var req challenge_requests.ListChallengesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.challengeService.ListChallenges(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetChallenge godoc
+//
+//	@Summary		Get a challenge
+//	@Description	Get a challenge by its ID
+//	@Tags			challenges
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Challenge ID"
+//	@Success		200	{object}	challenge_responses.ChallengeResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/challenges/{id} [get]
+func (c *ChallengeController) GetChallenge(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.challengeService.GetChallenge(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateChallenge godoc
+//
+//	@Summary		Update a challenge
+//	@Description	Update a challenge. The progress of its users is recounted with the new goal on their next transaction; the challenges already completed keep their badges and rewards.
+//	@Tags			challenges
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int											true	"Challenge ID"
+//	@Param			request	body		challenge_requests.UpdateChallengeRequest	true	"Challenge"
+//	@Success		200		{object}	challenge_responses.ChallengeResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Router			/api/challenges/{id} [put]
+func (c *ChallengeController) UpdateChallenge(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req challenge_requests.UpdateChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.challengeService.UpdateChallenge(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeleteChallenge godoc
+//
+//	@Summary		Delete a challenge
+//	@Description	Remove a challenge. The badges already awarded for it are kept.
+//	@Tags			challenges
+//	@Security		ApiKeyAuth
+//	@Param			id	path	int	true	"Challenge ID"
+//	@Success		204	"No Content"
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/challenges/{id} [delete]
+func (c *ChallengeController) DeleteChallenge(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	err = c.challengeService.DeleteChallenge(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// ListUserChallenges godoc
+//
+//	@Summary		List the challenges of a user
+//	@Description	Get the challenges running now of the merchants whose program the user belongs to, with the user's progress towards the target as of their last transaction, and the badge and reward of those completed. Merchant callers only see the challenges of their merchant.
+//	@Tags			challenges
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{array}		challenge_responses.ChallengeProgressResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Router			/api/users/{id}/challenges [get]
+func (c *ChallengeController) ListUserChallenges(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.challengeService.ListUserChallenges(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ListBadges godoc
+//
+//	@Summary		List the badges of a user
+//	@Description	Get the badges the user earned by completing challenges, newest first. Merchant callers only see the badges of their merchant.
+//	@Tags			challenges
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{array}		challenge_responses.BadgeResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Router			/api/users/{id}/badges [get]
+func (c *ChallengeController) ListBadges(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.challengeService.ListBadges(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,324 @@
+package challenge_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_ports"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormChallengeRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormChallengeRepository(db *gorm.DB) challenge_ports.IChallengeRepository {
+	return &GormChallengeRepository{DB: db}
+}
+
+func (r *GormChallengeRepository) Create(ctx context.Context, challenge *models.Challenge) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Create(challenge).Error, "challenge")
+}
+
+func (r *GormChallengeRepository) GetByID(ctx context.Context, id uint) (*models.Challenge, error) {
+	var challenge models.Challenge
+	err := configs.DB(ctx, r.DB).First(&challenge, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "challenge")
+	}
+	return &challenge, nil
+}
+
+func (r *GormChallengeRepository) Update(ctx context.Context, challenge *models.Challenge) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Save(challenge).Error, "challenge")
+}
+
+func (r *GormChallengeRepository) Delete(ctx context.Context, id uint) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Delete(&models.Challenge{}, id).Error, "challenge")
+}
+
+var challengeSorting = pagination.Sorting[models.Challenge]{
+	IDColumn: "id",
+	ID:       func(challenge *models.Challenge) uint { return challenge.ID },
+	Fields: map[string]pagination.Key[models.Challenge]{
+		"id":        {Column: "id", Value: func(challenge *models.Challenge) any { return challenge.ID }},
+		"name":      {Column: "name", Value: func(challenge *models.Challenge) any { return challenge.Name }},
+		"startDate": {Column: "start_date", Value: func(challenge *models.Challenge) any { return challenge.StartDate }},
+		"createdAt": {Column: "created_at", Value: func(challenge *models.Challenge) any { return challenge.CreatedAt }},
+	},
+	Default: "id",
+}
+
+func (r *GormChallengeRepository) List(ctx context.Context, filter challenge_ports.ChallengeFilter, page pagination.Request) (*pagination.Page[models.Challenge], error) {
+	query := configs.DB(ctx, r.DB).Model(&models.Challenge{})
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.Goal != "" {
+		query = query.Where("goal = ?", filter.Goal)
+	}
+	if filter.ActiveAt != nil {
+		query = running(query, *filter.ActiveAt)
+	}
+	return pagination.Find(query, page, challengeSorting)
+}
+
+func (r *GormChallengeRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	var count int64
+	err := configs.DB(ctx, r.DB).Model(&models.Membership{}).
+		Where("user_id = ? AND merchant_id = ?", userID, merchantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormChallengeRepository) ListActive(ctx context.Context, merchantID uint, at time.Time) ([]models.Challenge, error) {
+	var challenges []models.Challenge
+	err := running(configs.DB(ctx, r.DB).Where("merchant_id = ?", merchantID), at).Order("id").Find(&challenges).Error
+	return challenges, err
+}
+
+// Evaluate locks the progress before the rewards of the completion, so the
+// concurrent transactions of a user complete a challenge once.
+func (r *GormChallengeRepository) Evaluate(ctx context.Context, challengeID uint, transaction challenge_ports.EvaluatedTransaction, now time.Time) (*models.ChallengeProgress, error) {
+	var evaluated *models.ChallengeProgress
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		var challenge models.Challenge
+		err := tx.Limit(1).Find(&challenge, challengeID).Error
+		if err != nil {
+			return err
+		}
+		if challenge.ID == 0 || challenge.MerchantID != transaction.MerchantID {
+			return domain_errors.NotFound("challenge_not_found", "challenge not found")
+		}
+
+		progress, err := lockProgress(tx, &challenge, transaction.UserID)
+		if err != nil || progress.Status == models.ChallengeCompleted {
+			return err
+		}
+
+		count, err := countProgress(tx, &challenge, transaction)
+		if err != nil {
+			return err
+		}
+		progress.Progress = min(count, challenge.Target)
+		if count >= challenge.Target {
+			err = completeChallenge(tx, progress, &challenge, transaction, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Omit(clause.Associations).Save(progress).Error
+		if err != nil {
+			return err
+		}
+		evaluated = progress
+		if progress.Status == models.ChallengeCompleted {
+			return events.Enqueue(tx, events.ForChallengeCompleted(progress))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "challenge_progress")
+	}
+	return evaluated, nil
+}
+
+func (r *GormChallengeRepository) ListStandings(ctx context.Context, userID uint, merchantID *uint, now time.Time) ([]challenge_ports.Standing, error) {
+	db := configs.DB(ctx, r.DB)
+	var challenges []models.Challenge
+	query := running(db, now).
+		Where("merchant_id IN (?)", db.Model(&models.Membership{}).Select("merchant_id").Where("user_id = ?", userID))
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+	err := query.Order("merchant_id, id").Find(&challenges).Error
+	if err != nil || len(challenges) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, len(challenges))
+	for i, challenge := range challenges {
+		ids[i] = challenge.ID
+	}
+	var progresses []models.ChallengeProgress
+	err = db.Where("user_id = ? AND challenge_id IN ?", userID, ids).Find(&progresses).Error
+	if err != nil {
+		return nil, err
+	}
+	byChallenge := make(map[uint]*models.ChallengeProgress, len(progresses))
+	for i := range progresses {
+		byChallenge[progresses[i].ChallengeID] = &progresses[i]
+	}
+
+	standings := make([]challenge_ports.Standing, len(challenges))
+	for i, challenge := range challenges {
+		standings[i] = challenge_ports.Standing{Challenge: challenge, Progress: byChallenge[challenge.ID]}
+	}
+	return standings, nil
+}
+
+func (r *GormChallengeRepository) ListBadges(ctx context.Context, userID uint, merchantID *uint) ([]models.Badge, error) {
+	var badges []models.Badge
+	query := configs.DB(ctx, r.DB).Where("user_id = ?", userID)
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+	err := query.Order("created_at DESC, id DESC").Find(&badges).Error
+	return badges, err
+}
+
+func running(query *gorm.DB, at time.Time) *gorm.DB {
+	return query.Where("start_date <= ? AND (end_date IS NULL OR end_date >= ?)", at, at)
+}
+
+// lockProgress locks the user's progress in the challenge, starting it when
+// there is none. The unique index on the progresses makes concurrent
+// transactions share the new one.
+func lockProgress(tx *gorm.DB, challenge *models.Challenge, userID uint) (*models.ChallengeProgress, error) {
+	progress, err := findProgress(tx, challenge.ID, userID)
+	if err != nil || progress != nil {
+		return progress, err
+	}
+
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(&models.ChallengeProgress{
+		ChallengeID: challenge.ID,
+		UserID:      userID,
+		MerchantID:  challenge.MerchantID,
+		Status:      models.ChallengeInProgress,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err = findProgress(tx, challenge.ID, userID)
+	if err == nil && progress == nil {
+		return nil, domain_errors.NotFound("challenge_progress_not_found", "challenge progress not found")
+	}
+	return progress, err
+}
+
+func findProgress(tx *gorm.DB, challengeID, userID uint) (*models.ChallengeProgress, error) {
+	var progresses []models.ChallengeProgress
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("challenge_id = ? AND user_id = ?", challengeID, userID).
+		Limit(1).Find(&progresses).Error
+	if err != nil || len(progresses) == 0 {
+		return nil, err
+	}
+	return &progresses[0], nil
+}
+
+// countProgress recounts the goal of the challenge from the user's
+// transactions at the merchant that have not been reversed, from the start of
+// the challenge up to the transaction evaluated, that reach its minimum
+// amount.
+func countProgress(tx *gorm.DB, challenge *models.Challenge, transaction challenge_ports.EvaluatedTransaction) (int, error) {
+	query := tx.Model(&models.Transaction{}).
+		Where("user_id = ? AND merchant_id = ? AND reversed_at IS NULL", transaction.UserID, challenge.MerchantID).
+		Where("date >= ? AND date <= ?", challenge.StartDate, transaction.Date)
+	if challenge.EndDate != nil {
+		query = query.Where("date <= ?", *challenge.EndDate)
+	}
+	if challenge.MinAmount != nil {
+		query = query.Where("amount >= ?", *challenge.MinAmount)
+	}
+
+	var count int64
+	var err error
+	switch challenge.Goal {
+	case models.ChallengeDistinctBranches:
+		if challenge.WindowDays != nil {
+			query = query.Where("date > ?", transaction.Date.AddDate(0, 0, -*challenge.WindowDays))
+		}
+		err = query.Distinct("branch_id").Count(&count).Error
+	case models.ChallengeWeeklyStreak:
+		return countStreak(query, challenge.Target, transaction.Date)
+	default:
+		err = query.Count(&count).Error
+	}
+	return int(count), err
+}
+
+// countStreak counts the consecutive weeks, from Monday to Sunday in UTC, with
+// a purchase up to the week of the date.
+func countStreak(query *gorm.DB, target int, date time.Time) (int, error) {
+	var weeks []struct{ Week time.Time }
+	err := query.Select("DISTINCT date_trunc('week', date AT TIME ZONE 'UTC') AS week").
+		Order("week DESC").Limit(target).Scan(&weeks).Error
+	if err != nil {
+		return 0, err
+	}
+
+	streak := 0
+	week := weekStart(date)
+	for _, purchased := range weeks {
+		if !purchased.Week.Equal(week) {
+			break
+		}
+		streak++
+		week = week.AddDate(0, 0, -7)
+	}
+	return streak, nil
+}
+
+func weekStart(date time.Time) time.Time {
+	date = date.UTC()
+	days := (int(date.Weekday()) + 6) % 7
+	return time.Date(date.Year(), date.Month(), date.Day()-days, 0, 0, 0, 0, time.UTC)
+}
+
+// completeChallenge awards the badge of the challenge and its reward, with the
+// merchant's validity counted from the transaction that completed it.
+func completeChallenge(tx *gorm.DB, progress *models.ChallengeProgress, challenge *models.Challenge, transaction challenge_ports.EvaluatedTransaction, now time.Time) error {
+	progress.Status = models.ChallengeCompleted
+	progress.CompletedAt = &now
+	progress.TransactionID = &transaction.ID
+
+	badge := &models.Badge{
+		ChallengeID: challenge.ID,
+		UserID:      progress.UserID,
+		MerchantID:  progress.MerchantID,
+		Name:        challenge.BadgeName,
+		ImageURL:    challenge.BadgeImageURL,
+	}
+	err := tx.Create(badge).Error
+	if err != nil {
+		return err
+	}
+	progress.BadgeID = &badge.ID
+	progress.Badge = badge
+
+	if challenge.RewardType == nil || challenge.RewardAmount == nil {
+		return nil
+	}
+	var merchant models.Merchant
+	err = tx.First(&merchant, progress.MerchantID).Error
+	if err != nil {
+		return err
+	}
+	reward := &models.Reward{
+		UserID:      progress.UserID,
+		MerchantID:  progress.MerchantID,
+		Type:        *challenge.RewardType,
+		Amount:      *challenge.RewardAmount,
+		ChallengeID: &challenge.ID,
+	}
+	if merchant.RewardValidityDays != nil {
+		expiry := transaction.Date.AddDate(0, 0, *merchant.RewardValidityDays)
+		reward.ExpiryDate = &expiry
+	}
+	err = reward_repository.GrantReward(tx, reward, events.RewardGranted)
+	if err != nil {
+		return err
+	}
+	progress.RewardID = &reward.ID
+	return nil
+}
@@ -0,0 +1,200 @@
+package src
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/security"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// Exit codes returned by the commands.
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+const usage = `usage: loyalty-campaigns <command> [arguments]
+
+commands:
+  serve                        start the HTTP server (default)
+  migrate up|down [n]|status   apply, roll back or list the schema migrations
+  seed                         load demo merchants, branches, campaigns and users
+  expire-rewards               remove the rewards whose expiry date has passed
+  issue-bonuses                grant the enrollment, birthday and anniversary bonuses due today
+  recalculate-balances         rebuild the balances from the rewards ledger
+  user balance <id>            print the balances of a user
+`
+
+type command func(ctx context.Context, c *container, args []string) int
+
+var commands = map[string]command{
+	"serve":                serve,
+	"migrate":              migrate,
+	"seed":                 seed,
+	"expire-rewards":       expireRewards,
+	"issue-bonuses":        issueBonuses,
+	"recalculate-balances": recalculateBalances,
+	"user":                 user,
+}
+
+// Execute runs the command named by the first argument and returns the process
+// exit code. Every command shares the same configuration and composition root;
+// commands other than serve run as the system principal.
+func Execute(args []string) int {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Print(usage)
+		return exitOK
+	}
+
+	run, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", name, usage)
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	c, err := newContainer()
+	if err != nil {
+		logger.Error("failed to initialize: %v", err)
+		return exitFailure
+	}
+	defer c.close()
+
+	if name != "serve" {
+		ctx = security.WithPrincipal(ctx, security.System())
+	}
+
+	return run(ctx, c, args)
+}
+
+// requireSchema stops commands from running against a database that has
+// pending migrations.
+func requireSchema(ctx context.Context, c *container) bool {
+	err := c.migrator.Verify(ctx)
+	if err != nil {
+		logger.Error("database schema check failed, run the migrate up command first: %v", err)
+		return false
+	}
+	return true
+}
+
+func expireRewards(ctx context.Context, c *container, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "expire-rewards takes no arguments\n\n%s", usage)
+		return exitUsage
+	}
+	if !requireSchema(ctx, c) {
+		return exitFailure
+	}
+
+	expired, err := c.rewardService.ExpireRewards(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to expire rewards: %v", err)
+		return exitFailure
+	}
+
+	logger.Success("[OK] %d rewards expired", expired)
+	return exitOK
+}
+
+func issueBonuses(ctx context.Context, c *container, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "issue-bonuses takes no arguments\n\n%s", usage)
+		return exitUsage
+	}
+	if !requireSchema(ctx, c) {
+		return exitFailure
+	}
+
+	issued, err := c.bonusService.IssueLifecycleBonuses(ctx, time.Now())
+	if err != nil {
+		logger.Error("failed to issue bonuses: %v", err)
+		return exitFailure
+	}
+
+	logger.Success("[OK] %d bonuses issued", issued)
+	return exitOK
+}
+
+func recalculateBalances(ctx context.Context, c *container, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "recalculate-balances takes no arguments\n\n%s", usage)
+		return exitUsage
+	}
+	if !requireSchema(ctx, c) {
+		return exitFailure
+	}
+
+	corrected, err := c.rewardService.RecalculateBalances(ctx)
+	if err != nil {
+		logger.Error("failed to recalculate balances: %v", err)
+		return exitFailure
+	}
+
+	logger.Success("[OK] balances recalculated, %d corrected", corrected)
+	return exitOK
+}
+
+func user(ctx context.Context, c *container, args []string) int {
+	if len(args) != 2 || args[0] != "balance" {
+		fmt.Fprintf(os.Stderr, "usage: user balance <id>\n")
+		return exitUsage
+	}
+	userID, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid user ID %q\n", args[1])
+		return exitUsage
+	}
+	if !requireSchema(ctx, c) {
+		return exitFailure
+	}
+
+	account, err := c.userService.GetUser(ctx, uint(userID))
+	if errors.Is(err, domain_errors.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "user %d not found\n", userID)
+		return exitNotFound
+	}
+	if err != nil {
+		logger.Error("failed to get the user: %v", err)
+		return exitFailure
+	}
+
+	balances, err := c.rewardService.GetBalances(ctx, account.ID)
+	if err != nil {
+		logger.Error("failed to get the balances: %v", err)
+		return exitFailure
+	}
+
+	fmt.Printf("User %d: %s\n\n", account.ID, account.Name)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "MERCHANT\tTYPE\tBALANCE\tPENDING\tHELD\tAVAILABLE\tUPDATED AT")
+	for _, balance := range balances {
+		fmt.Fprintf(writer, "%d\t%s\t%.2f\t%.2f\t%.2f\t%.2f\t%s\n", balance.MerchantID, balance.Type, balance.Amount, balance.Pending, balance.Held, balance.Available, balance.UpdatedAt.Format(time.RFC3339))
+	}
+	return flush(writer)
+}
+
+func flush(writer *tabwriter.Writer) int {
+	err := writer.Flush()
+	if err != nil {
+		logger.Error("failed to write the output: %v", err)
+		return exitFailure
+	}
+	return exitOK
+}
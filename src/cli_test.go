@@ -0,0 +1,18 @@
+package src_test
+
+import (
+	app "loyalty-campaigns/src"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Execute", func() {
+	It("should print the usage on help", func() {
+		Expect(app.Execute([]string{"help"})).To(Equal(app.ExitOK))
+	})
+
+	It("should reject an unknown command before connecting to the database", func() {
+		Expect(app.Execute([]string{"migrations", "up"})).To(Equal(app.ExitUsage))
+	})
+})
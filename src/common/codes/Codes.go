@@ -0,0 +1,72 @@
+// Package codes generates the random codes users type or show at a branch,
+// such as vouchers.
+package codes
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Alphabet has 32 symbols and leaves out 0, 1, I and O, which are easily
+// confused when a code is read aloud or typed.
+const Alphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// Generate returns a code of length random symbols of the alphabet, followed
+// by a check symbol when checkDigit is set. Each symbol carries 5 bits of
+// entropy, so the default length of 12 gives 60 bits.
+func Generate(length int, checkDigit bool) (string, error) {
+	max := big.NewInt(int64(len(Alphabet)))
+	code := make([]byte, length, length+1)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = Alphabet[n.Int64()]
+	}
+	if checkDigit {
+		code = append(code, Alphabet[checkSymbol(string(code))])
+	}
+	return string(code), nil
+}
+
+// Normalize upper-cases the code and removes the separators users add when
+// typing it.
+func Normalize(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+// Valid reports whether the last symbol of a normalized code is the check
+// symbol of the rest, which catches any single mistyped symbol and most
+// transpositions.
+func Valid(code string) bool {
+	if len(code) < 2 {
+		return false
+	}
+	last := strings.IndexByte(Alphabet, code[len(code)-1])
+	return last >= 0 && last == checkSymbol(code[:len(code)-1])
+}
+
+// checkSymbol computes the Luhn mod N check of the code over the alphabet, or
+// -1 when the code has symbols outside of it.
+func checkSymbol(code string) int {
+	n := len(Alphabet)
+	factor := 2
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		point := strings.IndexByte(Alphabet, code[i])
+		if point < 0 {
+			return -1
+		}
+		addend := factor * point
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return (n - sum%n) % n
+}
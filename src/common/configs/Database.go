@@ -12,8 +12,11 @@ import (
 	gormLogger "gorm.io/gorm/logger"
 )
 
+const defaultDSN = "host=localhost user=loyalty_user password=loyalty_pass dbname=loyalty port=5432 sslmode=disable TimeZone=America/Bogota"
+
 type IDBConnection interface {
 	GetDB() *gorm.DB
+	Ping(ctx context.Context) error
 	Close() error
 }
 
@@ -36,7 +39,10 @@ func NewDBConnection() IDBConnection {
 			dbConnInstance.logger.Fatal("database connection failed: %v", err)
 		}
 
-		err = dbConnInstance.ping()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err = dbConnInstance.Ping(ctx)
 		if err != nil {
 			dbConnInstance.logger.Fatal("failed to ping the database: %v", err)
 		}
@@ -48,10 +54,11 @@ func NewDBConnection() IDBConnection {
 
 func (p *dbConnection) connect() error {
 	gormConfig := &gorm.Config{
-		Logger: gormLogger.Default.LogMode(gormLogger.Error),
+		Logger:         gormLogger.Default.LogMode(gormLogger.Error),
+		TranslateError: true,
 	}
 
-	dsn := "host=localhost user=loyalty_user password=loyalty_pass dbname=loyalty port=5432 sslmode=disable TimeZone=America/Bogota"
+	dsn := GetEnv("DATABASE_URL", defaultDSN)
 
 	conn, err := gorm.Open(postgres.Open(dsn), gormConfig)
 	if err != nil {
@@ -63,14 +70,12 @@ func (p *dbConnection) connect() error {
 	return nil
 }
 
-func (p *dbConnection) ping() error {
+// Ping checks the database is reachable, giving up when ctx is done.
+func (p *dbConnection) Ping(ctx context.Context) error {
 	if p.connection == nil {
 		return fmt.Errorf("connection is nil")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	sqlDB, err := p.connection.DB()
 	if err != nil {
 		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
@@ -0,0 +1,85 @@
+package configs
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// IUnitOfWork runs the work of several services in a single database
+// transaction.
+type IUnitOfWork interface {
+	// Run calls fn with a context that carries a database transaction, which is
+	// committed when fn succeeds and rolled back otherwise. The repositories
+	// called with that context join the transaction; a nested Run uses a
+	// savepoint.
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactionKey struct{}
+
+type afterCommitKey struct{}
+
+// afterCommit holds the work that waits for a unit of work to commit.
+type afterCommit struct {
+	fns []func()
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) IUnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	pending := &afterCommit{}
+	err := DB(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		ctx := context.WithValue(ctx, transactionKey{}, tx)
+		return fn(context.WithValue(ctx, afterCommitKey{}, pending))
+	})
+	if err != nil {
+		return err
+	}
+
+	// A nested unit of work only commits with the outermost one.
+	AfterCommit(ctx, func() {
+		for _, fn := range pending.fns {
+			fn()
+		}
+	})
+	return nil
+}
+
+// Transaction runs fn in a database transaction as a unit of work, joining
+// the one running in ctx. The transaction passed to fn carries the unit of
+// work, so the work registered with AfterCommit waits for it.
+func Transaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return NewUnitOfWork(db).Run(ctx, func(ctx context.Context) error {
+		return fn(DB(ctx, db))
+	})
+}
+
+// AfterCommit runs fn once the unit of work running in ctx commits, and not at
+// all when it rolls back. Without a unit of work fn runs right away. It is
+// meant for side effects that must not outlive a rollback, such as metrics.
+func AfterCommit(ctx context.Context, fn func()) {
+	pending, ok := ctx.Value(afterCommitKey{}).(*afterCommit)
+	if ok {
+		pending.fns = append(pending.fns, fn)
+		return
+	}
+	fn()
+}
+
+// DB returns the database transaction of the unit of work running in ctx, or
+// db when there is none, bound to ctx. Repositories go through it so that they
+// join the unit of work of their caller.
+func DB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	tx, ok := ctx.Value(transactionKey{}).(*gorm.DB)
+	if ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
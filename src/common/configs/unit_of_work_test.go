@@ -0,0 +1,150 @@
+package configs_test
+
+import (
+	"context"
+	"errors"
+	"loyalty-campaigns/src/common/configs"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("UnitOfWork", func() {
+	var (
+		sqlMock    sqlmock.Sqlmock
+		db         *gorm.DB
+		unitOfWork configs.IUnitOfWork
+		ran        []string
+	)
+
+	errFailed := errors.New("step failed")
+
+	record := func(name string) func() {
+		return func() { ran = append(ran, name) }
+	}
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err = gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		unitOfWork = configs.NewUnitOfWork(db)
+		ran = nil
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("AfterCommit", func() {
+		It("should run right away without a unit of work", func() {
+			configs.AfterCommit(context.Background(), record("now"))
+
+			Expect(ran).To(Equal([]string{"now"}))
+		})
+
+		It("should wait for the unit of work to commit", func() {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectCommit()
+
+			err := unitOfWork.Run(context.Background(), func(ctx context.Context) error {
+				configs.AfterCommit(ctx, record("first"))
+				configs.AfterCommit(ctx, record("second"))
+				Expect(ran).To(BeEmpty())
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(ran).To(Equal([]string{"first", "second"}))
+		})
+
+		It("should not run when the unit of work rolls back", func() {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectRollback()
+
+			err := unitOfWork.Run(context.Background(), func(ctx context.Context) error {
+				configs.AfterCommit(ctx, record("rolled back"))
+				return errFailed
+			})
+
+			Expect(err).To(MatchError(errFailed))
+			Expect(ran).To(BeEmpty())
+		})
+
+		It("should not run when the commit fails", func() {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectCommit().WillReturnError(errFailed)
+
+			err := unitOfWork.Run(context.Background(), func(ctx context.Context) error {
+				configs.AfterCommit(ctx, record("not committed"))
+				return nil
+			})
+
+			Expect(err).To(MatchError(errFailed))
+			Expect(ran).To(BeEmpty())
+		})
+
+		It("should wait for the outermost unit of work of a nested one", func() {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectRollback()
+
+			err := unitOfWork.Run(context.Background(), func(ctx context.Context) error {
+				err := unitOfWork.Run(ctx, func(ctx context.Context) error {
+					configs.AfterCommit(ctx, record("nested"))
+					return nil
+				})
+				Expect(err).To(BeNil())
+				Expect(ran).To(BeEmpty())
+				return errFailed
+			})
+
+			Expect(err).To(MatchError(errFailed))
+			Expect(ran).To(BeEmpty())
+		})
+
+		It("should drop the work of a nested unit of work rolled back to its savepoint", func() {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectExec(`ROLLBACK TO SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectCommit()
+
+			err := unitOfWork.Run(context.Background(), func(ctx context.Context) error {
+				configs.AfterCommit(ctx, record("outer"))
+				err := unitOfWork.Run(ctx, func(ctx context.Context) error {
+					configs.AfterCommit(ctx, record("nested"))
+					return errFailed
+				})
+				Expect(err).To(MatchError(errFailed))
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(ran).To(Equal([]string{"outer"}))
+		})
+	})
+
+	Describe("Transaction", func() {
+		It("should run the work registered with the transaction after it commits", func() {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`UPDATE balances`).WillReturnResult(sqlmock.NewResult(0, 1))
+			sqlMock.ExpectCommit()
+
+			err := configs.Transaction(context.Background(), db, func(tx *gorm.DB) error {
+				configs.AfterCommit(tx.Statement.Context, record("committed"))
+				Expect(ran).To(BeEmpty())
+				return tx.Exec("UPDATE balances SET amount = 0").Error
+			})
+
+			Expect(err).To(BeNil())
+			Expect(ran).To(Equal([]string{"committed"}))
+		})
+	})
+})
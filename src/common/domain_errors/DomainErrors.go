@@ -0,0 +1,142 @@
+package domain_errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Kind classifies a domain error and decides its HTTP status.
+type Kind string
+
+const (
+	KindNotFound            Kind = "not_found"
+	KindValidation          Kind = "validation"
+	KindConflict            Kind = "conflict"
+	KindInsufficientBalance Kind = "insufficient_balance"
+	KindForbidden           Kind = "forbidden"
+	KindUnauthenticated     Kind = "unauthenticated"
+	KindBudgetExhausted     Kind = "budget_exhausted"
+)
+
+// Generic sentinels: errors.Is(err, ErrNotFound) matches any not found error,
+// whatever its code.
+var (
+	ErrNotFound            = New(KindNotFound, string(KindNotFound), "resource not found")
+	ErrValidation          = New(KindValidation, string(KindValidation), "invalid request")
+	ErrConflict            = New(KindConflict, string(KindConflict), "conflict with the current state of the resource")
+	ErrInsufficientBalance = New(KindInsufficientBalance, string(KindInsufficientBalance), "insufficient balance")
+	ErrForbidden           = New(KindForbidden, string(KindForbidden), "not allowed to access this resource")
+	ErrUnauthenticated     = New(KindUnauthenticated, string(KindUnauthenticated), "authentication required")
+	ErrBudgetExhausted     = New(KindBudgetExhausted, string(KindBudgetExhausted), "budget exhausted")
+)
+
+// Error is a failure the domain expects and knows how to report: Code is a
+// stable, machine readable identifier and Message is meant for humans.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func New(kind Kind, code, format string, args ...any) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func NotFound(code, format string, args ...any) *Error {
+	return New(KindNotFound, code, format, args...)
+}
+
+func Validation(code, format string, args ...any) *Error {
+	return New(KindValidation, code, format, args...)
+}
+
+func Conflict(code, format string, args ...any) *Error {
+	return New(KindConflict, code, format, args...)
+}
+
+func InsufficientBalance(code, format string, args ...any) *Error {
+	return New(KindInsufficientBalance, code, format, args...)
+}
+
+func Forbidden(code, format string, args ...any) *Error {
+	return New(KindForbidden, code, format, args...)
+}
+
+func Unauthenticated(code, format string, args ...any) *Error {
+	return New(KindUnauthenticated, code, format, args...)
+}
+
+func BudgetExhausted(code, format string, args ...any) *Error {
+	return New(KindBudgetExhausted, code, format, args...)
+}
+
+// InvalidRequest reports a request body or query string that failed binding.
+func InvalidRequest(err error) *Error {
+	return &Error{Kind: KindValidation, Code: "invalid_request", Message: err.Error(), Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same code, or of the same kind when target is one
+// of the generic sentinels.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t.Kind != e.Kind {
+		return false
+	}
+	return t.Code == string(t.Kind) || t.Code == e.Code
+}
+
+// Wrap returns a copy of the error that keeps cause for logging and errors.Is.
+func (e *Error) Wrap(cause error) *Error {
+	wrapped := *e
+	wrapped.Err = cause
+	return &wrapped
+}
+
+// Status returns the HTTP status for the kind of error.
+func (k Kind) Status() int {
+	switch k {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindConflict:
+		return http.StatusConflict
+	case KindInsufficientBalance, KindBudgetExhausted:
+		return http.StatusUnprocessableEntity
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindUnauthenticated:
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Translate turns the database errors that repositories expect into domain
+// errors about entity; any other error is returned unchanged.
+func Translate(err error, entity string) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound(entity+"_not_found", "%s not found", entity).Wrap(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return Conflict(entity+"_already_exists", "%s already exists", entity).Wrap(err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return Validation(entity+"_invalid_reference", "%s references a record that does not exist", entity).Wrap(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,70 @@
+package domain_errors
+
+import (
+	"errors"
+	"loyalty-campaigns/src/common/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is the body of every error response, after RFC 7807.
+type Problem struct {
+	Status int    `json:"status" example:"404"`
+	Code   string `json:"code" example:"user_not_found"`
+	Title  string `json:"title" example:"Not Found"`
+	Detail string `json:"detail" example:"user not found"`
+}
+
+// NewProblem describes err for the client. Errors that are not domain errors
+// are reported as internal errors without exposing their message.
+func NewProblem(err error) Problem {
+	var domainErr *Error
+	if !errors.As(err, &domainErr) {
+		return Problem{
+			Status: http.StatusInternalServerError,
+			Code:   "internal_error",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Detail: "an unexpected error occurred",
+		}
+	}
+
+	status := domainErr.Kind.Status()
+	return Problem{
+		Status: status,
+		Code:   domainErr.Code,
+		Title:  http.StatusText(status),
+		Detail: err.Error(),
+	}
+}
+
+// Middleware renders the last error attached with ctx.Error as a problem
+// response, unless the handler already wrote one.
+func Middleware() gin.HandlerFunc {
+	logger := utils.NewLogger()
+
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		err := ctx.Errors.Last().Err
+		problem := NewProblem(err)
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error("%s %s failed: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+		}
+
+		ctx.Header("Content-Type", problemContentType)
+		ctx.AbortWithStatusJSON(problem.Status, problem)
+	}
+}
+
+// Abort stops the handler chain with err, to be rendered by Middleware.
+func Abort(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	ctx.Abort()
+}
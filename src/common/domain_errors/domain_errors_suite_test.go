@@ -0,0 +1,13 @@
+package domain_errors_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDomainErrors(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "DomainErrors Suite")
+}
@@ -0,0 +1,112 @@
+package domain_errors_test
+
+import (
+	"errors"
+	"fmt"
+	"loyalty-campaigns/src/common/domain_errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("DomainErrors", func() {
+	DescribeTable("should map each kind to its HTTP status",
+		func(kind domain_errors.Kind, status int) {
+			Expect(kind.Status()).To(Equal(status))
+		},
+		Entry("not found", domain_errors.KindNotFound, http.StatusNotFound),
+		Entry("validation", domain_errors.KindValidation, http.StatusBadRequest),
+		Entry("conflict", domain_errors.KindConflict, http.StatusConflict),
+		Entry("insufficient balance", domain_errors.KindInsufficientBalance, http.StatusUnprocessableEntity),
+		Entry("budget exhausted", domain_errors.KindBudgetExhausted, http.StatusUnprocessableEntity),
+		Entry("forbidden", domain_errors.KindForbidden, http.StatusForbidden),
+		Entry("unauthenticated", domain_errors.KindUnauthenticated, http.StatusUnauthorized),
+		Entry("unknown", domain_errors.Kind("other"), http.StatusInternalServerError),
+	)
+
+	Describe("Is", func() {
+		errUserNotFound := domain_errors.NotFound("user_not_found", "user not found")
+
+		It("should match the generic sentinel of its kind", func() {
+			Expect(errors.Is(errUserNotFound, domain_errors.ErrNotFound)).To(BeTrue())
+			Expect(errors.Is(errUserNotFound, domain_errors.ErrConflict)).To(BeFalse())
+		})
+
+		It("should match errors with the same code", func() {
+			Expect(errors.Is(errUserNotFound, domain_errors.NotFound("user_not_found", "no user %d", 1))).To(BeTrue())
+			Expect(errors.Is(errUserNotFound, domain_errors.NotFound("campaign_not_found", "campaign not found"))).To(BeFalse())
+		})
+
+		It("should match through wrapping in both directions", func() {
+			cause := errors.New("connection reset")
+			wrapped := fmt.Errorf("loading user: %w", errUserNotFound.Wrap(cause))
+
+			Expect(errors.Is(wrapped, errUserNotFound)).To(BeTrue())
+			Expect(errors.Is(wrapped, cause)).To(BeTrue())
+			Expect(errUserNotFound.Err).To(BeNil())
+		})
+	})
+
+	Describe("Translate", func() {
+		It("should keep nil", func() {
+			Expect(domain_errors.Translate(nil, "user")).To(BeNil())
+		})
+
+		It("should translate a missing record into not found", func() {
+			err := domain_errors.Translate(fmt.Errorf("query: %w", gorm.ErrRecordNotFound), "user")
+
+			assertDomainError(err, domain_errors.KindNotFound, "user_not_found", "user not found")
+			Expect(errors.Is(err, gorm.ErrRecordNotFound)).To(BeTrue())
+		})
+
+		It("should translate a duplicated key into a conflict", func() {
+			err := domain_errors.Translate(gorm.ErrDuplicatedKey, "merchant")
+
+			assertDomainError(err, domain_errors.KindConflict, "merchant_already_exists", "merchant already exists")
+		})
+
+		It("should translate a foreign key violation into a validation error", func() {
+			err := domain_errors.Translate(gorm.ErrForeignKeyViolated, "branch")
+
+			assertDomainError(err, domain_errors.KindValidation, "branch_invalid_reference", "branch references a record that does not exist")
+		})
+
+		It("should return other errors unchanged", func() {
+			cause := errors.New("connection reset")
+
+			Expect(domain_errors.Translate(cause, "user")).To(BeIdenticalTo(cause))
+		})
+	})
+
+	Describe("NewProblem", func() {
+		It("should describe a domain error", func() {
+			err := fmt.Errorf("redeem: %w", domain_errors.InsufficientBalance("insufficient_rewards", "insufficient rewards"))
+
+			Expect(domain_errors.NewProblem(err)).To(Equal(domain_errors.Problem{
+				Status: http.StatusUnprocessableEntity,
+				Code:   "insufficient_rewards",
+				Title:  "Unprocessable Entity",
+				Detail: "redeem: insufficient rewards",
+			}))
+		})
+
+		It("should hide the message of other errors", func() {
+			Expect(domain_errors.NewProblem(errors.New("pq: password authentication failed"))).To(Equal(domain_errors.Problem{
+				Status: http.StatusInternalServerError,
+				Code:   "internal_error",
+				Title:  "Internal Server Error",
+				Detail: "an unexpected error occurred",
+			}))
+		})
+	})
+})
+
+func assertDomainError(err error, kind domain_errors.Kind, code, message string) {
+	var domainErr *domain_errors.Error
+	ExpectWithOffset(1, errors.As(err, &domainErr)).To(BeTrue())
+	ExpectWithOffset(1, domainErr.Kind).To(Equal(kind))
+	ExpectWithOffset(1, domainErr.Code).To(Equal(code))
+	ExpectWithOffset(1, domainErr.Message).To(Equal(message))
+}
@@ -0,0 +1,110 @@
+package domain_errors_test
+
+import (
+	"encoding/json"
+	"errors"
+	"loyalty-campaigns/src/common/domain_errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var router *gin.Engine
+
+	type createRequest struct {
+		Name   string  `json:"name" binding:"required"`
+		Amount float64 `json:"amount" binding:"gt=0"`
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(domain_errors.Middleware())
+		router.GET("/not-found", func(ctx *gin.Context) {
+			domain_errors.Abort(ctx, domain_errors.NotFound("user_not_found", "user not found"))
+		})
+		router.GET("/internal", func(ctx *gin.Context) {
+			domain_errors.Abort(ctx, errors.New("pq: connection refused"))
+		})
+		router.GET("/written", func(ctx *gin.Context) {
+			_ = ctx.Error(domain_errors.ErrConflict)
+			ctx.JSON(http.StatusAccepted, gin.H{"status": "accepted"})
+		})
+		router.POST("/items", func(ctx *gin.Context) {
+			var req createRequest
+			if err := ctx.ShouldBindJSON(&req); err != nil {
+				ctx.Error(domain_errors.InvalidRequest(err))
+				return
+			}
+			ctx.Status(http.StatusCreated)
+		})
+	})
+
+	serve := func(method, path, body string) (*httptest.ResponseRecorder, domain_errors.Problem) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		var problem domain_errors.Problem
+		if recorder.Header().Get("Content-Type") == "application/problem+json" {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &problem)).To(Succeed())
+		}
+		return recorder, problem
+	}
+
+	It("should render a domain error as a problem", func() {
+		recorder, problem := serve(http.MethodGet, "/not-found", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json"))
+		Expect(problem).To(Equal(domain_errors.Problem{
+			Status: http.StatusNotFound,
+			Code:   "user_not_found",
+			Title:  "Not Found",
+			Detail: "user not found",
+		}))
+	})
+
+	It("should render other errors as internal errors", func() {
+		recorder, problem := serve(http.MethodGet, "/internal", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(problem.Code).To(Equal("internal_error"))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("connection refused"))
+	})
+
+	It("should keep a response the handler already wrote", func() {
+		recorder, _ := serve(http.MethodGet, "/written", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusAccepted))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"status":"accepted"}`))
+	})
+
+	It("should render a failed binding as an invalid request", func() {
+		recorder, problem := serve(http.MethodPost, "/items", `{"amount": -1}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(problem.Code).To(Equal("invalid_request"))
+		Expect(problem.Detail).To(ContainSubstring("'Name' failed on the 'required' tag"))
+		Expect(problem.Detail).To(ContainSubstring("'Amount' failed on the 'gt' tag"))
+	})
+
+	It("should render malformed JSON as an invalid request", func() {
+		recorder, problem := serve(http.MethodPost, "/items", `{"name":`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(problem.Code).To(Equal("invalid_request"))
+	})
+
+	It("should let successful requests through", func() {
+		recorder, _ := serve(http.MethodPost, "/items", `{"name": "coffee", "amount": 2}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+	})
+})
@@ -0,0 +1,526 @@
+package events
+
+import (
+	"fmt"
+	"loyalty-campaigns/src/common/models"
+	"time"
+)
+
+// Event types published to other systems. The type names and the shape of
+// their data are a public contract: add fields, never rename them.
+const (
+	TransactionProcessed = "transaction.processed"
+	TransactionReversed  = "transaction.reversed"
+	RewardGranted        = "reward.granted"
+	RewardAdjusted       = "reward.adjusted"
+	RewardRevoked        = "reward.revoked"
+	RewardRedeemed       = "reward.redeemed"
+	RewardExpired        = "reward.expired"
+	RewardRefunded       = "reward.refunded"
+	RewardVested         = "reward.vested"
+	RewardCredited       = "reward.credited"
+	RewardDebited        = "reward.debited"
+	ItemRedeemed         = "item.redeemed"
+	ItemFulfilled        = "item.fulfilled"
+	ItemCancelled        = "item.cancelled"
+	HoldAuthorized       = "hold.authorized"
+	HoldCaptured         = "hold.captured"
+	HoldVoided           = "hold.voided"
+	HoldExpired          = "hold.expired"
+	HoldReduced          = "hold.reduced"
+	ReferralCreated      = "referral.created"
+	ReferralQualified    = "referral.qualified"
+	BonusIssued          = "bonus.issued"
+	StampAdded           = "stamp.added"
+	StampRevoked         = "stamp.revoked"
+	StampCardCompleted   = "stamp_card.completed"
+	ThresholdReached     = "spend_threshold.reached"
+	ThresholdRevoked     = "spend_threshold.revoked"
+	ChallengeCompleted   = "challenge.completed"
+	CampaignCreated      = "campaign.created"
+	CampaignUpdated      = "campaign.updated"
+	CampaignDeleted      = "campaign.deleted"
+)
+
+// Types lists every event type.
+var Types = []string{
+	TransactionProcessed,
+	TransactionReversed,
+	RewardGranted,
+	RewardAdjusted,
+	RewardRevoked,
+	RewardRedeemed,
+	RewardExpired,
+	RewardRefunded,
+	RewardVested,
+	RewardCredited,
+	RewardDebited,
+	ItemRedeemed,
+	ItemFulfilled,
+	ItemCancelled,
+	HoldAuthorized,
+	HoldCaptured,
+	HoldVoided,
+	HoldExpired,
+	HoldReduced,
+	ReferralCreated,
+	ReferralQualified,
+	BonusIssued,
+	StampAdded,
+	StampRevoked,
+	StampCardCompleted,
+	ThresholdReached,
+	ThresholdRevoked,
+	ChallengeCompleted,
+	CampaignCreated,
+	CampaignUpdated,
+	CampaignDeleted,
+}
+
+// Event is a domain event as delivered to the sinks. Events of the same user
+// share a partition key and are delivered in the order they were recorded.
+type Event struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	AggregateType string    `json:"aggregateType"`
+	AggregateID   uint      `json:"aggregateId"`
+	UserID        *uint     `json:"userId,omitempty"`
+	MerchantID    *uint     `json:"merchantId,omitempty"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	Data          any       `json:"data"`
+}
+
+// PartitionKey groups the events that must be delivered in order: those of
+// the same user, or of the same aggregate when there is no user.
+func (e Event) PartitionKey() string {
+	if e.UserID != nil {
+		return fmt.Sprintf("user:%d", *e.UserID)
+	}
+	return fmt.Sprintf("%s:%d", e.AggregateType, e.AggregateID)
+}
+
+type TransactionData struct {
+	ID          uint       `json:"id"`
+	UserID      uint       `json:"userId"`
+	BranchID    uint       `json:"branchId"`
+	MerchantID  uint       `json:"merchantId"`
+	ExternalRef *string    `json:"externalRef,omitempty"`
+	Amount      float64    `json:"amount"`
+	Date        time.Time  `json:"date"`
+	ReversedAt  *time.Time `json:"reversedAt,omitempty"`
+}
+
+type RewardData struct {
+	ID         uint       `json:"id,omitempty"`
+	UserID     uint       `json:"userId"`
+	MerchantID uint       `json:"merchantId"`
+	Type       string     `json:"type"`
+	Amount     float64    `json:"amount"`
+	ExpiryDate *time.Time `json:"expiryDate,omitempty"`
+	// CampaignID and TransactionID are set on the rewards earned by a
+	// transaction, CampaignID only when a campaign awarded them.
+	CampaignID    *uint `json:"campaignId,omitempty"`
+	TransactionID *uint `json:"transactionId,omitempty"`
+	// AdjustmentID is set on the credits and debits of manual adjustments.
+	AdjustmentID *uint `json:"adjustmentId,omitempty"`
+	// ReferralID is set on the referral bonuses.
+	ReferralID *uint `json:"referralId,omitempty"`
+	// LifecycleBonusID is set on the enrollment, birthday and anniversary
+	// bonuses.
+	LifecycleBonusID *uint `json:"lifecycleBonusId,omitempty"`
+	// StampCardID is set on the reward of a completed stamp card.
+	StampCardID *uint `json:"stampCardId,omitempty"`
+	// ThresholdAwardID is set on the bonus of a spend-threshold campaign.
+	ThresholdAwardID *uint `json:"thresholdAwardId,omitempty"`
+	// ChallengeID is set on the reward of a completed challenge.
+	ChallengeID *uint `json:"challengeId,omitempty"`
+	// VestsAt is set on the rewards granted pending, until they vest.
+	VestsAt *time.Time `json:"vestsAt,omitempty"`
+}
+
+type ItemRedemptionData struct {
+	ID            uint       `json:"id"`
+	CatalogItemID uint       `json:"catalogItemId"`
+	UserID        uint       `json:"userId"`
+	MerchantID    uint       `json:"merchantId"`
+	BranchID      uint       `json:"branchId"`
+	Quantity      int        `json:"quantity"`
+	RewardType    string     `json:"rewardType"`
+	Cost          float64    `json:"cost"`
+	Status        string     `json:"status"`
+	FulfilledAt   *time.Time `json:"fulfilledAt,omitempty"`
+	CancelledAt   *time.Time `json:"cancelledAt,omitempty"`
+	CancelReason  string     `json:"cancelReason,omitempty"`
+}
+
+type HoldData struct {
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"userId"`
+	MerchantID     uint       `json:"merchantId"`
+	BranchID       *uint      `json:"branchId,omitempty"`
+	Type           string     `json:"type"`
+	Amount         float64    `json:"amount"`
+	CapturedAmount *float64   `json:"capturedAmount,omitempty"`
+	Status         string     `json:"status"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	CapturedAt     *time.Time `json:"capturedAt,omitempty"`
+	VoidedAt       *time.Time `json:"voidedAt,omitempty"`
+}
+
+type ReferralData struct {
+	ID            uint       `json:"id"`
+	ReferrerID    uint       `json:"referrerId"`
+	RefereeID     uint       `json:"refereeId"`
+	MerchantID    uint       `json:"merchantId"`
+	Status        string     `json:"status"`
+	TransactionID *uint      `json:"transactionId,omitempty"`
+	QualifiedAt   *time.Time `json:"qualifiedAt,omitempty"`
+}
+
+type BonusData struct {
+	ID         uint      `json:"id"`
+	UserID     uint      `json:"userId"`
+	MerchantID uint      `json:"merchantId"`
+	Kind       string    `json:"kind"`
+	Year       int       `json:"year,omitempty"`
+	OccursOn   time.Time `json:"occursOn"`
+	RewardType string    `json:"rewardType"`
+	Amount     float64   `json:"amount"`
+}
+
+type StampCardData struct {
+	ID             uint `json:"id"`
+	CampaignID     uint `json:"campaignId"`
+	UserID         uint `json:"userId"`
+	MerchantID     uint `json:"merchantId"`
+	Stamps         int  `json:"stamps"`
+	StampsRequired int  `json:"stampsRequired"`
+	// TransactionID is the transaction whose stamp was added or revoked.
+	TransactionID    uint       `json:"transactionId"`
+	Status           string     `json:"status"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty"`
+	RewardID         *uint      `json:"rewardId,omitempty"`
+	ItemRedemptionID *uint      `json:"itemRedemptionId,omitempty"`
+}
+
+type ThresholdAwardData struct {
+	ID            uint       `json:"id"`
+	CampaignID    uint       `json:"campaignId"`
+	UserID        uint       `json:"userId"`
+	MerchantID    uint       `json:"merchantId"`
+	TransactionID uint       `json:"transactionId"`
+	Spent         float64    `json:"spent"`
+	RewardID      *uint      `json:"rewardId,omitempty"`
+	RevokedAt     *time.Time `json:"revokedAt,omitempty"`
+}
+
+type ChallengeData struct {
+	ID            uint       `json:"id"`
+	ChallengeID   uint       `json:"challengeId"`
+	UserID        uint       `json:"userId"`
+	MerchantID    uint       `json:"merchantId"`
+	Progress      int        `json:"progress"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+	TransactionID *uint      `json:"transactionId,omitempty"`
+	BadgeID       *uint      `json:"badgeId,omitempty"`
+	BadgeName     string     `json:"badgeName,omitempty"`
+	RewardID      *uint      `json:"rewardId,omitempty"`
+}
+
+type CampaignData struct {
+	ID         uint       `json:"id"`
+	MerchantID uint       `json:"merchantId"`
+	BranchID   *uint      `json:"branchId,omitempty"`
+	Kind       string     `json:"kind"`
+	Type       string     `json:"type"`
+	Value      float64    `json:"value"`
+	MinAmount  *float64   `json:"minAmount,omitempty"`
+	StartDate  time.Time  `json:"startDate"`
+	EndDate    *time.Time `json:"endDate,omitempty"`
+	// StampsRequired and CatalogItemID are set on stamp-card campaigns.
+	StampsRequired *int  `json:"stampsRequired,omitempty"`
+	CatalogItemID  *uint `json:"catalogItemId,omitempty"`
+	// SpendThreshold is set on spend-threshold campaigns.
+	SpendThreshold *float64 `json:"spendThreshold,omitempty"`
+}
+
+// ForTransaction describes a transaction that was processed or reversed.
+func ForTransaction(eventType string, transaction *models.Transaction) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "transaction",
+		AggregateID:   transaction.ID,
+		UserID:        &transaction.UserID,
+		MerchantID:    &transaction.MerchantID,
+		Data: TransactionData{
+			ID:          transaction.ID,
+			UserID:      transaction.UserID,
+			BranchID:    transaction.BranchID,
+			MerchantID:  transaction.MerchantID,
+			ExternalRef: transaction.ExternalRef,
+			Amount:      transaction.Amount,
+			Date:        transaction.Date,
+			ReversedAt:  transaction.ReversedAt,
+		},
+	}
+}
+
+// ForReward describes a change to a single reward: granted, adjusted, revoked,
+// expired, refunded, vested or credited.
+func ForReward(eventType string, reward *models.Reward) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "reward",
+		AggregateID:   reward.ID,
+		UserID:        &reward.UserID,
+		MerchantID:    &reward.MerchantID,
+		Data: RewardData{
+			ID:               reward.ID,
+			UserID:           reward.UserID,
+			MerchantID:       reward.MerchantID,
+			Type:             reward.Type,
+			Amount:           reward.Amount,
+			ExpiryDate:       reward.ExpiryDate,
+			CampaignID:       reward.CampaignID,
+			TransactionID:    reward.TransactionID,
+			AdjustmentID:     reward.AdjustmentID,
+			ReferralID:       reward.ReferralID,
+			LifecycleBonusID: reward.LifecycleBonusID,
+			StampCardID:      reward.StampCardID,
+			ThresholdAwardID: reward.ThresholdAwardID,
+			ChallengeID:      reward.ChallengeID,
+			VestsAt:          reward.VestsAt,
+		},
+	}
+}
+
+// ForRedemption describes an amount redeemed from the user's balance, which
+// may consume several rewards.
+func ForRedemption(userID, merchantID uint, rewardType string, amount float64) Event {
+	return Event{
+		Type:          RewardRedeemed,
+		AggregateType: "user",
+		AggregateID:   userID,
+		UserID:        &userID,
+		MerchantID:    &merchantID,
+		Data: RewardData{
+			UserID:     userID,
+			MerchantID: merchantID,
+			Type:       rewardType,
+			Amount:     amount,
+		},
+	}
+}
+
+// ForDebit describes an amount debited from the user's balance by a manual
+// adjustment, which may consume several rewards.
+func ForDebit(adjustment *models.Adjustment) Event {
+	return Event{
+		Type:          RewardDebited,
+		AggregateType: "adjustment",
+		AggregateID:   adjustment.ID,
+		UserID:        &adjustment.UserID,
+		MerchantID:    &adjustment.MerchantID,
+		Data: RewardData{
+			UserID:       adjustment.UserID,
+			MerchantID:   adjustment.MerchantID,
+			Type:         adjustment.RewardType,
+			Amount:       adjustment.Amount,
+			AdjustmentID: &adjustment.ID,
+		},
+	}
+}
+
+// ForItemRedemption describes a change to the redemption of a catalog item:
+// redeemed, fulfilled or cancelled.
+func ForItemRedemption(eventType string, redemption *models.ItemRedemption) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "item_redemption",
+		AggregateID:   redemption.ID,
+		UserID:        &redemption.UserID,
+		MerchantID:    &redemption.MerchantID,
+		Data: ItemRedemptionData{
+			ID:            redemption.ID,
+			CatalogItemID: redemption.CatalogItemID,
+			UserID:        redemption.UserID,
+			MerchantID:    redemption.MerchantID,
+			BranchID:      redemption.BranchID,
+			Quantity:      redemption.Quantity,
+			RewardType:    redemption.RewardType,
+			Cost:          redemption.Cost,
+			Status:        redemption.Status,
+			FulfilledAt:   redemption.FulfilledAt,
+			CancelledAt:   redemption.CancelledAt,
+			CancelReason:  redemption.CancelReason,
+		},
+	}
+}
+
+// ForHold describes a change to a redemption hold: authorized, captured,
+// voided, expired or reduced because the rewards it reserved expired. A capture comes after the reward.redeemed event of the
+// amount captured.
+func ForHold(eventType string, hold *models.RedemptionHold) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "redemption_hold",
+		AggregateID:   hold.ID,
+		UserID:        &hold.UserID,
+		MerchantID:    &hold.MerchantID,
+		Data: HoldData{
+			ID:             hold.ID,
+			UserID:         hold.UserID,
+			MerchantID:     hold.MerchantID,
+			BranchID:       hold.BranchID,
+			Type:           hold.Type,
+			Amount:         hold.Amount,
+			CapturedAmount: hold.CapturedAmount,
+			Status:         hold.Status,
+			ExpiresAt:      hold.ExpiresAt,
+			CapturedAt:     hold.CapturedAt,
+			VoidedAt:       hold.VoidedAt,
+		},
+	}
+}
+
+// ForReferral describes a referral that was created or qualified. The events
+// go to the referee's partition, after the bonuses in the case of a
+// qualification.
+func ForReferral(eventType string, referral *models.Referral) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "referral",
+		AggregateID:   referral.ID,
+		UserID:        &referral.RefereeID,
+		MerchantID:    &referral.MerchantID,
+		Data: ReferralData{
+			ID:            referral.ID,
+			ReferrerID:    referral.ReferrerID,
+			RefereeID:     referral.RefereeID,
+			MerchantID:    referral.MerchantID,
+			Status:        referral.Status,
+			TransactionID: referral.TransactionID,
+			QualifiedAt:   referral.QualifiedAt,
+		},
+	}
+}
+
+// ForLifecycleBonus describes an enrollment, birthday or anniversary bonus
+// issued to a member, after the reward.granted event of its reward.
+func ForLifecycleBonus(bonus *models.LifecycleBonus) Event {
+	return Event{
+		Type:          BonusIssued,
+		AggregateType: "lifecycle_bonus",
+		AggregateID:   bonus.ID,
+		UserID:        &bonus.UserID,
+		MerchantID:    &bonus.MerchantID,
+		Data: BonusData{
+			ID:         bonus.ID,
+			UserID:     bonus.UserID,
+			MerchantID: bonus.MerchantID,
+			Kind:       bonus.Kind,
+			Year:       bonus.Year,
+			OccursOn:   bonus.OccursOn,
+			RewardType: bonus.RewardType,
+			Amount:     bonus.Amount,
+		},
+	}
+}
+
+// ForStampCard describes a stamp added to or revoked from a card, or a card
+// completed by the stamp of the transaction, after the events of its reward.
+func ForStampCard(eventType string, card *models.StampCard, transactionID uint) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "stamp_card",
+		AggregateID:   card.ID,
+		UserID:        &card.UserID,
+		MerchantID:    &card.MerchantID,
+		Data: StampCardData{
+			ID:               card.ID,
+			CampaignID:       card.CampaignID,
+			UserID:           card.UserID,
+			MerchantID:       card.MerchantID,
+			Stamps:           card.Stamps,
+			StampsRequired:   card.StampsRequired,
+			TransactionID:    transactionID,
+			Status:           card.Status,
+			CompletedAt:      card.CompletedAt,
+			RewardID:         card.RewardID,
+			ItemRedemptionID: card.ItemRedemptionID,
+		},
+	}
+}
+
+// ForThresholdAward describes a user reaching the threshold of a
+// spend-threshold campaign, after the reward.granted event of the bonus, or
+// the award revoked with the bonus by the reversal of its transaction.
+func ForThresholdAward(eventType string, award *models.ThresholdAward) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "threshold_award",
+		AggregateID:   award.ID,
+		UserID:        &award.UserID,
+		MerchantID:    &award.MerchantID,
+		Data: ThresholdAwardData{
+			ID:            award.ID,
+			CampaignID:    award.CampaignID,
+			UserID:        award.UserID,
+			MerchantID:    award.MerchantID,
+			TransactionID: award.TransactionID,
+			Spent:         award.Spent,
+			RewardID:      award.RewardID,
+			RevokedAt:     award.RevokedAt,
+		},
+	}
+}
+
+// ForChallengeCompleted describes a user completing a challenge and earning
+// its badge, after the reward.granted event of its reward.
+func ForChallengeCompleted(progress *models.ChallengeProgress) Event {
+	data := ChallengeData{
+		ID:            progress.ID,
+		ChallengeID:   progress.ChallengeID,
+		UserID:        progress.UserID,
+		MerchantID:    progress.MerchantID,
+		Progress:      progress.Progress,
+		CompletedAt:   progress.CompletedAt,
+		TransactionID: progress.TransactionID,
+		BadgeID:       progress.BadgeID,
+		RewardID:      progress.RewardID,
+	}
+	if progress.Badge != nil {
+		data.BadgeName = progress.Badge.Name
+	}
+	return Event{
+		Type:          ChallengeCompleted,
+		AggregateType: "challenge_progress",
+		AggregateID:   progress.ID,
+		UserID:        &progress.UserID,
+		MerchantID:    &progress.MerchantID,
+		Data:          data,
+	}
+}
+
+func ForCampaign(eventType string, campaign *models.Campaign) Event {
+	return Event{
+		Type:          eventType,
+		AggregateType: "campaign",
+		AggregateID:   campaign.ID,
+		MerchantID:    &campaign.MerchantID,
+		Data: CampaignData{
+			ID:             campaign.ID,
+			MerchantID:     campaign.MerchantID,
+			BranchID:       campaign.BranchID,
+			Kind:           campaign.Kind,
+			Type:           campaign.Type,
+			Value:          campaign.Value,
+			MinAmount:      campaign.MinAmount,
+			StartDate:      campaign.StartDate,
+			EndDate:        campaign.EndDate,
+			StampsRequired: campaign.StampsRequired,
+			CatalogItemID:  campaign.CatalogItemID,
+			SpendThreshold: campaign.SpendThreshold,
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"loyalty-campaigns/src/common/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Enqueue writes the events to the outbox. tx must be the database transaction
+// that applies the change the events describe, so that both are committed or
+// rolled back together. Each event takes the next sequence of its partition,
+// which keeps the partition locked until tx ends: the events of a partition are
+// thus numbered in the order they are committed.
+func Enqueue(tx *gorm.DB, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]models.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		if event.ID == "" {
+			id, err := newEventID()
+			if err != nil {
+				return err
+			}
+			event.ID = id
+		}
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = now
+		}
+
+		payload, err := json.Marshal(event.Data)
+		if err != nil {
+			return fmt.Errorf("encoding %s event: %w", event.Type, err)
+		}
+
+		rows = append(rows, models.OutboxEvent{
+			EventID:       event.ID,
+			Type:          event.Type,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			PartitionKey:  event.PartitionKey(),
+			UserID:        event.UserID,
+			MerchantID:    event.MerchantID,
+			Payload:       string(payload),
+			OccurredAt:    event.OccurredAt,
+			NextAttemptAt: event.OccurredAt,
+		})
+	}
+
+	err := assignSequences(tx, rows)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&rows).Error
+}
+
+// assignSequences reserves the sequences of the rows in outbox_partitions. The
+// partitions are locked in the order of their keys so that transactions writing
+// to the same partitions cannot deadlock.
+func assignSequences(tx *gorm.DB, rows []models.OutboxEvent) error {
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row.PartitionKey]++
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	next := make(map[string]int64, len(keys))
+	for _, key := range keys {
+		var last int64
+		err := tx.Raw(`INSERT INTO outbox_partitions (partition_key, last_sequence) VALUES (?, ?)
+			ON CONFLICT (partition_key) DO UPDATE SET last_sequence = outbox_partitions.last_sequence + EXCLUDED.last_sequence
+			RETURNING last_sequence`, key, counts[key]).Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		next[key] = last - counts[key] + 1
+	}
+
+	for i := range rows {
+		rows[i].Sequence = next[rows[i].PartitionKey]
+		next[rows[i].PartitionKey]++
+	}
+	return nil
+}
+
+// fromOutbox rebuilds the event stored in an outbox row; the data is kept as
+// the JSON that was recorded.
+func fromOutbox(row *models.OutboxEvent) Event {
+	return Event{
+		ID:            row.EventID,
+		Type:          row.Type,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		UserID:        row.UserID,
+		MerchantID:    row.MerchantID,
+		OccurredAt:    row.OccurredAt,
+		Data:          json.RawMessage(row.Payload),
+	}
+}
+
+// newEventID returns a random (version 4) UUID.
+func newEventID() (string, error) {
+	var id [16]byte
+	_, err := rand.Read(id[:])
+	if err != nil {
+		return "", err
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16]), nil
+}
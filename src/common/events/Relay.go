@@ -0,0 +1,219 @@
+package events
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/metrics"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// relayLockID is the advisory lock that keeps a single relay publishing at a
+	// time, which is what preserves the order of the events across instances.
+	relayLockID = 7070035
+
+	defaultBatchSize   = 100
+	defaultMaxAttempts = 20
+	minRetryBackoff    = time.Second
+	maxRetryBackoff    = 5 * time.Minute
+	maxLastErrorBytes  = 1000
+)
+
+// IRelay publishes the pending outbox events to a sink.
+type IRelay interface {
+	// Relay publishes one batch of pending events and returns how many were
+	// delivered.
+	Relay(ctx context.Context) (int, error)
+}
+
+type relay struct {
+	db          *gorm.DB
+	sink        ISink
+	batchSize   int
+	maxAttempts int
+	logger      utils.ILogger
+}
+
+// attempt is the result of publishing one event.
+type attempt struct {
+	row *models.OutboxEvent
+	err error
+}
+
+func NewRelay(db *gorm.DB, sink ISink, batchSize, maxAttempts int) IRelay {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return &relay{
+		db:          db,
+		sink:        sink,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		logger:      utils.NewLogger(),
+	}
+}
+
+// Relay delivers the pending events of every partition in the order they were
+// committed. An event is marked as published only after the sink accepted it,
+// so a crash in between delivers it again (at least once). When an event
+// fails, the later events of its partition wait for it, with an exponential
+// backoff between retries, while the other partitions go on. After maxAttempts
+// failures the event is dead: it is kept for an operator and its partition
+// moves on to the next event.
+//
+// The relay holds a session advisory lock on a dedicated connection rather than
+// a transaction, so that no transaction stays open while the sink is called;
+// the outcome of the batch is recorded afterwards in a short transaction.
+func (r *relay) Relay(ctx context.Context) (int, error) {
+	published := 0
+	err := r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var locked bool
+		err := conn.Raw("SELECT pg_try_advisory_lock(?)", relayLockID).Scan(&locked).Error
+		if err != nil || !locked {
+			// Another relay is publishing
+			return err
+		}
+		// The lock belongs to the connection, which goes back to the pool, so it
+		// is released even when ctx is done. So is the outcome recorded, not to
+		// deliver again events the sink already accepted.
+		conn = conn.WithContext(context.WithoutCancel(ctx))
+		defer func() {
+			err := conn.Exec("SELECT pg_advisory_unlock(?)", relayLockID).Error
+			if err != nil {
+				r.logger.Error("failed to release the outbox relay lock: %v", err)
+			}
+		}()
+
+		now := time.Now()
+		pending, err := r.pending(conn, now)
+		if err != nil {
+			return err
+		}
+
+		attempts := r.publish(ctx, pending, now)
+		err = r.record(conn, attempts, now)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range attempts {
+			if a.err == nil {
+				metrics.OutboxEventsPublished.WithLabelValues(a.row.Type).Inc()
+				published++
+			}
+		}
+		return nil
+	})
+	return published, err
+}
+
+// pending returns the next events of the partitions whose first pending event
+// is due, in sequence order within each partition. The batch takes the first
+// event of every partition before the second of any, so that busy partitions
+// do not hold back the others.
+func (r *relay) pending(conn *gorm.DB, now time.Time) ([]models.OutboxEvent, error) {
+	var pending []models.OutboxEvent
+	err := conn.Raw(`SELECT * FROM (
+			SELECT outbox_events.*,
+				ROW_NUMBER() OVER (PARTITION BY partition_key ORDER BY sequence) AS position,
+				FIRST_VALUE(next_attempt_at) OVER (PARTITION BY partition_key ORDER BY sequence) AS head_attempt_at
+			FROM outbox_events
+			WHERE published_at IS NULL AND dead_at IS NULL
+		) pending
+		WHERE head_attempt_at <= ?
+		ORDER BY position, id
+		LIMIT ?`, now, r.batchSize).Scan(&pending).Error
+	return pending, err
+}
+
+// publish sends the events to the sink. Once an event of a partition fails, the
+// rest of the partition is left for a later run.
+func (r *relay) publish(ctx context.Context, pending []models.OutboxEvent, now time.Time) []attempt {
+	attempts := make([]attempt, 0, len(pending))
+	blocked := map[string]bool{}
+	for i := range pending {
+		row := &pending[i]
+		if blocked[row.PartitionKey] {
+			continue
+		}
+		if row.NextAttemptAt.After(now) {
+			blocked[row.PartitionKey] = true
+			continue
+		}
+
+		err := r.sink.Publish(ctx, fromOutbox(row))
+		if err != nil {
+			blocked[row.PartitionKey] = true
+			metrics.OutboxPublishFailures.WithLabelValues(row.Type).Inc()
+			r.logger.Warn("failed to publish event %s (%s), attempt %d: %v", row.EventID, row.Type, row.Attempts+1, err)
+		}
+		attempts = append(attempts, attempt{row: row, err: err})
+	}
+	return attempts
+}
+
+// record marks the published events and schedules the retry of the failed
+// ones, or makes them dead when they ran out of attempts.
+func (r *relay) record(conn *gorm.DB, attempts []attempt, now time.Time) error {
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		var published []uint
+		for _, a := range attempts {
+			if a.err == nil {
+				published = append(published, a.row.ID)
+				continue
+			}
+
+			updates := map[string]any{
+				"attempts":        a.row.Attempts + 1,
+				"next_attempt_at": now.Add(retryBackoff(a.row.Attempts + 1)),
+				"last_error":      truncate(a.err.Error(), maxLastErrorBytes),
+			}
+			if a.row.Attempts+1 >= r.maxAttempts {
+				updates["dead_at"] = now
+				metrics.OutboxDeadEvents.WithLabelValues(a.row.Type).Inc()
+				r.logger.Error("event %s (%s) is dead after %d attempts: %v", a.row.EventID, a.row.Type, a.row.Attempts+1, a.err)
+			}
+			err := tx.Model(&models.OutboxEvent{}).Where("id = ?", a.row.ID).Updates(updates).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(published) == 0 {
+			return nil
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", published).Updates(map[string]any{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"published_at": now,
+		}).Error
+	})
+}
+
+// retryBackoff doubles the wait after every failed attempt, up to a maximum.
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,151 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"loyalty-campaigns/src/common/configs"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ISink delivers events to another system. Publish must return an error unless
+// the event was accepted; the relay then retries it, so sinks may see the same
+// event more than once and consumers should deduplicate by event ID.
+type ISink interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// NewSinkFromEnv builds the sink selected by OUTBOX_SINK: "log" (the default)
+// appends the events to OUTBOX_LOG_FILE, "http" posts them to OUTBOX_HTTP_URL.
+// Message brokers are plugged in with NewBrokerSink.
+func NewSinkFromEnv() (ISink, error) {
+	switch sink := configs.GetEnv("OUTBOX_SINK", "log"); sink {
+	case "log":
+		return NewLogFileSink(configs.GetEnv("OUTBOX_LOG_FILE", "outbox-events.log")), nil
+	case "http":
+		url := configs.GetEnv("OUTBOX_HTTP_URL", "")
+		if url == "" {
+			return nil, fmt.Errorf("OUTBOX_HTTP_URL is required by the http outbox sink")
+		}
+		return NewHTTPSink(url, configs.GetEnvDuration("OUTBOX_HTTP_TIMEOUT", 10*time.Second)), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox sink %q", sink)
+	}
+}
+
+// FanOutSink publishes every event to each of its sinks in turn. An event that
+// fails in one of them is retried in all of them.
+type FanOutSink []ISink
+
+func NewFanOutSink(sinks ...ISink) FanOutSink {
+	return FanOutSink(sinks)
+}
+
+func (s FanOutSink) Publish(ctx context.Context, event Event) error {
+	for _, sink := range s {
+		err := sink.Publish(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogFileSink appends every event to a file as a JSON line.
+type LogFileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogFileSink(path string) *LogFileSink {
+	return &LogFileSink{path: path}
+}
+
+func (s *LogFileSink) Publish(ctx context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// HTTPSink posts every event as JSON to a URL and expects a 2xx response.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("event sink responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// IBrokerPublisher is implemented by the message broker clients (Kafka, NATS,
+// RabbitMQ...) that BrokerSink adapts. The key must be used as the partition or
+// ordering key so that the broker keeps the events of a user in order.
+type IBrokerPublisher interface {
+	Publish(ctx context.Context, topic, key string, body []byte) error
+}
+
+// BrokerSink publishes every event to a topic of a message broker, keyed by
+// its partition key.
+type BrokerSink struct {
+	publisher IBrokerPublisher
+	topic     string
+}
+
+func NewBrokerSink(publisher IBrokerPublisher, topic string) *BrokerSink {
+	return &BrokerSink{publisher: publisher, topic: topic}
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.publisher.Publish(ctx, s.topic, event.PartitionKey(), body)
+}
@@ -0,0 +1,205 @@
+package events_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"loyalty-campaigns/src/common/events"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("Relay", func() {
+	var (
+		db      *gorm.DB
+		sqlMock sqlmock.Sqlmock
+		sink    *fakeSink
+		relay   events.IRelay
+	)
+
+	BeforeEach(func() {
+		db, sqlMock = newMockDB()
+		sink = &fakeSink{failing: map[string]bool{}}
+		relay = events.NewRelay(db, sink, 10, 3)
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	pendingColumns := []string{"id", "event_id", "type", "aggregate_type", "aggregate_id", "partition_key", "sequence", "payload", "occurred_at", "attempts", "next_attempt_at", "position"}
+	past := time.Now().Add(-time.Minute)
+
+	It("should do nothing while another relay holds the lock", func() {
+		sqlMock.ExpectQuery(`pg_try_advisory_lock`).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+
+		published, err := relay.Relay(context.Background())
+
+		Expect(err).To(BeNil())
+		Expect(published).To(Equal(0))
+		Expect(sink.published).To(BeEmpty())
+	})
+
+	It("should hold back only the partition of a failed event", func() {
+		sink.failing["e1"] = true
+		sqlMock.ExpectQuery(`pg_try_advisory_lock`).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		sqlMock.ExpectQuery(`FIRST_VALUE\(next_attempt_at\) OVER \(PARTITION BY partition_key ORDER BY sequence\)`).
+			WillReturnRows(sqlmock.NewRows(pendingColumns).
+				AddRow(1, "e1", "reward.granted", "reward", 1, "user:1", 1, "{}", past, 0, past, 1).
+				AddRow(3, "e3", "reward.granted", "reward", 3, "user:2", 1, "{}", past, 0, past, 1).
+				AddRow(2, "e2", "reward.granted", "reward", 2, "user:1", 2, "{}", past, 0, past, 2))
+		// The outcome is recorded after publishing, in its own transaction
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(`UPDATE "outbox_events" SET "attempts"=\$1,"last_error"=\$2,"next_attempt_at"=\$3 WHERE id = \$4`).
+			WithArgs(1, "sink unavailable", sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec(`UPDATE "outbox_events" SET "attempts"=attempts \+ 1,"published_at"=\$1 WHERE id IN \(\$2\)`).
+			WithArgs(sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+		sqlMock.ExpectExec(`pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		published, err := relay.Relay(context.Background())
+
+		Expect(err).To(BeNil())
+		Expect(published).To(Equal(1))
+		Expect(sink.attempted).To(Equal([]string{"e1", "e3"}))
+		Expect(sink.published).To(Equal([]string{"e3"}))
+	})
+
+	It("should publish the events of a partition in sequence order", func() {
+		sqlMock.ExpectQuery(`pg_try_advisory_lock`).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		// e7 got the lower id, but was committed after e8
+		sqlMock.ExpectQuery(`ORDER BY position, id`).
+			WillReturnRows(sqlmock.NewRows(pendingColumns).
+				AddRow(8, "e8", "reward.granted", "reward", 8, "user:1", 1, "{}", past, 0, past, 1).
+				AddRow(7, "e7", "reward.redeemed", "reward", 7, "user:1", 2, "{}", past, 0, past, 2))
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(`UPDATE "outbox_events" SET "attempts"=attempts \+ 1,"published_at"=\$1 WHERE id IN \(\$2,\$3\)`).
+			WithArgs(sqlmock.AnyArg(), 8, 7).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		sqlMock.ExpectCommit()
+		sqlMock.ExpectExec(`pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		published, err := relay.Relay(context.Background())
+
+		Expect(err).To(BeNil())
+		Expect(published).To(Equal(2))
+		Expect(sink.published).To(Equal([]string{"e8", "e7"}))
+	})
+
+	It("should make an event dead when it runs out of attempts", func() {
+		sink.failing["e1"] = true
+		sqlMock.ExpectQuery(`pg_try_advisory_lock`).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		sqlMock.ExpectQuery(`FROM outbox_events`).
+			WillReturnRows(sqlmock.NewRows(pendingColumns).
+				AddRow(1, "e1", "reward.granted", "reward", 1, "user:1", 1, "{}", past, 2, past, 1))
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(`UPDATE "outbox_events" SET "attempts"=\$1,"dead_at"=\$2,"last_error"=\$3,"next_attempt_at"=\$4 WHERE id = \$5`).
+			WithArgs(3, sqlmock.AnyArg(), "sink unavailable", sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectCommit()
+		sqlMock.ExpectExec(`pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		published, err := relay.Relay(context.Background())
+
+		Expect(err).To(BeNil())
+		Expect(published).To(Equal(0))
+	})
+
+	It("should not count as published the events it could not record", func() {
+		sqlMock.ExpectQuery(`pg_try_advisory_lock`).WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+		sqlMock.ExpectQuery(`FROM outbox_events`).
+			WillReturnRows(sqlmock.NewRows(pendingColumns).
+				AddRow(1, "e1", "reward.granted", "reward", 1, "user:1", 1, "{}", past, 0, past, 1))
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(`UPDATE "outbox_events"`).WillReturnError(errors.New("connection reset"))
+		sqlMock.ExpectRollback()
+		sqlMock.ExpectExec(`pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		published, err := relay.Relay(context.Background())
+
+		Expect(err).To(MatchError("connection reset"))
+		Expect(published).To(Equal(0))
+		Expect(sink.published).To(Equal([]string{"e1"}))
+	})
+})
+
+var _ = Describe("Enqueue", func() {
+	It("should number the events of each partition after the last sequence taken", func() {
+		db, sqlMock := newMockDB()
+		userID, otherUserID := uint(1), uint(2)
+
+		sqlMock.ExpectQuery(`INSERT INTO outbox_partitions`).WithArgs("user:1", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"last_sequence"}).AddRow(7))
+		sqlMock.ExpectQuery(`INSERT INTO outbox_partitions`).WithArgs("user:2", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"last_sequence"}).AddRow(1))
+		sqlMock.ExpectQuery(`INSERT INTO "outbox_events"`).
+			WithArgs(outboxArgs(6, 1, 7)...).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+
+		err := events.Enqueue(db,
+			events.Event{Type: "reward.granted", AggregateType: "reward", AggregateID: 1, UserID: &userID},
+			events.Event{Type: "reward.granted", AggregateType: "reward", AggregateID: 2, UserID: &otherUserID},
+			events.Event{Type: "reward.redeemed", AggregateType: "reward", AggregateID: 3, UserID: &userID},
+		)
+
+		Expect(err).To(BeNil())
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+})
+
+// outboxColumns is the number of columns inserted per outbox event, and
+// sequenceColumn the position of the sequence among them.
+const (
+	outboxColumns  = 15
+	sequenceColumn = 5
+)
+
+// outboxArgs matches the arguments of an insert of outbox events with the given
+// sequences.
+func outboxArgs(sequences ...int64) []driver.Value {
+	args := make([]driver.Value, 0, len(sequences)*outboxColumns)
+	for _, sequence := range sequences {
+		for i := 0; i < outboxColumns; i++ {
+			if i == sequenceColumn {
+				args = append(args, sequence)
+				continue
+			}
+			args = append(args, sqlmock.AnyArg())
+		}
+	}
+	return args
+}
+
+func newMockDB() (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, sqlMock, err := sqlmock.New()
+	Expect(err).To(BeNil())
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	Expect(err).To(BeNil())
+	return db, sqlMock
+}
+
+type fakeSink struct {
+	failing   map[string]bool
+	attempted []string
+	published []string
+}
+
+func (s *fakeSink) Publish(ctx context.Context, event events.Event) error {
+	s.attempted = append(s.attempted, event.ID)
+	if s.failing[event.ID] {
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
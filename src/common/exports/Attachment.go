@@ -0,0 +1,53 @@
+package exports
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Attachment returns a writer that sends an export as a file download named
+// after name and the current date, in CSV unless format is JSON lines. The headers are only sent on the first
+// write or flush, so an error returned before that is still rendered as a
+// problem response.
+func Attachment(ctx *gin.Context, name, format string) io.Writer {
+	return &attachment{ctx: ctx, name: name, format: format}
+}
+
+type attachment struct {
+	ctx     *gin.Context
+	name    string
+	format  string
+	started bool
+}
+
+func (a *attachment) Write(p []byte) (int, error) {
+	a.start()
+	return a.ctx.Writer.Write(p)
+}
+
+func (a *attachment) Flush() {
+	a.start()
+	a.ctx.Writer.Flush()
+}
+
+func (a *attachment) start() {
+	if a.started {
+		return
+	}
+	a.started = true
+
+	format, contentType := FormatCSV, "text/csv; charset=utf-8"
+	if a.format == FormatJSONL {
+		format, contentType = FormatJSONL, "application/x-ndjson"
+	}
+	a.ctx.Header("Content-Type", contentType)
+	a.ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("%s-%s.%s", a.name, time.Now().Format("20060102"), format),
+	}))
+	a.ctx.Status(http.StatusOK)
+}
@@ -0,0 +1,115 @@
+package exports
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Export formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// BatchSize is the number of rows fetched from the cursor at a time.
+const BatchSize = 1000
+
+// Stream runs the query behind a server-side cursor and passes the rows to fn
+// in batches, so an export holds one batch in memory whatever its size. The
+// cursor lives in its own database transaction, which sees a consistent
+// snapshot of the data.
+func Stream[T any](ctx context.Context, db *gorm.DB, query *gorm.DB, fn func([]T) error) error {
+	stmt := query.Session(&gorm.Session{DryRun: true}).Find(new([]T)).Statement
+	if stmt.Error != nil {
+		return stmt.Error
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The statement is already bound for the database, so it bypasses the
+		// placeholder expansion of gorm.
+		_, err := tx.Statement.ConnPool.ExecContext(ctx, "DECLARE export_cursor NO SCROLL CURSOR FOR "+stmt.SQL.String(), stmt.Vars...)
+		if err != nil {
+			return err
+		}
+
+		for {
+			var batch []T
+			err = tx.Raw(fmt.Sprintf("FETCH %d FROM export_cursor", BatchSize)).Scan(&batch).Error
+			if err != nil {
+				return err
+			}
+			if len(batch) == 0 {
+				return tx.Exec("CLOSE export_cursor").Error
+			}
+
+			err = fn(batch)
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// Encoder writes items as CSV, with a header of columns, or as JSON lines.
+// It flushes the underlying writer on Flush so that rows reach the client as
+// they are produced.
+type Encoder[T any] struct {
+	w       io.Writer
+	csv     *csv.Writer
+	json    *json.Encoder
+	columns []string
+	record  func(*T) []string
+	started bool
+}
+
+// NewEncoder returns an encoder for the format. record returns the CSV values
+// of an item in the order of columns; JSON lines encode the item itself.
+func NewEncoder[T any](w io.Writer, format string, columns []string, record func(*T) []string) *Encoder[T] {
+	encoder := &Encoder[T]{w: w, columns: columns, record: record}
+	if format == FormatJSONL {
+		encoder.json = json.NewEncoder(w)
+	} else {
+		encoder.csv = csv.NewWriter(w)
+	}
+	return encoder
+}
+
+func (e *Encoder[T]) Write(item *T) error {
+	if e.json != nil {
+		return e.json.Encode(item)
+	}
+
+	if !e.started {
+		e.started = true
+		err := e.csv.Write(e.columns)
+		if err != nil {
+			return err
+		}
+	}
+	return e.csv.Write(e.record(item))
+}
+
+// Flush writes the buffered rows out. A CSV export with no rows still gets
+// its header.
+func (e *Encoder[T]) Flush() error {
+	if e.csv != nil {
+		if !e.started {
+			e.started = true
+			_ = e.csv.Write(e.columns)
+		}
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
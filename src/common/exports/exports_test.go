@@ -0,0 +1,191 @@
+package exports_test
+
+import (
+	"context"
+	"errors"
+	"loyalty-campaigns/src/common/exports"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+var itemColumns = []string{"id", "name"}
+
+func itemRecord(item *item) []string {
+	return []string{strconv.FormatUint(uint64(item.ID), 10), item.Name}
+}
+
+var _ = Describe("Encoder", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+	})
+
+	It("should write CSV with a header and quote the values that need it", func() {
+		encoder := exports.NewEncoder(recorder, exports.FormatCSV, itemColumns, itemRecord)
+
+		Expect(encoder.Write(&item{ID: 1, Name: "coffee"})).To(Succeed())
+		Expect(encoder.Write(&item{ID: 2, Name: `tea, "green"`})).To(Succeed())
+		Expect(encoder.Flush()).To(Succeed())
+
+		Expect(recorder.Body.String()).To(Equal("id,name\n1,coffee\n2,\"tea, \"\"green\"\"\"\n"))
+	})
+
+	It("should write only the header of an empty CSV export", func() {
+		encoder := exports.NewEncoder(recorder, exports.FormatCSV, itemColumns, itemRecord)
+
+		Expect(encoder.Flush()).To(Succeed())
+		Expect(encoder.Flush()).To(Succeed())
+
+		Expect(recorder.Body.String()).To(Equal("id,name\n"))
+	})
+
+	It("should write one JSON document per line without a header", func() {
+		encoder := exports.NewEncoder(recorder, exports.FormatJSONL, itemColumns, itemRecord)
+
+		Expect(encoder.Write(&item{ID: 1, Name: "coffee"})).To(Succeed())
+		Expect(encoder.Write(&item{ID: 2, Name: "tea"})).To(Succeed())
+		Expect(encoder.Flush()).To(Succeed())
+
+		Expect(recorder.Body.String()).To(Equal("{\"id\":1,\"name\":\"coffee\"}\n{\"id\":2,\"name\":\"tea\"}\n"))
+	})
+
+	It("should flush the rows to the client", func() {
+		encoder := exports.NewEncoder(recorder, exports.FormatCSV, itemColumns, itemRecord)
+
+		Expect(encoder.Write(&item{ID: 1, Name: "coffee"})).To(Succeed())
+		Expect(recorder.Flushed).To(BeFalse())
+		Expect(encoder.Flush()).To(Succeed())
+
+		Expect(recorder.Flushed).To(BeTrue())
+	})
+})
+
+var _ = Describe("Attachment", func() {
+	var (
+		recorder *httptest.ResponseRecorder
+		ctx      *gin.Context
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		recorder = httptest.NewRecorder()
+		ctx, _ = gin.CreateTestContext(recorder)
+	})
+
+	It("should not send the headers before the first write", func() {
+		exports.Attachment(ctx, "users", exports.FormatCSV)
+
+		Expect(ctx.Writer.Written()).To(BeFalse())
+		Expect(ctx.Writer.Header().Get("Content-Disposition")).To(BeEmpty())
+	})
+
+	It("should send a CSV file named after the export and the date", func() {
+		w := exports.Attachment(ctx, "users", "")
+
+		_, err := w.Write([]byte("id\n"))
+
+		Expect(err).To(BeNil())
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("text/csv; charset=utf-8"))
+		Expect(recorder.Header().Get("Content-Disposition")).To(Equal("attachment; filename=users-" + time.Now().Format("20060102") + ".csv"))
+		Expect(recorder.Body.String()).To(Equal("id\n"))
+	})
+
+	It("should send JSON lines when asked", func() {
+		w := exports.Attachment(ctx, "transactions", exports.FormatJSONL)
+
+		w.(http.Flusher).Flush()
+
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+		Expect(recorder.Header().Get("Content-Disposition")).To(HaveSuffix(".jsonl"))
+		Expect(recorder.Flushed).To(BeTrue())
+	})
+})
+
+var _ = Describe("Stream", func() {
+	var (
+		db      *gorm.DB
+		sqlMock sqlmock.Sqlmock
+		ctx     context.Context
+	)
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err = gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	query := func() *gorm.DB {
+		return db.Where("name ILIKE ?", "%co%").Order("id")
+	}
+
+	It("should pass the rows of the query to fn in batches read from a cursor", func() {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(`^DECLARE export_cursor NO SCROLL CURSOR FOR SELECT \* FROM "items" WHERE name ILIKE \$1 ORDER BY id$`).
+			WithArgs("%co%").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).
+			WillReturnRows(sqlmock.NewRows(itemColumns).AddRow(1, "coffee").AddRow(2, "cocoa"))
+		sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).
+			WillReturnRows(sqlmock.NewRows(itemColumns).AddRow(3, "coconut"))
+		sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).
+			WillReturnRows(sqlmock.NewRows(itemColumns))
+		sqlMock.ExpectExec(`^CLOSE export_cursor$`).WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectCommit()
+
+		var batches [][]item
+		err := exports.Stream(ctx, db, query(), func(batch []item) error {
+			batches = append(batches, batch)
+			return nil
+		})
+
+		Expect(err).To(BeNil())
+		Expect(batches).To(Equal([][]item{
+			{{ID: 1, Name: "coffee"}, {ID: 2, Name: "cocoa"}},
+			{{ID: 3, Name: "coconut"}},
+		}))
+	})
+
+	It("should stop and roll back when fn fails", func() {
+		failure := errors.New("client went away")
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectExec(`^DECLARE export_cursor`).WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).
+			WillReturnRows(sqlmock.NewRows(itemColumns).AddRow(1, "coffee"))
+		sqlMock.ExpectRollback()
+
+		calls := 0
+		err := exports.Stream(ctx, db, query(), func(batch []item) error {
+			calls++
+			return failure
+		})
+
+		Expect(err).To(MatchError(failure))
+		Expect(calls).To(Equal(1))
+	})
+})
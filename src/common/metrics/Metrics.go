@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const namespace = "loyalty"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	TransactionsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_processed_total",
+		Help:      "Number of transactions processed by the loyalty engine.",
+	}, []string{"merchant"})
+
+	RewardsGranted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rewards_granted_total",
+		Help:      "Number of rewards granted by reward type and merchant.",
+	}, []string{"type", "merchant"})
+
+	RewardsGrantedAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rewards_granted_amount_total",
+		Help:      "Sum of reward amounts granted by reward type and merchant.",
+	}, []string{"type", "merchant"})
+
+	RewardsExpired = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rewards_expired_total",
+		Help:      "Number of rewards removed after their expiry date by reward type and merchant.",
+	}, []string{"type", "merchant"})
+
+	RewardsExpiredAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rewards_expired_amount_total",
+		Help:      "Sum of reward amounts expired by reward type and merchant.",
+	}, []string{"type", "merchant"})
+
+	Redemptions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redemptions_total",
+		Help:      "Number of successful redemptions by reward type and merchant.",
+	}, []string{"type", "merchant"})
+
+	InsufficientBalanceRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "insufficient_balance_rejections_total",
+		Help:      "Number of redemptions rejected because of insufficient balance.",
+	}, []string{"type", "merchant"})
+
+	CampaignHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "campaign_hits_total",
+		Help:      "Number of times a campaign was applied to a transaction.",
+	}, []string{"campaign", "merchant"})
+
+	OutboxEventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_published_total",
+		Help:      "Number of domain events delivered to the sink by event type.",
+	}, []string{"type"})
+
+	OutboxPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "publish_failures_total",
+		Help:      "Number of failed attempts to deliver a domain event by event type.",
+	}, []string{"type"})
+
+	OutboxDeadEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "dead_events_total",
+		Help:      "Number of domain events given up after running out of attempts by event type.",
+	}, []string{"type"})
+)
+
+// RegisterDBStats exposes the connection pool statistics of the underlying sql.DB.
+func RegisterDBStats(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, namespace))
+}
+
+// AddAmount adds amount to a counter of amounts. Counters only go up and Add
+// panics on a negative value, so amounts that are not positive are left out.
+func AddAmount(counter prometheus.Counter, amount float64) {
+	if amount > 0 {
+		counter.Add(amount)
+	}
+}
+
+// ID formats a numeric identifier as a label value.
+func ID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,412 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"loyalty-campaigns/src/common/utils"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// advisoryLockID identifies the Postgres advisory lock that serializes
+// migration runs, so that two instances never migrate at the same time.
+const advisoryLockID = 7070031
+
+var (
+	ErrSchemaOutdated = errors.New("database schema is not up to date")
+	ErrUnknownVersion = errors.New("database has a migration this build does not know about")
+	ErrChecksumDrift  = errors.New("migration was changed after it was applied")
+	ErrInvalidSteps   = errors.New("the number of migrations to roll back must be positive")
+
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+)
+
+// Migration is a versioned schema change embedded in the binary. Files are named
+// NNNN_name.up.sql and NNNN_name.down.sql and applied in version order.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Checksum identifies the contents of the up script, so that editing a
+// migration that was already applied can be detected.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
+
+type IMigrator interface {
+	Up(ctx context.Context) ([]Migration, error)
+	Down(ctx context.Context, steps int) ([]Migration, error)
+	Status(ctx context.Context) ([]MigrationStatus, error)
+	Verify(ctx context.Context) error
+}
+
+type migrator struct {
+	db     *gorm.DB
+	source fs.FS
+	logger utils.ILogger
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  *string
+	AppliedAt time.Time
+}
+
+func NewMigrator(db *gorm.DB) IMigrator {
+	source, err := fs.Sub(files, "sql")
+	if err != nil {
+		panic(err)
+	}
+	return NewMigratorFS(db, source)
+}
+
+// NewMigratorFS returns a migrator for the migration files at the root of source.
+func NewMigratorFS(db *gorm.DB, source fs.FS) IMigrator {
+	return &migrator{
+		db:     db,
+		source: source,
+		logger: utils.NewLogger(),
+	}
+}
+
+// Up applies every pending migration, each one in its own transaction. It
+// refuses to run when an applied migration was changed since.
+func (m *migrator) Up(ctx context.Context) ([]Migration, error) {
+	migrations, err := LoadFS(m.source)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		err = checkApplied(migrations, done, false)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if row, ok := done[migration.Version]; ok {
+				if row.Checksum == nil {
+					// Recorded before checksums were kept
+					err := conn.Exec("UPDATE schema_migrations SET checksum = ? WHERE version = ?", migration.Checksum(), migration.Version).Error
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Exec("INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)", migration.Version, migration.Name, migration.Checksum()).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info("applied migration %04d_%s", migration.Version, migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, ErrInvalidSteps
+	}
+
+	migrations, err := LoadFS(m.source)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err = m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(done))
+		for version := range done {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		if steps < len(versions) {
+			versions = versions[:steps]
+		}
+
+		for _, version := range versions {
+			migration, ok := byVersion[version]
+			if !ok {
+				return fmt.Errorf("%w: %04d_%s", ErrUnknownVersion, version, done[version].Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("rollback of %04d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			m.logger.Info("rolled back migration %04d_%s", migration.Version, migration.Name)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists the known migrations and the ones recorded in the database,
+// in version order. Pending migrations have no AppliedAt.
+func (m *migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	migrations, err := LoadFS(m.source)
+	if err != nil {
+		return nil, err
+	}
+
+	done, err := m.readApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := done[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range done {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{Version: row.Version, Name: row.Name, AppliedAt: &appliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Verify checks that the database has applied exactly the migrations embedded
+// in this build, unchanged. It never changes the schema; run the migrate
+// command for that.
+func (m *migrator) Verify(ctx context.Context) error {
+	migrations, err := LoadFS(m.source)
+	if err != nil {
+		return err
+	}
+
+	done, err := m.readApplied(ctx)
+	if err != nil {
+		return err
+	}
+	err = checkApplied(migrations, done, true)
+	if err != nil {
+		return err
+	}
+
+	var current int64
+	for version := range done {
+		if version > current {
+			current = version
+		}
+	}
+
+	pending := 0
+	for _, migration := range migrations {
+		if _, ok := done[migration.Version]; !ok {
+			pending++
+		}
+	}
+	if pending > 0 {
+		latest := migrations[len(migrations)-1].Version
+		return fmt.Errorf("%w: database is at version %d, this build expects %d (%d pending)", ErrSchemaOutdated, current, latest, pending)
+	}
+	return nil
+}
+
+// checkApplied reports applied migrations whose up script no longer matches
+// the recorded checksum and, when strict, applied migrations this build does
+// not know about.
+func checkApplied(migrations []Migration, done map[int64]appliedMigration, strict bool) error {
+	known := make(map[int64]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		row, ok := done[migration.Version]
+		if ok && row.Checksum != nil && *row.Checksum != migration.Checksum() {
+			return fmt.Errorf("%w: %04d_%s", ErrChecksumDrift, migration.Version, migration.Name)
+		}
+	}
+	if !strict {
+		return nil
+	}
+
+	for version, row := range done {
+		if !known[version] {
+			return fmt.Errorf("%w: %04d_%s", ErrUnknownVersion, version, row.Name)
+		}
+	}
+	return nil
+}
+
+// withLock runs fn on a single connection while holding the migrations
+// advisory lock, creating the schema_migrations table if needed.
+func (m *migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		err := conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockID).Error
+		if err != nil {
+			return fmt.Errorf("failed to acquire the migrations lock: %w", err)
+		}
+		defer func() {
+			err := conn.Exec("SELECT pg_advisory_unlock(?)", advisoryLockID).Error
+			if err != nil {
+				m.logger.Error("failed to release the migrations lock: %v", err)
+			}
+		}()
+
+		err = conn.Exec(`
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    BIGINT PRIMARY KEY,
+				name       TEXT NOT NULL,
+				checksum   TEXT,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			)
+		`).Error
+		if err == nil {
+			err = conn.Exec("ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum TEXT").Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create the schema_migrations table: %w", err)
+		}
+
+		return fn(conn)
+	})
+}
+
+// readApplied returns the applied migrations without taking the lock. A
+// database that was never migrated has no schema_migrations table yet.
+func (m *migrator) readApplied(ctx context.Context) (map[int64]appliedMigration, error) {
+	db := m.db.WithContext(ctx)
+
+	var table *string
+	err := db.Raw("SELECT to_regclass('schema_migrations')::text").Scan(&table).Error
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return map[int64]appliedMigration{}, nil
+	}
+
+	return appliedVersions(db)
+}
+
+func appliedVersions(db *gorm.DB) (map[int64]appliedMigration, error) {
+	// The checksum is read through to_jsonb because Verify may run against a
+	// table created before the column existed.
+	var rows []appliedMigration
+	err := db.Raw("SELECT version, name, to_jsonb(schema_migrations)->>'checksum' AS checksum, applied_at FROM schema_migrations ORDER BY version").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// Load reads the migrations embedded in the binary, sorted by version.
+func Load() ([]Migration, error) {
+	source, err := fs.Sub(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return LoadFS(source)
+}
+
+// LoadFS reads the migration files at the root of source, sorted by version.
+func LoadFS(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has two names: %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
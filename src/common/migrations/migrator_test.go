@@ -0,0 +1,312 @@
+package migrations_test
+
+import (
+	"context"
+	"errors"
+	"loyalty-campaigns/src/common/migrations"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("Migrator", func() {
+	var (
+		db       *gorm.DB
+		sqlMock  sqlmock.Sqlmock
+		source   fstest.MapFS
+		migrator migrations.IMigrator
+		ctx      context.Context
+	)
+
+	appliedColumns := []string{"version", "name", "checksum", "applied_at"}
+	appliedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		db, sqlMock = newMockDB()
+		// Version 10 sorts before 2 as text
+		source = fstest.MapFS{
+			"10_second.up.sql":   {Data: []byte("CREATE TABLE second ();")},
+			"10_second.down.sql": {Data: []byte("DROP TABLE second;")},
+			"2_first.up.sql":     {Data: []byte("CREATE TABLE first ();")},
+			"2_first.down.sql":   {Data: []byte("DROP TABLE first;")},
+		}
+		migrator = migrations.NewMigratorFS(db, source)
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	checksum := func(version int64) string {
+		loaded, err := migrations.LoadFS(source)
+		Expect(err).To(BeNil())
+		for _, migration := range loaded {
+			if migration.Version == version {
+				return migration.Checksum()
+			}
+		}
+		Fail("unknown migration")
+		return ""
+	}
+
+	expectLock := func() {
+		sqlMock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+		sqlMock.ExpectExec(`ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum`).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	expectUnlock := func() {
+		sqlMock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	expectTable := func() {
+		sqlMock.ExpectQuery(`SELECT to_regclass\('schema_migrations'\)::text`).
+			WillReturnRows(sqlmock.NewRows([]string{"to_regclass"}).AddRow("schema_migrations"))
+	}
+
+	Describe("LoadFS", func() {
+		It("should sort the migrations by numeric version", func() {
+			loaded, err := migrations.LoadFS(source)
+
+			Expect(err).To(BeNil())
+			Expect(loaded).To(HaveLen(2))
+			Expect(loaded[0].Version).To(Equal(int64(2)))
+			Expect(loaded[0].Name).To(Equal("first"))
+			Expect(loaded[0].Up).To(Equal("CREATE TABLE first ();"))
+			Expect(loaded[0].Down).To(Equal("DROP TABLE first;"))
+			Expect(loaded[1].Version).To(Equal(int64(10)))
+		})
+
+		It("should reject a migration without a down file", func() {
+			delete(source, "2_first.down.sql")
+
+			_, err := migrations.LoadFS(source)
+
+			Expect(err).To(MatchError(ContainSubstring("needs both an up and a down file")))
+		})
+
+		It("should reject a version with two names", func() {
+			source["2_other.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+			_, err := migrations.LoadFS(source)
+
+			Expect(err).To(MatchError(ContainSubstring("has two names")))
+		})
+
+		It("should reject an invalid file name", func() {
+			source["first.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+			_, err := migrations.LoadFS(source)
+
+			Expect(err).To(MatchError(ContainSubstring("invalid migration file name")))
+		})
+
+		It("should load the embedded migrations in contiguous order", func() {
+			loaded, err := migrations.Load()
+
+			Expect(err).To(BeNil())
+			for i, migration := range loaded {
+				Expect(migration.Version).To(Equal(int64(i + 1)))
+			}
+		})
+	})
+
+	Describe("Verify", func() {
+		It("should pass when every migration is applied unchanged", func() {
+			expectTable()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).
+					AddRow(2, "first", checksum(2), appliedAt).
+					AddRow(10, "second", nil, appliedAt))
+
+			Expect(migrator.Verify(ctx)).To(Succeed())
+		})
+
+		It("should report pending migrations", func() {
+			expectTable()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).AddRow(2, "first", checksum(2), appliedAt))
+
+			err := migrator.Verify(ctx)
+
+			Expect(err).To(MatchError(migrations.ErrSchemaOutdated))
+			Expect(err).To(MatchError(ContainSubstring("database is at version 2, this build expects 10 (1 pending)")))
+		})
+
+		It("should report a database that was never migrated", func() {
+			sqlMock.ExpectQuery(`SELECT to_regclass\('schema_migrations'\)::text`).
+				WillReturnRows(sqlmock.NewRows([]string{"to_regclass"}).AddRow(nil))
+
+			Expect(migrator.Verify(ctx)).To(MatchError(migrations.ErrSchemaOutdated))
+		})
+
+		It("should report migrations this build does not know about", func() {
+			expectTable()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).
+					AddRow(2, "first", checksum(2), appliedAt).
+					AddRow(10, "second", checksum(10), appliedAt).
+					AddRow(11, "third", "abc", appliedAt))
+
+			err := migrator.Verify(ctx)
+
+			Expect(err).To(MatchError(migrations.ErrUnknownVersion))
+			Expect(err).To(MatchError(ContainSubstring("0011_third")))
+		})
+
+		It("should report a migration changed after it was applied", func() {
+			expectTable()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).
+					AddRow(2, "first", "edited", appliedAt).
+					AddRow(10, "second", checksum(10), appliedAt))
+
+			err := migrator.Verify(ctx)
+
+			Expect(err).To(MatchError(migrations.ErrChecksumDrift))
+			Expect(err).To(MatchError(ContainSubstring("0002_first")))
+		})
+	})
+
+	Describe("Up", func() {
+		It("should apply the pending migrations in version order while holding the lock", func() {
+			expectLock()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).AddRow(2, "first", nil, appliedAt))
+			// Rows recorded before checksums were kept get one
+			sqlMock.ExpectExec(`UPDATE schema_migrations SET checksum = \$1 WHERE version = \$2`).
+				WithArgs(checksum(2), 2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`CREATE TABLE second \(\);`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectExec(`INSERT INTO schema_migrations \(version, name, checksum\) VALUES \(\$1, \$2, \$3\)`).
+				WithArgs(10, "second", checksum(10)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			sqlMock.ExpectCommit()
+			expectUnlock()
+
+			applied, err := migrator.Up(ctx)
+
+			Expect(err).To(BeNil())
+			Expect(applied).To(HaveLen(1))
+			Expect(applied[0].Version).To(Equal(int64(10)))
+		})
+
+		It("should roll back a failed migration and release the lock", func() {
+			expectLock()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns))
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`CREATE TABLE first \(\);`).WillReturnError(errors.New("syntax error"))
+			sqlMock.ExpectRollback()
+			expectUnlock()
+
+			applied, err := migrator.Up(ctx)
+
+			Expect(err).To(MatchError(ContainSubstring("migration 0002_first failed: syntax error")))
+			Expect(applied).To(BeEmpty())
+		})
+
+		It("should not migrate without the lock", func() {
+			sqlMock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WillReturnError(errors.New("connection lost"))
+
+			_, err := migrator.Up(ctx)
+
+			Expect(err).To(MatchError(ContainSubstring("failed to acquire the migrations lock")))
+		})
+
+		It("should refuse to run when an applied migration changed", func() {
+			expectLock()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).AddRow(2, "first", "edited", appliedAt))
+			expectUnlock()
+
+			applied, err := migrator.Up(ctx)
+
+			Expect(err).To(MatchError(migrations.ErrChecksumDrift))
+			Expect(applied).To(BeEmpty())
+		})
+	})
+
+	Describe("Down", func() {
+		It("should reject a non-positive number of steps", func() {
+			for _, steps := range []int{0, -1} {
+				reverted, err := migrator.Down(ctx, steps)
+
+				Expect(err).To(MatchError(migrations.ErrInvalidSteps))
+				Expect(reverted).To(BeEmpty())
+			}
+		})
+
+		It("should roll back the newest migrations first", func() {
+			expectLock()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).
+					AddRow(2, "first", checksum(2), appliedAt).
+					AddRow(10, "second", checksum(10), appliedAt))
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`DROP TABLE second;`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \$1`).WithArgs(10).WillReturnResult(sqlmock.NewResult(0, 1))
+			sqlMock.ExpectCommit()
+			expectUnlock()
+
+			reverted, err := migrator.Down(ctx, 1)
+
+			Expect(err).To(BeNil())
+			Expect(reverted).To(HaveLen(1))
+			Expect(reverted[0].Version).To(Equal(int64(10)))
+		})
+
+		It("should not roll back a migration this build does not know about", func() {
+			expectLock()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).
+					AddRow(2, "first", checksum(2), appliedAt).
+					AddRow(11, "third", "abc", appliedAt))
+			expectUnlock()
+
+			reverted, err := migrator.Down(ctx, 5)
+
+			Expect(err).To(MatchError(migrations.ErrUnknownVersion))
+			Expect(reverted).To(BeEmpty())
+		})
+	})
+
+	Describe("Status", func() {
+		It("should list known and unknown migrations in version order", func() {
+			expectTable()
+			sqlMock.ExpectQuery(`FROM schema_migrations ORDER BY version`).
+				WillReturnRows(sqlmock.NewRows(appliedColumns).
+					AddRow(2, "first", checksum(2), appliedAt).
+					AddRow(11, "third", "abc", appliedAt))
+
+			statuses, err := migrator.Status(ctx)
+
+			Expect(err).To(BeNil())
+			Expect(statuses).To(HaveLen(3))
+			Expect(statuses[0].Version).To(Equal(int64(2)))
+			Expect(statuses[0].AppliedAt).NotTo(BeNil())
+			Expect(statuses[1].Version).To(Equal(int64(10)))
+			Expect(statuses[1].AppliedAt).To(BeNil())
+			Expect(statuses[2].Version).To(Equal(int64(11)))
+		})
+	})
+})
+
+func newMockDB() (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, sqlMock, err := sqlmock.New()
+	Expect(err).To(BeNil())
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	Expect(err).To(BeNil())
+	return db, sqlMock
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS rewards;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS campaigns;
+DROP TABLE IF EXISTS branches;
+DROP TABLE IF EXISTS merchants;
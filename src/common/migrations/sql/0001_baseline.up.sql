@@ -0,0 +1,116 @@
+-- Baseline schema, equivalent to what AutoMigrate created before versioned
+-- migrations were introduced. Every statement is idempotent so that existing
+-- databases can adopt it without changes.
+
+CREATE TABLE IF NOT EXISTS merchants (
+    id                  BIGSERIAL PRIMARY KEY,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ,
+    deleted_at          TIMESTAMPTZ,
+    name                TEXT,
+    conversion_factor   DECIMAL,
+    default_reward_type TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_merchants_deleted_at ON merchants (deleted_at);
+
+CREATE TABLE IF NOT EXISTS branches (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    name        TEXT,
+    merchant_id BIGINT,
+    CONSTRAINT fk_merchants_branches FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_branches_deleted_at ON branches (deleted_at);
+
+CREATE TABLE IF NOT EXISTS campaigns (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    merchant_id BIGINT NOT NULL,
+    branch_id   BIGINT,
+    start_date  TIMESTAMPTZ NOT NULL,
+    end_date    TIMESTAMPTZ,
+    type        TEXT NOT NULL,
+    value       DECIMAL NOT NULL,
+    min_amount  DECIMAL,
+    CONSTRAINT fk_merchants_campaigns FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_branches_campaigns FOREIGN KEY (branch_id) REFERENCES branches (id)
+);
+CREATE INDEX IF NOT EXISTS idx_campaigns_deleted_at ON campaigns (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_campaigns_branch_id ON campaigns (branch_id);
+
+CREATE TABLE IF NOT EXISTS users (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    name       TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id    BIGINT,
+    branch_id  BIGINT,
+    amount     DECIMAL,
+    date       TIMESTAMPTZ,
+    CONSTRAINT fk_users_transactions FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_branches_transactions FOREIGN KEY (branch_id) REFERENCES branches (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS rewards (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    user_id     BIGINT,
+    merchant_id BIGINT,
+    type        TEXT,
+    amount      DECIMAL,
+    CONSTRAINT fk_users_rewards FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_rewards_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_rewards_deleted_at ON rewards (deleted_at);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGSERIAL PRIMARY KEY,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ,
+    merchant_id  BIGINT NOT NULL,
+    branch_id    BIGINT,
+    name         TEXT NOT NULL,
+    role         TEXT NOT NULL DEFAULT 'merchant_admin',
+    prefix       TEXT NOT NULL,
+    key_hash     TEXT NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    CONSTRAINT fk_api_keys_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_api_keys_branch FOREIGN KEY (branch_id) REFERENCES branches (id)
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_deleted_at ON api_keys (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_api_keys_merchant_id ON api_keys (merchant_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_branch_id ON api_keys (branch_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
+
+CREATE TABLE IF NOT EXISTS memberships (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    user_id     BIGINT NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    enrolled_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_memberships_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_memberships_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+CREATE INDEX IF NOT EXISTS idx_memberships_deleted_at ON memberships (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_memberships_merchant_id ON memberships (merchant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_memberships_user_merchant ON memberships (user_id, merchant_id);
@@ -0,0 +1,3 @@
+-- Backfilled memberships cannot be told apart from regular enrollments, so
+-- rolling back this migration keeps them.
+SELECT 1;
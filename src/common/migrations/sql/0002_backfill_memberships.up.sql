@@ -0,0 +1,15 @@
+-- Enroll users in the merchants they already transacted with or earned rewards
+-- from, so that merchant scoped queries keep seeing them.
+INSERT INTO memberships (user_id, merchant_id, enrolled_at, created_at, updated_at)
+SELECT user_id, merchant_id, MIN(first_seen), NOW(), NOW()
+FROM (
+    SELECT t.user_id, b.merchant_id, t.date AS first_seen
+    FROM transactions t JOIN branches b ON b.id = t.branch_id
+    WHERE t.deleted_at IS NULL
+    UNION ALL
+    SELECT r.user_id, r.merchant_id, r.created_at AS first_seen
+    FROM rewards r
+    WHERE r.deleted_at IS NULL
+) AS activity
+GROUP BY user_id, merchant_id
+ON CONFLICT (user_id, merchant_id) DO NOTHING;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS balances;
+DROP INDEX IF EXISTS idx_rewards_expiry_date;
+ALTER TABLE rewards DROP COLUMN IF EXISTS expiry_date;
+ALTER TABLE merchants DROP COLUMN IF EXISTS reward_validity_days;
@@ -0,0 +1,23 @@
+ALTER TABLE merchants ADD COLUMN reward_validity_days BIGINT;
+
+ALTER TABLE rewards ADD COLUMN expiry_date TIMESTAMPTZ;
+CREATE INDEX idx_rewards_expiry_date ON rewards (expiry_date);
+
+CREATE TABLE balances (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    user_id     BIGINT NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    type        TEXT NOT NULL,
+    amount      DECIMAL NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_balances_deleted_at ON balances (deleted_at);
+CREATE UNIQUE INDEX idx_balances_user_merchant_type ON balances (user_id, merchant_id, type);
+
+INSERT INTO balances (user_id, merchant_id, type, amount, created_at, updated_at)
+SELECT user_id, merchant_id, type, SUM(amount), NOW(), NOW()
+FROM rewards
+WHERE deleted_at IS NULL AND user_id IS NOT NULL AND merchant_id IS NOT NULL AND type IS NOT NULL
+GROUP BY user_id, merchant_id, type;
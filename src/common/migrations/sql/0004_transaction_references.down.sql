@@ -0,0 +1,9 @@
+ALTER TABLE balances DROP CONSTRAINT IF EXISTS fk_balances_merchant;
+ALTER TABLE balances DROP CONSTRAINT IF EXISTS fk_balances_user;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_branch_merchant;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_transactions_merchant;
+ALTER TABLE branches DROP CONSTRAINT IF EXISTS uni_branches_id_merchant;
+
+DROP INDEX IF EXISTS idx_transactions_merchant_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS merchant_id;
@@ -0,0 +1,14 @@
+-- Transactions record the merchant of their branch, and the pair must match an
+-- existing branch so a transaction can never credit another merchant's program.
+ALTER TABLE transactions ADD COLUMN merchant_id BIGINT;
+UPDATE transactions SET merchant_id = branches.merchant_id
+FROM branches
+WHERE branches.id = transactions.branch_id;
+CREATE INDEX idx_transactions_merchant_id ON transactions (merchant_id);
+
+ALTER TABLE branches ADD CONSTRAINT uni_branches_id_merchant UNIQUE (id, merchant_id);
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id);
+ALTER TABLE transactions ADD CONSTRAINT fk_transactions_branch_merchant FOREIGN KEY (branch_id, merchant_id) REFERENCES branches (id, merchant_id);
+
+ALTER TABLE balances ADD CONSTRAINT fk_balances_user FOREIGN KEY (user_id) REFERENCES users (id);
+ALTER TABLE balances ADD CONSTRAINT fk_balances_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id);
@@ -0,0 +1,20 @@
+CREATE TABLE outbox_events (
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        TEXT NOT NULL,
+    type            TEXT NOT NULL,
+    aggregate_type  TEXT NOT NULL,
+    aggregate_id    BIGINT NOT NULL,
+    partition_key   TEXT NOT NULL,
+    user_id         BIGINT,
+    merchant_id     BIGINT,
+    payload         JSONB NOT NULL,
+    occurred_at     TIMESTAMPTZ NOT NULL,
+    attempts        BIGINT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error      TEXT,
+    published_at    TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_outbox_events_event_id ON outbox_events (event_id);
+CREATE INDEX idx_outbox_events_partition_key ON outbox_events (partition_key);
+-- The relay only scans the events that are still pending.
+CREATE INDEX idx_outbox_events_pending ON outbox_events (id) WHERE published_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS import_rows;
+DROP TABLE IF EXISTS import_jobs;
+DROP INDEX IF EXISTS idx_transactions_merchant_external_ref;
+ALTER TABLE transactions DROP COLUMN IF EXISTS external_ref;
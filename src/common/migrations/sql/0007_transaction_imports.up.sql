@@ -0,0 +1,46 @@
+-- Transactions may carry the reference of the sale in the merchant's own
+-- system. A reference is processed once per merchant, which deduplicates both
+-- retried requests and rows imported twice.
+ALTER TABLE transactions ADD COLUMN external_ref TEXT;
+CREATE UNIQUE INDEX idx_transactions_merchant_external_ref ON transactions (merchant_id, external_ref) WHERE external_ref IS NOT NULL;
+
+CREATE TABLE import_jobs (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    merchant_id BIGINT NOT NULL,
+    branch_id   BIGINT,
+    format      TEXT NOT NULL,
+    file_name   TEXT,
+    content     BYTEA NOT NULL,
+    status      TEXT NOT NULL,
+    total       BIGINT NOT NULL DEFAULT 0,
+    processed   BIGINT NOT NULL DEFAULT 0,
+    succeeded   BIGINT NOT NULL DEFAULT 0,
+    failed      BIGINT NOT NULL DEFAULT 0,
+    duplicates  BIGINT NOT NULL DEFAULT 0,
+    error       TEXT,
+    lease_until TIMESTAMPTZ,
+    started_at  TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    CONSTRAINT fk_import_jobs_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_import_jobs_branch FOREIGN KEY (branch_id, merchant_id) REFERENCES branches (id, merchant_id)
+);
+CREATE INDEX idx_import_jobs_deleted_at ON import_jobs (deleted_at);
+CREATE INDEX idx_import_jobs_merchant_id ON import_jobs (merchant_id);
+CREATE INDEX idx_import_jobs_status ON import_jobs (status);
+
+CREATE TABLE import_rows (
+    id            BIGSERIAL PRIMARY KEY,
+    import_job_id BIGINT NOT NULL,
+    line          BIGINT NOT NULL,
+    external_ref  TEXT,
+    status        TEXT NOT NULL,
+    error_code    TEXT,
+    error         TEXT,
+    raw           TEXT,
+    created_at    TIMESTAMPTZ,
+    CONSTRAINT fk_import_rows_import_job FOREIGN KEY (import_job_id) REFERENCES import_jobs (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_import_rows_job_line ON import_rows (import_job_id, line);
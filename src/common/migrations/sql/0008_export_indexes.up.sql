@@ -0,0 +1,3 @@
+-- Reward movements are exported from the outbox by merchant, in the order the
+-- events were recorded.
+CREATE INDEX idx_outbox_events_reward_merchant ON outbox_events (merchant_id, id) WHERE type LIKE 'reward.%';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_events_reward_movements;
+DROP INDEX IF EXISTS idx_transactions_merchant_date;
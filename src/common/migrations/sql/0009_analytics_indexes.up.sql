@@ -0,0 +1,4 @@
+-- Merchant analytics aggregate transactions and reward events by merchant over
+-- a date range.
+CREATE INDEX idx_transactions_merchant_date ON transactions (merchant_id, date) WHERE deleted_at IS NULL;
+CREATE INDEX idx_outbox_events_reward_movements ON outbox_events (merchant_id, occurred_at) WHERE type IN ('reward.granted', 'reward.redeemed');
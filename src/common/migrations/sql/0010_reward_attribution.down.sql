@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_outbox_events_aggregate;
+
+DROP INDEX IF EXISTS idx_rewards_campaign_id;
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_transaction;
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_campaign;
+ALTER TABLE rewards DROP COLUMN IF EXISTS transaction_id;
+ALTER TABLE rewards DROP COLUMN IF EXISTS campaign_id;
@@ -0,0 +1,10 @@
+-- Rewards remember the campaign and the transaction that earned them, so that
+-- campaign reports can attribute their cost and participants.
+ALTER TABLE rewards ADD COLUMN campaign_id BIGINT;
+ALTER TABLE rewards ADD COLUMN transaction_id BIGINT;
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns (id);
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id);
+CREATE INDEX idx_rewards_campaign_id ON rewards (campaign_id);
+
+-- The movements of a reward are looked up by aggregate.
+CREATE INDEX idx_outbox_events_aggregate ON outbox_events (aggregate_type, aggregate_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS item_redemptions;
+DROP TABLE IF EXISTS catalog_item_branches;
+DROP TABLE IF EXISTS catalog_items;
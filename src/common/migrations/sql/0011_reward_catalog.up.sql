@@ -0,0 +1,55 @@
+-- Merchants publish catalogs of items their users redeem rewards for. Stock is
+-- decremented on redemption and never goes below zero.
+CREATE TABLE catalog_items (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    merchant_id BIGINT NOT NULL,
+    name        TEXT NOT NULL,
+    description TEXT,
+    reward_type TEXT NOT NULL,
+    cost        DECIMAL NOT NULL,
+    stock       BIGINT,
+    valid_from  TIMESTAMPTZ,
+    valid_until TIMESTAMPTZ,
+    CONSTRAINT fk_catalog_items_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT chk_catalog_items_stock CHECK (stock IS NULL OR stock >= 0)
+);
+CREATE INDEX idx_catalog_items_deleted_at ON catalog_items (deleted_at);
+CREATE INDEX idx_catalog_items_merchant_id ON catalog_items (merchant_id);
+
+CREATE TABLE catalog_item_branches (
+    catalog_item_id BIGINT NOT NULL,
+    branch_id       BIGINT NOT NULL,
+    PRIMARY KEY (catalog_item_id, branch_id),
+    CONSTRAINT fk_catalog_item_branches_item FOREIGN KEY (catalog_item_id) REFERENCES catalog_items (id) ON DELETE CASCADE,
+    CONSTRAINT fk_catalog_item_branches_branch FOREIGN KEY (branch_id) REFERENCES branches (id)
+);
+
+CREATE TABLE item_redemptions (
+    id              BIGSERIAL PRIMARY KEY,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    deleted_at      TIMESTAMPTZ,
+    catalog_item_id BIGINT NOT NULL,
+    user_id         BIGINT NOT NULL,
+    merchant_id     BIGINT NOT NULL,
+    branch_id       BIGINT NOT NULL,
+    quantity        BIGINT NOT NULL,
+    reward_type     TEXT NOT NULL,
+    cost            DECIMAL NOT NULL,
+    status          TEXT NOT NULL,
+    fulfilled_at    TIMESTAMPTZ,
+    cancelled_at    TIMESTAMPTZ,
+    cancel_reason   TEXT,
+    CONSTRAINT fk_item_redemptions_item FOREIGN KEY (catalog_item_id) REFERENCES catalog_items (id),
+    CONSTRAINT fk_item_redemptions_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_item_redemptions_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_item_redemptions_branch FOREIGN KEY (branch_id, merchant_id) REFERENCES branches (id, merchant_id)
+);
+CREATE INDEX idx_item_redemptions_deleted_at ON item_redemptions (deleted_at);
+CREATE INDEX idx_item_redemptions_catalog_item_id ON item_redemptions (catalog_item_id);
+CREATE INDEX idx_item_redemptions_user_id ON item_redemptions (user_id);
+CREATE INDEX idx_item_redemptions_merchant_id ON item_redemptions (merchant_id);
+CREATE INDEX idx_item_redemptions_status ON item_redemptions (status);
@@ -0,0 +1,25 @@
+-- Each catalog redemption gets a voucher code to be shown at a branch. The
+-- partial index serves the job that expires the issued vouchers.
+CREATE TABLE vouchers (
+    id                 BIGSERIAL PRIMARY KEY,
+    created_at         TIMESTAMPTZ,
+    updated_at         TIMESTAMPTZ,
+    deleted_at         TIMESTAMPTZ,
+    code               TEXT NOT NULL,
+    item_redemption_id BIGINT NOT NULL,
+    user_id            BIGINT NOT NULL,
+    merchant_id        BIGINT NOT NULL,
+    status             TEXT NOT NULL,
+    expires_at         TIMESTAMPTZ,
+    used_at            TIMESTAMPTZ,
+    used_branch_id     BIGINT,
+    cancelled_at       TIMESTAMPTZ,
+    CONSTRAINT fk_vouchers_item_redemption FOREIGN KEY (item_redemption_id) REFERENCES item_redemptions (id),
+    CONSTRAINT fk_vouchers_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_vouchers_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_vouchers_used_branch FOREIGN KEY (used_branch_id, merchant_id) REFERENCES branches (id, merchant_id)
+);
+CREATE INDEX idx_vouchers_deleted_at ON vouchers (deleted_at);
+CREATE UNIQUE INDEX idx_vouchers_code ON vouchers (code);
+CREATE UNIQUE INDEX idx_vouchers_item_redemption_id ON vouchers (item_redemption_id);
+CREATE INDEX idx_vouchers_issued_expires_at ON vouchers (expires_at) WHERE status = 'issued';
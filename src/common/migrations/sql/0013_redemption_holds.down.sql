@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS redemption_holds;
+ALTER TABLE balances DROP COLUMN IF EXISTS held;
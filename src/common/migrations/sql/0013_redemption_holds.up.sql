@@ -0,0 +1,28 @@
+-- Two-phase redemptions reserve part of a balance until they are captured or
+-- released. The partial index serves the job that expires the active holds.
+ALTER TABLE balances ADD COLUMN held DECIMAL NOT NULL DEFAULT 0;
+
+CREATE TABLE redemption_holds (
+    id              BIGSERIAL PRIMARY KEY,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    deleted_at      TIMESTAMPTZ,
+    user_id         BIGINT NOT NULL,
+    merchant_id     BIGINT NOT NULL,
+    branch_id       BIGINT,
+    type            TEXT NOT NULL,
+    amount          DECIMAL NOT NULL,
+    captured_amount DECIMAL,
+    status          TEXT NOT NULL,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    captured_at     TIMESTAMPTZ,
+    voided_at       TIMESTAMPTZ,
+    CONSTRAINT fk_redemption_holds_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_redemption_holds_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_redemption_holds_branch FOREIGN KEY (branch_id, merchant_id) REFERENCES branches (id, merchant_id)
+);
+CREATE INDEX idx_redemption_holds_deleted_at ON redemption_holds (deleted_at);
+CREATE INDEX idx_redemption_holds_user_id ON redemption_holds (user_id);
+CREATE INDEX idx_redemption_holds_merchant_id ON redemption_holds (merchant_id);
+CREATE INDEX idx_redemption_holds_branch_id ON redemption_holds (branch_id);
+CREATE INDEX idx_redemption_holds_held_expires_at ON redemption_holds (expires_at) WHERE status = 'held';
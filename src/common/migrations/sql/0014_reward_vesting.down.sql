@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_rewards_transaction_id;
+DROP INDEX IF EXISTS idx_rewards_pending_vests_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversed_at;
+ALTER TABLE balances DROP COLUMN IF EXISTS pending;
+ALTER TABLE rewards DROP COLUMN IF EXISTS vests_at;
+ALTER TABLE merchants DROP COLUMN IF EXISTS reward_vesting_days;
@@ -0,0 +1,9 @@
+-- Rewards earned by transactions can stay pending for a return window before
+-- they vest; a reversed transaction revokes the rewards still pending. The
+-- partial index serves the job that vests the pending rewards.
+ALTER TABLE merchants ADD COLUMN reward_vesting_days BIGINT;
+ALTER TABLE rewards ADD COLUMN vests_at TIMESTAMPTZ;
+ALTER TABLE balances ADD COLUMN pending DECIMAL NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN reversed_at TIMESTAMPTZ;
+CREATE INDEX idx_rewards_pending_vests_at ON rewards (vests_at) WHERE vests_at IS NOT NULL;
+CREATE INDEX idx_rewards_transaction_id ON rewards (transaction_id);
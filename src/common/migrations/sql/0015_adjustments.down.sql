@@ -0,0 +1,4 @@
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_adjustment;
+ALTER TABLE rewards DROP COLUMN IF EXISTS adjustment_id;
+DROP TABLE IF EXISTS adjustment_audit_entries;
+DROP TABLE IF EXISTS adjustments;
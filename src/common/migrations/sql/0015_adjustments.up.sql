@@ -0,0 +1,48 @@
+-- Manual balance adjustments made by support, with an append-only audit trail
+-- of who requested, approved, rejected and applied them.
+CREATE TABLE adjustments (
+    id                  BIGSERIAL PRIMARY KEY,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ,
+    deleted_at          TIMESTAMPTZ,
+    user_id             BIGINT NOT NULL,
+    merchant_id         BIGINT NOT NULL,
+    direction           TEXT NOT NULL,
+    reward_type         TEXT NOT NULL,
+    amount              DECIMAL NOT NULL,
+    reason_code         TEXT NOT NULL,
+    comment             TEXT,
+    status              TEXT NOT NULL,
+    requested_by_key_id BIGINT,
+    requested_by_role   TEXT NOT NULL,
+    reviewed_by_key_id  BIGINT,
+    reviewed_by_role    TEXT,
+    review_comment      TEXT,
+    reviewed_at         TIMESTAMPTZ,
+    applied_at          TIMESTAMPTZ,
+    CONSTRAINT fk_adjustments_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_adjustments_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_adjustments_requested_by FOREIGN KEY (requested_by_key_id) REFERENCES api_keys (id),
+    CONSTRAINT fk_adjustments_reviewed_by FOREIGN KEY (reviewed_by_key_id) REFERENCES api_keys (id),
+    CONSTRAINT chk_adjustments_amount CHECK (amount > 0)
+);
+CREATE INDEX idx_adjustments_deleted_at ON adjustments (deleted_at);
+CREATE INDEX idx_adjustments_user_id ON adjustments (user_id);
+CREATE INDEX idx_adjustments_merchant_id ON adjustments (merchant_id);
+CREATE INDEX idx_adjustments_status ON adjustments (status);
+
+CREATE TABLE adjustment_audit_entries (
+    id            BIGSERIAL PRIMARY KEY,
+    created_at    TIMESTAMPTZ,
+    adjustment_id BIGINT NOT NULL,
+    action        TEXT NOT NULL,
+    key_id        BIGINT,
+    role          TEXT NOT NULL,
+    comment       TEXT,
+    CONSTRAINT fk_adjustment_audit_entries_adjustment FOREIGN KEY (adjustment_id) REFERENCES adjustments (id),
+    CONSTRAINT fk_adjustment_audit_entries_key FOREIGN KEY (key_id) REFERENCES api_keys (id)
+);
+CREATE INDEX idx_adjustment_audit_entries_adjustment_id ON adjustment_audit_entries (adjustment_id);
+
+ALTER TABLE rewards ADD COLUMN adjustment_id BIGINT;
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_adjustment FOREIGN KEY (adjustment_id) REFERENCES adjustments (id);
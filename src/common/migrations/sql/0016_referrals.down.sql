@@ -0,0 +1,12 @@
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_referral;
+ALTER TABLE rewards DROP COLUMN IF EXISTS referral_id;
+DROP TABLE IF EXISTS referrals;
+ALTER TABLE merchants DROP COLUMN IF EXISTS referral_min_amount;
+ALTER TABLE merchants DROP COLUMN IF EXISTS referee_bonus;
+ALTER TABLE merchants DROP COLUMN IF EXISTS referrer_bonus;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_referred_by;
+DROP INDEX IF EXISTS idx_users_referred_by_user_id;
+DROP INDEX IF EXISTS idx_users_referral_code;
+ALTER TABLE users DROP COLUMN IF EXISTS referred_at;
+ALTER TABLE users DROP COLUMN IF EXISTS referred_by_user_id;
+ALTER TABLE users DROP COLUMN IF EXISTS referral_code;
@@ -0,0 +1,39 @@
+-- Referral program: users share a referral code, the referrals record who
+-- brought whom to which merchant, and the merchant configures the bonuses of
+-- the referrer and the referee.
+ALTER TABLE users ADD COLUMN referral_code TEXT;
+ALTER TABLE users ADD COLUMN referred_by_user_id BIGINT;
+ALTER TABLE users ADD COLUMN referred_at TIMESTAMPTZ;
+ALTER TABLE users ADD CONSTRAINT fk_users_referred_by FOREIGN KEY (referred_by_user_id) REFERENCES users (id);
+CREATE UNIQUE INDEX idx_users_referral_code ON users (referral_code);
+CREATE INDEX idx_users_referred_by_user_id ON users (referred_by_user_id);
+
+ALTER TABLE merchants ADD COLUMN referrer_bonus DECIMAL;
+ALTER TABLE merchants ADD COLUMN referee_bonus DECIMAL;
+ALTER TABLE merchants ADD COLUMN referral_min_amount DECIMAL;
+
+CREATE TABLE referrals (
+    id             BIGSERIAL PRIMARY KEY,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    deleted_at     TIMESTAMPTZ,
+    referrer_id    BIGINT NOT NULL,
+    referee_id     BIGINT NOT NULL,
+    merchant_id    BIGINT NOT NULL,
+    code           TEXT NOT NULL,
+    status         TEXT NOT NULL,
+    transaction_id BIGINT,
+    qualified_at   TIMESTAMPTZ,
+    CONSTRAINT fk_referrals_referrer FOREIGN KEY (referrer_id) REFERENCES users (id),
+    CONSTRAINT fk_referrals_referee FOREIGN KEY (referee_id) REFERENCES users (id),
+    CONSTRAINT fk_referrals_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_referrals_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+    CONSTRAINT chk_referrals_self CHECK (referrer_id <> referee_id)
+);
+CREATE INDEX idx_referrals_deleted_at ON referrals (deleted_at);
+CREATE UNIQUE INDEX idx_referrals_referee_id ON referrals (referee_id);
+CREATE INDEX idx_referrals_referrer_id ON referrals (referrer_id, created_at);
+CREATE INDEX idx_referrals_merchant_id ON referrals (merchant_id);
+
+ALTER TABLE rewards ADD COLUMN referral_id BIGINT;
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_referral FOREIGN KEY (referral_id) REFERENCES referrals (id);
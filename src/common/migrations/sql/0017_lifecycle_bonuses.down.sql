@@ -0,0 +1,8 @@
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_lifecycle_bonus;
+ALTER TABLE rewards DROP COLUMN IF EXISTS lifecycle_bonus_id;
+DROP TABLE IF EXISTS lifecycle_bonuses;
+ALTER TABLE merchants DROP COLUMN IF EXISTS bonus_window_days;
+ALTER TABLE merchants DROP COLUMN IF EXISTS anniversary_bonus;
+ALTER TABLE merchants DROP COLUMN IF EXISTS birthday_bonus;
+ALTER TABLE merchants DROP COLUMN IF EXISTS enrollment_bonus;
+ALTER TABLE users DROP COLUMN IF EXISTS birth_date;
@@ -0,0 +1,27 @@
+-- Lifecycle bonuses: merchants grant bonuses when a member enrolls, on their
+-- birthday and on the anniversaries of their enrollment. The unique index
+-- makes the daily job that issues them safe to run again.
+ALTER TABLE users ADD COLUMN birth_date DATE;
+
+ALTER TABLE merchants ADD COLUMN enrollment_bonus DECIMAL;
+ALTER TABLE merchants ADD COLUMN birthday_bonus DECIMAL;
+ALTER TABLE merchants ADD COLUMN anniversary_bonus DECIMAL;
+ALTER TABLE merchants ADD COLUMN bonus_window_days BIGINT;
+
+CREATE TABLE lifecycle_bonuses (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    user_id     BIGINT NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    kind        TEXT NOT NULL,
+    year        BIGINT NOT NULL,
+    occurs_on   DATE NOT NULL,
+    reward_type TEXT NOT NULL,
+    amount      DECIMAL NOT NULL,
+    CONSTRAINT fk_lifecycle_bonuses_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_lifecycle_bonuses_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+CREATE UNIQUE INDEX idx_lifecycle_bonuses_occasion ON lifecycle_bonuses (user_id, merchant_id, kind, year);
+
+ALTER TABLE rewards ADD COLUMN lifecycle_bonus_id BIGINT;
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_lifecycle_bonus FOREIGN KEY (lifecycle_bonus_id) REFERENCES lifecycle_bonuses (id);
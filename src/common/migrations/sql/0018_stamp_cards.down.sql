@@ -0,0 +1,8 @@
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_stamp_card;
+ALTER TABLE rewards DROP COLUMN IF EXISTS stamp_card_id;
+DROP TABLE IF EXISTS stamps;
+DROP TABLE IF EXISTS stamp_cards;
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS fk_campaigns_catalog_item;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS catalog_item_id;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS stamps_required;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS kind;
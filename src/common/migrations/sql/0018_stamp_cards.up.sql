@@ -0,0 +1,51 @@
+-- Stamp-card campaigns: each qualifying transaction adds a stamp to the
+-- user's open card of the campaign, and a full card issues a reward or a
+-- voucher for a catalog item. The partial unique index keeps one open card
+-- per user and campaign, and a transaction stamps a campaign once.
+ALTER TABLE campaigns ADD COLUMN kind TEXT NOT NULL DEFAULT 'multiplier';
+ALTER TABLE campaigns ADD COLUMN stamps_required BIGINT;
+ALTER TABLE campaigns ADD COLUMN catalog_item_id BIGINT;
+ALTER TABLE campaigns ADD CONSTRAINT fk_campaigns_catalog_item FOREIGN KEY (catalog_item_id) REFERENCES catalog_items (id);
+
+CREATE TABLE stamp_cards (
+    id                  BIGSERIAL PRIMARY KEY,
+    created_at          TIMESTAMPTZ,
+    updated_at          TIMESTAMPTZ,
+    deleted_at          TIMESTAMPTZ,
+    campaign_id         BIGINT NOT NULL,
+    user_id             BIGINT NOT NULL,
+    merchant_id         BIGINT NOT NULL,
+    stamps_required     BIGINT NOT NULL,
+    stamps              BIGINT NOT NULL,
+    status              TEXT NOT NULL,
+    completed_at        TIMESTAMPTZ,
+    reward_id           BIGINT,
+    item_redemption_id  BIGINT,
+    CONSTRAINT fk_stamp_cards_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns (id),
+    CONSTRAINT fk_stamp_cards_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_stamp_cards_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_stamp_cards_reward FOREIGN KEY (reward_id) REFERENCES rewards (id),
+    CONSTRAINT fk_stamp_cards_item_redemption FOREIGN KEY (item_redemption_id) REFERENCES item_redemptions (id)
+);
+CREATE INDEX idx_stamp_cards_deleted_at ON stamp_cards (deleted_at);
+CREATE INDEX idx_stamp_cards_campaign_id ON stamp_cards (campaign_id);
+CREATE INDEX idx_stamp_cards_user_id ON stamp_cards (user_id);
+CREATE UNIQUE INDEX idx_stamp_cards_open ON stamp_cards (campaign_id, user_id) WHERE status = 'open' AND deleted_at IS NULL;
+
+CREATE TABLE stamps (
+    id             BIGSERIAL PRIMARY KEY,
+    created_at     TIMESTAMPTZ,
+    stamp_card_id  BIGINT NOT NULL,
+    campaign_id    BIGINT NOT NULL,
+    transaction_id BIGINT NOT NULL,
+    revoked_at     TIMESTAMPTZ,
+    CONSTRAINT fk_stamps_stamp_card FOREIGN KEY (stamp_card_id) REFERENCES stamp_cards (id),
+    CONSTRAINT fk_stamps_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns (id),
+    CONSTRAINT fk_stamps_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+CREATE INDEX idx_stamps_stamp_card_id ON stamps (stamp_card_id);
+CREATE UNIQUE INDEX idx_stamps_transaction ON stamps (campaign_id, transaction_id);
+CREATE INDEX idx_stamps_transaction_id ON stamps (transaction_id);
+
+ALTER TABLE rewards ADD COLUMN stamp_card_id BIGINT;
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_stamp_card FOREIGN KEY (stamp_card_id) REFERENCES stamp_cards (id);
@@ -0,0 +1,5 @@
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_threshold_award;
+ALTER TABLE rewards DROP COLUMN IF EXISTS threshold_award_id;
+DROP TABLE IF EXISTS threshold_awards;
+DROP INDEX IF EXISTS idx_transactions_user_merchant_date;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS spend_threshold;
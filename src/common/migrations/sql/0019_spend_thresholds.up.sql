@@ -0,0 +1,32 @@
+-- Spend-threshold campaigns: the bonus is awarded once per user when their
+-- qualifying spend during the campaign reaches the threshold. The partial
+-- unique index keeps a single award in force per user and campaign.
+ALTER TABLE campaigns ADD COLUMN spend_threshold DECIMAL;
+
+-- The progress of a user sums their transactions at the merchant during the
+-- campaign.
+CREATE INDEX idx_transactions_user_merchant_date ON transactions (user_id, merchant_id, date) WHERE deleted_at IS NULL;
+
+CREATE TABLE threshold_awards (
+    id             BIGSERIAL PRIMARY KEY,
+    created_at     TIMESTAMPTZ,
+    campaign_id    BIGINT NOT NULL,
+    user_id        BIGINT NOT NULL,
+    merchant_id    BIGINT NOT NULL,
+    transaction_id BIGINT NOT NULL,
+    spent          DECIMAL NOT NULL,
+    reward_id      BIGINT,
+    revoked_at     TIMESTAMPTZ,
+    CONSTRAINT fk_threshold_awards_campaign FOREIGN KEY (campaign_id) REFERENCES campaigns (id),
+    CONSTRAINT fk_threshold_awards_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_threshold_awards_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_threshold_awards_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+    CONSTRAINT fk_threshold_awards_reward FOREIGN KEY (reward_id) REFERENCES rewards (id)
+);
+CREATE INDEX idx_threshold_awards_campaign_id ON threshold_awards (campaign_id);
+CREATE INDEX idx_threshold_awards_user_id ON threshold_awards (user_id);
+CREATE INDEX idx_threshold_awards_transaction_id ON threshold_awards (transaction_id);
+CREATE UNIQUE INDEX idx_threshold_awards_in_force ON threshold_awards (campaign_id, user_id) WHERE revoked_at IS NULL;
+
+ALTER TABLE rewards ADD COLUMN threshold_award_id BIGINT;
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_threshold_award FOREIGN KEY (threshold_award_id) REFERENCES threshold_awards (id);
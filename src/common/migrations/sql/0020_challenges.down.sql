@@ -0,0 +1,5 @@
+ALTER TABLE rewards DROP CONSTRAINT IF EXISTS fk_rewards_challenge;
+ALTER TABLE rewards DROP COLUMN IF EXISTS challenge_id;
+DROP TABLE IF EXISTS challenge_progresses;
+DROP TABLE IF EXISTS badges;
+DROP TABLE IF EXISTS challenges;
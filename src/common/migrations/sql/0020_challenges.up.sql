@@ -0,0 +1,66 @@
+-- Challenges: missions of a merchant evaluated on each transaction of its
+-- users, which award a badge and optionally a reward once per user. The
+-- unique indexes keep one progress and one badge per user and challenge.
+CREATE TABLE challenges (
+    id              BIGSERIAL PRIMARY KEY,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    deleted_at      TIMESTAMPTZ,
+    merchant_id     BIGINT NOT NULL,
+    name            TEXT NOT NULL,
+    description     TEXT,
+    goal            TEXT NOT NULL,
+    target          BIGINT NOT NULL,
+    window_days     BIGINT,
+    min_amount      DECIMAL,
+    start_date      TIMESTAMPTZ NOT NULL,
+    end_date        TIMESTAMPTZ,
+    badge_name      TEXT NOT NULL,
+    badge_image_url TEXT,
+    reward_type     TEXT,
+    reward_amount   DECIMAL,
+    CONSTRAINT fk_challenges_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+CREATE INDEX idx_challenges_deleted_at ON challenges (deleted_at);
+CREATE INDEX idx_challenges_merchant_id ON challenges (merchant_id);
+
+CREATE TABLE badges (
+    id           BIGSERIAL PRIMARY KEY,
+    created_at   TIMESTAMPTZ,
+    challenge_id BIGINT NOT NULL,
+    user_id      BIGINT NOT NULL,
+    merchant_id  BIGINT NOT NULL,
+    name         TEXT NOT NULL,
+    image_url    TEXT,
+    CONSTRAINT fk_badges_challenge FOREIGN KEY (challenge_id) REFERENCES challenges (id),
+    CONSTRAINT fk_badges_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_badges_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id)
+);
+CREATE INDEX idx_badges_user_id ON badges (user_id);
+CREATE UNIQUE INDEX idx_badges_challenge_user ON badges (challenge_id, user_id);
+
+CREATE TABLE challenge_progresses (
+    id             BIGSERIAL PRIMARY KEY,
+    created_at     TIMESTAMPTZ,
+    updated_at     TIMESTAMPTZ,
+    challenge_id   BIGINT NOT NULL,
+    user_id        BIGINT NOT NULL,
+    merchant_id    BIGINT NOT NULL,
+    progress       BIGINT NOT NULL,
+    status         TEXT NOT NULL,
+    completed_at   TIMESTAMPTZ,
+    transaction_id BIGINT,
+    badge_id       BIGINT,
+    reward_id      BIGINT,
+    CONSTRAINT fk_challenge_progresses_challenge FOREIGN KEY (challenge_id) REFERENCES challenges (id),
+    CONSTRAINT fk_challenge_progresses_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_challenge_progresses_merchant FOREIGN KEY (merchant_id) REFERENCES merchants (id),
+    CONSTRAINT fk_challenge_progresses_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id),
+    CONSTRAINT fk_challenge_progresses_badge FOREIGN KEY (badge_id) REFERENCES badges (id),
+    CONSTRAINT fk_challenge_progresses_reward FOREIGN KEY (reward_id) REFERENCES rewards (id)
+);
+CREATE INDEX idx_challenge_progresses_user_id ON challenge_progresses (user_id);
+CREATE UNIQUE INDEX idx_challenge_progresses_challenge_user ON challenge_progresses (challenge_id, user_id);
+
+ALTER TABLE rewards ADD COLUMN challenge_id BIGINT;
+ALTER TABLE rewards ADD CONSTRAINT fk_rewards_challenge FOREIGN KEY (challenge_id) REFERENCES challenges (id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events (id) WHERE published_at IS NULL;
+DROP INDEX IF EXISTS idx_outbox_events_partition_sequence;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS dead_at;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS sequence;
+DROP TABLE IF EXISTS outbox_partitions;
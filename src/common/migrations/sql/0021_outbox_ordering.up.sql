@@ -0,0 +1,31 @@
+-- Events are ordered within their partition by a sequence taken from
+-- outbox_partitions. The row stays locked until the writing transaction ends,
+-- so a partition's sequence follows the order in which its events are
+-- committed, which the id of the events does not.
+CREATE TABLE outbox_partitions (
+    partition_key TEXT PRIMARY KEY,
+    last_sequence BIGINT NOT NULL
+);
+
+ALTER TABLE outbox_events ADD COLUMN sequence BIGINT;
+ALTER TABLE outbox_events ADD COLUMN dead_at TIMESTAMPTZ;
+
+UPDATE outbox_events e
+SET sequence = numbered.sequence
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY partition_key ORDER BY id) AS sequence
+    FROM outbox_events
+) numbered
+WHERE numbered.id = e.id;
+
+INSERT INTO outbox_partitions (partition_key, last_sequence)
+SELECT partition_key, MAX(sequence) FROM outbox_events GROUP BY partition_key;
+
+ALTER TABLE outbox_events ALTER COLUMN sequence SET NOT NULL;
+CREATE UNIQUE INDEX idx_outbox_events_partition_sequence ON outbox_events (partition_key, sequence);
+
+-- The relay only scans the events that are still pending; dead events ran out
+-- of attempts and are left for an operator.
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events (partition_key, sequence)
+    WHERE published_at IS NULL AND dead_at IS NULL;
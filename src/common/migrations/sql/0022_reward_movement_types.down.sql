@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_events_reward_movements;
+CREATE INDEX idx_outbox_events_reward_movements ON outbox_events (merchant_id, occurred_at) WHERE type IN ('reward.granted', 'reward.redeemed');
@@ -0,0 +1,5 @@
+-- Merchant analytics read every event that issues or spends rewards, not only
+-- grants and redemptions.
+DROP INDEX IF EXISTS idx_outbox_events_reward_movements;
+CREATE INDEX idx_outbox_events_reward_movements ON outbox_events (merchant_id, occurred_at)
+    WHERE type IN ('reward.granted', 'reward.credited', 'reward.refunded', 'reward.redeemed', 'reward.debited');
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKey struct {
+	gorm.Model
+	MerchantID uint     `gorm:"not null;index"`
+	Merchant   Merchant `gorm:"foreignKey:MerchantID"`
+	BranchID   *uint    `gorm:"index"`
+	Branch     *Branch  `gorm:"foreignKey:BranchID"`
+	Name       string   `gorm:"not null"`
+	Role       string   `gorm:"not null;default:merchant_admin"`
+	Prefix     string   `gorm:"not null;uniqueIndex"`
+	KeyHash    string   `gorm:"not null"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
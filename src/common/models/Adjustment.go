@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Adjustment directions.
+const (
+	AdjustmentCredit = "credit"
+	AdjustmentDebit  = "debit"
+)
+
+// Adjustment statuses. An adjustment above the approval threshold stays
+// pending until a second operator approves or rejects it; the others are
+// applied when they are requested.
+const (
+	AdjustmentPending  = "pending"
+	AdjustmentApplied  = "applied"
+	AdjustmentRejected = "rejected"
+)
+
+// Adjustment reason codes.
+const (
+	AdjustmentReasonGoodwill     = "goodwill"
+	AdjustmentReasonServiceIssue = "service_issue"
+	AdjustmentReasonCorrection   = "correction"
+	AdjustmentReasonMigration    = "migration"
+	AdjustmentReasonFraud        = "fraud"
+	AdjustmentReasonOther        = "other"
+)
+
+// Adjustment is a manual credit or debit of a user's balance made by support.
+// Operators are identified by their API key; a nil key is the platform admin
+// token. A credit is applied as a new reward and a debit consumes the user's
+// available rewards.
+type Adjustment struct {
+	gorm.Model
+	UserID           uint   `gorm:"not null;index"`
+	MerchantID       uint   `gorm:"not null;index"`
+	Direction        string `gorm:"not null"`
+	RewardType       string `gorm:"not null"`
+	Amount           float64
+	ReasonCode       string `gorm:"not null"`
+	Comment          string
+	Status           string `gorm:"not null;index"`
+	RequestedByKeyID *uint
+	RequestedByRole  string `gorm:"not null"`
+	ReviewedByKeyID  *uint
+	ReviewedByRole   string
+	ReviewComment    string
+	ReviewedAt       *time.Time
+	AppliedAt        *time.Time
+	AuditEntries     []AdjustmentAuditEntry
+}
+
+// Adjustment audit actions.
+const (
+	AdjustmentAuditRequested = "requested"
+	AdjustmentAuditApproved  = "approved"
+	AdjustmentAuditRejected  = "rejected"
+	AdjustmentAuditApplied   = "applied"
+)
+
+// AdjustmentAuditEntry records who did what to an adjustment and when. The
+// entries are only ever appended.
+type AdjustmentAuditEntry struct {
+	ID           uint `gorm:"primarykey"`
+	CreatedAt    time.Time
+	AdjustmentID uint   `gorm:"not null;index"`
+	Action       string `gorm:"not null"`
+	KeyID        *uint
+	Role         string `gorm:"not null"`
+	Comment      string
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Balance is the running total of a user's rewards of one type at a merchant.
+// It is kept in sync with the rewards ledger and can be rebuilt from it.
+// Pending is the part of Amount that has not vested yet and Held the part
+// reserved by redemption holds, so the available balance is Amount minus both.
+type Balance struct {
+	gorm.Model
+	UserID     uint    `gorm:"not null;uniqueIndex:idx_balances_user_merchant_type"`
+	MerchantID uint    `gorm:"not null;uniqueIndex:idx_balances_user_merchant_type"`
+	Type       string  `gorm:"not null;uniqueIndex:idx_balances_user_merchant_type"`
+	Amount     float64 `gorm:"not null;default:0"`
+	Pending    float64 `gorm:"not null;default:0"`
+	Held       float64 `gorm:"not null;default:0"`
+}
@@ -6,6 +6,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// Campaign kinds. A multiplier campaign multiplies the base reward of each
+// qualifying transaction by Value; a stamp-card campaign gives a stamp per
+// qualifying transaction instead, and a reward once a card is full; a
+// spend-threshold campaign awards Value once, when the qualifying spend of the
+// user during the campaign reaches SpendThreshold.
+const (
+	CampaignMultiplier     = "multiplier"
+	CampaignStampCard      = "stamp_card"
+	CampaignSpendThreshold = "spend_threshold"
+)
+
 type Campaign struct {
 	gorm.Model
 	MerchantID uint      `gorm:"not null"`
@@ -14,7 +25,13 @@ type Campaign struct {
 	Branch     Branch    `gorm:"foreignKey:BranchID"`
 	StartDate  time.Time `gorm:"not null"`
 	EndDate    *time.Time
+	Kind       string  `gorm:"not null;default:multiplier"`
 	Type       string  `gorm:"not null"`
 	Value      float64 `gorm:"not null"`
 	MinAmount  *float64
+	// StampsRequired is the size of the cards of a stamp-card campaign. A full
+	// card rewards Value in Type or, with CatalogItemID, a voucher for the item.
+	StampsRequired *int
+	CatalogItemID  *uint
+	SpendThreshold *float64
 }
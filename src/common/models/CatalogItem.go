@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CatalogItem is something the users of a merchant can redeem their rewards
+// for, at a cost in points or cashback. A nil Stock is unlimited, and an item
+// without branches is available in all the branches of the merchant.
+type CatalogItem struct {
+	gorm.Model
+	MerchantID  uint     `gorm:"not null;index"`
+	Merchant    Merchant `gorm:"foreignKey:MerchantID"`
+	Name        string   `gorm:"not null"`
+	Description string
+	RewardType  string  `gorm:"not null"`
+	Cost        float64 `gorm:"not null"`
+	Stock       *int
+	ValidFrom   *time.Time
+	ValidUntil  *time.Time
+	Branches    []CatalogItemBranch `gorm:"foreignKey:CatalogItemID"`
+}
+
+// CatalogItemBranch makes a catalog item available in a branch.
+type CatalogItemBranch struct {
+	CatalogItemID uint `gorm:"primaryKey"`
+	BranchID      uint `gorm:"primaryKey"`
+}
+
+// Item redemption statuses. A pending redemption has been paid for and waits
+// for the branch to hand the item over; a cancelled one was refunded.
+const (
+	ItemRedemptionPending   = "pending"
+	ItemRedemptionFulfilled = "fulfilled"
+	ItemRedemptionCancelled = "cancelled"
+)
+
+// ItemRedemption records the redemption of Quantity units of a catalog item
+// at a branch, for Cost in total.
+type ItemRedemption struct {
+	gorm.Model
+	CatalogItemID uint        `gorm:"not null;index"`
+	CatalogItem   CatalogItem `gorm:"foreignKey:CatalogItemID"`
+	UserID        uint        `gorm:"not null;index"`
+	MerchantID    uint        `gorm:"not null;index"`
+	BranchID      uint        `gorm:"not null"`
+	Quantity      int         `gorm:"not null"`
+	RewardType    string      `gorm:"not null"`
+	Cost          float64     `gorm:"not null"`
+	Status        string      `gorm:"not null;index"`
+	FulfilledAt   *time.Time
+	CancelledAt   *time.Time
+	CancelReason  string
+	Voucher       *Voucher `gorm:"foreignKey:ItemRedemptionID"`
+}
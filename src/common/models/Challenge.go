@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Challenge goals. A distinct-branches challenge is completed by buying at
+// Target different branches, within WindowDays when set; a weekly-streak one
+// by buying in Target consecutive weeks, and a transactions one by Target
+// purchases.
+const (
+	ChallengeDistinctBranches = "distinct_branches"
+	ChallengeWeeklyStreak     = "weekly_streak"
+	ChallengeTransactions     = "transactions"
+)
+
+// Challenge is a mission for the users of a merchant, evaluated on each of
+// their transactions between StartDate and EndDate that reach MinAmount.
+// Completing it awards its badge once per user, and RewardAmount in
+// RewardType when the challenge has a reward.
+type Challenge struct {
+	gorm.Model
+	MerchantID    uint   `gorm:"not null;index"`
+	Name          string `gorm:"not null"`
+	Description   string
+	Goal          string `gorm:"not null"`
+	Target        int    `gorm:"not null"`
+	WindowDays    *int
+	MinAmount     *float64
+	StartDate     time.Time `gorm:"not null"`
+	EndDate       *time.Time
+	BadgeName     string `gorm:"not null"`
+	BadgeImageURL string
+	RewardType    *string
+	RewardAmount  *float64
+}
+
+// Challenge progress statuses.
+const (
+	ChallengeInProgress = "in_progress"
+	ChallengeCompleted  = "completed"
+)
+
+// ChallengeProgress is where a user stands in a challenge as of their last
+// transaction evaluated. Progress is recounted from their transactions on
+// each evaluation, so reversed transactions stop counting; a completed
+// challenge stays completed.
+type ChallengeProgress struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ChallengeID uint   `gorm:"not null;index"`
+	UserID      uint   `gorm:"not null;index"`
+	MerchantID  uint   `gorm:"not null"`
+	Progress    int    `gorm:"not null"`
+	Status      string `gorm:"not null"`
+	CompletedAt *time.Time
+	// TransactionID is the transaction that completed the challenge.
+	TransactionID *uint
+	BadgeID       *uint
+	Badge         *Badge `gorm:"foreignKey:BadgeID"`
+	RewardID      *uint
+}
+
+// Badge is awarded to a user for completing a challenge, with the name and
+// image the challenge had at the time.
+type Badge struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	ChallengeID uint   `gorm:"not null"`
+	UserID      uint   `gorm:"not null;index"`
+	MerchantID  uint   `gorm:"not null"`
+	Name        string `gorm:"not null"`
+	ImageURL    string
+}
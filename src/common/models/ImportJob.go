@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Import formats.
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// Import job statuses. A job is processing from the moment a worker claims it
+// until all its rows have a result; a failed job could not be read at all.
+const (
+	ImportJobPending    = "pending"
+	ImportJobProcessing = "processing"
+	ImportJobCompleted  = "completed"
+	ImportJobFailed     = "failed"
+)
+
+// ImportJob is a file of transactions uploaded by a merchant, processed in the
+// background row by row. BranchID restricts the rows to a branch when the file
+// was uploaded by a branch operator.
+type ImportJob struct {
+	gorm.Model
+	MerchantID uint     `gorm:"not null;index"`
+	Merchant   Merchant `gorm:"foreignKey:MerchantID"`
+	BranchID   *uint
+	Format     string `gorm:"not null"`
+	FileName   string
+	Content    []byte `gorm:"not null"`
+	Status     string `gorm:"not null;index"`
+	Total      int    `gorm:"not null;default:0"`
+	Processed  int    `gorm:"not null;default:0"`
+	Succeeded  int    `gorm:"not null;default:0"`
+	Failed     int    `gorm:"not null;default:0"`
+	Duplicates int    `gorm:"not null;default:0"`
+	Error      string
+	LeaseUntil *time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+// Import row statuses.
+const (
+	ImportRowProcessed = "processed"
+	ImportRowFailed    = "failed"
+	ImportRowDuplicate = "duplicate"
+)
+
+// ImportRow is the result of one row of an import job. Line is the line of
+// the row in the file, counting the CSV header.
+type ImportRow struct {
+	ID          uint `gorm:"primarykey"`
+	ImportJobID uint `gorm:"not null;uniqueIndex:idx_import_rows_job_line"`
+	Line        int  `gorm:"not null;uniqueIndex:idx_import_rows_job_line"`
+	ExternalRef string
+	Status      string `gorm:"not null"`
+	ErrorCode   string
+	Error       string
+	Raw         string
+	CreatedAt   time.Time
+}
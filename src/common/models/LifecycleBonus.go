@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Lifecycle bonus kinds.
+const (
+	BonusEnrollment  = "enrollment"
+	BonusBirthday    = "birthday"
+	BonusAnniversary = "anniversary"
+)
+
+// LifecycleBonus records that a member received the bonus of an occasion of
+// their life in the merchant's program. Year is the year of the birthday or
+// anniversary, and 0 for the enrollment bonus, so each bonus is granted once.
+type LifecycleBonus struct {
+	ID         uint `gorm:"primarykey"`
+	CreatedAt  time.Time
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_lifecycle_bonuses_occasion"`
+	MerchantID uint      `gorm:"not null;uniqueIndex:idx_lifecycle_bonuses_occasion"`
+	Kind       string    `gorm:"not null;uniqueIndex:idx_lifecycle_bonuses_occasion"`
+	Year       int       `gorm:"not null;uniqueIndex:idx_lifecycle_bonuses_occasion"`
+	OccursOn   time.Time `gorm:"type:date;not null"`
+	RewardType string    `gorm:"not null"`
+	Amount     float64   `gorm:"not null"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Membership records that a user is enrolled in a merchant's loyalty program.
+type Membership struct {
+	gorm.Model
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_memberships_user_merchant"`
+	User       User      `gorm:"foreignKey:UserID"`
+	MerchantID uint      `gorm:"not null;uniqueIndex:idx_memberships_user_merchant;index"`
+	Merchant   Merchant  `gorm:"foreignKey:MerchantID"`
+	EnrolledAt time.Time `gorm:"not null"`
+}
@@ -9,6 +9,27 @@ type Merchant struct {
 	Name              string
 	ConversionFactor  float64
 	DefaultRewardType string
-	Branches          []Branch
-	Campaigns         []Campaign
+	// RewardValidityDays is how long granted rewards can be redeemed; nil means they never expire.
+	RewardValidityDays *int
+	// RewardVestingDays keeps the rewards earned by transactions pending for
+	// that many days, so that they can be reversed on a return; nil means
+	// they are available at once.
+	RewardVestingDays *int
+	// ReferrerBonus and RefereeBonus are granted, in the default reward type,
+	// when a referred user makes their first transaction of at least
+	// ReferralMinAmount; the merchant accepts referrals when either is set.
+	ReferrerBonus     *float64
+	RefereeBonus      *float64
+	ReferralMinAmount *float64
+	// EnrollmentBonus, BirthdayBonus and AnniversaryBonus are granted, in the
+	// default reward type, by the daily lifecycle bonuses job when a member
+	// enrolls, on their birthday and on each anniversary of their enrollment.
+	// BonusWindowDays is how many days after the date the bonus can still be
+	// granted; nil uses the server default.
+	EnrollmentBonus  *float64
+	BirthdayBonus    *float64
+	AnniversaryBonus *float64
+	BonusWindowDays  *int
+	Branches         []Branch
+	Campaigns        []Campaign
 }
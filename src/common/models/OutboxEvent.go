@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxEvent is a domain event waiting to be published. It is written in the
+// same database transaction as the change it describes and marked as
+// published by the relay once a sink accepted it. Sequence orders the events
+// of a partition as they were committed; DeadAt is set when the relay gave up
+// on the event after running out of attempts.
+type OutboxEvent struct {
+	ID            uint   `gorm:"primarykey"`
+	EventID       string `gorm:"not null;uniqueIndex"`
+	Type          string `gorm:"not null"`
+	AggregateType string `gorm:"not null"`
+	AggregateID   uint   `gorm:"not null"`
+	PartitionKey  string `gorm:"not null;index"`
+	Sequence      int64  `gorm:"not null"`
+	UserID        *uint
+	MerchantID    *uint
+	Payload       string    `gorm:"type:jsonb;not null"`
+	OccurredAt    time.Time `gorm:"not null"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null"`
+	LastError     string
+	PublishedAt   *time.Time
+	DeadAt        *time.Time
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Redemption hold statuses. A held amount is reserved until it is captured,
+// voided or expires.
+const (
+	RedemptionHoldHeld     = "held"
+	RedemptionHoldCaptured = "captured"
+	RedemptionHoldVoided   = "voided"
+	RedemptionHoldExpired  = "expired"
+)
+
+// RedemptionHold reserves an amount of a user's rewards for a redemption that
+// has not been completed yet, such as a checkout in progress. The amount
+// counts against the available balance but stays in the user's rewards until
+// the hold is captured.
+type RedemptionHold struct {
+	gorm.Model
+	UserID         uint   `gorm:"not null;index"`
+	MerchantID     uint   `gorm:"not null;index"`
+	BranchID       *uint  `gorm:"index"`
+	Type           string `gorm:"not null"`
+	Amount         float64
+	CapturedAmount *float64
+	Status         string    `gorm:"not null"`
+	ExpiresAt      time.Time `gorm:"not null"`
+	CapturedAt     *time.Time
+	VoidedAt       *time.Time
+}
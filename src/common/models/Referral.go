@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Referral statuses. A referral is pending until the referee's first
+// qualifying transaction at the merchant, which grants both bonuses.
+const (
+	ReferralPending   = "pending"
+	ReferralQualified = "qualified"
+)
+
+// Referral records that the referrer brought the referee to the merchant's
+// program with their referral code.
+type Referral struct {
+	gorm.Model
+	ReferrerID uint     `gorm:"not null;index"`
+	Referrer   User     `gorm:"foreignKey:ReferrerID"`
+	RefereeID  uint     `gorm:"not null;uniqueIndex"`
+	Referee    User     `gorm:"foreignKey:RefereeID"`
+	MerchantID uint     `gorm:"not null;index"`
+	Merchant   Merchant `gorm:"foreignKey:MerchantID"`
+	Code       string   `gorm:"not null"`
+	Status     string   `gorm:"not null"`
+	// TransactionID is the referee's transaction that qualified the referral.
+	TransactionID *uint
+	QualifiedAt   *time.Time
+}
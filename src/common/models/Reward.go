@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -12,4 +14,24 @@ type Reward struct {
 	Merchant   Merchant
 	Type       string
 	Amount     float64
+	ExpiryDate *time.Time `gorm:"index"`
+	// CampaignID and TransactionID attribute the reward to the campaign and
+	// the transaction that earned it; manual rewards have neither.
+	CampaignID    *uint `gorm:"index"`
+	TransactionID *uint `gorm:"index"`
+	// AdjustmentID is set on the rewards credited by a manual adjustment.
+	AdjustmentID *uint
+	// ReferralID is set on the referral bonuses of the referrer and the referee.
+	ReferralID *uint
+	// LifecycleBonusID is set on the enrollment, birthday and anniversary bonuses.
+	LifecycleBonusID *uint
+	// StampCardID is set on the reward of a completed stamp card.
+	StampCardID *uint
+	// ThresholdAwardID is set on the bonus of a spend-threshold campaign.
+	ThresholdAwardID *uint
+	// ChallengeID is set on the reward of a completed challenge.
+	ChallengeID *uint
+	// VestsAt is set while the reward is pending: it counts towards the
+	// balance but cannot be spent until the vesting job clears it.
+	VestsAt *time.Time
 }
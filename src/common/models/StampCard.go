@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Stamp card statuses. A user has at most one open card per campaign; the
+// stamp that fills it completes it, and the next stamp starts a new card.
+const (
+	StampCardOpen      = "open"
+	StampCardCompleted = "completed"
+)
+
+// StampCard collects the stamps of a user in a stamp-card campaign. The reward
+// of a completed card is either RewardID or, for catalog items, the redemption
+// that carries the voucher.
+type StampCard struct {
+	gorm.Model
+	CampaignID       uint     `gorm:"not null;index"`
+	Campaign         Campaign `gorm:"foreignKey:CampaignID"`
+	UserID           uint     `gorm:"not null;index"`
+	MerchantID       uint     `gorm:"not null"`
+	StampsRequired   int      `gorm:"not null"`
+	Stamps           int      `gorm:"not null"`
+	Status           string   `gorm:"not null"`
+	CompletedAt      *time.Time
+	RewardID         *uint
+	ItemRedemptionID *uint
+	ItemRedemption   *ItemRedemption `gorm:"foreignKey:ItemRedemptionID"`
+}
+
+// Stamp is the stamp a transaction added to a card. A transaction adds at most
+// one stamp per campaign, and reversing it sets RevokedAt.
+type Stamp struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	StampCardID   uint `gorm:"not null;index"`
+	CampaignID    uint `gorm:"not null;uniqueIndex:idx_stamps_transaction"`
+	TransactionID uint `gorm:"not null;uniqueIndex:idx_stamps_transaction"`
+	RevokedAt     *time.Time
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ThresholdAward records that the spend of a user reached the threshold of a
+// spend-threshold campaign, with the transaction that crossed it. A user gets
+// one award per campaign; reversing the transaction while its bonus is
+// pending revokes the award, and the user can reach the threshold again.
+type ThresholdAward struct {
+	ID            uint `gorm:"primarykey"`
+	CreatedAt     time.Time
+	CampaignID    uint    `gorm:"not null;index"`
+	UserID        uint    `gorm:"not null;index"`
+	MerchantID    uint    `gorm:"not null"`
+	TransactionID uint    `gorm:"not null;index"`
+	Spent         float64 `gorm:"not null"`
+	RewardID      *uint
+	RevokedAt     *time.Time
+}
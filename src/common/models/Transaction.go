@@ -8,10 +8,17 @@ import (
 
 type Transaction struct {
 	gorm.Model
-	UserID   uint
-	User     User
-	BranchID uint
-	Branch   Branch
-	Amount   float64
-	Date     time.Time
+	UserID     uint
+	User       User
+	BranchID   uint
+	Branch     Branch
+	MerchantID uint `gorm:"index"`
+	// ExternalRef is the reference of the sale in the merchant's own system;
+	// it is unique per merchant so the same sale is never processed twice.
+	ExternalRef *string
+	Amount      float64
+	Date        time.Time
+	// ReversedAt is set when the sale is returned; the rewards it earned that
+	// were still pending are revoked.
+	ReversedAt *time.Time
 }
@@ -1,12 +1,24 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
-	Name         string
-	Transactions []Transaction
-	Rewards      []Reward
+	Name string
+	// BirthDate is a calendar date, with no time of day; the birthday
+	// bonuses need it.
+	BirthDate *time.Time `gorm:"type:date"`
+	// ReferralCode is the code the user shares to refer others; it is issued
+	// on request, so users who never asked for one have none.
+	ReferralCode *string `gorm:"uniqueIndex"`
+	// ReferredByUserID and ReferredAt attribute the user to the referrer whose
+	// code brought them in. A user can be referred only once.
+	ReferredByUserID *uint `gorm:"index"`
+	ReferredAt       *time.Time
+	Transactions     []Transaction
+	Rewards          []Reward
 }
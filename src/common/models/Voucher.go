@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Voucher statuses. An issued voucher can be used once at a branch of the
+// merchant until it expires; cancelling its redemption cancels it.
+const (
+	VoucherIssued    = "issued"
+	VoucherUsed      = "used"
+	VoucherExpired   = "expired"
+	VoucherCancelled = "cancelled"
+)
+
+// Voucher is the code a user shows at a branch to collect the item of a
+// redemption.
+type Voucher struct {
+	gorm.Model
+	Code             string         `gorm:"not null;uniqueIndex"`
+	ItemRedemptionID uint           `gorm:"not null;uniqueIndex"`
+	ItemRedemption   ItemRedemption `gorm:"foreignKey:ItemRedemptionID"`
+	UserID           uint           `gorm:"not null"`
+	MerchantID       uint           `gorm:"not null"`
+	Status           string         `gorm:"not null"`
+	ExpiresAt        *time.Time
+	UsedAt           *time.Time
+	UsedBranchID     *uint
+	CancelledAt      *time.Time
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook is a merchant subscription to domain events, delivered by HTTP POST
+// to URL and signed with Secret. EventTypes is a comma separated list.
+type Webhook struct {
+	gorm.Model
+	MerchantID uint     `gorm:"not null;index"`
+	Merchant   Merchant `gorm:"foreignKey:MerchantID"`
+	URL        string   `gorm:"not null"`
+	EventTypes string   `gorm:"not null"`
+	Secret     string   `gorm:"not null"`
+	Active     bool     `gorm:"not null;default:true"`
+}
+
+// Webhook delivery statuses. Pending and retrying deliveries are attempted
+// when NextAttemptAt is due; dead deliveries ran out of attempts and are only
+// sent again on a manual redelivery.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryRetrying  = "retrying"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookDelivery is the delivery of one event to one webhook and the outcome
+// of its last attempt.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint    `gorm:"not null;uniqueIndex:idx_webhook_deliveries_webhook_event"`
+	Webhook        Webhook `gorm:"foreignKey:WebhookID"`
+	EventID        string  `gorm:"not null;uniqueIndex:idx_webhook_deliveries_webhook_event"`
+	EventType      string  `gorm:"not null"`
+	Payload        string  `gorm:"type:jsonb;not null"`
+	Status         string  `gorm:"not null;index"`
+	Attempts       int     `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time
+	LastAttemptAt  *time.Time
+	ResponseStatus *int
+	LastError      string
+	DeliveredAt    *time.Time
+}
@@ -0,0 +1,174 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"loyalty-campaigns/src/common/domain_errors"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidPage = domain_errors.Validation("invalid_page", "invalid pagination parameters")
+
+// Request holds the query parameters shared by every list endpoint. Sort takes a
+// field name, prefixed with "-" for descending order.
+type Request struct {
+	Limit  int    `form:"limit" json:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor" json:"cursor"`
+	Sort   string `form:"sort" json:"sort"`
+}
+
+// Page is the envelope returned by every list endpoint. NextCursor is only set
+// when HasMore is true and must be sent back unchanged, with the same sort.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// Map converts the items of a page keeping its cursor.
+func Map[T any, R any](page *Page[T], mapper func(*T) R) *Page[R] {
+	items := make([]R, len(page.Items))
+	for i := range page.Items {
+		items[i] = mapper(&page.Items[i])
+	}
+	return &Page[R]{
+		Items:      items,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	}
+}
+
+// Key is a sortable column and the accessor that reads its value from a row.
+type Key[M any] struct {
+	Column string
+	Value  func(*M) any
+}
+
+// Sorting describes the sort fields a repository accepts. The primary key is
+// always used as tie-breaker so that the ordering is total.
+type Sorting[M any] struct {
+	IDColumn string
+	ID       func(*M) uint
+	Fields   map[string]Key[M]
+	Default  string
+}
+
+type cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v,omitempty"`
+	ID    uint            `json:"id"`
+}
+
+// Find runs the query with keyset pagination: instead of an OFFSET it continues
+// after the (sort value, id) pair of the last row of the previous page.
+func Find[M any](db *gorm.DB, req Request, sorting Sorting[M]) (*Page[M], error) {
+	sort := req.Sort
+	if sort == "" {
+		sort = sorting.Default
+	}
+	descending := strings.HasPrefix(sort, "-")
+	key, ok := sorting.Fields[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidPage, strings.TrimPrefix(sort, "-"))
+	}
+	byID := key.Column == sorting.IDColumn
+
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := db
+	if req.Cursor != "" {
+		after, err := decodeCursor(req.Cursor)
+		if err != nil || after.Sort != sort {
+			return nil, fmt.Errorf("%w: cursor does not match the requested sort", ErrInvalidPage)
+		}
+		if byID {
+			query = query.Where(fmt.Sprintf("%s %s ?", sorting.IDColumn, comparison), after.ID)
+		} else {
+			value := reflect.New(reflect.TypeOf(key.Value(new(M))))
+			if err := json.Unmarshal(after.Value, value.Interface()); err != nil {
+				return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPage)
+			}
+			query = query.Where(
+				fmt.Sprintf("(%s, %s) %s (?, ?)", key.Column, sorting.IDColumn, comparison),
+				value.Elem().Interface(), after.ID,
+			)
+		}
+	}
+
+	if !byID {
+		query = query.Order(key.Column + " " + direction)
+	}
+	limit := req.limit()
+
+	var items []M
+	err := query.Order(sorting.IDColumn + " " + direction).Limit(limit + 1).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[M]{Items: items}
+	if page.Items == nil {
+		page.Items = []M{}
+	}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.HasMore = true
+
+		last := &page.Items[limit-1]
+		next := cursor{Sort: sort, ID: sorting.ID(last)}
+		if !byID {
+			next.Value, err = json.Marshal(key.Value(last))
+			if err != nil {
+				return nil, err
+			}
+		}
+		page.NextCursor, err = encodeCursor(next)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func (r Request) limit() int {
+	if r.Limit <= 0 {
+		return DefaultLimit
+	}
+	if r.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return r.Limit
+}
+
+func encodeCursor(c cursor) (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(encoded string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
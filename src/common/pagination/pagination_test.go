@@ -0,0 +1,216 @@
+package pagination_test
+
+import (
+	"encoding/base64"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type Item struct {
+	ID        uint
+	Name      string
+	CreatedAt time.Time
+}
+
+var itemSorting = pagination.Sorting[Item]{
+	IDColumn: "id",
+	ID:       func(item *Item) uint { return item.ID },
+	Fields: map[string]pagination.Key[Item]{
+		"id":        {Column: "id", Value: func(item *Item) any { return item.ID }},
+		"name":      {Column: "name", Value: func(item *Item) any { return item.Name }},
+		"createdAt": {Column: "created_at", Value: func(item *Item) any { return item.CreatedAt }},
+	},
+	Default: "id",
+}
+
+var _ = Describe("Find", func() {
+	var (
+		sqlMock sqlmock.Sqlmock
+		db      *gorm.DB
+		day     time.Time
+	)
+
+	columns := []string{"id", "name", "created_at"}
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err = gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		day = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("should sort by the default field and return every row when they fit in a page", func() {
+		sqlMock.ExpectQuery(`^SELECT \* FROM "items" ORDER BY id ASC LIMIT \$1$`).
+			WithArgs(pagination.DefaultLimit + 1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "a", day).AddRow(2, "b", day))
+
+		page, err := pagination.Find(db, pagination.Request{}, itemSorting)
+
+		Expect(err).To(BeNil())
+		Expect(page.Items).To(HaveLen(2))
+		Expect(page.HasMore).To(BeFalse())
+		Expect(page.NextCursor).To(BeEmpty())
+	})
+
+	It("should return an empty list rather than nil", func() {
+		sqlMock.ExpectQuery(`^SELECT \* FROM "items"`).WillReturnRows(sqlmock.NewRows(columns))
+
+		page, err := pagination.Find(db, pagination.Request{}, itemSorting)
+
+		Expect(err).To(BeNil())
+		Expect(page.Items).NotTo(BeNil())
+		Expect(page.Items).To(BeEmpty())
+	})
+
+	It("should cap the limit", func() {
+		sqlMock.ExpectQuery(`LIMIT \$1$`).
+			WithArgs(pagination.MaxLimit + 1).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := pagination.Find(db, pagination.Request{Limit: 1000}, itemSorting)
+
+		Expect(err).To(BeNil())
+	})
+
+	Describe("in ascending order", func() {
+		It("should continue after the sort value and id of the last row of the previous page", func() {
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" ORDER BY created_at ASC,id ASC LIMIT \$1$`).
+				WithArgs(3).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(4, "a", day).
+					AddRow(2, "b", day.Add(time.Hour)).
+					AddRow(3, "c", day.Add(2*time.Hour)))
+
+			first, err := pagination.Find(db, pagination.Request{Limit: 2, Sort: "createdAt"}, itemSorting)
+
+			Expect(err).To(BeNil())
+			Expect(first.Items).To(HaveLen(2))
+			Expect(first.HasMore).To(BeTrue())
+			Expect(first.NextCursor).NotTo(BeEmpty())
+
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" WHERE \(created_at, id\) > \(\$1, \$2\) ORDER BY created_at ASC,id ASC LIMIT \$3$`).
+				WithArgs(day.Add(time.Hour), 2, 3).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "c", day.Add(2*time.Hour)))
+
+			second, err := pagination.Find(db, pagination.Request{Limit: 2, Sort: "createdAt", Cursor: first.NextCursor}, itemSorting)
+
+			Expect(err).To(BeNil())
+			Expect(second.Items).To(Equal([]Item{{ID: 3, Name: "c", CreatedAt: day.Add(2 * time.Hour)}}))
+			Expect(second.HasMore).To(BeFalse())
+		})
+
+		It("should continue after the id when sorting by the id", func() {
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" ORDER BY id ASC LIMIT \$1$`).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "a", day).AddRow(2, "b", day))
+
+			first, err := pagination.Find(db, pagination.Request{Limit: 1, Sort: "id"}, itemSorting)
+			Expect(err).To(BeNil())
+
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" WHERE id > \$1 ORDER BY id ASC LIMIT \$2$`).
+				WithArgs(1, 2).
+				WillReturnRows(sqlmock.NewRows(columns))
+
+			_, err = pagination.Find(db, pagination.Request{Limit: 1, Sort: "id", Cursor: first.NextCursor}, itemSorting)
+
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Describe("in descending order", func() {
+		It("should continue before the sort value and id of the last row of the previous page", func() {
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" ORDER BY name DESC,id DESC LIMIT \$1$`).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(5, "c", day).AddRow(7, "b", day))
+
+			first, err := pagination.Find(db, pagination.Request{Limit: 1, Sort: "-name"}, itemSorting)
+			Expect(err).To(BeNil())
+			Expect(first.Items).To(Equal([]Item{{ID: 5, Name: "c", CreatedAt: day}}))
+
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" WHERE \(name, id\) < \(\$1, \$2\) ORDER BY name DESC,id DESC LIMIT \$3$`).
+				WithArgs("c", 5, 2).
+				WillReturnRows(sqlmock.NewRows(columns))
+
+			_, err = pagination.Find(db, pagination.Request{Limit: 1, Sort: "-name", Cursor: first.NextCursor}, itemSorting)
+
+			Expect(err).To(BeNil())
+		})
+
+		It("should continue before the id when sorting by the id", func() {
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" ORDER BY id DESC LIMIT \$1$`).
+				WillReturnRows(sqlmock.NewRows(columns).AddRow(9, "a", day).AddRow(8, "b", day))
+
+			first, err := pagination.Find(db, pagination.Request{Limit: 1, Sort: "-id"}, itemSorting)
+			Expect(err).To(BeNil())
+
+			sqlMock.ExpectQuery(`^SELECT \* FROM "items" WHERE id < \$1 ORDER BY id DESC LIMIT \$2$`).
+				WithArgs(9, 2).
+				WillReturnRows(sqlmock.NewRows(columns))
+
+			_, err = pagination.Find(db, pagination.Request{Limit: 1, Sort: "-id", Cursor: first.NextCursor}, itemSorting)
+
+			Expect(err).To(BeNil())
+		})
+	})
+
+	It("should break ties between equal sort values on the id", func() {
+		sqlMock.ExpectQuery(`^SELECT \* FROM "items" ORDER BY created_at ASC,id ASC LIMIT \$1$`).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "a", day).AddRow(6, "b", day).AddRow(8, "c", day))
+
+		first, err := pagination.Find(db, pagination.Request{Limit: 2, Sort: "createdAt"}, itemSorting)
+		Expect(err).To(BeNil())
+
+		// The next page starts after (day, 6), so the row with the same
+		// creation date and a greater id is not skipped.
+		sqlMock.ExpectQuery(`WHERE \(created_at, id\) > \(\$1, \$2\) ORDER BY created_at ASC,id ASC`).
+			WithArgs(day, 6, 3).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(8, "c", day))
+
+		second, err := pagination.Find(db, pagination.Request{Limit: 2, Sort: "createdAt", Cursor: first.NextCursor}, itemSorting)
+
+		Expect(err).To(BeNil())
+		Expect(second.Items).To(HaveLen(1))
+		Expect(second.Items[0].ID).To(Equal(uint(8)))
+	})
+
+	It("should reject an unknown sort field", func() {
+		_, err := pagination.Find(db, pagination.Request{Sort: "-password"}, itemSorting)
+
+		Expect(err).To(MatchError(pagination.ErrInvalidPage))
+		Expect(err).To(MatchError(ContainSubstring(`unknown sort field "password"`)))
+		Expect(err).To(MatchError(domain_errors.ErrValidation))
+	})
+
+	Describe("with an invalid cursor", func() {
+		cursorOf := func(json string) string {
+			return base64.RawURLEncoding.EncodeToString([]byte(json))
+		}
+
+		DescribeTable("should reject it",
+			func(cursor func() string, message string) {
+				_, err := pagination.Find(db, pagination.Request{Sort: "createdAt", Cursor: cursor()}, itemSorting)
+
+				Expect(err).To(MatchError(pagination.ErrInvalidPage))
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("that is not base64", func() string { return "not a cursor!" }, "cursor does not match the requested sort"),
+			Entry("that is not JSON", func() string { return cursorOf("{") }, "cursor does not match the requested sort"),
+			Entry("issued for another sort", func() string { return cursorOf(`{"s":"-createdAt","v":"2026-03-01T12:00:00Z","id":3}`) }, "cursor does not match the requested sort"),
+			Entry("with a value of the wrong type", func() string { return cursorOf(`{"s":"createdAt","v":42,"id":3}`) }, "malformed cursor"),
+		)
+	})
+})
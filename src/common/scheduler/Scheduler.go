@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"loyalty-campaigns/src/common/utils"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work executed periodically by the scheduler.
+type Job func(ctx context.Context) error
+
+type JobStatus struct {
+	Name          string     `json:"name"`
+	Interval      string     `json:"interval"`
+	Running       bool       `json:"running"`
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+}
+
+// Clock is the source of time of the scheduler, replaced in tests.
+type Clock interface {
+	Now() time.Time
+	NewTicker(interval time.Duration) Ticker
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(interval time.Duration) Ticker {
+	return realTicker{time.NewTicker(interval)}
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+type IScheduler interface {
+	Register(name string, interval time.Duration, job Job)
+	Start(ctx context.Context)
+	Stop()
+	Status() []JobStatus
+	Healthy() error
+}
+
+type scheduledJob struct {
+	name          string
+	interval      time.Duration
+	job           Job
+	running       bool
+	lastRunAt     *time.Time
+	lastSuccessAt *time.Time
+	lastError     string
+}
+
+type scheduler struct {
+	mu        sync.RWMutex
+	jobs      []*scheduledJob
+	started   bool
+	startedAt time.Time
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	clock     Clock
+	logger    utils.ILogger
+}
+
+var (
+	schedulerInstance *scheduler
+	schedulerOnce     sync.Once
+)
+
+func NewScheduler() IScheduler {
+	schedulerOnce.Do(func() {
+		schedulerInstance = newScheduler(realClock{})
+	})
+	return schedulerInstance
+}
+
+// NewSchedulerWithClock returns a scheduler, separate from the shared one,
+// that reads the time from clock.
+func NewSchedulerWithClock(clock Clock) IScheduler {
+	return newScheduler(clock)
+}
+
+func newScheduler(clock Clock) *scheduler {
+	return &scheduler{
+		clock:  clock,
+		logger: utils.NewLogger(),
+	}
+}
+
+func (s *scheduler) Register(name string, interval time.Duration, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, &scheduledJob{
+		name:     name,
+		interval: interval,
+		job:      job,
+	})
+}
+
+func (s *scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.started = true
+	s.startedAt = s.clock.Now()
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+
+	s.logger.Success("[OK] scheduler started with %d jobs", len(s.jobs))
+}
+
+func (s *scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = false
+	s.cancel()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *scheduler) loop(ctx context.Context, job *scheduledJob) {
+	defer s.wg.Done()
+
+	ticker := s.clock.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+func (s *scheduler) run(ctx context.Context, job *scheduledJob) {
+	s.mu.Lock()
+	now := s.clock.Now()
+	job.running = true
+	job.lastRunAt = &now
+	s.mu.Unlock()
+
+	err := safeRun(ctx, job.job)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.running = false
+	if err != nil {
+		job.lastError = err.Error()
+		s.logger.Error("job %s failed: %v", job.name, err)
+		return
+	}
+
+	finishedAt := s.clock.Now()
+	job.lastSuccessAt = &finishedAt
+	job.lastError = ""
+}
+
+func safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job(ctx)
+}
+
+func (s *scheduler) Status() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]JobStatus, len(s.jobs))
+	for i, job := range s.jobs {
+		statuses[i] = JobStatus{
+			Name:          job.name,
+			Interval:      job.interval.String(),
+			Running:       job.running,
+			LastRunAt:     job.lastRunAt,
+			LastSuccessAt: job.lastSuccessAt,
+			LastError:     job.lastError,
+		}
+	}
+	return statuses
+}
+
+// Healthy reports an error when the scheduler is not running or when a job that
+// is not running has not completed successfully for more than three of its
+// intervals. A run that takes longer than that, such as a slow webhook batch,
+// does not make the scheduler unhealthy while it is still in progress.
+func (s *scheduler) Healthy() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.started {
+		return fmt.Errorf("scheduler is not running")
+	}
+
+	now := s.clock.Now()
+	for _, job := range s.jobs {
+		if job.running {
+			continue
+		}
+
+		lastSuccess := s.startedAt
+		if job.lastSuccessAt != nil {
+			lastSuccess = *job.lastSuccessAt
+		}
+		if now.Sub(lastSuccess) > 3*job.interval {
+			return fmt.Errorf("job %s has not succeeded since %s", job.name, lastSuccess.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"loyalty-campaigns/src/common/scheduler"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduler", func() {
+	var (
+		clock   *fakeClock
+		sched   scheduler.IScheduler
+		results chan error
+		start   time.Time
+	)
+
+	interval := time.Minute
+
+	// job blocks until the test hands it the outcome of its run
+	job := func(ctx context.Context) error {
+		select {
+		case err := <-results:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	status := func() scheduler.JobStatus {
+		return sched.Status()[0]
+	}
+
+	// finish completes the current run with err and waits for it to be recorded
+	finish := func(err error) {
+		results <- err
+		Eventually(func() bool { return status().Running }).Should(BeFalse())
+	}
+
+	BeforeEach(func() {
+		start = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+		clock = &fakeClock{now: start}
+		results = make(chan error)
+		sched = scheduler.NewSchedulerWithClock(clock)
+		sched.Register("expire-rewards", interval, job)
+	})
+
+	AfterEach(func() {
+		sched.Stop()
+	})
+
+	It("should be unhealthy before it starts", func() {
+		Expect(sched.Healthy()).To(MatchError("scheduler is not running"))
+	})
+
+	It("should run a job as soon as it starts and record its success", func() {
+		sched.Start(context.Background())
+		Eventually(func() bool { return status().Running }).Should(BeTrue())
+		clock.Advance(time.Second)
+		finish(nil)
+
+		Expect(status().LastRunAt).To(Equal(ptr(start)))
+		Expect(status().LastSuccessAt).To(Equal(ptr(start.Add(time.Second))))
+		Expect(status().LastError).To(BeEmpty())
+		Expect(status().Interval).To(Equal("1m0s"))
+		Expect(sched.Healthy()).To(Succeed())
+	})
+
+	It("should become unhealthy after three intervals without a success", func() {
+		sched.Start(context.Background())
+		finish(nil)
+
+		clock.Advance(3 * interval)
+		Expect(sched.Healthy()).To(Succeed())
+
+		clock.Advance(time.Nanosecond)
+		Expect(sched.Healthy()).To(MatchError(ContainSubstring("job expire-rewards has not succeeded since 2026-01-01T12:00:00Z")))
+	})
+
+	It("should stay healthy while a run takes longer than three intervals", func() {
+		sched.Start(context.Background())
+		finish(nil)
+
+		clock.Advance(interval)
+		clock.Tick()
+		Eventually(func() bool { return status().Running }).Should(BeTrue())
+
+		clock.Advance(10 * interval)
+		Expect(sched.Healthy()).To(Succeed())
+
+		finish(errors.New("webhook timeout"))
+		Expect(sched.Healthy()).To(MatchError(ContainSubstring("job expire-rewards has not succeeded since 2026-01-01T12:00:00Z")))
+	})
+
+	It("should count a job that never succeeded from the start of the scheduler", func() {
+		sched.Start(context.Background())
+		finish(errors.New("database unavailable"))
+
+		clock.Advance(3 * interval)
+		Expect(sched.Healthy()).To(Succeed())
+
+		clock.Advance(time.Nanosecond)
+		Expect(sched.Healthy()).To(HaveOccurred())
+	})
+
+	It("should record a failed run and keep running the job", func() {
+		sched.Start(context.Background())
+		finish(nil)
+
+		clock.Advance(interval)
+		clock.Tick()
+		finish(errors.New("database unavailable"))
+
+		Expect(status().LastError).To(Equal("database unavailable"))
+		Expect(status().LastRunAt).To(Equal(ptr(start.Add(interval))))
+		Expect(status().LastSuccessAt).To(Equal(ptr(start)))
+
+		clock.Advance(interval)
+		clock.Tick()
+		finish(nil)
+
+		Expect(status().LastError).To(BeEmpty())
+		Expect(status().LastSuccessAt).To(Equal(ptr(start.Add(2 * interval))))
+	})
+
+	It("should recover from a panicking job", func() {
+		sched.Register("panics", interval, func(ctx context.Context) error {
+			panic("boom")
+		})
+		sched.Start(context.Background())
+		finish(nil)
+
+		Eventually(func() string { return sched.Status()[1].LastError }).Should(Equal("panic: boom"))
+		Expect(status().LastError).To(BeEmpty())
+	})
+
+	It("should wait for running jobs when it stops", func() {
+		sched.Start(context.Background())
+		Eventually(func() bool { return status().Running }).Should(BeTrue())
+
+		sched.Stop()
+
+		Expect(status().Running).To(BeFalse())
+		Expect(status().LastError).To(Equal(context.Canceled.Error()))
+		Expect(sched.Healthy()).To(MatchError("scheduler is not running"))
+	})
+})
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(interval time.Duration) scheduler.Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ticker := &fakeTicker{c: make(chan time.Time)}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Tick fires every ticker once, waiting for its job loop to receive it.
+func (c *fakeClock) Tick() {
+	c.mu.Lock()
+	tickers := c.tickers
+	now := c.now
+	c.mu.Unlock()
+
+	for _, ticker := range tickers {
+		Eventually(ticker.c).Should(BeSent(now))
+	}
+}
+
+type fakeTicker struct {
+	c chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {}
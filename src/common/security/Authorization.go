@@ -0,0 +1,116 @@
+package security
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+)
+
+var (
+	ErrUnauthenticated = domain_errors.ErrUnauthenticated
+	ErrForbidden       = domain_errors.ErrForbidden
+)
+
+type Action string
+
+const (
+	// ActionRead covers queries over the merchant's data.
+	ActionRead Action = "read"
+	// ActionOperate covers point-of-sale operations such as processing transactions and redemptions.
+	ActionOperate Action = "operate"
+	// ActionManage covers configuration changes such as branches, campaigns and API keys.
+	ActionManage Action = "manage"
+)
+
+// Can reports whether the principal may perform the action on data owned by the
+// merchant and, when given, the branch. Branch operators only operate at their
+// own branch, so they cannot perform operations that are not tied to a branch.
+func (p *Principal) Can(action Action, merchantID uint, branchID *uint) bool {
+	if p == nil {
+		return false
+	}
+	if p.IsAdmin() {
+		return true
+	}
+	if p.MerchantID != merchantID {
+		return false
+	}
+
+	switch p.Role {
+	case RoleMerchantAdmin:
+		return true
+	case RoleBranchOperator:
+		switch action {
+		case ActionRead:
+			return true
+		case ActionOperate:
+			return branchID != nil && p.BranchID != nil && *p.BranchID == *branchID
+		}
+	case RoleAnalyst:
+		return action == ActionRead
+	}
+
+	return false
+}
+
+// Authorize checks the principal of the context against a merchant-owned resource.
+func Authorize(ctx context.Context, action Action, merchantID uint, branchID *uint) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !principal.Can(action, merchantID, branchID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// OperatorBranch returns branchID, or the branch of a branch operator when it
+// is nil. Operations that take place at a branch, such as redemptions, use it
+// so that branch operators need not repeat their branch.
+func OperatorBranch(ctx context.Context, branchID *uint) *uint {
+	if branchID != nil {
+		return branchID
+	}
+	principal, ok := PrincipalFromContext(ctx)
+	if ok && principal.Role == RoleBranchOperator {
+		return principal.BranchID
+	}
+	return nil
+}
+
+func AuthorizeAdmin(ctx context.Context) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !principal.IsAdmin() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// MerchantScope returns the merchant every query must be filtered by, or nil
+// when the caller can see all merchants.
+func MerchantScope(ctx context.Context) (*uint, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	return principal.MerchantScope(), nil
+}
+
+// FilterScope returns the merchant a list must be filtered by: whatever was
+// requested for platform admins, otherwise the caller's own merchant.
+func FilterScope(ctx context.Context, requested *uint) (*uint, error) {
+	scope, err := MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scope == nil {
+		return requested, nil
+	}
+	if requested != nil && *requested != *scope {
+		return nil, ErrForbidden
+	}
+	return scope, nil
+}
@@ -0,0 +1,25 @@
+package security
+
+import (
+	"loyalty-campaigns/src/common/domain_errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errAdminRequired = domain_errors.Forbidden("admin_required", "admin credentials required")
+
+// RequireAdmin only lets platform administrators through.
+func RequireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, ok := GetPrincipal(ctx)
+		if !ok {
+			domain_errors.Abort(ctx, ErrUnauthenticated)
+			return
+		}
+		if !principal.IsAdmin() {
+			domain_errors.Abort(ctx, errAdminRequired)
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package security
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Role string
+
+const (
+	RolePlatformAdmin  Role = "platform_admin"
+	RoleMerchantAdmin  Role = "merchant_admin"
+	RoleBranchOperator Role = "branch_operator"
+	RoleAnalyst        Role = "analyst"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Role       Role  `json:"role"`
+	KeyID      uint  `json:"keyId,omitempty"`
+	MerchantID uint  `json:"merchantId,omitempty"`
+	BranchID   *uint `json:"branchId,omitempty"`
+}
+
+// System is the principal used by background jobs and internal tooling.
+func System() *Principal {
+	return &Principal{Role: RolePlatformAdmin}
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p != nil && p.Role == RolePlatformAdmin
+}
+
+// MerchantScope returns the merchant the principal is restricted to, or nil for platform admins.
+func (p *Principal) MerchantScope() *uint {
+	if p.IsAdmin() {
+		return nil
+	}
+	merchantID := p.MerchantID
+	return &merchantID
+}
+
+type principalKey struct{}
+
+const ginPrincipalKey = "principal"
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// SetPrincipal attaches the caller identity to the gin context and to the
+// request context that is handed down to the services.
+func SetPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(ginPrincipalKey, principal)
+	ctx.Request = ctx.Request.WithContext(WithPrincipal(ctx.Request.Context(), principal))
+}
+
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	value, exists := ctx.Get(ginPrincipalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*Principal)
+	return principal, ok && principal != nil
+}
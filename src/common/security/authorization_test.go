@@ -0,0 +1,107 @@
+package security_test
+
+import (
+	"context"
+
+	"loyalty-campaigns/src/common/security"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+var _ = Describe("Authorization", func() {
+	var (
+		merchantID uint = 1
+		branchID   uint = 2
+		operator   *security.Principal
+	)
+
+	BeforeEach(func() {
+		operator = &security.Principal{
+			Role:       security.RoleBranchOperator,
+			MerchantID: merchantID,
+			BranchID:   ptr(branchID),
+		}
+	})
+
+	Describe("Can", func() {
+		It("should let a branch operator operate at their branch", func() {
+			Expect(operator.Can(security.ActionOperate, merchantID, ptr(branchID))).To(BeTrue())
+		})
+
+		It("should not let a branch operator operate at another branch", func() {
+			Expect(operator.Can(security.ActionOperate, merchantID, ptr(branchID+1))).To(BeFalse())
+		})
+
+		It("should not let a branch operator operate merchant-wide", func() {
+			Expect(operator.Can(security.ActionOperate, merchantID, nil)).To(BeFalse())
+		})
+
+		It("should not let a branch operator without a branch operate", func() {
+			operator.BranchID = nil
+
+			Expect(operator.Can(security.ActionOperate, merchantID, ptr(branchID))).To(BeFalse())
+		})
+
+		It("should let a branch operator read the whole merchant", func() {
+			Expect(operator.Can(security.ActionRead, merchantID, nil)).To(BeTrue())
+		})
+
+		It("should not let a branch operator manage", func() {
+			Expect(operator.Can(security.ActionManage, merchantID, ptr(branchID))).To(BeFalse())
+		})
+
+		It("should not let a branch operator act on another merchant", func() {
+			Expect(operator.Can(security.ActionRead, merchantID+1, nil)).To(BeFalse())
+		})
+
+		It("should let a merchant admin operate merchant-wide", func() {
+			admin := &security.Principal{Role: security.RoleMerchantAdmin, MerchantID: merchantID}
+
+			Expect(admin.Can(security.ActionOperate, merchantID, nil)).To(BeTrue())
+		})
+
+		It("should only let an analyst read", func() {
+			analyst := &security.Principal{Role: security.RoleAnalyst, MerchantID: merchantID}
+
+			Expect(analyst.Can(security.ActionRead, merchantID, nil)).To(BeTrue())
+			Expect(analyst.Can(security.ActionOperate, merchantID, ptr(branchID))).To(BeFalse())
+		})
+	})
+
+	Describe("Authorize", func() {
+		It("should reject a merchant-wide operation by a branch operator", func() {
+			ctx := security.WithPrincipal(context.Background(), operator)
+
+			Expect(security.Authorize(ctx, security.ActionOperate, merchantID, nil)).To(MatchError(security.ErrForbidden))
+		})
+
+		It("should reject a context without a principal", func() {
+			Expect(security.Authorize(context.Background(), security.ActionRead, merchantID, nil)).To(MatchError(security.ErrUnauthenticated))
+		})
+	})
+
+	Describe("OperatorBranch", func() {
+		It("should keep the requested branch", func() {
+			ctx := security.WithPrincipal(context.Background(), operator)
+
+			Expect(security.OperatorBranch(ctx, ptr(branchID+1))).To(Equal(ptr(branchID + 1)))
+		})
+
+		It("should default to the branch of a branch operator", func() {
+			ctx := security.WithPrincipal(context.Background(), operator)
+
+			Expect(security.OperatorBranch(ctx, nil)).To(Equal(ptr(branchID)))
+		})
+
+		It("should stay merchant-wide for other roles", func() {
+			ctx := security.WithPrincipal(context.Background(), &security.Principal{Role: security.RoleMerchantAdmin, MerchantID: merchantID})
+
+			Expect(security.OperatorBranch(ctx, nil)).To(BeNil())
+		})
+	})
+})
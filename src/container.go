@@ -0,0 +1,172 @@
+package src
+
+import (
+	"context"
+	"loyalty-campaigns/src/bonus/bonus_app"
+	"loyalty-campaigns/src/bonus/bonus_infra/bonus_repository"
+	"loyalty-campaigns/src/branch/branch_app"
+	"loyalty-campaigns/src/branch/branch_infra/branch_repository"
+	"loyalty-campaigns/src/campaign/campaign_app"
+	"loyalty-campaigns/src/campaign/campaign_infra/campaign_repository"
+	"loyalty-campaigns/src/catalog/catalog_app"
+	"loyalty-campaigns/src/catalog/catalog_infra/catalog_repository"
+	"loyalty-campaigns/src/challenge/challenge_app"
+	"loyalty-campaigns/src/challenge/challenge_infra/challenge_repository"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/migrations"
+	"loyalty-campaigns/src/common/scheduler"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/loyalty/loyalty_app"
+	"loyalty-campaigns/src/loyalty/loyalty_infra/loyalty_repository"
+	"loyalty-campaigns/src/merchant/merchant_app"
+	"loyalty-campaigns/src/merchant/merchant_infra/merchant_repository"
+	"loyalty-campaigns/src/referral/referral_app"
+	"loyalty-campaigns/src/referral/referral_infra/referral_repository"
+	"loyalty-campaigns/src/reward/reward_app"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"loyalty-campaigns/src/stamp/stamp_app"
+	"loyalty-campaigns/src/stamp/stamp_infra/stamp_repository"
+	"loyalty-campaigns/src/threshold/threshold_app"
+	"loyalty-campaigns/src/threshold/threshold_infra/threshold_repository"
+	"loyalty-campaigns/src/transaction/transaction_app"
+	"loyalty-campaigns/src/transaction/transaction_infra/transaction_repository"
+	"loyalty-campaigns/src/user/user_app"
+	"loyalty-campaigns/src/user/user_infra/user_repository"
+	"loyalty-campaigns/src/webhook/webhook_app"
+	"loyalty-campaigns/src/webhook/webhook_infra/webhook_repository"
+	"time"
+)
+
+const (
+	expireRewardsInterval   = time.Hour
+	vestRewardsInterval     = time.Hour
+	expireVouchersInterval  = time.Hour
+	expireHoldsInterval     = time.Minute
+	issueBonusesInterval    = 24 * time.Hour
+	relayEventsInterval     = 5 * time.Second
+	deliverWebhooksInterval = 5 * time.Second
+	processImportsInterval  = 5 * time.Second
+)
+
+// container is the composition root shared by every command: it owns the
+// database connection and builds the services the commands work with.
+type container struct {
+	dbConnection    configs.IDBConnection
+	migrator        migrations.IMigrator
+	scheduler       scheduler.IScheduler
+	relay           events.IRelay
+	merchantService merchant_app.IMerchantService
+	branchService   branch_app.IBranchService
+	campaignService campaign_app.ICampaignService
+	userService     user_app.IUserService
+	rewardService   reward_app.IRewardService
+	webhookService  webhook_app.IWebhookService
+	importService   loyalty_app.IImportService
+	catalogService  catalog_app.ICatalogService
+	bonusService    bonus_app.IBonusService
+}
+
+func newContainer() (*container, error) {
+	dbConnection := configs.NewDBConnection()
+	db := dbConnection.GetDB()
+
+	sink, err := events.NewSinkFromEnv()
+	if err != nil {
+		dbConnection.Close()
+		return nil, err
+	}
+	webhookService := webhook_app.NewWebhookService(
+		webhook_repository.NewGormWebhookRepository(db),
+		webhook_app.WebhookPolicyFromEnv(),
+	)
+
+	merchantService := merchant_app.NewMerchantService(merchant_repository.NewGormMerchantRepository(db))
+	campaignService := campaign_app.NewCampaignService(campaign_repository.NewGormCampaignRepository(db))
+	userRepository := user_repository.NewGormUserRepository(db)
+	userService := user_app.NewUserService(userRepository)
+	rewardService := reward_app.NewRewardService(reward_repository.NewGormRewardRepository(db))
+	branchRepository := branch_repository.NewGormBranchRepository(db)
+	loyaltyService := loyalty_app.NewLoyaltyService(
+		transaction_app.NewTransactionService(transaction_repository.NewGormTransactionRepository(db), branchRepository, userRepository),
+		campaignService,
+		rewardService,
+		merchantService,
+		userService,
+		referral_app.NewReferralService(referral_repository.NewGormReferralRepository(db), referral_app.ReferralPolicyFromEnv()),
+		stamp_app.NewStampService(stamp_repository.NewGormStampRepository(db), catalog_app.VoucherPolicyFromEnv()),
+		threshold_app.NewThresholdService(threshold_repository.NewGormThresholdRepository(db)),
+		challenge_app.NewChallengeService(challenge_repository.NewGormChallengeRepository(db)),
+		configs.NewUnitOfWork(db),
+		configs.GetEnvDuration("REDEMPTION_HOLD_TIMEOUT", loyalty_app.DefaultHoldTimeout),
+	)
+
+	c := &container{
+		dbConnection:    dbConnection,
+		migrator:        migrations.NewMigrator(db),
+		scheduler:       scheduler.NewScheduler(),
+		relay:           events.NewRelay(db, events.NewFanOutSink(webhookService, sink), configs.GetEnvInt("OUTBOX_BATCH_SIZE", 100), configs.GetEnvInt("OUTBOX_MAX_ATTEMPTS", 20)),
+		merchantService: merchantService,
+		branchService:   branch_app.NewBranchService(branchRepository),
+		campaignService: campaignService,
+		userService:     userService,
+		rewardService:   rewardService,
+		webhookService:  webhookService,
+		importService: loyalty_app.NewImportService(
+			loyalty_repository.NewGormImportRepository(db),
+			loyaltyService,
+			configs.NewUnitOfWork(db),
+			configs.GetEnvInt("IMPORT_MAX_BYTES", loyalty_app.DefaultMaxImportBytes),
+		),
+		catalogService: catalog_app.NewCatalogService(catalog_repository.NewGormCatalogRepository(db), catalog_app.VoucherPolicyFromEnv()),
+		bonusService:   bonus_app.NewBonusService(bonus_repository.NewGormBonusRepository(db), bonus_app.BonusPolicyFromEnv()),
+	}
+	c.registerJobs()
+
+	return c, nil
+}
+
+// registerJobs schedules the background jobs run by the server. Jobs act as
+// the system principal, except imports, which act on behalf of the merchant
+// that uploaded them.
+func (c *container) registerJobs() {
+	c.scheduler.Register("expire-rewards", expireRewardsInterval, func(ctx context.Context) error {
+		_, err := c.rewardService.ExpireRewards(security.WithPrincipal(ctx, security.System()), time.Now())
+		return err
+	})
+	c.scheduler.Register("vest-rewards", vestRewardsInterval, func(ctx context.Context) error {
+		_, err := c.rewardService.VestRewards(security.WithPrincipal(ctx, security.System()), time.Now())
+		return err
+	})
+	c.scheduler.Register("expire-holds", expireHoldsInterval, func(ctx context.Context) error {
+		_, err := c.rewardService.ExpireHolds(security.WithPrincipal(ctx, security.System()), time.Now())
+		return err
+	})
+	c.scheduler.Register("expire-vouchers", expireVouchersInterval, func(ctx context.Context) error {
+		_, err := c.catalogService.ExpireVouchers(security.WithPrincipal(ctx, security.System()), time.Now())
+		return err
+	})
+	c.scheduler.Register("issue-lifecycle-bonuses", issueBonusesInterval, func(ctx context.Context) error {
+		_, err := c.bonusService.IssueLifecycleBonuses(security.WithPrincipal(ctx, security.System()), time.Now())
+		return err
+	})
+	c.scheduler.Register("relay-events", configs.GetEnvDuration("OUTBOX_RELAY_INTERVAL", relayEventsInterval), func(ctx context.Context) error {
+		_, err := c.relay.Relay(ctx)
+		return err
+	})
+	c.scheduler.Register("deliver-webhooks", deliverWebhooksInterval, func(ctx context.Context) error {
+		_, err := c.webhookService.DeliverDue(ctx)
+		return err
+	})
+	c.scheduler.Register("process-imports", processImportsInterval, func(ctx context.Context) error {
+		_, err := c.importService.ProcessPending(ctx)
+		return err
+	})
+}
+
+func (c *container) close() {
+	err := c.dbConnection.Close()
+	if err != nil {
+		logger.Error("failed to close the database connection: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package src
+
+// Exposes the unexported command internals to the src_test package.
+var (
+	RunMigrate       = runMigrate
+	ParseMigrateArgs = parseMigrateArgs
+)
+
+const (
+	ExitOK      = exitOK
+	ExitFailure = exitFailure
+	ExitUsage   = exitUsage
+)
@@ -0,0 +1,118 @@
+package health_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/migrations"
+	"loyalty-campaigns/src/common/scheduler"
+	"loyalty-campaigns/src/health/health_domain/health_structs/health_responses"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+
+	databaseCheckTimeout   = 2 * time.Second
+	migrationsCheckTimeout = 2 * time.Second
+)
+
+type IHealthService interface {
+	Liveness() health_responses.HealthResponse
+	Readiness(ctx context.Context) (health_responses.HealthResponse, bool)
+	SetReady(ready bool)
+}
+
+type healthService struct {
+	dbConnection configs.IDBConnection
+	migrator     migrations.IMigrator
+	scheduler    scheduler.IScheduler
+	ready        atomic.Bool
+}
+
+var (
+	healthServiceInstance *healthService
+	healthServiceOnce     sync.Once
+)
+
+func NewHealthService(dbConnection configs.IDBConnection, migrator migrations.IMigrator, scheduler scheduler.IScheduler) IHealthService {
+	healthServiceOnce.Do(func() {
+		healthServiceInstance = &healthService{
+			dbConnection: dbConnection,
+			migrator:     migrator,
+			scheduler:    scheduler,
+		}
+	})
+	return healthServiceInstance
+}
+
+func (s *healthService) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+func (s *healthService) Liveness() health_responses.HealthResponse {
+	return health_responses.HealthResponse{Status: StatusOK}
+}
+
+// Readiness runs every check. The database and migrations checks give up when
+// ctx is done or after a short timeout, so a stalled database fails the probe
+// instead of hanging it.
+func (s *healthService) Readiness(ctx context.Context) (health_responses.HealthResponse, bool) {
+	checks := map[string]health_responses.CheckResult{
+		"startup":    s.checkStartup(),
+		"database":   s.checkDatabase(ctx),
+		"migrations": s.checkMigrations(ctx),
+		"scheduler":  s.checkScheduler(),
+	}
+
+	ready := true
+	for _, check := range checks {
+		if check.Status != StatusOK {
+			ready = false
+		}
+	}
+
+	status := StatusOK
+	if !ready {
+		status = StatusUnavailable
+	}
+
+	return health_responses.HealthResponse{Status: status, Checks: checks}, ready
+}
+
+func (s *healthService) checkStartup() health_responses.CheckResult {
+	if !s.ready.Load() {
+		return health_responses.CheckResult{Status: StatusUnavailable, Error: "server is starting or shutting down"}
+	}
+	return health_responses.CheckResult{Status: StatusOK}
+}
+
+func (s *healthService) checkDatabase(ctx context.Context) health_responses.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, databaseCheckTimeout)
+	defer cancel()
+
+	if err := s.dbConnection.Ping(ctx); err != nil {
+		return health_responses.CheckResult{Status: StatusUnavailable, Error: err.Error()}
+	}
+	return health_responses.CheckResult{Status: StatusOK}
+}
+
+func (s *healthService) checkMigrations(ctx context.Context) health_responses.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, migrationsCheckTimeout)
+	defer cancel()
+
+	if err := s.migrator.Verify(ctx); err != nil {
+		return health_responses.CheckResult{Status: StatusUnavailable, Error: err.Error()}
+	}
+	return health_responses.CheckResult{Status: StatusOK}
+}
+
+func (s *healthService) checkScheduler() health_responses.CheckResult {
+	jobs := s.scheduler.Status()
+	if err := s.scheduler.Healthy(); err != nil {
+		return health_responses.CheckResult{Status: StatusUnavailable, Error: err.Error(), Details: jobs}
+	}
+	return health_responses.CheckResult{Status: StatusOK, Details: jobs}
+}
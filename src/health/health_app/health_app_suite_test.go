@@ -0,0 +1,13 @@
+package health_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHealthApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HealthApp Suite")
+}
@@ -0,0 +1,153 @@
+package health_app_test
+
+import (
+	"context"
+	"errors"
+	"loyalty-campaigns/src/common/migrations"
+	"loyalty-campaigns/src/common/scheduler"
+	"loyalty-campaigns/src/health/health_app"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/gorm"
+)
+
+var _ = Describe("HealthService", func() {
+	db := &fakeDB{}
+	migrator := &fakeMigrator{}
+	sched := &fakeScheduler{}
+	healthService := health_app.NewHealthService(db, migrator, sched)
+
+	jobs := []scheduler.JobStatus{{Name: "expire-rewards", Interval: "1m0s"}}
+
+	BeforeEach(func() {
+		db.err = nil
+		db.stalled = false
+		migrator.err = nil
+		sched.err = nil
+		sched.jobs = jobs
+		healthService.SetReady(true)
+	})
+
+	It("should always report liveness", func() {
+		healthService.SetReady(false)
+
+		Expect(healthService.Liveness().Status).To(Equal(health_app.StatusOK))
+	})
+
+	It("should be ready when every check passes", func() {
+		response, ready := healthService.Readiness(context.Background())
+
+		Expect(ready).To(BeTrue())
+		Expect(response.Status).To(Equal(health_app.StatusOK))
+		Expect(response.Checks).To(HaveLen(4))
+		for name, check := range response.Checks {
+			Expect(check.Status).To(Equal(health_app.StatusOK), name)
+		}
+		Expect(response.Checks["scheduler"].Details).To(Equal(jobs))
+	})
+
+	DescribeTable("should not be ready when a check fails",
+		func(fail func(), name, message string) {
+			fail()
+
+			response, ready := healthService.Readiness(context.Background())
+
+			Expect(ready).To(BeFalse())
+			Expect(response.Status).To(Equal(health_app.StatusUnavailable))
+			Expect(response.Checks[name].Status).To(Equal(health_app.StatusUnavailable))
+			Expect(response.Checks[name].Error).To(Equal(message))
+			for other, check := range response.Checks {
+				if other != name {
+					Expect(check.Status).To(Equal(health_app.StatusOK), other)
+				}
+			}
+		},
+		Entry("while starting or shutting down", func() { healthService.SetReady(false) }, "startup", "server is starting or shutting down"),
+		Entry("without a database", func() { db.err = errors.New("connection refused") }, "database", "connection refused"),
+		Entry("with pending migrations", func() { migrator.err = migrations.ErrSchemaOutdated }, "migrations", migrations.ErrSchemaOutdated.Error()),
+		Entry("with a stalled scheduler", func() { sched.err = errors.New("job expire-rewards has not succeeded") }, "scheduler", "job expire-rewards has not succeeded"),
+	)
+
+	It("should report the jobs when the scheduler is unhealthy", func() {
+		sched.err = errors.New("scheduler is not running")
+
+		response, _ := healthService.Readiness(context.Background())
+
+		Expect(response.Checks["scheduler"].Details).To(Equal(jobs))
+	})
+
+	It("should bound the database check with a timeout", func() {
+		healthService.Readiness(context.Background())
+
+		deadline, ok := db.ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(time.Until(deadline)).To(BeNumerically("<=", 2*time.Second))
+	})
+
+	It("should stop waiting for a stalled database when the request is cancelled", func() {
+		db.stalled = true
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		response, ready := healthService.Readiness(ctx)
+
+		Expect(ready).To(BeFalse())
+		Expect(response.Checks["database"].Status).To(Equal(health_app.StatusUnavailable))
+		Expect(response.Checks["database"].Error).To(Equal(context.Canceled.Error()))
+	})
+
+	It("should bound the migrations check with a timeout", func() {
+		healthService.Readiness(context.Background())
+
+		deadline, ok := migrator.ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(time.Until(deadline)).To(BeNumerically("<=", 2*time.Second))
+	})
+})
+
+type fakeDB struct {
+	err     error
+	stalled bool
+	ctx     context.Context
+}
+
+func (d *fakeDB) GetDB() *gorm.DB { return nil }
+func (d *fakeDB) Close() error    { return nil }
+func (d *fakeDB) Ping(ctx context.Context) error {
+	d.ctx = ctx
+	if d.stalled {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return d.err
+}
+
+type fakeMigrator struct {
+	err error
+	ctx context.Context
+}
+
+func (m *fakeMigrator) Up(ctx context.Context) ([]migrations.Migration, error) { return nil, nil }
+func (m *fakeMigrator) Down(ctx context.Context, steps int) ([]migrations.Migration, error) {
+	return nil, nil
+}
+func (m *fakeMigrator) Status(ctx context.Context) ([]migrations.MigrationStatus, error) {
+	return nil, nil
+}
+func (m *fakeMigrator) Verify(ctx context.Context) error {
+	m.ctx = ctx
+	return m.err
+}
+
+type fakeScheduler struct {
+	err  error
+	jobs []scheduler.JobStatus
+}
+
+func (s *fakeScheduler) Register(name string, interval time.Duration, job scheduler.Job) {}
+func (s *fakeScheduler) Start(ctx context.Context)                                       {}
+func (s *fakeScheduler) Stop()                                                           {}
+func (s *fakeScheduler) Status() []scheduler.JobStatus                                   { return s.jobs }
+func (s *fakeScheduler) Healthy() error                                                  { return s.err }
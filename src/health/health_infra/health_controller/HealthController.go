@@ -0,0 +1,71 @@
+package health_controller
+
+import (
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/migrations"
+	"loyalty-campaigns/src/common/scheduler"
+	"loyalty-campaigns/src/health/health_app"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	healthService health_app.IHealthService
+}
+
+var (
+	healthControllerInstance *HealthController
+	healthControllerOnce     sync.Once
+)
+
+func NewHealthController(router *gin.Engine, dbConnection configs.IDBConnection, migrator migrations.IMigrator, scheduler scheduler.IScheduler) *HealthController {
+	healthControllerOnce.Do(func() {
+		healthControllerInstance = &HealthController{}
+		healthControllerInstance.healthService = health_app.NewHealthService(dbConnection, migrator, scheduler)
+		healthControllerInstance.setupHealthRoutes(router)
+	})
+	return healthControllerInstance
+}
+
+func (c *HealthController) setupHealthRoutes(router *gin.Engine) {
+	router.GET("/healthz", c.Liveness)
+	router.GET("/readyz", c.Readiness)
+}
+
+// SetReady marks the service as ready (or not) to receive traffic.
+func (c *HealthController) SetReady(ready bool) {
+	c.healthService.SetReady(ready)
+}
+
+// Liveness godoc
+//
+//	@Summary		Liveness probe
+//	@Description	Report that the process is alive
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	health_responses.HealthResponse
+//	@Router			/healthz [get]
+func (c *HealthController) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.healthService.Liveness())
+}
+
+// Readiness godoc
+//
+//	@Summary		Readiness probe
+//	@Description	Check the database, schema migrations and background scheduler and report whether the service can receive traffic
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	health_responses.HealthResponse
+//	@Failure		503	{object}	health_responses.HealthResponse
+//	@Router			/readyz [get]
+func (c *HealthController) Readiness(ctx *gin.Context) {
+	response, ready := c.healthService.Readiness(ctx.Request.Context())
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
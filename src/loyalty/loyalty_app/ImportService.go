@@ -0,0 +1,532 @@
+package loyalty_app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_ports"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_structs/loyalty_requests"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_structs/loyalty_responses"
+	"loyalty-campaigns/src/transaction/transaction_app"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxImportBytes is the default size limit of an uploaded file.
+	DefaultMaxImportBytes = 10 << 20
+
+	importRowsPerRun   = 1000
+	importLease        = 2 * time.Minute
+	maxExternalRefSize = 100
+	maxImportLineBytes = 64 << 10
+)
+
+// Columns of a CSV import, matched case-insensitively. JSONL rows use the same
+// names as keys. merchantId is optional.
+var importColumns = []string{"userId", "branchId", "merchantId", "amount", "date", "externalRef"}
+
+var (
+	ErrUnknownImportFormat = domain_errors.Validation("unknown_import_format", "unknown import format, expected csv or jsonl")
+	ErrImportTooLarge      = domain_errors.Validation("import_too_large", "the file exceeds the size limit of the imports")
+	ErrEmptyImport         = domain_errors.Validation("empty_import", "the file has no rows")
+	ErrInvalidImportFile   = domain_errors.Validation("invalid_import_file", "the file could not be read")
+)
+
+type IImportService interface {
+	// CreateImport stores the file and queues it; the rows are processed in
+	// the background by ProcessPending.
+	CreateImport(ctx context.Context, req loyalty_requests.CreateImportRequest, fileName string, content io.Reader) (*loyalty_responses.ImportJobResponse, error)
+	GetImport(ctx context.Context, id uint) (*loyalty_responses.ImportJobResponse, error)
+	ListImportRows(ctx context.Context, id uint, req loyalty_requests.ListImportRowsRequest) (*pagination.Page[loyalty_responses.ImportRowResponse], error)
+	// ErrorReport returns the failed and duplicate rows of an import as CSV.
+	ErrorReport(ctx context.Context, id uint) ([]byte, error)
+	// ProcessPending processes the rows of the queued imports, up to a limit
+	// per call, and returns how many rows it processed.
+	ProcessPending(ctx context.Context) (int, error)
+}
+
+type importService struct {
+	importRepo     loyalty_ports.IImportRepository
+	loyaltyService ILoyaltyService
+	unitOfWork     configs.IUnitOfWork
+	maxBytes       int
+	logger         utils.ILogger
+}
+
+func NewImportService(importRepo loyalty_ports.IImportRepository, loyaltyService ILoyaltyService, unitOfWork configs.IUnitOfWork, maxBytes int) IImportService {
+	return &importService{
+		importRepo:     importRepo,
+		loyaltyService: loyaltyService,
+		unitOfWork:     unitOfWork,
+		maxBytes:       maxBytes,
+		logger:         utils.NewLogger(),
+	}
+}
+
+func (s *importService) CreateImport(ctx context.Context, req loyalty_requests.CreateImportRequest, fileName string, content io.Reader) (*loyalty_responses.ImportJobResponse, error) {
+	branchID := security.OperatorBranch(ctx, req.BranchID)
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	format := req.Format
+	if format == "" {
+		format = formatOf(fileName)
+	}
+	if format == "" {
+		return nil, ErrUnknownImportFormat
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, int64(s.maxBytes)+1))
+	if err != nil {
+		return nil, ErrInvalidImportFile.Wrap(err)
+	}
+	if len(data) > s.maxBytes {
+		return nil, ErrImportTooLarge
+	}
+
+	records, err := parseImport(format, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptyImport
+	}
+
+	job := &models.ImportJob{
+		MerchantID: req.MerchantID,
+		BranchID:   branchID,
+		Format:     format,
+		Content:    data,
+		Status:     models.ImportJobPending,
+		Total:      len(records),
+	}
+	if fileName != "" {
+		job.FileName = filepath.Base(fileName)
+	}
+
+	err = s.importRepo.Create(ctx, job)
+	if err != nil {
+		s.logger.Error("Error al crear importación: %v", err)
+		return nil, err
+	}
+
+	return mapImportJobToResponse(job), nil
+}
+
+func (s *importService) GetImport(ctx context.Context, id uint) (*loyalty_responses.ImportJobResponse, error) {
+	job, err := s.getImport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return mapImportJobToResponse(job), nil
+}
+
+func (s *importService) ListImportRows(ctx context.Context, id uint, req loyalty_requests.ListImportRowsRequest) (*pagination.Page[loyalty_responses.ImportRowResponse], error) {
+	_, err := s.getImport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.importRepo.ListRows(ctx, loyalty_ports.ImportRowFilter{
+		ImportJobID: id,
+		Status:      req.Status,
+	}, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar filas de la importación: %v", err)
+		return nil, err
+	}
+
+	return pagination.Map(rows, func(row *models.ImportRow) loyalty_responses.ImportRowResponse {
+		return *mapImportRowToResponse(row)
+	}), nil
+}
+
+func (s *importService) ErrorReport(ctx context.Context, id uint) ([]byte, error) {
+	_, err := s.getImport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.importRepo.ListUnprocessedRows(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener los errores de la importación: %v", err)
+		return nil, err
+	}
+
+	var report bytes.Buffer
+	writer := csv.NewWriter(&report)
+	_ = writer.Write([]string{"line", "externalRef", "status", "errorCode", "error", "row"})
+	for _, row := range rows {
+		_ = writer.Write([]string{strconv.Itoa(row.Line), row.ExternalRef, row.Status, row.ErrorCode, row.Error, row.Raw})
+	}
+	writer.Flush()
+	return report.Bytes(), writer.Error()
+}
+
+func (s *importService) ProcessPending(ctx context.Context) (int, error) {
+	processed := 0
+	for processed < importRowsPerRun {
+		job, err := s.importRepo.ClaimJob(ctx, time.Now(), importLease)
+		if err != nil || job == nil {
+			return processed, err
+		}
+
+		n, err := s.process(ctx, job, importRowsPerRun-processed)
+		processed += n
+		if err != nil {
+			return processed, err
+		}
+	}
+	return processed, nil
+}
+
+// process runs up to limit rows of a claimed job through the accrual logic,
+// starting after the rows it already saved. Each row is processed in a unit of
+// work that also saves its outcome and the progress of the job, so a crash can
+// neither lose a row that was processed nor process it again. When saving
+// fails the run stops, and the job is resumed from the progress last saved
+// once its lease ends. A job left unfinished is released for the next run.
+func (s *importService) process(ctx context.Context, job *models.ImportJob, limit int) (int, error) {
+	records, err := parseImport(job.Format, job.Content)
+	if err != nil {
+		now := time.Now()
+		job.Status = models.ImportJobFailed
+		job.Error = err.Error()
+		job.LeaseUntil = nil
+		job.FinishedAt = &now
+		return 0, s.importRepo.SaveProgress(ctx, job, nil)
+	}
+	job.Total = len(records)
+
+	principal := &security.Principal{Role: security.RoleMerchantAdmin, MerchantID: job.MerchantID}
+	if job.BranchID != nil {
+		principal.Role = security.RoleBranchOperator
+		principal.BranchID = job.BranchID
+	}
+	rowCtx := security.WithPrincipal(ctx, principal)
+
+	processed := 0
+	for job.Processed < len(records) && processed < limit {
+		record := records[job.Processed]
+		err = s.unitOfWork.Run(rowCtx, func(ctx context.Context) error {
+			row := s.processRecord(ctx, job, record)
+			job.Processed++
+			switch row.Status {
+			case models.ImportRowProcessed:
+				job.Succeeded++
+			case models.ImportRowDuplicate:
+				job.Duplicates++
+			default:
+				job.Failed++
+			}
+			leaseUntil := time.Now().Add(importLease)
+			job.LeaseUntil = &leaseUntil
+			return s.saveProgress(ctx, job, []models.ImportRow{row})
+		})
+		if err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	now := time.Now()
+	if job.Processed == len(records) {
+		job.Status = models.ImportJobCompleted
+		job.FinishedAt = &now
+		job.LeaseUntil = nil
+	} else {
+		job.LeaseUntil = &now
+	}
+	return processed, s.saveProgress(ctx, job, nil)
+}
+
+func (s *importService) saveProgress(ctx context.Context, job *models.ImportJob, rows []models.ImportRow) error {
+	err := s.importRepo.SaveProgress(ctx, job, rows)
+	if err != nil {
+		s.logger.Error("Error al guardar el progreso de la importación %d: %v", job.ID, err)
+	}
+	return err
+}
+
+// processRecord processes one row and describes its outcome. A row that fails
+// leaves nothing behind, as ProcessTransaction rolls back its own unit of work.
+func (s *importService) processRecord(ctx context.Context, job *models.ImportJob, record importRecord) models.ImportRow {
+	row := models.ImportRow{
+		ImportJobID: job.ID,
+		Line:        record.Line,
+		Raw:         record.Raw,
+		Status:      models.ImportRowProcessed,
+	}
+	if record.Request.ExternalRef != nil {
+		row.ExternalRef = *record.Request.ExternalRef
+	}
+
+	err := record.Err
+	if err == nil {
+		req := record.Request
+		if req.MerchantID == 0 {
+			req.MerchantID = job.MerchantID
+		}
+		err = s.loyaltyService.ProcessTransaction(ctx, req)
+	}
+	if err == nil {
+		return row
+	}
+
+	row.Status = models.ImportRowFailed
+	if errors.Is(err, transaction_app.ErrDuplicateTransaction) {
+		row.Status = models.ImportRowDuplicate
+	}
+	problem := domain_errors.NewProblem(err)
+	row.ErrorCode = problem.Code
+	row.Error = problem.Detail
+	if problem.Code == "internal_error" {
+		s.logger.Error("Error al procesar la línea %d de la importación %d: %v", record.Line, job.ID, err)
+	}
+	return row
+}
+
+// getImport loads the job and checks that the caller may read its merchant.
+func (s *importService) getImport(ctx context.Context, id uint) (*models.ImportJob, error) {
+	job, err := s.importRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener importación: %v", err)
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionRead, job.MerchantID, job.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// importRecord is a row of an import file: the transaction it describes, or
+// why it could not be read.
+type importRecord struct {
+	Line    int
+	Raw     string
+	Request loyalty_requests.ProcessTransactionRequest
+	Err     error
+}
+
+// importFields holds the values of a row before they are validated.
+type importFields struct {
+	UserID      json.Number `json:"userId"`
+	BranchID    json.Number `json:"branchId"`
+	MerchantID  json.Number `json:"merchantId"`
+	Amount      json.Number `json:"amount"`
+	Date        string      `json:"date"`
+	ExternalRef string      `json:"externalRef"`
+}
+
+func formatOf(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return models.ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return models.ImportFormatJSONL
+	}
+	return ""
+}
+
+// parseImport reads every row of a file. Only an unreadable file is an error;
+// invalid rows are returned with their error.
+func parseImport(format string, data []byte) ([]importRecord, error) {
+	switch format {
+	case models.ImportFormatCSV:
+		return parseCSV(data)
+	case models.ImportFormatJSONL:
+		return parseJSONL(data)
+	}
+	return nil, ErrUnknownImportFormat
+}
+
+func parseCSV(data []byte) ([]importRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ErrInvalidImportFile.Wrap(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		for _, column := range importColumns {
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")), column) {
+				columns[column] = i
+			}
+		}
+	}
+	for _, column := range importColumns {
+		if _, ok := columns[column]; !ok && column != "merchantId" {
+			return nil, domain_errors.Validation(ErrInvalidImportFile.Code, "the file has no %s column", column)
+		}
+	}
+
+	var records []importRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, ErrInvalidImportFile.Wrap(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(fields) {
+				return ""
+			}
+			return strings.TrimSpace(fields[i])
+		}
+		record := importRecord{Line: line, Raw: strings.Join(fields, ",")}
+		record.Request, record.Err = validateImportFields(importFields{
+			UserID:      json.Number(value("userId")),
+			BranchID:    json.Number(value("branchId")),
+			MerchantID:  json.Number(value("merchantId")),
+			Amount:      json.Number(value("amount")),
+			Date:        value("date"),
+			ExternalRef: value("externalRef"),
+		})
+		records = append(records, record)
+	}
+}
+
+func parseJSONL(data []byte) ([]importRecord, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineBytes)
+
+	var records []importRecord
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		record := importRecord{Line: line, Raw: raw}
+		var fields importFields
+		decoder := json.NewDecoder(strings.NewReader(raw))
+		decoder.UseNumber()
+		err := decoder.Decode(&fields)
+		if err != nil {
+			record.Err = domain_errors.Validation("invalid_row", "the row is not a valid JSON object")
+		} else {
+			record.Request, record.Err = validateImportFields(fields)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrInvalidImportFile.Wrap(err)
+	}
+	return records, nil
+}
+
+// validateImportFields converts the values of a row into a transaction. The
+// external reference is required so that an import can be retried safely.
+func validateImportFields(fields importFields) (loyalty_requests.ProcessTransactionRequest, error) {
+	var req loyalty_requests.ProcessTransactionRequest
+
+	userID, err := strconv.ParseUint(fields.UserID.String(), 10, 32)
+	if err != nil || userID == 0 {
+		return req, domain_errors.Validation("invalid_row", "userId must be a positive integer")
+	}
+	branchID, err := strconv.ParseUint(fields.BranchID.String(), 10, 32)
+	if err != nil || branchID == 0 {
+		return req, domain_errors.Validation("invalid_row", "branchId must be a positive integer")
+	}
+	var merchantID uint64
+	if fields.MerchantID != "" {
+		merchantID, err = strconv.ParseUint(fields.MerchantID.String(), 10, 32)
+		if err != nil {
+			return req, domain_errors.Validation("invalid_row", "merchantId must be a positive integer")
+		}
+	}
+	amount, err := strconv.ParseFloat(fields.Amount.String(), 64)
+	if err != nil || amount <= 0 {
+		return req, domain_errors.Validation("invalid_row", "amount must be a number greater than 0")
+	}
+	date, err := parseImportDate(fields.Date)
+	if err != nil {
+		return req, domain_errors.Validation("invalid_row", "date must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	externalRef := strings.TrimSpace(fields.ExternalRef)
+	if externalRef == "" || len(externalRef) > maxExternalRefSize {
+		return req, domain_errors.Validation("invalid_row", "externalRef is required and must have at most %d characters", maxExternalRefSize)
+	}
+
+	req.UserID = uint(userID)
+	req.BranchID = uint(branchID)
+	req.MerchantID = uint(merchantID)
+	req.Amount = amount
+	req.Date = date
+	req.ExternalRef = &externalRef
+	return req, nil
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return date, nil
+	}
+	date, err = time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
+
+func mapImportJobToResponse(job *models.ImportJob) *loyalty_responses.ImportJobResponse {
+	return &loyalty_responses.ImportJobResponse{
+		ID:         job.ID,
+		MerchantID: job.MerchantID,
+		BranchID:   job.BranchID,
+		Format:     job.Format,
+		FileName:   job.FileName,
+		Status:     job.Status,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Succeeded:  job.Succeeded,
+		Failed:     job.Failed,
+		Duplicates: job.Duplicates,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
+
+func mapImportRowToResponse(row *models.ImportRow) *loyalty_responses.ImportRowResponse {
+	return &loyalty_responses.ImportRowResponse{
+		Line:        row.Line,
+		ExternalRef: row.ExternalRef,
+		Status:      row.Status,
+		ErrorCode:   row.ErrorCode,
+		Error:       row.Error,
+	}
+}
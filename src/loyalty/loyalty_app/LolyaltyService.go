@@ -1,20 +1,47 @@
 package loyalty_app
 
 import (
+	"context"
 	"errors"
 	"loyalty-campaigns/src/campaign/campaign_app"
+	"loyalty-campaigns/src/campaign/campaign_domain/campaign_structs/campaign_responses"
+	"loyalty-campaigns/src/challenge/challenge_app"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_structs/challenge_requests"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/metrics"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/common/utils"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_structs/loyalty_requests"
 	"loyalty-campaigns/src/merchant/merchant_app"
+	"loyalty-campaigns/src/referral/referral_app"
+	"loyalty-campaigns/src/referral/referral_domain/referral_structs/referral_requests"
 	"loyalty-campaigns/src/reward/reward_app"
 	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_requests"
+	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_responses"
+	"loyalty-campaigns/src/stamp/stamp_app"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_structs/stamp_requests"
+	"loyalty-campaigns/src/threshold/threshold_app"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_structs/threshold_requests"
 	"loyalty-campaigns/src/transaction/transaction_app"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_structs/transaction_requests"
+	"loyalty-campaigns/src/user/user_app"
 	"time"
 )
 
+// DefaultHoldTimeout is how long a redemption stays authorized by default
+// before its hold expires.
+const DefaultHoldTimeout = 15 * time.Minute
+
 type ILoyaltyService interface {
-	ProcessTransaction(userID, merchantID, branchID uint, amount float64, date time.Time) error
-	RedeemRewards(userID, merchantID uint, amount float64, rewardType string) error
+	ProcessTransaction(ctx context.Context, req loyalty_requests.ProcessTransactionRequest) error
+	RedeemRewards(ctx context.Context, userID, merchantID uint, amount float64, rewardType string) error
+	// AuthorizeRedemption holds the amount from the user's available balance
+	// until it is captured or voided, or the hold expires.
+	AuthorizeRedemption(ctx context.Context, req loyalty_requests.AuthorizeRedemptionRequest) (*reward_responses.HoldResponse, error)
+	GetRedemption(ctx context.Context, holdID uint) (*reward_responses.HoldResponse, error)
+	CaptureRedemption(ctx context.Context, holdID uint, req loyalty_requests.CaptureRedemptionRequest) (*reward_responses.HoldResponse, error)
+	VoidRedemption(ctx context.Context, holdID uint) (*reward_responses.HoldResponse, error)
 }
 
 type loyaltyService struct {
@@ -22,6 +49,13 @@ type loyaltyService struct {
 	campaignService    campaign_app.ICampaignService
 	rewardService      reward_app.IRewardService
 	merchantService    merchant_app.IMerchantService
+	userService        user_app.IUserService
+	referralService    referral_app.IReferralService
+	stampService       stamp_app.IStampService
+	thresholdService   threshold_app.IThresholdService
+	challengeService   challenge_app.IChallengeService
+	unitOfWork         configs.IUnitOfWork
+	holdTimeout        time.Duration
 	logger             utils.ILogger
 }
 
@@ -30,108 +64,320 @@ func NewLoyaltyService(
 	campaignService campaign_app.ICampaignService,
 	rewardService reward_app.IRewardService,
 	merchantService merchant_app.IMerchantService,
+	userService user_app.IUserService,
+	referralService referral_app.IReferralService,
+	stampService stamp_app.IStampService,
+	thresholdService threshold_app.IThresholdService,
+	challengeService challenge_app.IChallengeService,
+	unitOfWork configs.IUnitOfWork,
+	holdTimeout time.Duration,
 ) ILoyaltyService {
 	return &loyaltyService{
 		transactionService: transactionService,
 		campaignService:    campaignService,
 		rewardService:      rewardService,
 		merchantService:    merchantService,
+		userService:        userService,
+		referralService:    referralService,
+		stampService:       stampService,
+		thresholdService:   thresholdService,
+		challengeService:   challengeService,
+		unitOfWork:         unitOfWork,
+		holdTimeout:        holdTimeout,
 		logger:             utils.NewLogger(),
 	}
 }
 
-func (s *loyaltyService) ProcessTransaction(userID, merchantID, branchID uint, amount float64, date time.Time) error {
+// ProcessTransaction records the transaction and awards its rewards. A zero
+// merchant is taken from the branch; otherwise the branch must belong to it.
+// The transaction service validates the user, branch and merchant, rejects
+// external references already processed, and checks that the caller may
+// operate the branch. Stamp-card campaigns give a stamp instead of a reward,
+// spend-threshold campaigns add the amount to the user's spend towards their
+// bonus, and the base reward is granted when no multiplier campaign is
+// active. The transaction is then evaluated on the merchant's challenges. The
+// first qualifying transaction of a referred user also grants the referral
+// bonuses. All of it runs in a single unit of work, so a transaction is never
+// left recorded without its rewards: a failure rolls it back and it can be
+// sent again with the same external reference.
+func (s *loyaltyService) ProcessTransaction(ctx context.Context, req loyalty_requests.ProcessTransactionRequest) error {
+	var merchantID uint
+	err := s.unitOfWork.Run(ctx, func(ctx context.Context) error {
+		var err error
+		merchantID, err = s.processTransaction(ctx, req)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.TransactionsProcessed.WithLabelValues(metrics.ID(merchantID)).Inc()
+	return nil
+}
+
+// processTransaction applies the steps of ProcessTransaction and returns the
+// merchant of the transaction.
+func (s *loyaltyService) processTransaction(ctx context.Context, req loyalty_requests.ProcessTransactionRequest) (uint, error) {
+	userID, branchID, amount, date := req.UserID, req.BranchID, req.Amount, req.Date
+
 	// 1. Crear la transacción
-	_, err := s.transactionService.CreateTransaction(transaction_requests.CreateTransactionRequest{
-		UserID:   userID,
-		BranchID: branchID,
-		Amount:   amount,
-		Date:     date,
+	transaction, err := s.transactionService.CreateTransaction(ctx, transaction_requests.CreateTransactionRequest{
+		UserID:      userID,
+		BranchID:    branchID,
+		MerchantID:  req.MerchantID,
+		ExternalRef: req.ExternalRef,
+		Amount:      amount,
+		Date:        date,
 	})
+	if errors.Is(err, transaction_app.ErrDuplicateTransaction) {
+		return 0, err
+	}
 	if err != nil {
 		s.logger.Error("Error al crear transacción", err)
-		return err
+		return 0, err
+	}
+	merchantID := transaction.MerchantID
+
+	// Inscribir al usuario en el programa del comercio
+	err = s.userService.EnrollUser(ctx, userID, merchantID, transaction.BranchID)
+	if err != nil {
+		s.logger.Error("Error al inscribir usuario", err)
+		return 0, err
 	}
 
 	// Obtener el merchant
-	merchant, err := s.merchantService.GetMerchant(merchantID)
+	merchant, err := s.merchantService.GetMerchant(ctx, merchantID)
 	if err != nil {
 		s.logger.Error("Error al obtener merchant", err)
-		return err
+		return 0, err
 	}
 
 	// Calcular recompensa base
 	baseReward := amount * merchant.ConversionFactor
 
+	// Vencimiento de las recompensas según la vigencia configurada por el comercio
+	var expiryDate *time.Time
+	if merchant.RewardValidityDays != nil {
+		expiry := date.AddDate(0, 0, *merchant.RewardValidityDays)
+		expiryDate = &expiry
+	}
+
+	// Las recompensas quedan pendientes durante el plazo de devolución del comercio
+	var vestsAt *time.Time
+	if merchant.RewardVestingDays != nil {
+		vesting := date.AddDate(0, 0, *merchant.RewardVestingDays)
+		vestsAt = &vesting
+	}
+
 	// Obtener campañas activas
-	activeCampaigns, err := s.campaignService.GetActiveCampaigns(merchantID, &branchID, date)
+	activeCampaigns, err := s.campaignService.GetActiveCampaigns(ctx, merchantID, &branchID, date)
 	if err != nil {
 		s.logger.Error("Error al obtener campañas activas", err)
-		return err
+		return 0, err
+	}
+
+	// Separar las campañas de tarjetas de sellos y de monto acumulado
+	var multiplierCampaigns []campaign_responses.CampaignResponse
+	var stampCampaignIDs, thresholdCampaignIDs []uint
+	for _, campaign := range activeCampaigns {
+		qualifies := campaign.MinAmount == nil || amount >= *campaign.MinAmount
+		switch {
+		case campaign.Kind == models.CampaignStampCard:
+			if qualifies {
+				stampCampaignIDs = append(stampCampaignIDs, campaign.ID)
+			}
+		case campaign.Kind == models.CampaignSpendThreshold:
+			if qualifies {
+				thresholdCampaignIDs = append(thresholdCampaignIDs, campaign.ID)
+			}
+		default:
+			multiplierCampaigns = append(multiplierCampaigns, campaign)
+		}
 	}
 
 	// Procesar recompensas
-	if len(activeCampaigns) > 0 {
+	if len(multiplierCampaigns) > 0 {
 		// Hay campañas activas
-		for _, campaign := range activeCampaigns {
+		for _, campaign := range multiplierCampaigns {
 			if campaign.MinAmount == nil || amount >= *campaign.MinAmount {
 				finalReward := baseReward * campaign.Value
-				_, err = s.rewardService.CreateReward(reward_requests.CreateRewardRequest{
-					UserID:     userID,
-					MerchantID: merchantID,
-					Type:       campaign.Type,
-					Amount:     finalReward,
+				_, err = s.rewardService.CreateReward(ctx, reward_requests.CreateRewardRequest{
+					UserID:        userID,
+					MerchantID:    merchantID,
+					Type:          campaign.Type,
+					Amount:        finalReward,
+					ExpiryDate:    expiryDate,
+					CampaignID:    &campaign.ID,
+					TransactionID: &transaction.ID,
+					BranchID:      &transaction.BranchID,
+					VestsAt:       vestsAt,
 				})
 				if err != nil {
 					s.logger.Error("Error al crear recompensa de campaña", err)
-					return err
+					return 0, err
 				}
+				configs.AfterCommit(ctx, func() {
+					metrics.CampaignHits.WithLabelValues(metrics.ID(campaign.ID), metrics.ID(merchantID)).Inc()
+				})
 			}
 		}
 	} else {
 		// No hay campañas activas, otorgar la recompensa base según el tipo predeterminado del merchant
-		_, err = s.rewardService.CreateReward(reward_requests.CreateRewardRequest{
-			UserID:     userID,
-			MerchantID: merchantID,
-			Type:       merchant.DefaultRewardType,
-			Amount:     baseReward,
+		_, err = s.rewardService.CreateReward(ctx, reward_requests.CreateRewardRequest{
+			UserID:        userID,
+			MerchantID:    merchantID,
+			Type:          merchant.DefaultRewardType,
+			Amount:        baseReward,
+			ExpiryDate:    expiryDate,
+			TransactionID: &transaction.ID,
+			BranchID:      &transaction.BranchID,
+			VestsAt:       vestsAt,
 		})
 		if err != nil {
 			s.logger.Error("Error al crear recompensa base", err)
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	// Agregar los sellos de las tarjetas
+	if len(stampCampaignIDs) > 0 {
+		_, err = s.stampService.AddStamps(ctx, stamp_requests.AddStampsRequest{
+			TransactionID: transaction.ID,
+			UserID:        userID,
+			MerchantID:    merchantID,
+			BranchID:      transaction.BranchID,
+			Date:          date,
+			CampaignIDs:   stampCampaignIDs,
+		})
+		if err != nil {
+			s.logger.Error("Error al agregar sellos", err)
+			return 0, err
+		}
+	}
+
+	// Otorgar los bonos de las campañas cuyo monto acumulado se alcanza
+	if len(thresholdCampaignIDs) > 0 {
+		_, err = s.thresholdService.TrackSpend(ctx, threshold_requests.TrackSpendRequest{
+			TransactionID: transaction.ID,
+			UserID:        userID,
+			MerchantID:    merchantID,
+			BranchID:      transaction.BranchID,
+			Date:          date,
+			CampaignIDs:   thresholdCampaignIDs,
+		})
+		if err != nil {
+			s.logger.Error("Error al acumular monto de campañas", err)
+			return 0, err
+		}
+	}
+
+	// Evaluar los desafíos del comercio
+	_, err = s.challengeService.EvaluateChallenges(ctx, challenge_requests.EvaluateChallengesRequest{
+		TransactionID: transaction.ID,
+		UserID:        userID,
+		MerchantID:    merchantID,
+		BranchID:      transaction.BranchID,
+		Amount:        amount,
+		Date:          date,
+	})
+	if err != nil {
+		s.logger.Error("Error al evaluar desafíos", err)
+		return 0, err
+	}
+
+	// Otorgar los bonos de referido si es la primera transacción calificada del usuario
+	_, err = s.referralService.QualifyReferral(ctx, referral_requests.QualifyReferralRequest{
+		TransactionID: transaction.ID,
+		UserID:        userID,
+		MerchantID:    merchantID,
+		BranchID:      transaction.BranchID,
+		Amount:        amount,
+		Date:          date,
+	})
+	if err != nil {
+		s.logger.Error("Error al calificar referido", err)
+		return 0, err
+	}
+
+	return merchantID, nil
 }
 
-func (s *loyaltyService) RedeemRewards(userID, merchantID uint, amount float64, rewardType string) error {
+// RedeemRewards takes place at the branch of a branch operator.
+func (s *loyaltyService) RedeemRewards(ctx context.Context, userID, merchantID uint, amount float64, rewardType string) error {
+	err := security.Authorize(ctx, security.ActionOperate, merchantID, security.OperatorBranch(ctx, nil))
+	if err != nil {
+		return err
+	}
+
 	// 1. Get user's rewards
-	rewards, err := s.rewardService.ListRewardsByUser(userID)
+	rewards, err := s.rewardService.ListRewardsByUser(ctx, userID)
 	if err != nil {
 		s.logger.Error("Error getting user rewards", err)
 		return err
 	}
 
-	// 2. Calculate total available rewards
+	// 2. Calculate total available rewards, leaving out the pending ones
 	var totalRewards float64
 	for _, reward := range rewards {
-		if reward.MerchantID == merchantID && reward.Type == rewardType {
+		if reward.MerchantID == merchantID && reward.Type == rewardType && reward.VestsAt == nil {
 			totalRewards += reward.Amount
 		}
 	}
 
 	// 3. Check if user has enough rewards
 	if totalRewards < amount {
-		return errors.New("insufficient rewards")
+		metrics.InsufficientBalanceRejections.WithLabelValues(rewardType, metrics.ID(merchantID)).Inc()
+		return reward_app.ErrInsufficientBalance
 	}
 
-	// 4. Deduct rewards
-	err = s.rewardService.DeductRewards(userID, merchantID, amount, rewardType)
+	// 4. Deduct rewards, which fails if part of them is held
+	err = s.rewardService.DeductRewards(ctx, userID, merchantID, amount, rewardType)
+	if errors.Is(err, reward_app.ErrInsufficientBalance) {
+		metrics.InsufficientBalanceRejections.WithLabelValues(rewardType, metrics.ID(merchantID)).Inc()
+		return err
+	}
 	if err != nil {
 		s.logger.Error("Error deducting rewards", err)
 		return err
 	}
 
+	metrics.Redemptions.WithLabelValues(rewardType, metrics.ID(merchantID)).Inc()
+
 	return nil
 }
+
+func (s *loyaltyService) AuthorizeRedemption(ctx context.Context, req loyalty_requests.AuthorizeRedemptionRequest) (*reward_responses.HoldResponse, error) {
+	hold, err := s.rewardService.HoldRewards(ctx, reward_requests.HoldRewardsRequest{
+		UserID:     req.UserID,
+		MerchantID: req.MerchantID,
+		BranchID:   req.BranchID,
+		Type:       req.RewardType,
+		Amount:     req.Amount,
+		ExpiresAt:  time.Now().Add(s.holdTimeout),
+	})
+	if errors.Is(err, reward_app.ErrInsufficientBalance) {
+		metrics.InsufficientBalanceRejections.WithLabelValues(req.RewardType, metrics.ID(req.MerchantID)).Inc()
+	}
+	return hold, err
+}
+
+func (s *loyaltyService) GetRedemption(ctx context.Context, holdID uint) (*reward_responses.HoldResponse, error) {
+	return s.rewardService.GetHold(ctx, holdID)
+}
+
+// CaptureRedemption redeems the held amount, or part of it, as RedeemRewards
+// does.
+func (s *loyaltyService) CaptureRedemption(ctx context.Context, holdID uint, req loyalty_requests.CaptureRedemptionRequest) (*reward_responses.HoldResponse, error) {
+	hold, err := s.rewardService.CaptureHold(ctx, holdID, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.Redemptions.WithLabelValues(hold.Type, metrics.ID(hold.MerchantID)).Inc()
+
+	return hold, nil
+}
+
+func (s *loyaltyService) VoidRedemption(ctx context.Context, holdID uint) (*reward_responses.HoldResponse, error) {
+	return s.rewardService.VoidHold(ctx, holdID)
+}
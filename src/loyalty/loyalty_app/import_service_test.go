@@ -0,0 +1,322 @@
+package loyalty_app_test
+
+import (
+	"context"
+	"encoding/csv"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/loyalty/loyalty_app"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_ports"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_structs/loyalty_requests"
+	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_responses"
+	"loyalty-campaigns/src/transaction/transaction_app"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ImportService", func() {
+	const csvFile = "userId,branchId,amount,date,externalRef\n" +
+		"1,3,100,2024-05-01,POS-1\n" +
+		"1,3,50.5,2024-05-01T10:00:00Z,POS-2\n" +
+		"1,3,-10,2024-05-01,POS-3\n"
+
+	var (
+		importService loyalty_app.IImportService
+		mockImports   *mockImportRepository
+		mockLoyalty   *mockLoyaltyService
+		merchantID    uint
+		ctx           context.Context
+	)
+
+	BeforeEach(func() {
+		mockImports = new(mockImportRepository)
+		mockLoyalty = new(mockLoyaltyService)
+		importService = loyalty_app.NewImportService(mockImports, mockLoyalty, fakeUnitOfWork{}, 1024)
+		merchantID = 2
+		ctx = security.WithPrincipal(context.Background(), &security.Principal{Role: security.RoleMerchantAdmin, MerchantID: merchantID})
+	})
+
+	Describe("CreateImport", func() {
+		It("should queue the file with its number of rows", func() {
+			var created *models.ImportJob
+			mockImports.On("Create", mock.Anything, mock.AnythingOfType("*models.ImportJob")).
+				Run(func(args mock.Arguments) { created = args.Get(1).(*models.ImportJob) }).
+				Return(nil)
+
+			response, err := importService.CreateImport(ctx, loyalty_requests.CreateImportRequest{MerchantID: merchantID}, "sales.csv", strings.NewReader(csvFile))
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.ImportJobPending))
+			Expect(response.Total).To(Equal(3))
+			Expect(created.Format).To(Equal(models.ImportFormatCSV))
+			Expect(created.FileName).To(Equal("sales.csv"))
+			Expect(string(created.Content)).To(Equal(csvFile))
+		})
+
+		It("should reject a CSV file without a required column", func() {
+			_, err := importService.CreateImport(ctx, loyalty_requests.CreateImportRequest{MerchantID: merchantID}, "sales.csv", strings.NewReader("userId,branchId,amount,date\n1,3,100,2024-05-01\n"))
+
+			Expect(err).To(MatchError(loyalty_app.ErrInvalidImportFile))
+			mockImports.AssertNotCalled(GinkgoT(), "Create")
+		})
+
+		It("should reject a file over the size limit", func() {
+			_, err := importService.CreateImport(ctx, loyalty_requests.CreateImportRequest{MerchantID: merchantID}, "sales.csv", strings.NewReader(csvFile+strings.Repeat("1,3,100,2024-05-01,POS\n", 100)))
+
+			Expect(err).To(MatchError(loyalty_app.ErrImportTooLarge))
+		})
+
+		It("should reject a file of unknown format", func() {
+			_, err := importService.CreateImport(ctx, loyalty_requests.CreateImportRequest{MerchantID: merchantID}, "sales.xlsx", strings.NewReader(csvFile))
+
+			Expect(err).To(MatchError(loyalty_app.ErrUnknownImportFormat))
+		})
+
+		It("should reject an import for another merchant", func() {
+			_, err := importService.CreateImport(ctx, loyalty_requests.CreateImportRequest{MerchantID: merchantID + 1}, "sales.csv", strings.NewReader(csvFile))
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockImports.AssertNotCalled(GinkgoT(), "Create")
+		})
+	})
+
+	Describe("ProcessPending", func() {
+		var (
+			job          *models.ImportJob
+			saved        []models.ImportRow
+			saveProgress *mock.Call
+		)
+
+		BeforeEach(func() {
+			saved = nil
+			job = &models.ImportJob{
+				MerchantID: merchantID,
+				Format:     models.ImportFormatCSV,
+				Content:    []byte(csvFile),
+				Status:     models.ImportJobProcessing,
+				Total:      3,
+			}
+			job.ID = 7
+			mockImports.On("ClaimJob", mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Duration")).Return(job, nil).Once()
+			mockImports.On("ClaimJob", mock.Anything, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Duration")).Return(nil, nil)
+			saveProgress = mockImports.On("SaveProgress", mock.Anything, job, mock.Anything).
+				Run(func(args mock.Arguments) { saved = append(saved, args.Get(2).([]models.ImportRow)...) }).
+				Return(nil)
+		})
+
+		It("should process every row on behalf of the merchant and record the results", func() {
+			merchantScoped := mock.MatchedBy(func(ctx context.Context) bool {
+				principal, ok := security.PrincipalFromContext(ctx)
+				return ok && !principal.IsAdmin() && principal.MerchantID == merchantID
+			})
+			mockLoyalty.On("ProcessTransaction", merchantScoped, mock.MatchedBy(func(req loyalty_requests.ProcessTransactionRequest) bool {
+				return *req.ExternalRef == "POS-1"
+			})).Return(nil)
+			mockLoyalty.On("ProcessTransaction", merchantScoped, mock.MatchedBy(func(req loyalty_requests.ProcessTransactionRequest) bool {
+				return *req.ExternalRef == "POS-2"
+			})).Return(transaction_app.ErrDuplicateTransaction)
+
+			processed, err := importService.ProcessPending(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(processed).To(Equal(3))
+			mockLoyalty.AssertNumberOfCalls(GinkgoT(), "ProcessTransaction", 2)
+			mockLoyalty.AssertCalled(GinkgoT(), "ProcessTransaction", mock.Anything, loyalty_requests.ProcessTransactionRequest{
+				UserID:      1,
+				MerchantID:  merchantID,
+				BranchID:    3,
+				Amount:      100,
+				Date:        time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+				ExternalRef: ptr("POS-1"),
+			})
+
+			Expect(saved).To(HaveLen(3))
+			Expect(saved[0]).To(haveImportRow(2, models.ImportRowProcessed, ""))
+			Expect(saved[1]).To(haveImportRow(3, models.ImportRowDuplicate, "transaction_already_exists"))
+			Expect(saved[2]).To(haveImportRow(4, models.ImportRowFailed, "invalid_row"))
+
+			Expect(job.Status).To(Equal(models.ImportJobCompleted))
+			Expect(job.FinishedAt).NotTo(BeNil())
+			Expect(job.Processed).To(Equal(3))
+			Expect(job.Succeeded).To(Equal(1))
+			Expect(job.Duplicates).To(Equal(1))
+			Expect(job.Failed).To(Equal(1))
+		})
+
+		It("should save each row in the unit of work that processes it", func() {
+			mockLoyalty.On("ProcessTransaction", inUnitOfWork, mock.Anything).Return(nil)
+
+			_, err := importService.ProcessPending(context.Background())
+
+			Expect(err).To(BeNil())
+			mockLoyalty.AssertNumberOfCalls(GinkgoT(), "ProcessTransaction", 2)
+			for _, line := range []int{2, 3, 4} {
+				mockImports.AssertCalled(GinkgoT(), "SaveProgress", inUnitOfWork, job, mock.MatchedBy(func(rows []models.ImportRow) bool {
+					return len(rows) == 1 && rows[0].Line == line
+				}))
+			}
+		})
+
+		It("should stop at the row whose outcome could not be saved", func() {
+			saveProgress.Unset()
+			mockImports.On("SaveProgress", mock.Anything, job, mock.MatchedBy(func(rows []models.ImportRow) bool {
+				return len(rows) == 1 && rows[0].Line == 3
+			})).Return(domain_errors.ErrConflict)
+			mockImports.On("SaveProgress", mock.Anything, job, mock.Anything).Return(nil)
+			mockLoyalty.On("ProcessTransaction", mock.Anything, mock.Anything).Return(nil)
+
+			processed, err := importService.ProcessPending(context.Background())
+
+			Expect(err).To(MatchError(domain_errors.ErrConflict))
+			Expect(processed).To(Equal(1))
+			mockLoyalty.AssertNumberOfCalls(GinkgoT(), "ProcessTransaction", 2)
+		})
+
+		It("should resume after the rows already processed", func() {
+			job.Processed = 2
+			job.Succeeded = 2
+
+			processed, err := importService.ProcessPending(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(processed).To(Equal(1))
+			mockLoyalty.AssertNotCalled(GinkgoT(), "ProcessTransaction", mock.Anything, mock.Anything)
+			Expect(saved).To(HaveLen(1))
+			Expect(saved[0].Line).To(Equal(4))
+			Expect(job.Status).To(Equal(models.ImportJobCompleted))
+		})
+	})
+
+	Describe("ErrorReport", func() {
+		It("should list the rows that were not processed as CSV", func() {
+			mockImports.On("GetByID", mock.Anything, uint(7)).Return(&models.ImportJob{MerchantID: merchantID}, nil)
+			mockImports.On("ListUnprocessedRows", mock.Anything, uint(7)).Return([]models.ImportRow{
+				{Line: 4, ExternalRef: "POS-3", Status: models.ImportRowFailed, ErrorCode: "invalid_row", Error: "amount must be a number greater than 0", Raw: "1,3,-10,2024-05-01,POS-3"},
+			}, nil)
+
+			report, err := importService.ErrorReport(ctx, 7)
+
+			Expect(err).To(BeNil())
+			records, err := csv.NewReader(strings.NewReader(string(report))).ReadAll()
+			Expect(err).To(BeNil())
+			Expect(records).To(Equal([][]string{
+				{"line", "externalRef", "status", "errorCode", "error", "row"},
+				{"4", "POS-3", "failed", "invalid_row", "amount must be a number greater than 0", "1,3,-10,2024-05-01,POS-3"},
+			}))
+		})
+
+		It("should not show the report of another merchant", func() {
+			mockImports.On("GetByID", mock.Anything, uint(7)).Return(&models.ImportJob{MerchantID: merchantID + 1}, nil)
+
+			_, err := importService.ErrorReport(ctx, 7)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockImports.AssertNotCalled(GinkgoT(), "ListUnprocessedRows", mock.Anything, mock.Anything)
+		})
+	})
+})
+
+// haveImportRow matches the line, status and error code of an import row.
+func haveImportRow(line int, status, errorCode string) OmegaMatcher {
+	return And(
+		HaveField("Line", line),
+		HaveField("Status", status),
+		HaveField("ErrorCode", errorCode),
+	)
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+type mockImportRepository struct {
+	mock.Mock
+}
+
+func (m *mockImportRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *mockImportRepository) GetByID(ctx context.Context, id uint) (*models.ImportJob, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportJob), args.Error(1)
+}
+
+func (m *mockImportRepository) ClaimJob(ctx context.Context, now time.Time, lease time.Duration) (*models.ImportJob, error) {
+	args := m.Called(ctx, now, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportJob), args.Error(1)
+}
+
+func (m *mockImportRepository) SaveProgress(ctx context.Context, job *models.ImportJob, rows []models.ImportRow) error {
+	args := m.Called(ctx, job, rows)
+	return args.Error(0)
+}
+
+func (m *mockImportRepository) ListRows(ctx context.Context, filter loyalty_ports.ImportRowFilter, page pagination.Request) (*pagination.Page[models.ImportRow], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(*pagination.Page[models.ImportRow]), args.Error(1)
+}
+
+func (m *mockImportRepository) ListUnprocessedRows(ctx context.Context, jobID uint) ([]models.ImportRow, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).([]models.ImportRow), args.Error(1)
+}
+
+type mockLoyaltyService struct {
+	mock.Mock
+}
+
+func (m *mockLoyaltyService) ProcessTransaction(ctx context.Context, req loyalty_requests.ProcessTransactionRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *mockLoyaltyService) RedeemRewards(ctx context.Context, userID, merchantID uint, amount float64, rewardType string) error {
+	args := m.Called(ctx, userID, merchantID, amount, rewardType)
+	return args.Error(0)
+}
+
+func (m *mockLoyaltyService) AuthorizeRedemption(ctx context.Context, req loyalty_requests.AuthorizeRedemptionRequest) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
+
+func (m *mockLoyaltyService) GetRedemption(ctx context.Context, holdID uint) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, holdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
+
+func (m *mockLoyaltyService) CaptureRedemption(ctx context.Context, holdID uint, req loyalty_requests.CaptureRedemptionRequest) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, holdID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
+
+func (m *mockLoyaltyService) VoidRedemption(ctx context.Context, holdID uint) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, holdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
@@ -1,20 +1,46 @@
 package loyalty_app_test
 
 import (
+	"context"
+	"io"
 	"loyalty-campaigns/src/campaign/campaign_domain/campaign_structs/campaign_requests"
 	"loyalty-campaigns/src/campaign/campaign_domain/campaign_structs/campaign_responses"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_structs/challenge_requests"
+	"loyalty-campaigns/src/challenge/challenge_domain/challenge_structs/challenge_responses"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/metrics"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/loyalty/loyalty_app"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_structs/loyalty_requests"
 	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_requests"
 	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_responses"
+	"loyalty-campaigns/src/referral/referral_domain/referral_structs/referral_requests"
+	"loyalty-campaigns/src/referral/referral_domain/referral_structs/referral_responses"
+	"loyalty-campaigns/src/reward/reward_app"
 	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_requests"
 	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_responses"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_structs/stamp_requests"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_structs/stamp_responses"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_structs/threshold_requests"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_structs/threshold_responses"
+	"loyalty-campaigns/src/transaction/transaction_app"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_structs/transaction_requests"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_structs/transaction_responses"
+	"loyalty-campaigns/src/user/user_domain/user_structs/user_requests"
+	"loyalty-campaigns/src/user/user_domain/user_structs/user_responses"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var _ = Describe("LoyaltyService", func() {
@@ -24,6 +50,12 @@ var _ = Describe("LoyaltyService", func() {
 		mockMerchant    *mockMerchantService
 		mockCampaign    *mockCampaignService
 		mockReward      *mockRewardService
+		mockUser        *mockUserService
+		mockReferral    *mockReferralService
+		mockStamp       *mockStampService
+		mockThreshold   *mockThresholdService
+		mockChallenge   *mockChallengeService
+		ctx             context.Context
 		userID          uint
 		merchantID      uint
 		branchID        uint
@@ -36,14 +68,29 @@ var _ = Describe("LoyaltyService", func() {
 		mockMerchant = new(mockMerchantService)
 		mockCampaign = new(mockCampaignService)
 		mockReward = new(mockRewardService)
+		mockUser = new(mockUserService)
+		mockReferral = new(mockReferralService)
+		mockStamp = new(mockStampService)
+		mockThreshold = new(mockThresholdService)
+		mockChallenge = new(mockChallengeService)
+		mockChallenge.On("EvaluateChallenges", mock.Anything, mock.AnythingOfType("challenge_requests.EvaluateChallengesRequest")).Return([]challenge_responses.ChallengeProgressResponse{}, nil).Maybe()
 
 		loyaltyService = loyalty_app.NewLoyaltyService(
 			mockTransaction,
 			mockCampaign,
 			mockReward,
 			mockMerchant,
+			mockUser,
+			mockReferral,
+			mockStamp,
+			mockThreshold,
+			mockChallenge,
+			fakeUnitOfWork{},
+			10*time.Minute,
 		)
 
+		ctx = security.WithPrincipal(context.Background(), security.System())
+
 		userID = 1
 		merchantID = 2
 		branchID = 3
@@ -54,60 +101,474 @@ var _ = Describe("LoyaltyService", func() {
 	Describe("ProcessTransaction", func() {
 		Context("When there are no active campaigns", func() {
 			BeforeEach(func() {
-				mockTransaction.On("CreateTransaction", mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{}, nil)
-				mockMerchant.On("GetMerchant", merchantID).Return(&merchant_responses.MerchantResponse{
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
 					ID:                merchantID,
 					ConversionFactor:  0.1,
 					DefaultRewardType: "points",
 				}, nil)
-				mockCampaign.On("GetActiveCampaigns", merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{}, nil)
-				mockReward.On("CreateReward", mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), nil)
 			})
 
 			It("should process the transaction and create a default reward", func() {
-				err := loyaltyService.ProcessTransaction(userID, merchantID, branchID, amount, date)
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
 
 				Expect(err).To(BeNil())
 				mockTransaction.AssertExpectations(GinkgoT())
 				mockMerchant.AssertExpectations(GinkgoT())
 				mockCampaign.AssertExpectations(GinkgoT())
 				mockReward.AssertExpectations(GinkgoT())
+				mockUser.AssertExpectations(GinkgoT())
+
+				mockReward.AssertCalled(GinkgoT(), "CreateReward", mock.Anything, reward_requests.CreateRewardRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					Type:          "points",
+					Amount:        10.0, // 100 * 0.1
+					TransactionID: ptr(uint(9)),
+					BranchID:      &branchID,
+				})
+			})
+
+			It("should record the transaction and its rewards in a single unit of work", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(BeNil())
+				mockTransaction.AssertCalled(GinkgoT(), "CreateTransaction", inUnitOfWork, mock.Anything)
+				mockUser.AssertCalled(GinkgoT(), "EnrollUser", inUnitOfWork, userID, merchantID, branchID)
+				mockReward.AssertCalled(GinkgoT(), "CreateReward", inUnitOfWork, mock.Anything)
+				mockReferral.AssertCalled(GinkgoT(), "QualifyReferral", inUnitOfWork, mock.Anything)
+			})
 
-				mockReward.AssertCalled(GinkgoT(), "CreateReward", reward_requests.CreateRewardRequest{
+			It("should qualify the referral of the user with the transaction", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
 					UserID:     userID,
 					MerchantID: merchantID,
-					Type:       "points",
-					Amount:     10.0, // 100 * 0.1
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(BeNil())
+				mockReferral.AssertCalled(GinkgoT(), "QualifyReferral", mock.Anything, referral_requests.QualifyReferralRequest{
+					TransactionID: 9,
+					UserID:        userID,
+					MerchantID:    merchantID,
+					BranchID:      branchID,
+					Amount:        amount,
+					Date:          date,
 				})
 			})
 		})
 
 		Context("When there is an active campaign", func() {
 			BeforeEach(func() {
-				mockTransaction.On("CreateTransaction", mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{}, nil)
-				mockMerchant.On("GetMerchant", merchantID).Return(&merchant_responses.MerchantResponse{
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
 					ID:                merchantID,
 					ConversionFactor:  0.1,
 					DefaultRewardType: "points",
 				}, nil)
-				mockCampaign.On("GetActiveCampaigns", merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{
 					{
+						ID:    4,
 						Type:  "points",
 						Value: 2.0,
 					},
 				}, nil)
-				mockReward.On("CreateReward", mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), nil)
 			})
 
 			It("should process the transaction and create a campaign reward", func() {
-				err := loyaltyService.ProcessTransaction(userID, merchantID, branchID, amount, date)
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(BeNil())
+				mockReward.AssertCalled(GinkgoT(), "CreateReward", mock.Anything, reward_requests.CreateRewardRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					Type:          "points",
+					Amount:        20.0, // (100 * 0.1) * 2
+					CampaignID:    ptr(uint(4)),
+					TransactionID: ptr(uint(9)),
+					BranchID:      &branchID,
+				})
+			})
+
+			Describe("the campaign hits", func() {
+				var sqlMock sqlmock.Sqlmock
+
+				hits := func() float64 {
+					return testutil.ToFloat64(metrics.CampaignHits.WithLabelValues("4", "2"))
+				}
+
+				process := func() error {
+					return loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+						UserID:     userID,
+						MerchantID: merchantID,
+						BranchID:   branchID,
+						Amount:     amount,
+						Date:       date,
+					})
+				}
+
+				BeforeEach(func() {
+					sqlDB, mock, err := sqlmock.New()
+					Expect(err).To(BeNil())
+					db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+						Logger: logger.Default.LogMode(logger.Silent),
+					})
+					Expect(err).To(BeNil())
+					sqlMock = mock
+					sqlMock.ExpectBegin()
+
+					loyaltyService = loyalty_app.NewLoyaltyService(
+						mockTransaction,
+						mockCampaign,
+						mockReward,
+						mockMerchant,
+						mockUser,
+						mockReferral,
+						mockStamp,
+						mockThreshold,
+						mockChallenge,
+						configs.NewUnitOfWork(db),
+						10*time.Minute,
+					)
+				})
+
+				AfterEach(func() {
+					Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+				})
+
+				It("should be counted once the transaction commits", func() {
+					sqlMock.ExpectCommit()
+					before := hits()
+
+					Expect(process()).To(Succeed())
+
+					Expect(hits()).To(Equal(before + 1))
+				})
+
+				It("should not be counted when the transaction rolls back", func() {
+					sqlMock.ExpectRollback()
+					mockReferral.ExpectedCalls = nil
+					mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), domain_errors.ErrForbidden)
+					before := hits()
+
+					Expect(process()).To(MatchError(domain_errors.ErrForbidden))
+
+					mockReward.AssertCalled(GinkgoT(), "CreateReward", mock.Anything, mock.Anything)
+					Expect(hits()).To(Equal(before))
+				})
+			})
+		})
+
+		Context("When the merchant is omitted", func() {
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, transaction_requests.CreateTransactionRequest{
+					UserID:   userID,
+					BranchID: branchID,
+					Amount:   amount,
+					Date:     date,
+				}).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
+					ID:                merchantID,
+					ConversionFactor:  0.1,
+					DefaultRewardType: "points",
+				}, nil)
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), nil)
+			})
+
+			It("should award the rewards of the merchant of the branch", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:   userID,
+					BranchID: branchID,
+					Amount:   amount,
+					Date:     date,
+				})
+
+				Expect(err).To(BeNil())
+				mockUser.AssertExpectations(GinkgoT())
+				mockReward.AssertCalled(GinkgoT(), "CreateReward", mock.Anything, reward_requests.CreateRewardRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					Type:          "points",
+					Amount:        10.0,
+					TransactionID: ptr(uint(9)),
+					BranchID:      &branchID,
+				})
+			})
+		})
+
+		Context("When the branch belongs to another merchant", func() {
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return((*transaction_responses.TransactionResponse)(nil), transaction_app.ErrBranchMerchantMismatch)
+			})
+
+			It("should reject the transaction without awarding rewards", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(MatchError(transaction_app.ErrBranchMerchantMismatch))
+				Expect(err).To(MatchError(domain_errors.ErrValidation))
+				mockUser.AssertNotCalled(GinkgoT(), "EnrollUser")
+				mockReward.AssertNotCalled(GinkgoT(), "CreateReward")
+			})
+		})
+
+		Context("When the external reference was already processed", func() {
+			externalRef := "POS-0001"
+
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, transaction_requests.CreateTransactionRequest{
+					UserID:      userID,
+					BranchID:    branchID,
+					MerchantID:  merchantID,
+					ExternalRef: &externalRef,
+					Amount:      amount,
+					Date:        date,
+				}).Return((*transaction_responses.TransactionResponse)(nil), transaction_app.ErrDuplicateTransaction)
+			})
+
+			It("should reject the transaction without awarding rewards", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:      userID,
+					MerchantID:  merchantID,
+					BranchID:    branchID,
+					ExternalRef: &externalRef,
+					Amount:      amount,
+					Date:        date,
+				})
+
+				Expect(err).To(MatchError(transaction_app.ErrDuplicateTransaction))
+				Expect(err).To(MatchError(domain_errors.ErrConflict))
+				mockUser.AssertNotCalled(GinkgoT(), "EnrollUser")
+				mockReward.AssertNotCalled(GinkgoT(), "CreateReward")
+			})
+		})
+
+		Context("When the referral bonuses cannot be granted", func() {
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
+					ID:                merchantID,
+					ConversionFactor:  0.1,
+					DefaultRewardType: "points",
+				}, nil)
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), domain_errors.ErrForbidden)
+			})
+
+			It("should return the error of the referral", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			})
+		})
+
+		Context("When stamp-card campaigns are active", func() {
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
+					ID:                merchantID,
+					ConversionFactor:  0.1,
+					DefaultRewardType: "points",
+				}, nil)
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{
+					{ID: 5, Kind: models.CampaignStampCard, Type: "points", Value: 50, StampsRequired: ptr(10)},
+					{ID: 6, Kind: models.CampaignStampCard, Type: "points", Value: 50, StampsRequired: ptr(5), MinAmount: ptr(500.0)},
+				}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockStamp.On("AddStamps", mock.Anything, mock.AnythingOfType("stamp_requests.AddStampsRequest")).Return([]stamp_responses.StampCardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), nil)
+			})
+
+			It("should stamp the cards of the qualifying campaigns and grant the base reward", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(BeNil())
+				mockStamp.AssertCalled(GinkgoT(), "AddStamps", mock.Anything, stamp_requests.AddStampsRequest{
+					TransactionID: 9,
+					UserID:        userID,
+					MerchantID:    merchantID,
+					BranchID:      branchID,
+					Date:          date,
+					CampaignIDs:   []uint{5},
+				})
+				mockReward.AssertCalled(GinkgoT(), "CreateReward", mock.Anything, reward_requests.CreateRewardRequest{
+					UserID:        userID,
+					MerchantID:    merchantID,
+					Type:          "points",
+					Amount:        10.0,
+					TransactionID: ptr(uint(9)),
+					BranchID:      &branchID,
+				})
+				mockReward.AssertNumberOfCalls(GinkgoT(), "CreateReward", 1)
+			})
+		})
+
+		Context("When spend-threshold campaigns are active", func() {
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
+					ID:                merchantID,
+					ConversionFactor:  0.1,
+					DefaultRewardType: "points",
+				}, nil)
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{
+					{ID: 7, Kind: models.CampaignSpendThreshold, Type: "points", Value: 500, SpendThreshold: ptr(1000.0)},
+					{ID: 8, Kind: models.CampaignSpendThreshold, Type: "points", Value: 500, SpendThreshold: ptr(1000.0), MinAmount: ptr(500.0)},
+				}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockThreshold.On("TrackSpend", mock.Anything, mock.AnythingOfType("threshold_requests.TrackSpendRequest")).Return([]threshold_responses.ThresholdAwardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), nil)
+			})
+
+			It("should track the spend of the qualifying campaigns and grant the base reward", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(BeNil())
+				mockThreshold.AssertCalled(GinkgoT(), "TrackSpend", mock.Anything, threshold_requests.TrackSpendRequest{
+					TransactionID: 9,
+					UserID:        userID,
+					MerchantID:    merchantID,
+					BranchID:      branchID,
+					Date:          date,
+					CampaignIDs:   []uint{7},
+				})
+				mockReward.AssertCalled(GinkgoT(), "CreateReward", mock.Anything, reward_requests.CreateRewardRequest{
+					UserID:        userID,
+					MerchantID:    merchantID,
+					Type:          "points",
+					Amount:        10.0,
+					TransactionID: ptr(uint(9)),
+					BranchID:      &branchID,
+				})
+				mockReward.AssertNumberOfCalls(GinkgoT(), "CreateReward", 1)
+			})
+		})
+
+		Context("When the transaction is processed", func() {
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
+					ID:                merchantID,
+					ConversionFactor:  0.1,
+					DefaultRewardType: "points",
+				}, nil)
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), nil)
+			})
+
+			It("should evaluate it on the merchant's challenges", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
+					UserID:     userID,
+					MerchantID: merchantID,
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
 
 				Expect(err).To(BeNil())
-				mockReward.AssertCalled(GinkgoT(), "CreateReward", reward_requests.CreateRewardRequest{
+				mockChallenge.AssertCalled(GinkgoT(), "EvaluateChallenges", mock.Anything, challenge_requests.EvaluateChallengesRequest{
+					TransactionID: 9,
+					UserID:        userID,
+					MerchantID:    merchantID,
+					BranchID:      branchID,
+					Amount:        amount,
+					Date:          date,
+				})
+			})
+		})
+
+		Context("When the merchant has a vesting period", func() {
+			BeforeEach(func() {
+				mockTransaction.On("CreateTransaction", mock.Anything, mock.AnythingOfType("transaction_requests.CreateTransactionRequest")).Return(&transaction_responses.TransactionResponse{ID: 9, BranchID: branchID, MerchantID: merchantID}, nil)
+				mockUser.On("EnrollUser", mock.Anything, userID, merchantID, branchID).Return(nil)
+				mockMerchant.On("GetMerchant", mock.Anything, merchantID).Return(&merchant_responses.MerchantResponse{
+					ID:                merchantID,
+					ConversionFactor:  0.1,
+					DefaultRewardType: "points",
+					RewardVestingDays: ptr(14),
+				}, nil)
+				mockCampaign.On("GetActiveCampaigns", mock.Anything, merchantID, &branchID, mock.AnythingOfType("time.Time")).Return([]campaign_responses.CampaignResponse{}, nil)
+				mockReward.On("CreateReward", mock.Anything, mock.AnythingOfType("reward_requests.CreateRewardRequest")).Return(&reward_responses.RewardResponse{}, nil)
+				mockReferral.On("QualifyReferral", mock.Anything, mock.AnythingOfType("referral_requests.QualifyReferralRequest")).Return((*referral_responses.ReferralResponse)(nil), nil)
+			})
+
+			It("should grant the rewards pending until the end of the period", func() {
+				err := loyaltyService.ProcessTransaction(ctx, loyalty_requests.ProcessTransactionRequest{
 					UserID:     userID,
 					MerchantID: merchantID,
-					Type:       "points",
-					Amount:     20.0, // (100 * 0.1) * 2
+					BranchID:   branchID,
+					Amount:     amount,
+					Date:       date,
+				})
+
+				Expect(err).To(BeNil())
+				mockReward.AssertCalled(GinkgoT(), "CreateReward", mock.Anything, reward_requests.CreateRewardRequest{
+					UserID:        userID,
+					MerchantID:    merchantID,
+					Type:          "points",
+					Amount:        10.0,
+					TransactionID: ptr(uint(9)),
+					BranchID:      &branchID,
+					VestsAt:       ptr(date.AddDate(0, 0, 14)),
 				})
 			})
 		})
@@ -117,7 +578,7 @@ var _ = Describe("LoyaltyService", func() {
 	Describe("RedeemRewards", func() {
 		Context("When user has sufficient rewards", func() {
 			BeforeEach(func() {
-				mockReward.On("ListRewardsByUser", userID).Return([]reward_responses.RewardResponse{
+				mockReward.On("ListRewardsByUser", mock.Anything, userID).Return([]reward_responses.RewardResponse{
 					{
 						MerchantID: merchantID,
 						Type:       "points",
@@ -125,11 +586,11 @@ var _ = Describe("LoyaltyService", func() {
 					},
 				}, nil)
 				// Cambia esta línea para que coincida con el monto que estás probando
-				mockReward.On("DeductRewards", userID, merchantID, float64(30), "points").Return(nil)
+				mockReward.On("DeductRewards", mock.Anything, userID, merchantID, float64(30), "points").Return(nil)
 			})
 
 			It("should redeem the rewards successfully", func() {
-				err := loyaltyService.RedeemRewards(userID, merchantID, 30.0, "points")
+				err := loyaltyService.RedeemRewards(ctx, userID, merchantID, 30.0, "points")
 
 				Expect(err).To(BeNil())
 				mockReward.AssertExpectations(GinkgoT())
@@ -138,7 +599,7 @@ var _ = Describe("LoyaltyService", func() {
 
 		Context("When user has insufficient rewards", func() {
 			BeforeEach(func() {
-				mockReward.On("ListRewardsByUser", userID).Return([]reward_responses.RewardResponse{
+				mockReward.On("ListRewardsByUser", mock.Anything, userID).Return([]reward_responses.RewardResponse{
 					{
 						MerchantID: merchantID,
 						Type:       "points",
@@ -148,13 +609,129 @@ var _ = Describe("LoyaltyService", func() {
 			})
 
 			It("should return an error", func() {
-				err := loyaltyService.RedeemRewards(userID, merchantID, 30.0, "points")
+				err := loyaltyService.RedeemRewards(ctx, userID, merchantID, 30.0, "points")
 
 				Expect(err).To(MatchError("insufficient rewards"))
+				Expect(err).To(MatchError(domain_errors.ErrInsufficientBalance))
 				mockReward.AssertNotCalled(GinkgoT(), "DeductRewards")
 			})
 		})
 
+		Context("When part of the rewards is pending", func() {
+			BeforeEach(func() {
+				vestsAt := date.AddDate(0, 0, 7)
+				mockReward.On("ListRewardsByUser", mock.Anything, userID).Return([]reward_responses.RewardResponse{
+					{
+						MerchantID: merchantID,
+						Type:       "points",
+						Amount:     20.0,
+					},
+					{
+						MerchantID: merchantID,
+						Type:       "points",
+						Amount:     30.0,
+						VestsAt:    &vestsAt,
+					},
+				}, nil)
+			})
+
+			It("should only count the vested rewards", func() {
+				err := loyaltyService.RedeemRewards(ctx, userID, merchantID, 30.0, "points")
+
+				Expect(err).To(MatchError(domain_errors.ErrInsufficientBalance))
+				mockReward.AssertNotCalled(GinkgoT(), "DeductRewards")
+			})
+		})
+
+		Context("When part of the rewards is held", func() {
+			BeforeEach(func() {
+				mockReward.On("ListRewardsByUser", mock.Anything, userID).Return([]reward_responses.RewardResponse{
+					{
+						MerchantID: merchantID,
+						Type:       "points",
+						Amount:     50.0,
+					},
+				}, nil)
+				mockReward.On("DeductRewards", mock.Anything, userID, merchantID, float64(30), "points").Return(reward_app.ErrInsufficientBalance)
+			})
+
+			It("should return the error of the deduction", func() {
+				err := loyaltyService.RedeemRewards(ctx, userID, merchantID, 30.0, "points")
+
+				Expect(err).To(MatchError(domain_errors.ErrInsufficientBalance))
+			})
+		})
+
+	})
+
+	Describe("AuthorizeRedemption", func() {
+		It("should hold the amount until the hold timeout", func() {
+			var held reward_requests.HoldRewardsRequest
+			mockReward.On("HoldRewards", mock.Anything, mock.AnythingOfType("reward_requests.HoldRewardsRequest")).Run(func(args mock.Arguments) {
+				held = args.Get(1).(reward_requests.HoldRewardsRequest)
+			}).Return(&reward_responses.HoldResponse{ID: 5, Status: "held"}, nil)
+
+			hold, err := loyaltyService.AuthorizeRedemption(ctx, loyalty_requests.AuthorizeRedemptionRequest{
+				UserID:     userID,
+				MerchantID: merchantID,
+				BranchID:   &branchID,
+				Amount:     30,
+				RewardType: "points",
+			})
+
+			Expect(err).To(BeNil())
+			Expect(hold.ID).To(Equal(uint(5)))
+			Expect(held.UserID).To(Equal(userID))
+			Expect(held.BranchID).To(Equal(&branchID))
+			Expect(held.Type).To(Equal("points"))
+			Expect(held.Amount).To(Equal(30.0))
+			Expect(held.ExpiresAt).To(BeTemporally("~", time.Now().Add(10*time.Minute), time.Second))
+		})
+
+		It("should return the insufficient balance of the user", func() {
+			mockReward.On("HoldRewards", mock.Anything, mock.Anything).Return(nil, reward_app.ErrInsufficientBalance)
+
+			_, err := loyaltyService.AuthorizeRedemption(ctx, loyalty_requests.AuthorizeRedemptionRequest{
+				UserID:     userID,
+				MerchantID: merchantID,
+				Amount:     30,
+				RewardType: "points",
+			})
+
+			Expect(err).To(MatchError(domain_errors.ErrInsufficientBalance))
+		})
+	})
+
+	Describe("CaptureRedemption", func() {
+		It("should capture the whole hold when no amount is given", func() {
+			mockReward.On("CaptureHold", mock.Anything, uint(5), (*float64)(nil)).Return(&reward_responses.HoldResponse{ID: 5, Status: "captured"}, nil)
+
+			hold, err := loyaltyService.CaptureRedemption(ctx, 5, loyalty_requests.CaptureRedemptionRequest{})
+
+			Expect(err).To(BeNil())
+			Expect(hold.Status).To(Equal("captured"))
+		})
+
+		It("should capture part of the hold", func() {
+			amount := 12.5
+			mockReward.On("CaptureHold", mock.Anything, uint(5), &amount).Return(&reward_responses.HoldResponse{ID: 5, Status: "captured", CapturedAmount: &amount}, nil)
+
+			hold, err := loyaltyService.CaptureRedemption(ctx, 5, loyalty_requests.CaptureRedemptionRequest{Amount: &amount})
+
+			Expect(err).To(BeNil())
+			Expect(*hold.CapturedAmount).To(Equal(12.5))
+		})
+	})
+
+	Describe("VoidRedemption", func() {
+		It("should release the hold", func() {
+			mockReward.On("VoidHold", mock.Anything, uint(5)).Return(&reward_responses.HoldResponse{ID: 5, Status: "voided"}, nil)
+
+			hold, err := loyaltyService.VoidRedemption(ctx, 5)
+
+			Expect(err).To(BeNil())
+			Expect(hold.Status).To(Equal("voided"))
+		})
 	})
 })
 
@@ -163,67 +740,75 @@ type mockTransactionService struct {
 	mock.Mock
 }
 
-func (m *mockTransactionService) CreateTransaction(req transaction_requests.CreateTransactionRequest) (*transaction_responses.TransactionResponse, error) {
-	args := m.Called(req)
+func (m *mockTransactionService) CreateTransaction(ctx context.Context, req transaction_requests.CreateTransactionRequest) (*transaction_responses.TransactionResponse, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(*transaction_responses.TransactionResponse), args.Error(1)
 }
 
-func (m *mockTransactionService) GetTotalAmountByUserAndDateRange(userID uint, startDate, endDate time.Time) (float64, error) {
-	args := m.Called(userID, startDate, endDate)
+func (m *mockTransactionService) GetTotalAmountByUserAndDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) (float64, error) {
+	args := m.Called(ctx, userID, startDate, endDate)
 	return args.Get(0).(float64), args.Error(1)
 }
 
-func (m *mockTransactionService) GetTransaction(id uint) (*transaction_responses.TransactionResponse, error) {
-	args := m.Called(id)
+func (m *mockTransactionService) GetTransaction(ctx context.Context, id uint) (*transaction_responses.TransactionResponse, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*transaction_responses.TransactionResponse), args.Error(1)
 }
 
-func (m *mockTransactionService) GetTransactionsByDateRange(startDate, endDate time.Time) ([]transaction_responses.TransactionResponse, error) {
-	args := m.Called(startDate, endDate)
-	return args.Get(0).([]transaction_responses.TransactionResponse), args.Error(1)
+
+
+
+func (m *mockTransactionService) ReverseTransaction(ctx context.Context, id uint) (*transaction_responses.TransactionResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*transaction_responses.TransactionResponse), args.Error(1)
 }
 
-func (m *mockTransactionService) ListTransactionsByBranch(branchID uint) ([]transaction_responses.TransactionResponse, error) {
-	args := m.Called(branchID)
-	return args.Get(0).([]transaction_responses.TransactionResponse), args.Error(1)
+func (m *mockTransactionService) ListTransactions(ctx context.Context, req transaction_requests.ListTransactionsRequest) (*pagination.Page[transaction_responses.TransactionResponse], error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*pagination.Page[transaction_responses.TransactionResponse]), args.Error(1)
 }
 
-func (m *mockTransactionService) ListTransactionsByUser(userID uint) ([]transaction_responses.TransactionResponse, error) {
-	args := m.Called(userID)
-	return args.Get(0).([]transaction_responses.TransactionResponse), args.Error(1)
+func (m *mockTransactionService) ExportTransactions(ctx context.Context, req transaction_requests.ExportTransactionsRequest, w io.Writer) error {
+	args := m.Called(ctx, req, w)
+	return args.Error(0)
 }
 
 type mockCampaignService struct {
 	mock.Mock
 }
 
-func (m *mockCampaignService) GetActiveCampaigns(merchantID uint, branchID *uint, date time.Time) ([]campaign_responses.CampaignResponse, error) {
-	args := m.Called(merchantID, branchID, date)
+func (m *mockCampaignService) GetActiveCampaigns(ctx context.Context, merchantID uint, branchID *uint, date time.Time) ([]campaign_responses.CampaignResponse, error) {
+	args := m.Called(ctx, merchantID, branchID, date)
 	return args.Get(0).([]campaign_responses.CampaignResponse), args.Error(1)
 }
 
-func (m *mockCampaignService) CreateCampaign(req campaign_requests.CreateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
-	args := m.Called(req)
+func (m *mockCampaignService) GetCampaignReport(ctx context.Context, id uint) (*campaign_responses.CampaignReportResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*campaign_responses.CampaignReportResponse), args.Error(1)
+}
+
+func (m *mockCampaignService) CreateCampaign(ctx context.Context, req campaign_requests.CreateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(*campaign_responses.CampaignResponse), args.Error(1)
 }
 
-func (m *mockCampaignService) DeleteCampaign(id uint) error {
-	args := m.Called(id)
+func (m *mockCampaignService) DeleteCampaign(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *mockCampaignService) GetCampaign(id uint) (*campaign_responses.CampaignResponse, error) {
-	args := m.Called(id)
+func (m *mockCampaignService) GetCampaign(ctx context.Context, id uint) (*campaign_responses.CampaignResponse, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*campaign_responses.CampaignResponse), args.Error(1)
 }
 
-func (m *mockCampaignService) ListCampaigns() ([]campaign_responses.CampaignResponse, error) {
-	args := m.Called()
-	return args.Get(0).([]campaign_responses.CampaignResponse), args.Error(1)
+func (m *mockCampaignService) ListCampaigns(ctx context.Context, req campaign_requests.ListCampaignsRequest) (*pagination.Page[campaign_responses.CampaignResponse], error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*pagination.Page[campaign_responses.CampaignResponse]), args.Error(1)
 }
 
-func (m *mockCampaignService) UpdateCampaign(id uint, req campaign_requests.UpdateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
-	args := m.Called(id, req)
+func (m *mockCampaignService) UpdateCampaign(ctx context.Context, id uint, req campaign_requests.UpdateCampaignRequest) (*campaign_responses.CampaignResponse, error) {
+	args := m.Called(ctx, id, req)
 	return args.Get(0).(*campaign_responses.CampaignResponse), args.Error(1)
 }
 
@@ -231,56 +816,283 @@ type mockRewardService struct {
 	mock.Mock
 }
 
-func (m *mockRewardService) CreateReward(req reward_requests.CreateRewardRequest) (*reward_responses.RewardResponse, error) {
-	args := m.Called(req)
+func (m *mockRewardService) CreateReward(ctx context.Context, req reward_requests.CreateRewardRequest) (*reward_responses.RewardResponse, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(*reward_responses.RewardResponse), args.Error(1)
 }
 
-func (m *mockRewardService) ListRewardsByUser(userID uint) ([]reward_responses.RewardResponse, error) {
-	args := m.Called(userID)
+func (m *mockRewardService) ListRewards(ctx context.Context, req reward_requests.ListRewardsRequest) (*pagination.Page[reward_responses.RewardResponse], error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*pagination.Page[reward_responses.RewardResponse]), args.Error(1)
+}
+
+func (m *mockRewardService) ListRewardsByUser(ctx context.Context, userID uint) ([]reward_responses.RewardResponse, error) {
+	args := m.Called(ctx, userID)
 	return args.Get(0).([]reward_responses.RewardResponse), args.Error(1)
 }
 
-func (m *mockRewardService) DeductRewards(userID, merchantID uint, amount float64, rewardType string) error {
-	args := m.Called(userID, merchantID, amount, rewardType)
+func (m *mockRewardService) DeductRewards(ctx context.Context, userID, merchantID uint, amount float64, rewardType string) error {
+	args := m.Called(ctx, userID, merchantID, amount, rewardType)
 	return args.Error(0)
 }
 
-func (m *mockRewardService) GetReward(id uint) (*reward_responses.RewardResponse, error) {
-	args := m.Called(id)
+func (m *mockRewardService) GetReward(ctx context.Context, id uint) (*reward_responses.RewardResponse, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*reward_responses.RewardResponse), args.Error(1)
 }
 
-func (m *mockRewardService) GetTotalRewardsByUser(id uint) (*reward_responses.TotalRewardsResponse, error) {
-	args := m.Called(id)
+func (m *mockRewardService) GetTotalRewardsByUser(ctx context.Context, id uint) (*reward_responses.TotalRewardsResponse, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*reward_responses.TotalRewardsResponse), args.Error(1)
 }
 
+func (m *mockRewardService) GetBalances(ctx context.Context, userID uint) ([]reward_responses.BalanceResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]reward_responses.BalanceResponse), args.Error(1)
+}
+
+func (m *mockRewardService) ExpireRewards(ctx context.Context, currentDate time.Time) (int, error) {
+	args := m.Called(ctx, currentDate)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockRewardService) VestRewards(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockRewardService) RecalculateBalances(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockRewardService) ExportMovements(ctx context.Context, req reward_requests.ExportMovementsRequest, w io.Writer) error {
+	args := m.Called(ctx, req, w)
+	return args.Error(0)
+}
+
+func (m *mockRewardService) HoldRewards(ctx context.Context, req reward_requests.HoldRewardsRequest) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
+
+func (m *mockRewardService) GetHold(ctx context.Context, id uint) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
+
+func (m *mockRewardService) CaptureHold(ctx context.Context, id uint, amount *float64) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, id, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
+
+func (m *mockRewardService) VoidHold(ctx context.Context, id uint) (*reward_responses.HoldResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*reward_responses.HoldResponse), args.Error(1)
+}
+
+func (m *mockRewardService) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
 type mockMerchantService struct {
 	mock.Mock
 }
 
-func (m *mockMerchantService) GetMerchant(id uint) (*merchant_responses.MerchantResponse, error) {
-	args := m.Called(id)
+func (m *mockMerchantService) GetMerchant(ctx context.Context, id uint) (*merchant_responses.MerchantResponse, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*merchant_responses.MerchantResponse), args.Error(1)
 }
 
-func (m *mockMerchantService) CreateMerchant(req merchant_requests.CreateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
-	args := m.Called(req)
+func (m *mockMerchantService) CreateMerchant(ctx context.Context, req merchant_requests.CreateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
+	args := m.Called(ctx, req)
 	return args.Get(0).(*merchant_responses.MerchantResponse), args.Error(1)
 }
 
-func (m *mockMerchantService) UpdateMerchant(id uint, req merchant_requests.UpdateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
-	args := m.Called(id, req)
+func (m *mockMerchantService) UpdateMerchant(ctx context.Context, id uint, req merchant_requests.UpdateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
+	args := m.Called(ctx, id, req)
 	return args.Get(0).(*merchant_responses.MerchantResponse), args.Error(1)
 }
 
-func (m *mockMerchantService) DeleteMerchant(id uint) error {
-	args := m.Called(id)
+func (m *mockMerchantService) DeleteMerchant(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockMerchantService) ListMerchants(ctx context.Context, req merchant_requests.ListMerchantsRequest) (*pagination.Page[merchant_responses.MerchantResponse], error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*pagination.Page[merchant_responses.MerchantResponse]), args.Error(1)
+}
+
+type mockUserService struct {
+	mock.Mock
+}
+
+func (m *mockUserService) CreateUser(ctx context.Context, req user_requests.CreateUserRequest) (*user_responses.UserResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*user_responses.UserResponse), args.Error(1)
+}
+
+func (m *mockUserService) GetUser(ctx context.Context, id uint) (*user_responses.UserResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*user_responses.UserResponse), args.Error(1)
+}
+
+func (m *mockUserService) UpdateUser(ctx context.Context, id uint, req user_requests.UpdateUserRequest) (*user_responses.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*user_responses.UserResponse), args.Error(1)
+}
+
+func (m *mockUserService) DeleteUser(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockUserService) ListUsers(ctx context.Context, req user_requests.ListUsersRequest) (*pagination.Page[user_responses.UserResponse], error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*pagination.Page[user_responses.UserResponse]), args.Error(1)
+}
+
+func (m *mockUserService) GetUserWithTransactions(ctx context.Context, id uint) (*user_responses.UserWithTransactionsResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*user_responses.UserWithTransactionsResponse), args.Error(1)
+}
+
+func (m *mockUserService) GetUserWithRewards(ctx context.Context, id uint) (*user_responses.UserWithRewardsResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*user_responses.UserWithRewardsResponse), args.Error(1)
+}
+
+func (m *mockUserService) EnrollUser(ctx context.Context, userID, merchantID, branchID uint) error {
+	args := m.Called(ctx, userID, merchantID, branchID)
+	return args.Error(0)
+}
+
+func (m *mockUserService) ExportUsers(ctx context.Context, req user_requests.ExportUsersRequest, w io.Writer) error {
+	args := m.Called(ctx, req, w)
 	return args.Error(0)
 }
 
-func (m *mockMerchantService) ListMerchants() ([]*merchant_responses.MerchantResponse, error) {
-	args := m.Called()
-	return args.Get(0).([]*merchant_responses.MerchantResponse), args.Error(1)
+type mockStampService struct {
+	mock.Mock
+}
+
+func (m *mockStampService) AddStamps(ctx context.Context, req stamp_requests.AddStampsRequest) ([]stamp_responses.StampCardResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]stamp_responses.StampCardResponse), args.Error(1)
+}
+
+func (m *mockStampService) ListStampCards(ctx context.Context, userID uint, req stamp_requests.ListStampCardsRequest) ([]stamp_responses.StampCardResponse, error) {
+	args := m.Called(ctx, userID, req)
+	return args.Get(0).([]stamp_responses.StampCardResponse), args.Error(1)
+}
+
+type mockThresholdService struct {
+	mock.Mock
+}
+
+func (m *mockThresholdService) TrackSpend(ctx context.Context, req threshold_requests.TrackSpendRequest) ([]threshold_responses.ThresholdAwardResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]threshold_responses.ThresholdAwardResponse), args.Error(1)
 }
+
+func (m *mockThresholdService) ListSpendProgress(ctx context.Context, userID uint) ([]threshold_responses.SpendProgressResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]threshold_responses.SpendProgressResponse), args.Error(1)
+}
+
+type mockChallengeService struct {
+	mock.Mock
+}
+
+func (m *mockChallengeService) CreateChallenge(ctx context.Context, req challenge_requests.CreateChallengeRequest) (*challenge_responses.ChallengeResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*challenge_responses.ChallengeResponse), args.Error(1)
+}
+
+func (m *mockChallengeService) GetChallenge(ctx context.Context, id uint) (*challenge_responses.ChallengeResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*challenge_responses.ChallengeResponse), args.Error(1)
+}
+
+func (m *mockChallengeService) UpdateChallenge(ctx context.Context, id uint, req challenge_requests.UpdateChallengeRequest) (*challenge_responses.ChallengeResponse, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*challenge_responses.ChallengeResponse), args.Error(1)
+}
+
+func (m *mockChallengeService) DeleteChallenge(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockChallengeService) ListChallenges(ctx context.Context, req challenge_requests.ListChallengesRequest) (*pagination.Page[challenge_responses.ChallengeResponse], error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*pagination.Page[challenge_responses.ChallengeResponse]), args.Error(1)
+}
+
+func (m *mockChallengeService) EvaluateChallenges(ctx context.Context, req challenge_requests.EvaluateChallengesRequest) ([]challenge_responses.ChallengeProgressResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]challenge_responses.ChallengeProgressResponse), args.Error(1)
+}
+
+func (m *mockChallengeService) ListUserChallenges(ctx context.Context, userID uint) ([]challenge_responses.ChallengeProgressResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]challenge_responses.ChallengeProgressResponse), args.Error(1)
+}
+
+func (m *mockChallengeService) ListBadges(ctx context.Context, userID uint) ([]challenge_responses.BadgeResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]challenge_responses.BadgeResponse), args.Error(1)
+}
+
+type mockReferralService struct {
+	mock.Mock
+}
+
+func (m *mockReferralService) IssueReferralCode(ctx context.Context, userID uint) (*referral_responses.ReferralCodeResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*referral_responses.ReferralCodeResponse), args.Error(1)
+}
+
+func (m *mockReferralService) CreateReferral(ctx context.Context, req referral_requests.CreateReferralRequest) (*referral_responses.ReferralResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*referral_responses.ReferralResponse), args.Error(1)
+}
+
+func (m *mockReferralService) GetReferralStatus(ctx context.Context, userID uint) (*referral_responses.ReferralStatusResponse, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*referral_responses.ReferralStatusResponse), args.Error(1)
+}
+
+func (m *mockReferralService) QualifyReferral(ctx context.Context, req referral_requests.QualifyReferralRequest) (*referral_responses.ReferralResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*referral_responses.ReferralResponse), args.Error(1)
+}
+
+type unitOfWorkKey struct{}
+
+// fakeUnitOfWork runs the work right away, with a context that tells that it
+// runs in a unit of work.
+type fakeUnitOfWork struct{}
+
+func (fakeUnitOfWork) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(context.WithValue(ctx, unitOfWorkKey{}, true))
+}
+
+// inUnitOfWork matches the contexts of the work run by fakeUnitOfWork.
+var inUnitOfWork = mock.MatchedBy(func(ctx context.Context) bool {
+	return ctx.Value(unitOfWorkKey{}) == true
+})
@@ -0,0 +1,31 @@
+package loyalty_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+type ImportRowFilter struct {
+	ImportJobID uint
+	Status      string
+}
+
+type IImportRepository interface {
+	Create(ctx context.Context, job *models.ImportJob) error
+	// GetByID loads the job without its file content.
+	GetByID(ctx context.Context, id uint) (*models.ImportJob, error)
+	// ClaimJob returns the oldest job that is pending, or processing with an
+	// expired lease, with its content, marks it processing and leases it until
+	// now+lease so that no other worker picks it up meanwhile. It returns nil
+	// when there is no job to process.
+	ClaimJob(ctx context.Context, now time.Time, lease time.Duration) (*models.ImportJob, error)
+	// SaveProgress records the results of a batch of rows together with the
+	// counters, status and lease of the job, so a job resumed after a crash
+	// carries on after the last saved row.
+	SaveProgress(ctx context.Context, job *models.ImportJob, rows []models.ImportRow) error
+	ListRows(ctx context.Context, filter ImportRowFilter, page pagination.Request) (*pagination.Page[models.ImportRow], error)
+	// ListUnprocessedRows returns the failed and duplicate rows of a job by line.
+	ListUnprocessedRows(ctx context.Context, jobID uint) ([]models.ImportRow, error)
+}
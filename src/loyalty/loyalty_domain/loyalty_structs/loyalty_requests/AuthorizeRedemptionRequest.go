@@ -0,0 +1,15 @@
+package loyalty_requests
+
+type AuthorizeRedemptionRequest struct {
+	UserID     uint    `json:"userId" binding:"required"`
+	MerchantID uint    `json:"merchantId" binding:"required"`
+	BranchID   *uint   `json:"branchId"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	RewardType string  `json:"rewardType" binding:"required,oneof=points cashback"`
+}
+
+// CaptureRedemptionRequest captures the whole amount held when Amount is
+// omitted; a smaller amount releases the rest.
+type CaptureRedemptionRequest struct {
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
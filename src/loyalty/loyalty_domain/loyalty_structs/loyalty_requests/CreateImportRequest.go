@@ -0,0 +1,9 @@
+package loyalty_requests
+
+// CreateImportRequest describes an uploaded file of transactions. The format
+// defaults to the extension of the file name.
+type CreateImportRequest struct {
+	MerchantID uint   `form:"merchantId" binding:"required"`
+	BranchID   *uint  `form:"branchId"`
+	Format     string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
@@ -0,0 +1,9 @@
+package loyalty_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListImportRowsRequest accepts sort by line.
+type ListImportRowsRequest struct {
+	pagination.Request
+	Status string `form:"status" binding:"omitempty,oneof=processed failed duplicate"`
+}
@@ -3,9 +3,14 @@ package loyalty_requests
 import "time"
 
 type ProcessTransactionRequest struct {
-	UserID     uint      `json:"userId" binding:"required"`
-	MerchantID uint      `json:"merchantId" binding:"required"`
-	BranchID   uint      `json:"branchId" binding:"required"`
-	Amount     float64   `json:"amount" binding:"required,gt=0"`
-	Date       time.Time `json:"date" binding:"required"`
+	UserID uint `json:"userId" binding:"required"`
+	// MerchantID is optional and defaults to the merchant of the branch.
+	MerchantID uint    `json:"merchantId"`
+	BranchID   uint    `json:"branchId" binding:"required"`
+	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	// ExternalRef is the reference of the sale in the merchant's system. A
+	// reference already processed for the merchant is rejected, which makes
+	// retries safe.
+	ExternalRef *string   `json:"externalRef" binding:"omitempty,max=100"`
+	Date        time.Time `json:"date" binding:"required"`
 }
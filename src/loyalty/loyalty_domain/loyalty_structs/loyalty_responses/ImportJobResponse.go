@@ -0,0 +1,21 @@
+package loyalty_responses
+
+import "time"
+
+type ImportJobResponse struct {
+	ID         uint       `json:"id"`
+	MerchantID uint       `json:"merchantId"`
+	BranchID   *uint      `json:"branchId,omitempty"`
+	Format     string     `json:"format"`
+	FileName   string     `json:"fileName,omitempty"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	Duplicates int        `json:"duplicates"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	StartedAt  *time.Time `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
@@ -0,0 +1,9 @@
+package loyalty_responses
+
+type ImportRowResponse struct {
+	Line        int    `json:"line"`
+	ExternalRef string `json:"externalRef,omitempty"`
+	Status      string `json:"status"`
+	ErrorCode   string `json:"errorCode,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
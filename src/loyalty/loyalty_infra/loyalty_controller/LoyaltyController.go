@@ -1,25 +1,50 @@
 package loyalty_controller
 
 import (
+	"fmt"
+	"io"
+	"loyalty-campaigns/src/branch/branch_infra/branch_repository"
 	"loyalty-campaigns/src/campaign/campaign_app"
 	"loyalty-campaigns/src/campaign/campaign_infra/campaign_repository"
+	"loyalty-campaigns/src/catalog/catalog_app"
+	"loyalty-campaigns/src/catalog/catalog_domain/catalog_structs/catalog_requests"
+	"loyalty-campaigns/src/catalog/catalog_infra/catalog_repository"
+	"loyalty-campaigns/src/challenge/challenge_app"
+	"loyalty-campaigns/src/challenge/challenge_infra/challenge_repository"
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
 	"loyalty-campaigns/src/loyalty/loyalty_app"
 	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_structs/loyalty_requests"
+	"loyalty-campaigns/src/loyalty/loyalty_infra/loyalty_repository"
 	"loyalty-campaigns/src/merchant/merchant_app"
 	"loyalty-campaigns/src/merchant/merchant_infra/merchant_repository"
+	"loyalty-campaigns/src/referral/referral_app"
+	"loyalty-campaigns/src/referral/referral_infra/referral_repository"
 	"loyalty-campaigns/src/reward/reward_app"
 	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"loyalty-campaigns/src/stamp/stamp_app"
+	"loyalty-campaigns/src/stamp/stamp_infra/stamp_repository"
+	"loyalty-campaigns/src/threshold/threshold_app"
+	"loyalty-campaigns/src/threshold/threshold_infra/threshold_repository"
 	"loyalty-campaigns/src/transaction/transaction_app"
 	"loyalty-campaigns/src/transaction/transaction_infra/transaction_repository"
+	"loyalty-campaigns/src/user/user_app"
+	"loyalty-campaigns/src/user/user_infra/user_repository"
+	"mime"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type LoyaltyController struct {
 	loyaltyService loyalty_app.ILoyaltyService
+	importService  loyalty_app.IImportService
+	catalogService catalog_app.ICatalogService
 }
 
 var (
@@ -27,7 +52,7 @@ var (
 	loyaltyControllerOnce     sync.Once
 )
 
-func NewLoyaltyController(router *gin.Engine) *LoyaltyController {
+func NewLoyaltyController(router *gin.RouterGroup) *LoyaltyController {
 	loyaltyControllerOnce.Do(func() {
 		loyaltyControllerInstance = &LoyaltyController{}
 
@@ -37,54 +62,89 @@ func NewLoyaltyController(router *gin.Engine) *LoyaltyController {
 		campaignRepository := campaign_repository.NewGormCampaignRepository(db)
 		rewardRepository := reward_repository.NewGormRewardRepository(db)
 		merchantRepository := merchant_repository.NewGormMerchantRepository(db)
+		branchRepository := branch_repository.NewGormBranchRepository(db)
+		userRepository := user_repository.NewGormUserRepository(db)
 
-		transactionService := transaction_app.NewTransactionService(transactionRepository)
+		transactionService := transaction_app.NewTransactionService(transactionRepository, branchRepository, userRepository)
 		campaignService := campaign_app.NewCampaignService(campaignRepository)
 		rewardService := reward_app.NewRewardService(rewardRepository)
 		merchantService := merchant_app.NewMerchantService(merchantRepository)
+		userService := user_app.NewUserService(userRepository)
+		referralService := referral_app.NewReferralService(referral_repository.NewGormReferralRepository(db), referral_app.ReferralPolicyFromEnv())
+		stampService := stamp_app.NewStampService(stamp_repository.NewGormStampRepository(db), catalog_app.VoucherPolicyFromEnv())
+		thresholdService := threshold_app.NewThresholdService(threshold_repository.NewGormThresholdRepository(db))
+		challengeService := challenge_app.NewChallengeService(challenge_repository.NewGormChallengeRepository(db))
 
 		loyaltyControllerInstance.loyaltyService = loyalty_app.NewLoyaltyService(
 			transactionService,
 			campaignService,
 			rewardService,
 			merchantService,
+			userService,
+			referralService,
+			stampService,
+			thresholdService,
+			challengeService,
+			configs.NewUnitOfWork(db),
+			configs.GetEnvDuration("REDEMPTION_HOLD_TIMEOUT", loyalty_app.DefaultHoldTimeout),
 		)
+		loyaltyControllerInstance.importService = loyalty_app.NewImportService(
+			loyalty_repository.NewGormImportRepository(db),
+			loyaltyControllerInstance.loyaltyService,
+			configs.NewUnitOfWork(db),
+			configs.GetEnvInt("IMPORT_MAX_BYTES", loyalty_app.DefaultMaxImportBytes),
+		)
+
+		loyaltyControllerInstance.catalogService = catalog_app.NewCatalogService(catalog_repository.NewGormCatalogRepository(db), catalog_app.VoucherPolicyFromEnv())
 
 		loyaltyControllerInstance.setupRoutes(router)
 	})
 	return loyaltyControllerInstance
 }
 
-func (c *LoyaltyController) setupRoutes(router *gin.Engine) {
-	loyaltyGroup := router.Group("/api/loyalty")
+func (c *LoyaltyController) setupRoutes(router *gin.RouterGroup) {
+	loyaltyGroup := router.Group("/loyalty")
 	{
 		loyaltyGroup.POST("/process-transaction", c.ProcessTransaction)
 		loyaltyGroup.POST("/redeem-rewards", c.RedeemRewards)
+		loyaltyGroup.POST("/redeem-item", c.RedeemItem)
+		loyaltyGroup.POST("/authorize-redemption", c.AuthorizeRedemption)
+		loyaltyGroup.GET("/holds/:id", c.GetRedemption)
+		loyaltyGroup.POST("/holds/:id/capture", c.CaptureRedemption)
+		loyaltyGroup.POST("/holds/:id/void", c.VoidRedemption)
+		loyaltyGroup.POST("/imports", c.CreateImport)
+		loyaltyGroup.GET("/imports/:id", c.GetImport)
+		loyaltyGroup.GET("/imports/:id/rows", c.ListImportRows)
+		loyaltyGroup.GET("/imports/:id/errors", c.GetImportErrors)
 	}
 }
 
 // ProcessTransaction godoc
 //
 //	@Summary		Process a transaction and award loyalty points or cashback
-//	@Description	Process a user transaction and award loyalty points or cashback based on active campaigns
+//	@Description	Process a user transaction and award loyalty points or cashback based on active campaigns. The merchant defaults to the merchant of the branch; a merchant that does not own the branch is rejected with branch_merchant_mismatch, and an externalRef already processed for the merchant with transaction_already_exists (409)
 //	@Tags			loyalty
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		loyalty_requests.ProcessTransactionRequest	true	"Transaction details"
 //	@Success		200		{object}	map[string]string
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/loyalty/process-transaction [post]
 func (c *LoyaltyController) ProcessTransaction(ctx *gin.Context) {
 	var req loyalty_requests.ProcessTransactionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	err := c.loyaltyService.ProcessTransaction(req.UserID, req.MerchantID, req.BranchID, req.Amount, req.Date)
+	err := c.loyaltyService.ProcessTransaction(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -98,23 +158,349 @@ func (c *LoyaltyController) ProcessTransaction(ctx *gin.Context) {
 //	@Tags			loyalty
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		loyalty_requests.RedeemRewardsRequest	true	"Redemption details"
 //	@Success		200		{object}	map[string]string
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/loyalty/redeem-rewards [post]
 func (c *LoyaltyController) RedeemRewards(ctx *gin.Context) {
 	var req loyalty_requests.RedeemRewardsRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	err := c.loyaltyService.RedeemRewards(req.UserID, req.MerchantID, req.Amount, req.RewardType)
+	err := c.loyaltyService.RedeemRewards(ctx.Request.Context(), req.UserID, req.MerchantID, req.Amount, req.RewardType)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Rewards redeemed successfully"})
 }
+
+// RedeemItem godoc
+//
+//	@Summary		Redeem a catalog item
+//	@Description	Pay for a catalog item with the user's points or cashback at a branch. The cost is deducted and the stock decremented atomically; the redemption stays pending until the branch fulfils or cancels it. Rejected with item_not_available outside the validity window of the item or at a branch that does not offer it, item_out_of_stock (409) and insufficient_rewards (422).
+//	@Tags			loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		catalog_requests.RedeemItemRequest	true	"Redemption details"
+//	@Success		201		{object}	catalog_responses.ItemRedemptionResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Failure		422		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/loyalty/redeem-item [post]
+func (c *LoyaltyController) RedeemItem(ctx *gin.Context) {
+	var req catalog_requests.RedeemItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.catalogService.RedeemItem(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// AuthorizeRedemption godoc
+//
+//	@Summary		Authorize a redemption
+//	@Description	Hold an amount of a user's points or cashback when a checkout starts, to be captured when the sale completes or voided if it is abandoned. The amount stops being available but stays in the user's balance until it is captured. Holds expire after REDEMPTION_HOLD_TIMEOUT (15 minutes by default). Rejected with insufficient_rewards (422) when the available balance is not enough.
+//	@Tags			loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		loyalty_requests.AuthorizeRedemptionRequest	true	"Redemption details"
+//	@Success		201		{object}	reward_responses.HoldResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		422		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/loyalty/authorize-redemption [post]
+func (c *LoyaltyController) AuthorizeRedemption(ctx *gin.Context) {
+	var req loyalty_requests.AuthorizeRedemptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.loyaltyService.AuthorizeRedemption(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// GetRedemption godoc
+//
+//	@Summary		Get a redemption hold
+//	@Description	Get a redemption hold by its ID, with its status: held, captured, voided or expired
+//	@Tags			loyalty
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Hold ID"
+//	@Success		200	{object}	reward_responses.HoldResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/loyalty/holds/{id} [get]
+func (c *LoyaltyController) GetRedemption(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.loyaltyService.GetRedemption(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CaptureRedemption godoc
+//
+//	@Summary		Capture a redemption
+//	@Description	Redeem the amount of an active hold, or a smaller amount given in the body, releasing the rest. Rejected with hold_not_active or hold_expired (409), and capture_exceeds_hold when the amount is larger than the hold.
+//	@Tags			loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int											true	"Hold ID"
+//	@Param			request	body		loyalty_requests.CaptureRedemptionRequest	false	"Amount to capture"
+//	@Success		200		{object}	reward_responses.HoldResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Failure		422		{object}	domain_errors.Problem
+//	@Router			/api/loyalty/holds/{id}/capture [post]
+func (c *LoyaltyController) CaptureRedemption(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req loyalty_requests.CaptureRedemptionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.Error(domain_errors.InvalidRequest(err))
+			return
+		}
+	}
+
+	response, err := c.loyaltyService.CaptureRedemption(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// VoidRedemption godoc
+//
+//	@Summary		Void a redemption
+//	@Description	Release an active hold, making its amount available again. Rejected with hold_not_active (409) when it was already captured, voided or expired.
+//	@Tags			loyalty
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Hold ID"
+//	@Success		200	{object}	reward_responses.HoldResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Failure		409	{object}	domain_errors.Problem
+//	@Router			/api/loyalty/holds/{id}/void [post]
+func (c *LoyaltyController) VoidRedemption(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.loyaltyService.VoidRedemption(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CreateImport godoc
+//
+//	@Summary		Import a file of transactions
+//	@Description	Queue a CSV or JSONL file of transactions, uploaded as the "file" field of a multipart form or as the raw body, to be processed in the background like process-transaction. CSV files need a header with the columns userId, branchId, amount, date and externalRef, and optionally merchantId; JSONL rows use the same keys. Dates are RFC 3339 timestamps or YYYY-MM-DD dates. externalRef is required and rows already processed for the merchant are reported as duplicates. The format defaults to the file extension or the content type (text/csv, application/x-ndjson).
+//	@Tags			loyalty
+//	@Accept			multipart/form-data,text/csv,application/x-ndjson
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	query		loyalty_requests.CreateImportRequest	true	"Merchant, branch and format of the file"
+//	@Param			file	formData	file									false	"File of transactions"
+//	@Success		202		{object}	loyalty_responses.ImportJobResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/loyalty/imports [post]
+func (c *LoyaltyController) CreateImport(ctx *gin.Context) {
+	var req loyalty_requests.CreateImportRequest
+	if err := ctx.ShouldBindWith(&req, binding.Form); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	var fileName string
+	var content io.Reader = ctx.Request.Body
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.Error(domain_errors.InvalidRequest(err))
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			ctx.Error(domain_errors.InvalidRequest(err))
+			return
+		}
+		defer file.Close()
+		fileName, content = header.Filename, file
+	} else if req.Format == "" {
+		req.Format = formatOfContentType(ctx.ContentType())
+	}
+
+	response, err := c.importService.CreateImport(ctx.Request.Context(), req, fileName, content)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/api/loyalty/imports/%d", response.ID))
+	ctx.JSON(http.StatusAccepted, response)
+}
+
+// GetImport godoc
+//
+//	@Summary		Get an import
+//	@Description	Get the status and the row counters of an import
+//	@Tags			loyalty
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Import ID"
+//	@Success		200	{object}	loyalty_responses.ImportJobResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/loyalty/imports/{id} [get]
+func (c *LoyaltyController) GetImport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.importService.GetImport(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ListImportRows godoc
+//
+//	@Summary		List the rows of an import
+//	@Description	Get a page of the results of the processed rows of an import, optionally filtered by status. Sort by line, prefixed with "-" for descending order.
+//	@Tags			loyalty
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int										true	"Import ID"
+//	@Param			request	query		loyalty_requests.ListImportRowsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[loyalty_responses.ImportRowResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/loyalty/imports/{id}/rows [get]
+func (c *LoyaltyController) ListImportRows(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req loyalty_requests.ListImportRowsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.importService.ListImportRows(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetImportErrors godoc
+//
+//	@Summary		Download the error report of an import
+//	@Description	Download the failed and duplicate rows of an import as CSV, with the line, the error code and the original row
+//	@Tags			loyalty
+//	@Produce		text/csv
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Import ID"
+//	@Success		200	{file}		file
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
+//	@Router			/api/loyalty/imports/{id}/errors [get]
+func (c *LoyaltyController) GetImportErrors(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	report, err := c.importService.ErrorReport(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("import-%d-errors.csv", id),
+	}))
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", report)
+}
+
+// formatOfContentType maps the content type of a raw upload to its format.
+func formatOfContentType(contentType string) string {
+	switch contentType {
+	case "text/csv":
+		return models.ImportFormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return models.ImportFormatJSONL
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package loyalty_repository
+
+import (
+	"context"
+	"errors"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/loyalty/loyalty_domain/loyalty_ports"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormImportRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormImportRepository(db *gorm.DB) loyalty_ports.IImportRepository {
+	return &GormImportRepository{DB: db}
+}
+
+func (r *GormImportRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Omit("Merchant").Create(job).Error, "import_job")
+}
+
+func (r *GormImportRepository) GetByID(ctx context.Context, id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := configs.DB(ctx, r.DB).Omit("content").First(&job, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "import_job")
+	}
+	return &job, nil
+}
+
+func (r *GormImportRepository) ClaimJob(ctx context.Context, now time.Time, lease time.Duration) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND lease_until <= ?)", models.ImportJobPending, models.ImportJobProcessing, now).
+			Order("id").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		leaseUntil := now.Add(lease)
+		job.LeaseUntil = &leaseUntil
+		if job.StartedAt == nil {
+			job.StartedAt = &now
+		}
+		job.Status = models.ImportJobProcessing
+		return tx.Model(&job).Updates(map[string]any{
+			"status":      job.Status,
+			"lease_until": job.LeaseUntil,
+			"started_at":  job.StartedAt,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *GormImportRepository) SaveProgress(ctx context.Context, job *models.ImportJob, rows []models.ImportRow) error {
+	return configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		if len(rows) > 0 {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(job).Updates(map[string]any{
+			"status":      job.Status,
+			"total":       job.Total,
+			"processed":   job.Processed,
+			"succeeded":   job.Succeeded,
+			"failed":      job.Failed,
+			"duplicates":  job.Duplicates,
+			"error":       job.Error,
+			"lease_until": job.LeaseUntil,
+			"finished_at": job.FinishedAt,
+		}).Error
+	})
+}
+
+var importRowSorting = pagination.Sorting[models.ImportRow]{
+	IDColumn: "id",
+	ID:       func(row *models.ImportRow) uint { return row.ID },
+	Fields: map[string]pagination.Key[models.ImportRow]{
+		"line": {Column: "line", Value: func(row *models.ImportRow) any { return row.Line }},
+	},
+	Default: "line",
+}
+
+func (r *GormImportRepository) ListRows(ctx context.Context, filter loyalty_ports.ImportRowFilter, page pagination.Request) (*pagination.Page[models.ImportRow], error) {
+	query := configs.DB(ctx, r.DB).Where("import_job_id = ?", filter.ImportJobID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return pagination.Find(query, page, importRowSorting)
+}
+
+func (r *GormImportRepository) ListUnprocessedRows(ctx context.Context, jobID uint) ([]models.ImportRow, error) {
+	var rows []models.ImportRow
+	err := configs.DB(ctx, r.DB).
+		Where("import_job_id = ? AND status <> ?", jobID, models.ImportRowProcessed).
+		Order("line").
+		Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,201 @@
+package merchant_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_ports"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_requests"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_responses"
+	"time"
+)
+
+// MaxAnalyticsPeriods bounds the number of periods of a series, so that a
+// daily series covers a little over a year.
+const MaxAnalyticsPeriods = 400
+
+var (
+	ErrInvalidAnalyticsRange  = domain_errors.Validation("invalid_range", "to must not be before from")
+	ErrAnalyticsRangeTooLarge = domain_errors.Validation("range_too_large", "the range spans more than %d periods, use a coarser granularity", MaxAnalyticsPeriods)
+)
+
+type IAnalyticsService interface {
+	GetAnalytics(ctx context.Context, merchantID uint, req merchant_requests.AnalyticsRequest) (*merchant_responses.AnalyticsResponse, error)
+}
+
+type AnalyticsService struct {
+	repo merchant_ports.IAnalyticsRepository
+}
+
+func NewAnalyticsService(repo merchant_ports.IAnalyticsRepository) IAnalyticsService {
+	return &AnalyticsService{repo: repo}
+}
+
+func (s *AnalyticsService) GetAnalytics(ctx context.Context, merchantID uint, req merchant_requests.AnalyticsRequest) (*merchant_responses.AnalyticsResponse, error) {
+	err := security.Authorize(ctx, security.ActionRead, merchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = merchant_ports.GranularityDay
+	}
+	from := truncatePeriod(req.From, merchant_ports.GranularityDay)
+	to := truncatePeriod(req.To, merchant_ports.GranularityDay).AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return nil, ErrInvalidAnalyticsRange
+	}
+
+	var periods []time.Time
+	for period := truncatePeriod(from, granularity); period.Before(to); period = nextPeriod(period, granularity) {
+		if len(periods) == MaxAnalyticsPeriods {
+			return nil, ErrAnalyticsRangeTooLarge
+		}
+		periods = append(periods, period)
+	}
+
+	filter := merchant_ports.AnalyticsFilter{
+		MerchantID:  merchantID,
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+	}
+
+	totals, err := s.repo.SalesTotals(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sales, err := s.repo.SalesByPeriod(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := s.repo.SalesByBranch(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	movements, err := s.repo.RewardMovements(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	outstanding, err := s.repo.OutstandingRewards(ctx, merchantID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &merchant_responses.AnalyticsResponse{
+		MerchantID:  merchantID,
+		From:        from,
+		To:          to.AddDate(0, 0, -1),
+		Granularity: granularity,
+		Totals: merchant_responses.AnalyticsTotals{
+			SalesVolume:      totals.SalesVolume,
+			TransactionCount: totals.TransactionCount,
+			UniqueCustomers:  totals.UniqueCustomers,
+			RepeatCustomers:  totals.RepeatCustomers,
+			RepeatVisitRate:  repeatVisitRate(totals.RepeatCustomers, totals.UniqueCustomers),
+		},
+		Series:   make([]merchant_responses.AnalyticsPeriod, len(periods)),
+		Branches: make([]merchant_responses.BranchAnalytics, len(branches)),
+	}
+
+	// Periods are matched by instant, as the database returns them in its
+	// own location.
+	series := make(map[int64]*merchant_responses.AnalyticsPeriod, len(periods))
+	for i, period := range periods {
+		response.Series[i].Period = period
+		series[period.Unix()] = &response.Series[i]
+	}
+	for _, row := range sales {
+		if period, ok := series[row.Period.Unix()]; ok {
+			period.SalesVolume = row.SalesVolume
+			period.TransactionCount = row.TransactionCount
+			period.UniqueCustomers = row.UniqueCustomers
+		}
+	}
+	for _, row := range movements {
+		addRewardMovement(&response.Totals.RewardMovements, row)
+		if period, ok := series[row.Period.Unix()]; ok {
+			addRewardMovement(&period.RewardMovements, row)
+		}
+	}
+	for _, row := range outstanding {
+		addRewardAmount(&response.OutstandingLiability, row.RewardType, row.Amount)
+	}
+
+	for i, branch := range branches {
+		response.Branches[i] = merchant_responses.BranchAnalytics{
+			BranchID:         branch.BranchID,
+			Name:             branch.BranchName,
+			SalesVolume:      branch.SalesVolume,
+			TransactionCount: branch.TransactionCount,
+			UniqueCustomers:  branch.UniqueCustomers,
+			RepeatCustomers:  branch.RepeatCustomers,
+			RepeatVisitRate:  repeatVisitRate(branch.RepeatCustomers, branch.UniqueCustomers),
+		}
+	}
+
+	return response, nil
+}
+
+// truncatePeriod returns the start of the period of t in UTC, with weeks
+// starting on Monday as in date_trunc.
+func truncatePeriod(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	switch granularity {
+	case merchant_ports.GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case merchant_ports.GranularityWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func nextPeriod(period time.Time, granularity string) time.Time {
+	switch granularity {
+	case merchant_ports.GranularityMonth:
+		return period.AddDate(0, 1, 0)
+	case merchant_ports.GranularityWeek:
+		return period.AddDate(0, 0, 7)
+	default:
+		return period.AddDate(0, 0, 1)
+	}
+}
+
+func repeatVisitRate(repeatCustomers, uniqueCustomers int64) float64 {
+	if uniqueCustomers == 0 {
+		return 0
+	}
+	return float64(repeatCustomers) / float64(uniqueCustomers)
+}
+
+func addRewardMovement(movements *merchant_responses.RewardMovements, row merchant_ports.RewardMovement) {
+	var amounts *merchant_responses.RewardAmounts
+	switch row.EventType {
+	case events.RewardGranted:
+		amounts = &movements.Issued
+	case events.RewardRedeemed:
+		amounts = &movements.Redeemed
+	case events.RewardCredited:
+		amounts = &movements.Credited
+	case events.RewardDebited:
+		amounts = &movements.Debited
+	case events.RewardRefunded:
+		amounts = &movements.Refunded
+	default:
+		return
+	}
+	addRewardAmount(amounts, row.RewardType, row.Amount)
+}
+
+func addRewardAmount(amounts *merchant_responses.RewardAmounts, rewardType string, amount float64) {
+	switch rewardType {
+	case "points":
+		amounts.Points += amount
+	case "cashback":
+		amounts.Cashback += amount
+	}
+}
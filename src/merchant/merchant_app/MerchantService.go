@@ -1,18 +1,21 @@
 package merchant_app
 
 import (
+	"context"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/merchant/merchant_domain/merchant_ports"
 	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_requests"
 	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_responses"
 )
 
 type IMerchantService interface {
-	CreateMerchant(req merchant_requests.CreateMerchantRequest) (*merchant_responses.MerchantResponse, error)
-	ListMerchants() ([]*merchant_responses.MerchantResponse, error)
-	GetMerchant(id uint) (*merchant_responses.MerchantResponse, error)
-	UpdateMerchant(id uint, req merchant_requests.UpdateMerchantRequest) (*merchant_responses.MerchantResponse, error)
-	DeleteMerchant(id uint) error
+	CreateMerchant(ctx context.Context, req merchant_requests.CreateMerchantRequest) (*merchant_responses.MerchantResponse, error)
+	ListMerchants(ctx context.Context, req merchant_requests.ListMerchantsRequest) (*pagination.Page[merchant_responses.MerchantResponse], error)
+	GetMerchant(ctx context.Context, id uint) (*merchant_responses.MerchantResponse, error)
+	UpdateMerchant(ctx context.Context, id uint, req merchant_requests.UpdateMerchantRequest) (*merchant_responses.MerchantResponse, error)
+	DeleteMerchant(ctx context.Context, id uint) error
 }
 
 type MerchantService struct {
@@ -23,80 +26,161 @@ func NewMerchantService(repo merchant_ports.IMerchantRepository) IMerchantServic
 	return &MerchantService{repo: repo}
 }
 
-func (s *MerchantService) CreateMerchant(req merchant_requests.CreateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
+func (s *MerchantService) CreateMerchant(ctx context.Context, req merchant_requests.CreateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	merchant := &models.Merchant{
-		Name:             req.Name,
-		ConversionFactor: req.ConversionFactor,
+		Name:               req.Name,
+		ConversionFactor:   req.ConversionFactor,
+		DefaultRewardType:  req.DefaultRewardType,
+		RewardValidityDays: req.RewardValidityDays,
+		RewardVestingDays:  req.RewardVestingDays,
+		ReferrerBonus:      req.ReferrerBonus,
+		RefereeBonus:       req.RefereeBonus,
+		ReferralMinAmount:  req.ReferralMinAmount,
+		EnrollmentBonus:    req.EnrollmentBonus,
+		BirthdayBonus:      req.BirthdayBonus,
+		AnniversaryBonus:   req.AnniversaryBonus,
+		BonusWindowDays:    req.BonusWindowDays,
 	}
 
-	err := s.repo.Create(merchant)
+	err = s.repo.Create(ctx, merchant)
 	if err != nil {
 		return nil, err
 	}
 
 	return &merchant_responses.MerchantResponse{
-		ID:                merchant.ID,
-		Name:              merchant.Name,
-		ConversionFactor:  merchant.ConversionFactor,
-		DefaultRewardType: merchant.DefaultRewardType,
+		ID:                 merchant.ID,
+		Name:               merchant.Name,
+		ConversionFactor:   merchant.ConversionFactor,
+		DefaultRewardType:  merchant.DefaultRewardType,
+		RewardValidityDays: merchant.RewardValidityDays,
+		RewardVestingDays:  merchant.RewardVestingDays,
+		ReferrerBonus:      merchant.ReferrerBonus,
+		RefereeBonus:       merchant.RefereeBonus,
+		ReferralMinAmount:  merchant.ReferralMinAmount,
+		EnrollmentBonus:    merchant.EnrollmentBonus,
+		BirthdayBonus:      merchant.BirthdayBonus,
+		AnniversaryBonus:   merchant.AnniversaryBonus,
+		BonusWindowDays:    merchant.BonusWindowDays,
 	}, nil
 }
 
-func (s *MerchantService) ListMerchants() ([]*merchant_responses.MerchantResponse, error) {
-	merchants, err := s.repo.List()
+func (s *MerchantService) ListMerchants(ctx context.Context, req merchant_requests.ListMerchantsRequest) (*pagination.Page[merchant_responses.MerchantResponse], error) {
+	merchantID, err := security.MerchantScope(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*merchant_responses.MerchantResponse
-	for _, merchant := range merchants {
-		response = append(response, &merchant_responses.MerchantResponse{
-			ID:                merchant.ID,
-			Name:              merchant.Name,
-			ConversionFactor:  merchant.ConversionFactor,
-			DefaultRewardType: merchant.DefaultRewardType,
-		})
+	page, err := s.repo.List(ctx, merchant_ports.MerchantFilter{
+		MerchantID: merchantID,
+		Name:       req.Name,
+	}, req.Request)
+	if err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	return pagination.Map(page, func(merchant *models.Merchant) merchant_responses.MerchantResponse {
+		return merchant_responses.MerchantResponse{
+			ID:                 merchant.ID,
+			Name:               merchant.Name,
+			ConversionFactor:   merchant.ConversionFactor,
+			DefaultRewardType:  merchant.DefaultRewardType,
+			RewardValidityDays: merchant.RewardValidityDays,
+			RewardVestingDays:  merchant.RewardVestingDays,
+			ReferrerBonus:      merchant.ReferrerBonus,
+			RefereeBonus:       merchant.RefereeBonus,
+			ReferralMinAmount:  merchant.ReferralMinAmount,
+			EnrollmentBonus:    merchant.EnrollmentBonus,
+			BirthdayBonus:      merchant.BirthdayBonus,
+			AnniversaryBonus:   merchant.AnniversaryBonus,
+			BonusWindowDays:    merchant.BonusWindowDays,
+		}
+	}), nil
 }
 
-func (s *MerchantService) GetMerchant(id uint) (*merchant_responses.MerchantResponse, error) {
-	merchant, err := s.repo.GetByID(id)
+func (s *MerchantService) GetMerchant(ctx context.Context, id uint) (*merchant_responses.MerchantResponse, error) {
+	err := security.Authorize(ctx, security.ActionRead, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &merchant_responses.MerchantResponse{
-		ID:                merchant.ID,
-		Name:              merchant.Name,
-		ConversionFactor:  merchant.ConversionFactor,
-		DefaultRewardType: merchant.DefaultRewardType,
+		ID:                 merchant.ID,
+		Name:               merchant.Name,
+		ConversionFactor:   merchant.ConversionFactor,
+		DefaultRewardType:  merchant.DefaultRewardType,
+		RewardValidityDays: merchant.RewardValidityDays,
+		RewardVestingDays:  merchant.RewardVestingDays,
+		ReferrerBonus:      merchant.ReferrerBonus,
+		RefereeBonus:       merchant.RefereeBonus,
+		ReferralMinAmount:  merchant.ReferralMinAmount,
+		EnrollmentBonus:    merchant.EnrollmentBonus,
+		BirthdayBonus:      merchant.BirthdayBonus,
+		AnniversaryBonus:   merchant.AnniversaryBonus,
+		BonusWindowDays:    merchant.BonusWindowDays,
 	}, nil
 }
 
-func (s *MerchantService) UpdateMerchant(id uint, req merchant_requests.UpdateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
-	merchant, err := s.repo.GetByID(id)
+func (s *MerchantService) UpdateMerchant(ctx context.Context, id uint, req merchant_requests.UpdateMerchantRequest) (*merchant_responses.MerchantResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	merchant.Name = req.Name
 	merchant.ConversionFactor = req.ConversionFactor
-
-	err = s.repo.Update(merchant)
+	merchant.DefaultRewardType = req.DefaultRewardType
+	merchant.RewardValidityDays = req.RewardValidityDays
+	merchant.RewardVestingDays = req.RewardVestingDays
+	merchant.ReferrerBonus = req.ReferrerBonus
+	merchant.RefereeBonus = req.RefereeBonus
+	merchant.ReferralMinAmount = req.ReferralMinAmount
+	merchant.EnrollmentBonus = req.EnrollmentBonus
+	merchant.BirthdayBonus = req.BirthdayBonus
+	merchant.AnniversaryBonus = req.AnniversaryBonus
+	merchant.BonusWindowDays = req.BonusWindowDays
+
+	err = s.repo.Update(ctx, merchant)
 	if err != nil {
 		return nil, err
 	}
 
 	return &merchant_responses.MerchantResponse{
-		ID:                merchant.ID,
-		Name:              merchant.Name,
-		ConversionFactor:  merchant.ConversionFactor,
-		DefaultRewardType: merchant.DefaultRewardType,
+		ID:                 merchant.ID,
+		Name:               merchant.Name,
+		ConversionFactor:   merchant.ConversionFactor,
+		DefaultRewardType:  merchant.DefaultRewardType,
+		RewardValidityDays: merchant.RewardValidityDays,
+		RewardVestingDays:  merchant.RewardVestingDays,
+		ReferrerBonus:      merchant.ReferrerBonus,
+		RefereeBonus:       merchant.RefereeBonus,
+		ReferralMinAmount:  merchant.ReferralMinAmount,
+		EnrollmentBonus:    merchant.EnrollmentBonus,
+		BirthdayBonus:      merchant.BirthdayBonus,
+		AnniversaryBonus:   merchant.AnniversaryBonus,
+		BonusWindowDays:    merchant.BonusWindowDays,
 	}, nil
 }
 
-func (s *MerchantService) DeleteMerchant(id uint) error {
-	return s.repo.Delete(id)
+func (s *MerchantService) DeleteMerchant(ctx context.Context, id uint) error {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
 }
@@ -0,0 +1,172 @@
+package merchant_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/merchant/merchant_app"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_ports"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_requests"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_responses"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("AnalyticsService", func() {
+	var (
+		analyticsService merchant_app.IAnalyticsService
+		mockRepo         *mockAnalyticsRepository
+		merchantID       uint
+		ctx              context.Context
+	)
+
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	BeforeEach(func() {
+		mockRepo = new(mockAnalyticsRepository)
+		analyticsService = merchant_app.NewAnalyticsService(mockRepo)
+		merchantID = 2
+		ctx = security.WithPrincipal(context.Background(), &security.Principal{Role: security.RoleAnalyst, MerchantID: merchantID})
+	})
+
+	Describe("GetAnalytics", func() {
+		It("should build a weekly series with the totals, rewards and branches of the range", func() {
+			// 2024-05-01 is a Wednesday, so the first week starts on April 29.
+			filter := merchant_ports.AnalyticsFilter{MerchantID: merchantID, From: day(5, 1), To: day(5, 15), Granularity: merchant_ports.GranularityWeek}
+			mockRepo.On("SalesTotals", mock.Anything, filter).Return(&merchant_ports.SalesTotals{SalesVolume: 500, TransactionCount: 5, UniqueCustomers: 4, RepeatCustomers: 1}, nil)
+			mockRepo.On("SalesByPeriod", mock.Anything, filter).Return([]merchant_ports.SalesPeriod{
+				{Period: day(4, 29), SalesVolume: 200, TransactionCount: 2, UniqueCustomers: 2},
+				{Period: day(5, 13).In(time.FixedZone("UTC-3", -3*3600)), SalesVolume: 300, TransactionCount: 3, UniqueCustomers: 3},
+			}, nil)
+			mockRepo.On("SalesByBranch", mock.Anything, filter).Return([]merchant_ports.BranchSales{
+				{BranchID: 3, BranchName: "Centro", SalesVolume: 500, TransactionCount: 5, UniqueCustomers: 4, RepeatCustomers: 1},
+			}, nil)
+			mockRepo.On("RewardMovements", mock.Anything, filter).Return([]merchant_ports.RewardMovement{
+				{Period: day(4, 29), EventType: events.RewardGranted, RewardType: "points", Amount: 20},
+				{Period: day(5, 6), EventType: events.RewardRedeemed, RewardType: "points", Amount: 5},
+				{Period: day(5, 13), EventType: events.RewardGranted, RewardType: "cashback", Amount: 3},
+				{Period: day(5, 13), EventType: events.RewardCredited, RewardType: "points", Amount: 4},
+				{Period: day(5, 13), EventType: events.RewardDebited, RewardType: "points", Amount: 2},
+				{Period: day(5, 13), EventType: events.RewardRefunded, RewardType: "points", Amount: 1},
+				{Period: day(5, 13), EventType: events.RewardExpired, RewardType: "points", Amount: 9},
+			}, nil)
+			mockRepo.On("OutstandingRewards", mock.Anything, merchantID, mock.AnythingOfType("time.Time")).Return([]merchant_ports.RewardAmount{
+				{RewardType: "points", Amount: 15},
+				{RewardType: "cashback", Amount: 3},
+			}, nil)
+
+			response, err := analyticsService.GetAnalytics(ctx, merchantID, merchant_requests.AnalyticsRequest{
+				From:        day(5, 1),
+				To:          day(5, 14),
+				Granularity: merchant_ports.GranularityWeek,
+			})
+
+			Expect(err).To(BeNil())
+			Expect(response.To).To(Equal(day(5, 14)))
+			Expect(response.Totals).To(Equal(merchant_responses.AnalyticsTotals{
+				SalesVolume:      500,
+				TransactionCount: 5,
+				UniqueCustomers:  4,
+				RepeatCustomers:  1,
+				RepeatVisitRate:  0.25,
+				RewardMovements: merchant_responses.RewardMovements{
+					Issued:   merchant_responses.RewardAmounts{Points: 20, Cashback: 3},
+					Redeemed: merchant_responses.RewardAmounts{Points: 5},
+					Credited: merchant_responses.RewardAmounts{Points: 4},
+					Debited:  merchant_responses.RewardAmounts{Points: 2},
+					Refunded: merchant_responses.RewardAmounts{Points: 1},
+				},
+			}))
+			Expect(response.OutstandingLiability).To(Equal(merchant_responses.RewardAmounts{Points: 15, Cashback: 3}))
+			Expect(response.Series).To(Equal([]merchant_responses.AnalyticsPeriod{
+				{Period: day(4, 29), SalesVolume: 200, TransactionCount: 2, UniqueCustomers: 2, RewardMovements: merchant_responses.RewardMovements{
+					Issued: merchant_responses.RewardAmounts{Points: 20},
+				}},
+				{Period: day(5, 6), RewardMovements: merchant_responses.RewardMovements{
+					Redeemed: merchant_responses.RewardAmounts{Points: 5},
+				}},
+				{Period: day(5, 13), SalesVolume: 300, TransactionCount: 3, UniqueCustomers: 3, RewardMovements: merchant_responses.RewardMovements{
+					Issued:   merchant_responses.RewardAmounts{Cashback: 3},
+					Credited: merchant_responses.RewardAmounts{Points: 4},
+					Debited:  merchant_responses.RewardAmounts{Points: 2},
+					Refunded: merchant_responses.RewardAmounts{Points: 1},
+				}},
+			}))
+			Expect(response.Branches).To(HaveLen(1))
+			Expect(response.Branches[0].Name).To(Equal("Centro"))
+			Expect(response.Branches[0].RepeatVisitRate).To(Equal(0.25))
+		})
+
+		It("should default to a daily series", func() {
+			mockRepo.On("SalesTotals", mock.Anything, mock.Anything).Return(&merchant_ports.SalesTotals{}, nil)
+			mockRepo.On("SalesByPeriod", mock.Anything, mock.Anything).Return([]merchant_ports.SalesPeriod{}, nil)
+			mockRepo.On("SalesByBranch", mock.Anything, mock.Anything).Return([]merchant_ports.BranchSales{}, nil)
+			mockRepo.On("RewardMovements", mock.Anything, mock.Anything).Return([]merchant_ports.RewardMovement{}, nil)
+			mockRepo.On("OutstandingRewards", mock.Anything, merchantID, mock.Anything).Return([]merchant_ports.RewardAmount{}, nil)
+
+			response, err := analyticsService.GetAnalytics(ctx, merchantID, merchant_requests.AnalyticsRequest{From: day(5, 1), To: day(5, 3)})
+
+			Expect(err).To(BeNil())
+			Expect(response.Granularity).To(Equal(merchant_ports.GranularityDay))
+			Expect(response.Series).To(HaveLen(3))
+			Expect(response.Totals.RepeatVisitRate).To(BeZero())
+		})
+
+		It("should reject a range that ends before it starts", func() {
+			_, err := analyticsService.GetAnalytics(ctx, merchantID, merchant_requests.AnalyticsRequest{From: day(5, 2), To: day(5, 1)})
+
+			Expect(err).To(MatchError(merchant_app.ErrInvalidAnalyticsRange))
+		})
+
+		It("should reject a series with too many periods", func() {
+			_, err := analyticsService.GetAnalytics(ctx, merchantID, merchant_requests.AnalyticsRequest{From: day(1, 1), To: day(1, 1).AddDate(2, 0, 0)})
+
+			Expect(err).To(MatchError(merchant_app.ErrAnalyticsRangeTooLarge))
+			mockRepo.AssertNotCalled(GinkgoT(), "SalesTotals", mock.Anything, mock.Anything)
+		})
+
+		It("should not show the analytics of another merchant", func() {
+			_, err := analyticsService.GetAnalytics(ctx, merchantID+1, merchant_requests.AnalyticsRequest{From: day(5, 1), To: day(5, 3)})
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+		})
+	})
+})
+
+type mockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *mockAnalyticsRepository) SalesTotals(ctx context.Context, filter merchant_ports.AnalyticsFilter) (*merchant_ports.SalesTotals, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*merchant_ports.SalesTotals), args.Error(1)
+}
+
+func (m *mockAnalyticsRepository) SalesByPeriod(ctx context.Context, filter merchant_ports.AnalyticsFilter) ([]merchant_ports.SalesPeriod, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]merchant_ports.SalesPeriod), args.Error(1)
+}
+
+func (m *mockAnalyticsRepository) SalesByBranch(ctx context.Context, filter merchant_ports.AnalyticsFilter) ([]merchant_ports.BranchSales, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]merchant_ports.BranchSales), args.Error(1)
+}
+
+func (m *mockAnalyticsRepository) RewardMovements(ctx context.Context, filter merchant_ports.AnalyticsFilter) ([]merchant_ports.RewardMovement, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]merchant_ports.RewardMovement), args.Error(1)
+}
+
+func (m *mockAnalyticsRepository) OutstandingRewards(ctx context.Context, merchantID uint, currentDate time.Time) ([]merchant_ports.RewardAmount, error) {
+	args := m.Called(ctx, merchantID, currentDate)
+	return args.Get(0).([]merchant_ports.RewardAmount), args.Error(1)
+}
@@ -0,0 +1,13 @@
+package merchant_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMerchantApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MerchantApp Suite")
+}
@@ -0,0 +1,18 @@
+package merchant_ports
+
+import "time"
+
+// Analytics granularities, named after the units of date_trunc.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// AnalyticsFilter selects the activity of a merchant in [From, To).
+type AnalyticsFilter struct {
+	MerchantID  uint
+	From        time.Time
+	To          time.Time
+	Granularity string
+}
@@ -0,0 +1,53 @@
+package merchant_ports
+
+import (
+	"context"
+	"time"
+)
+
+// SalesTotals aggregates the transactions of a merchant or one of its branches.
+// Repeat customers are those with more than one transaction in the range.
+type SalesTotals struct {
+	SalesVolume      float64
+	TransactionCount int64
+	UniqueCustomers  int64
+	RepeatCustomers  int64
+}
+
+type SalesPeriod struct {
+	Period           time.Time
+	SalesVolume      float64
+	TransactionCount int64
+	UniqueCustomers  int64
+}
+
+type BranchSales struct {
+	BranchID         uint
+	BranchName       string
+	SalesVolume      float64
+	TransactionCount int64
+	UniqueCustomers  int64
+	RepeatCustomers  int64
+}
+
+// RewardMovement is the amount of a reward type issued or spent in a period,
+// by the type of the event that recorded it.
+type RewardMovement struct {
+	Period     time.Time
+	EventType  string
+	RewardType string
+	Amount     float64
+}
+
+type RewardAmount struct {
+	RewardType string
+	Amount     float64
+}
+
+type IAnalyticsRepository interface {
+	SalesTotals(ctx context.Context, filter AnalyticsFilter) (*SalesTotals, error)
+	SalesByPeriod(ctx context.Context, filter AnalyticsFilter) ([]SalesPeriod, error)
+	SalesByBranch(ctx context.Context, filter AnalyticsFilter) ([]BranchSales, error)
+	RewardMovements(ctx context.Context, filter AnalyticsFilter) ([]RewardMovement, error)
+	OutstandingRewards(ctx context.Context, merchantID uint, currentDate time.Time) ([]RewardAmount, error)
+}
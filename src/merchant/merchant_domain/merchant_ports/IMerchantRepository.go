@@ -1,11 +1,15 @@
 package merchant_ports
 
-import "loyalty-campaigns/src/common/models"
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+)
 
 type IMerchantRepository interface {
-	Create(merchant *models.Merchant) error
-	GetByID(id uint) (*models.Merchant, error)
-	Update(merchant *models.Merchant) error
-	Delete(id uint) error
-	List() ([]models.Merchant, error)
+	Create(ctx context.Context, merchant *models.Merchant) error
+	GetByID(ctx context.Context, id uint) (*models.Merchant, error)
+	Update(ctx context.Context, merchant *models.Merchant) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter MerchantFilter, page pagination.Request) (*pagination.Page[models.Merchant], error)
 }
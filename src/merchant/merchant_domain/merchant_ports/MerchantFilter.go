@@ -0,0 +1,6 @@
+package merchant_ports
+
+type MerchantFilter struct {
+	MerchantID *uint
+	Name       string
+}
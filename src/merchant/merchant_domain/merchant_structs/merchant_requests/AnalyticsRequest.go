@@ -0,0 +1,10 @@
+package merchant_requests
+
+import "time"
+
+// AnalyticsRequest selects the days from From to To, both included.
+type AnalyticsRequest struct {
+	From        time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To          time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	Granularity string    `form:"granularity" binding:"omitempty,oneof=day week month"`
+}
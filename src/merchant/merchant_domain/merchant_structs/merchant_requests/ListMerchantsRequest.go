@@ -0,0 +1,9 @@
+package merchant_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListMerchantsRequest accepts sort by id, name or createdAt.
+type ListMerchantsRequest struct {
+	pagination.Request
+	Name string `form:"name"`
+}
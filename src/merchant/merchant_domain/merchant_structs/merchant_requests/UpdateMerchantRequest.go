@@ -2,6 +2,23 @@ package merchant_requests
 
 type UpdateMerchantRequest struct {
 	Name              string  `json:"name" binding:"required"`
-	ConversionFactor  float64 `json:"conversion_factor" binding:"required"`
+	ConversionFactor  float64 `json:"conversion_factor" binding:"required,gt=0"`
 	DefaultRewardType string  `json:"defaultRewardType" binding:"required,oneof=points cashback"`
+	// RewardValidityDays makes the rewards granted by the merchant expire after that many days.
+	RewardValidityDays *int `json:"rewardValidityDays" binding:"omitempty,min=1"`
+	// RewardVestingDays keeps the rewards earned by transactions pending, and
+	// revocable by a reversal, for that many days.
+	RewardVestingDays *int `json:"rewardVestingDays" binding:"omitempty,min=1"`
+	// ReferrerBonus and RefereeBonus are granted when a referred user makes
+	// their first transaction of at least ReferralMinAmount; leave both out
+	// to turn referrals off.
+	ReferrerBonus     *float64 `json:"referrerBonus" binding:"omitempty,gt=0"`
+	RefereeBonus      *float64 `json:"refereeBonus" binding:"omitempty,gt=0"`
+	ReferralMinAmount *float64 `json:"referralMinAmount" binding:"omitempty,gt=0"`
+	// EnrollmentBonus, BirthdayBonus and AnniversaryBonus are granted by the
+	// daily lifecycle bonuses job, up to BonusWindowDays after the date.
+	EnrollmentBonus  *float64 `json:"enrollmentBonus" binding:"omitempty,gt=0"`
+	BirthdayBonus    *float64 `json:"birthdayBonus" binding:"omitempty,gt=0"`
+	AnniversaryBonus *float64 `json:"anniversaryBonus" binding:"omitempty,gt=0"`
+	BonusWindowDays  *int     `json:"bonusWindowDays" binding:"omitempty,min=0"`
 }
@@ -0,0 +1,62 @@
+package merchant_responses
+
+import "time"
+
+type AnalyticsResponse struct {
+	MerchantID  uint            `json:"merchant_id"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	Granularity string          `json:"granularity"`
+	Totals      AnalyticsTotals `json:"totals"`
+	// OutstandingLiability is what the merchant owes in unexpired rewards now,
+	// whatever the range.
+	OutstandingLiability RewardAmounts     `json:"outstanding_liability"`
+	Series               []AnalyticsPeriod `json:"series"`
+	Branches             []BranchAnalytics `json:"branches"`
+}
+
+type RewardAmounts struct {
+	Points   float64 `json:"points"`
+	Cashback float64 `json:"cashback"`
+}
+
+// RewardMovements are the rewards issued and spent. Issued counts the rewards
+// granted, at the amount of their last adjustment and leaving out the revoked
+// ones; Credited and Debited are manual adjustments, and Refunded the rewards
+// returned by cancelled catalog redemptions.
+type RewardMovements struct {
+	Issued   RewardAmounts `json:"issued"`
+	Redeemed RewardAmounts `json:"redeemed"`
+	Credited RewardAmounts `json:"credited"`
+	Debited  RewardAmounts `json:"debited"`
+	Refunded RewardAmounts `json:"refunded"`
+}
+
+type AnalyticsTotals struct {
+	SalesVolume      float64 `json:"sales_volume"`
+	TransactionCount int64   `json:"transaction_count"`
+	UniqueCustomers  int64   `json:"unique_customers"`
+	RepeatCustomers  int64   `json:"repeat_customers"`
+	// RepeatVisitRate is the share of unique customers with more than one
+	// transaction in the range.
+	RepeatVisitRate float64 `json:"repeat_visit_rate"`
+	RewardMovements
+}
+
+type AnalyticsPeriod struct {
+	Period           time.Time `json:"period"`
+	SalesVolume      float64   `json:"sales_volume"`
+	TransactionCount int64     `json:"transaction_count"`
+	UniqueCustomers  int64     `json:"unique_customers"`
+	RewardMovements
+}
+
+type BranchAnalytics struct {
+	BranchID         uint    `json:"branch_id"`
+	Name             string  `json:"name"`
+	SalesVolume      float64 `json:"sales_volume"`
+	TransactionCount int64   `json:"transaction_count"`
+	UniqueCustomers  int64   `json:"unique_customers"`
+	RepeatCustomers  int64   `json:"repeat_customers"`
+	RepeatVisitRate  float64 `json:"repeat_visit_rate"`
+}
@@ -1,8 +1,17 @@
 package merchant_responses
 
 type MerchantResponse struct {
-	ID                uint    `json:"id"`
-	Name              string  `json:"name"`
-	ConversionFactor  float64 `json:"conversion_factor"`
-	DefaultRewardType string  `json:"defaultRewardType"`
+	ID                 uint     `json:"id"`
+	Name               string   `json:"name"`
+	ConversionFactor   float64  `json:"conversion_factor"`
+	DefaultRewardType  string   `json:"defaultRewardType"`
+	RewardValidityDays *int     `json:"rewardValidityDays,omitempty"`
+	RewardVestingDays  *int     `json:"rewardVestingDays,omitempty"`
+	ReferrerBonus      *float64 `json:"referrerBonus,omitempty"`
+	RefereeBonus       *float64 `json:"refereeBonus,omitempty"`
+	ReferralMinAmount  *float64 `json:"referralMinAmount,omitempty"`
+	EnrollmentBonus    *float64 `json:"enrollmentBonus,omitempty"`
+	BirthdayBonus      *float64 `json:"birthdayBonus,omitempty"`
+	AnniversaryBonus   *float64 `json:"anniversaryBonus,omitempty"`
+	BonusWindowDays    *int     `json:"bonusWindowDays,omitempty"`
 }
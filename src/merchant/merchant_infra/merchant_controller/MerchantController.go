@@ -2,6 +2,9 @@ package merchant_controller
 
 import (
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/merchant/merchant_app"
 	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_requests"
 	"loyalty-campaigns/src/merchant/merchant_infra/merchant_repository"
@@ -13,31 +16,34 @@ import (
 )
 
 type MerchantController struct {
-	service merchant_app.IMerchantService
+	service   merchant_app.IMerchantService
+	analytics merchant_app.IAnalyticsService
 }
 
 var merchantControllerInstance *MerchantController
 var merchantControllerOnce sync.Once
 
-func NewMerchantController(router *gin.Engine) *MerchantController {
+func NewMerchantController(router *gin.RouterGroup) *MerchantController {
 	merchantControllerOnce.Do(func() {
 		merchantControllerInstance = &MerchantController{}
 		db := configs.NewDBConnection().GetDB()
 		merchantRepository := merchant_repository.NewGormMerchantRepository(db)
 		merchantControllerInstance.service = merchant_app.NewMerchantService(merchantRepository)
+		merchantControllerInstance.analytics = merchant_app.NewAnalyticsService(merchant_repository.NewGormAnalyticsRepository(db))
 		merchantControllerInstance.setupMerchantRoutes(router)
 	})
 	return merchantControllerInstance
 }
 
-func (c *MerchantController) setupMerchantRoutes(router *gin.Engine) {
-	merchantGroup := router.Group("/api/merchants")
+func (c *MerchantController) setupMerchantRoutes(router *gin.RouterGroup) {
+	merchantGroup := router.Group("/merchants")
 	{
-		merchantGroup.POST("", c.CreateMerchant)
+		merchantGroup.POST("", security.RequireAdmin(), c.CreateMerchant)
 		merchantGroup.GET("", c.ListMerchants)
 		merchantGroup.GET("/:id", c.GetMerchant)
+		merchantGroup.GET("/:id/analytics", c.GetAnalytics)
 		merchantGroup.PUT("/:id", c.UpdateMerchant)
-		merchantGroup.DELETE("/:id", c.DeleteMerchant)
+		merchantGroup.DELETE("/:id", security.RequireAdmin(), c.DeleteMerchant)
 	}
 }
 
@@ -47,21 +53,22 @@ func (c *MerchantController) setupMerchantRoutes(router *gin.Engine) {
 //	@Tags			merchants
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		merchant_requests.CreateMerchantRequest	true	"Merchant creation request"
 //	@Success		201		{object}	merchant_responses.MerchantResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/merchants [post]
 func (c *MerchantController) CreateMerchant(ctx *gin.Context) {
 	var req merchant_requests.CreateMerchantRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.service.CreateMerchant(req)
+	response, err := c.service.CreateMerchant(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -69,22 +76,31 @@ func (c *MerchantController) CreateMerchant(ctx *gin.Context) {
 }
 
 // ListMerchants godoc
-//	@Summary		List all merchants
-//	@Description	Get a list of all merchants in the system
+//	@Summary		List merchants
+//	@Description	Get a page of merchants; merchant keys only see their own. Sort by id, name or createdAt, prefixed with "-" for descending order.
 //	@Tags			merchants
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		merchant_responses.MerchantResponse
-//	@Failure		500	{object}	map[string]string
+//	@Security		ApiKeyAuth
+//	@Param			request	query		merchant_requests.ListMerchantsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[merchant_responses.MerchantResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/merchants [get]
 func (c *MerchantController) ListMerchants(ctx *gin.Context) {
-	response, err := c.service.ListMerchants()
+	var req merchant_requests.ListMerchantsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.service.ListMerchants(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetMerchant godoc
@@ -93,21 +109,22 @@ func (c *MerchantController) ListMerchants(ctx *gin.Context) {
 //	@Tags			merchants
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Merchant ID"
 //	@Success		200	{object}	merchant_responses.MerchantResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/merchants/{id} [get]
 func (c *MerchantController) GetMerchant(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.service.GetMerchant(uint(id))
+	response, err := c.service.GetMerchant(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -120,28 +137,29 @@ func (c *MerchantController) GetMerchant(ctx *gin.Context) {
 //	@Tags			merchants
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id		path		int										true	"Merchant ID"
 //	@Param			request	body		merchant_requests.UpdateMerchantRequest	true	"Merchant update request"
 //	@Success		200		{object}	merchant_responses.MerchantResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/merchants/{id} [put]
 func (c *MerchantController) UpdateMerchant(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
 	var req merchant_requests.UpdateMerchantRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.service.UpdateMerchant(uint(id), req)
+	response, err := c.service.UpdateMerchant(ctx.Request.Context(), uint(id), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -154,23 +172,60 @@ func (c *MerchantController) UpdateMerchant(ctx *gin.Context) {
 //	@Tags			merchants
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path	int	true	"Merchant ID"
 //	@Success		204	"No Content"
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/merchants/{id} [delete]
 func (c *MerchantController) DeleteMerchant(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("id"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	err = c.service.DeleteMerchant(uint(id))
+	err = c.service.DeleteMerchant(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
+
+// GetAnalytics godoc
+//	@Summary		Get merchant analytics
+//	@Description	Get the sales volume, transaction count, unique customers, repeat-visit rate, rewards issued and redeemed and per-branch breakdown of a merchant between two days, both included, with a series by day, week or month in UTC. The outstanding liability is the unexpired rewards at the time of the request.
+//	@Tags			merchants
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int									true	"Merchant ID"
+//	@Param			request	query		merchant_requests.AnalyticsRequest	true	"Date range and granularity"
+//	@Success		200		{object}	merchant_responses.AnalyticsResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/merchants/{id}/analytics [get]
+func (c *MerchantController) GetAnalytics(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	var req merchant_requests.AnalyticsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.analytics.GetAnalytics(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
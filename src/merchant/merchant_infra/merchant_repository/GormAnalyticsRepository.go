@@ -0,0 +1,138 @@
+package merchant_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_ports"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormAnalyticsRepository computes merchant analytics with SQL aggregates.
+// Periods are truncated in UTC.
+type GormAnalyticsRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormAnalyticsRepository(db *gorm.DB) merchant_ports.IAnalyticsRepository {
+	return &GormAnalyticsRepository{DB: db}
+}
+
+// transactions selects the sales of the merchant in the range, leaving out
+// the reversed ones.
+func (r *GormAnalyticsRepository) transactions(ctx context.Context, filter merchant_ports.AnalyticsFilter) *gorm.DB {
+	return configs.DB(ctx, r.DB).
+		Model(&models.Transaction{}).
+		Where("merchant_id = ? AND date >= ? AND date < ? AND reversed_at IS NULL", filter.MerchantID, filter.From, filter.To)
+}
+
+func (r *GormAnalyticsRepository) SalesTotals(ctx context.Context, filter merchant_ports.AnalyticsFilter) (*merchant_ports.SalesTotals, error) {
+	visits := r.transactions(ctx, filter).
+		Select("user_id, COUNT(*) AS visits, SUM(amount) AS sales").
+		Group("user_id")
+
+	var totals merchant_ports.SalesTotals
+	err := configs.DB(ctx, r.DB).
+		Table("(?) AS visits", visits).
+		Select(`COALESCE(SUM(sales), 0) AS sales_volume,
+			COALESCE(SUM(visits), 0) AS transaction_count,
+			COUNT(*) AS unique_customers,
+			COUNT(*) FILTER (WHERE visits > 1) AS repeat_customers`).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+func (r *GormAnalyticsRepository) SalesByPeriod(ctx context.Context, filter merchant_ports.AnalyticsFilter) ([]merchant_ports.SalesPeriod, error) {
+	var periods []merchant_ports.SalesPeriod
+	err := r.transactions(ctx, filter).
+		Select(`date_trunc(?, date AT TIME ZONE 'UTC') AS period,
+			SUM(amount) AS sales_volume,
+			COUNT(*) AS transaction_count,
+			COUNT(DISTINCT user_id) AS unique_customers`, filter.Granularity).
+		Group("period").
+		Order("period").
+		Scan(&periods).Error
+	return periods, err
+}
+
+func (r *GormAnalyticsRepository) SalesByBranch(ctx context.Context, filter merchant_ports.AnalyticsFilter) ([]merchant_ports.BranchSales, error) {
+	visits := r.transactions(ctx, filter).
+		Select("branch_id, user_id, COUNT(*) AS visits, SUM(amount) AS sales").
+		Group("branch_id, user_id")
+
+	var branches []merchant_ports.BranchSales
+	err := configs.DB(ctx, r.DB).
+		Table("(?) AS visits", visits).
+		Select(`visits.branch_id,
+			branches.name AS branch_name,
+			SUM(visits.sales) AS sales_volume,
+			SUM(visits.visits) AS transaction_count,
+			COUNT(*) AS unique_customers,
+			COUNT(*) FILTER (WHERE visits.visits > 1) AS repeat_customers`).
+		Joins("LEFT JOIN branches ON branches.id = visits.branch_id").
+		Group("visits.branch_id, branches.name").
+		Order("sales_volume DESC, visits.branch_id").
+		Scan(&branches).Error
+	return branches, err
+}
+
+// RewardMovements reads the reward events of the outbox: redemptions consume
+// the rewards in place, so the issued amounts only survive in the events. The
+// rewards granted, credited or refunded in the range count in the period they
+// were issued with the amount of their last adjustment, and not at all once
+// revoked, as in the campaign reports. Redemptions and debits count when they
+// happened.
+func (r *GormAnalyticsRepository) RewardMovements(ctx context.Context, filter merchant_ports.AnalyticsFilter) ([]merchant_ports.RewardMovement, error) {
+	issuedTypes := []string{events.RewardGranted, events.RewardCredited, events.RewardRefunded}
+	amountTypes := []string{events.RewardGranted, events.RewardCredited, events.RewardRefunded, events.RewardAdjusted}
+	spentTypes := []string{events.RewardRedeemed, events.RewardDebited}
+
+	var movements []merchant_ports.RewardMovement
+	err := configs.DB(ctx, r.DB).Raw(`
+		WITH issued AS (
+			SELECT aggregate_id, type, occurred_at, payload->>'type' AS reward_type
+			FROM outbox_events
+			WHERE merchant_id = ? AND type IN ? AND occurred_at >= ? AND occurred_at < ?
+		),
+		kept AS (
+			SELECT issued.type, issued.occurred_at, issued.reward_type,
+				(ARRAY_AGG(CAST(changes.payload->>'amount' AS DECIMAL) ORDER BY changes.sequence DESC)
+					FILTER (WHERE changes.type IN ?))[1] AS amount
+			FROM issued
+			JOIN outbox_events AS changes ON changes.aggregate_type = 'reward' AND changes.aggregate_id = issued.aggregate_id
+			GROUP BY issued.aggregate_id, issued.type, issued.occurred_at, issued.reward_type
+			HAVING NOT BOOL_OR(changes.type = ?)
+		)
+		SELECT date_trunc(?, occurred_at AT TIME ZONE 'UTC') AS period, type AS event_type, reward_type, SUM(amount) AS amount
+		FROM kept
+		GROUP BY 1, 2, 3
+		UNION ALL
+		SELECT date_trunc(?, occurred_at AT TIME ZONE 'UTC'), type, payload->>'type', SUM(CAST(payload->>'amount' AS DECIMAL))
+		FROM outbox_events
+		WHERE merchant_id = ? AND type IN ? AND occurred_at >= ? AND occurred_at < ?
+		GROUP BY 1, 2, 3
+		ORDER BY 1
+	`, filter.MerchantID, issuedTypes, filter.From, filter.To,
+		amountTypes, events.RewardRevoked,
+		filter.Granularity,
+		filter.Granularity, filter.MerchantID, spentTypes, filter.From, filter.To).
+		Scan(&movements).Error
+	return movements, err
+}
+
+func (r *GormAnalyticsRepository) OutstandingRewards(ctx context.Context, merchantID uint, currentDate time.Time) ([]merchant_ports.RewardAmount, error) {
+	var amounts []merchant_ports.RewardAmount
+	err := configs.DB(ctx, r.DB).
+		Model(&models.Reward{}).
+		Select("type AS reward_type, SUM(amount) AS amount").
+		Where("merchant_id = ? AND (expiry_date IS NULL OR expiry_date > ?)", merchantID, currentDate).
+		Group("type").
+		Scan(&amounts).Error
+	return amounts, err
+}
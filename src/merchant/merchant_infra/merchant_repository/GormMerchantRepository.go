@@ -1,7 +1,11 @@
 package merchant_repository
 
 import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 	"loyalty-campaigns/src/merchant/merchant_domain/merchant_ports"
 
 	"gorm.io/gorm"
@@ -15,29 +19,45 @@ func NewGormMerchantRepository(db *gorm.DB) merchant_ports.IMerchantRepository {
 	return &GormMerchantRepository{DB: db}
 }
 
-func (r *GormMerchantRepository) Create(merchant *models.Merchant) error {
-	return r.DB.Create(merchant).Error
+func (r *GormMerchantRepository) Create(ctx context.Context, merchant *models.Merchant) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Create(merchant).Error, "merchant")
 }
 
-func (r *GormMerchantRepository) GetByID(id uint) (*models.Merchant, error) {
+func (r *GormMerchantRepository) GetByID(ctx context.Context, id uint) (*models.Merchant, error) {
 	var merchant models.Merchant
-	err := r.DB.First(&merchant, id).Error
+	err := configs.DB(ctx, r.DB).First(&merchant, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "merchant")
 	}
 	return &merchant, nil
 }
 
-func (r *GormMerchantRepository) Update(merchant *models.Merchant) error {
-	return r.DB.Save(merchant).Error
+func (r *GormMerchantRepository) Update(ctx context.Context, merchant *models.Merchant) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Save(merchant).Error, "merchant")
 }
 
-func (r *GormMerchantRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Merchant{}, id).Error
+func (r *GormMerchantRepository) Delete(ctx context.Context, id uint) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Delete(&models.Merchant{}, id).Error, "merchant")
 }
 
-func (r *GormMerchantRepository) List() ([]models.Merchant, error) {
-	var merchants []models.Merchant
-	err := r.DB.Find(&merchants).Error
-	return merchants, err
+var merchantSorting = pagination.Sorting[models.Merchant]{
+	IDColumn: "id",
+	ID:       func(merchant *models.Merchant) uint { return merchant.ID },
+	Fields: map[string]pagination.Key[models.Merchant]{
+		"id":        {Column: "id", Value: func(merchant *models.Merchant) any { return merchant.ID }},
+		"name":      {Column: "name", Value: func(merchant *models.Merchant) any { return merchant.Name }},
+		"createdAt": {Column: "created_at", Value: func(merchant *models.Merchant) any { return merchant.CreatedAt }},
+	},
+	Default: "id",
+}
+
+func (r *GormMerchantRepository) List(ctx context.Context, filter merchant_ports.MerchantFilter, page pagination.Request) (*pagination.Page[models.Merchant], error) {
+	query := configs.DB(ctx, r.DB)
+	if filter.MerchantID != nil {
+		query = query.Where("id = ?", *filter.MerchantID)
+	}
+	if filter.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Name+"%")
+	}
+	return pagination.Find(query, page, merchantSorting)
 }
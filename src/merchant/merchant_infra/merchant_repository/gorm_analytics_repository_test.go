@@ -0,0 +1,136 @@
+package merchant_repository_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_ports"
+	"loyalty-campaigns/src/merchant/merchant_infra/merchant_repository"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("GormAnalyticsRepository", func() {
+	var (
+		sqlMock    sqlmock.Sqlmock
+		repository merchant_ports.IAnalyticsRepository
+		filter     merchant_ports.AnalyticsFilter
+	)
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		repository = merchant_repository.NewGormAnalyticsRepository(db)
+		from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		filter = merchant_ports.AnalyticsFilter{
+			MerchantID:  4,
+			From:        from,
+			To:          from.AddDate(0, 1, 0),
+			Granularity: merchant_ports.GranularityWeek,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	// salesOfMerchant is the filter on the transactions of every sales query.
+	salesOfMerchant := `WHERE \(merchant_id = \$\d AND date >= \$\d AND date < \$\d AND reversed_at IS NULL\) AND "transactions"\."deleted_at" IS NULL`
+
+	Describe("SalesTotals", func() {
+		It("should aggregate the visits of each customer, leaving out reversed sales", func() {
+			sqlMock.ExpectQuery(`^SELECT COALESCE\(SUM\(sales\), 0\) AS sales_volume,.+ FROM \(SELECT user_id, COUNT\(\*\) AS visits, SUM\(amount\) AS sales FROM "transactions" `+salesOfMerchant+` GROUP BY "user_id"\) AS visits$`).
+				WithArgs(filter.MerchantID, filter.From, filter.To).
+				WillReturnRows(sqlmock.NewRows([]string{"sales_volume", "transaction_count", "unique_customers", "repeat_customers"}).AddRow(500, 4, 3, 1))
+
+			totals, err := repository.SalesTotals(context.Background(), filter)
+
+			Expect(err).To(BeNil())
+			Expect(*totals).To(Equal(merchant_ports.SalesTotals{SalesVolume: 500, TransactionCount: 4, UniqueCustomers: 3, RepeatCustomers: 1}))
+		})
+	})
+
+	Describe("SalesByPeriod", func() {
+		It("should group the sales that were not reversed by period", func() {
+			period := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+			sqlMock.ExpectQuery(`^SELECT date_trunc\(\$1, date AT TIME ZONE 'UTC'\) AS period,.+ FROM "transactions" `+salesOfMerchant+` GROUP BY "period" ORDER BY period$`).
+				WithArgs(filter.Granularity, filter.MerchantID, filter.From, filter.To).
+				WillReturnRows(sqlmock.NewRows([]string{"period", "sales_volume", "transaction_count", "unique_customers"}).AddRow(period, 200, 2, 2))
+
+			periods, err := repository.SalesByPeriod(context.Background(), filter)
+
+			Expect(err).To(BeNil())
+			Expect(periods).To(Equal([]merchant_ports.SalesPeriod{{Period: period, SalesVolume: 200, TransactionCount: 2, UniqueCustomers: 2}}))
+		})
+	})
+
+	Describe("SalesByBranch", func() {
+		It("should group the sales that were not reversed by branch", func() {
+			sqlMock.ExpectQuery(`^SELECT visits\.branch_id,.+ FROM \(SELECT branch_id, user_id, COUNT\(\*\) AS visits, SUM\(amount\) AS sales FROM "transactions" `+salesOfMerchant+` GROUP BY branch_id, user_id\) AS visits LEFT JOIN branches ON branches\.id = visits\.branch_id GROUP BY visits\.branch_id, branches\.name ORDER BY sales_volume DESC, visits\.branch_id$`).
+				WithArgs(filter.MerchantID, filter.From, filter.To).
+				WillReturnRows(sqlmock.NewRows([]string{"branch_id", "branch_name", "sales_volume", "transaction_count", "unique_customers", "repeat_customers"}).AddRow(2, "Centro", 300, 3, 2, 1))
+
+			branches, err := repository.SalesByBranch(context.Background(), filter)
+
+			Expect(err).To(BeNil())
+			Expect(branches).To(Equal([]merchant_ports.BranchSales{{BranchID: 2, BranchName: "Centro", SalesVolume: 300, TransactionCount: 3, UniqueCustomers: 2, RepeatCustomers: 1}}))
+		})
+	})
+
+	Describe("RewardMovements", func() {
+		movementColumns := []string{"period", "event_type", "reward_type", "amount"}
+		period := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+		It("should read the rewards issued and spent by the merchant in the range", func() {
+			sqlMock.ExpectQuery(`(?s)WITH issued AS .* FROM outbox_events\s+WHERE merchant_id = \$1 AND type IN \(\$2,\$3,\$4\) AND occurred_at >= \$5 AND occurred_at < \$6\s+\).*`+
+				`UNION ALL.*FROM outbox_events\s+WHERE merchant_id = \$14 AND type IN \(\$15,\$16\) AND occurred_at >= \$17 AND occurred_at < \$18\s+GROUP BY 1, 2, 3\s+ORDER BY 1\s*$`).
+				WithArgs(filter.MerchantID, events.RewardGranted, events.RewardCredited, events.RewardRefunded, filter.From, filter.To,
+					events.RewardGranted, events.RewardCredited, events.RewardRefunded, events.RewardAdjusted, events.RewardRevoked,
+					filter.Granularity,
+					filter.Granularity, filter.MerchantID, events.RewardRedeemed, events.RewardDebited, filter.From, filter.To).
+				WillReturnRows(sqlmock.NewRows(movementColumns).
+					AddRow(period, events.RewardGranted, "points", 20).
+					AddRow(period, events.RewardDebited, "points", 2))
+
+			movements, err := repository.RewardMovements(context.Background(), filter)
+
+			Expect(err).To(BeNil())
+			Expect(movements).To(Equal([]merchant_ports.RewardMovement{
+				{Period: period, EventType: events.RewardGranted, RewardType: "points", Amount: 20},
+				{Period: period, EventType: events.RewardDebited, RewardType: "points", Amount: 2},
+			}))
+		})
+
+		It("should take the amount of the last adjustment of each issued reward and leave out the revoked ones", func() {
+			sqlMock.ExpectQuery(`(?s)kept AS \(\s+SELECT issued\.type, issued\.occurred_at, issued\.reward_type,\s+` +
+				`\(ARRAY_AGG\(CAST\(changes\.payload->>'amount' AS DECIMAL\) ORDER BY changes\.sequence DESC\)\s+FILTER \(WHERE changes\.type IN \(\$7,\$8,\$9,\$10\)\)\)\[1\] AS amount\s+` +
+				`FROM issued\s+JOIN outbox_events AS changes ON changes\.aggregate_type = 'reward' AND changes\.aggregate_id = issued\.aggregate_id\s+` +
+				`GROUP BY issued\.aggregate_id, issued\.type, issued\.occurred_at, issued\.reward_type\s+HAVING NOT BOOL_OR\(changes\.type = \$11\)\s+\)`).
+				WillReturnRows(sqlmock.NewRows(movementColumns))
+
+			movements, err := repository.RewardMovements(context.Background(), filter)
+
+			Expect(err).To(BeNil())
+			Expect(movements).To(BeEmpty())
+		})
+
+		It("should count the issued rewards in the period they were issued", func() {
+			sqlMock.ExpectQuery(`(?s)SELECT date_trunc\(\$12, occurred_at AT TIME ZONE 'UTC'\) AS period, type AS event_type, reward_type, SUM\(amount\) AS amount\s+FROM kept\s+GROUP BY 1, 2, 3\s+UNION ALL`).
+				WillReturnRows(sqlmock.NewRows(movementColumns))
+
+			_, err := repository.RewardMovements(context.Background(), filter)
+
+			Expect(err).To(BeNil())
+		})
+	})
+})
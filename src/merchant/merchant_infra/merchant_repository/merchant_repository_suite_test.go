@@ -0,0 +1,13 @@
+package merchant_repository_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMerchantRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MerchantRepository Suite")
+}
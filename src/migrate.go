@@ -0,0 +1,99 @@
+package src
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"loyalty-campaigns/src/common/migrations"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// migrate applies (up), rolls back (down [steps]) or lists (status) the schema
+// migrations. It is the only command that runs without verifying the schema.
+func migrate(ctx context.Context, c *container, args []string) int {
+	return runMigrate(ctx, c.migrator, args)
+}
+
+func runMigrate(ctx context.Context, migrator migrations.IMigrator, args []string) int {
+	action, steps, err := parseMigrateArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n%s\n", err, migrateUsage)
+		return exitUsage
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Error("failed to apply migrations: %v", err)
+			return exitFailure
+		}
+		logger.Success("[OK] %d migrations applied", len(applied))
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			logger.Error("failed to roll back migrations: %v", err)
+			return exitFailure
+		}
+		logger.Success("[OK] %d migrations rolled back", len(reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Error("failed to read the migrations status: %v", err)
+			return exitFailure
+		}
+		return printMigrationStatus(statuses)
+	}
+
+	return exitOK
+}
+
+// parseMigrateArgs returns the migrate action and, for down, the number of
+// steps to roll back. Unexpected arguments are rejected rather than ignored so
+// that a mistyped "migrate up 3" does not apply every pending migration.
+func parseMigrateArgs(args []string) (string, int, error) {
+	if len(args) == 0 {
+		return "", 0, errors.New("missing migrate command")
+	}
+
+	action, rest := args[0], args[1:]
+	switch action {
+	case "up", "status":
+		if len(rest) > 0 {
+			return "", 0, fmt.Errorf("migrate %s takes no arguments", action)
+		}
+		return action, 0, nil
+	case "down":
+		if len(rest) > 1 {
+			return "", 0, errors.New("migrate down takes at most one argument")
+		}
+		if len(rest) == 0 {
+			return action, 1, nil
+		}
+		steps, err := strconv.Atoi(rest[0])
+		if err != nil || steps < 1 {
+			return "", 0, fmt.Errorf("invalid number of steps %q", rest[0])
+		}
+		return action, steps, nil
+	default:
+		return "", 0, fmt.Errorf("unknown migrate command %q", action)
+	}
+}
+
+func printMigrationStatus(statuses []migrations.MigrationStatus) int {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return flush(writer)
+}
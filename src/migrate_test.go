@@ -0,0 +1,90 @@
+package src_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	app "loyalty-campaigns/src"
+	"loyalty-campaigns/src/common/migrations"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("migrate", func() {
+	var migrator *fakeMigrator
+
+	BeforeEach(func() {
+		migrator = &fakeMigrator{}
+	})
+
+	DescribeTable("should parse the arguments",
+		func(args []string, action string, steps int) {
+			parsedAction, parsedSteps, err := app.ParseMigrateArgs(args)
+
+			Expect(err).To(BeNil())
+			Expect(parsedAction).To(Equal(action))
+			Expect(parsedSteps).To(Equal(steps))
+		},
+		Entry("up", []string{"up"}, "up", 0),
+		Entry("status", []string{"status"}, "status", 0),
+		Entry("down without steps", []string{"down"}, "down", 1),
+		Entry("down with steps", []string{"down", "3"}, "down", 3),
+	)
+
+	DescribeTable("should reject invalid arguments with a usage error",
+		func(args []string, message string) {
+			_, _, err := app.ParseMigrateArgs(args)
+			Expect(err).To(MatchError(message))
+
+			Expect(app.RunMigrate(context.Background(), migrator, args)).To(Equal(app.ExitUsage))
+			Expect(migrator.calls).To(BeEmpty())
+		},
+		Entry("without a command", []string{}, "missing migrate command"),
+		Entry("with an unknown command", []string{"sideways"}, `unknown migrate command "sideways"`),
+		Entry("up with arguments", []string{"up", "3"}, "migrate up takes no arguments"),
+		Entry("status with arguments", []string{"status", "--all"}, "migrate status takes no arguments"),
+		Entry("down with too many arguments", []string{"down", "1", "2"}, "migrate down takes at most one argument"),
+		Entry("down with a non numeric step count", []string{"down", "all"}, `invalid number of steps "all"`),
+		Entry("down with zero steps", []string{"down", "0"}, `invalid number of steps "0"`),
+	)
+
+	DescribeTable("should run the requested command",
+		func(args []string, call string) {
+			Expect(app.RunMigrate(context.Background(), migrator, args)).To(Equal(app.ExitOK))
+			Expect(migrator.calls).To(Equal([]string{call}))
+		},
+		Entry("up", []string{"up"}, "up"),
+		Entry("down one step by default", []string{"down"}, "down 1"),
+		Entry("down the given steps", []string{"down", "2"}, "down 2"),
+		Entry("status", []string{"status"}, "status"),
+	)
+
+	It("should fail when the migrator fails", func() {
+		migrator.err = errors.New("connection refused")
+
+		Expect(app.RunMigrate(context.Background(), migrator, []string{"up"})).To(Equal(app.ExitFailure))
+	})
+})
+
+type fakeMigrator struct {
+	calls []string
+	err   error
+}
+
+func (m *fakeMigrator) Up(ctx context.Context) ([]migrations.Migration, error) {
+	m.calls = append(m.calls, "up")
+	return nil, m.err
+}
+
+func (m *fakeMigrator) Down(ctx context.Context, steps int) ([]migrations.Migration, error) {
+	m.calls = append(m.calls, fmt.Sprintf("down %d", steps))
+	return nil, m.err
+}
+
+func (m *fakeMigrator) Status(ctx context.Context) ([]migrations.MigrationStatus, error) {
+	m.calls = append(m.calls, "status")
+	return nil, m.err
+}
+
+func (m *fakeMigrator) Verify(ctx context.Context) error { return m.err }
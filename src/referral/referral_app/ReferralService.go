@@ -0,0 +1,240 @@
+package referral_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/codes"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"loyalty-campaigns/src/referral/referral_domain/referral_ports"
+	"loyalty-campaigns/src/referral/referral_domain/referral_structs/referral_requests"
+	"loyalty-campaigns/src/referral/referral_domain/referral_structs/referral_responses"
+	"time"
+)
+
+var (
+	ErrSelfReferral        = domain_errors.Validation("self_referral", "users cannot refer themselves")
+	ErrInvalidReferralCode = domain_errors.Validation("invalid_referral_code", "the referral code is mistyped")
+)
+
+// ReferralPolicy shapes the referral codes and limits how many referrals a
+// user can make at a merchant: at most MaxReferrals within Period.
+type ReferralPolicy struct {
+	CodeLength   int
+	MaxReferrals int
+	Period       time.Duration
+}
+
+// ReferralPolicyFromEnv reads the policy from REFERRAL_CODE_LENGTH,
+// REFERRAL_MAX_PER_PERIOD and REFERRAL_PERIOD.
+func ReferralPolicyFromEnv() ReferralPolicy {
+	return ReferralPolicy{
+		CodeLength:   configs.GetEnvInt("REFERRAL_CODE_LENGTH", 8),
+		MaxReferrals: configs.GetEnvInt("REFERRAL_MAX_PER_PERIOD", 10),
+		Period:       configs.GetEnvDuration("REFERRAL_PERIOD", 30*24*time.Hour),
+	}
+}
+
+type IReferralService interface {
+	// IssueReferralCode gives the user a referral code, or returns the one
+	// they already have.
+	IssueReferralCode(ctx context.Context, userID uint) (*referral_responses.ReferralCodeResponse, error)
+	// CreateReferral attributes a new user of the merchant to the owner of the
+	// referral code.
+	CreateReferral(ctx context.Context, req referral_requests.CreateReferralRequest) (*referral_responses.ReferralResponse, error)
+	GetReferralStatus(ctx context.Context, userID uint) (*referral_responses.ReferralStatusResponse, error)
+	// QualifyReferral grants the referral bonuses when the transaction is the
+	// first qualifying one of a referred user at the merchant. It returns nil
+	// when no referral qualifies.
+	QualifyReferral(ctx context.Context, req referral_requests.QualifyReferralRequest) (*referral_responses.ReferralResponse, error)
+}
+
+type referralService struct {
+	referralRepo referral_ports.IReferralRepository
+	policy       ReferralPolicy
+	logger       utils.ILogger
+}
+
+func NewReferralService(referralRepo referral_ports.IReferralRepository, policy ReferralPolicy) IReferralService {
+	return &referralService{
+		referralRepo: referralRepo,
+		policy:       policy,
+		logger:       utils.NewLogger(),
+	}
+}
+
+func (s *referralService) IssueReferralCode(ctx context.Context, userID uint) (*referral_responses.ReferralCodeResponse, error) {
+	err := s.authorizeUser(ctx, security.ActionOperate, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := codes.Generate(s.policy.CodeLength, true)
+	if err != nil {
+		s.logger.Error("Error al generar código de referido", err)
+		return nil, err
+	}
+
+	code, err = s.referralRepo.IssueCode(ctx, userID, code)
+	if err != nil {
+		s.logger.Error("Error al emitir código de referido", err)
+		return nil, err
+	}
+
+	return &referral_responses.ReferralCodeResponse{
+		UserID: userID,
+		Code:   code,
+	}, nil
+}
+
+func (s *referralService) CreateReferral(ctx context.Context, req referral_requests.CreateReferralRequest) (*referral_responses.ReferralResponse, error) {
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mistyped codes are rejected before they are looked up
+	code := codes.Normalize(req.Code)
+	if !codes.Valid(code) {
+		return nil, ErrInvalidReferralCode
+	}
+
+	referrer, err := s.referralRepo.GetUserByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if referrer.ID == req.UserID {
+		return nil, ErrSelfReferral
+	}
+
+	now := time.Now()
+	referral := &models.Referral{
+		ReferrerID: referrer.ID,
+		RefereeID:  req.UserID,
+		MerchantID: req.MerchantID,
+		Code:       code,
+	}
+	err = s.referralRepo.Create(ctx, referral, referral_ports.Limit{
+		Since: now.Add(-s.policy.Period),
+		Max:   s.policy.MaxReferrals,
+	}, now)
+	if err != nil {
+		s.logger.Error("Error al registrar referido", err)
+		return nil, err
+	}
+
+	return referralToResponse(referral), nil
+}
+
+// GetReferralStatus shows merchant callers only the referrals made at their
+// merchant.
+func (s *referralService) GetReferralStatus(ctx context.Context, userID uint) (*referral_responses.ReferralStatusResponse, error) {
+	err := s.authorizeUser(ctx, security.ActionRead, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.referralRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	referredBy, err := s.referralRepo.GetByReferee(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error al obtener referido", err)
+		return nil, err
+	}
+
+	referrals, err := s.referralRepo.ListByReferrer(ctx, userID, merchantID)
+	if err != nil {
+		s.logger.Error("Error al listar referidos", err)
+		return nil, err
+	}
+
+	response := &referral_responses.ReferralStatusResponse{
+		UserID:       user.ID,
+		ReferralCode: user.ReferralCode,
+		Referrals:    make([]referral_responses.ReferralResponse, len(referrals)),
+	}
+	if referredBy != nil && (merchantID == nil || referredBy.MerchantID == *merchantID) {
+		response.ReferredBy = referralToResponse(referredBy)
+	}
+	for i := range referrals {
+		response.Referrals[i] = *referralToResponse(&referrals[i])
+		if referrals[i].Status == models.ReferralQualified {
+			response.Qualified++
+		} else {
+			response.Pending++
+		}
+	}
+	return response, nil
+}
+
+func (s *referralService) QualifyReferral(ctx context.Context, req referral_requests.QualifyReferralRequest) (*referral_responses.ReferralResponse, error) {
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, &req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	referral, err := s.referralRepo.Qualify(ctx, referral_ports.QualifyingTransaction{
+		ID:         req.TransactionID,
+		UserID:     req.UserID,
+		MerchantID: req.MerchantID,
+		Amount:     req.Amount,
+		Date:       req.Date,
+	}, time.Now())
+	if err != nil {
+		s.logger.Error("Error al calificar referido", err)
+		return nil, err
+	}
+	if referral == nil {
+		return nil, nil
+	}
+
+	return referralToResponse(referral), nil
+}
+
+// authorizeUser lets merchant callers act only on the members of their program.
+func (s *referralService) authorizeUser(ctx context.Context, action security.Action, userID uint) error {
+	principal, ok := security.PrincipalFromContext(ctx)
+	if !ok {
+		return security.ErrUnauthenticated
+	}
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	err := security.Authorize(ctx, action, principal.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.referralRepo.IsMember(ctx, userID, principal.MerchantID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return security.ErrForbidden
+	}
+	return nil
+}
+
+func referralToResponse(referral *models.Referral) *referral_responses.ReferralResponse {
+	return &referral_responses.ReferralResponse{
+		ID:            referral.ID,
+		ReferrerID:    referral.ReferrerID,
+		RefereeID:     referral.RefereeID,
+		MerchantID:    referral.MerchantID,
+		Status:        referral.Status,
+		TransactionID: referral.TransactionID,
+		CreatedAt:     referral.CreatedAt,
+		QualifiedAt:   referral.QualifiedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package referral_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReferralApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ReferralApp Suite")
+}
@@ -0,0 +1,247 @@
+package referral_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/codes"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/referral/referral_app"
+	"loyalty-campaigns/src/referral/referral_domain/referral_ports"
+	"loyalty-campaigns/src/referral/referral_domain/referral_structs/referral_requests"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ReferralService", func() {
+	var (
+		referralService referral_app.IReferralService
+		mockReferral    *mockReferralRepository
+		merchantID      uint
+		code            string
+		referrer        *models.User
+	)
+
+	BeforeEach(func() {
+		mockReferral = new(mockReferralRepository)
+		referralService = referral_app.NewReferralService(mockReferral, referral_app.ReferralPolicy{
+			CodeLength:   8,
+			MaxReferrals: 5,
+			Period:       7 * 24 * time.Hour,
+		})
+		merchantID = 4
+
+		var err error
+		code, err = codes.Generate(8, true)
+		Expect(err).To(BeNil())
+		referrer = &models.User{Name: "Ana", ReferralCode: &code}
+		referrer.ID = 1
+	})
+
+	operator := func() context.Context {
+		return security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleMerchantAdmin,
+			KeyID:      21,
+			MerchantID: merchantID,
+		})
+	}
+
+	Describe("IssueReferralCode", func() {
+		It("should issue a code of the policy length with a check symbol", func() {
+			mockReferral.On("IssueCode", mock.Anything, uint(1), mock.AnythingOfType("string")).Return(code, nil)
+
+			response, err := referralService.IssueReferralCode(security.WithPrincipal(context.Background(), security.System()), 1)
+
+			Expect(err).To(BeNil())
+			Expect(response.Code).To(Equal(code))
+			issued := mockReferral.Calls[0].Arguments.String(2)
+			Expect(issued).To(HaveLen(9))
+			Expect(codes.Valid(issued)).To(BeTrue())
+		})
+
+		It("should not issue codes to users outside of the merchant's program", func() {
+			mockReferral.On("IsMember", mock.Anything, uint(1), merchantID).Return(false, nil)
+
+			_, err := referralService.IssueReferralCode(operator(), 1)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockReferral.AssertNotCalled(GinkgoT(), "IssueCode", mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("CreateReferral", func() {
+		request := func(userID uint) referral_requests.CreateReferralRequest {
+			return referral_requests.CreateReferralRequest{
+				Code:       code,
+				UserID:     userID,
+				MerchantID: merchantID,
+			}
+		}
+
+		It("should refer the user within the limit of the policy", func() {
+			mockReferral.On("GetUserByCode", mock.Anything, code).Return(referrer, nil)
+			mockReferral.On("Create", mock.Anything, mock.AnythingOfType("*models.Referral"), mock.AnythingOfType("referral_ports.Limit"), mock.AnythingOfType("time.Time")).Return(nil)
+
+			response, err := referralService.CreateReferral(operator(), request(2))
+
+			Expect(err).To(BeNil())
+			Expect(response.ReferrerID).To(Equal(referrer.ID))
+			Expect(response.RefereeID).To(Equal(uint(2)))
+			limit := mockReferral.Calls[1].Arguments.Get(2).(referral_ports.Limit)
+			now := mockReferral.Calls[1].Arguments.Get(3).(time.Time)
+			Expect(limit.Max).To(Equal(5))
+			Expect(limit.Since).To(Equal(now.Add(-7 * 24 * time.Hour)))
+		})
+
+		It("should accept the code as users type it", func() {
+			mockReferral.On("GetUserByCode", mock.Anything, code).Return(referrer, nil)
+			mockReferral.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			req := request(2)
+			req.Code = code[:4] + "-" + code[4:]
+
+			_, err := referralService.CreateReferral(operator(), req)
+
+			Expect(err).To(BeNil())
+		})
+
+		It("should not let users refer themselves", func() {
+			mockReferral.On("GetUserByCode", mock.Anything, code).Return(referrer, nil)
+
+			_, err := referralService.CreateReferral(operator(), request(referrer.ID))
+
+			Expect(err).To(MatchError(referral_app.ErrSelfReferral))
+			mockReferral.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should reject a mistyped code without looking it up", func() {
+			req := request(2)
+			last := strings.IndexByte(codes.Alphabet, code[len(code)-1])
+			req.Code = code[:len(code)-1] + string(codes.Alphabet[(last+1)%len(codes.Alphabet)])
+
+			_, err := referralService.CreateReferral(operator(), req)
+
+			Expect(err).To(MatchError(referral_app.ErrInvalidReferralCode))
+			mockReferral.AssertNotCalled(GinkgoT(), "GetUserByCode", mock.Anything, mock.Anything)
+		})
+
+		It("should return the limit reached by the referrer", func() {
+			mockReferral.On("GetUserByCode", mock.Anything, code).Return(referrer, nil)
+			mockReferral.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(referral_ports.ErrReferralLimitReached)
+
+			_, err := referralService.CreateReferral(operator(), request(2))
+
+			Expect(err).To(MatchError(referral_ports.ErrReferralLimitReached))
+			Expect(err).To(MatchError(domain_errors.ErrConflict))
+		})
+	})
+
+	Describe("GetReferralStatus", func() {
+		It("should count the pending and qualified referrals of the merchant", func() {
+			mockReferral.On("IsMember", mock.Anything, referrer.ID, merchantID).Return(true, nil)
+			mockReferral.On("GetUser", mock.Anything, referrer.ID).Return(referrer, nil)
+			mockReferral.On("GetByReferee", mock.Anything, referrer.ID).Return(&models.Referral{ReferrerID: 7, RefereeID: referrer.ID, MerchantID: merchantID + 1, Status: models.ReferralQualified}, nil)
+			mockReferral.On("ListByReferrer", mock.Anything, referrer.ID, &merchantID).Return([]models.Referral{
+				{ReferrerID: referrer.ID, RefereeID: 2, MerchantID: merchantID, Status: models.ReferralQualified},
+				{ReferrerID: referrer.ID, RefereeID: 3, MerchantID: merchantID, Status: models.ReferralPending},
+				{ReferrerID: referrer.ID, RefereeID: 5, MerchantID: merchantID, Status: models.ReferralPending},
+			}, nil)
+
+			response, err := referralService.GetReferralStatus(operator(), referrer.ID)
+
+			Expect(err).To(BeNil())
+			Expect(*response.ReferralCode).To(Equal(code))
+			Expect(response.Referrals).To(HaveLen(3))
+			Expect(response.Qualified).To(Equal(1))
+			Expect(response.Pending).To(Equal(2))
+			Expect(response.ReferredBy).To(BeNil())
+		})
+	})
+
+	Describe("QualifyReferral", func() {
+		req := referral_requests.QualifyReferralRequest{TransactionID: 9, UserID: 2, MerchantID: 4, Amount: 50}
+
+		It("should return nothing when no referral qualifies", func() {
+			mockReferral.On("Qualify", mock.Anything, mock.AnythingOfType("referral_ports.QualifyingTransaction"), mock.AnythingOfType("time.Time")).Return(nil, nil)
+
+			response, err := referralService.QualifyReferral(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(BeNil())
+		})
+
+		It("should return the qualified referral", func() {
+			qualified := &models.Referral{ReferrerID: 1, RefereeID: 2, MerchantID: 4, Status: models.ReferralQualified, TransactionID: ptr(uint(9))}
+			mockReferral.On("Qualify", mock.Anything, referral_ports.QualifyingTransaction{ID: 9, UserID: 2, MerchantID: 4, Amount: 50}, mock.AnythingOfType("time.Time")).Return(qualified, nil)
+
+			response, err := referralService.QualifyReferral(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response.Status).To(Equal(models.ReferralQualified))
+			Expect(response.TransactionID).To(Equal(ptr(uint(9))))
+		})
+	})
+})
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+type mockReferralRepository struct {
+	mock.Mock
+}
+
+func (m *mockReferralRepository) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockReferralRepository) GetUserByCode(ctx context.Context, code string) (*models.User, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *mockReferralRepository) IssueCode(ctx context.Context, userID uint, code string) (string, error) {
+	args := m.Called(ctx, userID, code)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockReferralRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockReferralRepository) Create(ctx context.Context, referral *models.Referral, limit referral_ports.Limit, now time.Time) error {
+	args := m.Called(ctx, referral, limit, now)
+	return args.Error(0)
+}
+
+func (m *mockReferralRepository) GetByReferee(ctx context.Context, refereeID uint) (*models.Referral, error) {
+	args := m.Called(ctx, refereeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Referral), args.Error(1)
+}
+
+func (m *mockReferralRepository) ListByReferrer(ctx context.Context, referrerID uint, merchantID *uint) ([]models.Referral, error) {
+	args := m.Called(ctx, referrerID, merchantID)
+	return args.Get(0).([]models.Referral), args.Error(1)
+}
+
+func (m *mockReferralRepository) Qualify(ctx context.Context, transaction referral_ports.QualifyingTransaction, now time.Time) (*models.Referral, error) {
+	args := m.Called(ctx, transaction, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Referral), args.Error(1)
+}
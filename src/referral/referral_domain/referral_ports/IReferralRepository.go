@@ -0,0 +1,58 @@
+package referral_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"time"
+)
+
+var (
+	ErrReferralsDisabled    = domain_errors.Conflict("referrals_disabled", "the merchant does not accept referrals")
+	ErrReferrerNotMember    = domain_errors.Conflict("referrer_not_member", "the referrer is not a member of the merchant's program")
+	ErrAlreadyReferred      = domain_errors.Conflict("already_referred", "the user was already referred")
+	ErrNotNewUser           = domain_errors.Conflict("not_a_new_user", "only users without transactions at the merchant can be referred")
+	ErrReferralLimitReached = domain_errors.Conflict("referral_limit_reached", "the referrer reached the maximum number of referrals for the period")
+)
+
+// Limit caps the referrals a referrer can make at a merchant: at most Max
+// since Since.
+type Limit struct {
+	Since time.Time
+	Max   int
+}
+
+// QualifyingTransaction is the transaction of a referee that may qualify their
+// referral.
+type QualifyingTransaction struct {
+	ID         uint
+	UserID     uint
+	MerchantID uint
+	Amount     float64
+	Date       time.Time
+}
+
+type IReferralRepository interface {
+	GetUser(ctx context.Context, userID uint) (*models.User, error)
+	// GetUserByCode finds the owner of a normalized referral code.
+	GetUserByCode(ctx context.Context, code string) (*models.User, error)
+	// IssueCode gives the user the code unless they already have one, and
+	// returns the user's code.
+	IssueCode(ctx context.Context, userID uint, code string) (string, error)
+	IsMember(ctx context.Context, userID, merchantID uint) (bool, error)
+	// Create attributes the referee to the referrer and enrolls the referee in
+	// the merchant's program. It fails with ErrReferralsDisabled,
+	// ErrReferrerNotMember, ErrAlreadyReferred, ErrNotNewUser or
+	// ErrReferralLimitReached, and nothing is recorded.
+	Create(ctx context.Context, referral *models.Referral, limit Limit, now time.Time) error
+	// GetByReferee returns the referral of the user, or nil when they were not
+	// referred.
+	GetByReferee(ctx context.Context, refereeID uint) (*models.Referral, error)
+	// ListByReferrer returns the referrals made by the user, newest first, at
+	// the merchant when one is given.
+	ListByReferrer(ctx context.Context, referrerID uint, merchantID *uint) ([]models.Referral, error)
+	// Qualify grants the bonuses of the referee's pending referral at the
+	// merchant when the transaction reaches the merchant's minimum amount, and
+	// returns the qualified referral; it returns nil when nothing qualifies.
+	Qualify(ctx context.Context, transaction QualifyingTransaction, now time.Time) (*models.Referral, error)
+}
@@ -0,0 +1,9 @@
+package referral_requests
+
+// CreateReferralRequest attributes a new user of the merchant to the owner of
+// the referral code.
+type CreateReferralRequest struct {
+	Code       string `json:"code" binding:"required,max=32"`
+	UserID     uint   `json:"userId" binding:"required"`
+	MerchantID uint   `json:"merchantId" binding:"required"`
+}
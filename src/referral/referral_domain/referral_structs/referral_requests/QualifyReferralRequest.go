@@ -0,0 +1,15 @@
+package referral_requests
+
+import "time"
+
+// QualifyReferralRequest describes a processed transaction, which qualifies
+// the referral of its user at the merchant when it is their first qualifying
+// one.
+type QualifyReferralRequest struct {
+	TransactionID uint
+	UserID        uint
+	MerchantID    uint
+	BranchID      uint
+	Amount        float64
+	Date          time.Time
+}
@@ -0,0 +1,30 @@
+package referral_responses
+
+import "time"
+
+type ReferralResponse struct {
+	ID            uint       `json:"id"`
+	ReferrerID    uint       `json:"referrerId"`
+	RefereeID     uint       `json:"refereeId"`
+	MerchantID    uint       `json:"merchantId"`
+	Status        string     `json:"status"`
+	TransactionID *uint      `json:"transactionId,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	QualifiedAt   *time.Time `json:"qualifiedAt,omitempty"`
+}
+
+type ReferralCodeResponse struct {
+	UserID uint   `json:"userId"`
+	Code   string `json:"code"`
+}
+
+// ReferralStatusResponse shows the referral code of a user, who referred them
+// and the referrals they made, with how many are pending and qualified.
+type ReferralStatusResponse struct {
+	UserID       uint               `json:"userId"`
+	ReferralCode *string            `json:"referralCode,omitempty"`
+	ReferredBy   *ReferralResponse  `json:"referredBy,omitempty"`
+	Referrals    []ReferralResponse `json:"referrals"`
+	Pending      int                `json:"pending"`
+	Qualified    int                `json:"qualified"`
+}
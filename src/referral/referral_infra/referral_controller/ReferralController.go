@@ -0,0 +1,136 @@
+package referral_controller
+
+import (
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/referral/referral_app"
+	"loyalty-campaigns/src/referral/referral_domain/referral_structs/referral_requests"
+	"loyalty-campaigns/src/referral/referral_infra/referral_repository"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReferralController struct {
+	referralService referral_app.IReferralService
+}
+
+var (
+	referralControllerInstance *ReferralController
+	referralControllerOnce     sync.Once
+)
+
+func NewReferralController(router *gin.RouterGroup) *ReferralController {
+	referralControllerOnce.Do(func() {
+		referralControllerInstance = &ReferralController{}
+		db := configs.NewDBConnection().GetDB()
+		referralRepository := referral_repository.NewGormReferralRepository(db)
+		referralControllerInstance.referralService = referral_app.NewReferralService(referralRepository, referral_app.ReferralPolicyFromEnv())
+		referralControllerInstance.setupReferralRoutes(router)
+	})
+	return referralControllerInstance
+}
+
+func (c *ReferralController) setupReferralRoutes(router *gin.RouterGroup) {
+	referralGroup := router.Group("/referrals")
+	{
+		referralGroup.POST("", c.CreateReferral)
+	}
+	userGroup := router.Group("/users")
+	{
+		userGroup.POST("/:id/referral-code", c.IssueReferralCode)
+		userGroup.GET("/:id/referral", c.GetReferralStatus)
+	}
+}
+
+// CreateReferral godoc
+//
+//	@Summary		Refer a new user
+//	@Description	Attribute a new user of the merchant to the owner of the referral code and enroll them in the merchant's program. Both receive the merchant's referral bonuses on the user's first transaction of at least the merchant's minimum amount. Rejected when users refer themselves (self_referral), the code is mistyped (invalid_referral_code) or unknown (referral_code_not_found), the merchant has no referral bonuses (referrals_disabled), the referrer is not a member of the merchant's program (referrer_not_member), the user was already referred (already_referred) or has transactions at the merchant (not_a_new_user), or the referrer reached the maximum number of referrals for the period (referral_limit_reached).
+//	@Tags			referrals
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	body		referral_requests.CreateReferralRequest	true	"Referral"
+//	@Success		201		{object}	referral_responses.ReferralResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		409		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/referrals [post]
+func (c *ReferralController) CreateReferral(ctx *gin.Context) {
+	var req referral_requests.CreateReferralRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.referralService.CreateReferral(ctx.Request.Context(), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// IssueReferralCode godoc
+//
+//	@Summary		Issue a referral code
+//	@Description	Give the user a referral code to share, or return the one they already have
+//	@Tags			referrals
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	referral_responses.ReferralCodeResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/users/{id}/referral-code [post]
+func (c *ReferralController) IssueReferralCode(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.referralService.IssueReferralCode(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetReferralStatus godoc
+//
+//	@Summary		Get the referral status of a user
+//	@Description	Get the user's referral code, who referred them and the referrals they made, with how many are pending and qualified. Merchant callers only see the referrals made at their merchant.
+//	@Tags			referrals
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{object}	referral_responses.ReferralStatusResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/users/{id}/referral [get]
+func (c *ReferralController) GetReferralStatus(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.referralService.GetReferralStatus(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
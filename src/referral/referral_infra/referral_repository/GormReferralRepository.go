@@ -0,0 +1,270 @@
+package referral_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/referral/referral_domain/referral_ports"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormReferralRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormReferralRepository(db *gorm.DB) referral_ports.IReferralRepository {
+	return &GormReferralRepository{DB: db}
+}
+
+func (r *GormReferralRepository) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	var user models.User
+	err := configs.DB(ctx, r.DB).First(&user, userID).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "user")
+	}
+	return &user, nil
+}
+
+func (r *GormReferralRepository) GetUserByCode(ctx context.Context, code string) (*models.User, error) {
+	var user models.User
+	err := configs.DB(ctx, r.DB).Where("referral_code = ?", code).First(&user).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "referral_code")
+	}
+	return &user, nil
+}
+
+func (r *GormReferralRepository) IssueCode(ctx context.Context, userID uint, code string) (string, error) {
+	var user models.User
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error
+		if err != nil || user.ReferralCode != nil {
+			return err
+		}
+		user.ReferralCode = &code
+		return tx.Model(&user).Update("referral_code", code).Error
+	})
+	if err != nil {
+		return "", domain_errors.Translate(err, "user")
+	}
+	return *user.ReferralCode, nil
+}
+
+func (r *GormReferralRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	return isMember(configs.DB(ctx, r.DB), userID, merchantID)
+}
+
+func (r *GormReferralRepository) Create(ctx context.Context, referral *models.Referral, limit referral_ports.Limit, now time.Time) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var merchant models.Merchant
+		err := tx.Limit(1).Find(&merchant, referral.MerchantID).Error
+		if err != nil {
+			return err
+		}
+		if merchant.ID == 0 {
+			return domain_errors.NotFound("merchant_not_found", "merchant not found")
+		}
+		if merchant.ReferrerBonus == nil && merchant.RefereeBonus == nil {
+			return referral_ports.ErrReferralsDisabled
+		}
+
+		isReferrerMember, err := isMember(tx, referral.ReferrerID, referral.MerchantID)
+		if err != nil {
+			return err
+		}
+		if !isReferrerMember {
+			return referral_ports.ErrReferrerNotMember
+		}
+
+		// Locking both users, in id order, serializes the referrals of the
+		// referrer, which the limit counts, and the attribution of the referee
+		referee, err := lockUsers(tx, referral.ReferrerID, referral.RefereeID)
+		if err != nil {
+			return err
+		}
+		if referee.ReferredByUserID != nil {
+			return referral_ports.ErrAlreadyReferred
+		}
+
+		var transactions int64
+		err = tx.Model(&models.Transaction{}).
+			Where("user_id = ? AND merchant_id = ?", referral.RefereeID, referral.MerchantID).
+			Count(&transactions).Error
+		if err != nil {
+			return err
+		}
+		if transactions > 0 {
+			return referral_ports.ErrNotNewUser
+		}
+
+		var referrals int64
+		err = tx.Model(&models.Referral{}).
+			Where("referrer_id = ? AND merchant_id = ? AND created_at >= ?", referral.ReferrerID, referral.MerchantID, limit.Since).
+			Count(&referrals).Error
+		if err != nil {
+			return err
+		}
+		if referrals >= int64(limit.Max) {
+			return referral_ports.ErrReferralLimitReached
+		}
+
+		referral.Status = models.ReferralPending
+		err = tx.Omit(clause.Associations).Create(referral).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(referee).Updates(map[string]any{
+			"referred_by_user_id": referral.ReferrerID,
+			"referred_at":         now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "merchant_id"}}, DoNothing: true}).
+			Create(&models.Membership{UserID: referral.RefereeID, MerchantID: referral.MerchantID, EnrolledAt: now}).Error
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForReferral(events.ReferralCreated, referral))
+	})
+	return domain_errors.Translate(err, "referral")
+}
+
+func (r *GormReferralRepository) GetByReferee(ctx context.Context, refereeID uint) (*models.Referral, error) {
+	var referrals []models.Referral
+	err := configs.DB(ctx, r.DB).Where("referee_id = ?", refereeID).Limit(1).Find(&referrals).Error
+	if err != nil || len(referrals) == 0 {
+		return nil, err
+	}
+	return &referrals[0], nil
+}
+
+func (r *GormReferralRepository) ListByReferrer(ctx context.Context, referrerID uint, merchantID *uint) ([]models.Referral, error) {
+	var referrals []models.Referral
+	query := configs.DB(ctx, r.DB).Where("referrer_id = ?", referrerID)
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+	err := query.Order("created_at DESC, id DESC").Find(&referrals).Error
+	return referrals, err
+}
+
+func (r *GormReferralRepository) Qualify(ctx context.Context, transaction referral_ports.QualifyingTransaction, now time.Time) (*models.Referral, error) {
+	var qualified *models.Referral
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		var referrals []models.Referral
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("referee_id = ? AND merchant_id = ? AND status = ?", transaction.UserID, transaction.MerchantID, models.ReferralPending).
+			Limit(1).Find(&referrals).Error
+		if err != nil || len(referrals) == 0 {
+			return err
+		}
+		referral := &referrals[0]
+
+		var merchant models.Merchant
+		err = tx.First(&merchant, transaction.MerchantID).Error
+		if err != nil {
+			return err
+		}
+		if merchant.ReferralMinAmount != nil && transaction.Amount < *merchant.ReferralMinAmount {
+			return nil
+		}
+
+		err = grantBonuses(tx, referral, &merchant, transaction)
+		if err != nil {
+			return err
+		}
+
+		referral.Status = models.ReferralQualified
+		referral.TransactionID = &transaction.ID
+		referral.QualifiedAt = &now
+		err = tx.Omit(clause.Associations).Save(referral).Error
+		if err != nil {
+			return err
+		}
+		qualified = referral
+		return events.Enqueue(tx, events.ForReferral(events.ReferralQualified, referral))
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "referral")
+	}
+	return qualified, nil
+}
+
+// grantBonuses grants the bonuses of the referee and the referrer like the
+// rewards of the qualifying transaction: they expire and vest with the
+// merchant's periods, and reversing the transaction revokes them while they
+// are pending. They are granted in user id order, so that two qualifications
+// never wait on each other's balances.
+func grantBonuses(tx *gorm.DB, referral *models.Referral, merchant *models.Merchant, transaction referral_ports.QualifyingTransaction) error {
+	var bonuses []models.Reward
+	for _, bonus := range []struct {
+		userID uint
+		amount *float64
+	}{
+		{referral.RefereeID, merchant.RefereeBonus},
+		{referral.ReferrerID, merchant.ReferrerBonus},
+	} {
+		if bonus.amount == nil {
+			continue
+		}
+		reward := models.Reward{
+			UserID:        bonus.userID,
+			MerchantID:    merchant.ID,
+			Type:          merchant.DefaultRewardType,
+			Amount:        *bonus.amount,
+			TransactionID: &transaction.ID,
+			ReferralID:    &referral.ID,
+		}
+		if merchant.RewardValidityDays != nil {
+			expiry := transaction.Date.AddDate(0, 0, *merchant.RewardValidityDays)
+			reward.ExpiryDate = &expiry
+		}
+		if merchant.RewardVestingDays != nil {
+			vesting := transaction.Date.AddDate(0, 0, *merchant.RewardVestingDays)
+			reward.VestsAt = &vesting
+		}
+		bonuses = append(bonuses, reward)
+	}
+	sort.Slice(bonuses, func(i, j int) bool { return bonuses[i].UserID < bonuses[j].UserID })
+
+	for i := range bonuses {
+		err := reward_repository.GrantReward(tx, &bonuses[i], events.RewardGranted)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockUsers locks the referrer and the referee and returns the referee.
+func lockUsers(tx *gorm.DB, referrerID, refereeID uint) (*models.User, error) {
+	var users []models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", []uint{referrerID, refereeID}).
+		Order("id").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		if users[i].ID == refereeID {
+			return &users[i], nil
+		}
+	}
+	return nil, domain_errors.NotFound("user_not_found", "user not found")
+}
+
+func isMember(db *gorm.DB, userID, merchantID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Membership{}).
+		Where("user_id = ? AND merchant_id = ?", userID, merchantID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -1,21 +1,45 @@
 package reward_app
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/exports"
+	"loyalty-campaigns/src/common/metrics"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/common/utils"
 	"loyalty-campaigns/src/reward/reward_domain/reward_ports"
 	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_requests"
 	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_responses"
+	"strconv"
 	"sync"
+	"time"
 )
 
 type IRewardService interface {
-	CreateReward(req reward_requests.CreateRewardRequest) (*reward_responses.RewardResponse, error)
-	GetReward(id uint) (*reward_responses.RewardResponse, error)
-	ListRewardsByUser(userID uint) ([]reward_responses.RewardResponse, error)
-	GetTotalRewardsByUser(userID uint) (*reward_responses.TotalRewardsResponse, error)
-	DeductRewards(userID, merchantID uint, amount float64, rewardType string) error
+	CreateReward(ctx context.Context, req reward_requests.CreateRewardRequest) (*reward_responses.RewardResponse, error)
+	GetReward(ctx context.Context, id uint) (*reward_responses.RewardResponse, error)
+	ListRewards(ctx context.Context, req reward_requests.ListRewardsRequest) (*pagination.Page[reward_responses.RewardResponse], error)
+	ListRewardsByUser(ctx context.Context, userID uint) ([]reward_responses.RewardResponse, error)
+	GetTotalRewardsByUser(ctx context.Context, userID uint) (*reward_responses.TotalRewardsResponse, error)
+	DeductRewards(ctx context.Context, userID, merchantID uint, amount float64, rewardType string) error
+	GetBalances(ctx context.Context, userID uint) ([]reward_responses.BalanceResponse, error)
+	ExpireRewards(ctx context.Context, currentDate time.Time) (int, error)
+	VestRewards(ctx context.Context, now time.Time) (int, error)
+	RecalculateBalances(ctx context.Context) (int64, error)
+	// ExportMovements writes the reward movements to w as CSV or JSON lines,
+	// streaming them from the database.
+	ExportMovements(ctx context.Context, req reward_requests.ExportMovementsRequest, w io.Writer) error
+	HoldRewards(ctx context.Context, req reward_requests.HoldRewardsRequest) (*reward_responses.HoldResponse, error)
+	GetHold(ctx context.Context, id uint) (*reward_responses.HoldResponse, error)
+	// CaptureHold redeems amount of the hold, or all of it when amount is nil.
+	CaptureHold(ctx context.Context, id uint, amount *float64) (*reward_responses.HoldResponse, error)
+	VoidHold(ctx context.Context, id uint) (*reward_responses.HoldResponse, error)
+	ExpireHolds(ctx context.Context, now time.Time) (int, error)
 }
 
 type rewardService struct {
@@ -23,6 +47,9 @@ type rewardService struct {
 	logger     utils.ILogger
 }
 
+// ErrInsufficientBalance is returned when a redemption exceeds the available rewards.
+var ErrInsufficientBalance = reward_ports.ErrInsufficientBalance
+
 var (
 	rewardServiceInstance *rewardService
 	rewardServiceOnce     sync.Once
@@ -38,15 +65,24 @@ func NewRewardService(rewardRepo reward_ports.IRewardRepository) IRewardService
 	return rewardServiceInstance
 }
 
-func (s *rewardService) CreateReward(req reward_requests.CreateRewardRequest) (*reward_responses.RewardResponse, error) {
+func (s *rewardService) CreateReward(ctx context.Context, req reward_requests.CreateRewardRequest) (*reward_responses.RewardResponse, error) {
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
 	reward := &models.Reward{
-		UserID:     req.UserID,
-		MerchantID: req.MerchantID,
-		Type:       req.Type,
-		Amount:     req.Amount,
+		UserID:        req.UserID,
+		MerchantID:    req.MerchantID,
+		Type:          req.Type,
+		Amount:        req.Amount,
+		ExpiryDate:    req.ExpiryDate,
+		CampaignID:    req.CampaignID,
+		TransactionID: req.TransactionID,
+		VestsAt:       req.VestsAt,
 	}
 
-	err := s.rewardRepo.Create(reward)
+	err = s.rewardRepo.Create(ctx, reward)
 	if err != nil {
 		s.logger.Error("Error al crear recompensa", err)
 		return nil, err
@@ -55,18 +91,50 @@ func (s *rewardService) CreateReward(req reward_requests.CreateRewardRequest) (*
 	return mapRewardToResponse(reward), nil
 }
 
-func (s *rewardService) GetReward(id uint) (*reward_responses.RewardResponse, error) {
-	reward, err := s.rewardRepo.GetByID(id)
+func (s *rewardService) GetReward(ctx context.Context, id uint) (*reward_responses.RewardResponse, error) {
+	reward, err := s.rewardRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al obtener recompensa", err)
 		return nil, err
 	}
 
+	err = security.Authorize(ctx, security.ActionRead, reward.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	return mapRewardToResponse(reward), nil
 }
 
-func (s *rewardService) ListRewardsByUser(userID uint) ([]reward_responses.RewardResponse, error) {
-	rewards, err := s.rewardRepo.GetByUserID(userID)
+func (s *rewardService) ListRewards(ctx context.Context, req reward_requests.ListRewardsRequest) (*pagination.Page[reward_responses.RewardResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.rewardRepo.List(ctx, reward_ports.RewardFilter{
+		MerchantID: merchantID,
+		UserID:     req.UserID,
+		Type:       req.Type,
+	}, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar recompensas", err)
+		return nil, err
+	}
+
+	return pagination.Map(page, func(reward *models.Reward) reward_responses.RewardResponse {
+		return *mapRewardToResponse(reward)
+	}), nil
+}
+
+// ListRewardsByUser returns every reward lot of the user, for balance checks.
+func (s *rewardService) ListRewardsByUser(ctx context.Context, userID uint) ([]reward_responses.RewardResponse, error) {
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rewards, err := s.rewardRepo.GetByUserID(ctx, userID, merchantID)
 	if err != nil {
 		s.logger.Error("Error al listar recompensas del usuario", err)
 		return nil, err
@@ -75,8 +143,13 @@ func (s *rewardService) ListRewardsByUser(userID uint) ([]reward_responses.Rewar
 	return mapRewardsToResponses(rewards), nil
 }
 
-func (s *rewardService) GetTotalRewardsByUser(userID uint) (*reward_responses.TotalRewardsResponse, error) {
-	totalPoints, totalCashback, err := s.rewardRepo.GetTotalRewardsByUser(userID)
+func (s *rewardService) GetTotalRewardsByUser(ctx context.Context, userID uint) (*reward_responses.TotalRewardsResponse, error) {
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPoints, totalCashback, err := s.rewardRepo.GetTotalRewardsByUser(ctx, userID, merchantID)
 	if err != nil {
 		s.logger.Error("Error al obtener total de recompensas del usuario", err)
 		return nil, err
@@ -89,13 +162,180 @@ func (s *rewardService) GetTotalRewardsByUser(userID uint) (*reward_responses.To
 	}, nil
 }
 
+// GetBalances returns the balances of the user at every merchant in the caller's scope.
+func (s *rewardService) GetBalances(ctx context.Context, userID uint) ([]reward_responses.BalanceResponse, error) {
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.rewardRepo.GetBalances(ctx, userID, merchantID)
+	if err != nil {
+		s.logger.Error("Error al obtener saldos del usuario", err)
+		return nil, err
+	}
+
+	responses := make([]reward_responses.BalanceResponse, len(balances))
+	for i, balance := range balances {
+		responses[i] = reward_responses.BalanceResponse{
+			MerchantID: balance.MerchantID,
+			Type:       balance.Type,
+			Amount:     balance.Amount,
+			Pending:    balance.Pending,
+			Held:       balance.Held,
+			Available:  balance.Amount - balance.Pending - balance.Held,
+			UpdatedAt:  balance.UpdatedAt,
+		}
+	}
+	return responses, nil
+}
+
+// ExpireRewards removes the rewards that expired before currentDate and returns how many were expired.
+func (s *rewardService) ExpireRewards(ctx context.Context, currentDate time.Time) (int, error) {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	expired, err := s.rewardRepo.ExpireRewards(ctx, currentDate)
+	if err != nil {
+		s.logger.Error("Error al expirar recompensas", err)
+		return 0, err
+	}
+
+	for _, reward := range expired {
+		metrics.RewardsExpired.WithLabelValues(reward.Type, metrics.ID(reward.MerchantID)).Inc()
+		metrics.AddAmount(metrics.RewardsExpiredAmount.WithLabelValues(reward.Type, metrics.ID(reward.MerchantID)), reward.Amount)
+	}
+
+	return len(expired), nil
+}
+
+// VestRewards makes the pending rewards that vested by now available and
+// returns how many were vested.
+func (s *rewardService) VestRewards(ctx context.Context, now time.Time) (int, error) {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	vested, err := s.rewardRepo.VestRewards(ctx, now)
+	if err != nil {
+		s.logger.Error("Error al consolidar recompensas pendientes", err)
+		return 0, err
+	}
+
+	return len(vested), nil
+}
+
+// RecalculateBalances rebuilds every balance from the rewards ledger and
+// returns how many of them were corrected.
+func (s *rewardService) RecalculateBalances(ctx context.Context) (int64, error) {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	corrected, err := s.rewardRepo.RecalculateBalances(ctx)
+	if err != nil {
+		s.logger.Error("Error al recalcular saldos", err)
+		return 0, err
+	}
+
+	return corrected, nil
+}
+
+func (s *rewardService) ExportMovements(ctx context.Context, req reward_requests.ExportMovementsRequest, w io.Writer) error {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	encoder := exports.NewEncoder(w, req.Format, movementExportColumns, movementExportRecord)
+	err = s.rewardRepo.StreamMovements(ctx, reward_ports.MovementFilter{
+		MerchantID: merchantID,
+		UserID:     req.UserID,
+		From:       req.From,
+		To:         req.To,
+	}, func(movements []models.OutboxEvent) error {
+		for i := range movements {
+			movement, err := mapEventToMovement(&movements[i])
+			if err != nil {
+				return err
+			}
+			err = encoder.Write(movement)
+			if err != nil {
+				return err
+			}
+		}
+		return encoder.Flush()
+	})
+	if err != nil {
+		s.logger.Error("Error al exportar movimientos de recompensas: %v", err)
+		return err
+	}
+	return encoder.Flush()
+}
+
+var movementExportColumns = []string{"event_id", "type", "reward_id", "user_id", "merchant_id", "reward_type", "amount", "expiry_date", "occurred_at"}
+
+func movementExportRecord(movement *reward_responses.MovementResponse) []string {
+	var rewardID, expiryDate string
+	if movement.RewardID != nil {
+		rewardID = strconv.FormatUint(uint64(*movement.RewardID), 10)
+	}
+	if movement.ExpiryDate != nil {
+		expiryDate = movement.ExpiryDate.Format(time.RFC3339)
+	}
+	return []string{
+		movement.EventID,
+		movement.Type,
+		rewardID,
+		strconv.FormatUint(uint64(movement.UserID), 10),
+		strconv.FormatUint(uint64(movement.MerchantID), 10),
+		movement.RewardType,
+		strconv.FormatFloat(movement.Amount, 'f', -1, 64),
+		expiryDate,
+		movement.OccurredAt.Format(time.RFC3339),
+	}
+}
+
+// mapEventToMovement reads a reward event back from its outbox payload.
+func mapEventToMovement(event *models.OutboxEvent) (*reward_responses.MovementResponse, error) {
+	var data events.RewardData
+	err := json.Unmarshal([]byte(event.Payload), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := &reward_responses.MovementResponse{
+		EventID:    event.EventID,
+		Type:       event.Type,
+		UserID:     data.UserID,
+		MerchantID: data.MerchantID,
+		RewardType: data.Type,
+		Amount:     data.Amount,
+		ExpiryDate: data.ExpiryDate,
+		OccurredAt: event.OccurredAt,
+	}
+	if event.AggregateType == "reward" {
+		rewardID := event.AggregateID
+		movement.RewardID = &rewardID
+	}
+	return movement, nil
+}
+
 func mapRewardToResponse(reward *models.Reward) *reward_responses.RewardResponse {
 	return &reward_responses.RewardResponse{
-		ID:         reward.ID,
-		UserID:     reward.UserID,
-		MerchantID: reward.MerchantID,
-		Type:       reward.Type,
-		Amount:     reward.Amount,
+		ID:            reward.ID,
+		UserID:        reward.UserID,
+		MerchantID:    reward.MerchantID,
+		Type:          reward.Type,
+		Amount:        reward.Amount,
+		ExpiryDate:    reward.ExpiryDate,
+		CampaignID:    reward.CampaignID,
+		TransactionID: reward.TransactionID,
+		VestsAt:       reward.VestsAt,
 	}
 }
 
@@ -107,56 +347,141 @@ func mapRewardsToResponses(rewards []models.Reward) []reward_responses.RewardRes
 	return responses
 }
 
-func (s *rewardService) DeductRewards(userID, merchantID uint, amount float64, rewardType string) error {
-	// 1. Get user's rewards for the specific merchant and type
-	rewards, err := s.rewardRepo.GetByUserMerchantAndType(userID, merchantID, rewardType)
+func (s *rewardService) DeductRewards(ctx context.Context, userID, merchantID uint, amount float64, rewardType string) error {
+	err := security.Authorize(ctx, security.ActionOperate, merchantID, security.OperatorBranch(ctx, nil))
 	if err != nil {
-		s.logger.Error("Error al obtener recompensas del usuario", err)
 		return err
 	}
 
-	// 2. Calculate total available rewards
-	var totalAvailable float64
-	for _, reward := range rewards {
-		totalAvailable += reward.Amount
+	// The repository checks the balance and consumes the rewards atomically
+	err = s.rewardRepo.Redeem(ctx, userID, merchantID, rewardType, amount)
+	if err != nil && !errors.Is(err, ErrInsufficientBalance) {
+		s.logger.Error("Error al deducir recompensas", err)
 	}
+	return err
+}
 
-	// 3. Check if user has enough rewards
-	if totalAvailable < amount {
-		return errors.New("insufficient rewards")
+// HoldRewards reserves an amount of the user's available rewards for a
+// redemption to be captured or voided later. Without a branch, the hold is
+// taken at the branch of a branch operator.
+func (s *rewardService) HoldRewards(ctx context.Context, req reward_requests.HoldRewardsRequest) (*reward_responses.HoldResponse, error) {
+	req.BranchID = security.OperatorBranch(ctx, req.BranchID)
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, req.BranchID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 4. Deduct rewards
-	remaining := amount
-	for _, reward := range rewards {
-		if remaining <= 0 {
-			break
-		}
+	hold := &models.RedemptionHold{
+		UserID:     req.UserID,
+		MerchantID: req.MerchantID,
+		BranchID:   req.BranchID,
+		Type:       req.Type,
+		Amount:     req.Amount,
+		ExpiresAt:  req.ExpiresAt,
+	}
 
-		if reward.Amount <= remaining {
-			// Use up this reward completely
-			err = s.rewardRepo.Delete(reward.ID)
-			if err != nil {
-				s.logger.Error("Error al eliminar recompensa", err)
-				return err
-			}
-			remaining -= reward.Amount
-		} else {
-			// Partially use this reward
-			reward.Amount -= remaining
-			err = s.rewardRepo.Update(&reward)
-			if err != nil {
-				s.logger.Error("Error al actualizar recompensa", err)
-				return err
-			}
-			remaining = 0
+	err = s.rewardRepo.Hold(ctx, hold)
+	if err != nil {
+		if !errors.Is(err, ErrInsufficientBalance) {
+			s.logger.Error("Error al reservar recompensas", err)
 		}
+		return nil, err
+	}
+
+	return mapHoldToResponse(hold), nil
+}
+
+func (s *rewardService) GetHold(ctx context.Context, id uint) (*reward_responses.HoldResponse, error) {
+	hold, err := s.rewardRepo.GetHold(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	if remaining > 0 {
-		s.logger.Error("Error inesperado al deducir recompensas", errors.New("remaining rewards after deduction"))
-		return errors.New("unexpected error while deducting rewards")
+	err = security.Authorize(ctx, security.ActionRead, hold.MerchantID, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return mapHoldToResponse(hold), nil
+}
+
+func (s *rewardService) CaptureHold(ctx context.Context, id uint, amount *float64) (*reward_responses.HoldResponse, error) {
+	hold, err := s.operableHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	captured := hold.Amount
+	if amount != nil {
+		captured = *amount
+	}
+
+	hold, err = s.rewardRepo.CaptureHold(ctx, id, captured, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return mapHoldToResponse(hold), nil
+}
+
+func (s *rewardService) VoidHold(ctx context.Context, id uint) (*reward_responses.HoldResponse, error) {
+	_, err := s.operableHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.rewardRepo.VoidHold(ctx, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return mapHoldToResponse(hold), nil
+}
+
+// ExpireHolds releases the holds that expired by now and returns how many were
+// released.
+func (s *rewardService) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	expired, err := s.rewardRepo.ExpireHolds(ctx, now)
+	if err != nil {
+		s.logger.Error("Error al expirar reservas", err)
+		return 0, err
+	}
+
+	return len(expired), nil
+}
+
+// operableHold returns the hold if the caller can operate its branch.
+func (s *rewardService) operableHold(ctx context.Context, id uint) (*models.RedemptionHold, error) {
+	hold, err := s.rewardRepo.GetHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, security.ActionOperate, hold.MerchantID, hold.BranchID)
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func mapHoldToResponse(hold *models.RedemptionHold) *reward_responses.HoldResponse {
+	return &reward_responses.HoldResponse{
+		ID:             hold.ID,
+		UserID:         hold.UserID,
+		MerchantID:     hold.MerchantID,
+		BranchID:       hold.BranchID,
+		Type:           hold.Type,
+		Amount:         hold.Amount,
+		CapturedAmount: hold.CapturedAmount,
+		Status:         hold.Status,
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+		CapturedAt:     hold.CapturedAt,
+		VoidedAt:       hold.VoidedAt,
+	}
 }
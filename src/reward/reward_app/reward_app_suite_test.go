@@ -0,0 +1,13 @@
+package reward_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRewardApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RewardApp Suite")
+}
@@ -0,0 +1,269 @@
+package reward_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/metrics"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/reward/reward_app"
+	"loyalty-campaigns/src/reward/reward_domain/reward_ports"
+	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_requests"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("RewardService", func() {
+	// The reward service is a singleton, so every spec shares its repository.
+	mockReward := new(mockRewardRepository)
+	rewardService := reward_app.NewRewardService(mockReward)
+
+	var ctx context.Context
+
+	BeforeEach(func() {
+		mockReward.ExpectedCalls = nil
+		mockReward.Calls = nil
+		ctx = security.WithPrincipal(context.Background(), security.System())
+	})
+
+	expired := func(merchant string) float64 {
+		return testutil.ToFloat64(metrics.RewardsExpiredAmount.WithLabelValues("points", merchant))
+	}
+
+	Describe("ExpireRewards", func() {
+		It("should count the amounts expired, leaving out those not positive", func() {
+			now := time.Now()
+			mockReward.On("ExpireRewards", mock.Anything, now).Return([]models.Reward{
+				{MerchantID: 104, Type: "points", Amount: 10},
+				{MerchantID: 104, Type: "points", Amount: -3},
+				{MerchantID: 104, Type: "points", Amount: 4},
+			}, nil)
+			count := testutil.ToFloat64(metrics.RewardsExpired.WithLabelValues("points", "104"))
+			amount := expired("104")
+
+			n, err := rewardService.ExpireRewards(ctx, now)
+
+			Expect(err).To(BeNil())
+			Expect(n).To(Equal(3))
+			Expect(testutil.ToFloat64(metrics.RewardsExpired.WithLabelValues("points", "104"))).To(Equal(count + 3))
+			Expect(expired("104")).To(Equal(amount + 14))
+		})
+	})
+
+	Describe("ExportMovements", func() {
+		merchantID := uint(4)
+		occurredAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+		movements := []models.OutboxEvent{
+			{EventID: "e1", Type: "reward.granted", AggregateType: "reward", AggregateID: 5, OccurredAt: occurredAt,
+				Payload: `{"id":5,"userId":1,"merchantId":4,"type":"points","amount":10,"expiryDate":"2026-06-01T00:00:00Z"}`},
+			{EventID: "e2", Type: "reward.redeemed", AggregateType: "user", AggregateID: 1, OccurredAt: occurredAt,
+				Payload: `{"userId":1,"merchantId":4,"type":"points","amount":2.5}`},
+		}
+
+		BeforeEach(func() {
+			ctx = security.WithPrincipal(context.Background(), &security.Principal{Role: security.RoleAnalyst, MerchantID: merchantID})
+		})
+
+		streaming := func(movements []models.OutboxEvent) func(args mock.Arguments) {
+			return func(args mock.Arguments) {
+				fn := args.Get(2).(func([]models.OutboxEvent) error)
+				Expect(fn(movements)).To(Succeed())
+			}
+		}
+
+		It("should export the movements of the caller's merchant as CSV", func() {
+			userID := uint(1)
+			mockReward.On("StreamMovements", mock.Anything, reward_ports.MovementFilter{MerchantID: &merchantID, UserID: &userID}, mock.Anything).
+				Run(streaming(movements)).Return(nil)
+			var out strings.Builder
+
+			err := rewardService.ExportMovements(ctx, reward_requests.ExportMovementsRequest{UserID: &userID}, &out)
+
+			Expect(err).To(BeNil())
+			Expect(out.String()).To(Equal("" +
+				"event_id,type,reward_id,user_id,merchant_id,reward_type,amount,expiry_date,occurred_at\n" +
+				"e1,reward.granted,5,1,4,points,10,2026-06-01T00:00:00Z,2026-03-01T10:00:00Z\n" +
+				"e2,reward.redeemed,,1,4,points,2.5,,2026-03-01T10:00:00Z\n"))
+		})
+
+		It("should export the movements as JSON lines", func() {
+			mockReward.On("StreamMovements", mock.Anything, reward_ports.MovementFilter{MerchantID: &merchantID}, mock.Anything).
+				Run(streaming(movements[1:])).Return(nil)
+			var out strings.Builder
+
+			err := rewardService.ExportMovements(ctx, reward_requests.ExportMovementsRequest{MerchantID: &merchantID, Format: "jsonl"}, &out)
+
+			Expect(err).To(BeNil())
+			Expect(strings.Count(out.String(), "\n")).To(Equal(1))
+			Expect(out.String()).To(MatchJSON(`{"event_id":"e2","type":"reward.redeemed","user_id":1,"merchant_id":4,"reward_type":"points","amount":2.5,"occurred_at":"2026-03-01T10:00:00Z"}`))
+		})
+
+		It("should not export the movements of another merchant", func() {
+			otherMerchantID := uint(5)
+			var out strings.Builder
+
+			err := rewardService.ExportMovements(ctx, reward_requests.ExportMovementsRequest{MerchantID: &otherMerchantID}, &out)
+
+			Expect(err).To(MatchError(security.ErrForbidden))
+			Expect(out.String()).To(BeEmpty())
+			mockReward.AssertNotCalled(GinkgoT(), "StreamMovements", mock.Anything, mock.Anything, mock.Anything)
+		})
+
+		It("should let a platform admin export every merchant", func() {
+			ctx = security.WithPrincipal(context.Background(), security.System())
+			mockReward.On("StreamMovements", mock.Anything, reward_ports.MovementFilter{}, mock.Anything).Return(nil)
+			var out strings.Builder
+
+			err := rewardService.ExportMovements(ctx, reward_requests.ExportMovementsRequest{}, &out)
+
+			Expect(err).To(BeNil())
+			Expect(out.String()).To(Equal("event_id,type,reward_id,user_id,merchant_id,reward_type,amount,expiry_date,occurred_at\n"))
+		})
+	})
+})
+
+type mockRewardRepository struct {
+	mock.Mock
+}
+
+func (m *mockRewardRepository) Create(ctx context.Context, reward *models.Reward) error {
+	args := m.Called(ctx, reward)
+	return args.Error(0)
+}
+
+func (m *mockRewardRepository) GetByID(ctx context.Context, id uint) (*models.Reward, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) Update(ctx context.Context, reward *models.Reward) error {
+	args := m.Called(ctx, reward)
+	return args.Error(0)
+}
+
+func (m *mockRewardRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockRewardRepository) Redeem(ctx context.Context, userID, merchantID uint, rewardType string, amount float64) error {
+	args := m.Called(ctx, userID, merchantID, rewardType, amount)
+	return args.Error(0)
+}
+
+func (m *mockRewardRepository) List(ctx context.Context, filter reward_ports.RewardFilter, page pagination.Request) (*pagination.Page[models.Reward], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(*pagination.Page[models.Reward]), args.Error(1)
+}
+
+func (m *mockRewardRepository) StreamMovements(ctx context.Context, filter reward_ports.MovementFilter, fn func([]models.OutboxEvent) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *mockRewardRepository) GetByUserID(ctx context.Context, userID uint, merchantID *uint) ([]models.Reward, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) GetTotalRewardsByUser(ctx context.Context, userID uint, merchantID *uint) (float64, float64, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Get(0).(float64), args.Get(1).(float64), args.Error(2)
+}
+
+func (m *mockRewardRepository) GetByMerchantID(ctx context.Context, merchantID uint) ([]models.Reward, error) {
+	args := m.Called(ctx, merchantID)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) GetByUserAndMerchant(ctx context.Context, userID, merchantID uint) ([]models.Reward, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) SumRewardsByUser(ctx context.Context, userID uint, rewardType string) (float64, error) {
+	args := m.Called(ctx, userID, rewardType)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *mockRewardRepository) GetActiveRewards(ctx context.Context, userID uint, currentDate time.Time) ([]models.Reward, error) {
+	args := m.Called(ctx, userID, currentDate)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) MarkAsRedeemed(ctx context.Context, rewardID uint) error {
+	args := m.Called(ctx, rewardID)
+	return args.Error(0)
+}
+
+func (m *mockRewardRepository) GetExpiredRewards(ctx context.Context, currentDate time.Time) ([]models.Reward, error) {
+	args := m.Called(ctx, currentDate)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) GetByUserMerchantAndType(ctx context.Context, userID, merchantID uint, rewardType string) ([]models.Reward, error) {
+	args := m.Called(ctx, userID, merchantID, rewardType)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) ExpireRewards(ctx context.Context, currentDate time.Time) ([]models.Reward, error) {
+	args := m.Called(ctx, currentDate)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) VestRewards(ctx context.Context, now time.Time) ([]models.Reward, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]models.Reward), args.Error(1)
+}
+
+func (m *mockRewardRepository) GetBalances(ctx context.Context, userID uint, merchantID *uint) ([]models.Balance, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Get(0).([]models.Balance), args.Error(1)
+}
+
+func (m *mockRewardRepository) RecalculateBalances(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockRewardRepository) Hold(ctx context.Context, hold *models.RedemptionHold) error {
+	args := m.Called(ctx, hold)
+	return args.Error(0)
+}
+
+func (m *mockRewardRepository) GetHold(ctx context.Context, id uint) (*models.RedemptionHold, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RedemptionHold), args.Error(1)
+}
+
+func (m *mockRewardRepository) CaptureHold(ctx context.Context, id uint, amount float64, now time.Time) (*models.RedemptionHold, error) {
+	args := m.Called(ctx, id, amount, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RedemptionHold), args.Error(1)
+}
+
+func (m *mockRewardRepository) VoidHold(ctx context.Context, id uint, now time.Time) (*models.RedemptionHold, error) {
+	args := m.Called(ctx, id, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RedemptionHold), args.Error(1)
+}
+
+func (m *mockRewardRepository) ExpireHolds(ctx context.Context, now time.Time) ([]models.RedemptionHold, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).([]models.RedemptionHold), args.Error(1)
+}
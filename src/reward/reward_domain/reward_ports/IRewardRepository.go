@@ -1,23 +1,58 @@
 package reward_ports
 
 import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 	"time"
 )
 
+// ErrInsufficientBalance is returned when a redemption exceeds the available rewards.
+var ErrInsufficientBalance = domain_errors.InsufficientBalance("insufficient_rewards", "insufficient rewards")
+
+var (
+	ErrHoldNotActive      = domain_errors.Conflict("hold_not_active", "the hold was already captured, voided or expired")
+	ErrHoldExpired        = domain_errors.Conflict("hold_expired", "the hold expired")
+	ErrCaptureExceedsHold = domain_errors.Validation("capture_exceeds_hold", "the amount captured exceeds the amount held")
+)
+
 type IRewardRepository interface {
-	Create(reward *models.Reward) error
-	GetByID(id uint) (*models.Reward, error)
-	Update(reward *models.Reward) error
-	Delete(id uint) error
-	List() ([]models.Reward, error)
-	GetByUserID(userID uint) ([]models.Reward, error)
-	GetTotalRewardsByUser(userID uint) (totalPoints float64, totalCashback float64, err error)
-	GetByMerchantID(merchantID uint) ([]models.Reward, error)
-	GetByUserAndMerchant(userID, merchantID uint) ([]models.Reward, error)
-	SumRewardsByUser(userID uint, rewardType string) (float64, error)
-	GetActiveRewards(userID uint, currentDate time.Time) ([]models.Reward, error)
-	MarkAsRedeemed(rewardID uint) error
-	GetExpiredRewards(currentDate time.Time) ([]models.Reward, error)
-	GetByUserMerchantAndType(userID, merchantID uint, rewardType string) ([]models.Reward, error)
+	Create(ctx context.Context, reward *models.Reward) error
+	GetByID(ctx context.Context, id uint) (*models.Reward, error)
+	Update(ctx context.Context, reward *models.Reward) error
+	Delete(ctx context.Context, id uint) error
+	Redeem(ctx context.Context, userID, merchantID uint, rewardType string, amount float64) error
+	List(ctx context.Context, filter RewardFilter, page pagination.Request) (*pagination.Page[models.Reward], error)
+	// StreamMovements passes the reward events recorded in the outbox to fn in
+	// batches read from a server-side cursor, in the order they were recorded.
+	StreamMovements(ctx context.Context, filter MovementFilter, fn func([]models.OutboxEvent) error) error
+	GetByUserID(ctx context.Context, userID uint, merchantID *uint) ([]models.Reward, error)
+	GetTotalRewardsByUser(ctx context.Context, userID uint, merchantID *uint) (totalPoints float64, totalCashback float64, err error)
+	GetByMerchantID(ctx context.Context, merchantID uint) ([]models.Reward, error)
+	GetByUserAndMerchant(ctx context.Context, userID, merchantID uint) ([]models.Reward, error)
+	SumRewardsByUser(ctx context.Context, userID uint, rewardType string) (float64, error)
+	GetActiveRewards(ctx context.Context, userID uint, currentDate time.Time) ([]models.Reward, error)
+	MarkAsRedeemed(ctx context.Context, rewardID uint) error
+	GetExpiredRewards(ctx context.Context, currentDate time.Time) ([]models.Reward, error)
+	GetByUserMerchantAndType(ctx context.Context, userID, merchantID uint, rewardType string) ([]models.Reward, error)
+	ExpireRewards(ctx context.Context, currentDate time.Time) ([]models.Reward, error)
+	// VestRewards makes the pending rewards whose vesting date passed by now
+	// available and returns them.
+	VestRewards(ctx context.Context, now time.Time) ([]models.Reward, error)
+	GetBalances(ctx context.Context, userID uint, merchantID *uint) ([]models.Balance, error)
+	RecalculateBalances(ctx context.Context) (int64, error)
+	// Hold reserves the amount of the hold from the user's available balance,
+	// the rewards not reserved by other holds, or fails with
+	// ErrInsufficientBalance. Holds and redemptions of a balance are serialized.
+	Hold(ctx context.Context, hold *models.RedemptionHold) error
+	GetHold(ctx context.Context, id uint) (*models.RedemptionHold, error)
+	// CaptureHold redeems amount from the rewards reserved by an active hold
+	// and releases the rest of it. It fails with ErrHoldNotActive, ErrHoldExpired
+	// or ErrCaptureExceedsHold.
+	CaptureHold(ctx context.Context, id uint, amount float64, now time.Time) (*models.RedemptionHold, error)
+	// VoidHold releases an active hold, or fails with ErrHoldNotActive.
+	VoidHold(ctx context.Context, id uint, now time.Time) (*models.RedemptionHold, error)
+	// ExpireHolds releases the active holds that expired by now and returns them.
+	ExpireHolds(ctx context.Context, now time.Time) ([]models.RedemptionHold, error)
 }
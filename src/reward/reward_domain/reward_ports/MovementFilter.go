@@ -0,0 +1,11 @@
+package reward_ports
+
+import "time"
+
+// MovementFilter selects reward movements by when they occurred.
+type MovementFilter struct {
+	MerchantID *uint
+	UserID     *uint
+	From       *time.Time
+	To         *time.Time
+}
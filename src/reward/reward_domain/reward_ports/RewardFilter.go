@@ -0,0 +1,7 @@
+package reward_ports
+
+type RewardFilter struct {
+	MerchantID *uint
+	UserID     *uint
+	Type       string
+}
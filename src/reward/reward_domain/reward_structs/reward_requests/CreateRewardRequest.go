@@ -1,8 +1,18 @@
 package reward_requests
 
+import "time"
+
 type CreateRewardRequest struct {
-	UserID     uint    `json:"user_id" binding:"required"`
-	MerchantID uint    `json:"merchant_id" binding:"required"`
-	Type       string  `json:"type" binding:"required"`
-	Amount     float64 `json:"amount" binding:"required"`
+	UserID     uint       `json:"user_id" binding:"required"`
+	MerchantID uint       `json:"merchant_id" binding:"required"`
+	Type       string     `json:"type" binding:"required"`
+	Amount     float64    `json:"amount" binding:"required,gt=0"`
+	ExpiryDate *time.Time `json:"expiry_date"`
+	// CampaignID, TransactionID, BranchID and VestsAt are set by the loyalty
+	// engine, never by the clients of the API. BranchID is the branch of the
+	// transaction, where a branch operator may grant its rewards.
+	CampaignID    *uint      `json:"-"`
+	BranchID      *uint      `json:"-"`
+	TransactionID *uint      `json:"-"`
+	VestsAt       *time.Time `json:"-"`
 }
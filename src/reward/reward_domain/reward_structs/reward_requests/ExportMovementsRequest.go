@@ -0,0 +1,11 @@
+package reward_requests
+
+import "time"
+
+type ExportMovementsRequest struct {
+	MerchantID *uint      `form:"merchantId"`
+	UserID     *uint      `form:"userId"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format     string     `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
@@ -0,0 +1,13 @@
+package reward_requests
+
+import "time"
+
+// HoldRewardsRequest reserves an amount of a user's rewards until ExpiresAt.
+type HoldRewardsRequest struct {
+	UserID     uint
+	MerchantID uint
+	BranchID   *uint
+	Type       string
+	Amount     float64
+	ExpiresAt  time.Time
+}
@@ -0,0 +1,11 @@
+package reward_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListRewardsRequest accepts sort by id, amount or createdAt.
+type ListRewardsRequest struct {
+	pagination.Request
+	MerchantID *uint  `form:"merchantId"`
+	UserID     *uint  `form:"userId"`
+	Type       string `form:"type" binding:"omitempty,oneof=points cashback"`
+}
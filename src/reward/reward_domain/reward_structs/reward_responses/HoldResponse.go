@@ -0,0 +1,18 @@
+package reward_responses
+
+import "time"
+
+type HoldResponse struct {
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"user_id"`
+	MerchantID     uint       `json:"merchant_id"`
+	BranchID       *uint      `json:"branch_id,omitempty"`
+	Type           string     `json:"type"`
+	Amount         float64    `json:"amount"`
+	CapturedAmount *float64   `json:"captured_amount,omitempty"`
+	Status         string     `json:"status"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CapturedAt     *time.Time `json:"captured_at,omitempty"`
+	VoidedAt       *time.Time `json:"voided_at,omitempty"`
+}
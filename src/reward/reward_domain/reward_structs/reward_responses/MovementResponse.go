@@ -0,0 +1,18 @@
+package reward_responses
+
+import "time"
+
+// MovementResponse is a change to the rewards of a user: a reward granted,
+// adjusted, revoked or expired, or an amount redeemed. For adjustments the
+// amount is the new amount of the reward.
+type MovementResponse struct {
+	EventID    string     `json:"event_id"`
+	Type       string     `json:"type"`
+	RewardID   *uint      `json:"reward_id,omitempty"`
+	UserID     uint       `json:"user_id"`
+	MerchantID uint       `json:"merchant_id"`
+	RewardType string     `json:"reward_type"`
+	Amount     float64    `json:"amount"`
+	ExpiryDate *time.Time `json:"expiry_date,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
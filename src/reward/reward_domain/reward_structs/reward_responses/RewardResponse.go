@@ -1,11 +1,18 @@
 package reward_responses
 
+import "time"
+
 type RewardResponse struct {
-	ID         uint    `json:"id"`
-	UserID     uint    `json:"user_id"`
-	MerchantID uint    `json:"merchant_id"`
-	Type       string  `json:"type"`
-	Amount     float64 `json:"amount"`
+	ID            uint       `json:"id"`
+	UserID        uint       `json:"user_id"`
+	MerchantID    uint       `json:"merchant_id"`
+	Type          string     `json:"type"`
+	Amount        float64    `json:"amount"`
+	ExpiryDate    *time.Time `json:"expiry_date,omitempty"`
+	CampaignID    *uint      `json:"campaign_id,omitempty"`
+	TransactionID *uint      `json:"transaction_id,omitempty"`
+	// VestsAt is set while the reward is pending and cannot be spent yet.
+	VestsAt *time.Time `json:"vests_at,omitempty"`
 }
 
 type TotalRewardsResponse struct {
@@ -13,3 +20,16 @@ type TotalRewardsResponse struct {
 	TotalPoints   float64 `json:"total_points"`
 	TotalCashback float64 `json:"total_cashback"`
 }
+
+// BalanceResponse reports the total amount of the rewards of a type, the part
+// of it that has not vested yet, the part reserved by redemption holds and the
+// rest, available to redeem.
+type BalanceResponse struct {
+	MerchantID uint      `json:"merchant_id"`
+	Type       string    `json:"type"`
+	Amount     float64   `json:"amount"`
+	Pending    float64   `json:"pending"`
+	Held       float64   `json:"held"`
+	Available  float64   `json:"available"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
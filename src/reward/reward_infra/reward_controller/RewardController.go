@@ -2,6 +2,10 @@ package reward_controller
 
 import (
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/exports"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/reward/reward_app"
 	"loyalty-campaigns/src/reward/reward_domain/reward_structs/reward_requests"
 	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
@@ -21,7 +25,7 @@ var (
 	rewardControllerOnce     sync.Once
 )
 
-func NewRewardController(router *gin.Engine) *RewardController {
+func NewRewardController(router *gin.RouterGroup) *RewardController {
 	rewardControllerOnce.Do(func() {
 		rewardControllerInstance = &RewardController{}
 		db := configs.NewDBConnection().GetDB()
@@ -32,10 +36,12 @@ func NewRewardController(router *gin.Engine) *RewardController {
 	return rewardControllerInstance
 }
 
-func (c *RewardController) setupRewardRoutes(router *gin.Engine) {
-	rewardGroup := router.Group("/api/rewards")
+func (c *RewardController) setupRewardRoutes(router *gin.RouterGroup) {
+	rewardGroup := router.Group("/rewards")
 	{
-		rewardGroup.POST("", c.CreateReward)
+		rewardGroup.POST("", security.RequireAdmin(), c.CreateReward)
+		rewardGroup.GET("", c.ListRewards)
+		rewardGroup.GET("/movements/export", c.ExportMovements)
 		rewardGroup.GET("/:id", c.GetReward)
 		rewardGroup.GET("/user/:userID", c.ListRewardsByUser)
 		rewardGroup.GET("/user/:userID/total", c.GetTotalRewardsByUser)
@@ -49,21 +55,22 @@ func (c *RewardController) setupRewardRoutes(router *gin.Engine) {
 //	@Tags			rewards
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		reward_requests.CreateRewardRequest	true	"Reward creation request"
 //	@Success		201		{object}	reward_responses.RewardResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/rewards [post]
 func (c *RewardController) CreateReward(ctx *gin.Context) {
 	var req reward_requests.CreateRewardRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.rewardService.CreateReward(req)
+	response, err := c.rewardService.CreateReward(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -77,21 +84,22 @@ func (c *RewardController) CreateReward(ctx *gin.Context) {
 //	@Tags			rewards
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Reward ID"
 //	@Success		200	{object}	reward_responses.RewardResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		404	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
 //	@Router			/api/rewards/{id} [get]
 func (c *RewardController) GetReward(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.rewardService.GetReward(uint(id))
+	response, err := c.rewardService.GetReward(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		ctx.Error(err)
 		return
 	}
 
@@ -101,29 +109,66 @@ func (c *RewardController) GetReward(ctx *gin.Context) {
 // ListRewardsByUser godoc
 //
 //	@Summary		List rewards for a specific user
-//	@Description	Get a list of all rewards for a given user
+//	@Description	Get a page of the rewards of a given user
 //	@Tags			rewards
 //	@Accept			json
 //	@Produce		json
-//	@Param			userID	path		int	true	"User ID"
-//	@Success		200		{array}		reward_responses.RewardResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Security		ApiKeyAuth
+//	@Param			userID	path		int									true	"User ID"
+//	@Param			request	query		reward_requests.ListRewardsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[reward_responses.RewardResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/rewards/user/{userID} [get]
 func (c *RewardController) ListRewardsByUser(ctx *gin.Context) {
 	userID, err := strconv.ParseUint(ctx.Param("userID"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		ctx.Error(domain_errors.Validation("invalid_user_id", "Invalid user ID"))
+		return
+	}
+
+	var req reward_requests.ListRewardsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+	id := uint(userID)
+	req.UserID = &id
+
+	c.listRewards(ctx, req)
+}
+
+// ListRewards godoc
+//
+//	@Summary		List rewards
+//	@Description	Get a page of rewards filtered by merchant, user and type. Sort by id, amount or createdAt, prefixed with "-" for descending order; the default is -createdAt.
+//	@Tags			rewards
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	query		reward_requests.ListRewardsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[reward_responses.RewardResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/rewards [get]
+func (c *RewardController) ListRewards(ctx *gin.Context) {
+	var req reward_requests.ListRewardsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	responses, err := c.rewardService.ListRewardsByUser(uint(userID))
+	c.listRewards(ctx, req)
+}
+
+func (c *RewardController) listRewards(ctx *gin.Context, req reward_requests.ListRewardsRequest) {
+	page, err := c.rewardService.ListRewards(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responses)
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetTotalRewardsByUser godoc
@@ -133,23 +178,50 @@ func (c *RewardController) ListRewardsByUser(ctx *gin.Context) {
 //	@Tags			rewards
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			userID	path		int	true	"User ID"
 //	@Success		200		{object}	reward_responses.TotalRewardsResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/rewards/user/{userID}/total [get]
 func (c *RewardController) GetTotalRewardsByUser(ctx *gin.Context) {
 	userID, err := strconv.ParseUint(ctx.Param("userID"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		ctx.Error(domain_errors.Validation("invalid_user_id", "Invalid user ID"))
 		return
 	}
 
-	response, err := c.rewardService.GetTotalRewardsByUser(uint(userID))
+	response, err := c.rewardService.GetTotalRewardsByUser(ctx.Request.Context(), uint(userID))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// ExportMovements godoc
+//
+//	@Summary		Export reward movements
+//	@Description	Download the rewards granted, adjusted, revoked, redeemed and expired, filtered by merchant, user and date range, as CSV (the default) or JSON lines in the order they were recorded. For adjustments the amount is the new amount of the reward. The file is streamed as it is read, so exports of any size are supported.
+//	@Tags			rewards
+//	@Produce		text/csv,application/x-ndjson
+//	@Security		ApiKeyAuth
+//	@Param			request	query		reward_requests.ExportMovementsRequest	false	"Filters and format"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/rewards/movements/export [get]
+func (c *RewardController) ExportMovements(ctx *gin.Context) {
+	var req reward_requests.ExportMovementsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	err := c.rewardService.ExportMovements(ctx.Request.Context(), req, exports.Attachment(ctx, "reward-movements", req.Format))
+	if err != nil {
+		ctx.Error(err)
+	}
+}
@@ -1,11 +1,20 @@
 package reward_repository
 
 import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/exports"
+	"loyalty-campaigns/src/common/metrics"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 	"loyalty-campaigns/src/reward/reward_domain/reward_ports"
+	"math"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormRewardRepository struct {
@@ -16,92 +25,710 @@ func NewGormRewardRepository(db *gorm.DB) reward_ports.IRewardRepository {
 	return &GormRewardRepository{DB: db}
 }
 
-func (r *GormRewardRepository) Create(reward *models.Reward) error {
-	return r.DB.Create(reward).Error
+// Create, Update and Delete keep the balances projection in sync with the
+// rewards ledger and record the reward event, in the same database transaction.
+func (r *GormRewardRepository) Create(ctx context.Context, reward *models.Reward) error {
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		return GrantReward(tx, reward, events.RewardGranted)
+	})
+	return domain_errors.Translate(err, "reward")
 }
 
-func (r *GormRewardRepository) GetByID(id uint) (*models.Reward, error) {
+// GrantReward creates the reward within the caller's database transaction,
+// for the repositories of other modules that grant rewards as part of their
+// own changes. The event type is reward.granted, reward.refunded when the
+// reward gives back a cancelled redemption, or reward.credited when it applies
+// a manual adjustment. The transaction must come from configs.Transaction or a
+// unit of work, so that the granted rewards are only counted in the metrics
+// once it commits; refunds give back rewards already counted and are not.
+func GrantReward(tx *gorm.DB, reward *models.Reward, eventType string) error {
+	err := tx.Create(reward).Error
+	if err != nil {
+		return err
+	}
+	err = adjustBalance(tx, reward, reward.Amount)
+	if err != nil {
+		return err
+	}
+	err = events.Enqueue(tx, events.ForReward(eventType, reward))
+	if err != nil {
+		return err
+	}
+
+	if eventType != events.RewardRefunded {
+		rewardType, merchant, amount := reward.Type, metrics.ID(reward.MerchantID), reward.Amount
+		configs.AfterCommit(tx.Statement.Context, func() {
+			metrics.RewardsGranted.WithLabelValues(rewardType, merchant).Inc()
+			metrics.AddAmount(metrics.RewardsGrantedAmount.WithLabelValues(rewardType, merchant), amount)
+		})
+	}
+	return nil
+}
+
+func (r *GormRewardRepository) GetByID(ctx context.Context, id uint) (*models.Reward, error) {
 	var reward models.Reward
-	err := r.DB.First(&reward, id).Error
+	err := configs.DB(ctx, r.DB).First(&reward, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "reward")
 	}
 	return &reward, nil
 }
 
-func (r *GormRewardRepository) Update(reward *models.Reward) error {
-	return r.DB.Save(reward).Error
+func (r *GormRewardRepository) Update(ctx context.Context, reward *models.Reward) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var previous models.Reward
+		err := tx.First(&previous, reward.ID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Save(reward).Error
+		if err != nil {
+			return err
+		}
+		err = adjustBalance(tx, &previous, -previous.Amount)
+		if err != nil {
+			return err
+		}
+		err = adjustBalance(tx, reward, reward.Amount)
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForReward(events.RewardAdjusted, reward))
+	})
+	return domain_errors.Translate(err, "reward")
 }
 
-func (r *GormRewardRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Reward{}, id).Error
+func (r *GormRewardRepository) Delete(ctx context.Context, id uint) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		return deleteReward(tx, id, events.RewardRevoked)
+	})
+	return domain_errors.Translate(err, "reward")
 }
 
-func (r *GormRewardRepository) List() ([]models.Reward, error) {
-	var rewards []models.Reward
-	err := r.DB.Find(&rewards).Error
-	return rewards, err
+// deleteReward removes the reward from the ledger and its balance, and records
+// it as an event of the given type.
+func deleteReward(tx *gorm.DB, id uint, eventType string) error {
+	var reward models.Reward
+	err := tx.First(&reward, id).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&reward).Error
+	if err != nil {
+		return err
+	}
+	err = adjustBalance(tx, &reward, -reward.Amount)
+	if err != nil {
+		return err
+	}
+	return events.Enqueue(tx, events.ForReward(eventType, &reward))
+}
+
+// Redeem consumes the amount from the user's rewards of the type, those that
+// expire first before the others, and records a single reward.redeemed event.
+// The rewards are locked so that concurrent redemptions cannot spend them twice.
+func (r *GormRewardRepository) Redeem(ctx context.Context, userID, merchantID uint, rewardType string, amount float64) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		return ConsumeRewards(tx, userID, merchantID, rewardType, amount)
+	})
+	return domain_errors.Translate(err, "reward")
+}
+
+// ConsumeRewards is Redeem within the caller's database transaction. Only the
+// rewards not reserved by redemption holds can be redeemed.
+func ConsumeRewards(tx *gorm.DB, userID, merchantID uint, rewardType string, amount float64) error {
+	err := consumeRewards(tx, userID, merchantID, rewardType, amount, 0)
+	if err != nil {
+		return err
+	}
+	return events.Enqueue(tx, events.ForRedemption(userID, merchantID, rewardType, amount))
+}
+
+// DebitRewards consumes the amount of a debit adjustment from the user's
+// available rewards as Redeem does, within the caller's database transaction,
+// and records it as a reward.debited event.
+func DebitRewards(tx *gorm.DB, adjustment *models.Adjustment) error {
+	err := consumeRewards(tx, adjustment.UserID, adjustment.MerchantID, adjustment.RewardType, adjustment.Amount, 0)
+	if err != nil {
+		return err
+	}
+	return events.Enqueue(tx, events.ForDebit(adjustment))
+}
+
+// consumeRewards spends amount, of which released was reserved by a hold that
+// the redemption captures and releases. The caller records the event.
+func consumeRewards(tx *gorm.DB, userID, merchantID uint, rewardType string, amount, released float64) error {
+	rewards, total, held, err := lockRewards(tx, userID, merchantID, rewardType)
+	if err != nil {
+		return err
+	}
+	if total-(held-released) < amount {
+		return reward_ports.ErrInsufficientBalance
+	}
+
+	remaining := amount
+	for _, reward := range rewards {
+		if remaining <= 0 {
+			break
+		}
+		if reward.Amount <= remaining {
+			// Use up this reward completely
+			err = tx.Delete(&reward).Error
+			remaining -= reward.Amount
+		} else {
+			// Partially use this reward
+			err = tx.Model(&reward).Update("amount", reward.Amount-remaining).Error
+			remaining = 0
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	err = adjustAmount(tx, userID, merchantID, rewardType, -amount)
+	if err != nil {
+		return err
+	}
+	if released > 0 {
+		return adjustHeld(tx, userID, merchantID, rewardType, -released)
+	}
+	return nil
+}
+
+// lockRewards locks the user's vested rewards of the type, those that expire
+// first before the others, and returns them with their total and the amount
+// held from them. Everything that spends or reserves a balance locks its
+// rewards first, which serializes them.
+func lockRewards(tx *gorm.DB, userID, merchantID uint, rewardType string) (rewards []models.Reward, total, held float64, err error) {
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND merchant_id = ? AND type = ? AND vests_at IS NULL", userID, merchantID, rewardType).
+		Order("expiry_date ASC NULLS LAST, id").
+		Find(&rewards).Error
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, reward := range rewards {
+		total += reward.Amount
+	}
+
+	err = tx.Model(&models.Balance{}).
+		Select("COALESCE(SUM(held), 0)").
+		Where("user_id = ? AND merchant_id = ? AND type = ?", userID, merchantID, rewardType).
+		Scan(&held).Error
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return rewards, total, held, nil
+}
+
+// adjustBalance adds delta to the balance of the reward, and to its pending
+// part while the reward has not vested.
+func adjustBalance(tx *gorm.DB, reward *models.Reward, delta float64) error {
+	err := adjustAmount(tx, reward.UserID, reward.MerchantID, reward.Type, delta)
+	if err != nil {
+		return err
+	}
+	if reward.VestsAt == nil {
+		return nil
+	}
+	return adjustPending(tx, reward.UserID, reward.MerchantID, reward.Type, delta)
+}
+
+func adjustAmount(tx *gorm.DB, userID, merchantID uint, rewardType string, delta float64) error {
+	return tx.Exec(`
+		INSERT INTO balances (user_id, merchant_id, type, amount, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+		ON CONFLICT (user_id, merchant_id, type)
+		DO UPDATE SET amount = balances.amount + EXCLUDED.amount, updated_at = NOW()
+	`, userID, merchantID, rewardType, delta).Error
 }
 
-func (r *GormRewardRepository) GetByUserID(userID uint) ([]models.Reward, error) {
+func adjustPending(tx *gorm.DB, userID, merchantID uint, rewardType string, delta float64) error {
+	return tx.Exec(`
+		INSERT INTO balances (user_id, merchant_id, type, pending, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+		ON CONFLICT (user_id, merchant_id, type)
+		DO UPDATE SET pending = balances.pending + EXCLUDED.pending, updated_at = NOW()
+	`, userID, merchantID, rewardType, delta).Error
+}
+
+func adjustHeld(tx *gorm.DB, userID, merchantID uint, rewardType string, delta float64) error {
+	return tx.Exec(`
+		INSERT INTO balances (user_id, merchant_id, type, held, created_at, updated_at)
+		VALUES (?, ?, ?, ?, NOW(), NOW())
+		ON CONFLICT (user_id, merchant_id, type)
+		DO UPDATE SET held = balances.held + EXCLUDED.held, updated_at = NOW()
+	`, userID, merchantID, rewardType, delta).Error
+}
+
+var rewardSorting = pagination.Sorting[models.Reward]{
+	IDColumn: "id",
+	ID:       func(reward *models.Reward) uint { return reward.ID },
+	Fields: map[string]pagination.Key[models.Reward]{
+		"id":        {Column: "id", Value: func(reward *models.Reward) any { return reward.ID }},
+		"amount":    {Column: "amount", Value: func(reward *models.Reward) any { return reward.Amount }},
+		"createdAt": {Column: "created_at", Value: func(reward *models.Reward) any { return reward.CreatedAt }},
+	},
+	Default: "-createdAt",
+}
+
+func (r *GormRewardRepository) List(ctx context.Context, filter reward_ports.RewardFilter, page pagination.Request) (*pagination.Page[models.Reward], error) {
+	query := configs.DB(ctx, r.DB)
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	return pagination.Find(query, page, rewardSorting)
+}
+
+func (r *GormRewardRepository) StreamMovements(ctx context.Context, filter reward_ports.MovementFilter, fn func([]models.OutboxEvent) error) error {
+	query := configs.DB(ctx, r.DB).Where("type LIKE 'reward.%'")
+	if filter.MerchantID != nil {
+		query = query.Where("merchant_id = ?", *filter.MerchantID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("occurred_at <= ?", *filter.To)
+	}
+	return exports.Stream(ctx, r.DB, query.Order("id"), fn)
+}
+
+func (r *GormRewardRepository) GetByUserID(ctx context.Context, userID uint, merchantID *uint) ([]models.Reward, error) {
 	var rewards []models.Reward
-	err := r.DB.Where("user_id = ?", userID).Find(&rewards).Error
+	query := configs.DB(ctx, r.DB).Where("user_id = ?", userID)
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+	err := query.Find(&rewards).Error
 	return rewards, err
 }
 
-func (r *GormRewardRepository) GetByMerchantID(merchantID uint) ([]models.Reward, error) {
+func (r *GormRewardRepository) GetByMerchantID(ctx context.Context, merchantID uint) ([]models.Reward, error) {
 	var rewards []models.Reward
-	err := r.DB.Where("merchant_id = ?", merchantID).Find(&rewards).Error
+	err := configs.DB(ctx, r.DB).Where("merchant_id = ?", merchantID).Find(&rewards).Error
 	return rewards, err
 }
 
-func (r *GormRewardRepository) GetByUserAndMerchant(userID, merchantID uint) ([]models.Reward, error) {
+func (r *GormRewardRepository) GetByUserAndMerchant(ctx context.Context, userID, merchantID uint) ([]models.Reward, error) {
 	var rewards []models.Reward
-	err := r.DB.Where("user_id = ? AND merchant_id = ?", userID, merchantID).Find(&rewards).Error
+	err := configs.DB(ctx, r.DB).Where("user_id = ? AND merchant_id = ?", userID, merchantID).Find(&rewards).Error
 	return rewards, err
 }
 
-func (r *GormRewardRepository) SumRewardsByUser(userID uint, rewardType string) (float64, error) {
+func (r *GormRewardRepository) SumRewardsByUser(ctx context.Context, userID uint, rewardType string) (float64, error) {
 	var totalReward float64
-	err := r.DB.Model(&models.Reward{}).
+	err := configs.DB(ctx, r.DB).Model(&models.Reward{}).
 		Select("SUM(amount)").
 		Where("user_id = ? AND type = ? AND is_redeemed = false", userID, rewardType).
 		Scan(&totalReward).Error
 	return totalReward, err
 }
 
-func (r *GormRewardRepository) GetActiveRewards(userID uint, currentDate time.Time) ([]models.Reward, error) {
+func (r *GormRewardRepository) GetActiveRewards(ctx context.Context, userID uint, currentDate time.Time) ([]models.Reward, error) {
 	var rewards []models.Reward
-	err := r.DB.Where("user_id = ? AND expiry_date > ? AND is_redeemed = false", userID, currentDate).Find(&rewards).Error
+	err := configs.DB(ctx, r.DB).Where("user_id = ? AND expiry_date > ? AND is_redeemed = false", userID, currentDate).Find(&rewards).Error
 	return rewards, err
 }
 
-func (r *GormRewardRepository) MarkAsRedeemed(rewardID uint) error {
-	return r.DB.Model(&models.Reward{}).Where("id = ?", rewardID).Update("is_redeemed", true).Error
+func (r *GormRewardRepository) MarkAsRedeemed(ctx context.Context, rewardID uint) error {
+	return configs.DB(ctx, r.DB).Model(&models.Reward{}).Where("id = ?", rewardID).Update("is_redeemed", true).Error
 }
 
-func (r *GormRewardRepository) GetExpiredRewards(currentDate time.Time) ([]models.Reward, error) {
+func (r *GormRewardRepository) GetExpiredRewards(ctx context.Context, currentDate time.Time) ([]models.Reward, error) {
 	var rewards []models.Reward
-	err := r.DB.Where("expiry_date <= ? AND is_redeemed = false", currentDate).Find(&rewards).Error
+	err := configs.DB(ctx, r.DB).Where("expiry_date <= ?", currentDate).Find(&rewards).Error
 	return rewards, err
 }
 
-func (r *GormRewardRepository) GetTotalRewardsByUser(userID uint) (totalPoints float64, totalCashback float64, err error) {
+// rewardKey identifies the rewards of a type of a user at a merchant, which
+// are the ones a balance and its holds refer to.
+type rewardKey struct {
+	UserID     uint
+	MerchantID uint
+	Type       string
+}
+
+// ExpireRewards removes the rewards that expired. Holds cannot reserve more
+// than the rewards left, so those that reserved the expired rewards are
+// reduced in the same transaction, the newest first, and voided when nothing
+// is left of them.
+func (r *GormRewardRepository) ExpireRewards(ctx context.Context, currentDate time.Time) ([]models.Reward, error) {
+	var expired []models.Reward
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var keys []rewardKey
+		err := tx.Model(&models.Reward{}).
+			Distinct("user_id", "merchant_id", "type").
+			Where("expiry_date <= ?", currentDate).
+			Order("user_id, merchant_id, type").
+			Scan(&keys).Error
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			rewards, err := expireRewards(tx, key, currentDate)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, rewards...)
+		}
+		return nil
+	})
+	return expired, err
+}
+
+// expireRewards removes the expired rewards of the key and reduces its holds.
+// The holds are locked before the rewards, as CaptureHold does, so that both
+// cannot deadlock.
+func expireRewards(tx *gorm.DB, key rewardKey, currentDate time.Time) ([]models.Reward, error) {
+	var holds []models.RedemptionHold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND merchant_id = ? AND type = ? AND status = ?", key.UserID, key.MerchantID, key.Type, models.RedemptionHoldHeld).
+		Order("created_at DESC, id DESC").
+		Find(&holds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rewards []models.Reward
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("user_id = ? AND merchant_id = ? AND type = ? AND expiry_date <= ?", key.UserID, key.MerchantID, key.Type, currentDate).
+		Find(&rewards).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, reward := range rewards {
+		err = deleteReward(tx, reward.ID, events.RewardExpired)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(rewards) == 0 || len(holds) == 0 {
+		return rewards, nil
+	}
+	return rewards, reduceHolds(tx, key, holds, currentDate)
+}
+
+// reduceHolds takes from the holds, in order, the amount they reserve beyond
+// the rewards of the key.
+func reduceHolds(tx *gorm.DB, key rewardKey, holds []models.RedemptionHold, now time.Time) error {
+	_, total, held, err := lockRewards(tx, key.UserID, key.MerchantID, key.Type)
+	if err != nil {
+		return err
+	}
+
+	excess := held - total
+	for i := range holds {
+		if excess <= 0 {
+			break
+		}
+		hold := &holds[i]
+		reduction := math.Min(excess, hold.Amount)
+		excess -= reduction
+
+		err = adjustHeld(tx, hold.UserID, hold.MerchantID, hold.Type, -reduction)
+		if err != nil {
+			return err
+		}
+		hold.Amount -= reduction
+		if hold.Amount > 0 {
+			err = saveHold(tx, hold, events.HoldReduced)
+		} else {
+			hold.Status = models.RedemptionHoldVoided
+			hold.VoidedAt = &now
+			err = saveHold(tx, hold, events.HoldVoided)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *GormRewardRepository) VestRewards(ctx context.Context, now time.Time) ([]models.Reward, error) {
+	var vested []models.Reward
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var rewards []models.Reward
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("vests_at <= ?", now).
+			Find(&rewards).Error
+		if err != nil {
+			return err
+		}
+		for i := range rewards {
+			reward := &rewards[i]
+			err = adjustPending(tx, reward.UserID, reward.MerchantID, reward.Type, -reward.Amount)
+			if err != nil {
+				return err
+			}
+			reward.VestsAt = nil
+			err = tx.Model(reward).Update("vests_at", nil).Error
+			if err != nil {
+				return err
+			}
+			err = events.Enqueue(tx, events.ForReward(events.RewardVested, reward))
+			if err != nil {
+				return err
+			}
+		}
+		vested = rewards
+		return nil
+	})
+	return vested, err
+}
+
+// RevokePendingRewards revokes the rewards earned by the transaction that have
+// not vested yet, within the caller's database transaction, and returns them.
+// The rewards already vested are kept: they may have been spent.
+func RevokePendingRewards(tx *gorm.DB, transactionID uint) ([]models.Reward, error) {
+	var rewards []models.Reward
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ? AND vests_at IS NOT NULL", transactionID).
+		Find(&rewards).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, reward := range rewards {
+		err = deleteReward(tx, reward.ID, events.RewardRevoked)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rewards, nil
+}
+
+func (r *GormRewardRepository) GetBalances(ctx context.Context, userID uint, merchantID *uint) ([]models.Balance, error) {
+	var balances []models.Balance
+	query := configs.DB(ctx, r.DB).Where("user_id = ?", userID)
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+	err := query.Order("merchant_id, type").Find(&balances).Error
+	return balances, err
+}
+
+// RecalculateBalances rebuilds the balances projection from the rewards ledger
+// and returns how many balances had drifted and were corrected.
+func (r *GormRewardRepository) RecalculateBalances(ctx context.Context) (int64, error) {
+	var corrected int64
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			UPDATE balances b SET amount = 0, pending = 0, updated_at = NOW()
+			WHERE (b.amount <> 0 OR b.pending <> 0) AND NOT EXISTS (
+				SELECT 1 FROM rewards r
+				WHERE r.deleted_at IS NULL AND r.user_id = b.user_id AND r.merchant_id = b.merchant_id AND r.type = b.type
+			)
+		`)
+		if result.Error != nil {
+			return result.Error
+		}
+		corrected = result.RowsAffected
+
+		result = tx.Exec(`
+			INSERT INTO balances (user_id, merchant_id, type, amount, pending, created_at, updated_at)
+			SELECT user_id, merchant_id, type, SUM(amount), COALESCE(SUM(amount) FILTER (WHERE vests_at IS NOT NULL), 0), NOW(), NOW()
+			FROM rewards
+			WHERE deleted_at IS NULL
+			GROUP BY user_id, merchant_id, type
+			ON CONFLICT (user_id, merchant_id, type)
+			DO UPDATE SET amount = EXCLUDED.amount, pending = EXCLUDED.pending, updated_at = NOW()
+			WHERE balances.amount IS DISTINCT FROM EXCLUDED.amount OR balances.pending IS DISTINCT FROM EXCLUDED.pending
+		`)
+		if result.Error != nil {
+			return result.Error
+		}
+		corrected += result.RowsAffected
+
+		result = tx.Exec(`
+			WITH held AS (
+				SELECT user_id, merchant_id, type, SUM(amount) AS amount
+				FROM redemption_holds
+				WHERE deleted_at IS NULL AND status = ?
+				GROUP BY user_id, merchant_id, type
+			)
+			UPDATE balances b SET held = COALESCE(held.amount, 0), updated_at = NOW()
+			FROM balances current
+			LEFT JOIN held ON held.user_id = current.user_id AND held.merchant_id = current.merchant_id AND held.type = current.type
+			WHERE current.id = b.id AND b.held IS DISTINCT FROM COALESCE(held.amount, 0)
+		`, models.RedemptionHoldHeld)
+		if result.Error != nil {
+			return result.Error
+		}
+		corrected += result.RowsAffected
+		return nil
+	})
+	return corrected, err
+}
+
+func (r *GormRewardRepository) Hold(ctx context.Context, hold *models.RedemptionHold) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		_, total, held, err := lockRewards(tx, hold.UserID, hold.MerchantID, hold.Type)
+		if err != nil {
+			return err
+		}
+		if total-held < hold.Amount {
+			return reward_ports.ErrInsufficientBalance
+		}
+
+		hold.Status = models.RedemptionHoldHeld
+		err = tx.Create(hold).Error
+		if err != nil {
+			return err
+		}
+		err = adjustHeld(tx, hold.UserID, hold.MerchantID, hold.Type, hold.Amount)
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForHold(events.HoldAuthorized, hold))
+	})
+	return domain_errors.Translate(err, "redemption_hold")
+}
+
+func (r *GormRewardRepository) GetHold(ctx context.Context, id uint) (*models.RedemptionHold, error) {
+	var hold models.RedemptionHold
+	err := configs.DB(ctx, r.DB).First(&hold, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "redemption_hold")
+	}
+	return &hold, nil
+}
+
+func (r *GormRewardRepository) CaptureHold(ctx context.Context, id uint, amount float64, now time.Time) (*models.RedemptionHold, error) {
+	var hold models.RedemptionHold
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := lockActiveHold(tx, id, &hold)
+		if err != nil {
+			return err
+		}
+		if !now.Before(hold.ExpiresAt) {
+			return reward_ports.ErrHoldExpired
+		}
+		if amount > hold.Amount {
+			return reward_ports.ErrCaptureExceedsHold
+		}
+
+		err = consumeRewards(tx, hold.UserID, hold.MerchantID, hold.Type, amount, hold.Amount)
+		if err != nil {
+			return err
+		}
+		err = events.Enqueue(tx, events.ForRedemption(hold.UserID, hold.MerchantID, hold.Type, amount))
+		if err != nil {
+			return err
+		}
+		hold.Status = models.RedemptionHoldCaptured
+		hold.CapturedAmount = &amount
+		hold.CapturedAt = &now
+		return saveHold(tx, &hold, events.HoldCaptured)
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "redemption_hold")
+	}
+	return &hold, nil
+}
+
+func (r *GormRewardRepository) VoidHold(ctx context.Context, id uint, now time.Time) (*models.RedemptionHold, error) {
+	var hold models.RedemptionHold
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := lockActiveHold(tx, id, &hold)
+		if err != nil {
+			return err
+		}
+		hold.Status = models.RedemptionHoldVoided
+		hold.VoidedAt = &now
+		return releaseHold(tx, &hold, events.HoldVoided)
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "redemption_hold")
+	}
+	return &hold, nil
+}
+
+func (r *GormRewardRepository) ExpireHolds(ctx context.Context, now time.Time) ([]models.RedemptionHold, error) {
+	var expired []models.RedemptionHold
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		var holds []models.RedemptionHold
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at <= ?", models.RedemptionHoldHeld, now).
+			Find(&holds).Error
+		if err != nil {
+			return err
+		}
+		for i := range holds {
+			holds[i].Status = models.RedemptionHoldExpired
+			err = releaseHold(tx, &holds[i], events.HoldExpired)
+			if err != nil {
+				return err
+			}
+		}
+		expired = holds
+		return nil
+	})
+	return expired, err
+}
+
+// lockActiveHold locks the hold into hold, which has to be held.
+func lockActiveHold(tx *gorm.DB, id uint, hold *models.RedemptionHold) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(hold, id).Error
+	if err != nil {
+		return err
+	}
+	if hold.Status != models.RedemptionHoldHeld {
+		return reward_ports.ErrHoldNotActive
+	}
+	return nil
+}
+
+// releaseHold gives the amount of the hold back to the available balance.
+func releaseHold(tx *gorm.DB, hold *models.RedemptionHold, eventType string) error {
+	err := adjustHeld(tx, hold.UserID, hold.MerchantID, hold.Type, -hold.Amount)
+	if err != nil {
+		return err
+	}
+	return saveHold(tx, hold, eventType)
+}
+
+func saveHold(tx *gorm.DB, hold *models.RedemptionHold, eventType string) error {
+	err := tx.Save(hold).Error
+	if err != nil {
+		return err
+	}
+	return events.Enqueue(tx, events.ForHold(eventType, hold))
+}
+
+func (r *GormRewardRepository) GetTotalRewardsByUser(ctx context.Context, userID uint, merchantID *uint) (totalPoints float64, totalCashback float64, err error) {
 	var pointsSum, cashbackSum struct {
 		Total float64
 	}
 
-	err = r.DB.Model(&models.Reward{}).
+	byUser := func() *gorm.DB {
+		query := configs.DB(ctx, r.DB).Model(&models.Balance{}).Where("user_id = ?", userID)
+		if merchantID != nil {
+			query = query.Where("merchant_id = ?", *merchantID)
+		}
+		return query
+	}
+
+	err = byUser().
 		Select("SUM(amount) as total").
-		Where("user_id = ? AND type = ?", userID, "points").
+		Where("type = ?", "points").
 		Scan(&pointsSum).Error
 	if err != nil {
 		return 0, 0, err
 	}
 
-	err = r.DB.Model(&models.Reward{}).
+	err = byUser().
 		Select("SUM(amount) as total").
-		Where("user_id = ? AND type = ?", userID, "cashback").
+		Where("type = ?", "cashback").
 		Scan(&cashbackSum).Error
 	if err != nil {
 		return 0, 0, err
@@ -110,8 +737,8 @@ func (r *GormRewardRepository) GetTotalRewardsByUser(userID uint) (totalPoints f
 	return pointsSum.Total, cashbackSum.Total, nil
 }
 
-func (r *GormRewardRepository) GetByUserMerchantAndType(userID, merchantID uint, rewardType string) ([]models.Reward, error) {
+func (r *GormRewardRepository) GetByUserMerchantAndType(ctx context.Context, userID, merchantID uint, rewardType string) ([]models.Reward, error) {
 	var rewards []models.Reward
-	err := r.DB.Where("user_id = ? AND merchant_id = ? AND type = ?", userID, merchantID, rewardType).Find(&rewards).Error
+	err := configs.DB(ctx, r.DB).Where("user_id = ? AND merchant_id = ? AND type = ?", userID, merchantID, rewardType).Find(&rewards).Error
 	return rewards, err
 }
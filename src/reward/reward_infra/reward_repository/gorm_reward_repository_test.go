@@ -0,0 +1,289 @@
+package reward_repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/metrics"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/reward/reward_domain/reward_ports"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("GormRewardRepository", func() {
+	var (
+		sqlMock    sqlmock.Sqlmock
+		db         *gorm.DB
+		repository reward_ports.IRewardRepository
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err = gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		repository = reward_repository.NewGormRewardRepository(db)
+		now = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	rewardColumns := []string{"id", "user_id", "merchant_id", "type", "amount", "expiry_date"}
+	holdColumns := []string{"id", "created_at", "user_id", "merchant_id", "type", "amount", "status", "expires_at"}
+
+	// expectExpiry expects the expiry of reward 5, worth 50 points of user 1 at
+	// merchant 4, while the user holds 80 points in hold 9.
+	expectExpiry := func() {
+		sqlMock.ExpectBegin()
+		sqlMock.ExpectQuery(`SELECT DISTINCT "user_id","merchant_id","type" FROM "rewards" WHERE expiry_date <= \$1`).
+			WithArgs(now).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "merchant_id", "type"}).AddRow(1, 4, "points"))
+		sqlMock.ExpectQuery(`SELECT \* FROM "redemption_holds" WHERE .* ORDER BY created_at DESC, id DESC FOR UPDATE$`).
+			WithArgs(1, 4, "points", "held").
+			WillReturnRows(sqlmock.NewRows(holdColumns).AddRow(9, now.Add(-time.Hour), 1, 4, "points", 80, "held", now.Add(time.Hour)))
+		sqlMock.ExpectQuery(`SELECT \* FROM "rewards" WHERE .*expiry_date <= \$4.* FOR UPDATE SKIP LOCKED`).
+			WithArgs(1, 4, "points", now).
+			WillReturnRows(sqlmock.NewRows(rewardColumns).AddRow(5, 1, 4, "points", 50, now.Add(-time.Minute)))
+		sqlMock.ExpectQuery(`SELECT \* FROM "rewards" WHERE "rewards"."id" = \$1`).
+			WillReturnRows(sqlmock.NewRows(rewardColumns).AddRow(5, 1, 4, "points", 50, now.Add(-time.Minute)))
+		sqlMock.ExpectExec(`UPDATE "rewards" SET "deleted_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+		sqlMock.ExpectExec(`INSERT INTO balances .* amount = balances.amount`).
+			WithArgs(1, 4, "points", -50.0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectEvent(sqlMock, events.RewardExpired)
+	}
+
+	Describe("Create", func() {
+		granted := func(merchant string) float64 {
+			return testutil.ToFloat64(metrics.RewardsGranted.WithLabelValues("points", merchant))
+		}
+
+		grantedAmount := func(merchant string) float64 {
+			return testutil.ToFloat64(metrics.RewardsGrantedAmount.WithLabelValues("points", merchant))
+		}
+
+		// expectGrant expects reward 5 of the merchant to be stored with its
+		// balance and event.
+		expectGrant := func(merchantID uint) {
+			sqlMock.ExpectQuery(`INSERT INTO "rewards"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+			sqlMock.ExpectExec(`INSERT INTO balances .* amount = balances.amount`).
+				WithArgs(1, merchantID, "points", 25.0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectEvent(sqlMock, events.RewardGranted)
+		}
+
+		It("should count the reward granted and its amount once the transaction commits", func() {
+			count, amount := granted("101"), grantedAmount("101")
+			sqlMock.ExpectBegin()
+			expectGrant(101)
+			sqlMock.ExpectCommit()
+
+			err := repository.Create(context.Background(), &models.Reward{UserID: 1, MerchantID: 101, Type: "points", Amount: 25})
+
+			Expect(err).To(BeNil())
+			Expect(granted("101")).To(Equal(count + 1))
+			Expect(grantedAmount("101")).To(Equal(amount + 25))
+		})
+
+		It("should leave amounts that are not positive out of the amount counter instead of panicking", func() {
+			amount := grantedAmount("104")
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectQuery(`INSERT INTO "rewards"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+			sqlMock.ExpectExec(`INSERT INTO balances`).WillReturnResult(sqlmock.NewResult(0, 1))
+			expectEvent(sqlMock, events.RewardGranted)
+			sqlMock.ExpectCommit()
+
+			Expect(func() {
+				err := repository.Create(context.Background(), &models.Reward{UserID: 1, MerchantID: 104, Type: "points", Amount: -5})
+				Expect(err).To(BeNil())
+			}).NotTo(Panic())
+			Expect(grantedAmount("104")).To(Equal(amount))
+		})
+
+		It("should not count the reward when the transaction rolls back", func() {
+			count := granted("102")
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectQuery(`INSERT INTO "rewards"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+			sqlMock.ExpectExec(`INSERT INTO balances`).WillReturnError(context.DeadlineExceeded)
+			sqlMock.ExpectRollback()
+
+			err := repository.Create(context.Background(), &models.Reward{UserID: 1, MerchantID: 102, Type: "points", Amount: 25})
+
+			Expect(err).To(HaveOccurred())
+			Expect(granted("102")).To(Equal(count))
+		})
+
+		It("should wait for the unit of work the reward is granted in", func() {
+			count := granted("103")
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+			expectGrant(103)
+			sqlMock.ExpectRollback()
+
+			err := configs.NewUnitOfWork(db).Run(context.Background(), func(ctx context.Context) error {
+				err := repository.Create(ctx, &models.Reward{UserID: 1, MerchantID: 103, Type: "points", Amount: 25})
+				Expect(err).To(BeNil())
+				Expect(granted("103")).To(Equal(count))
+				return context.Canceled
+			})
+
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(granted("103")).To(Equal(count))
+		})
+	})
+
+	Describe("ExpireRewards", func() {
+		It("should reduce the hold that reserved the rewards that expired", func() {
+			expectExpiry()
+			// 30 points are left for a hold of 80
+			sqlMock.ExpectQuery(`SELECT \* FROM "rewards" WHERE .*vests_at IS NULL.* FOR UPDATE$`).
+				WillReturnRows(sqlmock.NewRows(rewardColumns).AddRow(6, 1, 4, "points", 30, nil))
+			sqlMock.ExpectQuery(`SELECT COALESCE\(SUM\(held\), 0\) FROM "balances"`).
+				WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(80))
+			sqlMock.ExpectExec(`INSERT INTO balances .* held = balances.held`).
+				WithArgs(1, 4, "points", -50.0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			sqlMock.ExpectExec(`UPDATE "redemption_holds" SET .*"amount"=\$8,"captured_amount"=\$9,"status"=\$10`).
+				WithArgs(holdArgs(30.0, "held")...).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectEvent(sqlMock, events.HoldReduced)
+			sqlMock.ExpectCommit()
+
+			expired, err := repository.ExpireRewards(context.Background(), now)
+
+			Expect(err).To(BeNil())
+			Expect(expired).To(HaveLen(1))
+			Expect(expired[0].ID).To(Equal(uint(5)))
+		})
+
+		It("should void the hold when no rewards are left for it", func() {
+			expectExpiry()
+			sqlMock.ExpectQuery(`SELECT \* FROM "rewards" WHERE .*vests_at IS NULL.* FOR UPDATE$`).
+				WillReturnRows(sqlmock.NewRows(rewardColumns))
+			sqlMock.ExpectQuery(`SELECT COALESCE\(SUM\(held\), 0\) FROM "balances"`).
+				WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(80))
+			sqlMock.ExpectExec(`INSERT INTO balances .* held = balances.held`).
+				WithArgs(1, 4, "points", -80.0).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			sqlMock.ExpectExec(`UPDATE "redemption_holds" SET .*"amount"=\$8,"captured_amount"=\$9,"status"=\$10`).
+				WithArgs(holdArgs(0.0, "voided")...).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			expectEvent(sqlMock, events.HoldVoided)
+			sqlMock.ExpectCommit()
+
+			_, err := repository.ExpireRewards(context.Background(), now)
+
+			Expect(err).To(BeNil())
+		})
+
+		It("should leave the hold alone when the rewards left still cover it", func() {
+			expectExpiry()
+			sqlMock.ExpectQuery(`SELECT \* FROM "rewards" WHERE .*vests_at IS NULL.* FOR UPDATE$`).
+				WillReturnRows(sqlmock.NewRows(rewardColumns).AddRow(6, 1, 4, "points", 100, nil))
+			sqlMock.ExpectQuery(`SELECT COALESCE\(SUM\(held\), 0\) FROM "balances"`).
+				WillReturnRows(sqlmock.NewRows([]string{"held"}).AddRow(80))
+			sqlMock.ExpectCommit()
+
+			_, err := repository.ExpireRewards(context.Background(), now)
+
+			Expect(err).To(BeNil())
+		})
+	})
+
+	Describe("StreamMovements", func() {
+		eventColumns := []string{"id", "event_id", "type", "aggregate_type", "aggregate_id", "user_id", "merchant_id", "payload", "occurred_at"}
+
+		// expectCursor expects the movements to be read through a cursor over
+		// the query, in the batches given.
+		expectCursor := func(query string, args []driver.Value, batches ...*sqlmock.Rows) {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`^DECLARE export_cursor NO SCROLL CURSOR FOR ` + query + `$`).
+				WithArgs(args...).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			for _, rows := range batches {
+				sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).WillReturnRows(rows)
+			}
+			sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).WillReturnRows(sqlmock.NewRows(eventColumns))
+			sqlMock.ExpectExec(`^CLOSE export_cursor$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectCommit()
+		}
+
+		It("should read the reward events of the merchant that match the filters", func() {
+			merchantID, userID := uint(4), uint(1)
+			from, to := now.AddDate(0, -1, 0), now
+			expectCursor(
+				`SELECT \* FROM "outbox_events" WHERE type LIKE 'reward\.%' AND merchant_id = \$1 AND user_id = \$2 AND occurred_at >= \$3 AND occurred_at <= \$4 ORDER BY id`,
+				[]driver.Value{merchantID, userID, from, to},
+				sqlmock.NewRows(eventColumns).AddRow(7, "e7", events.RewardGranted, "reward", 5, userID, merchantID, "{}", now),
+			)
+
+			var movements []models.OutboxEvent
+			err := repository.StreamMovements(context.Background(), reward_ports.MovementFilter{
+				MerchantID: &merchantID,
+				UserID:     &userID,
+				From:       &from,
+				To:         &to,
+			}, func(batch []models.OutboxEvent) error {
+				movements = append(movements, batch...)
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(movements).To(HaveLen(1))
+			Expect(movements[0].EventID).To(Equal("e7"))
+			Expect(*movements[0].MerchantID).To(Equal(merchantID))
+		})
+
+		It("should read the reward events of every merchant without a merchant filter", func() {
+			expectCursor(`SELECT \* FROM "outbox_events" WHERE type LIKE 'reward\.%' ORDER BY id`, nil)
+
+			err := repository.StreamMovements(context.Background(), reward_ports.MovementFilter{}, func(batch []models.OutboxEvent) error {
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+		})
+	})
+})
+
+// expectEvent expects an event of the type to be written to the outbox.
+func expectEvent(sqlMock sqlmock.Sqlmock, eventType string) {
+	sqlMock.ExpectQuery(`INSERT INTO outbox_partitions`).
+		WillReturnRows(sqlmock.NewRows([]string{"last_sequence"}).AddRow(1))
+	args := make([]driver.Value, 15)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[1] = eventType
+	sqlMock.ExpectQuery(`INSERT INTO "outbox_events"`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+// holdArgs matches the update of hold 9 to the amount and status.
+func holdArgs(amount float64, status string) []driver.Value {
+	args := make([]driver.Value, 14)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	args[7] = amount
+	args[9] = status
+	args[13] = 9
+	return args
+}
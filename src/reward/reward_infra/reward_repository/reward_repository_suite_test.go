@@ -0,0 +1,13 @@
+package reward_repository_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRewardRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RewardRepository Suite")
+}
@@ -0,0 +1,148 @@
+package src
+
+import (
+	"context"
+	"fmt"
+	"loyalty-campaigns/src/branch/branch_domain/branch_structs/branch_requests"
+	"loyalty-campaigns/src/campaign/campaign_domain/campaign_structs/campaign_requests"
+	"loyalty-campaigns/src/merchant/merchant_domain/merchant_structs/merchant_requests"
+	"loyalty-campaigns/src/user/user_domain/user_structs/user_requests"
+	"os"
+	"time"
+)
+
+type seedCampaign struct {
+	branch     string
+	days       int
+	rewardType string
+	value      float64
+	minAmount  *float64
+}
+
+type seedMerchant struct {
+	merchant  merchant_requests.CreateMerchantRequest
+	branches  []string
+	campaigns []seedCampaign
+	users     []string
+}
+
+func seedData() []seedMerchant {
+	validity := 365
+	minAmount := 50000.0
+
+	return []seedMerchant{
+		{
+			merchant: merchant_requests.CreateMerchantRequest{
+				Name:               "Café Aroma (demo)",
+				ConversionFactor:   0.01,
+				DefaultRewardType:  "points",
+				RewardValidityDays: &validity,
+			},
+			branches: []string{"Centro", "Norte"},
+			campaigns: []seedCampaign{
+				{branch: "Centro", days: 30, rewardType: "points", value: 2},
+				{days: 90, rewardType: "points", value: 1.5, minAmount: &minAmount},
+			},
+			users: []string{"Ana Gómez", "Carlos Pérez", "Laura Rodríguez"},
+		},
+		{
+			merchant: merchant_requests.CreateMerchantRequest{
+				Name:              "Tienda La Esquina (demo)",
+				ConversionFactor:  0.02,
+				DefaultRewardType: "cashback",
+			},
+			branches: []string{"Chapinero", "Usaquén"},
+			campaigns: []seedCampaign{
+				{days: 15, rewardType: "cashback", value: 1.2},
+			},
+			users: []string{"Jorge Martínez", "Sofía Ramírez"},
+		},
+	}
+}
+
+// seed loads demo merchants with their branches, campaigns and enrolled users.
+// Merchants that already exist are skipped, so running it twice is harmless.
+func seed(ctx context.Context, c *container, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "seed takes no arguments\n\n%s", usage)
+		return exitUsage
+	}
+	if !requireSchema(ctx, c) {
+		return exitFailure
+	}
+
+	for _, data := range seedData() {
+		err := seedMerchantData(ctx, c, data)
+		if err != nil {
+			logger.Error("failed to seed %s: %v", data.merchant.Name, err)
+			return exitFailure
+		}
+	}
+
+	logger.Success("[OK] demo data loaded")
+	return exitOK
+}
+
+func seedMerchantData(ctx context.Context, c *container, data seedMerchant) error {
+	existing, err := c.merchantService.ListMerchants(ctx, merchant_requests.ListMerchantsRequest{Name: data.merchant.Name})
+	if err != nil {
+		return err
+	}
+	for _, merchant := range existing.Items {
+		if merchant.Name == data.merchant.Name {
+			logger.Info("%s already exists, skipping", data.merchant.Name)
+			return nil
+		}
+	}
+
+	merchant, err := c.merchantService.CreateMerchant(ctx, data.merchant)
+	if err != nil {
+		return err
+	}
+
+	branchIDs := map[string]uint{}
+	for _, name := range data.branches {
+		branch, err := c.branchService.CreateBranch(ctx, branch_requests.CreateBranchRequest{
+			Name:       name,
+			MerchantID: merchant.ID,
+		})
+		if err != nil {
+			return err
+		}
+		branchIDs[name] = branch.ID
+	}
+
+	now := time.Now()
+	for _, campaign := range data.campaigns {
+		endDate := now.AddDate(0, 0, campaign.days)
+		req := campaign_requests.CreateCampaignRequest{
+			MerchantID: merchant.ID,
+			StartDate:  now,
+			EndDate:    &endDate,
+			Type:       campaign.rewardType,
+			Value:      campaign.value,
+			MinAmount:  campaign.minAmount,
+		}
+		if campaign.branch != "" {
+			branchID := branchIDs[campaign.branch]
+			req.BranchID = &branchID
+		}
+		_, err := c.campaignService.CreateCampaign(ctx, req)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, name := range data.users {
+		_, err := c.userService.CreateUser(ctx, user_requests.CreateUserRequest{
+			Name:       name,
+			MerchantID: &merchant.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("seeded %s with %d branches, %d campaigns and %d users", merchant.Name, len(data.branches), len(data.campaigns), len(data.users))
+	return nil
+}
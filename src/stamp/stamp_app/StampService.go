@@ -0,0 +1,156 @@
+package stamp_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/catalog/catalog_app"
+	"loyalty-campaigns/src/common/codes"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_ports"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_structs/stamp_requests"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_structs/stamp_responses"
+	"time"
+)
+
+type IStampService interface {
+	// AddStamps adds the stamp of the transaction to the user's cards of the
+	// stamp-card campaigns, and returns the cards it completed.
+	AddStamps(ctx context.Context, req stamp_requests.AddStampsRequest) ([]stamp_responses.StampCardResponse, error)
+	ListStampCards(ctx context.Context, userID uint, req stamp_requests.ListStampCardsRequest) ([]stamp_responses.StampCardResponse, error)
+}
+
+type stampService struct {
+	stampRepo stamp_ports.IStampRepository
+	vouchers  catalog_app.VoucherPolicy
+	logger    utils.ILogger
+}
+
+// NewStampService issues the vouchers of the catalog items of full cards with
+// the same policy as those of catalog redemptions.
+func NewStampService(stampRepo stamp_ports.IStampRepository, vouchers catalog_app.VoucherPolicy) IStampService {
+	return &stampService{
+		stampRepo: stampRepo,
+		vouchers:  vouchers,
+		logger:    utils.NewLogger(),
+	}
+}
+
+func (s *stampService) AddStamps(ctx context.Context, req stamp_requests.AddStampsRequest) ([]stamp_responses.StampCardResponse, error) {
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, &req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := stamp_ports.StampedTransaction{
+		ID:         req.TransactionID,
+		UserID:     req.UserID,
+		MerchantID: req.MerchantID,
+		BranchID:   req.BranchID,
+		Date:       req.Date,
+	}
+	completed := []stamp_responses.StampCardResponse{}
+	for _, campaignID := range req.CampaignIDs {
+		// The voucher is only used when the stamp completes a card of a
+		// campaign that rewards a catalog item
+		now := time.Now()
+		voucher, err := s.newVoucher(now)
+		if err != nil {
+			return nil, err
+		}
+
+		card, err := s.stampRepo.AddStamp(ctx, campaignID, transaction, voucher, now)
+		if err != nil {
+			s.logger.Error("Error al agregar sello", err)
+			return nil, err
+		}
+		if card != nil && card.Status == models.StampCardCompleted {
+			completed = append(completed, *stampCardToResponse(card))
+		}
+	}
+	return completed, nil
+}
+
+// ListStampCards shows merchant callers only the cards of their merchant.
+func (s *stampService) ListStampCards(ctx context.Context, userID uint, req stamp_requests.ListStampCardsRequest) ([]stamp_responses.StampCardResponse, error) {
+	err := s.authorizeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := s.stampRepo.ListByUser(ctx, userID, merchantID, req.Status)
+	if err != nil {
+		s.logger.Error("Error al listar tarjetas de sellos", err)
+		return nil, err
+	}
+
+	responses := make([]stamp_responses.StampCardResponse, len(cards))
+	for i := range cards {
+		responses[i] = *stampCardToResponse(&cards[i])
+	}
+	return responses, nil
+}
+
+// authorizeUser lets merchant callers read only the members of their program.
+func (s *stampService) authorizeUser(ctx context.Context, userID uint) error {
+	principal, ok := security.PrincipalFromContext(ctx)
+	if !ok {
+		return security.ErrUnauthenticated
+	}
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	err := security.Authorize(ctx, security.ActionRead, principal.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.stampRepo.IsMember(ctx, userID, principal.MerchantID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return security.ErrForbidden
+	}
+	return nil
+}
+
+func (s *stampService) newVoucher(now time.Time) (*models.Voucher, error) {
+	code, err := codes.Generate(s.vouchers.CodeLength, s.vouchers.CheckDigit)
+	if err != nil {
+		s.logger.Error("Error al generar código de vale", err)
+		return nil, err
+	}
+	voucher := &models.Voucher{Code: code, Status: models.VoucherIssued}
+	if s.vouchers.ValidityDays > 0 {
+		expiresAt := now.AddDate(0, 0, s.vouchers.ValidityDays)
+		voucher.ExpiresAt = &expiresAt
+	}
+	return voucher, nil
+}
+
+func stampCardToResponse(card *models.StampCard) *stamp_responses.StampCardResponse {
+	response := &stamp_responses.StampCardResponse{
+		ID:               card.ID,
+		CampaignID:       card.CampaignID,
+		UserID:           card.UserID,
+		MerchantID:       card.MerchantID,
+		Stamps:           card.Stamps,
+		StampsRequired:   card.StampsRequired,
+		Status:           card.Status,
+		CreatedAt:        card.CreatedAt,
+		CompletedAt:      card.CompletedAt,
+		RewardID:         card.RewardID,
+		ItemRedemptionID: card.ItemRedemptionID,
+	}
+	if card.ItemRedemption != nil && card.ItemRedemption.Voucher != nil {
+		response.VoucherCode = &card.ItemRedemption.Voucher.Code
+	}
+	return response
+}
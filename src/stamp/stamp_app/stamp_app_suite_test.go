@@ -0,0 +1,13 @@
+package stamp_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestStampApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "StampApp Suite")
+}
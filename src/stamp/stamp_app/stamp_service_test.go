@@ -0,0 +1,178 @@
+package stamp_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/catalog/catalog_app"
+	"loyalty-campaigns/src/common/codes"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/stamp/stamp_app"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_ports"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_structs/stamp_requests"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("StampService", func() {
+	var (
+		stampService stamp_app.IStampService
+		mockStamp    *mockStampRepository
+		merchantID   uint
+		date         time.Time
+	)
+
+	BeforeEach(func() {
+		mockStamp = new(mockStampRepository)
+		stampService = stamp_app.NewStampService(mockStamp, catalog_app.VoucherPolicy{
+			CodeLength:   10,
+			CheckDigit:   true,
+			ValidityDays: 30,
+		})
+		merchantID = 4
+		date = time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC)
+	})
+
+	admin := func() context.Context {
+		return security.WithPrincipal(context.Background(), security.System())
+	}
+
+	operator := func() context.Context {
+		return security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleMerchantAdmin,
+			KeyID:      21,
+			MerchantID: merchantID,
+		})
+	}
+
+	card := func(id, campaignID uint, stamps int, status string) *models.StampCard {
+		card := &models.StampCard{CampaignID: campaignID, UserID: 1, MerchantID: merchantID, Stamps: stamps, StampsRequired: 10, Status: status}
+		card.ID = id
+		return card
+	}
+
+	Describe("AddStamps", func() {
+		var req stamp_requests.AddStampsRequest
+
+		BeforeEach(func() {
+			req = stamp_requests.AddStampsRequest{
+				TransactionID: 9,
+				UserID:        1,
+				MerchantID:    merchantID,
+				BranchID:      3,
+				Date:          date,
+				CampaignIDs:   []uint{5, 6},
+			}
+		})
+
+		It("should stamp every campaign and return the cards completed", func() {
+			completed := card(12, 6, 10, models.StampCardCompleted)
+			completed.ItemRedemptionID = ptr(uint(30))
+			completed.ItemRedemption = &models.ItemRedemption{Voucher: &models.Voucher{Code: "ABCDEFGHJK"}}
+			transaction := stamp_ports.StampedTransaction{ID: 9, UserID: 1, MerchantID: merchantID, BranchID: 3, Date: date}
+			mockStamp.On("AddStamp", mock.Anything, uint(5), transaction, mock.AnythingOfType("*models.Voucher"), mock.AnythingOfType("time.Time")).Return(card(11, 5, 3, models.StampCardOpen), nil)
+			mockStamp.On("AddStamp", mock.Anything, uint(6), transaction, mock.AnythingOfType("*models.Voucher"), mock.AnythingOfType("time.Time")).Return(completed, nil)
+
+			response, err := stampService.AddStamps(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].ID).To(Equal(uint(12)))
+			Expect(response[0].ItemRedemptionID).To(Equal(ptr(uint(30))))
+			Expect(response[0].VoucherCode).To(Equal(ptr("ABCDEFGHJK")))
+		})
+
+		It("should offer vouchers of the voucher policy", func() {
+			mockStamp.On("AddStamp", mock.Anything, mock.Anything, mock.Anything, mock.AnythingOfType("*models.Voucher"), mock.AnythingOfType("time.Time")).Return(card(11, 5, 3, models.StampCardOpen), nil)
+
+			_, err := stampService.AddStamps(operator(), req)
+
+			Expect(err).To(BeNil())
+			voucher := mockStamp.Calls[0].Arguments.Get(3).(*models.Voucher)
+			now := mockStamp.Calls[0].Arguments.Get(4).(time.Time)
+			Expect(voucher.Code).To(HaveLen(11))
+			Expect(codes.Valid(voucher.Code)).To(BeTrue())
+			Expect(voucher.Status).To(Equal(models.VoucherIssued))
+			Expect(*voucher.ExpiresAt).To(Equal(now.AddDate(0, 0, 30)))
+		})
+
+		It("should skip the transactions that already stamped the campaign", func() {
+			mockStamp.On("AddStamp", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+			response, err := stampService.AddStamps(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(BeEmpty())
+		})
+
+		It("should reject callers of other merchants", func() {
+			req.MerchantID = 7
+
+			_, err := stampService.AddStamps(operator(), req)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockStamp.AssertNotCalled(GinkgoT(), "AddStamp", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("ListStampCards", func() {
+		It("should list the cards of every merchant for admins", func() {
+			mockStamp.On("ListByUser", mock.Anything, uint(1), (*uint)(nil), models.StampCardOpen).Return([]models.StampCard{*card(11, 5, 3, models.StampCardOpen)}, nil)
+
+			response, err := stampService.ListStampCards(admin(), 1, stamp_requests.ListStampCardsRequest{Status: models.StampCardOpen})
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Stamps).To(Equal(3))
+			Expect(response[0].StampsRequired).To(Equal(10))
+		})
+
+		It("should only list the cards of the merchant of the caller", func() {
+			mockStamp.On("IsMember", mock.Anything, uint(1), merchantID).Return(true, nil)
+			mockStamp.On("ListByUser", mock.Anything, uint(1), &merchantID, "").Return([]models.StampCard{}, nil)
+
+			response, err := stampService.ListStampCards(operator(), 1, stamp_requests.ListStampCardsRequest{})
+
+			Expect(err).To(BeNil())
+			Expect(response).To(BeEmpty())
+		})
+
+		It("should reject users outside the merchant's program", func() {
+			mockStamp.On("IsMember", mock.Anything, uint(1), merchantID).Return(false, nil)
+
+			_, err := stampService.ListStampCards(operator(), 1, stamp_requests.ListStampCardsRequest{})
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockStamp.AssertNotCalled(GinkgoT(), "ListByUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+})
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+type mockStampRepository struct {
+	mock.Mock
+}
+
+func (m *mockStampRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockStampRepository) AddStamp(ctx context.Context, campaignID uint, transaction stamp_ports.StampedTransaction, voucher *models.Voucher, now time.Time) (*models.StampCard, error) {
+	args := m.Called(ctx, campaignID, transaction, voucher, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StampCard), args.Error(1)
+}
+
+func (m *mockStampRepository) ListByUser(ctx context.Context, userID uint, merchantID *uint, status string) ([]models.StampCard, error) {
+	args := m.Called(ctx, userID, merchantID, status)
+	return args.Get(0).([]models.StampCard), args.Error(1)
+}
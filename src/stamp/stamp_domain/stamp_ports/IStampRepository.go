@@ -0,0 +1,31 @@
+package stamp_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"time"
+)
+
+var ErrNotStampCard = domain_errors.Validation("not_a_stamp_card", "the campaign is not a stamp-card campaign")
+
+// StampedTransaction is the processed transaction that adds a stamp.
+type StampedTransaction struct {
+	ID         uint
+	UserID     uint
+	MerchantID uint
+	BranchID   uint
+	Date       time.Time
+}
+
+type IStampRepository interface {
+	IsMember(ctx context.Context, userID, merchantID uint) (bool, error)
+	// AddStamp adds the stamp of the transaction to the user's open card of
+	// the campaign, starting one if there is none, and completes the card when
+	// it is full. Campaigns that reward a catalog item issue the voucher for it. It
+	// returns nil when the transaction already stamped the campaign.
+	AddStamp(ctx context.Context, campaignID uint, transaction StampedTransaction, voucher *models.Voucher, now time.Time) (*models.StampCard, error)
+	// ListByUser returns the user's cards with the given status, or all of
+	// them, newest first, at the merchant when one is given.
+	ListByUser(ctx context.Context, userID uint, merchantID *uint, status string) ([]models.StampCard, error)
+}
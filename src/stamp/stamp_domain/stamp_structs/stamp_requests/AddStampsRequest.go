@@ -0,0 +1,14 @@
+package stamp_requests
+
+import "time"
+
+// AddStampsRequest describes a processed transaction that qualifies for the
+// stamp-card campaigns in CampaignIDs.
+type AddStampsRequest struct {
+	TransactionID uint
+	UserID        uint
+	MerchantID    uint
+	BranchID      uint
+	Date          time.Time
+	CampaignIDs   []uint
+}
@@ -0,0 +1,5 @@
+package stamp_requests
+
+type ListStampCardsRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=open completed"`
+}
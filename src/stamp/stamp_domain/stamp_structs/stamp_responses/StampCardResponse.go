@@ -0,0 +1,20 @@
+package stamp_responses
+
+import "time"
+
+// StampCardResponse shows a card of a stamp-card campaign. A completed card
+// shows its reward, or the redemption and voucher code of its catalog item.
+type StampCardResponse struct {
+	ID               uint       `json:"id"`
+	CampaignID       uint       `json:"campaignId"`
+	UserID           uint       `json:"userId"`
+	MerchantID       uint       `json:"merchantId"`
+	Stamps           int        `json:"stamps"`
+	StampsRequired   int        `json:"stampsRequired"`
+	Status           string     `json:"status"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty"`
+	RewardID         *uint      `json:"rewardId,omitempty"`
+	ItemRedemptionID *uint      `json:"itemRedemptionId,omitempty"`
+	VoucherCode      *string    `json:"voucherCode,omitempty"`
+}
@@ -0,0 +1,77 @@
+package stamp_controller
+
+import (
+	"loyalty-campaigns/src/catalog/catalog_app"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/stamp/stamp_app"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_structs/stamp_requests"
+	"loyalty-campaigns/src/stamp/stamp_infra/stamp_repository"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StampController struct {
+	stampService stamp_app.IStampService
+}
+
+var (
+	stampControllerInstance *StampController
+	stampControllerOnce     sync.Once
+)
+
+func NewStampController(router *gin.RouterGroup) *StampController {
+	stampControllerOnce.Do(func() {
+		stampControllerInstance = &StampController{}
+		db := configs.NewDBConnection().GetDB()
+		stampRepository := stamp_repository.NewGormStampRepository(db)
+		stampControllerInstance.stampService = stamp_app.NewStampService(stampRepository, catalog_app.VoucherPolicyFromEnv())
+		stampControllerInstance.setupStampRoutes(router)
+	})
+	return stampControllerInstance
+}
+
+func (c *StampController) setupStampRoutes(router *gin.RouterGroup) {
+	userGroup := router.Group("/users")
+	{
+		userGroup.GET("/:id/stamp-cards", c.ListStampCards)
+	}
+}
+
+// ListStampCards godoc
+//
+//	@Summary		List the stamp cards of a user
+//	@Description	Get the user's cards of stamp-card campaigns, newest first: the open card of each campaign with its stamps, and the completed ones with their reward, or the redemption and voucher code of their catalog item. Merchant callers only see the cards of their merchant.
+//	@Tags			stamp-cards
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int		true	"User ID"
+//	@Param			status	query		string	false	"Card status"	Enums(open, completed)
+//	@Success		200		{array}		stamp_responses.StampCardResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Router			/api/users/{id}/stamp-cards [get]
+func (c *StampController) ListStampCards(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req stamp_requests.ListStampCardsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.stampService.ListStampCards(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,272 @@
+package stamp_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"loyalty-campaigns/src/stamp/stamp_domain/stamp_ports"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormStampRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormStampRepository(db *gorm.DB) stamp_ports.IStampRepository {
+	return &GormStampRepository{DB: db}
+}
+
+func (r *GormStampRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	var count int64
+	err := configs.DB(ctx, r.DB).Model(&models.Membership{}).
+		Where("user_id = ? AND merchant_id = ?", userID, merchantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// AddStamp locks the open card before the rewards and the catalog item of its
+// completion.
+func (r *GormStampRepository) AddStamp(ctx context.Context, campaignID uint, transaction stamp_ports.StampedTransaction, voucher *models.Voucher, now time.Time) (*models.StampCard, error) {
+	var stamped *models.StampCard
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		var campaign models.Campaign
+		err := tx.Limit(1).Find(&campaign, campaignID).Error
+		if err != nil {
+			return err
+		}
+		if campaign.ID == 0 || campaign.MerchantID != transaction.MerchantID {
+			return domain_errors.NotFound("campaign_not_found", "campaign not found")
+		}
+		if campaign.Kind != models.CampaignStampCard || campaign.StampsRequired == nil {
+			return stamp_ports.ErrNotStampCard
+		}
+
+		card, err := lockOpenCard(tx, &campaign, transaction.UserID)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Stamp{
+			StampCardID:   card.ID,
+			CampaignID:    campaign.ID,
+			TransactionID: transaction.ID,
+		})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		card.Stamps++
+		err = events.Enqueue(tx, events.ForStampCard(events.StampAdded, card, transaction.ID))
+		if err != nil {
+			return err
+		}
+		if card.Stamps >= card.StampsRequired {
+			err = completeCard(tx, card, &campaign, transaction, voucher, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Omit(clause.Associations).Save(card).Error
+		if err != nil {
+			return err
+		}
+		stamped = card
+		if card.Status == models.StampCardCompleted {
+			return events.Enqueue(tx, events.ForStampCard(events.StampCardCompleted, card, transaction.ID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "stamp_card")
+	}
+	return stamped, nil
+}
+
+func (r *GormStampRepository) ListByUser(ctx context.Context, userID uint, merchantID *uint, status string) ([]models.StampCard, error) {
+	var cards []models.StampCard
+	query := configs.DB(ctx, r.DB).Preload("ItemRedemption.Voucher").Where("user_id = ?", userID)
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC, id DESC").Find(&cards).Error
+	return cards, err
+}
+
+// RevokeStamps revokes the stamps of a reversed transaction. A stamp comes off
+// its card while the card is open. The reward of a completed card stays
+// issued, so its stamp comes off the user's open card of the campaign instead,
+// and is forgiven when that card has no stamps yet.
+func RevokeStamps(tx *gorm.DB, transactionID uint, now time.Time) error {
+	var stamps []models.Stamp
+	err := tx.Where("transaction_id = ? AND revoked_at IS NULL", transactionID).
+		Order("campaign_id").Find(&stamps).Error
+	if err != nil {
+		return err
+	}
+
+	for _, stamp := range stamps {
+		var card models.StampCard
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&card, stamp.StampCardID).Error
+		if err != nil {
+			return err
+		}
+		if card.Status != models.StampCardOpen {
+			var open []models.StampCard
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("campaign_id = ? AND user_id = ? AND status = ? AND stamps > 0", card.CampaignID, card.UserID, models.StampCardOpen).
+				Limit(1).Find(&open).Error
+			if err != nil {
+				return err
+			}
+			if len(open) > 0 {
+				card = open[0]
+			}
+		}
+
+		err = tx.Model(&stamp).Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		if card.Status == models.StampCardOpen {
+			card.Stamps--
+			err = tx.Model(&card).Update("stamps", card.Stamps).Error
+			if err != nil {
+				return err
+			}
+		}
+		err = events.Enqueue(tx, events.ForStampCard(events.StampRevoked, &card, transactionID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockOpenCard locks the user's open card of the campaign, starting a new one
+// with the campaign's current size when there is none. The unique index on
+// the open cards makes concurrent transactions share the new card.
+func lockOpenCard(tx *gorm.DB, campaign *models.Campaign, userID uint) (*models.StampCard, error) {
+	card, err := findOpenCard(tx, campaign.ID, userID)
+	if err != nil || card != nil {
+		return card, err
+	}
+
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(&models.StampCard{
+		CampaignID:     campaign.ID,
+		UserID:         userID,
+		MerchantID:     campaign.MerchantID,
+		StampsRequired: *campaign.StampsRequired,
+		Status:         models.StampCardOpen,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	card, err = findOpenCard(tx, campaign.ID, userID)
+	if err == nil && card == nil {
+		return nil, domain_errors.NotFound("stamp_card_not_found", "stamp card not found")
+	}
+	return card, err
+}
+
+func findOpenCard(tx *gorm.DB, campaignID, userID uint) (*models.StampCard, error) {
+	var cards []models.StampCard
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("campaign_id = ? AND user_id = ? AND status = ?", campaignID, userID, models.StampCardOpen).
+		Limit(1).Find(&cards).Error
+	if err != nil || len(cards) == 0 {
+		return nil, err
+	}
+	return &cards[0], nil
+}
+
+// completeCard issues the reward of a full card: Value in the campaign's
+// reward type, with the merchant's validity, or a voucher for the campaign's
+// catalog item.
+func completeCard(tx *gorm.DB, card *models.StampCard, campaign *models.Campaign, transaction stamp_ports.StampedTransaction, voucher *models.Voucher, now time.Time) error {
+	card.Status = models.StampCardCompleted
+	card.CompletedAt = &now
+
+	if campaign.CatalogItemID != nil {
+		redemption, err := issueVoucher(tx, card, *campaign.CatalogItemID, transaction.BranchID, voucher)
+		if err != nil {
+			return err
+		}
+		card.ItemRedemptionID = &redemption.ID
+		card.ItemRedemption = redemption
+		return nil
+	}
+
+	var merchant models.Merchant
+	err := tx.First(&merchant, card.MerchantID).Error
+	if err != nil {
+		return err
+	}
+	reward := &models.Reward{
+		UserID:      card.UserID,
+		MerchantID:  card.MerchantID,
+		Type:        campaign.Type,
+		Amount:      campaign.Value,
+		CampaignID:  &campaign.ID,
+		StampCardID: &card.ID,
+	}
+	if merchant.RewardValidityDays != nil {
+		expiry := transaction.Date.AddDate(0, 0, *merchant.RewardValidityDays)
+		reward.ExpiryDate = &expiry
+	}
+	err = reward_repository.GrantReward(tx, reward, events.RewardGranted)
+	if err != nil {
+		return err
+	}
+	card.RewardID = &reward.ID
+	return nil
+}
+
+// issueVoucher gives the user a unit of the catalog item at no cost, as a
+// pending redemption at the branch of the transaction with its voucher. The
+// unit is taken from the stock while there is any, but a full card is never
+// left without its reward.
+func issueVoucher(tx *gorm.DB, card *models.StampCard, itemID, branchID uint, voucher *models.Voucher) (*models.ItemRedemption, error) {
+	var items []models.CatalogItem
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", itemID).Limit(1).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, domain_errors.NotFound("catalog_item_not_found", "catalog item not found")
+	}
+	item := &items[0]
+
+	err = tx.Model(item).Where("stock > 0").Update("stock", gorm.Expr("stock - 1")).Error
+	if err != nil {
+		return nil, err
+	}
+
+	voucher.UserID = card.UserID
+	voucher.MerchantID = card.MerchantID
+	redemption := &models.ItemRedemption{
+		CatalogItemID: item.ID,
+		UserID:        card.UserID,
+		MerchantID:    card.MerchantID,
+		BranchID:      branchID,
+		Quantity:      1,
+		RewardType:    item.RewardType,
+		Status:        models.ItemRedemptionPending,
+		Voucher:       voucher,
+	}
+	err = tx.Create(redemption).Error
+	if err != nil {
+		return nil, err
+	}
+	return redemption, events.Enqueue(tx, events.ForItemRedemption(events.ItemRedeemed, redemption))
+}
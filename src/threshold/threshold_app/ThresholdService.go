@@ -0,0 +1,149 @@
+package threshold_app
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_ports"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_structs/threshold_requests"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_structs/threshold_responses"
+	"time"
+)
+
+type IThresholdService interface {
+	// TrackSpend awards the bonuses of the spend-threshold campaigns whose
+	// threshold the transaction makes its user reach, and returns the awards.
+	TrackSpend(ctx context.Context, req threshold_requests.TrackSpendRequest) ([]threshold_responses.ThresholdAwardResponse, error)
+	ListSpendProgress(ctx context.Context, userID uint) ([]threshold_responses.SpendProgressResponse, error)
+}
+
+type thresholdService struct {
+	thresholdRepo threshold_ports.IThresholdRepository
+	logger        utils.ILogger
+}
+
+func NewThresholdService(thresholdRepo threshold_ports.IThresholdRepository) IThresholdService {
+	return &thresholdService{
+		thresholdRepo: thresholdRepo,
+		logger:        utils.NewLogger(),
+	}
+}
+
+func (s *thresholdService) TrackSpend(ctx context.Context, req threshold_requests.TrackSpendRequest) ([]threshold_responses.ThresholdAwardResponse, error) {
+	err := security.Authorize(ctx, security.ActionOperate, req.MerchantID, &req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := threshold_ports.QualifyingTransaction{
+		ID:         req.TransactionID,
+		UserID:     req.UserID,
+		MerchantID: req.MerchantID,
+		Date:       req.Date,
+	}
+	awarded := []threshold_responses.ThresholdAwardResponse{}
+	for _, campaignID := range req.CampaignIDs {
+		award, err := s.thresholdRepo.Award(ctx, campaignID, transaction, time.Now())
+		if err != nil {
+			s.logger.Error("Error al otorgar bono por monto acumulado", err)
+			return nil, err
+		}
+		if award != nil {
+			awarded = append(awarded, *awardToResponse(award))
+		}
+	}
+	return awarded, nil
+}
+
+// ListSpendProgress shows merchant callers only the campaigns of their
+// merchant.
+func (s *thresholdService) ListSpendProgress(ctx context.Context, userID uint) ([]threshold_responses.SpendProgressResponse, error) {
+	err := s.authorizeUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := s.thresholdRepo.ListProgress(ctx, userID, merchantID, time.Now())
+	if err != nil {
+		s.logger.Error("Error al listar progreso de monto acumulado", err)
+		return nil, err
+	}
+
+	responses := make([]threshold_responses.SpendProgressResponse, len(progress))
+	for i := range progress {
+		responses[i] = *progressToResponse(&progress[i])
+	}
+	return responses, nil
+}
+
+// authorizeUser lets merchant callers read only the members of their program.
+func (s *thresholdService) authorizeUser(ctx context.Context, userID uint) error {
+	principal, ok := security.PrincipalFromContext(ctx)
+	if !ok {
+		return security.ErrUnauthenticated
+	}
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	err := security.Authorize(ctx, security.ActionRead, principal.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.thresholdRepo.IsMember(ctx, userID, principal.MerchantID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return security.ErrForbidden
+	}
+	return nil
+}
+
+func awardToResponse(award *models.ThresholdAward) *threshold_responses.ThresholdAwardResponse {
+	return &threshold_responses.ThresholdAwardResponse{
+		ID:            award.ID,
+		CampaignID:    award.CampaignID,
+		UserID:        award.UserID,
+		MerchantID:    award.MerchantID,
+		TransactionID: award.TransactionID,
+		Spent:         award.Spent,
+		RewardID:      award.RewardID,
+		CreatedAt:     award.CreatedAt,
+	}
+}
+
+// progressToResponse counts the spend after the award towards the award:
+// there is nothing left to reach once it is awarded.
+func progressToResponse(progress *threshold_ports.Progress) *threshold_responses.SpendProgressResponse {
+	campaign := progress.Campaign
+	var threshold float64
+	if campaign.SpendThreshold != nil {
+		threshold = *campaign.SpendThreshold
+	}
+	response := &threshold_responses.SpendProgressResponse{
+		CampaignID:     campaign.ID,
+		MerchantID:     campaign.MerchantID,
+		BranchID:       campaign.BranchID,
+		StartDate:      campaign.StartDate,
+		EndDate:        campaign.EndDate,
+		RewardType:     campaign.Type,
+		Bonus:          campaign.Value,
+		SpendThreshold: threshold,
+		Spent:          progress.Spent,
+		Reached:        progress.Award != nil,
+	}
+	if progress.Award != nil {
+		response.Award = awardToResponse(progress.Award)
+	} else if progress.Spent < threshold {
+		response.Remaining = threshold - progress.Spent
+	}
+	return response
+}
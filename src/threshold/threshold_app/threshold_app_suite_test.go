@@ -0,0 +1,13 @@
+package threshold_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestThresholdApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ThresholdApp Suite")
+}
@@ -0,0 +1,174 @@
+package threshold_app_test
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/threshold/threshold_app"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_ports"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_structs/threshold_requests"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("ThresholdService", func() {
+	var (
+		thresholdService threshold_app.IThresholdService
+		mockThreshold    *mockThresholdRepository
+		merchantID       uint
+		date             time.Time
+	)
+
+	BeforeEach(func() {
+		mockThreshold = new(mockThresholdRepository)
+		thresholdService = threshold_app.NewThresholdService(mockThreshold)
+		merchantID = 4
+		date = time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC)
+	})
+
+	admin := func() context.Context {
+		return security.WithPrincipal(context.Background(), security.System())
+	}
+
+	operator := func() context.Context {
+		return security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleMerchantAdmin,
+			KeyID:      21,
+			MerchantID: merchantID,
+		})
+	}
+
+	campaign := func(id uint) models.Campaign {
+		campaign := models.Campaign{
+			MerchantID:     merchantID,
+			Kind:           models.CampaignSpendThreshold,
+			Type:           "points",
+			Value:          500,
+			SpendThreshold: ptr(1000.0),
+			StartDate:      date.AddDate(0, -1, 0),
+		}
+		campaign.ID = id
+		return campaign
+	}
+
+	Describe("TrackSpend", func() {
+		var req threshold_requests.TrackSpendRequest
+
+		BeforeEach(func() {
+			req = threshold_requests.TrackSpendRequest{
+				TransactionID: 9,
+				UserID:        1,
+				MerchantID:    merchantID,
+				BranchID:      3,
+				Date:          date,
+				CampaignIDs:   []uint{7, 8},
+			}
+		})
+
+		It("should return the awards of the thresholds reached", func() {
+			transaction := threshold_ports.QualifyingTransaction{ID: 9, UserID: 1, MerchantID: merchantID, Date: date}
+			award := &models.ThresholdAward{ID: 15, CampaignID: 8, UserID: 1, MerchantID: merchantID, TransactionID: 9, Spent: 1040, RewardID: ptr(uint(40))}
+			mockThreshold.On("Award", mock.Anything, uint(7), transaction, mock.AnythingOfType("time.Time")).Return(nil, nil)
+			mockThreshold.On("Award", mock.Anything, uint(8), transaction, mock.AnythingOfType("time.Time")).Return(award, nil)
+
+			response, err := thresholdService.TrackSpend(operator(), req)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].ID).To(Equal(uint(15)))
+			Expect(response[0].Spent).To(Equal(1040.0))
+			Expect(response[0].RewardID).To(Equal(ptr(uint(40))))
+		})
+
+		It("should fail when a campaign is not a spend-threshold campaign", func() {
+			mockThreshold.On("Award", mock.Anything, uint(7), mock.Anything, mock.Anything).Return(nil, threshold_ports.ErrNotSpendThreshold)
+
+			_, err := thresholdService.TrackSpend(operator(), req)
+
+			Expect(err).To(MatchError(threshold_ports.ErrNotSpendThreshold))
+			mockThreshold.AssertNumberOfCalls(GinkgoT(), "Award", 1)
+		})
+
+		It("should reject callers of other merchants", func() {
+			req.MerchantID = 7
+
+			_, err := thresholdService.TrackSpend(operator(), req)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockThreshold.AssertNotCalled(GinkgoT(), "Award", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("ListSpendProgress", func() {
+		It("should show what is left to reach the threshold", func() {
+			mockThreshold.On("ListProgress", mock.Anything, uint(1), (*uint)(nil), mock.AnythingOfType("time.Time")).Return([]threshold_ports.Progress{
+				{Campaign: campaign(7), Spent: 640},
+			}, nil)
+
+			response, err := thresholdService.ListSpendProgress(admin(), 1)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].SpendThreshold).To(Equal(1000.0))
+			Expect(response[0].Spent).To(Equal(640.0))
+			Expect(response[0].Remaining).To(Equal(360.0))
+			Expect(response[0].Reached).To(BeFalse())
+			Expect(response[0].Award).To(BeNil())
+		})
+
+		It("should show the award of the thresholds reached", func() {
+			award := &models.ThresholdAward{ID: 15, CampaignID: 7, UserID: 1, MerchantID: merchantID, TransactionID: 9, Spent: 1040}
+			mockThreshold.On("IsMember", mock.Anything, uint(1), merchantID).Return(true, nil)
+			mockThreshold.On("ListProgress", mock.Anything, uint(1), &merchantID, mock.AnythingOfType("time.Time")).Return([]threshold_ports.Progress{
+				{Campaign: campaign(7), Spent: 1300, Award: award},
+			}, nil)
+
+			response, err := thresholdService.ListSpendProgress(operator(), 1)
+
+			Expect(err).To(BeNil())
+			Expect(response).To(HaveLen(1))
+			Expect(response[0].Remaining).To(BeZero())
+			Expect(response[0].Reached).To(BeTrue())
+			Expect(response[0].Award.ID).To(Equal(uint(15)))
+		})
+
+		It("should reject users outside the merchant's program", func() {
+			mockThreshold.On("IsMember", mock.Anything, uint(1), merchantID).Return(false, nil)
+
+			_, err := thresholdService.ListSpendProgress(operator(), 1)
+
+			Expect(err).To(MatchError(domain_errors.ErrForbidden))
+			mockThreshold.AssertNotCalled(GinkgoT(), "ListProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	})
+})
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+type mockThresholdRepository struct {
+	mock.Mock
+}
+
+func (m *mockThresholdRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	args := m.Called(ctx, userID, merchantID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockThresholdRepository) Award(ctx context.Context, campaignID uint, transaction threshold_ports.QualifyingTransaction, now time.Time) (*models.ThresholdAward, error) {
+	args := m.Called(ctx, campaignID, transaction, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ThresholdAward), args.Error(1)
+}
+
+func (m *mockThresholdRepository) ListProgress(ctx context.Context, userID uint, merchantID *uint, now time.Time) ([]threshold_ports.Progress, error) {
+	args := m.Called(ctx, userID, merchantID, now)
+	return args.Get(0).([]threshold_ports.Progress), args.Error(1)
+}
@@ -0,0 +1,39 @@
+package threshold_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"time"
+)
+
+var ErrNotSpendThreshold = domain_errors.Validation("not_a_spend_threshold", "the campaign is not a spend-threshold campaign")
+
+// QualifyingTransaction is the processed transaction that adds to the spend
+// of its user.
+type QualifyingTransaction struct {
+	ID         uint
+	UserID     uint
+	MerchantID uint
+	Date       time.Time
+}
+
+// Progress is the qualifying spend of a user during a spend-threshold
+// campaign, with the award in force once they reached the threshold.
+type Progress struct {
+	Campaign models.Campaign
+	Spent    float64
+	Award    *models.ThresholdAward
+}
+
+type IThresholdRepository interface {
+	IsMember(ctx context.Context, userID, merchantID uint) (bool, error)
+	// Award grants the bonus of the campaign when the transaction brings the
+	// qualifying spend of its user to the threshold. It returns nil when the
+	// threshold is not reached yet or the user already has the award.
+	Award(ctx context.Context, campaignID uint, transaction QualifyingTransaction, now time.Time) (*models.ThresholdAward, error)
+	// ListProgress returns the progress of the user in the spend-threshold
+	// campaigns active at now, of the merchants whose program they belong to,
+	// or of the merchant when one is given.
+	ListProgress(ctx context.Context, userID uint, merchantID *uint, now time.Time) ([]Progress, error)
+}
@@ -0,0 +1,14 @@
+package threshold_requests
+
+import "time"
+
+// TrackSpendRequest describes a processed transaction that qualifies for the
+// spend-threshold campaigns in CampaignIDs.
+type TrackSpendRequest struct {
+	TransactionID uint
+	UserID        uint
+	MerchantID    uint
+	BranchID      uint
+	Date          time.Time
+	CampaignIDs   []uint
+}
@@ -0,0 +1,31 @@
+package threshold_responses
+
+import "time"
+
+type ThresholdAwardResponse struct {
+	ID            uint      `json:"id"`
+	CampaignID    uint      `json:"campaignId"`
+	UserID        uint      `json:"userId"`
+	MerchantID    uint      `json:"merchantId"`
+	TransactionID uint      `json:"transactionId"`
+	Spent         float64   `json:"spent"`
+	RewardID      *uint     `json:"rewardId,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// SpendProgressResponse shows how much a user spent towards the threshold of
+// a spend-threshold campaign, and the award once they reached it.
+type SpendProgressResponse struct {
+	CampaignID     uint                    `json:"campaignId"`
+	MerchantID     uint                    `json:"merchantId"`
+	BranchID       *uint                   `json:"branchId,omitempty"`
+	StartDate      time.Time               `json:"startDate"`
+	EndDate        *time.Time              `json:"endDate,omitempty"`
+	RewardType     string                  `json:"rewardType"`
+	Bonus          float64                 `json:"bonus"`
+	SpendThreshold float64                 `json:"spendThreshold"`
+	Spent          float64                 `json:"spent"`
+	Remaining      float64                 `json:"remaining"`
+	Reached        bool                    `json:"reached"`
+	Award          *ThresholdAwardResponse `json:"award,omitempty"`
+}
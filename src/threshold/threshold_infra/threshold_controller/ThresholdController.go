@@ -0,0 +1,68 @@
+package threshold_controller
+
+import (
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/threshold/threshold_app"
+	"loyalty-campaigns/src/threshold/threshold_infra/threshold_repository"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ThresholdController struct {
+	thresholdService threshold_app.IThresholdService
+}
+
+var (
+	thresholdControllerInstance *ThresholdController
+	thresholdControllerOnce     sync.Once
+)
+
+func NewThresholdController(router *gin.RouterGroup) *ThresholdController {
+	thresholdControllerOnce.Do(func() {
+		thresholdControllerInstance = &ThresholdController{}
+		db := configs.NewDBConnection().GetDB()
+		thresholdRepository := threshold_repository.NewGormThresholdRepository(db)
+		thresholdControllerInstance.thresholdService = threshold_app.NewThresholdService(thresholdRepository)
+		thresholdControllerInstance.setupThresholdRoutes(router)
+	})
+	return thresholdControllerInstance
+}
+
+func (c *ThresholdController) setupThresholdRoutes(router *gin.RouterGroup) {
+	userGroup := router.Group("/users")
+	{
+		userGroup.GET("/:id/spend-progress", c.ListSpendProgress)
+	}
+}
+
+// ListSpendProgress godoc
+//
+//	@Summary		List the spend progress of a user
+//	@Description	Get the user's progress in the active spend-threshold campaigns of the merchants whose program they belong to: the qualifying spend during the campaign, what is left to reach the threshold, and the award once it was reached. Merchant callers only see the campaigns of their merchant.
+//	@Tags			spend-thresholds
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{array}		threshold_responses.SpendProgressResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Router			/api/users/{id}/spend-progress [get]
+func (c *ThresholdController) ListSpendProgress(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.thresholdService.ListSpendProgress(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
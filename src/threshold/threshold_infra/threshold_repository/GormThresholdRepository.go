@@ -0,0 +1,193 @@
+package threshold_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"loyalty-campaigns/src/threshold/threshold_domain/threshold_ports"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormThresholdRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormThresholdRepository(db *gorm.DB) threshold_ports.IThresholdRepository {
+	return &GormThresholdRepository{DB: db}
+}
+
+func (r *GormThresholdRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	var count int64
+	err := configs.DB(ctx, r.DB).Model(&models.Membership{}).
+		Where("user_id = ? AND merchant_id = ?", userID, merchantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Award inserts the award before granting its bonus. The unique index on the
+// awards in force lets only one of the transactions that reach the threshold
+// concurrently award it.
+func (r *GormThresholdRepository) Award(ctx context.Context, campaignID uint, transaction threshold_ports.QualifyingTransaction, now time.Time) (*models.ThresholdAward, error) {
+	var awarded *models.ThresholdAward
+	err := configs.Transaction(ctx, r.DB, func(tx *gorm.DB) error {
+		var campaign models.Campaign
+		err := tx.Limit(1).Find(&campaign, campaignID).Error
+		if err != nil {
+			return err
+		}
+		if campaign.ID == 0 || campaign.MerchantID != transaction.MerchantID {
+			return domain_errors.NotFound("campaign_not_found", "campaign not found")
+		}
+		if campaign.Kind != models.CampaignSpendThreshold || campaign.SpendThreshold == nil {
+			return threshold_ports.ErrNotSpendThreshold
+		}
+
+		spent, err := spentDuring(tx, &campaign, transaction.UserID)
+		if err != nil || spent < *campaign.SpendThreshold {
+			return err
+		}
+
+		award := &models.ThresholdAward{
+			CampaignID:    campaign.ID,
+			UserID:        transaction.UserID,
+			MerchantID:    campaign.MerchantID,
+			TransactionID: transaction.ID,
+			Spent:         spent,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(award)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		reward, err := grantBonus(tx, award, &campaign, transaction)
+		if err != nil {
+			return err
+		}
+		award.RewardID = &reward.ID
+		err = tx.Model(award).Update("reward_id", reward.ID).Error
+		if err != nil {
+			return err
+		}
+		awarded = award
+		return events.Enqueue(tx, events.ForThresholdAward(events.ThresholdReached, award))
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "threshold_award")
+	}
+	return awarded, nil
+}
+
+func (r *GormThresholdRepository) ListProgress(ctx context.Context, userID uint, merchantID *uint, now time.Time) ([]threshold_ports.Progress, error) {
+	db := configs.DB(ctx, r.DB)
+	var campaigns []models.Campaign
+	query := db.Where("kind = ? AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)", models.CampaignSpendThreshold, now, now).
+		Where("merchant_id IN (?)", db.Model(&models.Membership{}).Select("merchant_id").Where("user_id = ?", userID))
+	if merchantID != nil {
+		query = query.Where("merchant_id = ?", *merchantID)
+	}
+	err := query.Order("merchant_id, id").Find(&campaigns).Error
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]threshold_ports.Progress, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		spent, err := spentDuring(db, &campaign, userID)
+		if err != nil {
+			return nil, err
+		}
+		var awards []models.ThresholdAward
+		err = db.Where("campaign_id = ? AND user_id = ? AND revoked_at IS NULL", campaign.ID, userID).
+			Limit(1).Find(&awards).Error
+		if err != nil {
+			return nil, err
+		}
+		item := threshold_ports.Progress{Campaign: campaign, Spent: spent}
+		if len(awards) > 0 {
+			item.Award = &awards[0]
+		}
+		progress = append(progress, item)
+	}
+	return progress, nil
+}
+
+// RevokeAwards revokes the awards whose bonus was among the rewards revoked by
+// the reversal of their transaction, within the caller's database
+// transaction, so the user can reach the threshold again.
+func RevokeAwards(tx *gorm.DB, revoked []models.Reward, now time.Time) error {
+	for _, reward := range revoked {
+		if reward.ThresholdAwardID == nil {
+			continue
+		}
+		var award models.ThresholdAward
+		err := tx.First(&award, *reward.ThresholdAwardID).Error
+		if err != nil {
+			return err
+		}
+		award.RevokedAt = &now
+		err = tx.Model(&award).Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		err = events.Enqueue(tx, events.ForThresholdAward(events.ThresholdRevoked, &award))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// spentDuring sums the user's transactions at the merchant during the
+// campaign that have not been reversed, restricted to the campaign's branch
+// and minimum amount when it has them.
+func spentDuring(db *gorm.DB, campaign *models.Campaign, userID uint) (float64, error) {
+	var spent float64
+	query := db.Model(&models.Transaction{}).
+		Where("user_id = ? AND merchant_id = ? AND reversed_at IS NULL AND date >= ?", userID, campaign.MerchantID, campaign.StartDate)
+	if campaign.EndDate != nil {
+		query = query.Where("date <= ?", *campaign.EndDate)
+	}
+	if campaign.BranchID != nil {
+		query = query.Where("branch_id = ?", *campaign.BranchID)
+	}
+	if campaign.MinAmount != nil {
+		query = query.Where("amount >= ?", *campaign.MinAmount)
+	}
+	err := query.Select("COALESCE(SUM(amount), 0)").Scan(&spent).Error
+	return spent, err
+}
+
+// grantBonus grants Value in the campaign's reward type, with the merchant's
+// validity and vesting counted from the transaction that reached the
+// threshold, so reversing it while the bonus is pending revokes the bonus.
+func grantBonus(tx *gorm.DB, award *models.ThresholdAward, campaign *models.Campaign, transaction threshold_ports.QualifyingTransaction) (*models.Reward, error) {
+	var merchant models.Merchant
+	err := tx.First(&merchant, award.MerchantID).Error
+	if err != nil {
+		return nil, err
+	}
+	reward := &models.Reward{
+		UserID:           award.UserID,
+		MerchantID:       award.MerchantID,
+		Type:             campaign.Type,
+		Amount:           campaign.Value,
+		CampaignID:       &campaign.ID,
+		TransactionID:    &transaction.ID,
+		ThresholdAwardID: &award.ID,
+	}
+	if merchant.RewardValidityDays != nil {
+		expiry := transaction.Date.AddDate(0, 0, *merchant.RewardValidityDays)
+		reward.ExpiryDate = &expiry
+	}
+	if merchant.RewardVestingDays != nil {
+		vesting := transaction.Date.AddDate(0, 0, *merchant.RewardVestingDays)
+		reward.VestsAt = &vesting
+	}
+	return reward, reward_repository.GrantReward(tx, reward, events.RewardGranted)
+}
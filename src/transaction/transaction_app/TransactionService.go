@@ -1,26 +1,53 @@
 package transaction_app
 
 import (
+	"context"
+	"errors"
+	"io"
+	"loyalty-campaigns/src/branch/branch_domain/branch_ports"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/exports"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/common/utils"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_ports"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_structs/transaction_requests"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_structs/transaction_responses"
+	"loyalty-campaigns/src/user/user_domain/user_ports"
+	"strconv"
 	"sync"
 	"time"
 )
 
+var (
+	// ErrBranchMerchantMismatch rejects a transaction whose branch belongs to a
+	// merchant other than the one given.
+	ErrBranchMerchantMismatch = domain_errors.Validation("branch_merchant_mismatch", "the branch does not belong to the merchant")
+	// ErrDuplicateTransaction rejects a transaction whose external reference was
+	// already processed for the merchant.
+	ErrDuplicateTransaction = domain_errors.Conflict("transaction_already_exists", "a transaction with this external reference was already processed")
+)
+
 type ITransactionService interface {
-	CreateTransaction(req transaction_requests.CreateTransactionRequest) (*transaction_responses.TransactionResponse, error)
-	GetTransaction(id uint) (*transaction_responses.TransactionResponse, error)
-	ListTransactionsByUser(userID uint) ([]transaction_responses.TransactionResponse, error)
-	ListTransactionsByBranch(branchID uint) ([]transaction_responses.TransactionResponse, error)
-	GetTransactionsByDateRange(startDate, endDate time.Time) ([]transaction_responses.TransactionResponse, error)
-	GetTotalAmountByUserAndDateRange(userID uint, startDate, endDate time.Time) (float64, error)
+	CreateTransaction(ctx context.Context, req transaction_requests.CreateTransactionRequest) (*transaction_responses.TransactionResponse, error)
+	GetTransaction(ctx context.Context, id uint) (*transaction_responses.TransactionResponse, error)
+	// ReverseTransaction records the return of the sale: the rewards it earned
+	// that are still pending are revoked, the vested ones are kept, and its
+	// stamps are revoked. A spend-threshold award whose bonus is revoked is
+	// revoked too.
+	ReverseTransaction(ctx context.Context, id uint) (*transaction_responses.TransactionResponse, error)
+	ListTransactions(ctx context.Context, req transaction_requests.ListTransactionsRequest) (*pagination.Page[transaction_responses.TransactionResponse], error)
+	GetTotalAmountByUserAndDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) (float64, error)
+	// ExportTransactions writes the matching transactions to w as CSV or JSON
+	// lines, streaming them from the database.
+	ExportTransactions(ctx context.Context, req transaction_requests.ExportTransactionsRequest, w io.Writer) error
 }
 
 type transactionService struct {
 	transactionRepo transaction_ports.ITransactionRepository
+	branchRepo      branch_ports.IBranchRepository
+	userRepo        user_ports.IUserRepository
 	logger          utils.ILogger
 }
 
@@ -29,25 +56,55 @@ var (
 	transactionServiceOnce     sync.Once
 )
 
-func NewTransactionService(transactionRepo transaction_ports.ITransactionRepository) ITransactionService {
+func NewTransactionService(transactionRepo transaction_ports.ITransactionRepository, branchRepo branch_ports.IBranchRepository, userRepo user_ports.IUserRepository) ITransactionService {
 	transactionServiceOnce.Do(func() {
 		transactionServiceInstance = &transactionService{
 			transactionRepo: transactionRepo,
+			branchRepo:      branchRepo,
+			userRepo:        userRepo,
 			logger:          utils.NewLogger(),
 		}
 	})
 	return transactionServiceInstance
 }
 
-func (s *transactionService) CreateTransaction(req transaction_requests.CreateTransactionRequest) (*transaction_responses.TransactionResponse, error) {
+// CreateTransaction records a purchase of an existing user at a branch. The
+// merchant is taken from the branch; a different merchant in the request is
+// rejected with ErrBranchMerchantMismatch.
+func (s *transactionService) CreateTransaction(ctx context.Context, req transaction_requests.CreateTransactionRequest) (*transaction_responses.TransactionResponse, error) {
+	branch, err := s.branchRepo.GetByID(ctx, req.BranchID)
+	if err != nil {
+		s.logger.Error("Error al obtener sucursal de la transacción", err)
+		return nil, err
+	}
+	if req.MerchantID != 0 && req.MerchantID != branch.MerchantID {
+		return nil, ErrBranchMerchantMismatch
+	}
+
+	err = security.Authorize(ctx, security.ActionOperate, branch.MerchantID, &branch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		s.logger.Error("Error al obtener usuario de la transacción", err)
+		return nil, err
+	}
+
 	transaction := &models.Transaction{
-		UserID:   req.UserID,
-		BranchID: req.BranchID,
-		Amount:   req.Amount,
-		Date:     req.Date,
+		UserID:      req.UserID,
+		BranchID:    branch.ID,
+		MerchantID:  branch.MerchantID,
+		ExternalRef: req.ExternalRef,
+		Amount:      req.Amount,
+		Date:        req.Date,
 	}
 
-	err := s.transactionRepo.Create(transaction)
+	err = s.transactionRepo.Create(ctx, transaction)
+	if errors.Is(err, domain_errors.ErrConflict) {
+		return nil, ErrDuplicateTransaction.Wrap(err)
+	}
 	if err != nil {
 		s.logger.Error("Error al crear transacción", err)
 		return nil, err
@@ -56,48 +113,118 @@ func (s *transactionService) CreateTransaction(req transaction_requests.CreateTr
 	return mapTransactionToResponse(transaction), nil
 }
 
-func (s *transactionService) GetTransaction(id uint) (*transaction_responses.TransactionResponse, error) {
-	transaction, err := s.transactionRepo.GetByID(id)
+func (s *transactionService) GetTransaction(ctx context.Context, id uint) (*transaction_responses.TransactionResponse, error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Error al obtener transacción", err)
 		return nil, err
 	}
 
+	err = security.Authorize(ctx, security.ActionRead, transaction.Branch.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	return mapTransactionToResponse(transaction), nil
 }
 
-func (s *transactionService) ListTransactionsByUser(userID uint) ([]transaction_responses.TransactionResponse, error) {
-	transactions, err := s.transactionRepo.GetByUserID(userID)
+func (s *transactionService) ReverseTransaction(ctx context.Context, id uint) (*transaction_responses.TransactionResponse, error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Error al listar transacciones del usuario", err)
+		s.logger.Error("Error al obtener transacción", err)
 		return nil, err
 	}
 
-	return mapTransactionsToResponses(transactions), nil
-}
+	err = security.Authorize(ctx, security.ActionOperate, transaction.Branch.MerchantID, &transaction.BranchID)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *transactionService) ListTransactionsByBranch(branchID uint) ([]transaction_responses.TransactionResponse, error) {
-	transactions, err := s.transactionRepo.GetByBranchID(branchID)
+	reversed, err := s.transactionRepo.Reverse(ctx, id, time.Now())
 	if err != nil {
-		s.logger.Error("Error al listar transacciones de la sucursal", err)
+		s.logger.Error("Error al revertir transacción", err)
 		return nil, err
 	}
 
-	return mapTransactionsToResponses(transactions), nil
+	return mapTransactionToResponse(reversed), nil
 }
 
-func (s *transactionService) GetTransactionsByDateRange(startDate, endDate time.Time) ([]transaction_responses.TransactionResponse, error) {
-	transactions, err := s.transactionRepo.GetByDateRange(startDate, endDate)
+func (s *transactionService) ListTransactions(ctx context.Context, req transaction_requests.ListTransactionsRequest) (*pagination.Page[transaction_responses.TransactionResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
 	if err != nil {
-		s.logger.Error("Error al obtener transacciones por rango de fechas", err)
 		return nil, err
 	}
 
-	return mapTransactionsToResponses(transactions), nil
+	page, err := s.transactionRepo.List(ctx, transaction_ports.TransactionFilter{
+		MerchantID: merchantID,
+		BranchID:   req.BranchID,
+		UserID:     req.UserID,
+		From:       req.From,
+		To:         req.To,
+	}, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar transacciones", err)
+		return nil, err
+	}
+
+	return pagination.Map(page, func(transaction *models.Transaction) transaction_responses.TransactionResponse {
+		return *mapTransactionToResponse(transaction)
+	}), nil
 }
 
-func (s *transactionService) GetTotalAmountByUserAndDateRange(userID uint, startDate, endDate time.Time) (float64, error) {
-	totalAmount, err := s.transactionRepo.GetTotalAmountByUserAndDateRange(userID, startDate, endDate)
+func (s *transactionService) ExportTransactions(ctx context.Context, req transaction_requests.ExportTransactionsRequest, w io.Writer) error {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	encoder := exports.NewEncoder(w, req.Format, transactionExportColumns, transactionExportRecord)
+	err = s.transactionRepo.Stream(ctx, transaction_ports.TransactionFilter{
+		MerchantID: merchantID,
+		BranchID:   req.BranchID,
+		From:       req.From,
+		To:         req.To,
+	}, func(transactions []models.Transaction) error {
+		for i := range transactions {
+			err := encoder.Write(mapTransactionToResponse(&transactions[i]))
+			if err != nil {
+				return err
+			}
+		}
+		return encoder.Flush()
+	})
+	if err != nil {
+		s.logger.Error("Error al exportar transacciones: %v", err)
+		return err
+	}
+	return encoder.Flush()
+}
+
+var transactionExportColumns = []string{"id", "user_id", "branch_id", "merchant_id", "external_ref", "amount", "date"}
+
+func transactionExportRecord(transaction *transaction_responses.TransactionResponse) []string {
+	var externalRef string
+	if transaction.ExternalRef != nil {
+		externalRef = *transaction.ExternalRef
+	}
+	return []string{
+		strconv.FormatUint(uint64(transaction.ID), 10),
+		strconv.FormatUint(uint64(transaction.UserID), 10),
+		strconv.FormatUint(uint64(transaction.BranchID), 10),
+		strconv.FormatUint(uint64(transaction.MerchantID), 10),
+		externalRef,
+		strconv.FormatFloat(transaction.Amount, 'f', -1, 64),
+		transaction.Date.Format(time.RFC3339),
+	}
+}
+
+func (s *transactionService) GetTotalAmountByUserAndDateRange(ctx context.Context, userID uint, startDate, endDate time.Time) (float64, error) {
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	totalAmount, err := s.transactionRepo.GetTotalAmountByUserAndDateRange(ctx, userID, startDate, endDate, merchantID)
 	if err != nil {
 		s.logger.Error("Error al obtener monto total de transacciones del usuario por rango de fechas", err)
 		return 0, err
@@ -108,18 +235,13 @@ func (s *transactionService) GetTotalAmountByUserAndDateRange(userID uint, start
 
 func mapTransactionToResponse(transaction *models.Transaction) *transaction_responses.TransactionResponse {
 	return &transaction_responses.TransactionResponse{
-		ID:       transaction.ID,
-		UserID:   transaction.UserID,
-		BranchID: transaction.BranchID,
-		Amount:   transaction.Amount,
-		Date:     transaction.Date,
-	}
-}
-
-func mapTransactionsToResponses(transactions []models.Transaction) []transaction_responses.TransactionResponse {
-	responses := make([]transaction_responses.TransactionResponse, len(transactions))
-	for i, transaction := range transactions {
-		responses[i] = *mapTransactionToResponse(&transaction)
+		ID:          transaction.ID,
+		UserID:      transaction.UserID,
+		BranchID:    transaction.BranchID,
+		MerchantID:  transaction.MerchantID,
+		ExternalRef: transaction.ExternalRef,
+		Amount:      transaction.Amount,
+		Date:        transaction.Date,
+		ReversedAt:  transaction.ReversedAt,
 	}
-	return responses
 }
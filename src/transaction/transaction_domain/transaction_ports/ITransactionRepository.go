@@ -1,19 +1,29 @@
 package transaction_ports
 
 import (
+	"context"
+	"loyalty-campaigns/src/common/domain_errors"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 	"time"
 )
 
+// ErrTransactionReversed rejects a second reversal of a transaction.
+var ErrTransactionReversed = domain_errors.Conflict("transaction_already_reversed", "the transaction was already reversed")
+
 type ITransactionRepository interface {
-	Create(transaction *models.Transaction) error
-	GetByID(id uint) (*models.Transaction, error)
-	Update(transaction *models.Transaction) error
-	Delete(id uint) error
-	List() ([]models.Transaction, error)
-	GetByUserID(userID uint) ([]models.Transaction, error)
-	GetByBranchID(branchID uint) ([]models.Transaction, error)
-	GetByDateRange(startDate, endDate time.Time) ([]models.Transaction, error)
-	GetByUserAndDateRange(userID uint, startDate, endDate time.Time) ([]models.Transaction, error)
-	GetTotalAmountByUserAndDateRange(userID uint, startDate, endDate time.Time) (float64, error)
+	Create(ctx context.Context, transaction *models.Transaction) error
+	GetByID(ctx context.Context, id uint) (*models.Transaction, error)
+	Update(ctx context.Context, transaction *models.Transaction) error
+	Delete(ctx context.Context, id uint) error
+	// Reverse marks the transaction as reversed and revokes its stamps, the
+	// rewards it earned that are still pending and the spend-threshold awards
+	// of those rewards, or fails with ErrTransactionReversed.
+	Reverse(ctx context.Context, id uint, now time.Time) (*models.Transaction, error)
+	List(ctx context.Context, filter TransactionFilter, page pagination.Request) (*pagination.Page[models.Transaction], error)
+	// Stream passes the matching transactions to fn in batches read from a
+	// server-side cursor, for exports too large to load at once.
+	Stream(ctx context.Context, filter TransactionFilter, fn func([]models.Transaction) error) error
+	GetByUserAndDateRange(ctx context.Context, userID uint, startDate, endDate time.Time, merchantID *uint) ([]models.Transaction, error)
+	GetTotalAmountByUserAndDateRange(ctx context.Context, userID uint, startDate, endDate time.Time, merchantID *uint) (float64, error)
 }
@@ -0,0 +1,11 @@
+package transaction_ports
+
+import "time"
+
+type TransactionFilter struct {
+	MerchantID *uint
+	BranchID   *uint
+	UserID     *uint
+	From       *time.Time
+	To         *time.Time
+}
@@ -3,8 +3,12 @@ package transaction_requests
 import "time"
 
 type CreateTransactionRequest struct {
-	UserID   uint      `json:"user_id" binding:"required"`
-	BranchID uint      `json:"branch_id" binding:"required"`
-	Amount   float64   `json:"amount" binding:"required"`
-	Date     time.Time `json:"date" binding:"required"`
+	UserID   uint `json:"user_id" binding:"required"`
+	BranchID uint `json:"branch_id" binding:"required"`
+	// MerchantID is optional: it defaults to the merchant of the branch and
+	// must match it when given.
+	MerchantID  uint      `json:"merchant_id"`
+	ExternalRef *string   `json:"external_ref" binding:"omitempty,max=100"`
+	Amount      float64   `json:"amount" binding:"required"`
+	Date        time.Time `json:"date" binding:"required"`
 }
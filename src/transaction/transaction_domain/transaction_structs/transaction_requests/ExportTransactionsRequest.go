@@ -0,0 +1,11 @@
+package transaction_requests
+
+import "time"
+
+type ExportTransactionsRequest struct {
+	MerchantID *uint      `form:"merchantId"`
+	BranchID   *uint      `form:"branchId"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format     string     `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
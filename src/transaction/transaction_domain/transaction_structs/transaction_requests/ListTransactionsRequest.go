@@ -0,0 +1,16 @@
+package transaction_requests
+
+import (
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+// ListTransactionsRequest accepts sort by id, date, amount or createdAt.
+type ListTransactionsRequest struct {
+	pagination.Request
+	MerchantID *uint      `form:"merchantId"`
+	BranchID   *uint      `form:"branchId"`
+	UserID     *uint      `form:"userId"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -3,9 +3,12 @@ package transaction_responses
 import "time"
 
 type TransactionResponse struct {
-	ID       uint      `json:"id"`
-	UserID   uint      `json:"user_id"`
-	BranchID uint      `json:"branch_id"`
-	Amount   float64   `json:"amount"`
-	Date     time.Time `json:"date"`
+	ID          uint       `json:"id"`
+	UserID      uint       `json:"user_id"`
+	BranchID    uint       `json:"branch_id"`
+	MerchantID  uint       `json:"merchant_id"`
+	ExternalRef *string    `json:"external_ref,omitempty"`
+	Amount      float64    `json:"amount"`
+	Date        time.Time  `json:"date"`
+	ReversedAt  *time.Time `json:"reversed_at,omitempty"`
 }
@@ -1,10 +1,15 @@
 package transaction_controller
 
 import (
+	"loyalty-campaigns/src/branch/branch_infra/branch_repository"
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/exports"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
 	"loyalty-campaigns/src/transaction/transaction_app"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_structs/transaction_requests"
 	"loyalty-campaigns/src/transaction/transaction_infra/transaction_repository"
+	"loyalty-campaigns/src/user/user_infra/user_repository"
 	"net/http"
 	"strconv"
 	"sync"
@@ -22,22 +27,27 @@ var (
 	transactionControllerOnce     sync.Once
 )
 
-func NewTransactionController(router *gin.Engine) *TransactionController {
+func NewTransactionController(router *gin.RouterGroup) *TransactionController {
 	transactionControllerOnce.Do(func() {
 		transactionControllerInstance = &TransactionController{}
 		db := configs.NewDBConnection().GetDB()
 		transactionRepository := transaction_repository.NewGormTransactionRepository(db)
-		transactionControllerInstance.transactionService = transaction_app.NewTransactionService(transactionRepository)
+		branchRepository := branch_repository.NewGormBranchRepository(db)
+		userRepository := user_repository.NewGormUserRepository(db)
+		transactionControllerInstance.transactionService = transaction_app.NewTransactionService(transactionRepository, branchRepository, userRepository)
 		transactionControllerInstance.setupTransactionRoutes(router)
 	})
 	return transactionControllerInstance
 }
 
-func (c *TransactionController) setupTransactionRoutes(router *gin.Engine) {
-	transactionGroup := router.Group("/api/transactions")
+func (c *TransactionController) setupTransactionRoutes(router *gin.RouterGroup) {
+	transactionGroup := router.Group("/transactions")
 	{
 		transactionGroup.POST("", c.CreateTransaction)
+		transactionGroup.GET("", c.ListTransactions)
+		transactionGroup.GET("/export", c.ExportTransactions)
 		transactionGroup.GET("/:id", c.GetTransaction)
+		transactionGroup.POST("/:id/reverse", c.ReverseTransaction)
 		transactionGroup.GET("/user/:userID", c.ListTransactionsByUser)
 		transactionGroup.GET("/user/:userID/total-amount", c.GetTotalAmountByUserAndDateRange)
 	}
@@ -46,25 +56,28 @@ func (c *TransactionController) setupTransactionRoutes(router *gin.Engine) {
 // CreateTransaction godoc
 //
 //	@Summary		Create a new transaction
-//	@Description	Create a new transaction in the system
+//	@Description	Create a new transaction for an existing user. The merchant defaults to the merchant of the branch and must match it when given
 //	@Tags			transactions
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		transaction_requests.CreateTransactionRequest	true	"Transaction creation request"
 //	@Success		201		{object}	transaction_responses.TransactionResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/transactions [post]
 func (c *TransactionController) CreateTransaction(ctx *gin.Context) {
 	var req transaction_requests.CreateTransactionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.transactionService.CreateTransaction(req)
+	response, err := c.transactionService.CreateTransaction(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -78,21 +91,52 @@ func (c *TransactionController) CreateTransaction(ctx *gin.Context) {
 //	@Tags			transactions
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"Transaction ID"
 //	@Success		200	{object}	transaction_responses.TransactionResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		404	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
 //	@Router			/api/transactions/{id} [get]
 func (c *TransactionController) GetTransaction(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.transactionService.GetTransaction(uint(id))
+	response, err := c.transactionService.GetTransaction(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ReverseTransaction godoc
+//
+//	@Summary		Reverse a transaction
+//	@Description	Record the return of a sale. The rewards it earned that are still pending are revoked; those already vested are kept. Its stamps come off their cards, or off the user's open card of the campaign when their card was already completed. A spend-threshold award whose pending bonus is revoked is revoked too, and the user can reach the threshold again.
+//	@Tags			transactions
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Transaction ID"
+//	@Success		200	{object}	transaction_responses.TransactionResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Failure		409	{object}	domain_errors.Problem
+//	@Router			/api/transactions/{id}/reverse [post]
+func (c *TransactionController) ReverseTransaction(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.transactionService.ReverseTransaction(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
@@ -102,29 +146,92 @@ func (c *TransactionController) GetTransaction(ctx *gin.Context) {
 // ListTransactionsByUser godoc
 //
 //	@Summary		List transactions for a specific user
-//	@Description	Get a list of all transactions for a given user
+//	@Description	Get a page of the transactions of a given user
 //	@Tags			transactions
 //	@Accept			json
 //	@Produce		json
-//	@Param			userID	path		int	true	"User ID"
-//	@Success		200		{array}		transaction_responses.TransactionResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Security		ApiKeyAuth
+//	@Param			userID	path		int											true	"User ID"
+//	@Param			request	query		transaction_requests.ListTransactionsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[transaction_responses.TransactionResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/transactions/user/{userID} [get]
 func (c *TransactionController) ListTransactionsByUser(ctx *gin.Context) {
 	userID, err := strconv.ParseUint(ctx.Param("userID"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		ctx.Error(domain_errors.Validation("invalid_user_id", "Invalid user ID"))
+		return
+	}
+
+	var req transaction_requests.ListTransactionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
+	id := uint(userID)
+	req.UserID = &id
+
+	c.listTransactions(ctx, req)
+}
+
+// ListTransactions godoc
+//
+//	@Summary		List transactions
+//	@Description	Get a page of transactions filtered by merchant, branch, user and date range. Sort by id, date, amount or createdAt, prefixed with "-" for descending order; the default is -date.
+//	@Tags			transactions
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			request	query		transaction_requests.ListTransactionsRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[transaction_responses.TransactionResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/transactions [get]
+func (c *TransactionController) ListTransactions(ctx *gin.Context) {
+	var req transaction_requests.ListTransactionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	c.listTransactions(ctx, req)
+}
+
+// ExportTransactions godoc
+//
+//	@Summary		Export transactions
+//	@Description	Download the transactions filtered by merchant, branch and date range as CSV (the default) or JSON lines, in id order. The file is streamed as it is read, so exports of any size are supported.
+//	@Tags			transactions
+//	@Produce		text/csv,application/x-ndjson
+//	@Security		ApiKeyAuth
+//	@Param			request	query		transaction_requests.ExportTransactionsRequest	false	"Filters and format"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/transactions/export [get]
+func (c *TransactionController) ExportTransactions(ctx *gin.Context) {
+	var req transaction_requests.ExportTransactionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	err := c.transactionService.ExportTransactions(ctx.Request.Context(), req, exports.Attachment(ctx, "transactions", req.Format))
+	if err != nil {
+		ctx.Error(err)
+	}
+}
 
-	responses, err := c.transactionService.ListTransactionsByUser(uint(userID))
+func (c *TransactionController) listTransactions(ctx *gin.Context, req transaction_requests.ListTransactionsRequest) {
+	page, err := c.transactionService.ListTransactions(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responses)
+	ctx.JSON(http.StatusOK, page)
 }
 
 // GetTotalAmountByUserAndDateRange godoc
@@ -134,35 +241,36 @@ func (c *TransactionController) ListTransactionsByUser(ctx *gin.Context) {
 //	@Tags			transactions
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			userID		path		int		true	"User ID"
 //	@Param			startDate	query		string	true	"Start date (RFC3339 format)"	Format(date-time)
 //	@Param			endDate		query		string	true	"End date (RFC3339 format)"		Format(date-time)
 //	@Success		200			{object}	map[string]float64
-//	@Failure		400			{object}	map[string]string
-//	@Failure		500			{object}	map[string]string
+//	@Failure		400			{object}	domain_errors.Problem
+//	@Failure		500			{object}	domain_errors.Problem
 //	@Router			/api/transactions/user/{userID}/total [get]
 func (c *TransactionController) GetTotalAmountByUserAndDateRange(ctx *gin.Context) {
 	userID, err := strconv.ParseUint(ctx.Param("userID"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		ctx.Error(domain_errors.Validation("invalid_user_id", "Invalid user ID"))
 		return
 	}
 
 	startDate, err := time.Parse(time.RFC3339, ctx.Query("startDate"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date"})
+		ctx.Error(domain_errors.Validation("invalid_start_date", "Invalid start date"))
 		return
 	}
 
 	endDate, err := time.Parse(time.RFC3339, ctx.Query("endDate"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date"})
+		ctx.Error(domain_errors.Validation("invalid_end_date", "Invalid end date"))
 		return
 	}
 
-	totalAmount, err := c.transactionService.GetTotalAmountByUserAndDateRange(uint(userID), startDate, endDate)
+	totalAmount, err := c.transactionService.GetTotalAmountByUserAndDateRange(ctx.Request.Context(), uint(userID), startDate, endDate)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -1,11 +1,21 @@
 package transaction_repository
 
 import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/exports"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/reward/reward_infra/reward_repository"
+	"loyalty-campaigns/src/stamp/stamp_infra/stamp_repository"
+	"loyalty-campaigns/src/threshold/threshold_infra/threshold_repository"
 	"loyalty-campaigns/src/transaction/transaction_domain/transaction_ports"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormTransactionRepository struct {
@@ -16,61 +26,123 @@ func NewGormTransactionRepository(db *gorm.DB) transaction_ports.ITransactionRep
 	return &GormTransactionRepository{DB: db}
 }
 
-func (r *GormTransactionRepository) Create(transaction *models.Transaction) error {
-	return r.DB.Create(transaction).Error
+// Create records the transaction together with its transaction.processed event.
+func (r *GormTransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(transaction).Error
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForTransaction(events.TransactionProcessed, transaction))
+	})
+	return domain_errors.Translate(err, "transaction")
 }
 
-func (r *GormTransactionRepository) GetByID(id uint) (*models.Transaction, error) {
+// GetByID loads the transaction together with its branch, which carries the owning merchant.
+func (r *GormTransactionRepository) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
 	var transaction models.Transaction
-	err := r.DB.First(&transaction, id).Error
+	err := configs.DB(ctx, r.DB).Joins("Branch").First(&transaction, "transactions.id = ?", id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "transaction")
 	}
 	return &transaction, nil
 }
 
-func (r *GormTransactionRepository) Update(transaction *models.Transaction) error {
-	return r.DB.Save(transaction).Error
+func (r *GormTransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Save(transaction).Error, "transaction")
 }
 
-func (r *GormTransactionRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Transaction{}, id).Error
+func (r *GormTransactionRepository) Delete(ctx context.Context, id uint) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Delete(&models.Transaction{}, id).Error, "transaction")
 }
 
-func (r *GormTransactionRepository) List() ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	err := r.DB.Find(&transactions).Error
-	return transactions, err
+// Reverse locks the transaction before its stamp cards and its rewards, as
+// the vesting job locks the rewards on their own and adding a stamp locks the
+// card before the reward of its completion.
+func (r *GormTransactionRepository) Reverse(ctx context.Context, id uint, now time.Time) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id).Error
+		if err != nil {
+			return err
+		}
+		if transaction.ReversedAt != nil {
+			return transaction_ports.ErrTransactionReversed
+		}
+
+		transaction.ReversedAt = &now
+		err = tx.Model(&transaction).Update("reversed_at", now).Error
+		if err != nil {
+			return err
+		}
+		err = stamp_repository.RevokeStamps(tx, transaction.ID, now)
+		if err != nil {
+			return err
+		}
+		revoked, err := reward_repository.RevokePendingRewards(tx, transaction.ID)
+		if err != nil {
+			return err
+		}
+		err = threshold_repository.RevokeAwards(tx, revoked, now)
+		if err != nil {
+			return err
+		}
+		return events.Enqueue(tx, events.ForTransaction(events.TransactionReversed, &transaction))
+	})
+	if err != nil {
+		return nil, domain_errors.Translate(err, "transaction")
+	}
+	return &transaction, nil
 }
 
-func (r *GormTransactionRepository) GetByUserID(userID uint) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	err := r.DB.Where("user_id = ?", userID).Find(&transactions).Error
-	return transactions, err
+var transactionSorting = pagination.Sorting[models.Transaction]{
+	IDColumn: "id",
+	ID:       func(transaction *models.Transaction) uint { return transaction.ID },
+	Fields: map[string]pagination.Key[models.Transaction]{
+		"id":        {Column: "id", Value: func(transaction *models.Transaction) any { return transaction.ID }},
+		"date":      {Column: "date", Value: func(transaction *models.Transaction) any { return transaction.Date }},
+		"amount":    {Column: "amount", Value: func(transaction *models.Transaction) any { return transaction.Amount }},
+		"createdAt": {Column: "created_at", Value: func(transaction *models.Transaction) any { return transaction.CreatedAt }},
+	},
+	Default: "-date",
 }
 
-func (r *GormTransactionRepository) GetByBranchID(branchID uint) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	err := r.DB.Where("branch_id = ?", branchID).Find(&transactions).Error
-	return transactions, err
+func (r *GormTransactionRepository) List(ctx context.Context, filter transaction_ports.TransactionFilter, page pagination.Request) (*pagination.Page[models.Transaction], error) {
+	return pagination.Find(r.filtered(ctx, filter), page, transactionSorting)
 }
 
-func (r *GormTransactionRepository) GetByDateRange(startDate, endDate time.Time) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	err := r.DB.Where("date BETWEEN ? AND ?", startDate, endDate).Find(&transactions).Error
-	return transactions, err
+// Stream passes the matching transactions to fn in batches, in id order.
+func (r *GormTransactionRepository) Stream(ctx context.Context, filter transaction_ports.TransactionFilter, fn func([]models.Transaction) error) error {
+	return exports.Stream(ctx, r.DB, r.filtered(ctx, filter).Order("id"), fn)
 }
 
-func (r *GormTransactionRepository) GetByUserAndDateRange(userID uint, startDate, endDate time.Time) ([]models.Transaction, error) {
+func (r *GormTransactionRepository) filtered(ctx context.Context, filter transaction_ports.TransactionFilter) *gorm.DB {
+	query := r.scoped(ctx, filter.MerchantID)
+	if filter.BranchID != nil {
+		query = query.Where("branch_id = ?", *filter.BranchID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.From != nil {
+		query = query.Where("date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("date <= ?", *filter.To)
+	}
+	return query
+}
+
+func (r *GormTransactionRepository) GetByUserAndDateRange(ctx context.Context, userID uint, startDate, endDate time.Time, merchantID *uint) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	err := r.DB.Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).Find(&transactions).Error
+	err := r.scoped(ctx, merchantID).Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *GormTransactionRepository) GetTotalAmountByUserAndDateRange(userID uint, startDate, endDate time.Time) (float64, error) {
+func (r *GormTransactionRepository) GetTotalAmountByUserAndDateRange(ctx context.Context, userID uint, startDate, endDate time.Time, merchantID *uint) (float64, error) {
 	var totalAmount float64
-	err := r.DB.Model(&models.Transaction{}).
-		Select("SUM(amount) as total_amount").
+	err := r.scoped(ctx, merchantID).Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0) as total_amount").
 		Where("user_id = ? AND date BETWEEN ? AND ?", userID, startDate, endDate).
 		Scan(&totalAmount).Error
 	if err != nil {
@@ -78,3 +150,12 @@ func (r *GormTransactionRepository) GetTotalAmountByUserAndDateRange(userID uint
 	}
 	return totalAmount, nil
 }
+
+// scoped restricts the transactions to the branches of the merchant, when one is given.
+func (r *GormTransactionRepository) scoped(ctx context.Context, merchantID *uint) *gorm.DB {
+	query := configs.DB(ctx, r.DB)
+	if merchantID != nil {
+		query = query.Where("branch_id IN (SELECT id FROM branches WHERE merchant_id = ?)", *merchantID)
+	}
+	return query
+}
@@ -0,0 +1,95 @@
+package transaction_repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/transaction/transaction_domain/transaction_ports"
+	"loyalty-campaigns/src/transaction/transaction_infra/transaction_repository"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("GormTransactionRepository", func() {
+	var (
+		sqlMock    sqlmock.Sqlmock
+		repository transaction_ports.ITransactionRepository
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		repository = transaction_repository.NewGormTransactionRepository(db)
+		now = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("Stream", func() {
+		transactionColumns := []string{"id", "user_id", "branch_id", "merchant_id", "amount", "date"}
+
+		// expectCursor expects the transactions to be read through a cursor
+		// over the query, in the batches given.
+		expectCursor := func(query string, args []driver.Value, batches ...*sqlmock.Rows) {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`^DECLARE export_cursor NO SCROLL CURSOR FOR ` + query + `$`).
+				WithArgs(args...).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			for _, rows := range batches {
+				sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).WillReturnRows(rows)
+			}
+			sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).WillReturnRows(sqlmock.NewRows(transactionColumns))
+			sqlMock.ExpectExec(`^CLOSE export_cursor$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectCommit()
+		}
+
+		It("should read the transactions of the branches of the merchant that match the filters", func() {
+			merchantID, branchID := uint(4), uint(2)
+			from, to := now.AddDate(0, -1, 0), now
+			expectCursor(
+				`SELECT \* FROM "transactions" WHERE branch_id IN \(SELECT id FROM branches WHERE merchant_id = \$1\) AND branch_id = \$2 AND date >= \$3 AND date <= \$4 AND "transactions"\."deleted_at" IS NULL ORDER BY id`,
+				[]driver.Value{merchantID, branchID, from, to},
+				sqlmock.NewRows(transactionColumns).AddRow(1, 9, branchID, merchantID, 100.0, now).AddRow(2, 9, branchID, merchantID, 50.0, now),
+			)
+
+			var transactions []models.Transaction
+			err := repository.Stream(context.Background(), transaction_ports.TransactionFilter{
+				MerchantID: &merchantID,
+				BranchID:   &branchID,
+				From:       &from,
+				To:         &to,
+			}, func(batch []models.Transaction) error {
+				transactions = append(transactions, batch...)
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(transactions).To(HaveLen(2))
+			Expect(transactions[1].Amount).To(Equal(50.0))
+		})
+
+		It("should read the transactions of every merchant without a merchant filter", func() {
+			expectCursor(`SELECT \* FROM "transactions" WHERE "transactions"\."deleted_at" IS NULL ORDER BY id`, nil)
+
+			err := repository.Stream(context.Background(), transaction_ports.TransactionFilter{}, func(batch []models.Transaction) error {
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+		})
+	})
+})
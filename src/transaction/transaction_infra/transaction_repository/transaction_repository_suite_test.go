@@ -0,0 +1,13 @@
+package transaction_repository_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTransactionRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "TransactionRepository Suite")
+}
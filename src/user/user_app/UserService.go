@@ -1,21 +1,31 @@
 package user_app
 
 import (
+	"context"
+	"io"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/exports"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
 	"loyalty-campaigns/src/user/user_domain/user_ports"
 	"loyalty-campaigns/src/user/user_domain/user_structs/user_requests"
 	"loyalty-campaigns/src/user/user_domain/user_structs/user_responses"
+	"strconv"
 	"sync"
+	"time"
 )
 
 type IUserService interface {
-	CreateUser(req user_requests.CreateUserRequest) (*user_responses.UserResponse, error)
-	GetUser(id uint) (*user_responses.UserResponse, error)
-	UpdateUser(id uint, req user_requests.UpdateUserRequest) (*user_responses.UserResponse, error)
-	DeleteUser(id uint) error
-	ListUsers() ([]user_responses.UserResponse, error)
-	GetUserWithTransactions(id uint) (*user_responses.UserWithTransactionsResponse, error)
-	GetUserWithRewards(id uint) (*user_responses.UserWithRewardsResponse, error)
+	CreateUser(ctx context.Context, req user_requests.CreateUserRequest) (*user_responses.UserResponse, error)
+	GetUser(ctx context.Context, id uint) (*user_responses.UserResponse, error)
+	UpdateUser(ctx context.Context, id uint, req user_requests.UpdateUserRequest) (*user_responses.UserResponse, error)
+	DeleteUser(ctx context.Context, id uint) error
+	ListUsers(ctx context.Context, req user_requests.ListUsersRequest) (*pagination.Page[user_responses.UserResponse], error)
+	GetUserWithTransactions(ctx context.Context, id uint) (*user_responses.UserWithTransactionsResponse, error)
+	GetUserWithRewards(ctx context.Context, id uint) (*user_responses.UserWithRewardsResponse, error)
+	EnrollUser(ctx context.Context, userID, merchantID, branchID uint) error
+	ExportUsers(ctx context.Context, req user_requests.ExportUsersRequest, w io.Writer) error
 }
 
 type userService struct {
@@ -36,76 +46,188 @@ func NewUserService(userRepo user_ports.IUserRepository) IUserService {
 	return userServiceInstance
 }
 
-func (s *userService) CreateUser(req user_requests.CreateUserRequest) (*user_responses.UserResponse, error) {
+func (s *userService) CreateUser(ctx context.Context, req user_requests.CreateUserRequest) (*user_responses.UserResponse, error) {
+	principal, ok := security.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, security.ErrUnauthenticated
+	}
+
+	// Merchant callers always enroll the user in their own program
+	merchantID := req.MerchantID
+	if !principal.IsAdmin() {
+		merchantID = &principal.MerchantID
+	}
+	if merchantID != nil {
+		err := security.Authorize(ctx, security.ActionOperate, *merchantID, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	birthDate, err := parseBirthDate(req.BirthDate)
+	if err != nil {
+		return nil, err
+	}
+
 	user := &models.User{
-		Name: req.Name,
+		Name:      req.Name,
+		BirthDate: birthDate,
 	}
 
-	err := s.userRepo.Create(user)
+	err = s.userRepo.Create(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
+	if merchantID != nil {
+		err = s.userRepo.Enroll(ctx, user.ID, *merchantID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &user_responses.UserResponse{
-		ID:   user.ID,
-		Name: user.Name,
+		ID:        user.ID,
+		Name:      user.Name,
+		BirthDate: formatBirthDate(user.BirthDate),
 	}, nil
 }
 
-func (s *userService) GetUser(id uint) (*user_responses.UserResponse, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) GetUser(ctx context.Context, id uint) (*user_responses.UserResponse, error) {
+	err := s.authorizeUser(ctx, security.ActionRead, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	return &user_responses.UserResponse{
-		ID:   user.ID,
-		Name: user.Name,
+		ID:        user.ID,
+		Name:      user.Name,
+		BirthDate: formatBirthDate(user.BirthDate),
 	}, nil
 }
 
-func (s *userService) UpdateUser(id uint, req user_requests.UpdateUserRequest) (*user_responses.UserResponse, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *userService) UpdateUser(ctx context.Context, id uint, req user_requests.UpdateUserRequest) (*user_responses.UserResponse, error) {
+	err := s.authorizeUser(ctx, security.ActionOperate, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	user.Name = req.Name
+	user.BirthDate, err = parseBirthDate(req.BirthDate)
+	if err != nil {
+		return nil, err
+	}
 
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
 	return &user_responses.UserResponse{
-		ID:   user.ID,
-		Name: user.Name,
+		ID:        user.ID,
+		Name:      user.Name,
+		BirthDate: formatBirthDate(user.BirthDate),
 	}, nil
 }
 
-func (s *userService) DeleteUser(id uint) error {
-	return s.userRepo.Delete(id)
+// DeleteUser is reserved to platform admins because users are shared across merchants.
+func (s *userService) DeleteUser(ctx context.Context, id uint) error {
+	err := security.AuthorizeAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.Delete(ctx, id)
 }
 
-func (s *userService) ListUsers() ([]user_responses.UserResponse, error) {
-	users, err := s.userRepo.List()
+func (s *userService) ListUsers(ctx context.Context, req user_requests.ListUsersRequest) (*pagination.Page[user_responses.UserResponse], error) {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
 	if err != nil {
 		return nil, err
 	}
 
-	var responses []user_responses.UserResponse
-	for _, user := range users {
-		responses = append(responses, user_responses.UserResponse{
-			ID:   user.ID,
-			Name: user.Name,
-		})
+	page, err := s.userRepo.List(ctx, user_ports.UserFilter{
+		MerchantID: merchantID,
+		Name:       req.Name,
+	}, req.Request)
+	if err != nil {
+		return nil, err
 	}
 
-	return responses, nil
+	return pagination.Map(page, func(user *models.User) user_responses.UserResponse {
+		return user_responses.UserResponse{
+			ID:        user.ID,
+			Name:      user.Name,
+			BirthDate: formatBirthDate(user.BirthDate),
+		}
+	}), nil
 }
 
-func (s *userService) GetUserWithTransactions(id uint) (*user_responses.UserWithTransactionsResponse, error) {
-	user, err := s.userRepo.GetUserWithTransactions(id)
+// ExportUsers writes the users to w as CSV or JSON lines, streaming them from
+// the database.
+func (s *userService) ExportUsers(ctx context.Context, req user_requests.ExportUsersRequest, w io.Writer) error {
+	merchantID, err := security.FilterScope(ctx, req.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	encoder := exports.NewEncoder(w, req.Format, userExportColumns, userExportRecord)
+	err = s.userRepo.Stream(ctx, user_ports.UserFilter{
+		MerchantID: merchantID,
+		Name:       req.Name,
+	}, func(users []models.User) error {
+		for _, user := range users {
+			err := encoder.Write(&user_responses.UserResponse{
+				ID:        user.ID,
+				Name:      user.Name,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return encoder.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+var userExportColumns = []string{"id", "name", "created_at", "updated_at"}
+
+func userExportRecord(user *user_responses.UserResponse) []string {
+	return []string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		user.Name,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func (s *userService) GetUserWithTransactions(ctx context.Context, id uint) (*user_responses.UserWithTransactionsResponse, error) {
+	err := s.authorizeUser(ctx, security.ActionRead, id)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserWithTransactions(ctx, id, merchantID)
 	if err != nil {
 		return nil, err
 	}
@@ -127,8 +249,18 @@ func (s *userService) GetUserWithTransactions(id uint) (*user_responses.UserWith
 	}, nil
 }
 
-func (s *userService) GetUserWithRewards(id uint) (*user_responses.UserWithRewardsResponse, error) {
-	user, err := s.userRepo.GetUserWithRewards(id)
+func (s *userService) GetUserWithRewards(ctx context.Context, id uint) (*user_responses.UserWithRewardsResponse, error) {
+	err := s.authorizeUser(ctx, security.ActionRead, id)
+	if err != nil {
+		return nil, err
+	}
+
+	merchantID, err := security.MerchantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserWithRewards(ctx, id, merchantID)
 	if err != nil {
 		return nil, err
 	}
@@ -149,3 +281,59 @@ func (s *userService) GetUserWithRewards(id uint) (*user_responses.UserWithRewar
 		Rewards: rewardResponses,
 	}, nil
 }
+
+// EnrollUser adds the user to the merchant's program on a transaction at the
+// branch; enrolling twice is a no-op.
+func (s *userService) EnrollUser(ctx context.Context, userID, merchantID, branchID uint) error {
+	err := security.Authorize(ctx, security.ActionOperate, merchantID, &branchID)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.Enroll(ctx, userID, merchantID, time.Now())
+}
+
+// parseBirthDate reads a birth date the request binding already validated.
+func parseBirthDate(value *string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	birthDate, err := time.Parse(time.DateOnly, *value)
+	if err != nil {
+		return nil, domain_errors.Validation("invalid_birth_date", "birthDate must be a date such as 1990-05-17")
+	}
+	return &birthDate, nil
+}
+
+func formatBirthDate(birthDate *time.Time) *string {
+	if birthDate == nil {
+		return nil
+	}
+	formatted := birthDate.Format(time.DateOnly)
+	return &formatted
+}
+
+// authorizeUser lets merchant callers act only on the members of their program.
+func (s *userService) authorizeUser(ctx context.Context, action security.Action, userID uint) error {
+	principal, ok := security.PrincipalFromContext(ctx)
+	if !ok {
+		return security.ErrUnauthenticated
+	}
+	if principal.IsAdmin() {
+		return nil
+	}
+
+	err := security.Authorize(ctx, action, principal.MerchantID, nil)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.userRepo.IsMember(ctx, userID, principal.MerchantID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return security.ErrForbidden
+	}
+	return nil
+}
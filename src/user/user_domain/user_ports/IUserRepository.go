@@ -1,16 +1,23 @@
 package user_ports
 
 import (
+	"context"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
 )
 
 type IUserRepository interface {
-	Create(user *models.User) error
-	GetByID(id uint) (*models.User, error)
-	Update(user *models.User) error
-	Delete(id uint) error
-	List() ([]models.User, error)
-	SearchByName(name string) ([]models.User, error)
-	GetUserWithTransactions(id uint) (*models.User, error)
-	GetUserWithRewards(id uint) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id uint) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter UserFilter, page pagination.Request) (*pagination.Page[models.User], error)
+	// Stream passes the matching users to fn in batches read from a
+	// server-side cursor, in id order.
+	Stream(ctx context.Context, filter UserFilter, fn func([]models.User) error) error
+	GetUserWithTransactions(ctx context.Context, id uint, merchantID *uint) (*models.User, error)
+	GetUserWithRewards(ctx context.Context, id uint, merchantID *uint) (*models.User, error)
+	IsMember(ctx context.Context, userID, merchantID uint) (bool, error)
+	Enroll(ctx context.Context, userID, merchantID uint, enrolledAt time.Time) error
 }
@@ -0,0 +1,6 @@
+package user_ports
+
+type UserFilter struct {
+	MerchantID *uint
+	Name       string
+}
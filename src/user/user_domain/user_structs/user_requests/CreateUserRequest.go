@@ -1,5 +1,8 @@
 package user_requests
 
 type CreateUserRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	MerchantID *uint  `json:"merchantId"`
+	// BirthDate is a date such as 1990-05-17.
+	BirthDate *string `json:"birthDate" binding:"omitempty,datetime=2006-01-02"`
 }
@@ -0,0 +1,7 @@
+package user_requests
+
+type ExportUsersRequest struct {
+	MerchantID *uint  `form:"merchantId"`
+	Name       string `form:"name"`
+	Format     string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
@@ -0,0 +1,10 @@
+package user_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListUsersRequest accepts sort by id, name or createdAt.
+type ListUsersRequest struct {
+	pagination.Request
+	MerchantID *uint  `form:"merchantId"`
+	Name       string `form:"name"`
+}
@@ -2,4 +2,6 @@ package user_requests
 
 type UpdateUserRequest struct {
 	Name string `json:"name" binding:"required"`
+	// BirthDate is a date such as 1990-05-17; leaving it out clears it.
+	BirthDate *string `json:"birthDate" binding:"omitempty,datetime=2006-01-02"`
 }
@@ -5,6 +5,7 @@ import "time"
 type UserResponse struct {
 	ID        uint      `json:"id"`
 	Name      string    `json:"name"`
+	BirthDate *string   `json:"birthDate,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
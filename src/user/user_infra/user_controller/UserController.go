@@ -2,6 +2,9 @@ package user_controller
 
 import (
 	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/exports"
+	_ "loyalty-campaigns/src/common/pagination" // resolves the generic types in the swagger annotations
 	"loyalty-campaigns/src/user/user_app"
 	"loyalty-campaigns/src/user/user_domain/user_structs/user_requests"
 	"loyalty-campaigns/src/user/user_infra/user_repository"
@@ -21,7 +24,7 @@ var (
 	userControllerOnce     sync.Once
 )
 
-func NewUserController(router *gin.Engine) *UserController {
+func NewUserController(router *gin.RouterGroup) *UserController {
 	userControllerOnce.Do(func() {
 		userControllerInstance = &UserController{}
 		db := configs.NewDBConnection().GetDB()
@@ -32,14 +35,15 @@ func NewUserController(router *gin.Engine) *UserController {
 	return userControllerInstance
 }
 
-func (c *UserController) setupUserRoutes(router *gin.Engine) {
-	userGroup := router.Group("/api/users")
+func (c *UserController) setupUserRoutes(router *gin.RouterGroup) {
+	userGroup := router.Group("/users")
 	{
 		userGroup.POST("", c.CreateUser)
 		userGroup.GET("/:id", c.GetUser)
 		userGroup.PUT("/:id", c.UpdateUser)
 		userGroup.DELETE("/:id", c.DeleteUser)
 		userGroup.GET("", c.ListUsers)
+		userGroup.GET("/export", c.ExportUsers)
 		userGroup.GET("/:id/transactions", c.GetUserWithTransactions)
 		userGroup.GET("/:id/rewards", c.GetUserWithRewards)
 	}
@@ -51,21 +55,22 @@ func (c *UserController) setupUserRoutes(router *gin.Engine) {
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			request	body		user_requests.CreateUserRequest	true	"User creation request"
 //	@Success		201		{object}	user_responses.UserResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/users [post]
 func (c *UserController) CreateUser(ctx *gin.Context) {
 	var req user_requests.CreateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.userService.CreateUser(req)
+	response, err := c.userService.CreateUser(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -78,21 +83,22 @@ func (c *UserController) CreateUser(ctx *gin.Context) {
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"User ID"
 //	@Success		200	{object}	user_responses.UserResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		404	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
 //	@Router			/api/users/{id} [get]
 func (c *UserController) GetUser(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.userService.GetUser(uint(id))
+	response, err := c.userService.GetUser(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		ctx.Error(err)
 		return
 	}
 
@@ -105,28 +111,29 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id		path		int								true	"User ID"
 //	@Param			request	body		user_requests.UpdateUserRequest	true	"User update request"
 //	@Success		200		{object}	user_responses.UserResponse
-//	@Failure		400		{object}	map[string]string
-//	@Failure		500		{object}	map[string]string
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/users/{id} [put]
 func (c *UserController) UpdateUser(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
 	var req user_requests.UpdateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(domain_errors.InvalidRequest(err))
 		return
 	}
 
-	response, err := c.userService.UpdateUser(uint(id), req)
+	response, err := c.userService.UpdateUser(ctx.Request.Context(), uint(id), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -139,21 +146,22 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"User ID"
 //	@Success		200	{object}	map[string]string
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/users/{id} [delete]
 func (c *UserController) DeleteUser(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	err = c.userService.DeleteUser(uint(id))
+	err = c.userService.DeleteUser(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -161,22 +169,56 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 }
 
 // ListUsers godoc
-//	@Summary		List all users
-//	@Description	Get a list of all users in the system
+//	@Summary		List users
+//	@Description	Get a page of the users enrolled in the caller's merchant, optionally filtered by name. Sort by id, name or createdAt, prefixed with "-" for descending order.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		user_responses.UserResponse
-//	@Failure		500	{object}	map[string]string
+//	@Security		ApiKeyAuth
+//	@Param			request	query		user_requests.ListUsersRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[user_responses.UserResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
 //	@Router			/api/users [get]
 func (c *UserController) ListUsers(ctx *gin.Context) {
-	responses, err := c.userService.ListUsers()
+	var req user_requests.ListUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.userService.ListUsers(ctx.Request.Context(), req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, responses)
+	ctx.JSON(http.StatusOK, page)
+}
+
+// ExportUsers godoc
+//	@Summary		Export users
+//	@Description	Download the users enrolled in the caller's merchant, optionally filtered by name, as CSV (the default) or JSON lines in id order. The file is streamed as it is read, so exports of any size are supported.
+//	@Tags			users
+//	@Produce		text/csv,application/x-ndjson
+//	@Security		ApiKeyAuth
+//	@Param			request	query		user_requests.ExportUsersRequest	false	"Filters and format"
+//	@Success		200		{file}		file
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/users/export [get]
+func (c *UserController) ExportUsers(ctx *gin.Context) {
+	var req user_requests.ExportUsersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	err := c.userService.ExportUsers(ctx.Request.Context(), req, exports.Attachment(ctx, "users", req.Format))
+	if err != nil {
+		ctx.Error(err)
+	}
 }
 
 // GetUserWithTransactions godoc
@@ -185,21 +227,22 @@ func (c *UserController) ListUsers(ctx *gin.Context) {
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"User ID"
 //	@Success		200	{object}	user_responses.UserWithTransactionsResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/users/{id}/transactions [get]
 func (c *UserController) GetUserWithTransactions(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.userService.GetUserWithTransactions(uint(id))
+	response, err := c.userService.GetUserWithTransactions(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -212,21 +255,22 @@ func (c *UserController) GetUserWithTransactions(ctx *gin.Context) {
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
+//	@Security		ApiKeyAuth
 //	@Param			id	path		int	true	"User ID"
 //	@Success		200	{object}	user_responses.UserWithRewardsResponse
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
 //	@Router			/api/users/{id}/rewards [get]
 func (c *UserController) GetUserWithRewards(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
 		return
 	}
 
-	response, err := c.userService.GetUserWithRewards(uint(id))
+	response, err := c.userService.GetUserWithRewards(ctx.Request.Context(), uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -1,10 +1,17 @@
 package user_repository
 
 import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/exports"
 	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
 	"loyalty-campaigns/src/user/user_domain/user_ports"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormUserRepository struct {
@@ -15,53 +22,111 @@ func NewGormUserRepository(db *gorm.DB) user_ports.IUserRepository {
 	return &GormUserRepository{DB: db}
 }
 
-func (r *GormUserRepository) Create(user *models.User) error {
-	return r.DB.Create(user).Error
+func (r *GormUserRepository) Create(ctx context.Context, user *models.User) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Create(user).Error, "user")
 }
 
-func (r *GormUserRepository) GetByID(id uint) (*models.User, error) {
+func (r *GormUserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	err := r.DB.First(&user, id).Error
+	err := configs.DB(ctx, r.DB).First(&user, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "user")
 	}
 	return &user, nil
 }
 
-func (r *GormUserRepository) Update(user *models.User) error {
-	return r.DB.Save(user).Error
+func (r *GormUserRepository) Update(ctx context.Context, user *models.User) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Save(user).Error, "user")
 }
 
-func (r *GormUserRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.User{}, id).Error
+func (r *GormUserRepository) Delete(ctx context.Context, id uint) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Delete(&models.User{}, id).Error, "user")
 }
 
-func (r *GormUserRepository) List() ([]models.User, error) {
-	var users []models.User
-	err := r.DB.Find(&users).Error
-	return users, err
+var userSorting = pagination.Sorting[models.User]{
+	IDColumn: "id",
+	ID:       func(user *models.User) uint { return user.ID },
+	Fields: map[string]pagination.Key[models.User]{
+		"id":        {Column: "id", Value: func(user *models.User) any { return user.ID }},
+		"name":      {Column: "name", Value: func(user *models.User) any { return user.Name }},
+		"createdAt": {Column: "created_at", Value: func(user *models.User) any { return user.CreatedAt }},
+	},
+	Default: "id",
 }
 
-func (r *GormUserRepository) SearchByName(name string) ([]models.User, error) {
-	var users []models.User
-	err := r.DB.Where("name LIKE ?", "%"+name+"%").Find(&users).Error
-	return users, err
+func (r *GormUserRepository) List(ctx context.Context, filter user_ports.UserFilter, page pagination.Request) (*pagination.Page[models.User], error) {
+	return pagination.Find(r.filtered(ctx, filter), page, userSorting)
 }
 
-func (r *GormUserRepository) GetUserWithTransactions(id uint) (*models.User, error) {
+func (r *GormUserRepository) Stream(ctx context.Context, filter user_ports.UserFilter, fn func([]models.User) error) error {
+	return exports.Stream(ctx, r.DB, r.filtered(ctx, filter).Order("id"), fn)
+}
+
+func (r *GormUserRepository) filtered(ctx context.Context, filter user_ports.UserFilter) *gorm.DB {
+	query := r.scoped(ctx, filter.MerchantID)
+	if filter.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Name+"%")
+	}
+	return query
+}
+
+func (r *GormUserRepository) GetUserWithTransactions(ctx context.Context, id uint, merchantID *uint) (*models.User, error) {
 	var user models.User
-	err := r.DB.Preload("Transactions").First(&user, id).Error
+	query := configs.DB(ctx, r.DB)
+	if merchantID != nil {
+		query = query.Preload("Transactions", "branch_id IN (SELECT id FROM branches WHERE merchant_id = ?)", *merchantID)
+	} else {
+		query = query.Preload("Transactions")
+	}
+
+	err := query.First(&user, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "user")
 	}
 	return &user, nil
 }
 
-func (r *GormUserRepository) GetUserWithRewards(id uint) (*models.User, error) {
+func (r *GormUserRepository) GetUserWithRewards(ctx context.Context, id uint, merchantID *uint) (*models.User, error) {
 	var user models.User
-	err := r.DB.Preload("Rewards").First(&user, id).Error
+	query := configs.DB(ctx, r.DB)
+	if merchantID != nil {
+		query = query.Preload("Rewards", "merchant_id = ?", *merchantID)
+	} else {
+		query = query.Preload("Rewards")
+	}
+
+	err := query.First(&user, id).Error
 	if err != nil {
-		return nil, err
+		return nil, domain_errors.Translate(err, "user")
 	}
 	return &user, nil
 }
+
+func (r *GormUserRepository) IsMember(ctx context.Context, userID, merchantID uint) (bool, error) {
+	var count int64
+	err := configs.DB(ctx, r.DB).Model(&models.Membership{}).
+		Where("user_id = ? AND merchant_id = ?", userID, merchantID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormUserRepository) Enroll(ctx context.Context, userID, merchantID uint, enrolledAt time.Time) error {
+	membership := &models.Membership{
+		UserID:     userID,
+		MerchantID: merchantID,
+		EnrolledAt: enrolledAt,
+	}
+	err := configs.DB(ctx, r.DB).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "merchant_id"}}, DoNothing: true}).
+		Create(membership).Error
+	return domain_errors.Translate(err, "membership")
+}
+
+// scoped restricts the users to the members of the merchant, when one is given.
+func (r *GormUserRepository) scoped(ctx context.Context, merchantID *uint) *gorm.DB {
+	query := configs.DB(ctx, r.DB)
+	if merchantID != nil {
+		query = query.Where("id IN (SELECT user_id FROM memberships WHERE merchant_id = ? AND deleted_at IS NULL)", *merchantID)
+	}
+	return query
+}
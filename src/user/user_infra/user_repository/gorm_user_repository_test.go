@@ -0,0 +1,87 @@
+package user_repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/user/user_domain/user_ports"
+	"loyalty-campaigns/src/user/user_infra/user_repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var _ = Describe("GormUserRepository", func() {
+	var (
+		sqlMock    sqlmock.Sqlmock
+		repository user_ports.IUserRepository
+	)
+
+	BeforeEach(func() {
+		sqlDB, mock, err := sqlmock.New()
+		Expect(err).To(BeNil())
+		db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		Expect(err).To(BeNil())
+		sqlMock = mock
+		repository = user_repository.NewGormUserRepository(db)
+	})
+
+	AfterEach(func() {
+		Expect(sqlMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("Stream", func() {
+		userColumns := []string{"id", "name"}
+
+		// expectCursor expects the users to be read through a cursor over the
+		// query, in the batches given.
+		expectCursor := func(query string, args []driver.Value, batches ...*sqlmock.Rows) {
+			sqlMock.ExpectBegin()
+			sqlMock.ExpectExec(`^DECLARE export_cursor NO SCROLL CURSOR FOR ` + query + `$`).
+				WithArgs(args...).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			for _, rows := range batches {
+				sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).WillReturnRows(rows)
+			}
+			sqlMock.ExpectQuery(`^FETCH 1000 FROM export_cursor$`).WillReturnRows(sqlmock.NewRows(userColumns))
+			sqlMock.ExpectExec(`^CLOSE export_cursor$`).WillReturnResult(sqlmock.NewResult(0, 0))
+			sqlMock.ExpectCommit()
+		}
+
+		It("should read the members of the merchant whose name matches", func() {
+			merchantID := uint(4)
+			expectCursor(
+				`SELECT \* FROM "users" WHERE \(id IN \(SELECT user_id FROM memberships WHERE merchant_id = \$1 AND deleted_at IS NULL\)\) AND name ILIKE \$2 AND "users"\."deleted_at" IS NULL ORDER BY id`,
+				[]driver.Value{merchantID, "%ana%"},
+				sqlmock.NewRows(userColumns).AddRow(1, "Ana"),
+				sqlmock.NewRows(userColumns).AddRow(2, "Mariana"),
+			)
+
+			var batches [][]models.User
+			err := repository.Stream(context.Background(), user_ports.UserFilter{MerchantID: &merchantID, Name: "ana"}, func(batch []models.User) error {
+				batches = append(batches, batch)
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+			Expect(batches).To(HaveLen(2))
+			Expect(batches[1][0].Name).To(Equal("Mariana"))
+		})
+
+		It("should read every user without a merchant filter", func() {
+			expectCursor(`SELECT \* FROM "users" WHERE "users"\."deleted_at" IS NULL ORDER BY id`, nil)
+
+			err := repository.Stream(context.Background(), user_ports.UserFilter{}, func(batch []models.User) error {
+				return nil
+			})
+
+			Expect(err).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package user_repository_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUserRepository(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "UserRepository Suite")
+}
@@ -0,0 +1,535 @@
+package webhook_app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/common/utils"
+	"loyalty-campaigns/src/webhook/webhook_domain/webhook_ports"
+	"loyalty-campaigns/src/webhook/webhook_domain/webhook_structs/webhook_requests"
+	"loyalty-campaigns/src/webhook/webhook_domain/webhook_structs/webhook_responses"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers sent with every delivery. The signature header has the form
+// "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">".
+const (
+	SignatureHeader = "X-Loyalty-Signature"
+	EventHeader     = "X-Loyalty-Event"
+	EventIDHeader   = "X-Loyalty-Event-ID"
+)
+
+const (
+	// MaxDeliveryAttempts is the number of attempts before a delivery is dead.
+	MaxDeliveryAttempts = 8
+
+	minDeliveryBackoff   = 10 * time.Second
+	maxDeliveryBackoff   = time.Hour
+	deliveryLease        = 5 * time.Minute
+	deliveryLeaseMargin  = 30 * time.Second
+	maxDeliveryBatchSize = 50
+	maxLastErrorBytes    = 1000
+	secretPrefix         = "whsec_"
+)
+
+var (
+	ErrUnknownEventType = domain_errors.Validation("unknown_event_type", "unknown event type, expected one of %s", strings.Join(events.Types, ", "))
+	ErrDeliveryNotFound = domain_errors.NotFound("webhook_delivery_not_found", "webhook delivery not found")
+)
+
+type IWebhookService interface {
+	CreateWebhook(ctx context.Context, merchantID uint, req webhook_requests.CreateWebhookRequest) (*webhook_responses.IssuedWebhookResponse, error)
+	GetWebhook(ctx context.Context, id uint) (*webhook_responses.WebhookResponse, error)
+	UpdateWebhook(ctx context.Context, id uint, req webhook_requests.UpdateWebhookRequest) (*webhook_responses.WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListWebhooks(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[webhook_responses.WebhookResponse], error)
+	ListDeliveries(ctx context.Context, webhookID uint, req webhook_requests.ListDeliveriesRequest) (*pagination.Page[webhook_responses.DeliveryResponse], error)
+	Redeliver(ctx context.Context, webhookID, deliveryID uint) (*webhook_responses.DeliveryResponse, error)
+	// Publish schedules the delivery of an event to the merchant's webhooks
+	// subscribed to it. It makes the service an events.ISink for the relay.
+	Publish(ctx context.Context, event events.Event) error
+	// DeliverDue attempts the deliveries that are due and returns how many
+	// succeeded.
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+// WebhookPolicy configures the deliveries. Webhooks only reach public
+// addresses unless AllowPrivateNetworks is set, which is meant for receivers
+// run locally during development.
+type WebhookPolicy struct {
+	Timeout              time.Duration
+	AllowPrivateNetworks bool
+}
+
+// WebhookPolicyFromEnv reads the policy from WEBHOOK_TIMEOUT and
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS.
+func WebhookPolicyFromEnv() WebhookPolicy {
+	return WebhookPolicy{
+		Timeout:              configs.GetEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		AllowPrivateNetworks: configs.GetEnvBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+	}
+}
+
+type webhookService struct {
+	webhookRepo webhook_ports.IWebhookRepository
+	client      *http.Client
+	lease       time.Duration
+	batchSize   int
+	logger      utils.ILogger
+}
+
+func NewWebhookService(webhookRepo webhook_ports.IWebhookRepository, policy WebhookPolicy) IWebhookService {
+	lease, batchSize := deliveryBatch(policy.Timeout)
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		client:      newDeliveryClient(policy),
+		lease:       lease,
+		batchSize:   batchSize,
+		logger:      utils.NewLogger(),
+	}
+}
+
+// deliveryBatch returns the lease and the size of the batches claimed by
+// DeliverDue. Deliveries are sent one after another, so the batch is sized
+// for all of them to time out before the lease expires and another worker
+// claims them again.
+func deliveryBatch(timeout time.Duration) (time.Duration, int) {
+	if timeout <= 0 {
+		return deliveryLease, 1
+	}
+
+	size := int((deliveryLease - deliveryLeaseMargin) / timeout)
+	size = min(max(size, 1), maxDeliveryBatchSize)
+	lease := max(deliveryLease, time.Duration(size)*timeout+deliveryLeaseMargin)
+	return lease, size
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, merchantID uint, req webhook_requests.CreateWebhookRequest) (*webhook_responses.IssuedWebhookResponse, error) {
+	err := security.Authorize(ctx, security.ActionManage, merchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = newSecret()
+		if err != nil {
+			s.logger.Error("Error al generar el secreto del webhook: %v", err)
+			return nil, err
+		}
+	}
+
+	webhook := &models.Webhook{
+		MerchantID: merchantID,
+		URL:        req.URL,
+		EventTypes: strings.Join(req.EventTypes, ","),
+		Secret:     secret,
+		Active:     true,
+	}
+
+	err = s.webhookRepo.Create(ctx, webhook)
+	if err != nil {
+		s.logger.Error("Error al crear webhook: %v", err)
+		return nil, err
+	}
+
+	return &webhook_responses.IssuedWebhookResponse{
+		WebhookResponse: *mapWebhookToResponse(webhook),
+		Secret:          secret,
+	}, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id uint) (*webhook_responses.WebhookResponse, error) {
+	webhook, err := s.getWebhook(ctx, security.ActionManage, id)
+	if err != nil {
+		return nil, err
+	}
+	return mapWebhookToResponse(webhook), nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, id uint, req webhook_requests.UpdateWebhookRequest) (*webhook_responses.WebhookResponse, error) {
+	webhook, err := s.getWebhook(ctx, security.ActionManage, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.URL = req.URL
+	webhook.EventTypes = strings.Join(req.EventTypes, ",")
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	err = s.webhookRepo.Update(ctx, webhook)
+	if err != nil {
+		s.logger.Error("Error al actualizar webhook: %v", err)
+		return nil, err
+	}
+
+	return mapWebhookToResponse(webhook), nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id uint) error {
+	_, err := s.getWebhook(ctx, security.ActionManage, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.webhookRepo.Delete(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al eliminar webhook: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[webhook_responses.WebhookResponse], error) {
+	err := security.Authorize(ctx, security.ActionManage, merchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := s.webhookRepo.ListByMerchant(ctx, merchantID, page)
+	if err != nil {
+		s.logger.Error("Error al listar webhooks: %v", err)
+		return nil, err
+	}
+
+	return pagination.Map(webhooks, func(webhook *models.Webhook) webhook_responses.WebhookResponse {
+		return *mapWebhookToResponse(webhook)
+	}), nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, webhookID uint, req webhook_requests.ListDeliveriesRequest) (*pagination.Page[webhook_responses.DeliveryResponse], error) {
+	_, err := s.getWebhook(ctx, security.ActionManage, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, webhook_ports.DeliveryFilter{
+		WebhookID: webhookID,
+		Status:    req.Status,
+	}, req.Request)
+	if err != nil {
+		s.logger.Error("Error al listar entregas del webhook: %v", err)
+		return nil, err
+	}
+
+	return pagination.Map(deliveries, func(delivery *models.WebhookDelivery) webhook_responses.DeliveryResponse {
+		return *mapDeliveryToResponse(delivery)
+	}), nil
+}
+
+// Redeliver sends a delivery again right away, whatever its status, and
+// restarts its attempts: if it fails it is retried like a new delivery.
+func (s *webhookService) Redeliver(ctx context.Context, webhookID, deliveryID uint) (*webhook_responses.DeliveryResponse, error) {
+	webhook, err := s.getWebhook(ctx, security.ActionManage, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.webhookRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.WebhookID != webhook.ID {
+		return nil, ErrDeliveryNotFound
+	}
+
+	delivery.Webhook = *webhook
+	delivery.Attempts = 0
+	s.attempt(ctx, delivery)
+
+	err = s.webhookRepo.UpdateDelivery(ctx, delivery)
+	if err != nil {
+		s.logger.Error("Error al guardar la entrega del webhook: %v", err)
+		return nil, err
+	}
+
+	return mapDeliveryToResponse(delivery), nil
+}
+
+func (s *webhookService) Publish(ctx context.Context, event events.Event) error {
+	if event.MerchantID == nil {
+		return nil
+	}
+
+	webhooks, err := s.webhookRepo.ListActiveByMerchant(ctx, *event.MerchantID)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	var payload []byte
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !slices.Contains(strings.Split(webhook.EventTypes, ","), event.Type) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(event)
+			if err != nil {
+				return err
+			}
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+
+	return s.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+func (s *webhookService) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, time.Now(), s.lease, s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		if s.attempt(ctx, delivery) {
+			delivered++
+		}
+
+		err = s.webhookRepo.UpdateDelivery(ctx, delivery)
+		if err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// attempt sends the delivery once and records the outcome: delivered, retried
+// after an exponential backoff, or dead after MaxDeliveryAttempts.
+func (s *webhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) bool {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	status, err := s.send(ctx, delivery)
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	if err == nil {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		return true
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > maxLastErrorBytes {
+		delivery.LastError = delivery.LastError[:maxLastErrorBytes]
+	}
+	if delivery.Attempts >= MaxDeliveryAttempts {
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.NextAttemptAt = nil
+		s.logger.Warn("webhook delivery %d of event %s is dead after %d attempts: %v", delivery.ID, delivery.EventID, delivery.Attempts, err)
+		return false
+	}
+
+	next := now.Add(deliveryBackoff(delivery.Attempts))
+	delivery.Status = models.WebhookDeliveryRetrying
+	delivery.NextAttemptAt = &next
+	return false
+}
+
+// send posts the signed payload and returns the response status, if any.
+func (s *webhookService) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "loyalty-campaigns-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(EventIDHeader, delivery.EventID)
+	req.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, time.Now().Unix(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// ErrForbiddenAddress rejects a delivery to an address that is not public.
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// blockedPrefixes are the special-purpose ranges, besides the private, loopback
+// and link-local ones, that webhooks must not reach.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// newDeliveryClient returns the client that posts the deliveries. Unless the
+// policy allows private networks, every connection is checked once the name of
+// the receiver is resolved, which also stops a name that resolves to a public
+// address when the webhook is saved and to an internal one later. Proxies and
+// redirects are not followed, as they would reach addresses that are not
+// checked.
+func newDeliveryClient(policy WebhookPolicy) *http.Client {
+	dialer := &net.Dialer{Timeout: policy.Timeout, KeepAlive: 30 * time.Second}
+	if !policy.AllowPrivateNetworks {
+		dialer.Control = publicOnly
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   policy.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicOnly is a dialer control that refuses the connections to loopback,
+// private, link-local and other special-purpose addresses.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	addr := addrPort.Addr().Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, addr)
+		}
+	}
+	return nil
+}
+
+// Sign returns the signature header of a delivery body sent at timestamp.
+// Receivers recompute it with their secret to authenticate the delivery.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// deliveryBackoff doubles the wait after every failed attempt, up to a maximum.
+func deliveryBackoff(attempts int) time.Duration {
+	backoff := minDeliveryBackoff
+	for i := 1; i < attempts && backoff < maxDeliveryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxDeliveryBackoff)
+}
+
+// getWebhook loads the webhook and checks the action against its merchant.
+func (s *webhookService) getWebhook(ctx context.Context, action security.Action, id uint) (*models.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Error al obtener webhook: %v", err)
+		return nil, err
+	}
+
+	err = security.Authorize(ctx, action, webhook.MerchantID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(events.Types, eventType) {
+			return ErrUnknownEventType
+		}
+	}
+	return nil
+}
+
+func newSecret() (string, error) {
+	secret := make([]byte, 24)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(secret), nil
+}
+
+func mapWebhookToResponse(webhook *models.Webhook) *webhook_responses.WebhookResponse {
+	return &webhook_responses.WebhookResponse{
+		ID:         webhook.ID,
+		MerchantID: webhook.MerchantID,
+		URL:        webhook.URL,
+		EventTypes: strings.Split(webhook.EventTypes, ","),
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+func mapDeliveryToResponse(delivery *models.WebhookDelivery) *webhook_responses.DeliveryResponse {
+	return &webhook_responses.DeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+}
@@ -0,0 +1,13 @@
+package webhook_app_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestWebhookApp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "WebhookApp Suite")
+}
@@ -0,0 +1,424 @@
+package webhook_app_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"loyalty-campaigns/src/common/events"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/common/security"
+	"loyalty-campaigns/src/webhook/webhook_app"
+	"loyalty-campaigns/src/webhook/webhook_domain/webhook_ports"
+	"loyalty-campaigns/src/webhook/webhook_domain/webhook_structs/webhook_requests"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/mock"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+var _ = Describe("WebhookService", func() {
+	const secret = "whsec_test-secret-0123456789"
+
+	var (
+		webhookService webhook_app.IWebhookService
+		mockWebhooks   *mockWebhookRepository
+		receiver       *httptest.Server
+		responseStatus int
+		mu             sync.Mutex
+		received       []receivedRequest
+		merchantID     uint
+		webhook        *models.Webhook
+		ctx            context.Context
+	)
+
+	BeforeEach(func() {
+		responseStatus = http.StatusOK
+		received = nil
+		receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+			status := responseStatus
+			mu.Unlock()
+			if status >= 300 && status < 400 {
+				w.Header().Set("Location", "/redirected")
+			}
+			w.WriteHeader(status)
+		}))
+		DeferCleanup(receiver.Close)
+
+		mockWebhooks = new(mockWebhookRepository)
+		webhookService = webhook_app.NewWebhookService(mockWebhooks, webhook_app.WebhookPolicy{Timeout: time.Second, AllowPrivateNetworks: true})
+		merchantID = 4
+		webhook = &models.Webhook{
+			MerchantID: merchantID,
+			URL:        receiver.URL,
+			EventTypes: events.RewardGranted + "," + events.RewardRedeemed,
+			Secret:     secret,
+			Active:     true,
+		}
+		webhook.ID = 9
+		ctx = security.WithPrincipal(context.Background(), &security.Principal{
+			Role:       security.RoleMerchantAdmin,
+			MerchantID: merchantID,
+		})
+	})
+
+	dueDelivery := func(attempts int) *models.WebhookDelivery {
+		now := time.Now()
+		delivery := &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Webhook:       *webhook,
+			EventID:       "8f14e45f-ceea-4e7a-9f3b-1c2d3e4f5a6b",
+			EventType:     events.RewardGranted,
+			Payload:       `{"id":"8f14e45f-ceea-4e7a-9f3b-1c2d3e4f5a6b","type":"reward.granted","data":{"amount":10}}`,
+			Status:        models.WebhookDeliveryRetrying,
+			Attempts:      attempts,
+			NextAttemptAt: &now,
+		}
+		delivery.ID = 21
+		return delivery
+	}
+
+	Describe("CreateWebhook", func() {
+		It("should generate a secret and return it once", func() {
+			var stored *models.Webhook
+			mockWebhooks.On("Create", mock.Anything, mock.AnythingOfType("*models.Webhook")).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(*models.Webhook)
+			}).Return(nil)
+
+			response, err := webhookService.CreateWebhook(ctx, merchantID, webhook_requests.CreateWebhookRequest{
+				URL:        receiver.URL,
+				EventTypes: []string{events.RewardGranted},
+			})
+
+			Expect(err).To(BeNil())
+			Expect(response.Secret).To(HavePrefix("whsec_"))
+			Expect(stored.Secret).To(Equal(response.Secret))
+			Expect(response.EventTypes).To(Equal([]string{events.RewardGranted}))
+		})
+
+		It("should reject unknown event types", func() {
+			_, err := webhookService.CreateWebhook(ctx, merchantID, webhook_requests.CreateWebhookRequest{
+				URL:        receiver.URL,
+				EventTypes: []string{"reward.stolen"},
+			})
+
+			Expect(err).To(MatchError(webhook_app.ErrUnknownEventType))
+			mockWebhooks.AssertNotCalled(GinkgoT(), "Create", mock.Anything, mock.Anything)
+		})
+	})
+
+	Describe("Publish", func() {
+		It("should schedule a delivery for the webhooks subscribed to the event", func() {
+			other := &models.Webhook{MerchantID: merchantID, EventTypes: events.CampaignCreated, Active: true}
+			other.ID = 10
+			mockWebhooks.On("ListActiveByMerchant", mock.Anything, merchantID).Return([]models.Webhook{*webhook, *other}, nil)
+			var scheduled []models.WebhookDelivery
+			mockWebhooks.On("CreateDeliveries", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				scheduled = args.Get(1).([]models.WebhookDelivery)
+			}).Return(nil)
+
+			userID := uint(1)
+			err := webhookService.Publish(context.Background(), events.Event{
+				ID:         "event-1",
+				Type:       events.RewardGranted,
+				UserID:     &userID,
+				MerchantID: &merchantID,
+				Data:       json.RawMessage(`{"amount":10}`),
+			})
+
+			Expect(err).To(BeNil())
+			Expect(scheduled).To(HaveLen(1))
+			Expect(scheduled[0].WebhookID).To(Equal(webhook.ID))
+			Expect(scheduled[0].EventID).To(Equal("event-1"))
+			Expect(scheduled[0].Status).To(Equal(models.WebhookDeliveryPending))
+			Expect(scheduled[0].Payload).To(ContainSubstring(`"data":{"amount":10}`))
+		})
+	})
+
+	Describe("DeliverDue", func() {
+		var delivery *models.WebhookDelivery
+
+		BeforeEach(func() {
+			delivery = dueDelivery(0)
+			mockWebhooks.On("ClaimDueDeliveries", mock.Anything, mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything).Return([]models.WebhookDelivery{*delivery}, nil)
+			mockWebhooks.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).Run(func(args mock.Arguments) {
+				delivery = args.Get(1).(*models.WebhookDelivery)
+			}).Return(nil)
+		})
+
+		It("should post the payload signed with the webhook secret", func() {
+			delivered, err := webhookService.DeliverDue(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(delivered).To(Equal(1))
+			Expect(received).To(HaveLen(1))
+
+			request := received[0]
+			Expect(string(request.body)).To(Equal(delivery.Payload))
+			Expect(request.header.Get(webhook_app.EventHeader)).To(Equal(events.RewardGranted))
+			Expect(request.header.Get(webhook_app.EventIDHeader)).To(Equal(delivery.EventID))
+
+			signature := request.header.Get(webhook_app.SignatureHeader)
+			timestamp, err := strconv.ParseInt(strings.TrimPrefix(strings.Split(signature, ",")[0], "t="), 10, 64)
+			Expect(err).To(BeNil())
+			Expect(signature).To(Equal(webhook_app.Sign(secret, timestamp, request.body)))
+			Expect(signature).NotTo(Equal(webhook_app.Sign("another-secret", timestamp, request.body)))
+
+			Expect(delivery.Status).To(Equal(models.WebhookDeliveryDelivered))
+			Expect(delivery.Attempts).To(Equal(1))
+			Expect(*delivery.ResponseStatus).To(Equal(http.StatusOK))
+			Expect(delivery.NextAttemptAt).To(BeNil())
+		})
+
+		It("should retry with an exponential backoff when the receiver fails", func() {
+			responseStatus = http.StatusServiceUnavailable
+
+			delivered, err := webhookService.DeliverDue(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(delivered).To(Equal(0))
+			Expect(delivery.Status).To(Equal(models.WebhookDeliveryRetrying))
+			Expect(*delivery.ResponseStatus).To(Equal(http.StatusServiceUnavailable))
+			Expect(delivery.LastError).To(ContainSubstring("503"))
+			firstBackoff := time.Until(*delivery.NextAttemptAt)
+			Expect(firstBackoff).To(BeNumerically("~", 10*time.Second, time.Second))
+		})
+
+		It("should double the backoff after every failed attempt", func() {
+			responseStatus = http.StatusInternalServerError
+			mockWebhooks.ExpectedCalls = nil
+			mockWebhooks.On("ClaimDueDeliveries", mock.Anything, mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything).Return([]models.WebhookDelivery{*dueDelivery(2)}, nil)
+			mockWebhooks.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).Run(func(args mock.Arguments) {
+				delivery = args.Get(1).(*models.WebhookDelivery)
+			}).Return(nil)
+
+			_, err := webhookService.DeliverDue(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(delivery.Attempts).To(Equal(3))
+			Expect(time.Until(*delivery.NextAttemptAt)).To(BeNumerically("~", 40*time.Second, time.Second))
+		})
+
+		It("should move the delivery to the dead letter state after the last attempt", func() {
+			responseStatus = http.StatusInternalServerError
+			mockWebhooks.ExpectedCalls = nil
+			mockWebhooks.On("ClaimDueDeliveries", mock.Anything, mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything).Return([]models.WebhookDelivery{*dueDelivery(webhook_app.MaxDeliveryAttempts - 1)}, nil)
+			mockWebhooks.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).Run(func(args mock.Arguments) {
+				delivery = args.Get(1).(*models.WebhookDelivery)
+			}).Return(nil)
+
+			_, err := webhookService.DeliverDue(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(delivery.Status).To(Equal(models.WebhookDeliveryDead))
+			Expect(delivery.NextAttemptAt).To(BeNil())
+		})
+	})
+
+	Describe("DeliverDue batches", func() {
+		DescribeTable("should claim no more deliveries than can time out within the lease",
+			func(timeout time.Duration, lease time.Duration, limit int) {
+				webhookService = webhook_app.NewWebhookService(mockWebhooks, webhook_app.WebhookPolicy{Timeout: timeout})
+				mockWebhooks.On("ClaimDueDeliveries", mock.Anything, mock.AnythingOfType("time.Time"), lease, limit).Return([]models.WebhookDelivery{}, nil)
+
+				delivered, err := webhookService.DeliverDue(context.Background())
+
+				Expect(err).To(BeNil())
+				Expect(delivered).To(Equal(0))
+				mockWebhooks.AssertExpectations(GinkgoT())
+				Expect(time.Duration(limit) * timeout).To(BeNumerically("<", lease))
+			},
+			Entry("a short timeout", time.Second, 5*time.Minute, 50),
+			Entry("the default timeout", 10*time.Second, 5*time.Minute, 27),
+			Entry("a timeout longer than the lease", 10*time.Minute, 10*time.Minute+30*time.Second, 1),
+		)
+	})
+
+	Describe("DeliverDue to addresses that are not public", func() {
+		var delivery *models.WebhookDelivery
+
+		BeforeEach(func() {
+			webhookService = webhook_app.NewWebhookService(mockWebhooks, webhook_app.WebhookPolicy{Timeout: time.Second})
+			mockWebhooks.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).Run(func(args mock.Arguments) {
+				delivery = args.Get(1).(*models.WebhookDelivery)
+			}).Return(nil)
+		})
+
+		DescribeTable("should refuse to connect",
+			func(url func() string) {
+				webhook.URL = url()
+				mockWebhooks.On("ClaimDueDeliveries", mock.Anything, mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything).Return([]models.WebhookDelivery{*dueDelivery(0)}, nil)
+
+				delivered, err := webhookService.DeliverDue(context.Background())
+
+				Expect(err).To(BeNil())
+				Expect(delivered).To(Equal(0))
+				Expect(received).To(BeEmpty())
+				Expect(delivery.Status).To(Equal(models.WebhookDeliveryRetrying))
+				Expect(delivery.LastError).To(ContainSubstring(webhook_app.ErrForbiddenAddress.Error()))
+			},
+			Entry("loopback", func() string { return receiver.URL }),
+			Entry("a name that resolves to loopback", func() string { return strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1) }),
+			Entry("private", func() string { return "https://10.0.0.1/hooks" }),
+			Entry("link-local", func() string { return "https://169.254.169.254/latest/meta-data" }),
+			Entry("shared address space", func() string { return "https://100.64.0.1/hooks" }),
+			Entry("IPv4-mapped loopback", func() string { return "https://[::ffff:127.0.0.1]/hooks" }),
+		)
+	})
+
+	Describe("DeliverDue with redirects", func() {
+		It("should not follow them", func() {
+			responseStatus = http.StatusTemporaryRedirect
+			var delivery *models.WebhookDelivery
+			mockWebhooks.On("ClaimDueDeliveries", mock.Anything, mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything).Return([]models.WebhookDelivery{*dueDelivery(0)}, nil)
+			mockWebhooks.On("UpdateDelivery", mock.Anything, mock.AnythingOfType("*models.WebhookDelivery")).Run(func(args mock.Arguments) {
+				delivery = args.Get(1).(*models.WebhookDelivery)
+			}).Return(nil)
+
+			delivered, err := webhookService.DeliverDue(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(delivered).To(Equal(0))
+			Expect(received).To(HaveLen(1))
+			Expect(*delivery.ResponseStatus).To(Equal(http.StatusTemporaryRedirect))
+		})
+	})
+
+	Describe("webhook requests", func() {
+		It("should only accept https URLs", func() {
+			valid := webhook_requests.CreateWebhookRequest{URL: "https://crm.example.com/hooks", EventTypes: []string{events.RewardGranted}}
+			Expect(binding.Validator.ValidateStruct(valid)).To(Succeed())
+
+			plain := valid
+			plain.URL = "http://crm.example.com/hooks"
+			Expect(binding.Validator.ValidateStruct(plain)).NotTo(Succeed())
+
+			update := webhook_requests.UpdateWebhookRequest{URL: "http://crm.example.com/hooks", EventTypes: []string{events.RewardGranted}}
+			Expect(binding.Validator.ValidateStruct(update)).NotTo(Succeed())
+		})
+	})
+
+	Describe("Redeliver", func() {
+		BeforeEach(func() {
+			mockWebhooks.On("GetByID", mock.Anything, webhook.ID).Return(webhook, nil)
+		})
+
+		It("should send a dead delivery again and restart its attempts", func() {
+			dead := dueDelivery(webhook_app.MaxDeliveryAttempts)
+			dead.Status = models.WebhookDeliveryDead
+			dead.NextAttemptAt = nil
+			mockWebhooks.On("GetDelivery", mock.Anything, dead.ID).Return(dead, nil)
+			mockWebhooks.On("UpdateDelivery", mock.Anything, dead).Return(nil)
+
+			response, err := webhookService.Redeliver(ctx, webhook.ID, dead.ID)
+
+			Expect(err).To(BeNil())
+			Expect(received).To(HaveLen(1))
+			Expect(response.Status).To(Equal(models.WebhookDeliveryDelivered))
+			Expect(response.Attempts).To(Equal(1))
+		})
+
+		It("should not redeliver the deliveries of another webhook", func() {
+			foreign := dueDelivery(1)
+			foreign.WebhookID = 99
+			mockWebhooks.On("GetDelivery", mock.Anything, foreign.ID).Return(foreign, nil)
+
+			_, err := webhookService.Redeliver(ctx, webhook.ID, foreign.ID)
+
+			Expect(err).To(MatchError(webhook_app.ErrDeliveryNotFound))
+			Expect(received).To(BeEmpty())
+		})
+
+		It("should not let another merchant redeliver", func() {
+			otherMerchant := security.WithPrincipal(context.Background(), &security.Principal{
+				Role:       security.RoleMerchantAdmin,
+				MerchantID: merchantID + 1,
+			})
+
+			_, err := webhookService.Redeliver(otherMerchant, webhook.ID, 21)
+
+			Expect(err).To(MatchError(security.ErrForbidden))
+			Expect(received).To(BeEmpty())
+		})
+	})
+})
+
+type mockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *mockWebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) GetByID(ctx context.Context, id uint) (*models.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *mockWebhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) ListByMerchant(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[models.Webhook], error) {
+	args := m.Called(ctx, merchantID, page)
+	return args.Get(0).(*pagination.Page[models.Webhook]), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListActiveByMerchant(ctx context.Context, merchantID uint) ([]models.Webhook, error) {
+	args := m.Called(ctx, merchantID)
+	return args.Get(0).([]models.Webhook), args.Error(1)
+}
+
+func (m *mockWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *mockWebhookRepository) GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ListDeliveries(ctx context.Context, filter webhook_ports.DeliveryFilter, page pagination.Request) (*pagination.Page[models.WebhookDelivery], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(*pagination.Page[models.WebhookDelivery]), args.Error(1)
+}
+
+func (m *mockWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(ctx, now, lease, limit)
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *mockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
@@ -0,0 +1,6 @@
+package webhook_ports
+
+type DeliveryFilter struct {
+	WebhookID uint
+	Status    string
+}
@@ -0,0 +1,26 @@
+package webhook_ports
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"time"
+)
+
+type IWebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, id uint) (*models.Webhook, error)
+	Update(ctx context.Context, webhook *models.Webhook) error
+	Delete(ctx context.Context, id uint) error
+	ListByMerchant(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[models.Webhook], error)
+	ListActiveByMerchant(ctx context.Context, merchantID uint) ([]models.Webhook, error)
+	// CreateDeliveries skips the deliveries of an event already recorded for the webhook.
+	CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, filter DeliveryFilter, page pagination.Request) (*pagination.Page[models.WebhookDelivery], error)
+	// ClaimDueDeliveries returns the deliveries whose next attempt is due, with
+	// their webhook, and postpones them by lease so that no other worker picks
+	// them up meanwhile.
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+}
@@ -0,0 +1,8 @@
+package webhook_requests
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,startswith=https://"`
+	EventTypes []string `json:"eventTypes" binding:"required,min=1"`
+	// Secret signs the deliveries; a random one is generated when it is omitted.
+	Secret string `json:"secret" binding:"omitempty,min=16"`
+}
@@ -0,0 +1,9 @@
+package webhook_requests
+
+import "loyalty-campaigns/src/common/pagination"
+
+// ListDeliveriesRequest accepts sort by id or createdAt.
+type ListDeliveriesRequest struct {
+	pagination.Request
+	Status string `form:"status" binding:"omitempty,oneof=pending retrying delivered dead"`
+}
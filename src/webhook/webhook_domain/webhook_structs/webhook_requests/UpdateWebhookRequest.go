@@ -0,0 +1,7 @@
+package webhook_requests
+
+type UpdateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,startswith=https://"`
+	EventTypes []string `json:"eventTypes" binding:"required,min=1"`
+	Active     *bool    `json:"active"`
+}
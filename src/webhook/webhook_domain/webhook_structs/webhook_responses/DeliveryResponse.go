@@ -0,0 +1,18 @@
+package webhook_responses
+
+import "time"
+
+type DeliveryResponse struct {
+	ID             uint       `json:"id"`
+	WebhookID      uint       `json:"webhookId"`
+	EventID        string     `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus *int       `json:"responseStatus"`
+	LastError      string     `json:"lastError,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+}
@@ -0,0 +1,18 @@
+package webhook_responses
+
+import "time"
+
+type WebhookResponse struct {
+	ID         uint      `json:"id"`
+	MerchantID uint      `json:"merchantId"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// IssuedWebhookResponse carries the signing secret, which is only shown once.
+type IssuedWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
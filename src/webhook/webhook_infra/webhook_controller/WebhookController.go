@@ -0,0 +1,296 @@
+package webhook_controller
+
+import (
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/webhook/webhook_app"
+	"loyalty-campaigns/src/webhook/webhook_domain/webhook_structs/webhook_requests"
+	"loyalty-campaigns/src/webhook/webhook_infra/webhook_repository"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookService webhook_app.IWebhookService
+}
+
+var (
+	webhookControllerInstance *WebhookController
+	webhookControllerOnce     sync.Once
+)
+
+func NewWebhookController(router *gin.RouterGroup) *WebhookController {
+	webhookControllerOnce.Do(func() {
+		webhookControllerInstance = &WebhookController{}
+		db := configs.NewDBConnection().GetDB()
+		webhookRepository := webhook_repository.NewGormWebhookRepository(db)
+		webhookControllerInstance.webhookService = webhook_app.NewWebhookService(
+			webhookRepository,
+			webhook_app.WebhookPolicyFromEnv(),
+		)
+		webhookControllerInstance.setupWebhookRoutes(router)
+	})
+	return webhookControllerInstance
+}
+
+func (c *WebhookController) setupWebhookRoutes(router *gin.RouterGroup) {
+	router.POST("/merchants/:id/webhooks", c.CreateWebhook)
+	router.GET("/merchants/:id/webhooks", c.ListWebhooks)
+
+	webhookGroup := router.Group("/webhooks")
+	{
+		webhookGroup.GET("/:id", c.GetWebhook)
+		webhookGroup.PUT("/:id", c.UpdateWebhook)
+		webhookGroup.DELETE("/:id", c.DeleteWebhook)
+		webhookGroup.GET("/:id/deliveries", c.ListDeliveries)
+		webhookGroup.POST("/:id/deliveries/:deliveryId/redeliver", c.Redeliver)
+	}
+}
+
+// CreateWebhook godoc
+//
+//	@Summary		Subscribe a webhook
+//	@Description	Subscribe a URL to the events of a merchant. Deliveries are signed with the secret, which is generated when omitted and only returned once.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int										true	"Merchant ID"
+//	@Param			request	body		webhook_requests.CreateWebhookRequest	true	"Webhook subscription request"
+//	@Success		201		{object}	webhook_responses.IssuedWebhookResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/merchants/{id}/webhooks [post]
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	merchantID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_merchant_id", "Invalid merchant ID"))
+		return
+	}
+
+	var req webhook_requests.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.webhookService.CreateWebhook(ctx.Request.Context(), uint(merchantID), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// ListWebhooks godoc
+//
+//	@Summary		List webhooks of a merchant
+//	@Description	Get a page of the webhooks of a merchant. Sort by id or createdAt, prefixed with "-" for descending order.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int					true	"Merchant ID"
+//	@Param			request	query		pagination.Request	false	"Sort and pagination"
+//	@Success		200		{object}	pagination.Page[webhook_responses.WebhookResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/merchants/{id}/webhooks [get]
+func (c *WebhookController) ListWebhooks(ctx *gin.Context) {
+	merchantID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_merchant_id", "Invalid merchant ID"))
+		return
+	}
+
+	var req pagination.Request
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.webhookService.ListWebhooks(ctx.Request.Context(), uint(merchantID), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetWebhook godoc
+//
+//	@Summary		Get a webhook
+//	@Description	Get a webhook subscription by its ID
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Webhook ID"
+//	@Success		200	{object}	webhook_responses.WebhookResponse
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Router			/api/webhooks/{id} [get]
+func (c *WebhookController) GetWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	response, err := c.webhookService.GetWebhook(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateWebhook godoc
+//
+//	@Summary		Update a webhook
+//	@Description	Change the URL, the event types or the active flag of a webhook
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int										true	"Webhook ID"
+//	@Param			request	body		webhook_requests.UpdateWebhookRequest	true	"Webhook update request"
+//	@Success		200		{object}	webhook_responses.WebhookResponse
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/webhooks/{id} [put]
+func (c *WebhookController) UpdateWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req webhook_requests.UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	response, err := c.webhookService.UpdateWebhook(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		Delete a webhook
+//	@Description	Delete a webhook subscription; its pending deliveries are not sent
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id	path		int	true	"Webhook ID"
+//	@Success		200	{object}	map[string]string
+//	@Failure		400	{object}	domain_errors.Problem
+//	@Failure		403	{object}	domain_errors.Problem
+//	@Failure		404	{object}	domain_errors.Problem
+//	@Failure		500	{object}	domain_errors.Problem
+//	@Router			/api/webhooks/{id} [delete]
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	err = c.webhookService.DeleteWebhook(ctx.Request.Context(), uint(id))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ListDeliveries godoc
+//
+//	@Summary		List the deliveries of a webhook
+//	@Description	Get a page of the delivery history of a webhook, newest first by default, optionally filtered by status. Sort by id or createdAt, prefixed with "-" for descending order.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id		path		int										true	"Webhook ID"
+//	@Param			request	query		webhook_requests.ListDeliveriesRequest	false	"Filters, sort and pagination"
+//	@Success		200		{object}	pagination.Page[webhook_responses.DeliveryResponse]
+//	@Failure		400		{object}	domain_errors.Problem
+//	@Failure		403		{object}	domain_errors.Problem
+//	@Failure		404		{object}	domain_errors.Problem
+//	@Failure		500		{object}	domain_errors.Problem
+//	@Router			/api/webhooks/{id}/deliveries [get]
+func (c *WebhookController) ListDeliveries(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	var req webhook_requests.ListDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(domain_errors.InvalidRequest(err))
+		return
+	}
+
+	page, err := c.webhookService.ListDeliveries(ctx.Request.Context(), uint(id), req)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// Redeliver godoc
+//
+//	@Summary		Redeliver an event
+//	@Description	Send a delivery again right away, including dead ones. A failed redelivery is retried with the usual backoff.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Param			id			path		int	true	"Webhook ID"
+//	@Param			deliveryId	path		int	true	"Delivery ID"
+//	@Success		200			{object}	webhook_responses.DeliveryResponse
+//	@Failure		400			{object}	domain_errors.Problem
+//	@Failure		403			{object}	domain_errors.Problem
+//	@Failure		404			{object}	domain_errors.Problem
+//	@Failure		500			{object}	domain_errors.Problem
+//	@Router			/api/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (c *WebhookController) Redeliver(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_id", "Invalid ID"))
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(ctx.Param("deliveryId"), 10, 32)
+	if err != nil {
+		ctx.Error(domain_errors.Validation("invalid_delivery_id", "Invalid delivery ID"))
+		return
+	}
+
+	response, err := c.webhookService.Redeliver(ctx.Request.Context(), uint(id), uint(deliveryID))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
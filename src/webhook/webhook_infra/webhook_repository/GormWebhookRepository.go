@@ -0,0 +1,131 @@
+package webhook_repository
+
+import (
+	"context"
+	"loyalty-campaigns/src/common/configs"
+	"loyalty-campaigns/src/common/domain_errors"
+	"loyalty-campaigns/src/common/models"
+	"loyalty-campaigns/src/common/pagination"
+	"loyalty-campaigns/src/webhook/webhook_domain/webhook_ports"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormWebhookRepository struct {
+	DB *gorm.DB
+}
+
+func NewGormWebhookRepository(db *gorm.DB) webhook_ports.IWebhookRepository {
+	return &GormWebhookRepository{DB: db}
+}
+
+func (r *GormWebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Create(webhook).Error, "webhook")
+}
+
+func (r *GormWebhookRepository) GetByID(ctx context.Context, id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := configs.DB(ctx, r.DB).First(&webhook, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "webhook")
+	}
+	return &webhook, nil
+}
+
+func (r *GormWebhookRepository) Update(ctx context.Context, webhook *models.Webhook) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Save(webhook).Error, "webhook")
+}
+
+func (r *GormWebhookRepository) Delete(ctx context.Context, id uint) error {
+	return domain_errors.Translate(configs.DB(ctx, r.DB).Delete(&models.Webhook{}, id).Error, "webhook")
+}
+
+var webhookSorting = pagination.Sorting[models.Webhook]{
+	IDColumn: "id",
+	ID:       func(webhook *models.Webhook) uint { return webhook.ID },
+	Fields: map[string]pagination.Key[models.Webhook]{
+		"id":        {Column: "id", Value: func(webhook *models.Webhook) any { return webhook.ID }},
+		"createdAt": {Column: "created_at", Value: func(webhook *models.Webhook) any { return webhook.CreatedAt }},
+	},
+	Default: "id",
+}
+
+func (r *GormWebhookRepository) ListByMerchant(ctx context.Context, merchantID uint, page pagination.Request) (*pagination.Page[models.Webhook], error) {
+	query := configs.DB(ctx, r.DB).Where("merchant_id = ?", merchantID)
+	return pagination.Find(query, page, webhookSorting)
+}
+
+func (r *GormWebhookRepository) ListActiveByMerchant(ctx context.Context, merchantID uint) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := configs.DB(ctx, r.DB).Where("merchant_id = ? AND active", merchantID).Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *GormWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := configs.DB(ctx, r.DB).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Omit("Webhook").
+		Create(&deliveries).Error
+	return domain_errors.Translate(err, "webhook_delivery")
+}
+
+func (r *GormWebhookRepository) GetDelivery(ctx context.Context, id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := configs.DB(ctx, r.DB).First(&delivery, id).Error
+	if err != nil {
+		return nil, domain_errors.Translate(err, "webhook_delivery")
+	}
+	return &delivery, nil
+}
+
+var deliverySorting = pagination.Sorting[models.WebhookDelivery]{
+	IDColumn: "id",
+	ID:       func(delivery *models.WebhookDelivery) uint { return delivery.ID },
+	Fields: map[string]pagination.Key[models.WebhookDelivery]{
+		"id":        {Column: "id", Value: func(delivery *models.WebhookDelivery) any { return delivery.ID }},
+		"createdAt": {Column: "created_at", Value: func(delivery *models.WebhookDelivery) any { return delivery.CreatedAt }},
+	},
+	Default: "-id",
+}
+
+func (r *GormWebhookRepository) ListDeliveries(ctx context.Context, filter webhook_ports.DeliveryFilter, page pagination.Request) (*pagination.Page[models.WebhookDelivery], error) {
+	query := configs.DB(ctx, r.DB).Where("webhook_id = ?", filter.WebhookID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return pagination.Find(query, page, deliverySorting)
+}
+
+func (r *GormWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := configs.DB(ctx, r.DB).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "webhook_deliveries"}, Options: "SKIP LOCKED"}).
+			Joins("Webhook").
+			Where("webhook_deliveries.next_attempt_at <= ? AND \"Webhook\".active", now).
+			Order("webhook_deliveries.next_attempt_at, webhook_deliveries.id").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+func (r *GormWebhookRepository) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	err := configs.DB(ctx, r.DB).Omit("Webhook").Save(delivery).Error
+	return domain_errors.Translate(err, "webhook_delivery")
+}